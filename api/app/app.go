@@ -0,0 +1,59 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hbalmes/ci_cd-api/api/clients"
+	"github.com/hbalmes/ci_cd-api/api/configs"
+	"github.com/hbalmes/ci_cd-api/api/controllers/routers"
+	"github.com/hbalmes/ci_cd-api/api/services"
+	"github.com/hbalmes/ci_cd-api/api/services/storage"
+)
+
+//App is the container of the dependencies of the API.
+//The storage, the github client and the services are built once by New
+//and shared by the routes and the scheduler.
+type App struct {
+	Config       *configs.Config
+	SQL          *storage.SQL
+	GithubClient clients.GithubClient
+	Services     *services.Services
+	Scheduler    *services.Scheduler
+}
+
+//New connects to the database, migrates its schema and builds every dependency of the API
+func New(ctx context.Context, cfg *configs.Config) (*App, error) {
+	//The database could be starting too, so the connection is retried
+	sql, err := storage.ConnectMySQL(ctx, cfg.Database)
+	if err != nil {
+		return nil, fmt.Errorf("error stablishing the mysql connection: %v", err)
+	}
+
+	if err := sql.Migrate(ctx); err != nil {
+		sql.Client.Close()
+		return nil, fmt.Errorf("error migrating the database: %v", err)
+	}
+
+	githubClient := clients.NewGithubClient(cfg.Github)
+	svc := services.NewServices(sql, githubClient, cfg.Auth)
+
+	return &App{
+		Config:       cfg,
+		SQL:          sql,
+		GithubClient: githubClient,
+		Services:     svc,
+		Scheduler:    services.NewScheduler(svc.ReleaseSchedule, svc.Webhook, svc.Build),
+	}, nil
+}
+
+//Handler returns the http handler serving every endpoint of the API
+func (a *App) Handler() *gin.Engine {
+	return routers.Route(a.Services, a.Config.Github.WebhookSecret)
+}
+
+//Close closes the database connection
+func (a *App) Close() error {
+	return a.SQL.Client.Close()
+}
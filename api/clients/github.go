@@ -5,47 +5,168 @@ package clients
 // create and delete jobs necessary for the execution of release process
 
 import (
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"github.com/hbalmes/ci_cd-api/api/configs"
+	"github.com/hbalmes/ci_cd-api/api/metrics"
 	"github.com/hbalmes/ci_cd-api/api/models"
 	"github.com/hbalmes/ci_cd-api/api/models/webhook"
 	"github.com/hbalmes/ci_cd-api/api/utils"
 	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"github.com/hbalmes/ci_cd-api/api/utils/logger"
+	"github.com/hbalmes/ci_cd-api/api/utils/tracing"
 	"github.com/mercadolibre/golang-restclient/rest"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
 	"net/http"
-	"os"
+	"strings"
 	"time"
 )
 
+//HeadModifiedErrorCode is the error code of a merge refused because the pull request head is not the expected sha
+const HeadModifiedErrorCode = "head_modified"
+
+//closedPullRequestsPageSize is the number of closed pull requests requested by page, the maximum allowed by github
+const closedPullRequestsPageSize = 100
+
 type GithubClient interface {
-	GetBranchInformation(config *models.Configuration, branchName string) (*models.GetBranchResponse, apierrors.ApiError)
-	CreateGithubRef(config *models.Configuration, branchConfig *models.Branch, workflowConfig *models.WorkflowConfig) apierrors.ApiError
-	ProtectBranch(config *models.Configuration, branchConfig *models.Branch) apierrors.ApiError
-	UnprotectBranch(config *models.Configuration, branchConfig *models.Branch) apierrors.ApiError
-	SetDefaultBranch(config *models.Configuration, workflowConfig *models.WorkflowConfig) apierrors.ApiError
-	CreateStatus(config *models.Configuration, statusWH *webhook.Status) apierrors.ApiError
-	CreateBranch(config *models.Configuration, branchConfig *models.Branch, sha string) apierrors.ApiError
-	CreateIssueComment(config *models.Configuration, pullRequest *models.PullRequest, issueCommentBody string) apierrors.ApiError
-	CreateRelease(config *models.Configuration, build *models.Build) apierrors.ApiError
+	GetBranchInformation(ctx context.Context, config *models.Configuration, branchName string) (*models.GetBranchResponse, apierrors.ApiError)
+	CreateGithubRef(ctx context.Context, config *models.Configuration, branchConfig *models.Branch, workflowConfig *models.WorkflowConfig) apierrors.ApiError
+	ProtectBranch(ctx context.Context, config *models.Configuration, branchConfig *models.Branch) apierrors.ApiError
+	UnprotectBranch(ctx context.Context, config *models.Configuration, branchConfig *models.Branch) apierrors.ApiError
+	SetDefaultBranch(ctx context.Context, config *models.Configuration, workflowConfig *models.WorkflowConfig) apierrors.ApiError
+	CreateStatus(ctx context.Context, config *models.Configuration, statusWH *webhook.Status) apierrors.ApiError
+	CreateBranch(ctx context.Context, config *models.Configuration, branchConfig *models.Branch, sha string) apierrors.ApiError
+	CreateIssueComment(ctx context.Context, config *models.Configuration, pullRequest *models.PullRequest, issueCommentBody string) apierrors.ApiError
+	CreateRelease(ctx context.Context, config *models.Configuration, build *models.Build) apierrors.ApiError
+	GetCollaboratorPermission(ctx context.Context, config *models.Configuration, username string) (string, apierrors.ApiError)
+	GetPullRequestsByCommit(ctx context.Context, config *models.Configuration, sha string) ([]models.CommitPullRequestResponse, apierrors.ApiError)
+	ListClosedPullRequests(ctx context.Context, config *models.Configuration, since time.Time) ([]models.CommitPullRequestResponse, apierrors.ApiError)
+	CreateCommitComment(ctx context.Context, config *models.Configuration, sha string, commentBody string) apierrors.ApiError
+	CreatePullRequest(ctx context.Context, config *models.Configuration, head string, base string, title string, body string) (*models.PullRequestResponse, apierrors.ApiError)
+	MergePullRequest(ctx context.Context, config *models.Configuration, number int, mergeMethod string, sha string) apierrors.ApiError
+	MergeBranch(ctx context.Context, config *models.Configuration, base string, head string, commitMessage string) (string, apierrors.ApiError)
+	DeleteBranch(ctx context.Context, config *models.Configuration, branchName string) apierrors.ApiError
+	FastForwardBranch(ctx context.Context, config *models.Configuration, branchName string, sha string) apierrors.ApiError
+	CompareCommits(ctx context.Context, config *models.Configuration, base string, head string) (*models.CompareResponse, apierrors.ApiError)
+	GetFileContent(ctx context.Context, config *models.Configuration, path string, ref string) (*models.FileContentResponse, apierrors.ApiError)
+	CreateTree(ctx context.Context, config *models.Configuration, request *models.TreeRequest) (string, apierrors.ApiError)
+	CreateCommit(ctx context.Context, config *models.Configuration, request *models.CommitRequest) (string, apierrors.ApiError)
+	CreateDeployment(ctx context.Context, config *models.Configuration, request *models.DeploymentRequest) (*models.DeploymentResponse, apierrors.ApiError)
+	CreateDeploymentStatus(ctx context.Context, config *models.Configuration, deploymentID int64, state string, description string) apierrors.ApiError
+	IsTeamMember(ctx context.Context, config *models.Configuration, team string, username string) (bool, apierrors.ApiError)
+	GetReleaseByTag(ctx context.Context, config *models.Configuration, tagName string) (*models.ReleaseResponse, apierrors.ApiError)
+	UpdateRelease(ctx context.Context, config *models.Configuration, releaseID int64, request *models.ReleaseUpdateRequest) apierrors.ApiError
+	CheckCredentials(ctx context.Context) apierrors.ApiError
 }
 
 type githubClient struct {
 	Client Client
 }
 
-func NewGithubClient() GithubClient {
-	ghToken := os.Getenv("TESISGHTOKEN")
+//instrument returns the rest client recording and logging the calls of the given github client method
+func (c *githubClient) instrument(ctx context.Context, method string) Client {
+	return &instrumentedClient{
+		Client: c.Client,
+		Method: method,
+		ctx:    ctx,
+	}
+}
+
+//instrumentedClient traces, counts, times and logs the requests performed by a rest client
+//It only lives for a single call, so it carries the context of the request
+type instrumentedClient struct {
+	Client Client
+	Method string
+	ctx    context.Context
+}
+
+func (c *instrumentedClient) Get(url string) Response {
+	return c.do(http.MethodGet, url, func() Response { return c.Client.Get(url) })
+}
+
+func (c *instrumentedClient) Post(url string, body interface{}) Response {
+	return c.do(http.MethodPost, url, func() Response { return c.Client.Post(url, body) })
+}
+
+func (c *instrumentedClient) Put(url string, body interface{}) Response {
+	return c.do(http.MethodPut, url, func() Response { return c.Client.Put(url, body) })
+}
+
+func (c *instrumentedClient) Patch(url string, body interface{}) Response {
+	return c.do(http.MethodPatch, url, func() Response { return c.Client.Patch(url, body) })
+}
+
+func (c *instrumentedClient) Delete(url string) Response {
+	return c.do(http.MethodDelete, url, func() Response { return c.Client.Delete(url) })
+}
+
+//do performs the request inside a client span and observes it
+func (c *instrumentedClient) do(httpMethod string, url string, request func() Response) Response {
+	_, span := tracing.Start(c.ctx, "GithubClient."+c.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.HTTPRequestMethodKey.String(httpMethod), semconv.URLFull(url)),
+	)
+	defer span.End()
+
+	start := time.Now()
+	response := request()
+
+	switch {
+	case response == nil:
+		span.SetStatus(codes.Error, "no response")
+	case response.Err() != nil:
+		span.RecordError(response.Err())
+		span.SetStatus(codes.Error, response.Err().Error())
+	default:
+		span.SetAttributes(semconv.HTTPResponseStatusCode(response.StatusCode()))
+		if response.StatusCode() >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(response.StatusCode()))
+		}
+	}
+
+	return c.observe(start, response)
+}
+
+func (c *instrumentedClient) observe(start time.Time, response Response) Response {
+	statusCode := 0
+	if response != nil && response.Err() == nil {
+		statusCode = response.StatusCode()
+	}
+	metrics.ObserveGithubRequest(c.Method, statusCode, start)
+
+	l := logger.FromContext(c.ctx)
+	switch {
+	case response == nil || response.Err() != nil:
+		event := l.Error()
+		if response != nil {
+			event = event.Err(response.Err())
+		}
+		event.Str("method", c.Method).Dur("latency", time.Since(start)).Msg("github request failed")
+	case statusCode >= http.StatusInternalServerError:
+		l.Error().Str("method", c.Method).Int("status", statusCode).Dur("latency", time.Since(start)).Msg("github request failed")
+	default:
+		l.Debug().Str("method", c.Method).Int("status", statusCode).Dur("latency", time.Since(start)).Msg("github request")
+	}
+
+	return response
+}
+
+//NewGithubClient initializes a GithubClient with the base url and the token of the configs
+func NewGithubClient(cfg configs.GithubConfig) GithubClient {
 	hs := make(http.Header)
 	hs.Set("cache-control", "no-cache")
 	hs.Set("Content-Type", "application/json")
-	hs.Set("Authorization", fmt.Sprintf("token %s", ghToken))
+	hs.Set("Authorization", fmt.Sprintf("token %s", cfg.Token))
 	hs.Set("Accept", "application/vnd.github.luke-cage-preview+json")
 
 	return &githubClient{
 		Client: &client{
 			RestClient: &rest.RequestBuilder{
-				BaseURL:        configs.GetGithubBaseURL(),
+				BaseURL:        cfg.BaseURL,
 				Timeout:        5 * time.Second,
 				Headers:        hs,
 				ContentType:    rest.JSON,
@@ -64,13 +185,13 @@ type ghGetBranchResponse struct {
 
 //Gets a repository branch info
 //This perform a GET request to Github api using
-func (c *githubClient) GetBranchInformation(config *models.Configuration, branchName string) (*models.GetBranchResponse, apierrors.ApiError) {
+func (c *githubClient) GetBranchInformation(ctx context.Context, config *models.Configuration, branchName string) (*models.GetBranchResponse, apierrors.ApiError) {
 
 	if config.RepositoryName == nil || config.RepositoryOwner == nil || branchName == "" {
 		return nil, apierrors.NewBadRequestApiError("invalid github body params")
 	}
 
-	response := c.Client.Get(fmt.Sprintf("/repos/%s/%s/branches/%s", *config.RepositoryOwner, *config.RepositoryName, branchName))
+	response := c.instrument(ctx, "GetBranchInformation").Get(fmt.Sprintf("/repos/%s/%s/branches/%s", *config.RepositoryOwner, *config.RepositoryName, branchName))
 
 	if response.Err() != nil {
 		return nil, apierrors.NewInternalServerApiError("Something went wrong getting branch information", response.Err())
@@ -90,7 +211,7 @@ func (c *githubClient) GetBranchInformation(config *models.Configuration, branch
 
 //Protects the branch from pushs by following the workflow configuration
 //This perform a PUT request to Github api
-func (c *githubClient) ProtectBranch(config *models.Configuration, branchConfig *models.Branch) apierrors.ApiError {
+func (c *githubClient) ProtectBranch(ctx context.Context, config *models.Configuration, branchConfig *models.Branch) apierrors.ApiError {
 
 	if branchConfig.Name == nil {
 		return apierrors.NewBadRequestApiError("invalid branch protection body params")
@@ -103,7 +224,7 @@ func (c *githubClient) ProtectBranch(config *models.Configuration, branchConfig
 		"restrictions":                  nil,
 	}
 
-	response := c.Client.Put(fmt.Sprintf("/repos/%s/%s/branches/%s/protection", *config.RepositoryOwner, *config.RepositoryName, *branchConfig.Name), body)
+	response := c.instrument(ctx, "ProtectBranch").Put(fmt.Sprintf("/repos/%s/%s/branches/%s/protection", *config.RepositoryOwner, *config.RepositoryName, *branchConfig.Name), body)
 
 	if response.Err() != nil {
 		return apierrors.NewInternalServerApiError("Something went wrong protecting branch", response.Err())
@@ -121,7 +242,7 @@ func (c *githubClient) ProtectBranch(config *models.Configuration, branchConfig
 
 //Create a new reference, in this case a branch
 //This perform a POST request to Github api
-func (c *githubClient) CreateBranch(config *models.Configuration, branchConfig *models.Branch, sha string) apierrors.ApiError {
+func (c *githubClient) CreateBranch(ctx context.Context, config *models.Configuration, branchConfig *models.Branch, sha string) apierrors.ApiError {
 
 	if branchConfig.Name == nil || config.RepositoryOwner == nil || config.RepositoryName == nil || sha == "" {
 		return apierrors.NewBadRequestApiError("invalid body params")
@@ -136,7 +257,7 @@ func (c *githubClient) CreateBranch(config *models.Configuration, branchConfig *
 
 	url := fmt.Sprintf("/repos/%s/%s/git/refs", *config.RepositoryOwner, *config.RepositoryName)
 
-	response := c.Client.Post(url, body)
+	response := c.instrument(ctx, "CreateBranch").Post(url, body)
 
 	if response.Err() != nil {
 		return apierrors.NewInternalServerApiError("Something went wrong creating a branch", response.Err())
@@ -151,7 +272,7 @@ func (c *githubClient) CreateBranch(config *models.Configuration, branchConfig *
 
 //Create a new reference on github. First we get the information needed to make the creation and then the creation itself.
 //This perform a GetBranchInformation and CreateBranch
-func (c *githubClient) CreateGithubRef(config *models.Configuration, branchConfig *models.Branch, workflowConfig *models.WorkflowConfig) apierrors.ApiError {
+func (c *githubClient) CreateGithubRef(ctx context.Context, config *models.Configuration, branchConfig *models.Branch, workflowConfig *models.WorkflowConfig) apierrors.ApiError {
 
 	if branchConfig.Name == nil || config.RepositoryOwner == nil || config.RepositoryName == nil {
 		return apierrors.NewBadRequestApiError("invalid body params")
@@ -164,13 +285,13 @@ func (c *githubClient) CreateGithubRef(config *models.Configuration, branchConfi
 		initialBranch = utils.Stringify("master")
 	}
 
-	branchInfo, getBranchError := c.GetBranchInformation(config, *initialBranch)
+	branchInfo, getBranchError := c.GetBranchInformation(ctx, config, *initialBranch)
 
 	if getBranchError != nil {
 		return getBranchError
 	}
 
-	createRefErr := c.CreateBranch(config, branchConfig, branchInfo.Commit.Sha)
+	createRefErr := c.CreateBranch(ctx, config, branchConfig, branchInfo.Commit.Sha)
 
 	if createRefErr != nil {
 		return createRefErr
@@ -181,7 +302,7 @@ func (c *githubClient) CreateGithubRef(config *models.Configuration, branchConfi
 
 //SetDefaultBranch updates the default branch of repository.
 //This is the branch from which new branches should start
-func (c *githubClient) SetDefaultBranch(config *models.Configuration, workflowConfig *models.WorkflowConfig) apierrors.ApiError {
+func (c *githubClient) SetDefaultBranch(ctx context.Context, config *models.Configuration, workflowConfig *models.WorkflowConfig) apierrors.ApiError {
 
 	if config.RepositoryOwner == nil || config.RepositoryName == nil || workflowConfig.DefaultBranch == nil {
 		return apierrors.NewBadRequestApiError("invalid body params")
@@ -192,7 +313,7 @@ func (c *githubClient) SetDefaultBranch(config *models.Configuration, workflowCo
 		"default_branch": workflowConfig.DefaultBranch,
 	}
 
-	response := c.Client.Post(fmt.Sprintf("/repos/%s/%s", *config.RepositoryOwner, *config.RepositoryName), body)
+	response := c.instrument(ctx, "SetDefaultBranch").Post(fmt.Sprintf("/repos/%s/%s", *config.RepositoryOwner, *config.RepositoryName), body)
 
 	if response.Err() != nil {
 		return apierrors.NewInternalServerApiError("Something went wrong setting default branch", response.Err())
@@ -207,7 +328,7 @@ func (c *githubClient) SetDefaultBranch(config *models.Configuration, workflowCo
 
 //CreateStatus create commit statuses for a given SHA.
 //This perform a POST request
-func (c *githubClient) CreateStatus(config *models.Configuration, statusWH *webhook.Status) apierrors.ApiError {
+func (c *githubClient) CreateStatus(ctx context.Context, config *models.Configuration, statusWH *webhook.Status) apierrors.ApiError {
 
 	if config.RepositoryOwner == nil || config.RepositoryName == nil || statusWH.Sha == nil || statusWH.Context == nil {
 		return apierrors.NewBadRequestApiError("invalid body params")
@@ -220,7 +341,7 @@ func (c *githubClient) CreateStatus(config *models.Configuration, statusWH *webh
 		"context":     statusWH.Context,
 	}
 
-	response := c.Client.Post(fmt.Sprintf("/repos/%s/%s/statuses/%s", *config.RepositoryOwner, *config.RepositoryName, *statusWH.Sha), body)
+	response := c.instrument(ctx, "CreateStatus").Post(fmt.Sprintf("/repos/%s/%s/statuses/%s", *config.RepositoryOwner, *config.RepositoryName, *statusWH.Sha), body)
 
 	if response.Err() != nil {
 		return apierrors.NewInternalServerApiError("RestClient Error creating new status", response.Err())
@@ -236,13 +357,13 @@ func (c *githubClient) CreateStatus(config *models.Configuration, statusWH *webh
 
 //UnprotectBranch deletes the branch protection
 //This perform a DELETE request to Github api
-func (c *githubClient) UnprotectBranch(config *models.Configuration, branchConfig *models.Branch) apierrors.ApiError {
+func (c *githubClient) UnprotectBranch(ctx context.Context, config *models.Configuration, branchConfig *models.Branch) apierrors.ApiError {
 
 	if branchConfig.Name == nil {
 		return apierrors.NewBadRequestApiError("invalid branch body params")
 	}
 
-	response := c.Client.Delete(fmt.Sprintf("/repos/%s/%s/branches/%s/protection", *config.RepositoryOwner, *config.RepositoryName, *branchConfig.Name))
+	response := c.instrument(ctx, "UnprotectBranch").Delete(fmt.Sprintf("/repos/%s/%s/branches/%s/protection", *config.RepositoryOwner, *config.RepositoryName, *branchConfig.Name))
 
 	if response.Err() != nil {
 		return apierrors.NewInternalServerApiError("Something went wrong deleting branch protection", response.Err())
@@ -261,7 +382,7 @@ func (c *githubClient) UnprotectBranch(config *models.Configuration, branchConfi
 
 //CreateIssueComment create issue comment to a pull request.
 //This perform a POST request
-func (c *githubClient) CreateIssueComment(config *models.Configuration, pullRequest *models.PullRequest, issueCommentBody string) apierrors.ApiError {
+func (c *githubClient) CreateIssueComment(ctx context.Context, config *models.Configuration, pullRequest *models.PullRequest, issueCommentBody string) apierrors.ApiError {
 
 	if config.RepositoryOwner == nil || config.RepositoryName == nil || pullRequest.PullRequestNumber == 0 {
 		return apierrors.NewBadRequestApiError("invalid body params")
@@ -271,7 +392,7 @@ func (c *githubClient) CreateIssueComment(config *models.Configuration, pullRequ
 		"body": issueCommentBody,
 	}
 
-	response := c.Client.Post(fmt.Sprintf("/repos/%s/%s/issues/%d/comments", *config.RepositoryOwner, *config.RepositoryName, pullRequest.PullRequestNumber), body)
+	response := c.instrument(ctx, "CreateIssueComment").Post(fmt.Sprintf("/repos/%s/%s/issues/%d/comments", *config.RepositoryOwner, *config.RepositoryName, pullRequest.PullRequestNumber), body)
 
 	if response.Err() != nil {
 		return apierrors.NewInternalServerApiError("restClient Error creating new issue comment", response.Err())
@@ -287,7 +408,7 @@ func (c *githubClient) CreateIssueComment(config *models.Configuration, pullRequ
 
 //CreateRelease create a new github release.
 //This perform a POST request
-func (c *githubClient) CreateRelease(config *models.Configuration, build *models.Build) apierrors.ApiError {
+func (c *githubClient) CreateRelease(ctx context.Context, config *models.Configuration, build *models.Build) apierrors.ApiError {
 
 	if config.RepositoryOwner == nil || config.RepositoryName == nil || build.Sha == nil{
 		return apierrors.NewBadRequestApiError("invalid body params")
@@ -314,7 +435,7 @@ func (c *githubClient) CreateRelease(config *models.Configuration, build *models
 		"prerelease": preRelease,
 	}
 
-	response := c.Client.Post(fmt.Sprintf("/repos/%s/%s/releases", *config.RepositoryOwner, *config.RepositoryName), body)
+	response := c.instrument(ctx, "CreateRelease").Post(fmt.Sprintf("/repos/%s/%s/releases", *config.RepositoryOwner, *config.RepositoryName), body)
 
 	if response.Err() != nil {
 		return apierrors.NewInternalServerApiError("restClient Error creating new release", response.Err())
@@ -326,3 +447,563 @@ func (c *githubClient) CreateRelease(config *models.Configuration, build *models
 
 	return nil
 }
+
+//GetCollaboratorPermission gets the permission level (admin, write, read or none) of a user over the repository.
+//This perform a GET request
+func (c *githubClient) GetCollaboratorPermission(ctx context.Context, config *models.Configuration, username string) (string, apierrors.ApiError) {
+
+	if config.RepositoryOwner == nil || config.RepositoryName == nil || username == "" {
+		return "", apierrors.NewBadRequestApiError("invalid body params")
+	}
+
+	response := c.instrument(ctx, "GetCollaboratorPermission").Get(fmt.Sprintf("/repos/%s/%s/collaborators/%s/permission", *config.RepositoryOwner, *config.RepositoryName, username))
+
+	if response.Err() != nil {
+		return "", apierrors.NewInternalServerApiError("restClient Error getting collaborator permission", response.Err())
+	}
+
+	if response.StatusCode() != http.StatusOK {
+		return "", apierrors.NewInternalServerApiError("error getting collaborator permission", response.Err())
+	}
+
+	var permission models.CollaboratorPermissionResponse
+	if err := json.Unmarshal(response.Bytes(), &permission); err != nil {
+		return "", apierrors.NewBadRequestApiError("error binding github collaborator permission response")
+	}
+
+	return permission.Permission, nil
+}
+
+//GetPullRequestsByCommit lists the pull requests associated with a commit.
+//This perform a GET request
+func (c *githubClient) GetPullRequestsByCommit(ctx context.Context, config *models.Configuration, sha string) ([]models.CommitPullRequestResponse, apierrors.ApiError) {
+
+	if config.RepositoryOwner == nil || config.RepositoryName == nil || sha == "" {
+		return nil, apierrors.NewBadRequestApiError("invalid body params")
+	}
+
+	response := c.instrument(ctx, "GetPullRequestsByCommit").Get(fmt.Sprintf("/repos/%s/%s/commits/%s/pulls", *config.RepositoryOwner, *config.RepositoryName, sha))
+
+	if response.Err() != nil {
+		return nil, apierrors.NewInternalServerApiError("restClient Error getting commit pull requests", response.Err())
+	}
+
+	if response.StatusCode() != http.StatusOK {
+		return nil, apierrors.NewInternalServerApiError("error getting commit pull requests", response.Err())
+	}
+
+	var pullRequests []models.CommitPullRequestResponse
+	if err := json.Unmarshal(response.Bytes(), &pullRequests); err != nil {
+		return nil, apierrors.NewBadRequestApiError("error binding github commit pull requests response")
+	}
+
+	return pullRequests, nil
+}
+
+//ListClosedPullRequests lists the closed pull requests updated since the given time, the latest merged ones among them.
+//The pull requests are listed from the last updated, page by page, until a page ends before the given time.
+//This perform GET requests
+func (c *githubClient) ListClosedPullRequests(ctx context.Context, config *models.Configuration, since time.Time) ([]models.CommitPullRequestResponse, apierrors.ApiError) {
+
+	if config.RepositoryOwner == nil || config.RepositoryName == nil {
+		return nil, apierrors.NewBadRequestApiError("invalid body params")
+	}
+
+	pullRequests := make([]models.CommitPullRequestResponse, 0)
+
+	for page := 1; ; page++ {
+		response := c.instrument(ctx, "ListClosedPullRequests").Get(fmt.Sprintf("/repos/%s/%s/pulls?state=closed&sort=updated&direction=desc&per_page=%d&page=%d", *config.RepositoryOwner, *config.RepositoryName, closedPullRequestsPageSize, page))
+
+		if response.Err() != nil {
+			return nil, apierrors.NewInternalServerApiError("restClient Error listing closed pull requests", response.Err())
+		}
+
+		if response.StatusCode() != http.StatusOK {
+			return nil, apierrors.NewInternalServerApiError(fmt.Sprintf("error listing closed pull requests - status: %d", response.StatusCode()), response.Err())
+		}
+
+		var pagePullRequests []models.CommitPullRequestResponse
+		if err := json.Unmarshal(response.Bytes(), &pagePullRequests); err != nil {
+			return nil, apierrors.NewBadRequestApiError("error binding github closed pull requests response")
+		}
+
+		pullRequests = append(pullRequests, pagePullRequests...)
+
+		if len(pagePullRequests) < closedPullRequestsPageSize || updatedBefore(pagePullRequests[len(pagePullRequests)-1], since) {
+			return pullRequests, nil
+		}
+	}
+}
+
+//updatedBefore checks if the pull request was last updated before the given time.
+func updatedBefore(pr models.CommitPullRequestResponse, since time.Time) bool {
+	if pr.UpdatedAt == nil {
+		return false
+	}
+
+	updatedAt, err := time.Parse(time.RFC3339, *pr.UpdatedAt)
+
+	return err == nil && updatedAt.Before(since)
+}
+
+//CreateCommitComment create a comment on a commit.
+//This perform a POST request
+func (c *githubClient) CreateCommitComment(ctx context.Context, config *models.Configuration, sha string, commentBody string) apierrors.ApiError {
+
+	if config.RepositoryOwner == nil || config.RepositoryName == nil || sha == "" {
+		return apierrors.NewBadRequestApiError("invalid body params")
+	}
+
+	body := map[string]interface{}{
+		"body": commentBody,
+	}
+
+	response := c.instrument(ctx, "CreateCommitComment").Post(fmt.Sprintf("/repos/%s/%s/commits/%s/comments", *config.RepositoryOwner, *config.RepositoryName, sha), body)
+
+	if response.Err() != nil {
+		return apierrors.NewInternalServerApiError("restClient Error creating new commit comment", response.Err())
+	}
+
+	if response.StatusCode() != http.StatusOK && response.StatusCode() != http.StatusCreated {
+		return apierrors.NewInternalServerApiError("error creating new commit comment", response.Err())
+	}
+
+	return nil
+}
+
+//CreatePullRequest opens a new pull request from the head branch into the base branch.
+//This perform a POST request
+func (c *githubClient) CreatePullRequest(ctx context.Context, config *models.Configuration, head string, base string, title string, body string) (*models.PullRequestResponse, apierrors.ApiError) {
+
+	if config.RepositoryOwner == nil || config.RepositoryName == nil || head == "" || base == "" {
+		return nil, apierrors.NewBadRequestApiError("invalid body params")
+	}
+
+	prBody := map[string]interface{}{
+		"title": title,
+		"head":  head,
+		"base":  base,
+		"body":  body,
+	}
+
+	response := c.instrument(ctx, "CreatePullRequest").Post(fmt.Sprintf("/repos/%s/%s/pulls", *config.RepositoryOwner, *config.RepositoryName), prBody)
+
+	if response.Err() != nil {
+		return nil, apierrors.NewInternalServerApiError("restClient Error creating new pull request", response.Err())
+	}
+
+	if response.StatusCode() != http.StatusCreated {
+		if response.StatusCode() == http.StatusUnprocessableEntity {
+			return nil, apierrors.NewBadRequestApiError("pull request not created")
+		}
+		return nil, apierrors.NewInternalServerApiError(fmt.Sprintf("error creating new pull request - status: %d", response.StatusCode()), response.Err())
+	}
+
+	var pullRequest models.PullRequestResponse
+	if err := json.Unmarshal(response.Bytes(), &pullRequest); err != nil {
+		return nil, apierrors.NewBadRequestApiError("error binding github pull request response")
+	}
+
+	return &pullRequest, nil
+}
+
+//MergePullRequest merges a pull request using the given merge method (merge, squash or rebase).
+//When a sha is given, github only merges the pull request if its head is still that sha.
+//This perform a PUT request
+func (c *githubClient) MergePullRequest(ctx context.Context, config *models.Configuration, number int, mergeMethod string, sha string) apierrors.ApiError {
+
+	if config.RepositoryOwner == nil || config.RepositoryName == nil || number == 0 {
+		return apierrors.NewBadRequestApiError("invalid body params")
+	}
+
+	body := map[string]interface{}{
+		"merge_method": mergeMethod,
+	}
+
+	if sha != "" {
+		body["sha"] = sha
+	}
+
+	response := c.instrument(ctx, "MergePullRequest").Put(fmt.Sprintf("/repos/%s/%s/pulls/%d/merge", *config.RepositoryOwner, *config.RepositoryName, number), body)
+
+	if response.Err() != nil {
+		return apierrors.NewInternalServerApiError("restClient Error merging pull request", response.Err())
+	}
+
+	switch response.StatusCode() {
+	case http.StatusOK:
+		return nil
+	case http.StatusConflict:
+		return apierrors.NewApiError("pull request head was modified", HeadModifiedErrorCode, http.StatusConflict, apierrors.CauseList{})
+	case http.StatusMethodNotAllowed:
+		return apierrors.NewApiError("pull request not mergeable", "conflict_error", http.StatusConflict, apierrors.CauseList{})
+	default:
+		return apierrors.NewInternalServerApiError(fmt.Sprintf("error merging pull request - status: %d", response.StatusCode()), response.Err())
+	}
+}
+
+//MergeBranch merges the head (a branch or a sha) into the base branch.
+//Returns the sha of the merge commit or an empty sha when the base already contains the head.
+//This perform a POST request
+func (c *githubClient) MergeBranch(ctx context.Context, config *models.Configuration, base string, head string, commitMessage string) (string, apierrors.ApiError) {
+
+	if config.RepositoryOwner == nil || config.RepositoryName == nil || base == "" || head == "" {
+		return "", apierrors.NewBadRequestApiError("invalid body params")
+	}
+
+	body := map[string]interface{}{
+		"base":           base,
+		"head":           head,
+		"commit_message": commitMessage,
+	}
+
+	response := c.instrument(ctx, "MergeBranch").Post(fmt.Sprintf("/repos/%s/%s/merges", *config.RepositoryOwner, *config.RepositoryName), body)
+
+	if response.Err() != nil {
+		return "", apierrors.NewInternalServerApiError("restClient Error merging branch", response.Err())
+	}
+
+	switch response.StatusCode() {
+	case http.StatusCreated:
+		var merge models.MergeResponse
+		if err := json.Unmarshal(response.Bytes(), &merge); err != nil {
+			return "", apierrors.NewBadRequestApiError("error binding github merge response")
+		}
+		return merge.Sha, nil
+	case http.StatusNoContent:
+		return "", nil
+	case http.StatusConflict:
+		return "", apierrors.NewApiError("merge conflict", "conflict_error", http.StatusConflict, apierrors.CauseList{})
+	default:
+		return "", apierrors.NewInternalServerApiError(fmt.Sprintf("error merging branch - status: %d", response.StatusCode()), response.Err())
+	}
+}
+
+//DeleteBranch deletes the branch reference.
+//This perform a DELETE request
+func (c *githubClient) DeleteBranch(ctx context.Context, config *models.Configuration, branchName string) apierrors.ApiError {
+
+	if config.RepositoryOwner == nil || config.RepositoryName == nil || branchName == "" {
+		return apierrors.NewBadRequestApiError("invalid body params")
+	}
+
+	response := c.instrument(ctx, "DeleteBranch").Delete(fmt.Sprintf("/repos/%s/%s/git/refs/heads/%s", *config.RepositoryOwner, *config.RepositoryName, branchName))
+
+	if response.Err() != nil {
+		return apierrors.NewInternalServerApiError("restClient Error deleting branch", response.Err())
+	}
+
+	if response.StatusCode() != http.StatusNoContent {
+		if response.StatusCode() == http.StatusNotFound || response.StatusCode() == http.StatusUnprocessableEntity {
+			return apierrors.NewNotFoundApiError(fmt.Sprintf("branch %s not found", branchName))
+		}
+		return apierrors.NewInternalServerApiError(fmt.Sprintf("error deleting branch - status: %d", response.StatusCode()), response.Err())
+	}
+
+	return nil
+}
+
+//FastForwardBranch moves the branch reference to the sha, which must contain the current head of the branch.
+//This perform a PATCH request
+func (c *githubClient) FastForwardBranch(ctx context.Context, config *models.Configuration, branchName string, sha string) apierrors.ApiError {
+
+	if config.RepositoryOwner == nil || config.RepositoryName == nil || branchName == "" || sha == "" {
+		return apierrors.NewBadRequestApiError("invalid body params")
+	}
+
+	body := map[string]interface{}{
+		"sha":   sha,
+		"force": false,
+	}
+
+	response := c.instrument(ctx, "FastForwardBranch").Patch(fmt.Sprintf("/repos/%s/%s/git/refs/heads/%s", *config.RepositoryOwner, *config.RepositoryName, branchName), body)
+
+	if response.Err() != nil {
+		return apierrors.NewInternalServerApiError("restClient Error fast-forwarding branch", response.Err())
+	}
+
+	switch response.StatusCode() {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnprocessableEntity:
+		return apierrors.NewApiError(fmt.Sprintf("branch %s can not be fast-forwarded to %s", branchName, sha), "conflict_error", http.StatusConflict, apierrors.CauseList{})
+	default:
+		return apierrors.NewInternalServerApiError(fmt.Sprintf("error fast-forwarding branch - status: %d", response.StatusCode()), response.Err())
+	}
+}
+
+//CompareCommits lists the commits reachable from head and not reachable from base.
+//This perform a GET request
+func (c *githubClient) CompareCommits(ctx context.Context, config *models.Configuration, base string, head string) (*models.CompareResponse, apierrors.ApiError) {
+
+	if config.RepositoryOwner == nil || config.RepositoryName == nil || base == "" || head == "" {
+		return nil, apierrors.NewBadRequestApiError("invalid body params")
+	}
+
+	response := c.instrument(ctx, "CompareCommits").Get(fmt.Sprintf("/repos/%s/%s/compare/%s...%s", *config.RepositoryOwner, *config.RepositoryName, base, head))
+
+	if response.Err() != nil {
+		return nil, apierrors.NewInternalServerApiError("restClient Error comparing commits", response.Err())
+	}
+
+	if response.StatusCode() != http.StatusOK {
+		return nil, apierrors.NewInternalServerApiError(fmt.Sprintf("error comparing commits - status: %d", response.StatusCode()), response.Err())
+	}
+
+	var compare models.CompareResponse
+	if err := json.Unmarshal(response.Bytes(), &compare); err != nil {
+		return nil, apierrors.NewBadRequestApiError("error binding github compare response")
+	}
+
+	return &compare, nil
+}
+
+//GetFileContent gets a file of the repository at the given ref.
+//The content is returned already decoded.
+//This perform a GET request
+func (c *githubClient) GetFileContent(ctx context.Context, config *models.Configuration, path string, ref string) (*models.FileContentResponse, apierrors.ApiError) {
+
+	if config.RepositoryOwner == nil || config.RepositoryName == nil || path == "" || ref == "" {
+		return nil, apierrors.NewBadRequestApiError("invalid body params")
+	}
+
+	response := c.instrument(ctx, "GetFileContent").Get(fmt.Sprintf("/repos/%s/%s/contents/%s?ref=%s", *config.RepositoryOwner, *config.RepositoryName, path, ref))
+
+	if response.Err() != nil {
+		return nil, apierrors.NewInternalServerApiError("restClient Error getting file content", response.Err())
+	}
+
+	if response.StatusCode() != http.StatusOK {
+		if response.StatusCode() == http.StatusNotFound {
+			return nil, apierrors.NewNotFoundApiError(fmt.Sprintf("file %s not found", path))
+		}
+		return nil, apierrors.NewInternalServerApiError(fmt.Sprintf("error getting file content - status: %d", response.StatusCode()), response.Err())
+	}
+
+	var file models.FileContentResponse
+	if err := json.Unmarshal(response.Bytes(), &file); err != nil {
+		return nil, apierrors.NewBadRequestApiError("error binding github file content response")
+	}
+
+	content, err := base64.StdEncoding.DecodeString(strings.Replace(file.Content, "\n", "", -1))
+
+	if err != nil {
+		return nil, apierrors.NewBadRequestApiError("error decoding github file content")
+	}
+
+	file.Content = string(content)
+
+	return &file, nil
+}
+
+//CreateTree creates a git tree with the given files on top of the base tree.
+//Returns the sha of the tree.
+//This perform a POST request
+func (c *githubClient) CreateTree(ctx context.Context, config *models.Configuration, request *models.TreeRequest) (string, apierrors.ApiError) {
+
+	if config.RepositoryOwner == nil || config.RepositoryName == nil || request == nil || request.BaseTree == "" || len(request.Tree) == 0 {
+		return "", apierrors.NewBadRequestApiError("invalid body params")
+	}
+
+	response := c.instrument(ctx, "CreateTree").Post(fmt.Sprintf("/repos/%s/%s/git/trees", *config.RepositoryOwner, *config.RepositoryName), request)
+
+	if response.Err() != nil {
+		return "", apierrors.NewInternalServerApiError("restClient Error creating tree", response.Err())
+	}
+
+	if response.StatusCode() != http.StatusCreated {
+		return "", apierrors.NewInternalServerApiError(fmt.Sprintf("error creating tree - status: %d", response.StatusCode()), response.Err())
+	}
+
+	var tree models.GitObjectResponse
+	if err := json.Unmarshal(response.Bytes(), &tree); err != nil {
+		return "", apierrors.NewBadRequestApiError("error binding github tree response")
+	}
+
+	return tree.Sha, nil
+}
+
+//CreateCommit creates a git commit of the given tree, no branch is moved to it.
+//Returns the sha of the commit.
+//This perform a POST request
+func (c *githubClient) CreateCommit(ctx context.Context, config *models.Configuration, request *models.CommitRequest) (string, apierrors.ApiError) {
+
+	if config.RepositoryOwner == nil || config.RepositoryName == nil || request == nil || request.Tree == "" || len(request.Parents) == 0 {
+		return "", apierrors.NewBadRequestApiError("invalid body params")
+	}
+
+	response := c.instrument(ctx, "CreateCommit").Post(fmt.Sprintf("/repos/%s/%s/git/commits", *config.RepositoryOwner, *config.RepositoryName), request)
+
+	if response.Err() != nil {
+		return "", apierrors.NewInternalServerApiError("restClient Error creating commit", response.Err())
+	}
+
+	if response.StatusCode() != http.StatusCreated {
+		return "", apierrors.NewInternalServerApiError(fmt.Sprintf("error creating commit - status: %d", response.StatusCode()), response.Err())
+	}
+
+	var commit models.GitObjectResponse
+	if err := json.Unmarshal(response.Bytes(), &commit); err != nil {
+		return "", apierrors.NewBadRequestApiError("error binding github commit response")
+	}
+
+	return commit.Sha, nil
+}
+
+//CreateDeployment creates a github deployment of a ref into an environment.
+//This perform a POST request
+func (c *githubClient) CreateDeployment(ctx context.Context, config *models.Configuration, request *models.DeploymentRequest) (*models.DeploymentResponse, apierrors.ApiError) {
+
+	if config.RepositoryOwner == nil || config.RepositoryName == nil || request == nil || request.Ref == "" || request.Environment == "" {
+		return nil, apierrors.NewBadRequestApiError("invalid body params")
+	}
+
+	response := c.instrument(ctx, "CreateDeployment").Post(fmt.Sprintf("/repos/%s/%s/deployments", *config.RepositoryOwner, *config.RepositoryName), request)
+
+	if response.Err() != nil {
+		return nil, apierrors.NewInternalServerApiError("restClient Error creating deployment", response.Err())
+	}
+
+	if response.StatusCode() != http.StatusCreated {
+		if response.StatusCode() == http.StatusConflict || response.StatusCode() == http.StatusUnprocessableEntity {
+			return nil, apierrors.NewBadRequestApiError("deployment not created")
+		}
+		return nil, apierrors.NewInternalServerApiError(fmt.Sprintf("error creating deployment - status: %d", response.StatusCode()), response.Err())
+	}
+
+	var deployment models.DeploymentResponse
+	if err := json.Unmarshal(response.Bytes(), &deployment); err != nil {
+		return nil, apierrors.NewBadRequestApiError("error binding github deployment response")
+	}
+
+	return &deployment, nil
+}
+
+//CreateDeploymentStatus creates a new status of a github deployment.
+//This perform a POST request
+func (c *githubClient) CreateDeploymentStatus(ctx context.Context, config *models.Configuration, deploymentID int64, state string, description string) apierrors.ApiError {
+
+	if config.RepositoryOwner == nil || config.RepositoryName == nil || deploymentID == 0 || state == "" {
+		return apierrors.NewBadRequestApiError("invalid body params")
+	}
+
+	body := map[string]interface{}{
+		"state":       state,
+		"description": description,
+	}
+
+	response := c.instrument(ctx, "CreateDeploymentStatus").Post(fmt.Sprintf("/repos/%s/%s/deployments/%d/statuses", *config.RepositoryOwner, *config.RepositoryName, deploymentID), body)
+
+	if response.Err() != nil {
+		return apierrors.NewInternalServerApiError("restClient Error creating deployment status", response.Err())
+	}
+
+	if response.StatusCode() != http.StatusCreated {
+		return apierrors.NewInternalServerApiError(fmt.Sprintf("error creating deployment status - status: %d", response.StatusCode()), response.Err())
+	}
+
+	return nil
+}
+
+//IsTeamMember checks if the user is an active member of a team of the repository owner organization.
+//This perform a GET request
+func (c *githubClient) IsTeamMember(ctx context.Context, config *models.Configuration, team string, username string) (bool, apierrors.ApiError) {
+
+	if config.RepositoryOwner == nil || team == "" || username == "" {
+		return false, apierrors.NewBadRequestApiError("invalid body params")
+	}
+
+	response := c.instrument(ctx, "IsTeamMember").Get(fmt.Sprintf("/orgs/%s/teams/%s/memberships/%s", *config.RepositoryOwner, team, username))
+
+	if response.Err() != nil {
+		return false, apierrors.NewInternalServerApiError("restClient Error getting team membership", response.Err())
+	}
+
+	//The user is not a member of the team
+	if response.StatusCode() == http.StatusNotFound {
+		return false, nil
+	}
+
+	if response.StatusCode() != http.StatusOK {
+		return false, apierrors.NewInternalServerApiError(fmt.Sprintf("error getting team membership - status: %d", response.StatusCode()), response.Err())
+	}
+
+	var membership models.TeamMembershipResponse
+	if err := json.Unmarshal(response.Bytes(), &membership); err != nil {
+		return false, apierrors.NewBadRequestApiError("error binding github team membership response")
+	}
+
+	return membership.State == "active", nil
+}
+
+//GetReleaseByTag gets the github release of a tag.
+//This perform a GET request
+func (c *githubClient) GetReleaseByTag(ctx context.Context, config *models.Configuration, tagName string) (*models.ReleaseResponse, apierrors.ApiError) {
+
+	if config.RepositoryOwner == nil || config.RepositoryName == nil || tagName == "" {
+		return nil, apierrors.NewBadRequestApiError("invalid body params")
+	}
+
+	response := c.instrument(ctx, "GetReleaseByTag").Get(fmt.Sprintf("/repos/%s/%s/releases/tags/%s", *config.RepositoryOwner, *config.RepositoryName, tagName))
+
+	if response.Err() != nil {
+		return nil, apierrors.NewInternalServerApiError("restClient Error getting release", response.Err())
+	}
+
+	if response.StatusCode() == http.StatusNotFound {
+		return nil, apierrors.NewNotFoundApiError(fmt.Sprintf("release %s not found", tagName))
+	}
+
+	if response.StatusCode() != http.StatusOK {
+		return nil, apierrors.NewInternalServerApiError(fmt.Sprintf("error getting release - status: %d", response.StatusCode()), response.Err())
+	}
+
+	var release models.ReleaseResponse
+	if err := json.Unmarshal(response.Bytes(), &release); err != nil {
+		return nil, apierrors.NewBadRequestApiError("error binding github release response")
+	}
+
+	return &release, nil
+}
+
+//UpdateRelease updates a github release, for example to turn it into a prerelease or a draft.
+//This perform a PATCH request
+func (c *githubClient) UpdateRelease(ctx context.Context, config *models.Configuration, releaseID int64, request *models.ReleaseUpdateRequest) apierrors.ApiError {
+
+	if config.RepositoryOwner == nil || config.RepositoryName == nil || releaseID == 0 || request == nil {
+		return apierrors.NewBadRequestApiError("invalid body params")
+	}
+
+	response := c.instrument(ctx, "UpdateRelease").Patch(fmt.Sprintf("/repos/%s/%s/releases/%d", *config.RepositoryOwner, *config.RepositoryName, releaseID), request)
+
+	if response.Err() != nil {
+		return apierrors.NewInternalServerApiError("restClient Error updating release", response.Err())
+	}
+
+	if response.StatusCode() != http.StatusOK {
+		return apierrors.NewInternalServerApiError(fmt.Sprintf("error updating release - status: %d", response.StatusCode()), response.Err())
+	}
+
+	return nil
+}
+
+//CheckCredentials checks that the github token is valid by getting the authenticated user.
+//This perform a GET request
+func (c *githubClient) CheckCredentials(ctx context.Context) apierrors.ApiError {
+
+	response := c.instrument(ctx, "CheckCredentials").Get("/user")
+
+	if response.Err() != nil {
+		return apierrors.NewInternalServerApiError("restClient Error getting the authenticated user", response.Err())
+	}
+
+	if response.StatusCode() == http.StatusUnauthorized {
+		return apierrors.NewApiError("invalid github credentials", "unauthorized", http.StatusUnauthorized, apierrors.CauseList{})
+	}
+
+	if response.StatusCode() != http.StatusOK {
+		return apierrors.NewInternalServerApiError(fmt.Sprintf("error getting the authenticated user - status: %d", response.StatusCode()), response.Err())
+	}
+
+	return nil
+}
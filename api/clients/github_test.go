@@ -1,13 +1,19 @@
 package clients
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"github.com/golang/mock/gomock"
 	"github.com/hbalmes/ci_cd-api/api/configs"
+	"github.com/hbalmes/ci_cd-api/api/metrics"
 	"github.com/hbalmes/ci_cd-api/api/models"
 	"github.com/hbalmes/ci_cd-api/api/models/webhook"
 	"github.com/hbalmes/ci_cd-api/api/utils"
 	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"github.com/hbalmes/ci_cd-api/api/utils/tracing"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.opentelemetry.io/otel/codes"
 	"reflect"
 	"strings"
 	"testing"
@@ -33,12 +39,10 @@ func Test_githubClient_GetBranchInformation(t *testing.T) {
 	}
 
 	getBranchResp := models.GetBranchResponse{
-		Name: "feature/pepe",
-		Commit: struct {
-			Sha string `json:"sha"`
-		}{"1245678qwertyuasdfghzxcvb"},
+		Name:      "feature/pepe",
 		Protected: false,
 	}
+	getBranchResp.Commit.Sha = "1245678qwertyuasdfghzxcvb"
 
 	statusList := []string{"workflow", "continuous-integration", "minimum-coverage", "pull-request-coverage"}
 
@@ -180,7 +184,7 @@ func Test_githubClient_GetBranchInformation(t *testing.T) {
 			c := &githubClient{
 				Client: client,
 			}
-			branchResp, error := c.GetBranchInformation(tt.args.config, tt.args.branchName)
+			branchResp, error := c.GetBranchInformation(context.Background(), tt.args.config, tt.args.branchName)
 			if !reflect.DeepEqual(branchResp, tt.expects.want) {
 				t.Errorf("GetBranchInformation() got = %v, want %v", tt.expects.want, tt.expects.error)
 			}
@@ -361,7 +365,7 @@ func Test_githubClient_ProtectBranch(t *testing.T) {
 				Client: client,
 			}
 
-			if got := c.ProtectBranch(tt.args.config, tt.args.branchConfig); !reflect.DeepEqual(got, tt.expects.error) {
+			if got := c.ProtectBranch(context.Background(), tt.args.config, tt.args.branchConfig); !reflect.DeepEqual(got, tt.expects.error) {
 				t.Errorf("ProtectBranch() = %v, want %v", got, tt.expects.error)
 			}
 		})
@@ -528,7 +532,7 @@ func Test_githubClient_CreateBranch(t *testing.T) {
 				Client: client,
 			}
 
-			if got := c.CreateBranch(tt.args.config, tt.args.branchConfig, tt.args.sha); !reflect.DeepEqual(got, tt.expects.error) {
+			if got := c.CreateBranch(context.Background(), tt.args.config, tt.args.branchConfig, tt.args.sha); !reflect.DeepEqual(got, tt.expects.error) {
 				t.Errorf("ProtectBranch() = %v, want %v", got, tt.expects.error)
 			}
 		})
@@ -610,12 +614,10 @@ func Test_githubClient_CreateGithubRef(t *testing.T) {
 	}
 
 	branchInfo := models.GetBranchResponse{
-		Name: "master",
-		Commit: struct {
-			Sha string `json:"sha"`
-		}{Sha: "234567qwertasdfghzxcvb"},
+		Name:      "master",
 		Protected: false,
 	}
+	branchInfo.Commit.Sha = "234567qwertasdfghzxcvb"
 
 	tests := []struct {
 		name             string
@@ -761,7 +763,7 @@ func Test_githubClient_CreateGithubRef(t *testing.T) {
 				Client: client,
 			}
 
-			if got := c.CreateGithubRef(tt.args.config, tt.args.branchConfig, tt.args.workflowConfig); !reflect.DeepEqual(got, tt.expects.error) {
+			if got := c.CreateGithubRef(context.Background(), tt.args.config, tt.args.branchConfig, tt.args.workflowConfig); !reflect.DeepEqual(got, tt.expects.error) {
 				t.Errorf("ProtectBranch() = %v, want %v", got, tt.expects.error)
 			}
 		})
@@ -911,7 +913,7 @@ func Test_githubClient_SetDefaultBranch(t *testing.T) {
 				Client: client,
 			}
 
-			if got := c.SetDefaultBranch(tt.args.config, tt.args.workflowConfig); !reflect.DeepEqual(got, tt.expects.error) {
+			if got := c.SetDefaultBranch(context.Background(), tt.args.config, tt.args.workflowConfig); !reflect.DeepEqual(got, tt.expects.error) {
 				t.Errorf("ProtectBranch() = %v, want %v", got, tt.expects.error)
 			}
 		})
@@ -1076,7 +1078,7 @@ func Test_githubClient_CreateStatus(t *testing.T) {
 				Client: client,
 			}
 
-			if got := c.CreateStatus(tt.args.config, tt.args.status); !reflect.DeepEqual(got, tt.expects.error) {
+			if got := c.CreateStatus(context.Background(), tt.args.config, tt.args.status); !reflect.DeepEqual(got, tt.expects.error) {
 				t.Errorf("ProtectBranch() = %v, want %v", got, tt.expects.error)
 			}
 		})
@@ -1253,7 +1255,7 @@ func Test_githubClient_UnprotectBranch(t *testing.T) {
 				Client: client,
 			}
 
-			if got := c.UnprotectBranch(tt.args.config, tt.args.branchConfig); !reflect.DeepEqual(got, tt.expects.error) {
+			if got := c.UnprotectBranch(context.Background(), tt.args.config, tt.args.branchConfig); !reflect.DeepEqual(got, tt.expects.error) {
 				t.Errorf("ProtectBranch() = %v, want %v", got, tt.expects.error)
 			}
 		})
@@ -1426,7 +1428,7 @@ func Test_githubClient_CreateIssueComment(t *testing.T) {
 				Client: client,
 			}
 
-			if got := c.CreateIssueComment(tt.args.config, tt.args.pullRequest, tt.args.issueCommentBody); !reflect.DeepEqual(got, tt.expects.error) {
+			if got := c.CreateIssueComment(context.Background(), tt.args.config, tt.args.pullRequest, tt.args.issueCommentBody); !reflect.DeepEqual(got, tt.expects.error) {
 				t.Errorf("CreateIssueComment() = %v, want %v", got, tt.expects.error)
 			}
 		})
@@ -1621,9 +1623,1961 @@ func Test_githubClient_CreateRelease(t *testing.T) {
 			c := &githubClient{
 				Client: client,
 			}
-			if got := c.CreateRelease(tt.args.config, tt.args.build); !reflect.DeepEqual(got, tt.expects.error) {
+			if got := c.CreateRelease(context.Background(), tt.args.config, tt.args.build); !reflect.DeepEqual(got, tt.expects.error) {
 				t.Errorf("CreateRelease() = %v, want %v", got, tt.expects.error)
 			}
 		})
 	}
 }
+
+func Test_githubClient_GetCollaboratorPermission(t *testing.T) {
+	type restResponse struct {
+		mockError      error
+		mockStatusCode int
+		mockBytes      []byte
+	}
+
+	type expects struct {
+		permission string
+		error      apierrors.ApiError
+	}
+
+	var cicdConfigOK = models.Configuration{
+		ID:              utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:  utils.Stringify("ci-cd_api"),
+		RepositoryOwner: utils.Stringify("hbalmes"),
+		WorkflowType:    utils.Stringify("gitflow"),
+	}
+
+	tests := []struct {
+		name         string
+		username     string
+		restResponse restResponse
+		expects      expects
+	}{
+		{
+			name: "invalid username",
+			expects: expects{
+				error: apierrors.NewBadRequestApiError("invalid body params"),
+			},
+		},
+		{
+			name:     "rest client error",
+			username: "hbalmes",
+			restResponse: restResponse{
+				mockError: errors.New("some error"),
+			},
+			expects: expects{
+				error: apierrors.NewInternalServerApiError("restClient Error getting collaborator permission", errors.New("some error")),
+			},
+		},
+		{
+			name:     "user is not a collaborator",
+			username: "hbalmes",
+			restResponse: restResponse{
+				mockStatusCode: 404,
+			},
+			expects: expects{
+				error: apierrors.NewInternalServerApiError("error getting collaborator permission", nil),
+			},
+		},
+		{
+			name:     "error binding response",
+			username: "hbalmes",
+			restResponse: restResponse{
+				mockStatusCode: 200,
+				mockBytes:      utils.GetBytes(map[string]interface{}{"permission": 1}),
+			},
+			expects: expects{
+				error: apierrors.NewBadRequestApiError("error binding github collaborator permission response"),
+			},
+		},
+		{
+			name:     "permission getted OK",
+			username: "hbalmes",
+			restResponse: restResponse{
+				mockStatusCode: 200,
+				mockBytes: utils.GetBytes(map[string]interface{}{
+					"permission": "write",
+					"user":       map[string]interface{}{"login": "hbalmes"},
+				}),
+			},
+			expects: expects{
+				permission: "write",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			client := NewMockClient(ctrl)
+			response := NewMockResponse(ctrl)
+
+			response.EXPECT().Err().Return(tt.restResponse.mockError).AnyTimes()
+			response.EXPECT().StatusCode().Return(tt.restResponse.mockStatusCode).AnyTimes()
+			response.EXPECT().Bytes().Return(tt.restResponse.mockBytes).AnyTimes()
+
+			client.EXPECT().
+				Get("/repos/hbalmes/ci-cd_api/collaborators/hbalmes/permission").
+				Return(response).
+				AnyTimes()
+
+			c := &githubClient{
+				Client: client,
+			}
+			permission, err := c.GetCollaboratorPermission(context.Background(), &cicdConfigOK, tt.username)
+			if permission != tt.expects.permission {
+				t.Errorf("GetCollaboratorPermission() got = %v, want %v", permission, tt.expects.permission)
+			}
+			if !reflect.DeepEqual(err, tt.expects.error) {
+				t.Errorf("GetCollaboratorPermission() error = %v, want %v", err, tt.expects.error)
+			}
+		})
+	}
+}
+
+func Test_githubClient_GetPullRequestsByCommit(t *testing.T) {
+	type restResponse struct {
+		mockError      error
+		mockStatusCode int
+		mockBytes      []byte
+	}
+
+	type expects struct {
+		want  []models.CommitPullRequestResponse
+		error apierrors.ApiError
+	}
+
+	var cicdConfigOK = models.Configuration{
+		ID:              utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:  utils.Stringify("ci-cd_api"),
+		RepositoryOwner: utils.Stringify("hbalmes"),
+		WorkflowType:    utils.Stringify("gitflow"),
+	}
+
+	mergedAt := "2020-05-01T10:00:00Z"
+	pullRequests := []models.CommitPullRequestResponse{{Number: 12, State: "closed", MergedAt: &mergedAt}}
+	pullRequests[0].Base.Ref = "master"
+	pullRequests[0].Head.Ref = "release/1.2"
+
+	tests := []struct {
+		name         string
+		sha          string
+		restResponse restResponse
+		expects      expects
+	}{
+		{
+			name: "invalid sha",
+			expects: expects{
+				error: apierrors.NewBadRequestApiError("invalid body params"),
+			},
+		},
+		{
+			name: "rest client error",
+			sha:  "1245678qwertyuasdfghzxcvb",
+			restResponse: restResponse{
+				mockError: errors.New("some error"),
+			},
+			expects: expects{
+				error: apierrors.NewInternalServerApiError("restClient Error getting commit pull requests", errors.New("some error")),
+			},
+		},
+		{
+			name: "commit not found",
+			sha:  "1245678qwertyuasdfghzxcvb",
+			restResponse: restResponse{
+				mockStatusCode: 422,
+			},
+			expects: expects{
+				error: apierrors.NewInternalServerApiError("error getting commit pull requests", nil),
+			},
+		},
+		{
+			name: "error binding response",
+			sha:  "1245678qwertyuasdfghzxcvb",
+			restResponse: restResponse{
+				mockStatusCode: 200,
+				mockBytes:      utils.GetBytes(map[string]interface{}{"number": 12}),
+			},
+			expects: expects{
+				error: apierrors.NewBadRequestApiError("error binding github commit pull requests response"),
+			},
+		},
+		{
+			name: "pull requests getted OK",
+			sha:  "1245678qwertyuasdfghzxcvb",
+			restResponse: restResponse{
+				mockStatusCode: 200,
+				mockBytes: utils.GetBytes([]map[string]interface{}{{
+					"number":    12,
+					"state":     "closed",
+					"merged_at": mergedAt,
+					"base":      map[string]interface{}{"ref": "master"},
+					"head":      map[string]interface{}{"ref": "release/1.2"},
+				}}),
+			},
+			expects: expects{
+				want: pullRequests,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			client := NewMockClient(ctrl)
+			response := NewMockResponse(ctrl)
+
+			response.EXPECT().Err().Return(tt.restResponse.mockError).AnyTimes()
+			response.EXPECT().StatusCode().Return(tt.restResponse.mockStatusCode).AnyTimes()
+			response.EXPECT().Bytes().Return(tt.restResponse.mockBytes).AnyTimes()
+
+			client.EXPECT().
+				Get("/repos/hbalmes/ci-cd_api/commits/1245678qwertyuasdfghzxcvb/pulls").
+				Return(response).
+				AnyTimes()
+
+			c := &githubClient{
+				Client: client,
+			}
+			got, err := c.GetPullRequestsByCommit(context.Background(), &cicdConfigOK, tt.sha)
+			if !reflect.DeepEqual(got, tt.expects.want) {
+				t.Errorf("GetPullRequestsByCommit() got = %v, want %v", got, tt.expects.want)
+			}
+			if !reflect.DeepEqual(err, tt.expects.error) {
+				t.Errorf("GetPullRequestsByCommit() error = %v, want %v", err, tt.expects.error)
+			}
+		})
+	}
+}
+
+func Test_githubClient_ListClosedPullRequests(t *testing.T) {
+	type restResponse struct {
+		mockError      error
+		mockStatusCode int
+		mockBytes      []byte
+	}
+
+	type expects struct {
+		want  []models.CommitPullRequestResponse
+		error apierrors.ApiError
+	}
+
+	var cicdConfigOK = models.Configuration{
+		ID:              utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:  utils.Stringify("ci-cd_api"),
+		RepositoryOwner: utils.Stringify("hbalmes"),
+		WorkflowType:    utils.Stringify("gitflow"),
+	}
+
+	mergedAt := "2020-05-01T10:00:00Z"
+	pullRequests := []models.CommitPullRequestResponse{{Number: 12, State: "closed", MergedAt: &mergedAt, MergeCommitSha: utils.Stringify("1245678qwertyuasdfghzxcvb")}}
+	pullRequests[0].Base.Ref = "develop"
+	pullRequests[0].Head.Ref = "feature/release-notes"
+
+	tests := []struct {
+		name         string
+		config       models.Configuration
+		restResponse restResponse
+		expects      expects
+	}{
+		{
+			name: "invalid configuration",
+			expects: expects{
+				error: apierrors.NewBadRequestApiError("invalid body params"),
+			},
+		},
+		{
+			name:   "rest client error",
+			config: cicdConfigOK,
+			restResponse: restResponse{
+				mockError: errors.New("some error"),
+			},
+			expects: expects{
+				error: apierrors.NewInternalServerApiError("restClient Error listing closed pull requests", errors.New("some error")),
+			},
+		},
+		{
+			name:   "github error",
+			config: cicdConfigOK,
+			restResponse: restResponse{
+				mockStatusCode: 502,
+			},
+			expects: expects{
+				error: apierrors.NewInternalServerApiError("error listing closed pull requests - status: 502", nil),
+			},
+		},
+		{
+			name:   "error binding response",
+			config: cicdConfigOK,
+			restResponse: restResponse{
+				mockStatusCode: 200,
+				mockBytes:      utils.GetBytes(map[string]interface{}{"number": 12}),
+			},
+			expects: expects{
+				error: apierrors.NewBadRequestApiError("error binding github closed pull requests response"),
+			},
+		},
+		{
+			name:   "pull requests listed OK",
+			config: cicdConfigOK,
+			restResponse: restResponse{
+				mockStatusCode: 200,
+				mockBytes: utils.GetBytes([]map[string]interface{}{{
+					"number":           12,
+					"state":            "closed",
+					"merged_at":        mergedAt,
+					"merge_commit_sha": "1245678qwertyuasdfghzxcvb",
+					"base":             map[string]interface{}{"ref": "develop"},
+					"head":             map[string]interface{}{"ref": "feature/release-notes"},
+				}}),
+			},
+			expects: expects{
+				want: pullRequests,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			client := NewMockClient(ctrl)
+			response := NewMockResponse(ctrl)
+
+			response.EXPECT().Err().Return(tt.restResponse.mockError).AnyTimes()
+			response.EXPECT().StatusCode().Return(tt.restResponse.mockStatusCode).AnyTimes()
+			response.EXPECT().Bytes().Return(tt.restResponse.mockBytes).AnyTimes()
+
+			client.EXPECT().
+				Get("/repos/hbalmes/ci-cd_api/pulls?state=closed&sort=updated&direction=desc&per_page=100&page=1").
+				Return(response).
+				AnyTimes()
+
+			c := &githubClient{
+				Client: client,
+			}
+			got, err := c.ListClosedPullRequests(context.Background(), &tt.config, time.Time{})
+			if !reflect.DeepEqual(got, tt.expects.want) {
+				t.Errorf("ListClosedPullRequests() got = %v, want %v", got, tt.expects.want)
+			}
+			if !reflect.DeepEqual(err, tt.expects.error) {
+				t.Errorf("ListClosedPullRequests() error = %v, want %v", err, tt.expects.error)
+			}
+		})
+	}
+}
+
+func Test_githubClient_ListClosedPullRequestsPages(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	config := models.Configuration{
+		ID:              utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:  utils.Stringify("ci-cd_api"),
+		RepositoryOwner: utils.Stringify("hbalmes"),
+	}
+
+	since := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	//Full pages of pull requests updated every hour, from the last updated
+	newPage := func(from time.Time) []map[string]interface{} {
+		page := make([]map[string]interface{}, 0)
+		for i := 0; i < 100; i++ {
+			page = append(page, map[string]interface{}{
+				"number":     i,
+				"state":      "closed",
+				"updated_at": from.Add(-time.Duration(i) * time.Hour).Format(time.RFC3339),
+			})
+		}
+		return page
+	}
+
+	client := NewMockClient(ctrl)
+
+	//The second page ends before the previous tag, so the third one is not requested
+	for i, from := range []time.Time{since.Add(150 * time.Hour), since.Add(50 * time.Hour)} {
+		response := NewMockResponse(ctrl)
+		response.EXPECT().Err().Return(nil).AnyTimes()
+		response.EXPECT().StatusCode().Return(200).AnyTimes()
+		response.EXPECT().Bytes().Return(utils.GetBytes(newPage(from))).AnyTimes()
+
+		client.EXPECT().
+			Get(fmt.Sprintf("/repos/hbalmes/ci-cd_api/pulls?state=closed&sort=updated&direction=desc&per_page=100&page=%d", i+1)).
+			Return(response).
+			Times(1)
+	}
+
+	c := &githubClient{
+		Client: client,
+	}
+
+	got, err := c.ListClosedPullRequests(context.Background(), &config, since)
+
+	if err != nil {
+		t.Errorf("ListClosedPullRequests() error = %v, want nil", err)
+	}
+	if len(got) != 200 {
+		t.Errorf("ListClosedPullRequests() got %d pull requests, want 200", len(got))
+	}
+}
+
+func Test_githubClient_CreateCommitComment(t *testing.T) {
+	type restResponse struct {
+		mockError      error
+		mockStatusCode int
+	}
+
+	var cicdConfigOK = models.Configuration{
+		ID:              utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:  utils.Stringify("ci-cd_api"),
+		RepositoryOwner: utils.Stringify("hbalmes"),
+		WorkflowType:    utils.Stringify("gitflow"),
+	}
+
+	tests := []struct {
+		name         string
+		sha          string
+		restResponse restResponse
+		error        apierrors.ApiError
+	}{
+		{
+			name:  "invalid sha",
+			error: apierrors.NewBadRequestApiError("invalid body params"),
+		},
+		{
+			name: "rest client error",
+			sha:  "1245678qwertyuasdfghzxcvb",
+			restResponse: restResponse{
+				mockError: errors.New("some error"),
+			},
+			error: apierrors.NewInternalServerApiError("restClient Error creating new commit comment", errors.New("some error")),
+		},
+		{
+			name: "github error",
+			sha:  "1245678qwertyuasdfghzxcvb",
+			restResponse: restResponse{
+				mockStatusCode: 422,
+			},
+			error: apierrors.NewInternalServerApiError("error creating new commit comment", nil),
+		},
+		{
+			name: "commit comment created",
+			sha:  "1245678qwertyuasdfghzxcvb",
+			restResponse: restResponse{
+				mockStatusCode: 201,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			client := NewMockClient(ctrl)
+			response := NewMockResponse(ctrl)
+
+			response.EXPECT().Err().Return(tt.restResponse.mockError).AnyTimes()
+			response.EXPECT().StatusCode().Return(tt.restResponse.mockStatusCode).AnyTimes()
+
+			client.EXPECT().
+				Post("/repos/hbalmes/ci-cd_api/commits/1245678qwertyuasdfghzxcvb/comments", map[string]interface{}{"body": "direct push"}).
+				Return(response).
+				AnyTimes()
+
+			c := &githubClient{
+				Client: client,
+			}
+			err := c.CreateCommitComment(context.Background(), &cicdConfigOK, tt.sha, "direct push")
+			if !reflect.DeepEqual(err, tt.error) {
+				t.Errorf("CreateCommitComment() error = %v, want %v", err, tt.error)
+			}
+		})
+	}
+}
+
+func Test_githubClient_CreatePullRequest(t *testing.T) {
+	type restResponse struct {
+		mockError      error
+		mockStatusCode int
+		mockBytes      []byte
+	}
+
+	type expects struct {
+		want  *models.PullRequestResponse
+		error apierrors.ApiError
+	}
+
+	var cicdConfigOK = models.Configuration{
+		ID:              utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:  utils.Stringify("ci-cd_api"),
+		RepositoryOwner: utils.Stringify("hbalmes"),
+		WorkflowType:    utils.Stringify("gitflow"),
+	}
+
+	tests := []struct {
+		name         string
+		head         string
+		restResponse restResponse
+		expects      expects
+	}{
+		{
+			name: "invalid head",
+			expects: expects{
+				error: apierrors.NewBadRequestApiError("invalid body params"),
+			},
+		},
+		{
+			name: "rest client error",
+			head: "master",
+			restResponse: restResponse{
+				mockError: errors.New("some error"),
+			},
+			expects: expects{
+				error: apierrors.NewInternalServerApiError("restClient Error creating new pull request", errors.New("some error")),
+			},
+		},
+		{
+			name: "pull request already exists or without commits",
+			head: "master",
+			restResponse: restResponse{
+				mockStatusCode: 422,
+			},
+			expects: expects{
+				error: apierrors.NewBadRequestApiError("pull request not created"),
+			},
+		},
+		{
+			name: "github error",
+			head: "master",
+			restResponse: restResponse{
+				mockStatusCode: 500,
+			},
+			expects: expects{
+				error: apierrors.NewInternalServerApiError("error creating new pull request - status: 500", nil),
+			},
+		},
+		{
+			name: "pull request created",
+			head: "master",
+			restResponse: restResponse{
+				mockStatusCode: 201,
+				mockBytes: utils.GetBytes(map[string]interface{}{
+					"number":   13,
+					"html_url": "https://github.com/hbalmes/ci-cd_api/pull/13",
+					"state":    "open",
+				}),
+			},
+			expects: expects{
+				want: &models.PullRequestResponse{
+					Number:  13,
+					HTMLURL: "https://github.com/hbalmes/ci-cd_api/pull/13",
+					State:   "open",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			client := NewMockClient(ctrl)
+			response := NewMockResponse(ctrl)
+
+			response.EXPECT().Err().Return(tt.restResponse.mockError).AnyTimes()
+			response.EXPECT().StatusCode().Return(tt.restResponse.mockStatusCode).AnyTimes()
+			response.EXPECT().Bytes().Return(tt.restResponse.mockBytes).AnyTimes()
+
+			client.EXPECT().
+				Post("/repos/hbalmes/ci-cd_api/pulls", gomock.Any()).
+				Return(response).
+				AnyTimes()
+
+			c := &githubClient{
+				Client: client,
+			}
+			got, err := c.CreatePullRequest(context.Background(), &cicdConfigOK, tt.head, "develop", "Back-merge", "body")
+			if !reflect.DeepEqual(got, tt.expects.want) {
+				t.Errorf("CreatePullRequest() got = %v, want %v", got, tt.expects.want)
+			}
+			if !reflect.DeepEqual(err, tt.expects.error) {
+				t.Errorf("CreatePullRequest() error = %v, want %v", err, tt.expects.error)
+			}
+		})
+	}
+}
+
+func Test_githubClient_MergePullRequest(t *testing.T) {
+	type restResponse struct {
+		mockError      error
+		mockStatusCode int
+	}
+
+	var cicdConfigOK = models.Configuration{
+		ID:              utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:  utils.Stringify("ci-cd_api"),
+		RepositoryOwner: utils.Stringify("hbalmes"),
+		WorkflowType:    utils.Stringify("gitflow"),
+	}
+
+	tests := []struct {
+		name         string
+		number       int
+		sha          string
+		restResponse restResponse
+		error        apierrors.ApiError
+	}{
+		{
+			name:  "invalid number",
+			error: apierrors.NewBadRequestApiError("invalid body params"),
+		},
+		{
+			name:   "rest client error",
+			number: 13,
+			restResponse: restResponse{
+				mockError: errors.New("some error"),
+			},
+			error: apierrors.NewInternalServerApiError("restClient Error merging pull request", errors.New("some error")),
+		},
+		{
+			name:   "not mergeable",
+			number: 13,
+			restResponse: restResponse{
+				mockStatusCode: 405,
+			},
+			error: apierrors.NewApiError("pull request not mergeable", "conflict_error", 409, apierrors.CauseList{}),
+		},
+		{
+			name:   "head modified",
+			number: 13,
+			sha:    "headsha",
+			restResponse: restResponse{
+				mockStatusCode: 409,
+			},
+			error: apierrors.NewApiError("pull request head was modified", "head_modified", 409, apierrors.CauseList{}),
+		},
+		{
+			name:   "github error",
+			number: 13,
+			restResponse: restResponse{
+				mockStatusCode: 404,
+			},
+			error: apierrors.NewInternalServerApiError("error merging pull request - status: 404", nil),
+		},
+		{
+			name:   "pull request merged",
+			number: 13,
+			restResponse: restResponse{
+				mockStatusCode: 200,
+			},
+		},
+		{
+			name:   "pull request merged at the expected head",
+			number: 13,
+			sha:    "headsha",
+			restResponse: restResponse{
+				mockStatusCode: 200,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			client := NewMockClient(ctrl)
+			response := NewMockResponse(ctrl)
+
+			response.EXPECT().Err().Return(tt.restResponse.mockError).AnyTimes()
+			response.EXPECT().StatusCode().Return(tt.restResponse.mockStatusCode).AnyTimes()
+
+			body := map[string]interface{}{"merge_method": "merge"}
+			if tt.sha != "" {
+				body["sha"] = tt.sha
+			}
+
+			client.EXPECT().
+				Put("/repos/hbalmes/ci-cd_api/pulls/13/merge", body).
+				Return(response).
+				AnyTimes()
+
+			c := &githubClient{
+				Client: client,
+			}
+			err := c.MergePullRequest(context.Background(), &cicdConfigOK, tt.number, "merge", tt.sha)
+			if !reflect.DeepEqual(err, tt.error) {
+				t.Errorf("MergePullRequest() error = %v, want %v", err, tt.error)
+			}
+		})
+	}
+}
+
+func Test_githubClient_MergeBranch(t *testing.T) {
+	type restResponse struct {
+		mockError      error
+		mockStatusCode int
+		mockBytes      []byte
+	}
+
+	var cicdConfigOK = models.Configuration{
+		ID:              utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:  utils.Stringify("ci-cd_api"),
+		RepositoryOwner: utils.Stringify("hbalmes"),
+		WorkflowType:    utils.Stringify("gitflow"),
+	}
+
+	tests := []struct {
+		name         string
+		head         string
+		restResponse restResponse
+		sha          string
+		error        apierrors.ApiError
+	}{
+		{
+			name:  "invalid head",
+			error: apierrors.NewBadRequestApiError("invalid body params"),
+		},
+		{
+			name: "rest client error",
+			head: "23456789qwertyuiasdfghjzxcvbn",
+			restResponse: restResponse{
+				mockError: errors.New("some error"),
+			},
+			error: apierrors.NewInternalServerApiError("restClient Error merging branch", errors.New("some error")),
+		},
+		{
+			name: "merge conflict",
+			head: "23456789qwertyuiasdfghjzxcvbn",
+			restResponse: restResponse{
+				mockStatusCode: 409,
+			},
+			error: apierrors.NewApiError("merge conflict", "conflict_error", 409, apierrors.CauseList{}),
+		},
+		{
+			name: "nothing to merge",
+			head: "23456789qwertyuiasdfghjzxcvbn",
+			restResponse: restResponse{
+				mockStatusCode: 204,
+			},
+		},
+		{
+			name: "github error",
+			head: "23456789qwertyuiasdfghjzxcvbn",
+			restResponse: restResponse{
+				mockStatusCode: 404,
+			},
+			error: apierrors.NewInternalServerApiError("error merging branch - status: 404", nil),
+		},
+		{
+			name: "branch merged",
+			head: "23456789qwertyuiasdfghjzxcvbn",
+			restResponse: restResponse{
+				mockStatusCode: 201,
+				mockBytes:      []byte(`{"sha": "7638417db6d59f3c431d3e1f261cc637155684cd"}`),
+			},
+			sha: "7638417db6d59f3c431d3e1f261cc637155684cd",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			client := NewMockClient(ctrl)
+			response := NewMockResponse(ctrl)
+
+			response.EXPECT().Err().Return(tt.restResponse.mockError).AnyTimes()
+			response.EXPECT().StatusCode().Return(tt.restResponse.mockStatusCode).AnyTimes()
+			response.EXPECT().Bytes().Return(tt.restResponse.mockBytes).AnyTimes()
+
+			client.EXPECT().
+				Post("/repos/hbalmes/ci-cd_api/merges", map[string]interface{}{
+					"base":           "merge-queue/develop/pr-13",
+					"head":           tt.head,
+					"commit_message": "Merge queue",
+				}).
+				Return(response).
+				AnyTimes()
+
+			c := &githubClient{
+				Client: client,
+			}
+			sha, err := c.MergeBranch(context.Background(), &cicdConfigOK, "merge-queue/develop/pr-13", tt.head, "Merge queue")
+			if !reflect.DeepEqual(err, tt.error) {
+				t.Errorf("MergeBranch() error = %v, want %v", err, tt.error)
+			}
+			if sha != tt.sha {
+				t.Errorf("MergeBranch() sha = %v, want %v", sha, tt.sha)
+			}
+		})
+	}
+}
+
+func Test_githubClient_DeleteBranch(t *testing.T) {
+	type restResponse struct {
+		mockError      error
+		mockStatusCode int
+	}
+
+	var cicdConfigOK = models.Configuration{
+		ID:              utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:  utils.Stringify("ci-cd_api"),
+		RepositoryOwner: utils.Stringify("hbalmes"),
+		WorkflowType:    utils.Stringify("gitflow"),
+	}
+
+	tests := []struct {
+		name         string
+		branch       string
+		restResponse restResponse
+		error        apierrors.ApiError
+	}{
+		{
+			name:  "invalid branch",
+			error: apierrors.NewBadRequestApiError("invalid body params"),
+		},
+		{
+			name:   "rest client error",
+			branch: "feature/queue",
+			restResponse: restResponse{
+				mockError: errors.New("some error"),
+			},
+			error: apierrors.NewInternalServerApiError("restClient Error deleting branch", errors.New("some error")),
+		},
+		{
+			name:   "branch not found",
+			branch: "feature/queue",
+			restResponse: restResponse{
+				mockStatusCode: 422,
+			},
+			error: apierrors.NewNotFoundApiError("branch feature/queue not found"),
+		},
+		{
+			name:   "github error",
+			branch: "feature/queue",
+			restResponse: restResponse{
+				mockStatusCode: 500,
+			},
+			error: apierrors.NewInternalServerApiError("error deleting branch - status: 500", nil),
+		},
+		{
+			name:   "branch deleted",
+			branch: "feature/queue",
+			restResponse: restResponse{
+				mockStatusCode: 204,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			client := NewMockClient(ctrl)
+			response := NewMockResponse(ctrl)
+
+			response.EXPECT().Err().Return(tt.restResponse.mockError).AnyTimes()
+			response.EXPECT().StatusCode().Return(tt.restResponse.mockStatusCode).AnyTimes()
+
+			client.EXPECT().
+				Delete("/repos/hbalmes/ci-cd_api/git/refs/heads/feature/queue").
+				Return(response).
+				AnyTimes()
+
+			c := &githubClient{
+				Client: client,
+			}
+			err := c.DeleteBranch(context.Background(), &cicdConfigOK, tt.branch)
+			if !reflect.DeepEqual(err, tt.error) {
+				t.Errorf("DeleteBranch() error = %v, want %v", err, tt.error)
+			}
+		})
+	}
+}
+
+func Test_githubClient_FastForwardBranch(t *testing.T) {
+	type restResponse struct {
+		mockError      error
+		mockStatusCode int
+	}
+
+	var cicdConfigOK = models.Configuration{
+		ID:              utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:  utils.Stringify("ci-cd_api"),
+		RepositoryOwner: utils.Stringify("hbalmes"),
+		WorkflowType:    utils.Stringify("gitflow"),
+	}
+
+	tests := []struct {
+		name         string
+		sha          string
+		restResponse restResponse
+		error        apierrors.ApiError
+	}{
+		{
+			name:  "invalid sha",
+			error: apierrors.NewBadRequestApiError("invalid body params"),
+		},
+		{
+			name: "rest client error",
+			sha:  "integrationsha",
+			restResponse: restResponse{
+				mockError: errors.New("some error"),
+			},
+			error: apierrors.NewInternalServerApiError("restClient Error fast-forwarding branch", errors.New("some error")),
+		},
+		{
+			name: "not a fast-forward",
+			sha:  "integrationsha",
+			restResponse: restResponse{
+				mockStatusCode: 422,
+			},
+			error: apierrors.NewApiError("branch develop can not be fast-forwarded to integrationsha", "conflict_error", 409, apierrors.CauseList{}),
+		},
+		{
+			name: "github error",
+			sha:  "integrationsha",
+			restResponse: restResponse{
+				mockStatusCode: 500,
+			},
+			error: apierrors.NewInternalServerApiError("error fast-forwarding branch - status: 500", nil),
+		},
+		{
+			name: "branch fast-forwarded",
+			sha:  "integrationsha",
+			restResponse: restResponse{
+				mockStatusCode: 200,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			client := NewMockClient(ctrl)
+			response := NewMockResponse(ctrl)
+
+			response.EXPECT().Err().Return(tt.restResponse.mockError).AnyTimes()
+			response.EXPECT().StatusCode().Return(tt.restResponse.mockStatusCode).AnyTimes()
+
+			client.EXPECT().
+				Patch("/repos/hbalmes/ci-cd_api/git/refs/heads/develop", map[string]interface{}{"sha": tt.sha, "force": false}).
+				Return(response).
+				AnyTimes()
+
+			c := &githubClient{
+				Client: client,
+			}
+			err := c.FastForwardBranch(context.Background(), &cicdConfigOK, "develop", tt.sha)
+			if !reflect.DeepEqual(err, tt.error) {
+				t.Errorf("FastForwardBranch() error = %v, want %v", err, tt.error)
+			}
+		})
+	}
+}
+
+func Test_githubClient_CompareCommits(t *testing.T) {
+	type restResponse struct {
+		mockError      error
+		mockStatusCode int
+		mockBytes      []byte
+	}
+
+	var cicdConfigOK = models.Configuration{
+		ID:              utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:  utils.Stringify("ci-cd_api"),
+		RepositoryOwner: utils.Stringify("hbalmes"),
+		WorkflowType:    utils.Stringify("gitflow"),
+	}
+
+	tests := []struct {
+		name         string
+		base         string
+		restResponse restResponse
+		commits      int
+		error        apierrors.ApiError
+	}{
+		{
+			name:  "invalid base",
+			error: apierrors.NewBadRequestApiError("invalid body params"),
+		},
+		{
+			name: "rest client error",
+			base: "basesha",
+			restResponse: restResponse{
+				mockError: errors.New("some error"),
+			},
+			error: apierrors.NewInternalServerApiError("restClient Error comparing commits", errors.New("some error")),
+		},
+		{
+			name: "github error",
+			base: "basesha",
+			restResponse: restResponse{
+				mockStatusCode: 404,
+			},
+			error: apierrors.NewInternalServerApiError("error comparing commits - status: 404", nil),
+		},
+		{
+			name: "invalid response",
+			base: "basesha",
+			restResponse: restResponse{
+				mockStatusCode: 200,
+				mockBytes:      []byte(`{"commits": "none"}`),
+			},
+			error: apierrors.NewBadRequestApiError("error binding github compare response"),
+		},
+		{
+			name: "commits compared",
+			base: "basesha",
+			restResponse: restResponse{
+				mockStatusCode: 200,
+				mockBytes:      []byte(`{"commits": [{"sha": "sha1"}, {"sha": "sha2"}]}`),
+			},
+			commits: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			client := NewMockClient(ctrl)
+			response := NewMockResponse(ctrl)
+
+			response.EXPECT().Err().Return(tt.restResponse.mockError).AnyTimes()
+			response.EXPECT().StatusCode().Return(tt.restResponse.mockStatusCode).AnyTimes()
+			response.EXPECT().Bytes().Return(tt.restResponse.mockBytes).AnyTimes()
+
+			client.EXPECT().
+				Get("/repos/hbalmes/ci-cd_api/compare/basesha...headsha").
+				Return(response).
+				AnyTimes()
+
+			c := &githubClient{
+				Client: client,
+			}
+			compare, err := c.CompareCommits(context.Background(), &cicdConfigOK, tt.base, "headsha")
+			if !reflect.DeepEqual(err, tt.error) {
+				t.Errorf("CompareCommits() error = %v, want %v", err, tt.error)
+			}
+			if err == nil && len(compare.Commits) != tt.commits {
+				t.Errorf("CompareCommits() commits = %v, want %v", len(compare.Commits), tt.commits)
+			}
+		})
+	}
+}
+
+func Test_githubClient_GetFileContent(t *testing.T) {
+	type restResponse struct {
+		mockError      error
+		mockStatusCode int
+		mockBytes      []byte
+	}
+
+	var cicdConfigOK = models.Configuration{
+		ID:              utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:  utils.Stringify("ci-cd_api"),
+		RepositoryOwner: utils.Stringify("hbalmes"),
+		WorkflowType:    utils.Stringify("gitflow"),
+	}
+
+	tests := []struct {
+		name         string
+		path         string
+		restResponse restResponse
+		content      string
+		error        apierrors.ApiError
+	}{
+		{
+			name:  "invalid path",
+			error: apierrors.NewBadRequestApiError("invalid body params"),
+		},
+		{
+			name: "rest client error",
+			path: "CHANGELOG.md",
+			restResponse: restResponse{
+				mockError: errors.New("some error"),
+			},
+			error: apierrors.NewInternalServerApiError("restClient Error getting file content", errors.New("some error")),
+		},
+		{
+			name: "file not found",
+			path: "CHANGELOG.md",
+			restResponse: restResponse{
+				mockStatusCode: 404,
+			},
+			error: apierrors.NewNotFoundApiError("file CHANGELOG.md not found"),
+		},
+		{
+			name: "github error",
+			path: "CHANGELOG.md",
+			restResponse: restResponse{
+				mockStatusCode: 500,
+			},
+			error: apierrors.NewInternalServerApiError("error getting file content - status: 500", nil),
+		},
+		{
+			name: "invalid content",
+			path: "CHANGELOG.md",
+			restResponse: restResponse{
+				mockStatusCode: 200,
+				mockBytes:      []byte(`{"sha": "filesha", "encoding": "base64", "content": "not base64!"}`),
+			},
+			error: apierrors.NewBadRequestApiError("error decoding github file content"),
+		},
+		{
+			name: "file content decoded",
+			path: "CHANGELOG.md",
+			restResponse: restResponse{
+				mockStatusCode: 200,
+				mockBytes:      []byte(`{"sha": "filesha", "encoding": "base64", "content": "IyBDaGFu\nZ2Vsb2cK\n"}`),
+			},
+			content: "# Changelog\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			client := NewMockClient(ctrl)
+			response := NewMockResponse(ctrl)
+
+			response.EXPECT().Err().Return(tt.restResponse.mockError).AnyTimes()
+			response.EXPECT().StatusCode().Return(tt.restResponse.mockStatusCode).AnyTimes()
+			response.EXPECT().Bytes().Return(tt.restResponse.mockBytes).AnyTimes()
+
+			client.EXPECT().
+				Get("/repos/hbalmes/ci-cd_api/contents/CHANGELOG.md?ref=master").
+				Return(response).
+				AnyTimes()
+
+			c := &githubClient{
+				Client: client,
+			}
+			file, err := c.GetFileContent(context.Background(), &cicdConfigOK, tt.path, "master")
+			if !reflect.DeepEqual(err, tt.error) {
+				t.Errorf("GetFileContent() error = %v, want %v", err, tt.error)
+			}
+			if err == nil && (file.Content != tt.content || file.Sha != "filesha") {
+				t.Errorf("GetFileContent() file = %v, want content %v", file, tt.content)
+			}
+		})
+	}
+}
+
+func Test_githubClient_CreateTree(t *testing.T) {
+	type restResponse struct {
+		mockError      error
+		mockStatusCode int
+		mockBytes      []byte
+	}
+
+	var cicdConfigOK = models.Configuration{
+		ID:              utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:  utils.Stringify("ci-cd_api"),
+		RepositoryOwner: utils.Stringify("hbalmes"),
+		WorkflowType:    utils.Stringify("gitflow"),
+	}
+
+	request := models.TreeRequest{
+		BaseTree: "basetreesha",
+		Tree: []models.TreeEntry{
+			{Path: "VERSION", Mode: models.TreeEntryModeFile, Type: models.TreeEntryTypeBlob, Content: "1.3.0\n"},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		request      models.TreeRequest
+		restResponse restResponse
+		sha          string
+		error        apierrors.ApiError
+	}{
+		{
+			name:    "without files",
+			request: models.TreeRequest{BaseTree: "basetreesha"},
+			error:   apierrors.NewBadRequestApiError("invalid body params"),
+		},
+		{
+			name:    "rest client error",
+			request: request,
+			restResponse: restResponse{
+				mockError: errors.New("some error"),
+			},
+			error: apierrors.NewInternalServerApiError("restClient Error creating tree", errors.New("some error")),
+		},
+		{
+			name:    "github error",
+			request: request,
+			restResponse: restResponse{
+				mockStatusCode: 422,
+			},
+			error: apierrors.NewInternalServerApiError("error creating tree - status: 422", nil),
+		},
+		{
+			name:    "invalid response",
+			request: request,
+			restResponse: restResponse{
+				mockStatusCode: 201,
+				mockBytes:      []byte(`{"sha": 1}`),
+			},
+			error: apierrors.NewBadRequestApiError("error binding github tree response"),
+		},
+		{
+			name:    "tree created",
+			request: request,
+			restResponse: restResponse{
+				mockStatusCode: 201,
+				mockBytes:      []byte(`{"sha": "treesha"}`),
+			},
+			sha: "treesha",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			client := NewMockClient(ctrl)
+			response := NewMockResponse(ctrl)
+
+			response.EXPECT().Err().Return(tt.restResponse.mockError).AnyTimes()
+			response.EXPECT().StatusCode().Return(tt.restResponse.mockStatusCode).AnyTimes()
+			response.EXPECT().Bytes().Return(tt.restResponse.mockBytes).AnyTimes()
+
+			client.EXPECT().
+				Post("/repos/hbalmes/ci-cd_api/git/trees", &tt.request).
+				Return(response).
+				AnyTimes()
+
+			c := &githubClient{
+				Client: client,
+			}
+			sha, err := c.CreateTree(context.Background(), &cicdConfigOK, &tt.request)
+			if !reflect.DeepEqual(err, tt.error) {
+				t.Errorf("CreateTree() error = %v, want %v", err, tt.error)
+			}
+			if sha != tt.sha {
+				t.Errorf("CreateTree() sha = %v, want %v", sha, tt.sha)
+			}
+		})
+	}
+}
+
+func Test_githubClient_CreateCommit(t *testing.T) {
+	type restResponse struct {
+		mockError      error
+		mockStatusCode int
+		mockBytes      []byte
+	}
+
+	var cicdConfigOK = models.Configuration{
+		ID:              utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:  utils.Stringify("ci-cd_api"),
+		RepositoryOwner: utils.Stringify("hbalmes"),
+		WorkflowType:    utils.Stringify("gitflow"),
+	}
+
+	request := models.CommitRequest{
+		Message: "Release v1.3.0",
+		Tree:    "treesha",
+		Parents: []string{"buildsha"},
+	}
+
+	tests := []struct {
+		name         string
+		request      models.CommitRequest
+		restResponse restResponse
+		sha          string
+		error        apierrors.ApiError
+	}{
+		{
+			name:    "without parents",
+			request: models.CommitRequest{Tree: "treesha"},
+			error:   apierrors.NewBadRequestApiError("invalid body params"),
+		},
+		{
+			name:    "rest client error",
+			request: request,
+			restResponse: restResponse{
+				mockError: errors.New("some error"),
+			},
+			error: apierrors.NewInternalServerApiError("restClient Error creating commit", errors.New("some error")),
+		},
+		{
+			name:    "github error",
+			request: request,
+			restResponse: restResponse{
+				mockStatusCode: 422,
+			},
+			error: apierrors.NewInternalServerApiError("error creating commit - status: 422", nil),
+		},
+		{
+			name:    "invalid response",
+			request: request,
+			restResponse: restResponse{
+				mockStatusCode: 201,
+				mockBytes:      []byte(`{"sha": 1}`),
+			},
+			error: apierrors.NewBadRequestApiError("error binding github commit response"),
+		},
+		{
+			name:    "commit created",
+			request: request,
+			restResponse: restResponse{
+				mockStatusCode: 201,
+				mockBytes:      []byte(`{"sha": "commitsha"}`),
+			},
+			sha: "commitsha",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			client := NewMockClient(ctrl)
+			response := NewMockResponse(ctrl)
+
+			response.EXPECT().Err().Return(tt.restResponse.mockError).AnyTimes()
+			response.EXPECT().StatusCode().Return(tt.restResponse.mockStatusCode).AnyTimes()
+			response.EXPECT().Bytes().Return(tt.restResponse.mockBytes).AnyTimes()
+
+			client.EXPECT().
+				Post("/repos/hbalmes/ci-cd_api/git/commits", &tt.request).
+				Return(response).
+				AnyTimes()
+
+			c := &githubClient{
+				Client: client,
+			}
+			sha, err := c.CreateCommit(context.Background(), &cicdConfigOK, &tt.request)
+			if !reflect.DeepEqual(err, tt.error) {
+				t.Errorf("CreateCommit() error = %v, want %v", err, tt.error)
+			}
+			if sha != tt.sha {
+				t.Errorf("CreateCommit() sha = %v, want %v", sha, tt.sha)
+			}
+		})
+	}
+}
+
+func Test_githubClient_CreateDeployment(t *testing.T) {
+	type restResponse struct {
+		mockError      error
+		mockStatusCode int
+		mockBytes      []byte
+	}
+
+	var cicdConfigOK = models.Configuration{
+		ID:              utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:  utils.Stringify("ci-cd_api"),
+		RepositoryOwner: utils.Stringify("hbalmes"),
+		WorkflowType:    utils.Stringify("gitflow"),
+	}
+
+	tests := []struct {
+		name         string
+		request      *models.DeploymentRequest
+		restResponse restResponse
+		deploymentID int64
+		error        apierrors.ApiError
+	}{
+		{
+			name:    "invalid environment",
+			request: &models.DeploymentRequest{Ref: "buildsha"},
+			error:   apierrors.NewBadRequestApiError("invalid body params"),
+		},
+		{
+			name:    "rest client error",
+			request: &models.DeploymentRequest{Ref: "buildsha", Environment: "staging"},
+			restResponse: restResponse{
+				mockError: errors.New("some error"),
+			},
+			error: apierrors.NewInternalServerApiError("restClient Error creating deployment", errors.New("some error")),
+		},
+		{
+			name:    "deployment not created",
+			request: &models.DeploymentRequest{Ref: "buildsha", Environment: "staging"},
+			restResponse: restResponse{
+				mockStatusCode: 409,
+			},
+			error: apierrors.NewBadRequestApiError("deployment not created"),
+		},
+		{
+			name:    "github error",
+			request: &models.DeploymentRequest{Ref: "buildsha", Environment: "staging"},
+			restResponse: restResponse{
+				mockStatusCode: 500,
+			},
+			error: apierrors.NewInternalServerApiError("error creating deployment - status: 500", nil),
+		},
+		{
+			name:    "invalid response",
+			request: &models.DeploymentRequest{Ref: "buildsha", Environment: "staging"},
+			restResponse: restResponse{
+				mockStatusCode: 201,
+				mockBytes:      []byte(`{"id": "none"}`),
+			},
+			error: apierrors.NewBadRequestApiError("error binding github deployment response"),
+		},
+		{
+			name:    "deployment created",
+			request: &models.DeploymentRequest{Ref: "buildsha", Environment: "staging"},
+			restResponse: restResponse{
+				mockStatusCode: 201,
+				mockBytes:      []byte(`{"id": 99, "sha": "buildsha", "environment": "staging"}`),
+			},
+			deploymentID: 99,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			client := NewMockClient(ctrl)
+			response := NewMockResponse(ctrl)
+
+			response.EXPECT().Err().Return(tt.restResponse.mockError).AnyTimes()
+			response.EXPECT().StatusCode().Return(tt.restResponse.mockStatusCode).AnyTimes()
+			response.EXPECT().Bytes().Return(tt.restResponse.mockBytes).AnyTimes()
+
+			client.EXPECT().
+				Post("/repos/hbalmes/ci-cd_api/deployments", tt.request).
+				Return(response).
+				AnyTimes()
+
+			c := &githubClient{
+				Client: client,
+			}
+			deployment, err := c.CreateDeployment(context.Background(), &cicdConfigOK, tt.request)
+			if !reflect.DeepEqual(err, tt.error) {
+				t.Errorf("CreateDeployment() error = %v, want %v", err, tt.error)
+			}
+			if err == nil && deployment.ID != tt.deploymentID {
+				t.Errorf("CreateDeployment() id = %v, want %v", deployment.ID, tt.deploymentID)
+			}
+		})
+	}
+}
+
+func Test_githubClient_CreateDeploymentStatus(t *testing.T) {
+	type restResponse struct {
+		mockError      error
+		mockStatusCode int
+	}
+
+	var cicdConfigOK = models.Configuration{
+		ID:              utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:  utils.Stringify("ci-cd_api"),
+		RepositoryOwner: utils.Stringify("hbalmes"),
+		WorkflowType:    utils.Stringify("gitflow"),
+	}
+
+	tests := []struct {
+		name         string
+		state        string
+		restResponse restResponse
+		error        apierrors.ApiError
+	}{
+		{
+			name:  "invalid state",
+			error: apierrors.NewBadRequestApiError("invalid body params"),
+		},
+		{
+			name:  "rest client error",
+			state: "queued",
+			restResponse: restResponse{
+				mockError: errors.New("some error"),
+			},
+			error: apierrors.NewInternalServerApiError("restClient Error creating deployment status", errors.New("some error")),
+		},
+		{
+			name:  "github error",
+			state: "queued",
+			restResponse: restResponse{
+				mockStatusCode: 422,
+			},
+			error: apierrors.NewInternalServerApiError("error creating deployment status - status: 422", nil),
+		},
+		{
+			name:  "status created",
+			state: "queued",
+			restResponse: restResponse{
+				mockStatusCode: 201,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			client := NewMockClient(ctrl)
+			response := NewMockResponse(ctrl)
+
+			response.EXPECT().Err().Return(tt.restResponse.mockError).AnyTimes()
+			response.EXPECT().StatusCode().Return(tt.restResponse.mockStatusCode).AnyTimes()
+
+			client.EXPECT().
+				Post("/repos/hbalmes/ci-cd_api/deployments/99/statuses", map[string]interface{}{"state": tt.state, "description": "Deploy v1.3.0 to staging"}).
+				Return(response).
+				AnyTimes()
+
+			c := &githubClient{
+				Client: client,
+			}
+			if err := c.CreateDeploymentStatus(context.Background(), &cicdConfigOK, 99, tt.state, "Deploy v1.3.0 to staging"); !reflect.DeepEqual(err, tt.error) {
+				t.Errorf("CreateDeploymentStatus() error = %v, want %v", err, tt.error)
+			}
+		})
+	}
+}
+
+func Test_githubClient_IsTeamMember(t *testing.T) {
+	type restResponse struct {
+		mockError      error
+		mockStatusCode int
+		mockBytes      []byte
+	}
+
+	var cicdConfigOK = models.Configuration{
+		ID:              utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:  utils.Stringify("ci-cd_api"),
+		RepositoryOwner: utils.Stringify("hbalmes"),
+		WorkflowType:    utils.Stringify("gitflow"),
+	}
+
+	tests := []struct {
+		name         string
+		team         string
+		restResponse restResponse
+		member       bool
+		error        apierrors.ApiError
+	}{
+		{
+			name:  "invalid team",
+			error: apierrors.NewBadRequestApiError("invalid body params"),
+		},
+		{
+			name: "rest client error",
+			team: "release-managers",
+			restResponse: restResponse{
+				mockError: errors.New("some error"),
+			},
+			error: apierrors.NewInternalServerApiError("restClient Error getting team membership", errors.New("some error")),
+		},
+		{
+			name: "not a member",
+			team: "release-managers",
+			restResponse: restResponse{
+				mockStatusCode: 404,
+			},
+		},
+		{
+			name: "github error",
+			team: "release-managers",
+			restResponse: restResponse{
+				mockStatusCode: 403,
+			},
+			error: apierrors.NewInternalServerApiError("error getting team membership - status: 403", nil),
+		},
+		{
+			name: "invalid response",
+			team: "release-managers",
+			restResponse: restResponse{
+				mockStatusCode: 200,
+				mockBytes:      []byte(`{"state": 1}`),
+			},
+			error: apierrors.NewBadRequestApiError("error binding github team membership response"),
+		},
+		{
+			name: "pending invitation",
+			team: "release-managers",
+			restResponse: restResponse{
+				mockStatusCode: 200,
+				mockBytes:      []byte(`{"role": "member", "state": "pending"}`),
+			},
+		},
+		{
+			name: "active member",
+			team: "release-managers",
+			restResponse: restResponse{
+				mockStatusCode: 200,
+				mockBytes:      []byte(`{"role": "member", "state": "active"}`),
+			},
+			member: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			client := NewMockClient(ctrl)
+			response := NewMockResponse(ctrl)
+
+			response.EXPECT().Err().Return(tt.restResponse.mockError).AnyTimes()
+			response.EXPECT().StatusCode().Return(tt.restResponse.mockStatusCode).AnyTimes()
+			response.EXPECT().Bytes().Return(tt.restResponse.mockBytes).AnyTimes()
+
+			client.EXPECT().
+				Get("/orgs/hbalmes/teams/release-managers/memberships/hbalmes").
+				Return(response).
+				AnyTimes()
+
+			c := &githubClient{
+				Client: client,
+			}
+			member, err := c.IsTeamMember(context.Background(), &cicdConfigOK, tt.team, "hbalmes")
+			if !reflect.DeepEqual(err, tt.error) {
+				t.Errorf("IsTeamMember() error = %v, want %v", err, tt.error)
+			}
+			if member != tt.member {
+				t.Errorf("IsTeamMember() = %v, want %v", member, tt.member)
+			}
+		})
+	}
+}
+
+func Test_githubClient_GetReleaseByTag(t *testing.T) {
+	type restResponse struct {
+		mockError      error
+		mockStatusCode int
+		mockBytes      []byte
+	}
+
+	var cicdConfigOK = models.Configuration{
+		ID:              utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:  utils.Stringify("ci-cd_api"),
+		RepositoryOwner: utils.Stringify("hbalmes"),
+		WorkflowType:    utils.Stringify("gitflow"),
+	}
+
+	tests := []struct {
+		name         string
+		tag          string
+		restResponse restResponse
+		release      *models.ReleaseResponse
+		error        apierrors.ApiError
+	}{
+		{
+			name:  "invalid tag",
+			error: apierrors.NewBadRequestApiError("invalid body params"),
+		},
+		{
+			name: "rest client error",
+			tag:  "v1.3.0",
+			restResponse: restResponse{
+				mockError: errors.New("some error"),
+			},
+			error: apierrors.NewInternalServerApiError("restClient Error getting release", errors.New("some error")),
+		},
+		{
+			name: "release not found",
+			tag:  "v1.3.0",
+			restResponse: restResponse{
+				mockStatusCode: 404,
+			},
+			error: apierrors.NewNotFoundApiError("release v1.3.0 not found"),
+		},
+		{
+			name: "github error",
+			tag:  "v1.3.0",
+			restResponse: restResponse{
+				mockStatusCode: 500,
+			},
+			error: apierrors.NewInternalServerApiError("error getting release - status: 500", nil),
+		},
+		{
+			name: "invalid response",
+			tag:  "v1.3.0",
+			restResponse: restResponse{
+				mockStatusCode: 200,
+				mockBytes:      []byte(`{"id": "99"}`),
+			},
+			error: apierrors.NewBadRequestApiError("error binding github release response"),
+		},
+		{
+			name: "release found",
+			tag:  "v1.3.0",
+			restResponse: restResponse{
+				mockStatusCode: 200,
+				mockBytes:      []byte(`{"id": 99, "tag_name": "v1.3.0", "draft": false, "prerelease": false}`),
+			},
+			release: &models.ReleaseResponse{ID: 99, TagName: "v1.3.0"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			client := NewMockClient(ctrl)
+			response := NewMockResponse(ctrl)
+
+			response.EXPECT().Err().Return(tt.restResponse.mockError).AnyTimes()
+			response.EXPECT().StatusCode().Return(tt.restResponse.mockStatusCode).AnyTimes()
+			response.EXPECT().Bytes().Return(tt.restResponse.mockBytes).AnyTimes()
+
+			client.EXPECT().
+				Get("/repos/hbalmes/ci-cd_api/releases/tags/v1.3.0").
+				Return(response).
+				AnyTimes()
+
+			c := &githubClient{
+				Client: client,
+			}
+			release, err := c.GetReleaseByTag(context.Background(), &cicdConfigOK, tt.tag)
+			if !reflect.DeepEqual(err, tt.error) {
+				t.Errorf("GetReleaseByTag() error = %v, want %v", err, tt.error)
+			}
+			if !reflect.DeepEqual(release, tt.release) {
+				t.Errorf("GetReleaseByTag() = %v, want %v", release, tt.release)
+			}
+		})
+	}
+}
+
+func Test_githubClient_UpdateRelease(t *testing.T) {
+	type restResponse struct {
+		mockError      error
+		mockStatusCode int
+	}
+
+	var cicdConfigOK = models.Configuration{
+		ID:              utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:  utils.Stringify("ci-cd_api"),
+		RepositoryOwner: utils.Stringify("hbalmes"),
+		WorkflowType:    utils.Stringify("gitflow"),
+	}
+
+	prerelease := true
+	request := &models.ReleaseUpdateRequest{Prerelease: &prerelease}
+
+	tests := []struct {
+		name         string
+		releaseID    int64
+		restResponse restResponse
+		error        apierrors.ApiError
+	}{
+		{
+			name:  "invalid release id",
+			error: apierrors.NewBadRequestApiError("invalid body params"),
+		},
+		{
+			name:      "rest client error",
+			releaseID: 99,
+			restResponse: restResponse{
+				mockError: errors.New("some error"),
+			},
+			error: apierrors.NewInternalServerApiError("restClient Error updating release", errors.New("some error")),
+		},
+		{
+			name:      "github error",
+			releaseID: 99,
+			restResponse: restResponse{
+				mockStatusCode: 422,
+			},
+			error: apierrors.NewInternalServerApiError("error updating release - status: 422", nil),
+		},
+		{
+			name:      "release updated",
+			releaseID: 99,
+			restResponse: restResponse{
+				mockStatusCode: 200,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			client := NewMockClient(ctrl)
+			response := NewMockResponse(ctrl)
+
+			response.EXPECT().Err().Return(tt.restResponse.mockError).AnyTimes()
+			response.EXPECT().StatusCode().Return(tt.restResponse.mockStatusCode).AnyTimes()
+
+			client.EXPECT().
+				Patch("/repos/hbalmes/ci-cd_api/releases/99", request).
+				Return(response).
+				AnyTimes()
+
+			c := &githubClient{
+				Client: client,
+			}
+			if err := c.UpdateRelease(context.Background(), &cicdConfigOK, tt.releaseID, request); !reflect.DeepEqual(err, tt.error) {
+				t.Errorf("UpdateRelease() error = %v, want %v", err, tt.error)
+			}
+		})
+	}
+}
+
+func Test_githubClient_CheckCredentials(t *testing.T) {
+	type restResponse struct {
+		mockError      error
+		mockStatusCode int
+	}
+
+	tests := []struct {
+		name         string
+		restResponse restResponse
+		error        apierrors.ApiError
+	}{
+		{
+			name: "rest client error",
+			restResponse: restResponse{
+				mockError: errors.New("some error"),
+			},
+			error: apierrors.NewInternalServerApiError("restClient Error getting the authenticated user", errors.New("some error")),
+		},
+		{
+			name: "invalid credentials",
+			restResponse: restResponse{
+				mockStatusCode: 401,
+			},
+			error: apierrors.NewApiError("invalid github credentials", "unauthorized", 401, apierrors.CauseList{}),
+		},
+		{
+			name: "github error",
+			restResponse: restResponse{
+				mockStatusCode: 500,
+			},
+			error: apierrors.NewInternalServerApiError("error getting the authenticated user - status: 500", nil),
+		},
+		{
+			name: "valid credentials",
+			restResponse: restResponse{
+				mockStatusCode: 200,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			client := NewMockClient(ctrl)
+			response := NewMockResponse(ctrl)
+
+			response.EXPECT().Err().Return(tt.restResponse.mockError).AnyTimes()
+			response.EXPECT().StatusCode().Return(tt.restResponse.mockStatusCode).AnyTimes()
+
+			client.EXPECT().
+				Get("/user").
+				Return(response).
+				Times(1)
+
+			c := &githubClient{
+				Client: client,
+			}
+			if err := c.CheckCredentials(context.Background()); !reflect.DeepEqual(err, tt.error) {
+				t.Errorf("CheckCredentials() error = %v, want %v", err, tt.error)
+			}
+		})
+	}
+}
+
+func Test_instrumentedClient(t *testing.T) {
+	exporter := tracing.SetupInMemory()
+
+	tests := []struct {
+		name           string
+		mockError      error
+		mockStatusCode int
+		statusCode     string
+		spanStatus     codes.Code
+	}{
+		{
+			name:           "request performed",
+			mockStatusCode: 404,
+			statusCode:     "404",
+			spanStatus:     codes.Unset,
+		},
+		{
+			name:           "request failed on github",
+			mockStatusCode: 502,
+			statusCode:     "502",
+			spanStatus:     codes.Error,
+		},
+		{
+			name:       "request not performed",
+			mockError:  errors.New("some error"),
+			statusCode: "0",
+			spanStatus: codes.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			defer exporter.Reset()
+
+			client := NewMockClient(ctrl)
+			response := NewMockResponse(ctrl)
+
+			response.EXPECT().Err().Return(tt.mockError).AnyTimes()
+			response.EXPECT().StatusCode().Return(tt.mockStatusCode).AnyTimes()
+
+			client.EXPECT().
+				Get("/repos/hbalmes/ci-cd_api/releases/tags/v1.0.0").
+				Return(response).
+				Times(1)
+
+			c := &githubClient{
+				Client: client,
+			}
+
+			counter := metrics.GithubRequests.WithLabelValues("GetReleaseByTag", tt.statusCode)
+			before := testutil.ToFloat64(counter)
+
+			if got := c.instrument(context.Background(), "GetReleaseByTag").Get("/repos/hbalmes/ci-cd_api/releases/tags/v1.0.0"); got != response {
+				t.Errorf("Get() = %v, want %v", got, response)
+			}
+
+			if got := testutil.ToFloat64(counter) - before; got != 1 {
+				t.Errorf("github requests counted = %v, want 1", got)
+			}
+
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("spans recorded = %v, want 1", len(spans))
+			}
+			if spans[0].Name != "GithubClient.GetReleaseByTag" {
+				t.Errorf("span name = %v, want GithubClient.GetReleaseByTag", spans[0].Name)
+			}
+			if spans[0].Status.Code != tt.spanStatus {
+				t.Errorf("span status = %v, want %v", spans[0].Status.Code, tt.spanStatus)
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 type Client interface {
 	Post(string, interface{}) Response
 	Put(string, interface{}) Response
+	Patch(string, interface{}) Response
 	Get(string) Response
 	Delete(string) Response
 }
@@ -32,6 +33,11 @@ func (c *client) Put(url string, body interface{}) Response {
 	return newResponse(r)
 }
 
+func (c *client) Patch(url string, body interface{}) Response {
+	r := c.RestClient.Patch(url, body)
+	return newResponse(r)
+}
+
 func (c *client) Delete(url string) Response {
 	r := c.RestClient.Delete(url)
 	return newResponse(r)
@@ -60,6 +60,20 @@ func (mr *MockClientMockRecorder) Put(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Put", reflect.TypeOf((*MockClient)(nil).Put), arg0, arg1)
 }
 
+// Patch mocks base method
+func (m *MockClient) Patch(arg0 string, arg1 interface{}) Response {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Patch", arg0, arg1)
+	ret0, _ := ret[0].(Response)
+	return ret0
+}
+
+// Patch indicates an expected call of Patch
+func (mr *MockClientMockRecorder) Patch(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Patch", reflect.TypeOf((*MockClient)(nil).Patch), arg0, arg1)
+}
+
 // Get mocks base method
 func (m *MockClient) Get(arg0 string) Response {
 	m.ctrl.T.Helper()
@@ -0,0 +1,262 @@
+package configs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"gopkg.in/yaml.v3"
+)
+
+//Traces exporters
+const (
+	TracesExporterOTLP   = "otlp"
+	TracesExporterStdout = "stdout"
+	TracesExporterNone   = "none"
+)
+
+//Config represents every setting of the API.
+//It is loaded once on startup by Load and passed to the storage, the clients and the services.
+type Config struct {
+	Server   ServerConfig   `yaml:"server"`
+	Database DatabaseConfig `yaml:"database"`
+	Github   GithubConfig   `yaml:"github"`
+	Log      LogConfig      `yaml:"log"`
+	Tracing  TracingConfig  `yaml:"tracing"`
+	Auth     AuthConfig     `yaml:"auth"`
+}
+
+//ServerConfig represents the settings of the http server
+type ServerConfig struct {
+	Port string `yaml:"port"`
+	//ShutdownTimeout is how long the in-flight requests and jobs are waited for on shutdown
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+}
+
+//DatabaseConfig represents the settings of the mysql connection
+type DatabaseConfig struct {
+	User         string `yaml:"user"`
+	Password     string `yaml:"password"`
+	PasswordFile string `yaml:"password_file"`
+	Host         string `yaml:"host"`
+	Name         string `yaml:"name"`
+	//ConnectionAttempts is how many times the connection is tried on startup
+	ConnectionAttempts int `yaml:"connection_attempts"`
+}
+
+//GithubConfig represents the settings of the github api client
+type GithubConfig struct {
+	BaseURL   string `yaml:"base_url"`
+	Token     string `yaml:"token"`
+	TokenFile string `yaml:"token_file"`
+	//WebhookSecret is the secret the github webhooks are signed with, so the forged deliveries are rejected
+	WebhookSecret     string `yaml:"webhook_secret"`
+	WebhookSecretFile string `yaml:"webhook_secret_file"`
+}
+
+//LogConfig represents the settings of the logs
+type LogConfig struct {
+	Level string `yaml:"level"`
+}
+
+//TracingConfig represents the settings of the traces
+type TracingConfig struct {
+	//Exporter is where the traces are exported, one of otlp, stdout or none.
+	//It defaults to otlp when there is an OTLP endpoint and to none otherwise.
+	Exporter string `yaml:"exporter"`
+	//OTLPEndpoint is the url of the OTLP collector, the standard OTEL_EXPORTER_OTLP_* env vars are used when empty
+	OTLPEndpoint string `yaml:"otlp_endpoint"`
+}
+
+//AuthConfig represents the settings of the api authentication
+type AuthConfig struct {
+	//BootstrapToken is an admin api token which is not stored, to mint the first api tokens
+	BootstrapToken     string `yaml:"bootstrap_token"`
+	BootstrapTokenFile string `yaml:"bootstrap_token_file"`
+}
+
+//minBootstrapTokenLength is the minimum length of the bootstrap token, so it can not be guessed
+const minBootstrapTokenLength = 32
+
+//envOverrides binds every env var to the setting it overrides
+var envOverrides = []struct {
+	name string
+	set  func(c *Config, value string) error
+}{
+	{"PORT", func(c *Config, v string) error { c.Server.Port = v; return nil }},
+	{"SHUTDOWN_TIMEOUT", func(c *Config, v string) (err error) { c.Server.ShutdownTimeout, err = time.ParseDuration(v); return }},
+	{"DBUSER", func(c *Config, v string) error { c.Database.User = v; return nil }},
+	{"DBPASS", func(c *Config, v string) error { c.Database.Password = v; return nil }},
+	{"DBPASS_FILE", func(c *Config, v string) error { c.Database.PasswordFile = v; return nil }},
+	{"DBHOST", func(c *Config, v string) error { c.Database.Host = v; return nil }},
+	{"DBNAME", func(c *Config, v string) error { c.Database.Name = v; return nil }},
+	{"DB_CONNECTION_ATTEMPTS", func(c *Config, v string) (err error) { c.Database.ConnectionAttempts, err = strconv.Atoi(v); return }},
+	{"GITHUB_BASE_URL", func(c *Config, v string) error { c.Github.BaseURL = v; return nil }},
+	{"TESISGHTOKEN", func(c *Config, v string) error { c.Github.Token = v; return nil }},
+	{"TESISGHTOKEN_FILE", func(c *Config, v string) error { c.Github.TokenFile = v; return nil }},
+	{"GITHUB_WEBHOOK_SECRET", func(c *Config, v string) error { c.Github.WebhookSecret = v; return nil }},
+	{"GITHUB_WEBHOOK_SECRET_FILE", func(c *Config, v string) error { c.Github.WebhookSecretFile = v; return nil }},
+	{"LOG_LEVEL", func(c *Config, v string) error { c.Log.Level = strings.ToLower(v); return nil }},
+	{"OTEL_TRACES_EXPORTER", func(c *Config, v string) error { c.Tracing.Exporter = strings.ToLower(v); return nil }},
+	{"OTEL_EXPORTER_OTLP_ENDPOINT", func(c *Config, v string) error { c.Tracing.OTLPEndpoint = v; return nil }},
+	{"API_BOOTSTRAP_TOKEN", func(c *Config, v string) error { c.Auth.BootstrapToken = v; return nil }},
+	{"API_BOOTSTRAP_TOKEN_FILE", func(c *Config, v string) error { c.Auth.BootstrapTokenFile = v; return nil }},
+}
+
+//Default returns the settings used when neither the config file nor the env vars set them
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:            "8080",
+			ShutdownTimeout: 30 * time.Second,
+		},
+		Database: DatabaseConfig{
+			Host:               "localhost:3306",
+			Name:               "configurations",
+			ConnectionAttempts: 5,
+		},
+		Github: GithubConfig{
+			BaseURL: "https://api.github.com",
+		},
+		Log: LogConfig{
+			Level: "info",
+		},
+	}
+}
+
+//Load reads the settings from the given YAML file, when there is one, and then from the env vars, which take precedence.
+//The secrets are read from the files set in database.password_file (DBPASS_FILE), github.token_file (TESISGHTOKEN_FILE),
+//github.webhook_secret_file (GITHUB_WEBHOOK_SECRET_FILE) and auth.bootstrap_token_file (API_BOOTSTRAP_TOKEN_FILE), like the container secret mounts.
+//Returns an error listing every invalid setting.
+func Load(path string) (*Config, error) {
+	c := Default()
+
+	if path != "" {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading config file: %v", err)
+		}
+
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(c); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("error parsing config file %s: %v", path, err)
+		}
+	}
+
+	for _, env := range envOverrides {
+		if value, ok := os.LookupEnv(env.name); ok && value != "" {
+			if err := env.set(c, value); err != nil {
+				return nil, fmt.Errorf("invalid %s env var: %v", env.name, err)
+			}
+		}
+	}
+
+	//Unless the exporter is set, the traces are exported when there is an OTLP endpoint
+	if c.Tracing.Exporter == "" {
+		c.Tracing.Exporter = TracesExporterNone
+		if c.Tracing.OTLPEndpoint != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != "" {
+			c.Tracing.Exporter = TracesExporterOTLP
+		}
+	}
+
+	if err := readSecret(&c.Database.Password, c.Database.PasswordFile); err != nil {
+		return nil, fmt.Errorf("error reading database password file: %v", err)
+	}
+
+	if err := readSecret(&c.Github.Token, c.Github.TokenFile); err != nil {
+		return nil, fmt.Errorf("error reading github token file: %v", err)
+	}
+
+	if err := readSecret(&c.Github.WebhookSecret, c.Github.WebhookSecretFile); err != nil {
+		return nil, fmt.Errorf("error reading github webhook secret file: %v", err)
+	}
+
+	if err := readSecret(&c.Auth.BootstrapToken, c.Auth.BootstrapTokenFile); err != nil {
+		return nil, fmt.Errorf("error reading bootstrap token file: %v", err)
+	}
+
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+//Validate checks every setting, returning an error listing the invalid ones
+func (c *Config) Validate() error {
+	var problems []string
+
+	if c.Server.Port == "" {
+		problems = append(problems, "server.port (PORT) is required")
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		problems = append(problems, "server.shutdown_timeout (SHUTDOWN_TIMEOUT) must be positive")
+	}
+	if c.Database.Host == "" {
+		problems = append(problems, "database.host (DBHOST) is required")
+	}
+	if c.Database.Name == "" {
+		problems = append(problems, "database.name (DBNAME) is required")
+	}
+	if c.Database.User == "" {
+		problems = append(problems, "database.user (DBUSER) is required")
+	}
+	if c.Database.ConnectionAttempts <= 0 {
+		problems = append(problems, "database.connection_attempts (DB_CONNECTION_ATTEMPTS) must be positive")
+	}
+	if u, err := url.Parse(c.Github.BaseURL); err != nil || u.Scheme == "" || u.Host == "" {
+		problems = append(problems, "github.base_url (GITHUB_BASE_URL) must be an absolute url")
+	}
+	if c.Github.Token == "" {
+		problems = append(problems, "github.token (TESISGHTOKEN or TESISGHTOKEN_FILE) is required")
+	}
+	if c.Github.WebhookSecret == "" {
+		problems = append(problems, "github.webhook_secret (GITHUB_WEBHOOK_SECRET or GITHUB_WEBHOOK_SECRET_FILE) is required")
+	}
+	if level, err := zerolog.ParseLevel(c.Log.Level); err != nil || level == zerolog.NoLevel {
+		problems = append(problems, fmt.Sprintf("log.level (LOG_LEVEL) %q is not a valid level", c.Log.Level))
+	}
+	switch c.Tracing.Exporter {
+	case TracesExporterOTLP, TracesExporterStdout, TracesExporterNone:
+	default:
+		problems = append(problems, fmt.Sprintf("tracing.exporter (OTEL_TRACES_EXPORTER) %q must be one of otlp, stdout or none", c.Tracing.Exporter))
+	}
+	if c.Auth.BootstrapToken != "" && len(c.Auth.BootstrapToken) < minBootstrapTokenLength {
+		problems = append(problems, fmt.Sprintf("auth.bootstrap_token (API_BOOTSTRAP_TOKEN) must be at least %d characters", minBootstrapTokenLength))
+	}
+
+	if len(problems) > 0 {
+		return errors.New("invalid configuration: " + strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+//DSN returns the data source name of the mysql connection
+func (c DatabaseConfig) DSN() string {
+	return fmt.Sprintf("%s:%s@tcp(%s)/%s?charset=utf8&parseTime=True&loc=Local", c.User, c.Password, c.Host, c.Name)
+}
+
+//readSecret reads the secret from the given file, if any, trimming the trailing new line
+func readSecret(secret *string, path string) error {
+	if path == "" {
+		return nil
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	*secret = strings.TrimRight(string(content), "\r\n")
+	return nil
+}
@@ -0,0 +1,188 @@
+package configs
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//clearEnv empties every env var read by Load for the duration of the test
+func clearEnv(t *testing.T) {
+	for _, env := range envOverrides {
+		t.Setenv(env.name, "")
+	}
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+}
+
+//writeFile writes the content into a file of the test temp dir, returning its path
+func writeFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	withDefaults := func(edit func(c *Config)) *Config {
+		c := Default()
+		c.Database.User = "user"
+		c.Github.Token = "token"
+		c.Github.WebhookSecret = "webhook-secret"
+		c.Tracing.Exporter = TracesExporterNone
+		edit(c)
+		return c
+	}
+
+	tests := []struct {
+		name   string
+		file   string
+		env    map[string]string
+		secret string
+		want   *Config
+		err    string
+	}{
+		{
+			name: "defaults with the required env vars",
+			env:  map[string]string{"DBUSER": "user", "TESISGHTOKEN": "token", "GITHUB_WEBHOOK_SECRET": "webhook-secret"},
+			want: withDefaults(func(c *Config) {}),
+		},
+		{
+			name: "config file",
+			file: `
+server:
+  port: "9090"
+  shutdown_timeout: 10s
+database:
+  user: user
+  host: db:3306
+github:
+  token: token
+  webhook_secret: webhook-secret
+log:
+  level: debug
+tracing:
+  otlp_endpoint: http://collector:4318
+`,
+			want: withDefaults(func(c *Config) {
+				c.Server.Port = "9090"
+				c.Server.ShutdownTimeout = 10 * time.Second
+				c.Database.Host = "db:3306"
+				c.Log.Level = "debug"
+				c.Tracing.Exporter = TracesExporterOTLP
+				c.Tracing.OTLPEndpoint = "http://collector:4318"
+			}),
+		},
+		{
+			name: "env vars take precedence over the config file",
+			file: `
+server:
+  port: "9090"
+database:
+  user: user
+github:
+  token: token
+  webhook_secret: webhook-secret
+`,
+			env: map[string]string{"PORT": "7070", "SHUTDOWN_TIMEOUT": "5s", "LOG_LEVEL": "WARN", "OTEL_TRACES_EXPORTER": "stdout"},
+			want: withDefaults(func(c *Config) {
+				c.Server.Port = "7070"
+				c.Server.ShutdownTimeout = 5 * time.Second
+				c.Log.Level = "warn"
+				c.Tracing.Exporter = TracesExporterStdout
+			}),
+		},
+		{
+			name:   "secrets read from files",
+			env:    map[string]string{"DBUSER": "user", "DBPASS_FILE": "secret", "TESISGHTOKEN_FILE": "secret", "GITHUB_WEBHOOK_SECRET_FILE": "secret"},
+			secret: "s3cr3t\n",
+			want: withDefaults(func(c *Config) {
+				c.Database.Password = "s3cr3t"
+				c.Github.Token = "s3cr3t"
+				c.Github.WebhookSecret = "s3cr3t"
+			}),
+		},
+		{
+			name:   "bootstrap token read from a file",
+			env:    map[string]string{"DBUSER": "user", "TESISGHTOKEN": "token", "GITHUB_WEBHOOK_SECRET": "webhook-secret", "API_BOOTSTRAP_TOKEN_FILE": "secret"},
+			secret: "bootstrap-token-of-at-least-32-characters\n",
+			want: withDefaults(func(c *Config) {
+				c.Auth.BootstrapToken = "bootstrap-token-of-at-least-32-characters"
+			}),
+		},
+		{
+			name: "bootstrap token too short",
+			env:  map[string]string{"DBUSER": "user", "TESISGHTOKEN": "token", "GITHUB_WEBHOOK_SECRET": "webhook-secret", "API_BOOTSTRAP_TOKEN": "admin"},
+			err:  "auth.bootstrap_token (API_BOOTSTRAP_TOKEN) must be at least 32 characters",
+		},
+		{
+			name: "unknown setting in the config file",
+			file: `
+server:
+  prot: "9090"
+`,
+			err: "field prot not found",
+		},
+		{
+			name: "invalid env var",
+			env:  map[string]string{"DB_CONNECTION_ATTEMPTS": "many"},
+			err:  "invalid DB_CONNECTION_ATTEMPTS env var",
+		},
+		{
+			name: "missing secret file",
+			env:  map[string]string{"DBUSER": "user", "TESISGHTOKEN_FILE": "/nonexistent/token"},
+			err:  "error reading github token file",
+		},
+		{
+			name: "every invalid setting listed",
+			env:  map[string]string{"GITHUB_BASE_URL": "api.github.com", "LOG_LEVEL": "loud", "OTEL_TRACES_EXPORTER": "jaeger"},
+			err: "invalid configuration: database.user (DBUSER) is required; " +
+				"github.base_url (GITHUB_BASE_URL) must be an absolute url; " +
+				"github.token (TESISGHTOKEN or TESISGHTOKEN_FILE) is required; " +
+				"github.webhook_secret (GITHUB_WEBHOOK_SECRET or GITHUB_WEBHOOK_SECRET_FILE) is required; " +
+				`log.level (LOG_LEVEL) "loud" is not a valid level; ` +
+				`tracing.exporter (OTEL_TRACES_EXPORTER) "jaeger" must be one of otlp, stdout or none`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+
+			secret := writeFile(t, "secret", tt.secret)
+			for name, value := range tt.env {
+				if value == "secret" {
+					value = secret
+				}
+				t.Setenv(name, value)
+			}
+
+			path := ""
+			if tt.file != "" {
+				path = writeFile(t, "config.yml", tt.file)
+			}
+
+			got, err := Load(path)
+			if tt.err != "" {
+				assert.Nil(t, got)
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tt.err)
+				}
+				return
+			}
+			assert.Nil(t, err)
+
+			//The secret files live in the temp dir of the test
+			got.Database.PasswordFile, got.Github.TokenFile, got.Github.WebhookSecretFile, got.Auth.BootstrapTokenFile = "", "", "", ""
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDatabaseConfig_DSN(t *testing.T) {
+	c := DatabaseConfig{User: "user", Password: "pass", Host: "db:3306", Name: "configurations"}
+
+	assert.Equal(t, "user:pass@tcp(db:3306)/configurations?charset=utf8&parseTime=True&loc=Local", c.DSN())
+}
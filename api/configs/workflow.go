@@ -53,7 +53,7 @@ func GetGitflowConfig(configuration *models.Configuration) *models.WorkflowConfi
 	developWorkflowRequiredStatusChecks.Contexts = GetRequiredStatusCheck(configuration)
 
 	developRequirements.EnforceAdmins = true
-	developRequirements.AcceptPrFrom = []string{"feature/", "fix/", "enhancement/", "bugfix/"}
+	developRequirements.AcceptPrFrom = []string{"feature/", "fix/", "enhancement/", "bugfix/", "master"}
 	developRequirements.RequiredStatusChecks = developWorkflowRequiredStatusChecks
 	developRequirements.ProtectAtStartup = true
 
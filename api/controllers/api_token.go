@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/services"
+	"github.com/hbalmes/ci_cd-api/api/utils"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+)
+
+//APIToken represents the APITokenController layer
+//It has an instance of an APITokenService layer
+type APIToken struct {
+	Service services.APITokenService
+}
+
+//NewAPITokenController initializes an APITokenController
+func NewAPITokenController(service services.APITokenService) *APIToken {
+	return &APIToken{
+		Service: service,
+	}
+}
+
+//Create mints a new api token, returning the token itself this time only
+//It could returns
+//	201Created in case of a success processing the creation
+//	400BadRequest in case of an error parsing the request payload or invalid scopes or repositories
+//	500InternalServerError in case of an internal error procesing the creation
+func (c *APIToken) Create(ctx utils.HTTPContext) {
+	var req models.APITokenPayload
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(
+			http.StatusBadRequest,
+			apierrors.NewBadRequestApiError("invalid api token request payload"),
+		)
+		return
+	}
+
+	token, err := c.Service.Create(getRequestContext(ctx), &req)
+	if err != nil {
+		ctx.JSON(err.Status(), err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, token.Marshall())
+}
+
+//List retrieves every api token, without the tokens themselves.
+//It could returns
+//	200OK in case of a success procesing the search
+//	500InternalServerError in case of an internal error procesing the search
+func (c *APIToken) List(ctx utils.HTTPContext) {
+	tokens, err := c.Service.List(getRequestContext(ctx))
+	if err != nil {
+		ctx.JSON(err.Status(), err)
+		return
+	}
+
+	response := make([]interface{}, 0, len(tokens))
+	for i := range tokens {
+		response = append(response, tokens[i].Marshall())
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+//Revoke revokes an api token.
+//It could returns
+//	204NoContent in case of a success procesing the revocation
+//	404NotFound in case of the non existance of the api token
+//	500InternalServerError in case of an internal error processing the revocation
+func (c *APIToken) Revoke(ctx utils.HTTPContext) {
+	id, err := strconv.ParseUint(ctx.Param("tokenID"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, apierrors.NewBadRequestApiError("invalid api token id"))
+		return
+	}
+
+	if err := c.Service.Revoke(getRequestContext(ctx), uint32(id), time.Now()); err != nil {
+		ctx.JSON(err.Status(), err)
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/services"
+	"github.com/hbalmes/ci_cd-api/api/utils"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+)
+
+//Approval represents the ApprovalController layer
+//It has an instance of an ApprovalService layer
+type Approval struct {
+	Service services.ApprovalService
+}
+
+//NewApprovalController initializes an ApprovalController
+func NewApprovalController(service services.ApprovalService) *Approval {
+	return &Approval{
+		Service: service,
+	}
+}
+
+//Approve approves the deployment of a build into an environment of the given repository
+//It could returns
+//	201Created in case of a success saving the approval
+//	400BadRequest in case of an error parsing the request payload or an environment without approval gate
+//	403Forbidden in case of an api token not bound to a user which is an approver of the environment
+//	404NotFound in case of the non existance of the configuration or the build
+//	500InternalServerError in case of an internal error saving the approval
+func (c *Approval) Approve(ctx utils.HTTPContext) {
+	c.decide(ctx, c.Service.Approve)
+}
+
+//Reject rejects the deployment of a build into an environment of the given repository
+//It could returns
+//	201Created in case of a success saving the rejection
+//	400BadRequest in case of an error parsing the request payload, a missing comment or an environment without approval gate
+//	403Forbidden in case of an api token not bound to a user which is an approver of the environment
+//	404NotFound in case of the non existance of the configuration or the build
+//	500InternalServerError in case of an internal error saving the rejection
+func (c *Approval) Reject(ctx utils.HTTPContext) {
+	c.decide(ctx, c.Service.Reject)
+}
+
+//Show retrieves the state of the approval gate of an environment for a build, with all its approvals
+//It could returns
+//	200OK in case of a success procesing the search
+//	400BadRequest in case of an environment without approval gate
+//	404NotFound in case of the non existance of the configuration
+//	500InternalServerError in case of an internal error procesing the search
+func (c *Approval) Show(ctx utils.HTTPContext) {
+	buildID, err := getBuildIDfromURL(ctx)
+	if err != nil {
+		ctx.JSON(err.Status(), err)
+		return
+	}
+
+	gate, err := c.Service.GetGate(getRequestContext(ctx), getIDfromURL(ctx), buildID, ctx.Param("environment"))
+	if err != nil {
+		ctx.JSON(err.Status(), err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gate)
+}
+
+func (c *Approval) decide(ctx utils.HTTPContext, decide func(context.Context, string, uint32, string, *models.ApprovalPayload) (*models.Approval, apierrors.ApiError)) {
+	buildID, err := getBuildIDfromURL(ctx)
+	if err != nil {
+		ctx.JSON(err.Status(), err)
+		return
+	}
+
+	var req models.ApprovalPayload
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(
+			http.StatusBadRequest,
+			apierrors.NewBadRequestApiError("invalid approval request payload"),
+		)
+		return
+	}
+
+	approval, err := decide(getRequestContext(ctx), getIDfromURL(ctx), buildID, ctx.Param("environment"), &req)
+	if err != nil {
+		ctx.JSON(err.Status(), err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, approval)
+}
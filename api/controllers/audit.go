@@ -0,0 +1,35 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/hbalmes/ci_cd-api/api/services"
+	"github.com/hbalmes/ci_cd-api/api/utils"
+)
+
+//Audit represents the AuditController layer
+//It has an instance of an AuditService layer
+type Audit struct {
+	Service services.AuditService
+}
+
+//NewAuditController initializes an AuditController
+func NewAuditController(service services.AuditService) *Audit {
+	return &Audit{
+		Service: service,
+	}
+}
+
+//List retrieves the audit events recorded for a given repository.
+//It could returns
+//	200OK in case of a success procesing the search
+//	500InternalServerError in case of an internal error procesing the search
+func (c *Audit) List(ctx utils.HTTPContext) {
+	events, err := c.Service.List(getRequestContext(ctx), getIDfromURL(ctx))
+	if err != nil {
+		ctx.JSON(err.Status(), err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, events)
+}
@@ -1,10 +1,10 @@
 package controllers
 
 import (
+	"context"
 	"fmt"
 	"github.com/hbalmes/ci_cd-api/api/models"
 	"github.com/hbalmes/ci_cd-api/api/services"
-	"github.com/hbalmes/ci_cd-api/api/services/storage"
 	"github.com/hbalmes/ci_cd-api/api/utils"
 	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
 	"net/http"
@@ -23,9 +23,9 @@ type Configuration struct {
 }
 
 //NewConfigurationController initializes a ConfigurationController
-func NewConfigurationController(sql storage.SQLStorage) *Configuration {
+func NewConfigurationController(service services.ConfigurationService) *Configuration {
 	return &Configuration{
-		Service: services.NewConfigurationService(sql),
+		Service: service,
 	}
 }
 
@@ -44,7 +44,7 @@ func (c *Configuration) Create(ctx utils.HTTPContext) {
 		return
 	}
 
-	config, err := c.Service.Create(&req)
+	config, err := c.Service.Create(getRequestContext(ctx), &req)
 	if err != nil {
 		ctx.JSON(
 			http.StatusInternalServerError,
@@ -63,7 +63,7 @@ func (c *Configuration) Create(ctx utils.HTTPContext) {
 //	500InternalServerError in case of an internal error procesing the search
 func (c *Configuration) Show(ctx utils.HTTPContext) {
 	id := getIDfromURL(ctx)
-	config, err := c.Service.Get(id)
+	config, err := c.Service.Get(getRequestContext(ctx), id)
 	if err != nil {
 		if err != gorm.ErrRecordNotFound {
 			ctx.JSON(
@@ -100,13 +100,14 @@ func (c *Configuration) Update(ctx utils.HTTPContext) {
 	repoName := getRepoNamefromURL(ctx)
 	req.Repository.Name = &repoName
 
-	config, err := c.Service.Update(&req)
+	config, err := c.Service.Update(getRequestContext(ctx), &req)
 
 	if err != nil {
 		ctx.JSON(
 			http.StatusInternalServerError,
 			apierrors.NewInternalServerApiError("something was wrong updating repository configuration", err),
 		)
+		return
 	}
 
 	ctx.JSON(http.StatusOK, config.Marshall())
@@ -121,7 +122,7 @@ func (c *Configuration) Update(ctx utils.HTTPContext) {
 func (c *Configuration) Delete(ctx utils.HTTPContext) {
 
 	id := getIDfromURL(ctx)
-	err := c.Service.Delete(id)
+	err := c.Service.Delete(getRequestContext(ctx), id)
 
 	if err != nil {
 		if err != gorm.ErrRecordNotFound {
@@ -148,6 +149,14 @@ func getRepoNamefromURL(ctx utils.HTTPContext) string {
 	return ctx.Param("repoName")
 }
 
+//getRequestContext returns the context of the request being served, which carries the request logger
+func getRequestContext(ctx utils.HTTPContext) context.Context {
+	if r, ok := ctx.Value(0).(*http.Request); ok && r != nil {
+		return r.Context()
+	}
+	return context.Background()
+}
+
 func getIDfromURL(ctx utils.HTTPContext) string {
 	repoName := ctx.Param("repoName")
 	repoOwner := ctx.Param("repoOwner")
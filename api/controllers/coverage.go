@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/services"
+	"github.com/hbalmes/ci_cd-api/api/utils"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+)
+
+//Coverage represents the CoverageController layer
+//It has an instance of a CoverageService layer
+type Coverage struct {
+	Service services.CoverageService
+}
+
+//NewCoverageController initializes a CoverageController
+func NewCoverageController(service services.CoverageService) *Coverage {
+	return &Coverage{
+		Service: service,
+	}
+}
+
+//Create saves a coverage report for the given repository
+//It could returns
+//	201Created in case of a success processing the creation
+//	400BadRequest in case of an error parsing the request payload
+//	500InternalServerError in case of an internal error procesing the creation
+func (c *Coverage) Create(ctx utils.HTTPContext) {
+	var req models.CoverageRequestPayload
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(
+			http.StatusBadRequest,
+			apierrors.NewBadRequestApiError("invalid coverage request payload"),
+		)
+		return
+	}
+
+	coverage, err := c.Service.Create(getRequestContext(ctx), getIDfromURL(ctx), &req)
+	if err != nil {
+		ctx.JSON(err.Status(), err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, coverage)
+}
+
+//History retrieves the coverage time series for a given repository.
+//The 'branch' query param filters the reports of a single branch.
+//It could returns
+//	200OK in case of a success procesing the search
+//	500InternalServerError in case of an internal error procesing the search
+func (c *Coverage) History(ctx utils.HTTPContext) {
+	points, err := c.Service.GetHistory(getRequestContext(ctx), getIDfromURL(ctx), ctx.Query("branch"))
+	if err != nil {
+		ctx.JSON(err.Status(), err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, points)
+}
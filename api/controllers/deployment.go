@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/services"
+	"github.com/hbalmes/ci_cd-api/api/utils"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+)
+
+//Deployment represents the DeploymentController layer
+//It has an instance of a DeploymentService layer
+type Deployment struct {
+	Service services.DeploymentService
+}
+
+//NewDeploymentController initializes a DeploymentController
+func NewDeploymentController(service services.DeploymentService) *Deployment {
+	return &Deployment{
+		Service: service,
+	}
+}
+
+//Create requests the deployment of a build into an environment of the given repository
+//It could returns
+//	201Created in case of a success processing the request
+//	400BadRequest in case of an error parsing the request payload or an environment not configured
+//	403Forbidden in case of a request without api token, a requester which is not the authenticated one or a freeze override with an api token not bound to a user
+//	404NotFound in case of the non existance of the configuration or the build
+//	409Conflict in case of a production environment inside a freeze window
+//	500InternalServerError in case of an internal error procesing the request
+func (c *Deployment) Create(ctx utils.HTTPContext) {
+	var req models.DeploymentPayload
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(
+			http.StatusBadRequest,
+			apierrors.NewBadRequestApiError("invalid deployment request payload"),
+		)
+		return
+	}
+
+	deployment, err := c.Service.Create(getRequestContext(ctx), getIDfromURL(ctx), &req)
+	if err != nil {
+		ctx.JSON(err.Status(), err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, deployment)
+}
+
+//List retrieves the deployments of a given repository, the newest first.
+//The 'environment' query param filters the deployments of a single environment.
+//It could returns
+//	200OK in case of a success procesing the search
+//	500InternalServerError in case of an internal error procesing the search
+func (c *Deployment) List(ctx utils.HTTPContext) {
+	deployments, err := c.Service.List(getRequestContext(ctx), getIDfromURL(ctx), ctx.Query("environment"))
+	if err != nil {
+		ctx.JSON(err.Status(), err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, deployments)
+}
@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/hbalmes/ci_cd-api/api/services"
+	"github.com/hbalmes/ci_cd-api/api/utils"
+)
+
+//Dora represents the DoraController layer
+//It has an instance of a DoraService layer
+type Dora struct {
+	Service services.DoraService
+}
+
+//NewDoraController initializes a DoraController
+func NewDoraController(service services.DoraService) *Dora {
+	return &Dora{
+		Service: service,
+	}
+}
+
+//Show retrieves the DORA metrics of a repository or a team.
+//The query parameters are the repository (owner/name) or the team,
+//and the window, either the from and to dates (2006-01-02) or the days until today.
+//It could returns
+//	200OK in case of a success procesing the metrics
+//	400BadRequest in case of invalid query parameters
+//	404NotFound in case of the non existance of the configuration or the team
+//	500InternalServerError in case of an internal error procesing the metrics
+func (c *Dora) Show(ctx utils.HTTPContext) {
+	query, err := services.NewDoraQuery(ctx.Query("repository"), ctx.Query("team"), ctx.Query("from"), ctx.Query("to"), ctx.Query("days"), time.Now())
+	if err != nil {
+		ctx.JSON(err.Status(), err)
+		return
+	}
+
+	metrics, err := c.Service.Get(getRequestContext(ctx), query)
+	if err != nil {
+		ctx.JSON(err.Status(), err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, metrics)
+}
@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/services"
+	"github.com/hbalmes/ci_cd-api/api/utils"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+)
+
+//FreezeWindow represents the FreezeWindowController layer
+//It has an instance of a FreezeWindowService layer
+type FreezeWindow struct {
+	Service services.FreezeWindowService
+}
+
+//NewFreezeWindowController initializes a FreezeWindowController
+func NewFreezeWindowController(service services.FreezeWindowService) *FreezeWindow {
+	return &FreezeWindow{
+		Service: service,
+	}
+}
+
+//Create creates a new freeze window for the given repository, or a global one
+//It could returns
+//	201Created in case of a success processing the creation
+//	400BadRequest in case of an error parsing the request payload or an invalid freeze window
+//	404NotFound in case of the non existance of the configuration
+//	500InternalServerError in case of an internal error procesing the creation
+func (c *FreezeWindow) Create(ctx utils.HTTPContext) {
+	var req models.FreezeWindowPayload
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(
+			http.StatusBadRequest,
+			apierrors.NewBadRequestApiError("invalid freeze window request payload"),
+		)
+		return
+	}
+
+	window, err := c.Service.Create(getRequestContext(ctx), getFreezeWindowScope(ctx), &req)
+	if err != nil {
+		ctx.JSON(err.Status(), err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, window)
+}
+
+//List retrieves the freeze windows which apply to a given repository, or the global ones.
+//It could returns
+//	200OK in case of a success procesing the search
+//	500InternalServerError in case of an internal error procesing the search
+func (c *FreezeWindow) List(ctx utils.HTTPContext) {
+	windows, err := c.Service.List(getRequestContext(ctx), getFreezeWindowScope(ctx))
+	if err != nil {
+		ctx.JSON(err.Status(), err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, windows)
+}
+
+//Delete erases a freeze window of a given repository, or a global one.
+//It could returns
+//	204NoContent in case of a success procesing the delete
+//	404NotFound in case of the non existance of the freeze window
+//	500InternalServerError in case of an internal error processing the delete
+func (c *FreezeWindow) Delete(ctx utils.HTTPContext) {
+	id, parseErr := getFreezeWindowIDfromURL(ctx)
+	if parseErr != nil {
+		ctx.JSON(http.StatusBadRequest, parseErr)
+		return
+	}
+
+	if err := c.Service.Delete(getRequestContext(ctx), getFreezeWindowScope(ctx), id); err != nil {
+		ctx.JSON(err.Status(), err)
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+//getFreezeWindowScope returns the repository of the url, or an empty string for the global freeze windows
+func getFreezeWindowScope(ctx utils.HTTPContext) string {
+	if ctx.Param("repoName") == "" {
+		return ""
+	}
+	return getIDfromURL(ctx)
+}
+
+func getFreezeWindowIDfromURL(ctx utils.HTTPContext) (uint32, apierrors.ApiError) {
+	id, err := strconv.ParseUint(ctx.Param("windowID"), 10, 32)
+	if err != nil {
+		return 0, apierrors.NewBadRequestApiError("invalid freeze window id")
+	}
+	return uint32(id), nil
+}
@@ -0,0 +1,44 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/services"
+	"github.com/hbalmes/ci_cd-api/api/utils"
+)
+
+//Health represents the HealthController layer
+//It has an instance of a HealthService layer
+type Health struct {
+	Service services.HealthService
+}
+
+//NewHealthController initializes a HealthController
+func NewHealthController(service services.HealthService) *Health {
+	return &Health{
+		Service: service,
+	}
+}
+
+//Live reports that the API is up, without checking its dependencies.
+//It returns
+//	200OK while the API is able to answer
+func (c *Health) Live(ctx utils.HTTPContext) {
+	ctx.JSON(http.StatusOK, c.Service.Live())
+}
+
+//Ready reports whether the API is able to serve requests, checking its dependencies.
+//It could returns
+//	200OK in case of every dependency being up
+//	503ServiceUnavailable in case of any dependency being down
+func (c *Health) Ready(ctx utils.HTTPContext) {
+	health := c.Service.Ready(getRequestContext(ctx))
+
+	if health.Status != models.HealthStatusUp {
+		ctx.JSON(http.StatusServiceUnavailable, health)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, health)
+}
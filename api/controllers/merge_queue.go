@@ -0,0 +1,36 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/hbalmes/ci_cd-api/api/services"
+	"github.com/hbalmes/ci_cd-api/api/utils"
+)
+
+//MergeQueue represents the MergeQueueController layer
+//It has an instance of a MergeQueueService layer
+type MergeQueue struct {
+	Service services.MergeQueueService
+}
+
+//NewMergeQueueController initializes a MergeQueueController
+func NewMergeQueueController(service services.MergeQueueService) *MergeQueue {
+	return &MergeQueue{
+		Service: service,
+	}
+}
+
+//List retrieves the merge queues of a given repository, in merge order.
+//The 'branch' query param filters the queue of a single protected branch.
+//It could returns
+//	200OK in case of a success procesing the search
+//	500InternalServerError in case of an internal error procesing the search
+func (c *MergeQueue) List(ctx utils.HTTPContext) {
+	entries, err := c.Service.List(getRequestContext(ctx), getIDfromURL(ctx), ctx.Query("branch"))
+	if err != nil {
+		ctx.JSON(err.Status(), err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entries)
+}
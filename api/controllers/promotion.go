@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/services"
+	"github.com/hbalmes/ci_cd-api/api/utils"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+)
+
+//Promotion represents the PromotionController layer
+//It has an instance of a PromotionService layer
+type Promotion struct {
+	Service services.PromotionService
+}
+
+//NewPromotionController initializes a PromotionController
+func NewPromotionController(service services.PromotionService) *Promotion {
+	return &Promotion{
+		Service: service,
+	}
+}
+
+//Create promotes a test build of the given repository to a productive release
+//It could returns
+//	201Created in case of a success processing the promotion
+//	400BadRequest in case of an error parsing the request payload or a build which is not a test build
+//	403Forbidden in case of an api token not bound to a user, or a requester which is not that user
+//	404NotFound in case of the non existance of the configuration or the build
+//	409Conflict in case of a build already promoted, promotion gates not satisfied or a freeze window active
+//	500InternalServerError in case of an internal error procesing the promotion
+func (c *Promotion) Create(ctx utils.HTTPContext) {
+	buildID, err := getBuildIDfromURL(ctx)
+	if err != nil {
+		ctx.JSON(err.Status(), err)
+		return
+	}
+
+	var req models.PromotionPayload
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(
+			http.StatusBadRequest,
+			apierrors.NewBadRequestApiError("invalid promotion request payload"),
+		)
+		return
+	}
+
+	build, err := c.Service.Promote(getRequestContext(ctx), getIDfromURL(ctx), buildID, &req)
+	if err != nil {
+		ctx.JSON(err.Status(), err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, build)
+}
+
+func getBuildIDfromURL(ctx utils.HTTPContext) (uint32, apierrors.ApiError) {
+	id, err := strconv.ParseUint(ctx.Param("buildID"), 10, 32)
+	if err != nil {
+		return 0, apierrors.NewBadRequestApiError("invalid build id")
+	}
+	return uint32(id), nil
+}
@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/services"
+	"github.com/hbalmes/ci_cd-api/api/utils"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+)
+
+//ReleaseSchedule represents the ReleaseScheduleController layer
+//It has an instance of a ReleaseScheduleService layer
+type ReleaseSchedule struct {
+	Service services.ReleaseScheduleService
+}
+
+//NewReleaseScheduleController initializes a ReleaseScheduleController
+func NewReleaseScheduleController(service services.ReleaseScheduleService) *ReleaseSchedule {
+	return &ReleaseSchedule{
+		Service: service,
+	}
+}
+
+//Create creates a new release train for the given repository
+//It could returns
+//	201Created in case of a success processing the creation
+//	400BadRequest in case of an error parsing the request payload or an invalid cron expression
+//	404NotFound in case of the non existance of the configuration
+//	500InternalServerError in case of an internal error procesing the creation
+func (c *ReleaseSchedule) Create(ctx utils.HTTPContext) {
+	var req models.ReleaseSchedulePayload
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(
+			http.StatusBadRequest,
+			apierrors.NewBadRequestApiError("invalid release schedule request payload"),
+		)
+		return
+	}
+
+	schedule, err := c.Service.Create(getRequestContext(ctx), getIDfromURL(ctx), &req)
+	if err != nil {
+		ctx.JSON(err.Status(), err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, schedule)
+}
+
+//List retrieves the release trains of a given repository.
+//It could returns
+//	200OK in case of a success procesing the search
+//	500InternalServerError in case of an internal error procesing the search
+func (c *ReleaseSchedule) List(ctx utils.HTTPContext) {
+	schedules, err := c.Service.List(getRequestContext(ctx), getIDfromURL(ctx))
+	if err != nil {
+		ctx.JSON(err.Status(), err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schedules)
+}
+
+//Update updates a release train of a given repository.
+//It could returns
+//	200OK in case of a success procesing the update
+//	400BadRequest in case of an error parsing the request payload or an invalid cron expression
+//	404NotFound in case of the non existance of the release train
+//	500InternalServerError in case of an internal error procesing the update
+func (c *ReleaseSchedule) Update(ctx utils.HTTPContext) {
+	id, parseErr := getScheduleIDfromURL(ctx)
+	if parseErr != nil {
+		ctx.JSON(http.StatusBadRequest, parseErr)
+		return
+	}
+
+	var req models.ReleaseSchedulePayload
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(
+			http.StatusBadRequest,
+			apierrors.NewBadRequestApiError("invalid release schedule request payload"),
+		)
+		return
+	}
+
+	schedule, err := c.Service.Update(getRequestContext(ctx), getIDfromURL(ctx), id, &req)
+	if err != nil {
+		ctx.JSON(err.Status(), err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schedule)
+}
+
+//Delete erases a release train of a given repository.
+//It could returns
+//	204NoContent in case of a success procesing the delete
+//	404NotFound in case of the non existance of the release train
+//	500InternalServerError in case of an internal error processing the delete
+func (c *ReleaseSchedule) Delete(ctx utils.HTTPContext) {
+	id, parseErr := getScheduleIDfromURL(ctx)
+	if parseErr != nil {
+		ctx.JSON(http.StatusBadRequest, parseErr)
+		return
+	}
+
+	if err := c.Service.Delete(getRequestContext(ctx), getIDfromURL(ctx), id); err != nil {
+		ctx.JSON(err.Status(), err)
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+func getScheduleIDfromURL(ctx utils.HTTPContext) (uint32, apierrors.ApiError) {
+	id, err := strconv.ParseUint(ctx.Param("scheduleID"), 10, 32)
+	if err != nil {
+		return 0, apierrors.NewBadRequestApiError("invalid release schedule id")
+	}
+	return uint32(id), nil
+}
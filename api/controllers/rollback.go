@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/services"
+	"github.com/hbalmes/ci_cd-api/api/utils"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+)
+
+//Rollback represents the RollbackController layer
+//It has an instance of a RollbackService layer
+type Rollback struct {
+	Service services.RollbackService
+}
+
+//NewRollbackController initializes a RollbackController
+func NewRollbackController(service services.RollbackService) *Rollback {
+	return &Rollback{
+		Service: service,
+	}
+}
+
+//Create rolls back a productive build of the given repository to the previous productive build
+//It could returns
+//	200OK in case of a success processing the rollback
+//	400BadRequest in case of an error parsing the request payload or a build which is not a productive build
+//	403Forbidden in case of an api token not bound to a user, or a requester which is not that user
+//	404NotFound in case of the non existance of the configuration or the build
+//	409Conflict in case of a build already rolled back or without a previous productive build
+//	500InternalServerError in case of an internal error procesing the rollback
+func (c *Rollback) Create(ctx utils.HTTPContext) {
+	buildID, err := getBuildIDfromURL(ctx)
+	if err != nil {
+		ctx.JSON(err.Status(), err)
+		return
+	}
+
+	var req models.RollbackPayload
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(
+			http.StatusBadRequest,
+			apierrors.NewBadRequestApiError("invalid rollback request payload"),
+		)
+		return
+	}
+
+	rollback, err := c.Service.Rollback(getRequestContext(ctx), getIDfromURL(ctx), buildID, &req)
+	if err != nil {
+		ctx.JSON(err.Status(), err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rollback)
+}
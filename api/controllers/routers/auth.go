@@ -0,0 +1,83 @@
+package routers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/services"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+)
+
+const bearerPrefix = "Bearer "
+
+//repositoryResolver returns the repository (owner/name) a request acts on, empty for the global resources
+type repositoryResolver func(c *gin.Context) string
+
+//authorize authenticates the api token of the request, sent as a bearer token,
+//and checks that it has the given scope and that it can access the repository of the request.
+//The token is kept in the request context, so the services know who acts.
+func authorize(tokens services.APITokenService, scope string, repository repositoryResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		if header != "" && !strings.HasPrefix(header, bearerPrefix) {
+			err := apierrors.NewUnauthorizedApiError("the api token must be sent as a bearer token")
+			c.AbortWithStatusJSON(err.Status(), err)
+			return
+		}
+
+		token, err := tokens.Authenticate(c.Request.Context(), strings.TrimPrefix(header, bearerPrefix))
+		if err != nil {
+			c.AbortWithStatusJSON(err.Status(), err)
+			return
+		}
+
+		if !token.HasScope(scope) {
+			err := apierrors.NewForbiddenApiError(fmt.Sprintf("the api token needs the %s scope", scope))
+			c.AbortWithStatusJSON(err.Status(), err)
+			return
+		}
+
+		if repositoryName := repository(c); !token.CanAccess(repositoryName) {
+			message := fmt.Sprintf("the api token can not access the repository %s", repositoryName)
+			if repositoryName == "" {
+				message = "the api token is restricted to some repositories, it can not access the global resources"
+			}
+			err := apierrors.NewForbiddenApiError(message)
+			c.AbortWithStatusJSON(err.Status(), err)
+			return
+		}
+
+		c.Request = c.Request.WithContext(services.WithAPIToken(c.Request.Context(), token))
+		c.Next()
+	}
+}
+
+//repositoryFromURL returns the repository of the url path, or the one of the repository query parameter
+func repositoryFromURL(c *gin.Context) string {
+	if owner, name := c.Param("repoOwner"), c.Param("repoName"); owner != "" && name != "" {
+		return owner + "/" + name
+	}
+	return c.Query("repository")
+}
+
+//repositoryFromConfigurationPayload returns the repository of a configuration request payload.
+//The body is restored, so the controller can read it again.
+func repositoryFromConfigurationPayload(c *gin.Context) string {
+	body, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		return ""
+	}
+	c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	var payload models.PostRequestPayload
+	if err := json.Unmarshal(body, &payload); err != nil || payload.Repository.Owner == nil || payload.Repository.Name == nil {
+		return ""
+	}
+
+	return *payload.Repository.Owner + "/" + *payload.Repository.Name
+}
@@ -2,45 +2,77 @@ package routers
 
 import (
 	"github.com/hbalmes/ci_cd-api/api/controllers"
-	"github.com/hbalmes/ci_cd-api/api/services/storage"
+	"github.com/hbalmes/ci_cd-api/api/metrics"
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/services"
+	"github.com/hbalmes/ci_cd-api/api/utils/logger"
+	"github.com/hbalmes/ci_cd-api/api/utils/tracing"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
-var (
-	//SQLConnection is a stablished connection with the relational database
-	SQLConnection *storage.SQL
-)
-
-//Route defines all the endpoints of this API.
-func Route() *gin.Engine {
-	r := gin.Default()
+//Route defines all the endpoints of this API, served by the given services.
+//The github webhooks are only accepted when signed with the webhook secret.
+func Route(svc *services.Services, webhookSecret string) *gin.Engine {
+	r := gin.New()
+	r.Use(gin.Recovery(), tracing.Middleware(), logger.Middleware())
 
 	r.GET("/ping", func(c *gin.Context) {
 		c.String(http.StatusOK, "pong")
 	})
 
-	ct := controllers.NewConfigurationController(SQLConnection)
-	whct := controllers.NewWebhookController(SQLConnection)
+	hlct := controllers.NewHealthController(svc.Health)
+
+	//GET to /health/live reports that the API is up, for the liveness probes
+	r.GET("/health/live", func(c *gin.Context) {
+		hlct.Live(c)
+	})
+
+	//GET to /health/ready reports whether the database and github are reachable, for the readiness probes
+	r.GET("/health/ready", func(c *gin.Context) {
+		hlct.Ready(c)
+	})
+
+	//GET to /metrics exposes the operational metrics in the prometheus format
+	r.GET("/metrics", metrics.Handler())
+
+	//Every route but the probes, the metrics and the github webhooks needs an api token
+	auth := func(scope string) gin.HandlerFunc {
+		return authorize(svc.APIToken, scope, repositoryFromURL)
+	}
+
+	ct := controllers.NewConfigurationController(svc.Configuration)
+	whct := controllers.NewWebhookController(svc.Webhook, webhookSecret)
+	cvct := controllers.NewCoverageController(svc.Coverage)
+	auct := controllers.NewAuditController(svc.Audit)
+	rsct := controllers.NewReleaseScheduleController(svc.ReleaseSchedule)
+	mqct := controllers.NewMergeQueueController(svc.MergeQueue)
+	dpct := controllers.NewDeploymentController(svc.Deployment)
+	prct := controllers.NewPromotionController(svc.Promotion)
+	apct := controllers.NewApprovalController(svc.Approval)
+	fwct := controllers.NewFreezeWindowController(svc.FreezeWindow)
+	rbct := controllers.NewRollbackController(svc.Rollback)
+	drct := controllers.NewDoraController(svc.Dora)
+	tkct := controllers.NewAPITokenController(svc.APIToken)
 
 	//POST to /configurations performs a release process configuration create
-	r.POST("/configurations", func(c *gin.Context) {
+	r.POST("/configurations", authorize(svc.APIToken, models.APITokenScopeConfigure, repositoryFromConfigurationPayload), func(c *gin.Context) {
 		ct.Create(c)
 	})
 
 	//GET to /configurations/:repoName performs a release process configuration get
-	r.GET("/configurations/:repoOwner/:repoName", func(c *gin.Context) {
+	r.GET("/configurations/:repoOwner/:repoName", auth(models.APITokenScopeRead), func(c *gin.Context) {
 		ct.Show(c)
 	})
 
 	//PUT to /configurations/:repoName performs a release process configuration update
-	r.PUT("/configurations/:repoOwner/:repoName", func(c *gin.Context) {
+	r.PUT("/configurations/:repoOwner/:repoName", auth(models.APITokenScopeConfigure), func(c *gin.Context) {
 		ct.Update(c)
 	})
 
 	//DELETE to /configurations/:repoName performs a release process configuration delete
-	r.DELETE("/configurations/:repoOwner/:repoName", func(c *gin.Context) {
+	r.DELETE("/configurations/:repoOwner/:repoName", auth(models.APITokenScopeConfigure), func(c *gin.Context) {
 		ct.Delete(c)
 	})
 
@@ -49,5 +81,130 @@ func Route() *gin.Engine {
 		whct.CreateWebhook(c)
 	})
 
+	//POST to /repositories/:repoOwner/:repoName/coverage saves a coverage report
+	r.POST("/repositories/:repoOwner/:repoName/coverage", auth(models.APITokenScopeRelease), func(c *gin.Context) {
+		cvct.Create(c)
+	})
+
+	//GET to /repositories/:repoOwner/:repoName/coverage returns the coverage history of the repository
+	r.GET("/repositories/:repoOwner/:repoName/coverage", auth(models.APITokenScopeRead), func(c *gin.Context) {
+		cvct.History(c)
+	})
+
+	//GET to /repositories/:repoOwner/:repoName/audit returns the audit events of the repository
+	r.GET("/repositories/:repoOwner/:repoName/audit", auth(models.APITokenScopeRead), func(c *gin.Context) {
+		auct.List(c)
+	})
+
+	//POST to /repositories/:repoOwner/:repoName/release-schedules creates a release train
+	r.POST("/repositories/:repoOwner/:repoName/release-schedules", auth(models.APITokenScopeConfigure), func(c *gin.Context) {
+		rsct.Create(c)
+	})
+
+	//GET to /repositories/:repoOwner/:repoName/release-schedules returns the release trains of the repository
+	r.GET("/repositories/:repoOwner/:repoName/release-schedules", auth(models.APITokenScopeRead), func(c *gin.Context) {
+		rsct.List(c)
+	})
+
+	//PUT to /repositories/:repoOwner/:repoName/release-schedules/:scheduleID updates a release train
+	r.PUT("/repositories/:repoOwner/:repoName/release-schedules/:scheduleID", auth(models.APITokenScopeConfigure), func(c *gin.Context) {
+		rsct.Update(c)
+	})
+
+	//DELETE to /repositories/:repoOwner/:repoName/release-schedules/:scheduleID deletes a release train
+	r.DELETE("/repositories/:repoOwner/:repoName/release-schedules/:scheduleID", auth(models.APITokenScopeConfigure), func(c *gin.Context) {
+		rsct.Delete(c)
+	})
+
+	//GET to /repositories/:repoOwner/:repoName/merge-queue returns the merge queues of the repository
+	r.GET("/repositories/:repoOwner/:repoName/merge-queue", auth(models.APITokenScopeRead), func(c *gin.Context) {
+		mqct.List(c)
+	})
+
+	//POST to /repositories/:repoOwner/:repoName/deployments requests the deployment of a build into an environment
+	r.POST("/repositories/:repoOwner/:repoName/deployments", auth(models.APITokenScopeRelease), func(c *gin.Context) {
+		dpct.Create(c)
+	})
+
+	//GET to /repositories/:repoOwner/:repoName/deployments returns the deployments of the repository
+	r.GET("/repositories/:repoOwner/:repoName/deployments", auth(models.APITokenScopeRead), func(c *gin.Context) {
+		dpct.List(c)
+	})
+
+	//POST to /repositories/:repoOwner/:repoName/builds/:buildID/promote promotes a test build to a productive release
+	r.POST("/repositories/:repoOwner/:repoName/builds/:buildID/promote", auth(models.APITokenScopeRelease), func(c *gin.Context) {
+		prct.Create(c)
+	})
+
+	//POST to /repositories/:repoOwner/:repoName/builds/:buildID/rollback rolls back a productive build to the previous one
+	r.POST("/repositories/:repoOwner/:repoName/builds/:buildID/rollback", auth(models.APITokenScopeRelease), func(c *gin.Context) {
+		rbct.Create(c)
+	})
+
+	//POST to /repositories/:repoOwner/:repoName/builds/:buildID/environments/:environment/approve approves the deployment of a build
+	r.POST("/repositories/:repoOwner/:repoName/builds/:buildID/environments/:environment/approve", auth(models.APITokenScopeRelease), func(c *gin.Context) {
+		apct.Approve(c)
+	})
+
+	//POST to /repositories/:repoOwner/:repoName/builds/:buildID/environments/:environment/reject rejects the deployment of a build
+	r.POST("/repositories/:repoOwner/:repoName/builds/:buildID/environments/:environment/reject", auth(models.APITokenScopeRelease), func(c *gin.Context) {
+		apct.Reject(c)
+	})
+
+	//GET to /repositories/:repoOwner/:repoName/builds/:buildID/environments/:environment/approvals returns the approval gate state of a build
+	r.GET("/repositories/:repoOwner/:repoName/builds/:buildID/environments/:environment/approvals", auth(models.APITokenScopeRead), func(c *gin.Context) {
+		apct.Show(c)
+	})
+
+	//POST to /repositories/:repoOwner/:repoName/freeze-windows creates a freeze window for the repository
+	r.POST("/repositories/:repoOwner/:repoName/freeze-windows", auth(models.APITokenScopeConfigure), func(c *gin.Context) {
+		fwct.Create(c)
+	})
+
+	//GET to /repositories/:repoOwner/:repoName/freeze-windows returns the freeze windows which apply to the repository
+	r.GET("/repositories/:repoOwner/:repoName/freeze-windows", auth(models.APITokenScopeRead), func(c *gin.Context) {
+		fwct.List(c)
+	})
+
+	//DELETE to /repositories/:repoOwner/:repoName/freeze-windows/:windowID deletes a freeze window of the repository
+	r.DELETE("/repositories/:repoOwner/:repoName/freeze-windows/:windowID", auth(models.APITokenScopeConfigure), func(c *gin.Context) {
+		fwct.Delete(c)
+	})
+
+	//POST to /freeze-windows creates a global freeze window
+	r.POST("/freeze-windows", auth(models.APITokenScopeConfigure), func(c *gin.Context) {
+		fwct.Create(c)
+	})
+
+	//GET to /freeze-windows returns the global freeze windows
+	r.GET("/freeze-windows", auth(models.APITokenScopeRead), func(c *gin.Context) {
+		fwct.List(c)
+	})
+
+	//DELETE to /freeze-windows/:windowID deletes a global freeze window
+	r.DELETE("/freeze-windows/:windowID", auth(models.APITokenScopeConfigure), func(c *gin.Context) {
+		fwct.Delete(c)
+	})
+
+	//GET to /metrics/dora returns the DORA metrics of a repository or a team
+	r.GET("/metrics/dora", auth(models.APITokenScopeRead), func(c *gin.Context) {
+		drct.Show(c)
+	})
+
+	//POST to /tokens mints an api token
+	r.POST("/tokens", auth(models.APITokenScopeAdmin), func(c *gin.Context) {
+		tkct.Create(c)
+	})
+
+	//GET to /tokens returns the api tokens
+	r.GET("/tokens", auth(models.APITokenScopeAdmin), func(c *gin.Context) {
+		tkct.List(c)
+	})
+
+	//DELETE to /tokens/:tokenID revokes an api token
+	r.DELETE("/tokens/:tokenID", auth(models.APITokenScopeAdmin), func(c *gin.Context) {
+		tkct.Revoke(c)
+	})
+
 	return r
 }
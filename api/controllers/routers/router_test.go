@@ -1,16 +1,55 @@
 package routers
 
 import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/hbalmes/ci_cd-api/api/configs"
+	"github.com/hbalmes/ci_cd-api/api/mocks/interfaces"
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/models/webhook"
+	"github.com/hbalmes/ci_cd-api/api/services"
+	"github.com/hbalmes/ci_cd-api/api/services/storage"
+	"github.com/hbalmes/ci_cd-api/api/utils"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"github.com/hbalmes/ci_cd-api/api/utils/tracing"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
 )
 
+//testWebhookSecret is the secret the test github webhooks are signed with
+const testWebhookSecret = "webhook-secret"
+
+//sign returns the X-Hub-Signature-256 header github sends along the given webhook body
+func sign(secret string, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+//newTestRouter serves every route with the services of the API on top of a fake storage and a fake github client
+func newTestRouter(t *testing.T) (*gin.Engine, *interfaces.MockSQLStorage, *interfaces.MockGithubClient) {
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+	githubClient := interfaces.NewMockGithubClient(ctrl)
+
+	return Route(services.NewServices(sqlStorage, githubClient, configs.AuthConfig{}), testWebhookSecret), sqlStorage, githubClient
+}
+
 //TestPingRoute test that a GET /ping returns a 'pong' response with a 200OK status code.
 func TestPingRoute(t *testing.T) {
-	router := Route()
+	router, _, _ := newTestRouter(t)
 
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest("GET", "/ping", nil)
@@ -19,3 +58,351 @@ func TestPingRoute(t *testing.T) {
 	assert.Equal(t, 200, w.Code)
 	assert.Equal(t, "pong", w.Body.String())
 }
+
+//TestLiveRoute test that a GET /health/live returns an up status with a 200OK status code,
+//without checking the dependencies.
+func TestLiveRoute(t *testing.T) {
+	router, _, _ := newTestRouter(t)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/health/live", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.JSONEq(t, `{"status": "up"}`, w.Body.String())
+}
+
+//TestReadyRoute test that a GET /health/ready checks the dependencies,
+//returning a 503ServiceUnavailable status code when any of them is down.
+func TestReadyRoute(t *testing.T) {
+	tests := []struct {
+		name        string
+		githubError apierrors.ApiError
+		wantCode    int
+		wantStatus  string
+	}{
+		{
+			name:       "every dependency up",
+			wantCode:   200,
+			wantStatus: models.HealthStatusUp,
+		},
+		{
+			name:        "invalid github credentials",
+			githubError: apierrors.NewApiError("invalid github credentials", "unauthorized", 401, apierrors.CauseList{}),
+			wantCode:    503,
+			wantStatus:  models.HealthStatusDown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, sqlStorage, githubClient := newTestRouter(t)
+
+			sqlStorage.EXPECT().Ping(gomock.Any()).Return(nil)
+			sqlStorage.EXPECT().
+				Get(gomock.Any(), gomock.Any(), storage.SchemaMigrationID).
+				DoAndReturn(func(ctx context.Context, migration *models.SchemaMigration, id interface{}) error {
+					migration.Version = storage.SchemaVersion
+					return nil
+				})
+			githubClient.EXPECT().CheckCredentials(gomock.Any()).Return(tt.githubError)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/health/ready", nil)
+			router.ServeHTTP(w, req)
+
+			var health models.Health
+			assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &health))
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.Equal(t, tt.wantStatus, health.Status)
+		})
+	}
+}
+
+//TestRequestID test that every response carries the correlation id of the request,
+//which is the github delivery id for the webhooks.
+func TestRequestID(t *testing.T) {
+	router, _, _ := newTestRouter(t)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/ping", nil)
+	req.Header.Set("X-GitHub-Delivery", "72d3162e-cc78-11e3-81ab-4c9367dc0958")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, "72d3162e-cc78-11e3-81ab-4c9367dc0958", w.Header().Get("X-Request-ID"))
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/ping", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Len(t, w.Header().Get("X-Request-ID"), 32)
+}
+
+//TestTracing test that every request is served inside a server span named after its route,
+//continuing the trace propagated by the caller.
+func TestTracing(t *testing.T) {
+	exporter := tracing.SetupInMemory()
+	router, _, _ := newTestRouter(t)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/ping", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(w, req)
+
+	spans := exporter.GetSpans()
+	if assert.Len(t, spans, 1) {
+		assert.Equal(t, "GET /ping", spans[0].Name)
+		assert.Equal(t, trace.SpanKindServer, spans[0].SpanKind)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext.TraceID().String())
+		assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent.SpanID().String())
+	}
+}
+
+//TestAuthorization test that the routes need an api token with the scope of the route
+//which can access the repository of the request, while the github webhooks do not need any but their signature.
+func TestAuthorization(t *testing.T) {
+	readToken := &models.APIToken{Scopes: utils.Stringify(models.APITokenScopeRead)}
+	configureToken := &models.APIToken{Scopes: utils.Stringify(models.APITokenScopeConfigure)}
+	ownerToken := &models.APIToken{Scopes: utils.Stringify(models.APITokenScopeConfigure), Repositories: utils.Stringify("hbalmes/*")}
+	repoToken := &models.APIToken{Scopes: utils.Stringify(models.APITokenScopeAdmin), Repositories: utils.Stringify("hbalmes/ci_cd-api")}
+
+	tests := []struct {
+		name          string
+		method        string
+		url           string
+		body          string
+		authorization string
+		signed        bool
+		token         *models.APIToken
+		tokenError    apierrors.ApiError
+		listTimes     int
+		wantCode      int
+	}{
+		{
+			name:       "api token missing",
+			method:     "GET",
+			url:        "/repositories/hbalmes/ci_cd-api/freeze-windows",
+			tokenError: apierrors.NewUnauthorizedApiError("an api token is required"),
+			wantCode:   401,
+		},
+		{
+			name:          "api token not sent as a bearer token",
+			method:        "GET",
+			url:           "/repositories/hbalmes/ci_cd-api/freeze-windows",
+			authorization: "token cicd_token",
+			wantCode:      401,
+		},
+		{
+			name:          "invalid api token",
+			method:        "GET",
+			url:           "/repositories/hbalmes/ci_cd-api/freeze-windows",
+			authorization: "Bearer cicd_token",
+			tokenError:    apierrors.NewUnauthorizedApiError("invalid api token"),
+			wantCode:      401,
+		},
+		{
+			name:          "read scope",
+			method:        "GET",
+			url:           "/repositories/hbalmes/ci_cd-api/freeze-windows",
+			authorization: "Bearer cicd_token",
+			token:         readToken,
+			listTimes:     1,
+			wantCode:      200,
+		},
+		{
+			name:          "read scope implied by the others",
+			method:        "GET",
+			url:           "/repositories/hbalmes/ci_cd-api/freeze-windows",
+			authorization: "Bearer cicd_token",
+			token:         configureToken,
+			listTimes:     1,
+			wantCode:      200,
+		},
+		{
+			name:          "configure scope missing",
+			method:        "DELETE",
+			url:           "/configurations/hbalmes/ci_cd-api",
+			authorization: "Bearer cicd_token",
+			token:         readToken,
+			wantCode:      403,
+		},
+		{
+			name:          "release scope missing",
+			method:        "POST",
+			url:           "/repositories/hbalmes/ci_cd-api/builds/1/rollback",
+			authorization: "Bearer cicd_token",
+			token:         configureToken,
+			wantCode:      403,
+		},
+		{
+			name:          "admin scope missing",
+			method:        "GET",
+			url:           "/tokens",
+			authorization: "Bearer cicd_token",
+			token:         configureToken,
+			wantCode:      403,
+		},
+		{
+			name:          "repository of the owner",
+			method:        "GET",
+			url:           "/repositories/HBalmes/ci_cd-api/freeze-windows",
+			authorization: "Bearer cicd_token",
+			token:         ownerToken,
+			listTimes:     1,
+			wantCode:      200,
+		},
+		{
+			name:          "repository of another owner",
+			method:        "GET",
+			url:           "/repositories/other/ci_cd-api/freeze-windows",
+			authorization: "Bearer cicd_token",
+			token:         ownerToken,
+			wantCode:      403,
+		},
+		{
+			name:          "another repository",
+			method:        "DELETE",
+			url:           "/configurations/hbalmes/other",
+			authorization: "Bearer cicd_token",
+			token:         repoToken,
+			wantCode:      403,
+		},
+		{
+			name:          "repository of the query",
+			method:        "GET",
+			url:           "/metrics/dora?repository=hbalmes/other",
+			authorization: "Bearer cicd_token",
+			token:         repoToken,
+			wantCode:      403,
+		},
+		{
+			name:          "repository of the configuration payload",
+			method:        "POST",
+			url:           "/configurations",
+			body:          `{"repository": {"owner": "hbalmes", "name": "other"}}`,
+			authorization: "Bearer cicd_token",
+			token:         repoToken,
+			wantCode:      403,
+		},
+		{
+			name:          "global resources with a restricted token",
+			method:        "POST",
+			url:           "/freeze-windows",
+			authorization: "Bearer cicd_token",
+			token:         ownerToken,
+			wantCode:      403,
+		},
+		{
+			name:     "github webhooks without api token",
+			method:   "POST",
+			url:      "/webhooks",
+			body:     "{",
+			signed:   true,
+			wantCode: 400,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			tokenService := interfaces.NewMockAPITokenService(ctrl)
+			freezeWindowService := interfaces.NewMockFreezeWindowService(ctrl)
+
+			tokenService.EXPECT().
+				Authenticate(gomock.Any(), strings.TrimPrefix(tt.authorization, "Bearer ")).
+				Return(tt.token, tt.tokenError).
+				MaxTimes(1)
+
+			freezeWindowService.EXPECT().
+				List(gomock.Any(), gomock.Any()).
+				Return([]models.FreezeWindow{}, nil).
+				Times(tt.listTimes)
+
+			router := Route(&services.Services{
+				APIToken:     tokenService,
+				FreezeWindow: freezeWindowService,
+			}, testWebhookSecret)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			if tt.signed {
+				req.Header.Set("X-Github-Event", "status")
+				req.Header.Set("X-GitHub-Delivery", "72d3162e-cc78-11e3-81ab-4c9367dc0958")
+				req.Header.Set("X-Hub-Signature-256", sign(testWebhookSecret, tt.body))
+			}
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+		})
+	}
+}
+
+//TestWebhookSignature test that the github webhooks are only processed when signed with the webhook secret,
+//with the signed body still bound to the payload.
+func TestWebhookSignature(t *testing.T) {
+	body := `{"id": 1, "sha": "a10867b14bb761a232cd80139fbd4c0d33264240", "state": "success"}`
+
+	tests := []struct {
+		name         string
+		signature    string
+		processTimes int
+		wantCode     int
+	}{
+		{
+			name:         "signed with the secret",
+			signature:    sign(testWebhookSecret, body),
+			processTimes: 1,
+			wantCode:     200,
+		},
+		{
+			name:     "unsigned",
+			wantCode: 401,
+		},
+		{
+			name:      "signed with another secret",
+			signature: sign("another-secret", body),
+			wantCode:  401,
+		},
+		{
+			name:      "signed with sha1",
+			signature: "sha1=7d38cdd689735b008b3c702edd92eea23791c5f6",
+			wantCode:  401,
+		},
+		{
+			name:      "signature not in hex",
+			signature: "sha256=not-hex",
+			wantCode:  401,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			webhookService := interfaces.NewMockWebhookService(ctrl)
+			webhookService.EXPECT().
+				ProcessStatusWebhook(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, payload *webhook.Status) (*webhook.Webhook, apierrors.ApiError) {
+					assert.Equal(t, "a10867b14bb761a232cd80139fbd4c0d33264240", *payload.Sha)
+					return &webhook.Webhook{}, nil
+				}).
+				Times(tt.processTimes)
+
+			router := Route(&services.Services{Webhook: webhookService}, testWebhookSecret)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/webhooks", strings.NewReader(body))
+			req.Header.Set("X-Github-Event", "status")
+			req.Header.Set("X-GitHub-Delivery", "72d3162e-cc78-11e3-81ab-4c9367dc0958")
+			if tt.signature != "" {
+				req.Header.Set("X-Hub-Signature-256", tt.signature)
+			}
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+		})
+	}
+}
@@ -1,28 +1,39 @@
 package controllers
 
 import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"github.com/gin-gonic/gin"
+	"github.com/hbalmes/ci_cd-api/api/metrics"
 	"github.com/hbalmes/ci_cd-api/api/models/webhook"
 	"github.com/hbalmes/ci_cd-api/api/services"
-	"github.com/hbalmes/ci_cd-api/api/services/storage"
 	"github.com/hbalmes/ci_cd-api/api/utils"
 	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"io/ioutil"
 	"net/http"
+	"strings"
 )
 
 const (
 	ghEventHeader      = "X-Github-Event"
 	ghDeliveryIDHeader = "X-GitHub-Delivery"
+	ghSignatureHeader  = "X-Hub-Signature-256"
+	ghSignaturePrefix  = "sha256="
 )
 
 type Webhook struct {
 	Service services.WebhookService
+	//Secret is the secret the github webhooks are signed with
+	Secret string
 }
 
-//NewWebhookController initializes a WebhookController
-func NewWebhookController(sql storage.SQLStorage) *Webhook {
+//NewWebhookController initializes a WebhookController which only accepts the webhooks signed with the given secret
+func NewWebhookController(service services.WebhookService, secret string) *Webhook {
 	return &Webhook{
-		Service: services.NewWebhookService(sql),
+		Service: service,
+		Secret:  secret,
 	}
 }
 
@@ -30,16 +41,35 @@ func NewWebhookController(sql storage.SQLStorage) *Webhook {
 //It could returns
 //	201Created in case of a success processing the creation
 //	400BadRequest in case of an error parsing the request payload
+//	401Unauthorized in case the webhook is not signed with the secret
 //	500InternalServerError in case of an internal error procesing the creation
 func (c *Webhook) CreateWebhook(ginContext *gin.Context) {
 	var errorStatusCode int
+
+	//Nothing is processed unless github signed the delivery, as anyone can post to this endpoint
+	if !c.isSigned(ginContext) {
+		recordWebhook(ginContext.GetHeader(ghEventHeader), nil, metrics.WebhookOutcomeInvalidSignature)
+		ginContext.JSON(
+			http.StatusUnauthorized,
+			apierrors.NewUnauthorizedApiError("invalid webhook signature"),
+		)
+		return
+	}
+
 	//Check if 'X-Github-Event' header is present
 	if webhookEvent, deliveryID := getGetGithubHeaders(ginContext); webhookEvent != "" && deliveryID != "" {
 
+		var action *string
+		outcome := metrics.WebhookOutcomeProcessed
+		defer func() {
+			recordWebhook(webhookEvent, action, outcome)
+		}()
+
 		switch webhookEvent {
 		case "status":
 			var statusWH webhook.Status
 			if err := ginContext.BindJSON(&statusWH); err != nil {
+				outcome = metrics.WebhookOutcomeInvalidPayload
 				ginContext.JSON(
 					http.StatusBadRequest,
 					apierrors.NewBadRequestApiError("invalid status webhook payload"),
@@ -47,7 +77,8 @@ func (c *Webhook) CreateWebhook(ginContext *gin.Context) {
 				return
 			}
 
-			whook, err := c.Service.ProcessStatusWebhook(&statusWH)
+			action = statusWH.State
+			whook, err := c.Service.ProcessStatusWebhook(ginContext.Request.Context(), &statusWH)
 
 			if err != nil {
 
@@ -56,6 +87,7 @@ func (c *Webhook) CreateWebhook(ginContext *gin.Context) {
 				} else {
 					errorStatusCode = err.Status()
 				}
+				outcome = metrics.GetWebhookOutcome(errorStatusCode)
 				ginContext.JSON(
 					errorStatusCode,
 					err,
@@ -71,6 +103,7 @@ func (c *Webhook) CreateWebhook(ginContext *gin.Context) {
 			var pullRequestReviewWH webhook.PullRequestReviewWebhook
 
 			if err := ginContext.BindJSON(&pullRequestReviewWH); err != nil {
+				outcome = metrics.WebhookOutcomeInvalidPayload
 				ginContext.JSON(
 					http.StatusBadRequest,
 					apierrors.NewBadRequestApiError("invalid pull request review webhook payload"),
@@ -78,9 +111,11 @@ func (c *Webhook) CreateWebhook(ginContext *gin.Context) {
 				return
 			}
 
-			whook, err := c.Service.ProcessPullRequestReviewWebhook(&pullRequestReviewWH)
+			action = pullRequestReviewWH.Action
+			whook, err := c.Service.ProcessPullRequestReviewWebhook(ginContext.Request.Context(), &pullRequestReviewWH)
 
 			if err != nil {
+				outcome = metrics.GetWebhookOutcome(err.Status())
 				ginContext.JSON(
 					http.StatusInternalServerError,
 					err,
@@ -95,6 +130,7 @@ func (c *Webhook) CreateWebhook(ginContext *gin.Context) {
 
 			var pullRequestWH *webhook.PullRequestWebhook
 			if err := ginContext.BindJSON(&pullRequestWH); err != nil {
+				outcome = metrics.WebhookOutcomeInvalidPayload
 				ginContext.JSON(
 					http.StatusBadRequest,
 					apierrors.NewBadRequestApiError("invalid pull_request webhook payload"),
@@ -102,9 +138,11 @@ func (c *Webhook) CreateWebhook(ginContext *gin.Context) {
 				return
 			}
 
-			whook, err := c.Service.ProcessPullRequestWebhook(pullRequestWH)
+			action = pullRequestWH.Action
+			whook, err := c.Service.ProcessPullRequestWebhook(ginContext.Request.Context(), pullRequestWH)
 
 			if err != nil {
+				outcome = metrics.GetWebhookOutcome(err.Status())
 				ginContext.JSON(
 					http.StatusInternalServerError,
 					err,
@@ -115,12 +153,88 @@ func (c *Webhook) CreateWebhook(ginContext *gin.Context) {
 			ginContext.JSON(http.StatusOK, whook.Marshall())
 			return
 
-		case "issue_comment", "push":
-			response := map[string]interface{}{"message": "webhook accepted"}
-			ginContext.JSON(http.StatusAccepted, response)
+		case "issue_comment":
+
+			var issueCommentWH webhook.IssueComment
+			if err := ginContext.BindJSON(&issueCommentWH); err != nil {
+				outcome = metrics.WebhookOutcomeInvalidPayload
+				ginContext.JSON(
+					http.StatusBadRequest,
+					apierrors.NewBadRequestApiError("invalid issue_comment webhook payload"),
+				)
+				return
+			}
+
+			action = issueCommentWH.Action
+			whook, err := c.Service.ProcessIssueCommentWebhook(ginContext.Request.Context(), &issueCommentWH)
+
+			if err != nil {
+				outcome = metrics.GetWebhookOutcome(err.Status())
+				ginContext.JSON(
+					err.Status(),
+					err,
+				)
+				return
+			}
+
+			ginContext.JSON(http.StatusOK, whook.Marshall())
+			return
+
+		case "push":
+
+			var pushWH webhook.Push
+			if err := ginContext.BindJSON(&pushWH); err != nil {
+				outcome = metrics.WebhookOutcomeInvalidPayload
+				ginContext.JSON(
+					http.StatusBadRequest,
+					apierrors.NewBadRequestApiError("invalid push webhook payload"),
+				)
+				return
+			}
+
+			whook, err := c.Service.ProcessPushWebhook(ginContext.Request.Context(), &pushWH)
+
+			if err != nil {
+				outcome = metrics.GetWebhookOutcome(err.Status())
+				ginContext.JSON(
+					err.Status(),
+					err,
+				)
+				return
+			}
+
+			ginContext.JSON(http.StatusOK, whook.Marshall())
+			return
+
+		case "deployment_status":
+
+			var deploymentStatusWH webhook.DeploymentStatus
+			if err := ginContext.BindJSON(&deploymentStatusWH); err != nil {
+				outcome = metrics.WebhookOutcomeInvalidPayload
+				ginContext.JSON(
+					http.StatusBadRequest,
+					apierrors.NewBadRequestApiError("invalid deployment_status webhook payload"),
+				)
+				return
+			}
+
+			action = deploymentStatusWH.Action
+			whook, err := c.Service.ProcessDeploymentStatusWebhook(ginContext.Request.Context(), &deploymentStatusWH)
+
+			if err != nil {
+				outcome = metrics.GetWebhookOutcome(err.Status())
+				ginContext.JSON(
+					err.Status(),
+					err,
+				)
+				return
+			}
+
+			ginContext.JSON(http.StatusOK, whook.Marshall())
 			return
 
 		default:
+			outcome = metrics.WebhookOutcomeUnsupported
 			ginContext.JSON(
 				http.StatusBadRequest,
 				apierrors.NewBadRequestApiError("Event not supported yet"),
@@ -137,6 +251,46 @@ func (c *Webhook) CreateWebhook(ginContext *gin.Context) {
 	}
 }
 
+//recordWebhook counts a received webhook
+//The unsupported events are grouped to keep the event label bounded
+func recordWebhook(event string, action *string, outcome string) {
+	if outcome == metrics.WebhookOutcomeUnsupported || outcome == metrics.WebhookOutcomeInvalidSignature {
+		event = "other"
+	}
+
+	actionLabel := ""
+	if action != nil {
+		actionLabel = *action
+	}
+
+	metrics.WebhooksReceived.WithLabelValues(event, actionLabel, outcome).Inc()
+}
+
+//isSigned checks the X-Hub-Signature-256 header against the HMAC-SHA256 of the raw body keyed with the secret.
+//The body is restored so the payload can still be bound.
+func (c *Webhook) isSigned(ginContext *gin.Context) bool {
+	signature := ginContext.GetHeader(ghSignatureHeader)
+	if c.Secret == "" || !strings.HasPrefix(signature, ghSignaturePrefix) {
+		return false
+	}
+
+	got, err := hex.DecodeString(strings.TrimPrefix(signature, ghSignaturePrefix))
+	if err != nil {
+		return false
+	}
+
+	body, err := ioutil.ReadAll(ginContext.Request.Body)
+	if err != nil {
+		return false
+	}
+	ginContext.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	mac := hmac.New(sha256.New, []byte(c.Secret))
+	mac.Write(body)
+
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
 func getGetGithubHeaders(context utils.HTTPContext) (string, string) {
 	ghEvent := context.GetHeader(ghEventHeader)
 	ghDeliveryID := context.GetHeader(ghDeliveryIDHeader)
@@ -1,13 +1,18 @@
 package main
 
 import (
-	"fmt"
-	"github.com/hbalmes/ci_cd-api/api/controllers/routers"
-	"github.com/hbalmes/ci_cd-api/api/models"
-	"github.com/hbalmes/ci_cd-api/api/models/webhook"
-	"github.com/hbalmes/ci_cd-api/api/services/storage"
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/hbalmes/ci_cd-api/api/app"
+	"github.com/hbalmes/ci_cd-api/api/configs"
+	"github.com/hbalmes/ci_cd-api/api/utils/logger"
+	"github.com/hbalmes/ci_cd-api/api/utils/tracing"
 	_ "github.com/jinzhu/gorm/dialects/mysql"
+	"github.com/rs/zerolog/log"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 )
 
 func init() {
@@ -20,28 +25,72 @@ func init() {
 	//}
 }
 
-const defaultPort = ":8080"
-
 func main() {
-	sql, err := storage.NewMySQL()
-	defer sql.Client.Close()
-	//Something was wrong stablishing the database connection
+	//Settings from the optional CONFIG_FILE and the env vars
+	cfg, err := configs.Load(os.Getenv("CONFIG_FILE"))
+	if err != nil {
+		log.Fatal().Err(err).Msg("There was an error loading the configuration")
+	}
+
+	//JSON logs, the requests are logged by the logger middleware
+	logger.Setup(cfg.Log)
+	gin.SetMode(gin.ReleaseMode)
+
+	//Traces
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
 	if err != nil {
-		fmt.Println("There was an error stablishing the MySQL connection")
+		log.Fatal().Err(err).Msg("There was an error setting up the tracing")
 	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			log.Error().Err(err).Msg("There was an error flushing the traces")
+		}
+	}()
 
-	sql.Client.AutoMigrate(&models.Configuration{}, &models.RequireStatusCheck{}, &webhook.Webhook{}, &models.PullRequest{}, &models.Build{}, &models.LatestBuild{})
+	//Storage, clients and services, built once and shared by the routes and the scheduler
+	a, err := app.New(context.Background(), cfg)
+	if err != nil {
+		log.Fatal().Err(err).Msg("There was an error starting the application")
+	}
+	defer a.Close()
 
-	routers.SQLConnection = sql
+	//Release trains and interrupted jobs
+	a.Scheduler.Start()
 
-	router := routers.Route()
 	//Init GinGonic server
+	server := &http.Server{
+		Addr:    ":" + cfg.Server.Port,
+		Handler: a.Handler(),
+	}
 
-	serverPort := os.Getenv("PORT")
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Info().Str("port", cfg.Server.Port).Msg("starting server")
+		serverErr <- server.ListenAndServe()
+	}()
 
-	//Hack to heroku
-	if serverPort == "" {
-		serverPort = defaultPort
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	select {
+	case err := <-serverErr:
+		log.Error().Err(err).Msg("error running the server")
+	case sig := <-signals:
+		log.Info().Str("signal", sig.String()).Msg("shutting down")
+	}
+
+	//Stops accepting requests and drains the in-flight ones and the running jobs.
+	//The work not finished before the deadline is resumed on the next start.
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		log.Error().Err(err).Msg("in-flight requests not drained before the shutdown timeout")
 	}
-	router.Run(":" + serverPort)
+
+	if err := a.Scheduler.Stop(ctx); err != nil {
+		log.Error().Err(err).Msg("running jobs not drained before the shutdown timeout")
+	}
+
+	log.Info().Msg("server stopped")
 }
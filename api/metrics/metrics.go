@@ -0,0 +1,135 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "ci_cd_api"
+
+//Webhook outcomes
+const (
+	WebhookOutcomeProcessed        = "processed"
+	WebhookOutcomeSkipped          = "skipped"
+	WebhookOutcomeAlreadyExists    = "already_exists"
+	WebhookOutcomeInvalidPayload   = "invalid_payload"
+	WebhookOutcomeUnsupported      = "unsupported"
+	WebhookOutcomeInvalidSignature = "invalid_signature"
+	WebhookOutcomeError            = "error"
+)
+
+var (
+	//WebhooksReceived counts the github webhooks received by event, action and outcome
+	WebhooksReceived = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "webhooks_received_total",
+		Help:      "Github webhooks received by event, action and outcome.",
+	}, []string{"event", "action", "outcome"})
+
+	//WebhookProcessingDuration observes the latency of every Process*Webhook
+	WebhookProcessingDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "webhook_processing_duration_seconds",
+		Help:      "Latency of the github webhooks processing by processor.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"processor"})
+
+	//BuildsCreated counts the builds created by type
+	BuildsCreated = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "builds_created_total",
+		Help:      "Builds created by type.",
+	}, []string{"type"})
+
+	//GithubRequests counts the github api calls by client method and status code
+	GithubRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "github_requests_total",
+		Help:      "Github api calls by client method and status code.",
+	}, []string{"method", "status_code"})
+
+	//GithubRequestDuration observes the latency of the github api calls by client method
+	GithubRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "github_request_duration_seconds",
+		Help:      "Latency of the github api calls by client method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+
+	//DBQueryDuration observes the latency of the database queries by operation
+	DBQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Latency of the database queries by operation.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation"})
+
+	//MergeQueueEntries is the number of pull requests queued or being tested by repository and base branch
+	//It is set every time the queue of a branch is read, so it is the same for every instance
+	MergeQueueEntries = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "merge_queue_entries",
+		Help:      "Pull requests queued or being tested by repository and base branch.",
+	}, []string{"repository", "branch"})
+
+	//JobsRunning is the number of jobs being processed by this instance by type
+	JobsRunning = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "jobs_running",
+		Help:      "Jobs being processed by type.",
+	}, []string{"type"})
+)
+
+//Handler exposes the registered metrics in the prometheus text format
+func Handler() gin.HandlerFunc {
+	h := promhttp.Handler()
+	return func(c *gin.Context) {
+		h.ServeHTTP(c.Writer, c.Request)
+	}
+}
+
+//ObserveWebhookProcessing observes the time elapsed since start for the given webhook processor
+//It is meant to be deferred at the beginning of the processing
+func ObserveWebhookProcessing(processor string, start time.Time) {
+	WebhookProcessingDuration.WithLabelValues(processor).Observe(time.Since(start).Seconds())
+}
+
+//TrackJob counts a job of the given type as running until the returned function is called
+//It is meant to be deferred at the beginning of the processing
+func TrackJob(jobType string) func() {
+	running := JobsRunning.WithLabelValues(jobType)
+	running.Inc()
+	return running.Dec
+}
+
+//ObserveDBQuery observes the time elapsed since start for the given database operation
+func ObserveDBQuery(operation string, start time.Time) {
+	DBQueryDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+}
+
+//ObserveGithubRequest counts a github api call and observes its latency
+//The status code is 0 when the request could not be performed
+func ObserveGithubRequest(method string, statusCode int, start time.Time) {
+	GithubRequests.WithLabelValues(method, strconv.Itoa(statusCode)).Inc()
+	GithubRequestDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
+
+//GetWebhookOutcome returns the outcome label of a processed webhook given its response status code
+func GetWebhookOutcome(statusCode int) string {
+	switch {
+	case statusCode == http.StatusPartialContent:
+		return WebhookOutcomeSkipped
+	case statusCode == http.StatusAccepted:
+		return WebhookOutcomeAlreadyExists
+	case statusCode < http.StatusBadRequest:
+		return WebhookOutcomeProcessed
+	default:
+		return WebhookOutcomeError
+	}
+}
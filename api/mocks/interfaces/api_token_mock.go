@@ -0,0 +1,96 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: services/api_token.go
+
+// Package interfaces is a generated GoMock package.
+package interfaces
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/hbalmes/ci_cd-api/api/models"
+	apierrors "github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	reflect "reflect"
+	time "time"
+)
+
+// MockAPITokenService is a mock of APITokenService interface
+type MockAPITokenService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAPITokenServiceMockRecorder
+}
+
+// MockAPITokenServiceMockRecorder is the mock recorder for MockAPITokenService
+type MockAPITokenServiceMockRecorder struct {
+	mock *MockAPITokenService
+}
+
+// NewMockAPITokenService creates a new mock instance
+func NewMockAPITokenService(ctrl *gomock.Controller) *MockAPITokenService {
+	mock := &MockAPITokenService{ctrl: ctrl}
+	mock.recorder = &MockAPITokenServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAPITokenService) EXPECT() *MockAPITokenServiceMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockAPITokenService) Create(ctx context.Context, r *models.APITokenPayload) (*models.APIToken, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, r)
+	ret0, _ := ret[0].(*models.APIToken)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockAPITokenServiceMockRecorder) Create(ctx, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAPITokenService)(nil).Create), ctx, r)
+}
+
+// List mocks base method
+func (m *MockAPITokenService) List(ctx context.Context) ([]models.APIToken, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx)
+	ret0, _ := ret[0].([]models.APIToken)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List
+func (mr *MockAPITokenServiceMockRecorder) List(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockAPITokenService)(nil).List), ctx)
+}
+
+// Revoke mocks base method
+func (m *MockAPITokenService) Revoke(ctx context.Context, id uint32, now time.Time) apierrors.ApiError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", ctx, id, now)
+	ret0, _ := ret[0].(apierrors.ApiError)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke
+func (mr *MockAPITokenServiceMockRecorder) Revoke(ctx, id, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockAPITokenService)(nil).Revoke), ctx, id, now)
+}
+
+// Authenticate mocks base method
+func (m *MockAPITokenService) Authenticate(ctx context.Context, token string) (*models.APIToken, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", ctx, token)
+	ret0, _ := ret[0].(*models.APIToken)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// Authenticate indicates an expected call of Authenticate
+func (mr *MockAPITokenServiceMockRecorder) Authenticate(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockAPITokenService)(nil).Authenticate), ctx, token)
+}
@@ -0,0 +1,96 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: services/approval.go
+
+// Package interfaces is a generated GoMock package.
+package interfaces
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/hbalmes/ci_cd-api/api/models"
+	apierrors "github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	reflect "reflect"
+)
+
+// MockApprovalService is a mock of ApprovalService interface
+type MockApprovalService struct {
+	ctrl     *gomock.Controller
+	recorder *MockApprovalServiceMockRecorder
+}
+
+// MockApprovalServiceMockRecorder is the mock recorder for MockApprovalService
+type MockApprovalServiceMockRecorder struct {
+	mock *MockApprovalService
+}
+
+// NewMockApprovalService creates a new mock instance
+func NewMockApprovalService(ctrl *gomock.Controller) *MockApprovalService {
+	mock := &MockApprovalService{ctrl: ctrl}
+	mock.recorder = &MockApprovalServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockApprovalService) EXPECT() *MockApprovalServiceMockRecorder {
+	return m.recorder
+}
+
+// Approve mocks base method
+func (m *MockApprovalService) Approve(ctx context.Context, repositoryName string, buildID uint32, environment string, r *models.ApprovalPayload) (*models.Approval, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Approve", ctx, repositoryName, buildID, environment, r)
+	ret0, _ := ret[0].(*models.Approval)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// Approve indicates an expected call of Approve
+func (mr *MockApprovalServiceMockRecorder) Approve(ctx, repositoryName, buildID, environment, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Approve", reflect.TypeOf((*MockApprovalService)(nil).Approve), ctx, repositoryName, buildID, environment, r)
+}
+
+// Reject mocks base method
+func (m *MockApprovalService) Reject(ctx context.Context, repositoryName string, buildID uint32, environment string, r *models.ApprovalPayload) (*models.Approval, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reject", ctx, repositoryName, buildID, environment, r)
+	ret0, _ := ret[0].(*models.Approval)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// Reject indicates an expected call of Reject
+func (mr *MockApprovalServiceMockRecorder) Reject(ctx, repositoryName, buildID, environment, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reject", reflect.TypeOf((*MockApprovalService)(nil).Reject), ctx, repositoryName, buildID, environment, r)
+}
+
+// GetGate mocks base method
+func (m *MockApprovalService) GetGate(ctx context.Context, repositoryName string, buildID uint32, environment string) (*models.ApprovalGate, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGate", ctx, repositoryName, buildID, environment)
+	ret0, _ := ret[0].(*models.ApprovalGate)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// GetGate indicates an expected call of GetGate
+func (mr *MockApprovalServiceMockRecorder) GetGate(ctx, repositoryName, buildID, environment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGate", reflect.TypeOf((*MockApprovalService)(nil).GetGate), ctx, repositoryName, buildID, environment)
+}
+
+// CheckGate mocks base method
+func (m *MockApprovalService) CheckGate(ctx context.Context, config *models.Configuration, environment *models.Environment, buildID uint32) (*models.ApprovalGate, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckGate", ctx, config, environment, buildID)
+	ret0, _ := ret[0].(*models.ApprovalGate)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// CheckGate indicates an expected call of CheckGate
+func (mr *MockApprovalServiceMockRecorder) CheckGate(ctx, config, environment, buildID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckGate", reflect.TypeOf((*MockApprovalService)(nil).CheckGate), ctx, config, environment, buildID)
+}
@@ -0,0 +1,65 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: services/audit.go
+
+// Package interfaces is a generated GoMock package.
+package interfaces
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/hbalmes/ci_cd-api/api/models"
+	apierrors "github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	reflect "reflect"
+)
+
+// MockAuditService is a mock of AuditService interface
+type MockAuditService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditServiceMockRecorder
+}
+
+// MockAuditServiceMockRecorder is the mock recorder for MockAuditService
+type MockAuditServiceMockRecorder struct {
+	mock *MockAuditService
+}
+
+// NewMockAuditService creates a new mock instance
+func NewMockAuditService(ctrl *gomock.Controller) *MockAuditService {
+	mock := &MockAuditService{ctrl: ctrl}
+	mock.recorder = &MockAuditServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAuditService) EXPECT() *MockAuditServiceMockRecorder {
+	return m.recorder
+}
+
+// Record mocks base method
+func (m *MockAuditService) Record(ctx context.Context, event *models.AuditEvent) apierrors.ApiError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Record", ctx, event)
+	ret0, _ := ret[0].(apierrors.ApiError)
+	return ret0
+}
+
+// Record indicates an expected call of Record
+func (mr *MockAuditServiceMockRecorder) Record(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockAuditService)(nil).Record), ctx, event)
+}
+
+// List mocks base method
+func (m *MockAuditService) List(ctx context.Context, repositoryName string) ([]models.AuditEvent, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, repositoryName)
+	ret0, _ := ret[0].([]models.AuditEvent)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List
+func (mr *MockAuditServiceMockRecorder) List(ctx, repositoryName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockAuditService)(nil).List), ctx, repositoryName)
+}
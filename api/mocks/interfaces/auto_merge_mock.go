@@ -0,0 +1,52 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: services/auto_merge.go
+
+// Package interfaces is a generated GoMock package.
+package interfaces
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/hbalmes/ci_cd-api/api/models"
+	webhook "github.com/hbalmes/ci_cd-api/api/models/webhook"
+	apierrors "github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	reflect "reflect"
+)
+
+// MockAutoMergeService is a mock of AutoMergeService interface
+type MockAutoMergeService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAutoMergeServiceMockRecorder
+}
+
+// MockAutoMergeServiceMockRecorder is the mock recorder for MockAutoMergeService
+type MockAutoMergeServiceMockRecorder struct {
+	mock *MockAutoMergeService
+}
+
+// NewMockAutoMergeService creates a new mock instance
+func NewMockAutoMergeService(ctrl *gomock.Controller) *MockAutoMergeService {
+	mock := &MockAutoMergeService{ctrl: ctrl}
+	mock.recorder = &MockAutoMergeServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAutoMergeService) EXPECT() *MockAutoMergeServiceMockRecorder {
+	return m.recorder
+}
+
+// ProcessAutoMerge mocks base method
+func (m *MockAutoMergeService) ProcessAutoMerge(ctx context.Context, config *models.Configuration, payload *webhook.Status) (bool, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProcessAutoMerge", ctx, config, payload)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// ProcessAutoMerge indicates an expected call of ProcessAutoMerge
+func (mr *MockAutoMergeServiceMockRecorder) ProcessAutoMerge(ctx, config, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessAutoMerge", reflect.TypeOf((*MockAutoMergeService)(nil).ProcessAutoMerge), ctx, config, payload)
+}
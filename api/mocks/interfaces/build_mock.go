@@ -5,11 +5,14 @@
 package interfaces
 
 import (
+	context "context"
+	semver "github.com/coreos/go-semver/semver"
 	gomock "github.com/golang/mock/gomock"
 	models "github.com/hbalmes/ci_cd-api/api/models"
 	webhook "github.com/hbalmes/ci_cd-api/api/models/webhook"
 	apierrors "github.com/hbalmes/ci_cd-api/api/utils/apierrors"
 	reflect "reflect"
+	time "time"
 )
 
 // MockBuildService is a mock of BuildService interface
@@ -36,16 +39,150 @@ func (m *MockBuildService) EXPECT() *MockBuildServiceMockRecorder {
 }
 
 // ProcessBuild mocks base method
-func (m *MockBuildService) ProcessBuild(config *models.Configuration, payload *webhook.Status) (*models.Build, apierrors.ApiError) {
+func (m *MockBuildService) ProcessBuild(ctx context.Context, config *models.Configuration, payload *webhook.Status) (*models.Build, apierrors.ApiError) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ProcessBuild", config, payload)
+	ret := m.ctrl.Call(m, "ProcessBuild", ctx, config, payload)
 	ret0, _ := ret[0].(*models.Build)
 	ret1, _ := ret[1].(apierrors.ApiError)
 	return ret0, ret1
 }
 
 // ProcessBuild indicates an expected call of ProcessBuild
-func (mr *MockBuildServiceMockRecorder) ProcessBuild(config, payload interface{}) *gomock.Call {
+func (mr *MockBuildServiceMockRecorder) ProcessBuild(ctx, config, payload interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessBuild", reflect.TypeOf((*MockBuildService)(nil).ProcessBuild), config, payload)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessBuild", reflect.TypeOf((*MockBuildService)(nil).ProcessBuild), ctx, config, payload)
+}
+
+// GetBuildeableStatusChecks mocks base method
+func (m *MockBuildService) GetBuildeableStatusChecks(config *models.Configuration) []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBuildeableStatusChecks", config)
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// GetBuildeableStatusChecks indicates an expected call of GetBuildeableStatusChecks
+func (mr *MockBuildServiceMockRecorder) GetBuildeableStatusChecks(config interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBuildeableStatusChecks", reflect.TypeOf((*MockBuildService)(nil).GetBuildeableStatusChecks), config)
+}
+
+// GetStatusChecksState mocks base method
+func (m *MockBuildService) GetStatusChecksState(ctx context.Context, reqSCConfigured []string, payload *webhook.Status) map[string]bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatusChecksState", ctx, reqSCConfigured, payload)
+	ret0, _ := ret[0].(map[string]bool)
+	return ret0
+}
+
+// GetStatusChecksState indicates an expected call of GetStatusChecksState
+func (mr *MockBuildServiceMockRecorder) GetStatusChecksState(ctx, reqSCConfigured, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatusChecksState", reflect.TypeOf((*MockBuildService)(nil).GetStatusChecksState), ctx, reqSCConfigured, payload)
+}
+
+// GetReleaseOverride mocks base method
+func (m *MockBuildService) GetReleaseOverride(ctx context.Context, pr *models.PullRequest) *models.ReleaseOverride {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReleaseOverride", ctx, pr)
+	ret0, _ := ret[0].(*models.ReleaseOverride)
+	return ret0
+}
+
+// GetReleaseOverride indicates an expected call of GetReleaseOverride
+func (mr *MockBuildServiceMockRecorder) GetReleaseOverride(ctx, pr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReleaseOverride", reflect.TypeOf((*MockBuildService)(nil).GetReleaseOverride), ctx, pr)
+}
+
+// ResetFreezeStatus mocks base method
+func (m *MockBuildService) ResetFreezeStatus(ctx context.Context, config *models.Configuration, pr *models.PullRequest, description string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ResetFreezeStatus", ctx, config, pr, description)
+}
+
+// ResetFreezeStatus indicates an expected call of ResetFreezeStatus
+func (mr *MockBuildServiceMockRecorder) ResetFreezeStatus(ctx, config, pr, description interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetFreezeStatus", reflect.TypeOf((*MockBuildService)(nil).ResetFreezeStatus), ctx, config, pr, description)
+}
+
+// ResetEndedFreezes mocks base method
+func (m *MockBuildService) ResetEndedFreezes(ctx context.Context, now time.Time) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ResetEndedFreezes", ctx, now)
+}
+
+// ResetEndedFreezes indicates an expected call of ResetEndedFreezes
+func (mr *MockBuildServiceMockRecorder) ResetEndedFreezes(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetEndedFreezes", reflect.TypeOf((*MockBuildService)(nil).ResetEndedFreezes), ctx, now)
+}
+
+// BackMerge mocks base method
+func (m *MockBuildService) BackMerge(ctx context.Context, config *models.Configuration, pr *models.PullRequest) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "BackMerge", ctx, config, pr)
+}
+
+// BackMerge indicates an expected call of BackMerge
+func (mr *MockBuildServiceMockRecorder) BackMerge(ctx, config, pr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BackMerge", reflect.TypeOf((*MockBuildService)(nil).BackMerge), ctx, config, pr)
+}
+
+// GetLatestBuild mocks base method
+func (m *MockBuildService) GetLatestBuild(ctx context.Context, config *models.Configuration) *semver.Version {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestBuild", ctx, config)
+	ret0, _ := ret[0].(*semver.Version)
+	return ret0
+}
+
+// GetLatestBuild indicates an expected call of GetLatestBuild
+func (mr *MockBuildServiceMockRecorder) GetLatestBuild(ctx, config interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestBuild", reflect.TypeOf((*MockBuildService)(nil).GetLatestBuild), ctx, config)
+}
+
+// IncrementSemVer mocks base method
+func (m *MockBuildService) IncrementSemVer(version semver.Version, incrementer string) semver.Version {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementSemVer", version, incrementer)
+	ret0, _ := ret[0].(semver.Version)
+	return ret0
+}
+
+// IncrementSemVer indicates an expected call of IncrementSemVer
+func (mr *MockBuildServiceMockRecorder) IncrementSemVer(version, incrementer interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementSemVer", reflect.TypeOf((*MockBuildService)(nil).IncrementSemVer), version, incrementer)
+}
+
+// SaveBuild mocks base method
+func (m *MockBuildService) SaveBuild(ctx context.Context, build *models.Build) apierrors.ApiError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveBuild", ctx, build)
+	ret0, _ := ret[0].(apierrors.ApiError)
+	return ret0
+}
+
+// SaveBuild indicates an expected call of SaveBuild
+func (mr *MockBuildServiceMockRecorder) SaveBuild(ctx, build interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveBuild", reflect.TypeOf((*MockBuildService)(nil).SaveBuild), ctx, build)
+}
+
+// CreateAndSaveLatestBuild mocks base method
+func (m *MockBuildService) CreateAndSaveLatestBuild(ctx context.Context, build *models.Build, lastBuild *semver.Version) apierrors.ApiError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAndSaveLatestBuild", ctx, build, lastBuild)
+	ret0, _ := ret[0].(apierrors.ApiError)
+	return ret0
+}
+
+// CreateAndSaveLatestBuild indicates an expected call of CreateAndSaveLatestBuild
+func (mr *MockBuildServiceMockRecorder) CreateAndSaveLatestBuild(ctx, build, lastBuild interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAndSaveLatestBuild", reflect.TypeOf((*MockBuildService)(nil).CreateAndSaveLatestBuild), ctx, build, lastBuild)
 }
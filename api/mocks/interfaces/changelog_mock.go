@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: services/changelog.go
+
+// Package interfaces is a generated GoMock package.
+package interfaces
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/hbalmes/ci_cd-api/api/models"
+	apierrors "github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	reflect "reflect"
+)
+
+// MockChangelogService is a mock of ChangelogService interface
+type MockChangelogService struct {
+	ctrl     *gomock.Controller
+	recorder *MockChangelogServiceMockRecorder
+}
+
+// MockChangelogServiceMockRecorder is the mock recorder for MockChangelogService
+type MockChangelogServiceMockRecorder struct {
+	mock *MockChangelogService
+}
+
+// NewMockChangelogService creates a new mock instance
+func NewMockChangelogService(ctrl *gomock.Controller) *MockChangelogService {
+	mock := &MockChangelogService{ctrl: ctrl}
+	mock.recorder = &MockChangelogServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockChangelogService) EXPECT() *MockChangelogServiceMockRecorder {
+	return m.recorder
+}
+
+// Prepend mocks base method
+func (m *MockChangelogService) Prepend(ctx context.Context, config *models.Configuration, pullRequest *models.PullRequest, sha string, notes *models.ReleaseNotes, body string) (*models.TreeEntry, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Prepend", ctx, config, pullRequest, sha, notes, body)
+	ret0, _ := ret[0].(*models.TreeEntry)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// Prepend indicates an expected call of Prepend
+func (mr *MockChangelogServiceMockRecorder) Prepend(ctx, config, pullRequest, sha, notes, body interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Prepend", reflect.TypeOf((*MockChangelogService)(nil).Prepend), ctx, config, pullRequest, sha, notes, body)
+}
@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: services/chatops.go
+
+// Package interfaces is a generated GoMock package.
+package interfaces
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/hbalmes/ci_cd-api/api/models"
+	apierrors "github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	reflect "reflect"
+)
+
+// MockChatOpsService is a mock of ChatOpsService interface
+type MockChatOpsService struct {
+	ctrl     *gomock.Controller
+	recorder *MockChatOpsServiceMockRecorder
+}
+
+// MockChatOpsServiceMockRecorder is the mock recorder for MockChatOpsService
+type MockChatOpsServiceMockRecorder struct {
+	mock *MockChatOpsService
+}
+
+// NewMockChatOpsService creates a new mock instance
+func NewMockChatOpsService(ctrl *gomock.Controller) *MockChatOpsService {
+	mock := &MockChatOpsService{ctrl: ctrl}
+	mock.recorder = &MockChatOpsServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockChatOpsService) EXPECT() *MockChatOpsServiceMockRecorder {
+	return m.recorder
+}
+
+// ProcessCommand mocks base method
+func (m *MockChatOpsService) ProcessCommand(ctx context.Context, config *models.Configuration, pullRequest *models.PullRequest, sender, comment string) (string, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProcessCommand", ctx, config, pullRequest, sender, comment)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// ProcessCommand indicates an expected call of ProcessCommand
+func (mr *MockChatOpsServiceMockRecorder) ProcessCommand(ctx, config, pullRequest, sender, comment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessCommand", reflect.TypeOf((*MockChatOpsService)(nil).ProcessCommand), ctx, config, pullRequest, sender, comment)
+}
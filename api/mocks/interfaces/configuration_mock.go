@@ -5,6 +5,7 @@
 package interfaces
 
 import (
+	context "context"
 	gomock "github.com/golang/mock/gomock"
 	models "github.com/hbalmes/ci_cd-api/api/models"
 	apierrors "github.com/hbalmes/ci_cd-api/api/utils/apierrors"
@@ -35,60 +36,60 @@ func (m *MockConfigurationService) EXPECT() *MockConfigurationServiceMockRecorde
 }
 
 // Create mocks base method
-func (m *MockConfigurationService) Create(r *models.PostRequestPayload) (*models.Configuration, apierrors.ApiError) {
+func (m *MockConfigurationService) Create(ctx context.Context, r *models.PostRequestPayload) (*models.Configuration, apierrors.ApiError) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", r)
+	ret := m.ctrl.Call(m, "Create", ctx, r)
 	ret0, _ := ret[0].(*models.Configuration)
 	ret1, _ := ret[1].(apierrors.ApiError)
 	return ret0, ret1
 }
 
 // Create indicates an expected call of Create
-func (mr *MockConfigurationServiceMockRecorder) Create(r interface{}) *gomock.Call {
+func (mr *MockConfigurationServiceMockRecorder) Create(ctx, r interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockConfigurationService)(nil).Create), r)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockConfigurationService)(nil).Create), ctx, r)
 }
 
 // Get mocks base method
-func (m *MockConfigurationService) Get(arg0 string) (*models.Configuration, error) {
+func (m *MockConfigurationService) Get(ctx context.Context, id string) (*models.Configuration, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Get", arg0)
+	ret := m.ctrl.Call(m, "Get", ctx, id)
 	ret0, _ := ret[0].(*models.Configuration)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Get indicates an expected call of Get
-func (mr *MockConfigurationServiceMockRecorder) Get(arg0 interface{}) *gomock.Call {
+func (mr *MockConfigurationServiceMockRecorder) Get(ctx, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockConfigurationService)(nil).Get), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockConfigurationService)(nil).Get), ctx, id)
 }
 
 // Update mocks base method
-func (m *MockConfigurationService) Update(r *models.PutRequestPayload) (*models.Configuration, error) {
+func (m *MockConfigurationService) Update(ctx context.Context, r *models.PutRequestPayload) (*models.Configuration, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", r)
+	ret := m.ctrl.Call(m, "Update", ctx, r)
 	ret0, _ := ret[0].(*models.Configuration)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Update indicates an expected call of Update
-func (mr *MockConfigurationServiceMockRecorder) Update(r interface{}) *gomock.Call {
+func (mr *MockConfigurationServiceMockRecorder) Update(ctx, r interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockConfigurationService)(nil).Update), r)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockConfigurationService)(nil).Update), ctx, r)
 }
 
 // Delete mocks base method
-func (m *MockConfigurationService) Delete(id string) error {
+func (m *MockConfigurationService) Delete(ctx context.Context, id string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", id)
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete
-func (mr *MockConfigurationServiceMockRecorder) Delete(id interface{}) *gomock.Call {
+func (mr *MockConfigurationServiceMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockConfigurationService)(nil).Delete), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockConfigurationService)(nil).Delete), ctx, id)
 }
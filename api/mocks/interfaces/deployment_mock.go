@@ -0,0 +1,82 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: services/deployment.go
+
+// Package interfaces is a generated GoMock package.
+package interfaces
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/hbalmes/ci_cd-api/api/models"
+	webhook "github.com/hbalmes/ci_cd-api/api/models/webhook"
+	apierrors "github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	reflect "reflect"
+)
+
+// MockDeploymentService is a mock of DeploymentService interface
+type MockDeploymentService struct {
+	ctrl     *gomock.Controller
+	recorder *MockDeploymentServiceMockRecorder
+}
+
+// MockDeploymentServiceMockRecorder is the mock recorder for MockDeploymentService
+type MockDeploymentServiceMockRecorder struct {
+	mock *MockDeploymentService
+}
+
+// NewMockDeploymentService creates a new mock instance
+func NewMockDeploymentService(ctrl *gomock.Controller) *MockDeploymentService {
+	mock := &MockDeploymentService{ctrl: ctrl}
+	mock.recorder = &MockDeploymentServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockDeploymentService) EXPECT() *MockDeploymentServiceMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockDeploymentService) Create(ctx context.Context, repositoryName string, r *models.DeploymentPayload) (*models.Deployment, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, repositoryName, r)
+	ret0, _ := ret[0].(*models.Deployment)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockDeploymentServiceMockRecorder) Create(ctx, repositoryName, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockDeploymentService)(nil).Create), ctx, repositoryName, r)
+}
+
+// List mocks base method
+func (m *MockDeploymentService) List(ctx context.Context, repositoryName, environment string) ([]models.Deployment, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, repositoryName, environment)
+	ret0, _ := ret[0].([]models.Deployment)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List
+func (mr *MockDeploymentServiceMockRecorder) List(ctx, repositoryName, environment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockDeploymentService)(nil).List), ctx, repositoryName, environment)
+}
+
+// ProcessStatus mocks base method
+func (m *MockDeploymentService) ProcessStatus(ctx context.Context, payload *webhook.DeploymentStatus) (*models.Deployment, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProcessStatus", ctx, payload)
+	ret0, _ := ret[0].(*models.Deployment)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// ProcessStatus indicates an expected call of ProcessStatus
+func (mr *MockDeploymentServiceMockRecorder) ProcessStatus(ctx, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessStatus", reflect.TypeOf((*MockDeploymentService)(nil).ProcessStatus), ctx, payload)
+}
@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: services/dora.go
+
+// Package interfaces is a generated GoMock package.
+package interfaces
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/hbalmes/ci_cd-api/api/models"
+	apierrors "github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	reflect "reflect"
+)
+
+// MockDoraService is a mock of DoraService interface
+type MockDoraService struct {
+	ctrl     *gomock.Controller
+	recorder *MockDoraServiceMockRecorder
+}
+
+// MockDoraServiceMockRecorder is the mock recorder for MockDoraService
+type MockDoraServiceMockRecorder struct {
+	mock *MockDoraService
+}
+
+// NewMockDoraService creates a new mock instance
+func NewMockDoraService(ctrl *gomock.Controller) *MockDoraService {
+	mock := &MockDoraService{ctrl: ctrl}
+	mock.recorder = &MockDoraServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockDoraService) EXPECT() *MockDoraServiceMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method
+func (m *MockDoraService) Get(ctx context.Context, query *models.DoraQuery) (*models.DoraMetrics, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, query)
+	ret0, _ := ret[0].(*models.DoraMetrics)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockDoraServiceMockRecorder) Get(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockDoraService)(nil).Get), ctx, query)
+}
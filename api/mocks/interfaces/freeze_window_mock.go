@@ -0,0 +1,109 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: services/freeze_window.go
+
+// Package interfaces is a generated GoMock package.
+package interfaces
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/hbalmes/ci_cd-api/api/models"
+	apierrors "github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	reflect "reflect"
+	time "time"
+)
+
+// MockFreezeWindowService is a mock of FreezeWindowService interface
+type MockFreezeWindowService struct {
+	ctrl     *gomock.Controller
+	recorder *MockFreezeWindowServiceMockRecorder
+}
+
+// MockFreezeWindowServiceMockRecorder is the mock recorder for MockFreezeWindowService
+type MockFreezeWindowServiceMockRecorder struct {
+	mock *MockFreezeWindowService
+}
+
+// NewMockFreezeWindowService creates a new mock instance
+func NewMockFreezeWindowService(ctrl *gomock.Controller) *MockFreezeWindowService {
+	mock := &MockFreezeWindowService{ctrl: ctrl}
+	mock.recorder = &MockFreezeWindowServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockFreezeWindowService) EXPECT() *MockFreezeWindowServiceMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockFreezeWindowService) Create(ctx context.Context, repositoryName string, r *models.FreezeWindowPayload) (*models.FreezeWindow, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, repositoryName, r)
+	ret0, _ := ret[0].(*models.FreezeWindow)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockFreezeWindowServiceMockRecorder) Create(ctx, repositoryName, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockFreezeWindowService)(nil).Create), ctx, repositoryName, r)
+}
+
+// List mocks base method
+func (m *MockFreezeWindowService) List(ctx context.Context, repositoryName string) ([]models.FreezeWindow, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, repositoryName)
+	ret0, _ := ret[0].([]models.FreezeWindow)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List
+func (mr *MockFreezeWindowServiceMockRecorder) List(ctx, repositoryName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockFreezeWindowService)(nil).List), ctx, repositoryName)
+}
+
+// Delete mocks base method
+func (m *MockFreezeWindowService) Delete(ctx context.Context, repositoryName string, id uint32) apierrors.ApiError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, repositoryName, id)
+	ret0, _ := ret[0].(apierrors.ApiError)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockFreezeWindowServiceMockRecorder) Delete(ctx, repositoryName, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockFreezeWindowService)(nil).Delete), ctx, repositoryName, id)
+}
+
+// GetActive mocks base method
+func (m *MockFreezeWindowService) GetActive(ctx context.Context, repositoryName string, now time.Time) (*models.FreezeWindow, *time.Time, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActive", ctx, repositoryName, now)
+	ret0, _ := ret[0].(*models.FreezeWindow)
+	ret1, _ := ret[1].(*time.Time)
+	ret2, _ := ret[2].(apierrors.ApiError)
+	return ret0, ret1, ret2
+}
+
+// GetActive indicates an expected call of GetActive
+func (mr *MockFreezeWindowServiceMockRecorder) GetActive(ctx, repositoryName, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActive", reflect.TypeOf((*MockFreezeWindowService)(nil).GetActive), ctx, repositoryName, now)
+}
+
+// RecordOverride mocks base method
+func (m *MockFreezeWindowService) RecordOverride(ctx context.Context, repositoryName string, window *models.FreezeWindow, actor, reason string, branch, sha *string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RecordOverride", ctx, repositoryName, window, actor, reason, branch, sha)
+}
+
+// RecordOverride indicates an expected call of RecordOverride
+func (mr *MockFreezeWindowServiceMockRecorder) RecordOverride(ctx, repositoryName, window, actor, reason, branch, sha interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordOverride", reflect.TypeOf((*MockFreezeWindowService)(nil).RecordOverride), ctx, repositoryName, window, actor, reason, branch, sha)
+}
@@ -5,11 +5,13 @@
 package interfaces
 
 import (
+	context "context"
 	gomock "github.com/golang/mock/gomock"
 	models "github.com/hbalmes/ci_cd-api/api/models"
 	webhook "github.com/hbalmes/ci_cd-api/api/models/webhook"
 	apierrors "github.com/hbalmes/ci_cd-api/api/utils/apierrors"
 	reflect "reflect"
+	time "time"
 )
 
 // MockGithubClient is a mock of GithubClient interface
@@ -36,128 +38,406 @@ func (m *MockGithubClient) EXPECT() *MockGithubClientMockRecorder {
 }
 
 // GetBranchInformation mocks base method
-func (m *MockGithubClient) GetBranchInformation(config *models.Configuration, branchName string) (*models.GetBranchResponse, apierrors.ApiError) {
+func (m *MockGithubClient) GetBranchInformation(ctx context.Context, config *models.Configuration, branchName string) (*models.GetBranchResponse, apierrors.ApiError) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetBranchInformation", config, branchName)
+	ret := m.ctrl.Call(m, "GetBranchInformation", ctx, config, branchName)
 	ret0, _ := ret[0].(*models.GetBranchResponse)
 	ret1, _ := ret[1].(apierrors.ApiError)
 	return ret0, ret1
 }
 
 // GetBranchInformation indicates an expected call of GetBranchInformation
-func (mr *MockGithubClientMockRecorder) GetBranchInformation(config, branchName interface{}) *gomock.Call {
+func (mr *MockGithubClientMockRecorder) GetBranchInformation(ctx, config, branchName interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBranchInformation", reflect.TypeOf((*MockGithubClient)(nil).GetBranchInformation), config, branchName)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBranchInformation", reflect.TypeOf((*MockGithubClient)(nil).GetBranchInformation), ctx, config, branchName)
 }
 
 // CreateGithubRef mocks base method
-func (m *MockGithubClient) CreateGithubRef(config *models.Configuration, branchConfig *models.Branch, workflowConfig *models.WorkflowConfig) apierrors.ApiError {
+func (m *MockGithubClient) CreateGithubRef(ctx context.Context, config *models.Configuration, branchConfig *models.Branch, workflowConfig *models.WorkflowConfig) apierrors.ApiError {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateGithubRef", config, branchConfig, workflowConfig)
+	ret := m.ctrl.Call(m, "CreateGithubRef", ctx, config, branchConfig, workflowConfig)
 	ret0, _ := ret[0].(apierrors.ApiError)
 	return ret0
 }
 
 // CreateGithubRef indicates an expected call of CreateGithubRef
-func (mr *MockGithubClientMockRecorder) CreateGithubRef(config, branchConfig, workflowConfig interface{}) *gomock.Call {
+func (mr *MockGithubClientMockRecorder) CreateGithubRef(ctx, config, branchConfig, workflowConfig interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGithubRef", reflect.TypeOf((*MockGithubClient)(nil).CreateGithubRef), config, branchConfig, workflowConfig)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGithubRef", reflect.TypeOf((*MockGithubClient)(nil).CreateGithubRef), ctx, config, branchConfig, workflowConfig)
 }
 
 // ProtectBranch mocks base method
-func (m *MockGithubClient) ProtectBranch(config *models.Configuration, branchConfig *models.Branch) apierrors.ApiError {
+func (m *MockGithubClient) ProtectBranch(ctx context.Context, config *models.Configuration, branchConfig *models.Branch) apierrors.ApiError {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ProtectBranch", config, branchConfig)
+	ret := m.ctrl.Call(m, "ProtectBranch", ctx, config, branchConfig)
 	ret0, _ := ret[0].(apierrors.ApiError)
 	return ret0
 }
 
 // ProtectBranch indicates an expected call of ProtectBranch
-func (mr *MockGithubClientMockRecorder) ProtectBranch(config, branchConfig interface{}) *gomock.Call {
+func (mr *MockGithubClientMockRecorder) ProtectBranch(ctx, config, branchConfig interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProtectBranch", reflect.TypeOf((*MockGithubClient)(nil).ProtectBranch), config, branchConfig)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProtectBranch", reflect.TypeOf((*MockGithubClient)(nil).ProtectBranch), ctx, config, branchConfig)
 }
 
 // UnprotectBranch mocks base method
-func (m *MockGithubClient) UnprotectBranch(config *models.Configuration, branchConfig *models.Branch) apierrors.ApiError {
+func (m *MockGithubClient) UnprotectBranch(ctx context.Context, config *models.Configuration, branchConfig *models.Branch) apierrors.ApiError {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UnprotectBranch", config, branchConfig)
+	ret := m.ctrl.Call(m, "UnprotectBranch", ctx, config, branchConfig)
 	ret0, _ := ret[0].(apierrors.ApiError)
 	return ret0
 }
 
 // UnprotectBranch indicates an expected call of UnprotectBranch
-func (mr *MockGithubClientMockRecorder) UnprotectBranch(config, branchConfig interface{}) *gomock.Call {
+func (mr *MockGithubClientMockRecorder) UnprotectBranch(ctx, config, branchConfig interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnprotectBranch", reflect.TypeOf((*MockGithubClient)(nil).UnprotectBranch), config, branchConfig)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnprotectBranch", reflect.TypeOf((*MockGithubClient)(nil).UnprotectBranch), ctx, config, branchConfig)
 }
 
 // SetDefaultBranch mocks base method
-func (m *MockGithubClient) SetDefaultBranch(config *models.Configuration, workflowConfig *models.WorkflowConfig) apierrors.ApiError {
+func (m *MockGithubClient) SetDefaultBranch(ctx context.Context, config *models.Configuration, workflowConfig *models.WorkflowConfig) apierrors.ApiError {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetDefaultBranch", config, workflowConfig)
+	ret := m.ctrl.Call(m, "SetDefaultBranch", ctx, config, workflowConfig)
 	ret0, _ := ret[0].(apierrors.ApiError)
 	return ret0
 }
 
 // SetDefaultBranch indicates an expected call of SetDefaultBranch
-func (mr *MockGithubClientMockRecorder) SetDefaultBranch(config, workflowConfig interface{}) *gomock.Call {
+func (mr *MockGithubClientMockRecorder) SetDefaultBranch(ctx, config, workflowConfig interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDefaultBranch", reflect.TypeOf((*MockGithubClient)(nil).SetDefaultBranch), config, workflowConfig)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDefaultBranch", reflect.TypeOf((*MockGithubClient)(nil).SetDefaultBranch), ctx, config, workflowConfig)
 }
 
 // CreateStatus mocks base method
-func (m *MockGithubClient) CreateStatus(config *models.Configuration, statusWH *webhook.Status) apierrors.ApiError {
+func (m *MockGithubClient) CreateStatus(ctx context.Context, config *models.Configuration, statusWH *webhook.Status) apierrors.ApiError {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateStatus", config, statusWH)
+	ret := m.ctrl.Call(m, "CreateStatus", ctx, config, statusWH)
 	ret0, _ := ret[0].(apierrors.ApiError)
 	return ret0
 }
 
 // CreateStatus indicates an expected call of CreateStatus
-func (mr *MockGithubClientMockRecorder) CreateStatus(config, statusWH interface{}) *gomock.Call {
+func (mr *MockGithubClientMockRecorder) CreateStatus(ctx, config, statusWH interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateStatus", reflect.TypeOf((*MockGithubClient)(nil).CreateStatus), config, statusWH)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateStatus", reflect.TypeOf((*MockGithubClient)(nil).CreateStatus), ctx, config, statusWH)
 }
 
 // CreateBranch mocks base method
-func (m *MockGithubClient) CreateBranch(config *models.Configuration, branchConfig *models.Branch, sha string) apierrors.ApiError {
+func (m *MockGithubClient) CreateBranch(ctx context.Context, config *models.Configuration, branchConfig *models.Branch, sha string) apierrors.ApiError {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateBranch", config, branchConfig, sha)
+	ret := m.ctrl.Call(m, "CreateBranch", ctx, config, branchConfig, sha)
 	ret0, _ := ret[0].(apierrors.ApiError)
 	return ret0
 }
 
 // CreateBranch indicates an expected call of CreateBranch
-func (mr *MockGithubClientMockRecorder) CreateBranch(config, branchConfig, sha interface{}) *gomock.Call {
+func (mr *MockGithubClientMockRecorder) CreateBranch(ctx, config, branchConfig, sha interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBranch", reflect.TypeOf((*MockGithubClient)(nil).CreateBranch), config, branchConfig, sha)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBranch", reflect.TypeOf((*MockGithubClient)(nil).CreateBranch), ctx, config, branchConfig, sha)
 }
 
 // CreateIssueComment mocks base method
-func (m *MockGithubClient) CreateIssueComment(config *models.Configuration, pullRequest *models.PullRequest, issueCommentBody string) apierrors.ApiError {
+func (m *MockGithubClient) CreateIssueComment(ctx context.Context, config *models.Configuration, pullRequest *models.PullRequest, issueCommentBody string) apierrors.ApiError {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateIssueComment", config, pullRequest, issueCommentBody)
+	ret := m.ctrl.Call(m, "CreateIssueComment", ctx, config, pullRequest, issueCommentBody)
 	ret0, _ := ret[0].(apierrors.ApiError)
 	return ret0
 }
 
 // CreateIssueComment indicates an expected call of CreateIssueComment
-func (mr *MockGithubClientMockRecorder) CreateIssueComment(config, pullRequest, issueCommentBody interface{}) *gomock.Call {
+func (mr *MockGithubClientMockRecorder) CreateIssueComment(ctx, config, pullRequest, issueCommentBody interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIssueComment", reflect.TypeOf((*MockGithubClient)(nil).CreateIssueComment), config, pullRequest, issueCommentBody)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIssueComment", reflect.TypeOf((*MockGithubClient)(nil).CreateIssueComment), ctx, config, pullRequest, issueCommentBody)
 }
 
 // CreateRelease mocks base method
-func (m *MockGithubClient) CreateRelease(config *models.Configuration, build *models.Build) apierrors.ApiError {
+func (m *MockGithubClient) CreateRelease(ctx context.Context, config *models.Configuration, build *models.Build) apierrors.ApiError {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateRelease", config, build)
+	ret := m.ctrl.Call(m, "CreateRelease", ctx, config, build)
 	ret0, _ := ret[0].(apierrors.ApiError)
 	return ret0
 }
 
 // CreateRelease indicates an expected call of CreateRelease
-func (mr *MockGithubClientMockRecorder) CreateRelease(config, build interface{}) *gomock.Call {
+func (mr *MockGithubClientMockRecorder) CreateRelease(ctx, config, build interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRelease", reflect.TypeOf((*MockGithubClient)(nil).CreateRelease), config, build)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRelease", reflect.TypeOf((*MockGithubClient)(nil).CreateRelease), ctx, config, build)
+}
+
+// GetCollaboratorPermission mocks base method
+func (m *MockGithubClient) GetCollaboratorPermission(ctx context.Context, config *models.Configuration, username string) (string, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCollaboratorPermission", ctx, config, username)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// GetCollaboratorPermission indicates an expected call of GetCollaboratorPermission
+func (mr *MockGithubClientMockRecorder) GetCollaboratorPermission(ctx, config, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCollaboratorPermission", reflect.TypeOf((*MockGithubClient)(nil).GetCollaboratorPermission), ctx, config, username)
+}
+
+// GetPullRequestsByCommit mocks base method
+func (m *MockGithubClient) GetPullRequestsByCommit(ctx context.Context, config *models.Configuration, sha string) ([]models.CommitPullRequestResponse, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPullRequestsByCommit", ctx, config, sha)
+	ret0, _ := ret[0].([]models.CommitPullRequestResponse)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// GetPullRequestsByCommit indicates an expected call of GetPullRequestsByCommit
+func (mr *MockGithubClientMockRecorder) GetPullRequestsByCommit(ctx, config, sha interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPullRequestsByCommit", reflect.TypeOf((*MockGithubClient)(nil).GetPullRequestsByCommit), ctx, config, sha)
+}
+
+// ListClosedPullRequests mocks base method
+func (m *MockGithubClient) ListClosedPullRequests(ctx context.Context, config *models.Configuration, since time.Time) ([]models.CommitPullRequestResponse, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListClosedPullRequests", ctx, config, since)
+	ret0, _ := ret[0].([]models.CommitPullRequestResponse)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// ListClosedPullRequests indicates an expected call of ListClosedPullRequests
+func (mr *MockGithubClientMockRecorder) ListClosedPullRequests(ctx, config, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListClosedPullRequests", reflect.TypeOf((*MockGithubClient)(nil).ListClosedPullRequests), ctx, config, since)
+}
+
+// CreateCommitComment mocks base method
+func (m *MockGithubClient) CreateCommitComment(ctx context.Context, config *models.Configuration, sha, commentBody string) apierrors.ApiError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCommitComment", ctx, config, sha, commentBody)
+	ret0, _ := ret[0].(apierrors.ApiError)
+	return ret0
+}
+
+// CreateCommitComment indicates an expected call of CreateCommitComment
+func (mr *MockGithubClientMockRecorder) CreateCommitComment(ctx, config, sha, commentBody interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCommitComment", reflect.TypeOf((*MockGithubClient)(nil).CreateCommitComment), ctx, config, sha, commentBody)
+}
+
+// CreatePullRequest mocks base method
+func (m *MockGithubClient) CreatePullRequest(ctx context.Context, config *models.Configuration, head, base, title, body string) (*models.PullRequestResponse, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePullRequest", ctx, config, head, base, title, body)
+	ret0, _ := ret[0].(*models.PullRequestResponse)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// CreatePullRequest indicates an expected call of CreatePullRequest
+func (mr *MockGithubClientMockRecorder) CreatePullRequest(ctx, config, head, base, title, body interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePullRequest", reflect.TypeOf((*MockGithubClient)(nil).CreatePullRequest), ctx, config, head, base, title, body)
+}
+
+// MergePullRequest mocks base method
+func (m *MockGithubClient) MergePullRequest(ctx context.Context, config *models.Configuration, number int, mergeMethod, sha string) apierrors.ApiError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergePullRequest", ctx, config, number, mergeMethod, sha)
+	ret0, _ := ret[0].(apierrors.ApiError)
+	return ret0
+}
+
+// MergePullRequest indicates an expected call of MergePullRequest
+func (mr *MockGithubClientMockRecorder) MergePullRequest(ctx, config, number, mergeMethod, sha interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergePullRequest", reflect.TypeOf((*MockGithubClient)(nil).MergePullRequest), ctx, config, number, mergeMethod, sha)
+}
+
+// MergeBranch mocks base method
+func (m *MockGithubClient) MergeBranch(ctx context.Context, config *models.Configuration, base, head, commitMessage string) (string, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeBranch", ctx, config, base, head, commitMessage)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// MergeBranch indicates an expected call of MergeBranch
+func (mr *MockGithubClientMockRecorder) MergeBranch(ctx, config, base, head, commitMessage interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeBranch", reflect.TypeOf((*MockGithubClient)(nil).MergeBranch), ctx, config, base, head, commitMessage)
+}
+
+// DeleteBranch mocks base method
+func (m *MockGithubClient) DeleteBranch(ctx context.Context, config *models.Configuration, branchName string) apierrors.ApiError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBranch", ctx, config, branchName)
+	ret0, _ := ret[0].(apierrors.ApiError)
+	return ret0
+}
+
+// DeleteBranch indicates an expected call of DeleteBranch
+func (mr *MockGithubClientMockRecorder) DeleteBranch(ctx, config, branchName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBranch", reflect.TypeOf((*MockGithubClient)(nil).DeleteBranch), ctx, config, branchName)
+}
+
+// FastForwardBranch mocks base method
+func (m *MockGithubClient) FastForwardBranch(ctx context.Context, config *models.Configuration, branchName, sha string) apierrors.ApiError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FastForwardBranch", ctx, config, branchName, sha)
+	ret0, _ := ret[0].(apierrors.ApiError)
+	return ret0
+}
+
+// FastForwardBranch indicates an expected call of FastForwardBranch
+func (mr *MockGithubClientMockRecorder) FastForwardBranch(ctx, config, branchName, sha interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FastForwardBranch", reflect.TypeOf((*MockGithubClient)(nil).FastForwardBranch), ctx, config, branchName, sha)
+}
+
+// CompareCommits mocks base method
+func (m *MockGithubClient) CompareCommits(ctx context.Context, config *models.Configuration, base, head string) (*models.CompareResponse, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompareCommits", ctx, config, base, head)
+	ret0, _ := ret[0].(*models.CompareResponse)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// CompareCommits indicates an expected call of CompareCommits
+func (mr *MockGithubClientMockRecorder) CompareCommits(ctx, config, base, head interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompareCommits", reflect.TypeOf((*MockGithubClient)(nil).CompareCommits), ctx, config, base, head)
+}
+
+// GetFileContent mocks base method
+func (m *MockGithubClient) GetFileContent(ctx context.Context, config *models.Configuration, path, ref string) (*models.FileContentResponse, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFileContent", ctx, config, path, ref)
+	ret0, _ := ret[0].(*models.FileContentResponse)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// GetFileContent indicates an expected call of GetFileContent
+func (mr *MockGithubClientMockRecorder) GetFileContent(ctx, config, path, ref interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileContent", reflect.TypeOf((*MockGithubClient)(nil).GetFileContent), ctx, config, path, ref)
+}
+
+// CreateTree mocks base method
+func (m *MockGithubClient) CreateTree(ctx context.Context, config *models.Configuration, request *models.TreeRequest) (string, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTree", ctx, config, request)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// CreateTree indicates an expected call of CreateTree
+func (mr *MockGithubClientMockRecorder) CreateTree(ctx, config, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTree", reflect.TypeOf((*MockGithubClient)(nil).CreateTree), ctx, config, request)
+}
+
+// CreateCommit mocks base method
+func (m *MockGithubClient) CreateCommit(ctx context.Context, config *models.Configuration, request *models.CommitRequest) (string, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCommit", ctx, config, request)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// CreateCommit indicates an expected call of CreateCommit
+func (mr *MockGithubClientMockRecorder) CreateCommit(ctx, config, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCommit", reflect.TypeOf((*MockGithubClient)(nil).CreateCommit), ctx, config, request)
+}
+
+// CreateDeployment mocks base method
+func (m *MockGithubClient) CreateDeployment(ctx context.Context, config *models.Configuration, request *models.DeploymentRequest) (*models.DeploymentResponse, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDeployment", ctx, config, request)
+	ret0, _ := ret[0].(*models.DeploymentResponse)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// CreateDeployment indicates an expected call of CreateDeployment
+func (mr *MockGithubClientMockRecorder) CreateDeployment(ctx, config, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDeployment", reflect.TypeOf((*MockGithubClient)(nil).CreateDeployment), ctx, config, request)
+}
+
+// CreateDeploymentStatus mocks base method
+func (m *MockGithubClient) CreateDeploymentStatus(ctx context.Context, config *models.Configuration, deploymentID int64, state, description string) apierrors.ApiError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDeploymentStatus", ctx, config, deploymentID, state, description)
+	ret0, _ := ret[0].(apierrors.ApiError)
+	return ret0
+}
+
+// CreateDeploymentStatus indicates an expected call of CreateDeploymentStatus
+func (mr *MockGithubClientMockRecorder) CreateDeploymentStatus(ctx, config, deploymentID, state, description interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDeploymentStatus", reflect.TypeOf((*MockGithubClient)(nil).CreateDeploymentStatus), ctx, config, deploymentID, state, description)
+}
+
+// IsTeamMember mocks base method
+func (m *MockGithubClient) IsTeamMember(ctx context.Context, config *models.Configuration, team, username string) (bool, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsTeamMember", ctx, config, team, username)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// IsTeamMember indicates an expected call of IsTeamMember
+func (mr *MockGithubClientMockRecorder) IsTeamMember(ctx, config, team, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsTeamMember", reflect.TypeOf((*MockGithubClient)(nil).IsTeamMember), ctx, config, team, username)
+}
+
+// GetReleaseByTag mocks base method
+func (m *MockGithubClient) GetReleaseByTag(ctx context.Context, config *models.Configuration, tagName string) (*models.ReleaseResponse, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReleaseByTag", ctx, config, tagName)
+	ret0, _ := ret[0].(*models.ReleaseResponse)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// GetReleaseByTag indicates an expected call of GetReleaseByTag
+func (mr *MockGithubClientMockRecorder) GetReleaseByTag(ctx, config, tagName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReleaseByTag", reflect.TypeOf((*MockGithubClient)(nil).GetReleaseByTag), ctx, config, tagName)
+}
+
+// UpdateRelease mocks base method
+func (m *MockGithubClient) UpdateRelease(ctx context.Context, config *models.Configuration, releaseID int64, request *models.ReleaseUpdateRequest) apierrors.ApiError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRelease", ctx, config, releaseID, request)
+	ret0, _ := ret[0].(apierrors.ApiError)
+	return ret0
+}
+
+// UpdateRelease indicates an expected call of UpdateRelease
+func (mr *MockGithubClientMockRecorder) UpdateRelease(ctx, config, releaseID, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRelease", reflect.TypeOf((*MockGithubClient)(nil).UpdateRelease), ctx, config, releaseID, request)
+}
+
+// CheckCredentials mocks base method
+func (m *MockGithubClient) CheckCredentials(ctx context.Context) apierrors.ApiError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckCredentials", ctx)
+	ret0, _ := ret[0].(apierrors.ApiError)
+	return ret0
+}
+
+// CheckCredentials indicates an expected call of CheckCredentials
+func (mr *MockGithubClientMockRecorder) CheckCredentials(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckCredentials", reflect.TypeOf((*MockGithubClient)(nil).CheckCredentials), ctx)
 }
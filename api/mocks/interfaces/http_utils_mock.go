@@ -101,6 +101,20 @@ func (mr *MockHTTPContextMockRecorder) Param(key interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Param", reflect.TypeOf((*MockHTTPContext)(nil).Param), key)
 }
 
+// Query mocks base method
+func (m *MockHTTPContext) Query(key string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Query", key)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Query indicates an expected call of Query
+func (mr *MockHTTPContextMockRecorder) Query(key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockHTTPContext)(nil).Query), key)
+}
+
 // ShouldBindJSON mocks base method
 func (m *MockHTTPContext) ShouldBindJSON(arg0 interface{}) error {
 	m.ctrl.T.Helper()
@@ -114,3 +128,17 @@ func (mr *MockHTTPContextMockRecorder) ShouldBindJSON(arg0 interface{}) *gomock.
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShouldBindJSON", reflect.TypeOf((*MockHTTPContext)(nil).ShouldBindJSON), arg0)
 }
+
+// Value mocks base method
+func (m *MockHTTPContext) Value(key interface{}) interface{} {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Value", key)
+	ret0, _ := ret[0].(interface{})
+	return ret0
+}
+
+// Value indicates an expected call of Value
+func (mr *MockHTTPContextMockRecorder) Value(key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Value", reflect.TypeOf((*MockHTTPContext)(nil).Value), key)
+}
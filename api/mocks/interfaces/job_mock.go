@@ -0,0 +1,81 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: services/job.go
+
+// Package interfaces is a generated GoMock package.
+package interfaces
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/hbalmes/ci_cd-api/api/models"
+	apierrors "github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	reflect "reflect"
+	time "time"
+)
+
+// MockJobService is a mock of JobService interface
+type MockJobService struct {
+	ctrl     *gomock.Controller
+	recorder *MockJobServiceMockRecorder
+}
+
+// MockJobServiceMockRecorder is the mock recorder for MockJobService
+type MockJobServiceMockRecorder struct {
+	mock *MockJobService
+}
+
+// NewMockJobService creates a new mock instance
+func NewMockJobService(ctrl *gomock.Controller) *MockJobService {
+	mock := &MockJobService{ctrl: ctrl}
+	mock.recorder = &MockJobServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockJobService) EXPECT() *MockJobServiceMockRecorder {
+	return m.recorder
+}
+
+// Start mocks base method
+func (m *MockJobService) Start(ctx context.Context, jobType string, payload interface{}) (*models.Job, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Start", ctx, jobType, payload)
+	ret0, _ := ret[0].(*models.Job)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// Start indicates an expected call of Start
+func (mr *MockJobServiceMockRecorder) Start(ctx, jobType, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Start", reflect.TypeOf((*MockJobService)(nil).Start), ctx, jobType, payload)
+}
+
+// Finish mocks base method
+func (m *MockJobService) Finish(ctx context.Context, job *models.Job) apierrors.ApiError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Finish", ctx, job)
+	ret0, _ := ret[0].(apierrors.ApiError)
+	return ret0
+}
+
+// Finish indicates an expected call of Finish
+func (mr *MockJobServiceMockRecorder) Finish(ctx, job interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Finish", reflect.TypeOf((*MockJobService)(nil).Finish), ctx, job)
+}
+
+// ClaimInterrupted mocks base method
+func (m *MockJobService) ClaimInterrupted(ctx context.Context, now time.Time) ([]models.Job, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimInterrupted", ctx, now)
+	ret0, _ := ret[0].([]models.Job)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// ClaimInterrupted indicates an expected call of ClaimInterrupted
+func (mr *MockJobServiceMockRecorder) ClaimInterrupted(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimInterrupted", reflect.TypeOf((*MockJobService)(nil).ClaimInterrupted), ctx, now)
+}
@@ -0,0 +1,109 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: services/merge_queue.go
+
+// Package interfaces is a generated GoMock package.
+package interfaces
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/hbalmes/ci_cd-api/api/models"
+	webhook "github.com/hbalmes/ci_cd-api/api/models/webhook"
+	apierrors "github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	reflect "reflect"
+)
+
+// MockMergeQueueService is a mock of MergeQueueService interface
+type MockMergeQueueService struct {
+	ctrl     *gomock.Controller
+	recorder *MockMergeQueueServiceMockRecorder
+}
+
+// MockMergeQueueServiceMockRecorder is the mock recorder for MockMergeQueueService
+type MockMergeQueueServiceMockRecorder struct {
+	mock *MockMergeQueueService
+}
+
+// NewMockMergeQueueService creates a new mock instance
+func NewMockMergeQueueService(ctrl *gomock.Controller) *MockMergeQueueService {
+	mock := &MockMergeQueueService{ctrl: ctrl}
+	mock.recorder = &MockMergeQueueServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockMergeQueueService) EXPECT() *MockMergeQueueServiceMockRecorder {
+	return m.recorder
+}
+
+// Enqueue mocks base method
+func (m *MockMergeQueueService) Enqueue(ctx context.Context, config *models.Configuration, pullRequest *models.PullRequest, sender string) (*models.MergeQueueEntry, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enqueue", ctx, config, pullRequest, sender)
+	ret0, _ := ret[0].(*models.MergeQueueEntry)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// Enqueue indicates an expected call of Enqueue
+func (mr *MockMergeQueueServiceMockRecorder) Enqueue(ctx, config, pullRequest, sender interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enqueue", reflect.TypeOf((*MockMergeQueueService)(nil).Enqueue), ctx, config, pullRequest, sender)
+}
+
+// Dequeue mocks base method
+func (m *MockMergeQueueService) Dequeue(ctx context.Context, config *models.Configuration, pullRequest *models.PullRequest) apierrors.ApiError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Dequeue", ctx, config, pullRequest)
+	ret0, _ := ret[0].(apierrors.ApiError)
+	return ret0
+}
+
+// Dequeue indicates an expected call of Dequeue
+func (mr *MockMergeQueueServiceMockRecorder) Dequeue(ctx, config, pullRequest interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Dequeue", reflect.TypeOf((*MockMergeQueueService)(nil).Dequeue), ctx, config, pullRequest)
+}
+
+// List mocks base method
+func (m *MockMergeQueueService) List(ctx context.Context, repositoryName, branch string) ([]models.MergeQueueEntry, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, repositoryName, branch)
+	ret0, _ := ret[0].([]models.MergeQueueEntry)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List
+func (mr *MockMergeQueueServiceMockRecorder) List(ctx, repositoryName, branch interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockMergeQueueService)(nil).List), ctx, repositoryName, branch)
+}
+
+// Advance mocks base method
+func (m *MockMergeQueueService) Advance(ctx context.Context, config *models.Configuration, branch string) apierrors.ApiError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Advance", ctx, config, branch)
+	ret0, _ := ret[0].(apierrors.ApiError)
+	return ret0
+}
+
+// Advance indicates an expected call of Advance
+func (mr *MockMergeQueueServiceMockRecorder) Advance(ctx, config, branch interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Advance", reflect.TypeOf((*MockMergeQueueService)(nil).Advance), ctx, config, branch)
+}
+
+// ProcessStatus mocks base method
+func (m *MockMergeQueueService) ProcessStatus(ctx context.Context, config *models.Configuration, payload *webhook.Status) apierrors.ApiError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProcessStatus", ctx, config, payload)
+	ret0, _ := ret[0].(apierrors.ApiError)
+	return ret0
+}
+
+// ProcessStatus indicates an expected call of ProcessStatus
+func (mr *MockMergeQueueServiceMockRecorder) ProcessStatus(ctx, config, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessStatus", reflect.TypeOf((*MockMergeQueueService)(nil).ProcessStatus), ctx, config, payload)
+}
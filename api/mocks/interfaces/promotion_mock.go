@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: services/promotion.go
+
+// Package interfaces is a generated GoMock package.
+package interfaces
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/hbalmes/ci_cd-api/api/models"
+	apierrors "github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	reflect "reflect"
+)
+
+// MockPromotionService is a mock of PromotionService interface
+type MockPromotionService struct {
+	ctrl     *gomock.Controller
+	recorder *MockPromotionServiceMockRecorder
+}
+
+// MockPromotionServiceMockRecorder is the mock recorder for MockPromotionService
+type MockPromotionServiceMockRecorder struct {
+	mock *MockPromotionService
+}
+
+// NewMockPromotionService creates a new mock instance
+func NewMockPromotionService(ctrl *gomock.Controller) *MockPromotionService {
+	mock := &MockPromotionService{ctrl: ctrl}
+	mock.recorder = &MockPromotionServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockPromotionService) EXPECT() *MockPromotionServiceMockRecorder {
+	return m.recorder
+}
+
+// Promote mocks base method
+func (m *MockPromotionService) Promote(ctx context.Context, repositoryName string, buildID uint32, r *models.PromotionPayload) (*models.Build, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Promote", ctx, repositoryName, buildID, r)
+	ret0, _ := ret[0].(*models.Build)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// Promote indicates an expected call of Promote
+func (mr *MockPromotionServiceMockRecorder) Promote(ctx, repositoryName, buildID, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Promote", reflect.TypeOf((*MockPromotionService)(nil).Promote), ctx, repositoryName, buildID, r)
+}
+
+// CheckGates mocks base method
+func (m *MockPromotionService) CheckGates(ctx context.Context, config *models.Configuration, source *models.Build, requester string) ([]string, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckGates", ctx, config, source, requester)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// CheckGates indicates an expected call of CheckGates
+func (mr *MockPromotionServiceMockRecorder) CheckGates(ctx, config, source, requester interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckGates", reflect.TypeOf((*MockPromotionService)(nil).CheckGates), ctx, config, source, requester)
+}
@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: services/release_notes.go
+
+// Package interfaces is a generated GoMock package.
+package interfaces
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/hbalmes/ci_cd-api/api/models"
+	apierrors "github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	reflect "reflect"
+)
+
+// MockReleaseNotesService is a mock of ReleaseNotesService interface
+type MockReleaseNotesService struct {
+	ctrl     *gomock.Controller
+	recorder *MockReleaseNotesServiceMockRecorder
+}
+
+// MockReleaseNotesServiceMockRecorder is the mock recorder for MockReleaseNotesService
+type MockReleaseNotesServiceMockRecorder struct {
+	mock *MockReleaseNotesService
+}
+
+// NewMockReleaseNotesService creates a new mock instance
+func NewMockReleaseNotesService(ctrl *gomock.Controller) *MockReleaseNotesService {
+	mock := &MockReleaseNotesService{ctrl: ctrl}
+	mock.recorder = &MockReleaseNotesServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockReleaseNotesService) EXPECT() *MockReleaseNotesServiceMockRecorder {
+	return m.recorder
+}
+
+// Generate mocks base method
+func (m *MockReleaseNotesService) Generate(ctx context.Context, config *models.Configuration, build *models.Build, pullRequest *models.PullRequest) (*models.ReleaseNotes, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Generate", ctx, config, build, pullRequest)
+	ret0, _ := ret[0].(*models.ReleaseNotes)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// Generate indicates an expected call of Generate
+func (mr *MockReleaseNotesServiceMockRecorder) Generate(ctx, config, build, pullRequest interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Generate", reflect.TypeOf((*MockReleaseNotesService)(nil).Generate), ctx, config, build, pullRequest)
+}
+
+// Render mocks base method
+func (m *MockReleaseNotesService) Render(config *models.Configuration, notes *models.ReleaseNotes) (string, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Render", config, notes)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// Render indicates an expected call of Render
+func (mr *MockReleaseNotesServiceMockRecorder) Render(config, notes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Render", reflect.TypeOf((*MockReleaseNotesService)(nil).Render), config, notes)
+}
@@ -0,0 +1,123 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: services/release_schedule.go
+
+// Package interfaces is a generated GoMock package.
+package interfaces
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/hbalmes/ci_cd-api/api/models"
+	apierrors "github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	reflect "reflect"
+	time "time"
+)
+
+// MockReleaseScheduleService is a mock of ReleaseScheduleService interface
+type MockReleaseScheduleService struct {
+	ctrl     *gomock.Controller
+	recorder *MockReleaseScheduleServiceMockRecorder
+}
+
+// MockReleaseScheduleServiceMockRecorder is the mock recorder for MockReleaseScheduleService
+type MockReleaseScheduleServiceMockRecorder struct {
+	mock *MockReleaseScheduleService
+}
+
+// NewMockReleaseScheduleService creates a new mock instance
+func NewMockReleaseScheduleService(ctrl *gomock.Controller) *MockReleaseScheduleService {
+	mock := &MockReleaseScheduleService{ctrl: ctrl}
+	mock.recorder = &MockReleaseScheduleServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockReleaseScheduleService) EXPECT() *MockReleaseScheduleServiceMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockReleaseScheduleService) Create(ctx context.Context, repositoryName string, r *models.ReleaseSchedulePayload) (*models.ReleaseSchedule, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, repositoryName, r)
+	ret0, _ := ret[0].(*models.ReleaseSchedule)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockReleaseScheduleServiceMockRecorder) Create(ctx, repositoryName, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockReleaseScheduleService)(nil).Create), ctx, repositoryName, r)
+}
+
+// List mocks base method
+func (m *MockReleaseScheduleService) List(ctx context.Context, repositoryName string) ([]models.ReleaseSchedule, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, repositoryName)
+	ret0, _ := ret[0].([]models.ReleaseSchedule)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List
+func (mr *MockReleaseScheduleServiceMockRecorder) List(ctx, repositoryName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockReleaseScheduleService)(nil).List), ctx, repositoryName)
+}
+
+// Update mocks base method
+func (m *MockReleaseScheduleService) Update(ctx context.Context, repositoryName string, id uint32, r *models.ReleaseSchedulePayload) (*models.ReleaseSchedule, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, repositoryName, id, r)
+	ret0, _ := ret[0].(*models.ReleaseSchedule)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update
+func (mr *MockReleaseScheduleServiceMockRecorder) Update(ctx, repositoryName, id, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockReleaseScheduleService)(nil).Update), ctx, repositoryName, id, r)
+}
+
+// Delete mocks base method
+func (m *MockReleaseScheduleService) Delete(ctx context.Context, repositoryName string, id uint32) apierrors.ApiError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, repositoryName, id)
+	ret0, _ := ret[0].(apierrors.ApiError)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockReleaseScheduleServiceMockRecorder) Delete(ctx, repositoryName, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockReleaseScheduleService)(nil).Delete), ctx, repositoryName, id)
+}
+
+// CutReleaseBranch mocks base method
+func (m *MockReleaseScheduleService) CutReleaseBranch(ctx context.Context, schedule *models.ReleaseSchedule) (string, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CutReleaseBranch", ctx, schedule)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// CutReleaseBranch indicates an expected call of CutReleaseBranch
+func (mr *MockReleaseScheduleServiceMockRecorder) CutReleaseBranch(ctx, schedule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CutReleaseBranch", reflect.TypeOf((*MockReleaseScheduleService)(nil).CutReleaseBranch), ctx, schedule)
+}
+
+// RunDueSchedules mocks base method
+func (m *MockReleaseScheduleService) RunDueSchedules(ctx context.Context, now time.Time) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RunDueSchedules", ctx, now)
+}
+
+// RunDueSchedules indicates an expected call of RunDueSchedules
+func (mr *MockReleaseScheduleServiceMockRecorder) RunDueSchedules(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunDueSchedules", reflect.TypeOf((*MockReleaseScheduleService)(nil).RunDueSchedules), ctx, now)
+}
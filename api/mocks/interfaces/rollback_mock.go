@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: services/rollback.go
+
+// Package interfaces is a generated GoMock package.
+package interfaces
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/hbalmes/ci_cd-api/api/models"
+	apierrors "github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	reflect "reflect"
+)
+
+// MockRollbackService is a mock of RollbackService interface
+type MockRollbackService struct {
+	ctrl     *gomock.Controller
+	recorder *MockRollbackServiceMockRecorder
+}
+
+// MockRollbackServiceMockRecorder is the mock recorder for MockRollbackService
+type MockRollbackServiceMockRecorder struct {
+	mock *MockRollbackService
+}
+
+// NewMockRollbackService creates a new mock instance
+func NewMockRollbackService(ctrl *gomock.Controller) *MockRollbackService {
+	mock := &MockRollbackService{ctrl: ctrl}
+	mock.recorder = &MockRollbackServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockRollbackService) EXPECT() *MockRollbackServiceMockRecorder {
+	return m.recorder
+}
+
+// Rollback mocks base method
+func (m *MockRollbackService) Rollback(ctx context.Context, repositoryName string, buildID uint32, r *models.RollbackPayload) (*models.Rollback, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rollback", ctx, repositoryName, buildID, r)
+	ret0, _ := ret[0].(*models.Rollback)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// Rollback indicates an expected call of Rollback
+func (mr *MockRollbackServiceMockRecorder) Rollback(ctx, repositoryName, buildID, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rollback", reflect.TypeOf((*MockRollbackService)(nil).Rollback), ctx, repositoryName, buildID, r)
+}
@@ -5,6 +5,8 @@
 package interfaces
 
 import (
+	context "context"
+	sql "database/sql"
 	gomock "github.com/golang/mock/gomock"
 	gorm "github.com/jinzhu/gorm"
 	reflect "reflect"
@@ -34,52 +36,72 @@ func (m *MockSQLStorage) EXPECT() *MockSQLStorageMockRecorder {
 }
 
 // Insert mocks base method
-func (m *MockSQLStorage) Insert(arg0 interface{}) error {
+func (m *MockSQLStorage) Insert(arg0 context.Context, arg1 interface{}) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Insert", arg0)
+	ret := m.ctrl.Call(m, "Insert", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Insert indicates an expected call of Insert
-func (mr *MockSQLStorageMockRecorder) Insert(arg0 interface{}) *gomock.Call {
+func (mr *MockSQLStorageMockRecorder) Insert(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockSQLStorage)(nil).Insert), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockSQLStorage)(nil).Insert), arg0, arg1)
 }
 
 // Update mocks base method
-func (m *MockSQLStorage) Update(arg0 interface{}) error {
+func (m *MockSQLStorage) Update(arg0 context.Context, arg1 interface{}) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", arg0)
+	ret := m.ctrl.Call(m, "Update", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Update indicates an expected call of Update
-func (mr *MockSQLStorageMockRecorder) Update(arg0 interface{}) *gomock.Call {
+func (mr *MockSQLStorageMockRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockSQLStorage)(nil).Update), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockSQLStorage)(nil).Update), arg0, arg1)
+}
+
+// UpdateBy mocks base method
+func (m *MockSQLStorage) UpdateBy(arg0 context.Context, arg1 interface{}, arg2 map[string]interface{}, arg3 string, arg4 ...interface{}) (int64, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1, arg2, arg3}
+	for _, a := range arg4 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UpdateBy", varargs...)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateBy indicates an expected call of UpdateBy
+func (mr *MockSQLStorageMockRecorder) UpdateBy(arg0, arg1, arg2, arg3 interface{}, arg4 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1, arg2, arg3}, arg4...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBy", reflect.TypeOf((*MockSQLStorage)(nil).UpdateBy), varargs...)
 }
 
 // Get mocks base method
-func (m *MockSQLStorage) Get(arg0, arg1 interface{}) error {
+func (m *MockSQLStorage) Get(arg0 context.Context, arg1, arg2 interface{}) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Get", arg0, arg1)
+	ret := m.ctrl.Call(m, "Get", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Get indicates an expected call of Get
-func (mr *MockSQLStorageMockRecorder) Get(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockSQLStorageMockRecorder) Get(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockSQLStorage)(nil).Get), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockSQLStorage)(nil).Get), arg0, arg1, arg2)
 }
 
 // GetBy mocks base method
-func (m *MockSQLStorage) GetBy(arg0 interface{}, arg1 ...interface{}) error {
+func (m *MockSQLStorage) GetBy(arg0 context.Context, arg1 interface{}, arg2 ...interface{}) error {
 	m.ctrl.T.Helper()
-	varargs := []interface{}{arg0}
-	for _, a := range arg1 {
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
 		varargs = append(varargs, a)
 	}
 	ret := m.ctrl.Call(m, "GetBy", varargs...)
@@ -88,38 +110,122 @@ func (m *MockSQLStorage) GetBy(arg0 interface{}, arg1 ...interface{}) error {
 }
 
 // GetBy indicates an expected call of GetBy
-func (mr *MockSQLStorageMockRecorder) GetBy(arg0 interface{}, arg1 ...interface{}) *gomock.Call {
+func (mr *MockSQLStorageMockRecorder) GetBy(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	varargs := append([]interface{}{arg0}, arg1...)
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBy", reflect.TypeOf((*MockSQLStorage)(nil).GetBy), varargs...)
 }
 
 // Delete mocks base method
-func (m *MockSQLStorage) Delete(arg0 interface{}) error {
+func (m *MockSQLStorage) Delete(arg0 context.Context, arg1 interface{}) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", arg0)
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete
-func (mr *MockSQLStorageMockRecorder) Delete(arg0 interface{}) *gomock.Call {
+func (mr *MockSQLStorageMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockSQLStorage)(nil).Delete), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockSQLStorage)(nil).Delete), arg0, arg1)
 }
 
 // DeleteFromRequireStatusChecksByConfigurationID mocks base method
-func (m *MockSQLStorage) DeleteFromRequireStatusChecksByConfigurationID(arg0 *string) error {
+func (m *MockSQLStorage) DeleteFromRequireStatusChecksByConfigurationID(arg0 context.Context, arg1 *string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteFromRequireStatusChecksByConfigurationID", arg0)
+	ret := m.ctrl.Call(m, "DeleteFromRequireStatusChecksByConfigurationID", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteFromRequireStatusChecksByConfigurationID indicates an expected call of DeleteFromRequireStatusChecksByConfigurationID
-func (mr *MockSQLStorageMockRecorder) DeleteFromRequireStatusChecksByConfigurationID(arg0 interface{}) *gomock.Call {
+func (mr *MockSQLStorageMockRecorder) DeleteFromRequireStatusChecksByConfigurationID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFromRequireStatusChecksByConfigurationID", reflect.TypeOf((*MockSQLStorage)(nil).DeleteFromRequireStatusChecksByConfigurationID), arg0, arg1)
+}
+
+// DeleteFromMaintainersByConfigurationID mocks base method
+func (m *MockSQLStorage) DeleteFromMaintainersByConfigurationID(arg0 context.Context, arg1 *string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFromMaintainersByConfigurationID", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFromMaintainersByConfigurationID indicates an expected call of DeleteFromMaintainersByConfigurationID
+func (mr *MockSQLStorageMockRecorder) DeleteFromMaintainersByConfigurationID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFromMaintainersByConfigurationID", reflect.TypeOf((*MockSQLStorage)(nil).DeleteFromMaintainersByConfigurationID), arg0, arg1)
+}
+
+// DeleteFromBranchCleanupPrefixesByConfigurationID mocks base method
+func (m *MockSQLStorage) DeleteFromBranchCleanupPrefixesByConfigurationID(arg0 context.Context, arg1 *string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFromBranchCleanupPrefixesByConfigurationID", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFromBranchCleanupPrefixesByConfigurationID indicates an expected call of DeleteFromBranchCleanupPrefixesByConfigurationID
+func (mr *MockSQLStorageMockRecorder) DeleteFromBranchCleanupPrefixesByConfigurationID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFromBranchCleanupPrefixesByConfigurationID", reflect.TypeOf((*MockSQLStorage)(nil).DeleteFromBranchCleanupPrefixesByConfigurationID), arg0, arg1)
+}
+
+// DeleteFromVersionFilesByConfigurationID mocks base method
+func (m *MockSQLStorage) DeleteFromVersionFilesByConfigurationID(arg0 context.Context, arg1 *string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFromVersionFilesByConfigurationID", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFromVersionFilesByConfigurationID indicates an expected call of DeleteFromVersionFilesByConfigurationID
+func (mr *MockSQLStorageMockRecorder) DeleteFromVersionFilesByConfigurationID(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFromRequireStatusChecksByConfigurationID", reflect.TypeOf((*MockSQLStorage)(nil).DeleteFromRequireStatusChecksByConfigurationID), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFromVersionFilesByConfigurationID", reflect.TypeOf((*MockSQLStorage)(nil).DeleteFromVersionFilesByConfigurationID), arg0, arg1)
+}
+
+// DeleteFromEnvironmentsByConfigurationID mocks base method
+func (m *MockSQLStorage) DeleteFromEnvironmentsByConfigurationID(arg0 context.Context, arg1 *string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFromEnvironmentsByConfigurationID", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFromEnvironmentsByConfigurationID indicates an expected call of DeleteFromEnvironmentsByConfigurationID
+func (mr *MockSQLStorageMockRecorder) DeleteFromEnvironmentsByConfigurationID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFromEnvironmentsByConfigurationID", reflect.TypeOf((*MockSQLStorage)(nil).DeleteFromEnvironmentsByConfigurationID), arg0, arg1)
+}
+
+// DeleteFromEnvironmentApproversByConfigurationID mocks base method
+func (m *MockSQLStorage) DeleteFromEnvironmentApproversByConfigurationID(arg0 context.Context, arg1 *string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFromEnvironmentApproversByConfigurationID", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFromEnvironmentApproversByConfigurationID indicates an expected call of DeleteFromEnvironmentApproversByConfigurationID
+func (mr *MockSQLStorageMockRecorder) DeleteFromEnvironmentApproversByConfigurationID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFromEnvironmentApproversByConfigurationID", reflect.TypeOf((*MockSQLStorage)(nil).DeleteFromEnvironmentApproversByConfigurationID), arg0, arg1)
+}
+
+// Ping mocks base method
+func (m *MockSQLStorage) Ping(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping
+func (mr *MockSQLStorageMockRecorder) Ping(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockSQLStorage)(nil).Ping), arg0)
 }
 
 // MockSQLClient is a mock of SQLClient interface
@@ -192,6 +298,20 @@ func (mr *MockSQLClientMockRecorder) Save(value interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockSQLClient)(nil).Save), value)
 }
 
+// Model mocks base method
+func (m *MockSQLClient) Model(value interface{}) *gorm.DB {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Model", value)
+	ret0, _ := ret[0].(*gorm.DB)
+	return ret0
+}
+
+// Model indicates an expected call of Model
+func (mr *MockSQLClientMockRecorder) Model(value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Model", reflect.TypeOf((*MockSQLClient)(nil).Model), value)
+}
+
 // Delete mocks base method
 func (m *MockSQLClient) Delete(value interface{}, where ...interface{}) *gorm.DB {
 	m.ctrl.T.Helper()
@@ -256,3 +376,17 @@ func (mr *MockSQLClientMockRecorder) AutoMigrate(values ...interface{}) *gomock.
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AutoMigrate", reflect.TypeOf((*MockSQLClient)(nil).AutoMigrate), values...)
 }
+
+// DB mocks base method
+func (m *MockSQLClient) DB() *sql.DB {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DB")
+	ret0, _ := ret[0].(*sql.DB)
+	return ret0
+}
+
+// DB indicates an expected call of DB
+func (mr *MockSQLClientMockRecorder) DB() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DB", reflect.TypeOf((*MockSQLClient)(nil).DB))
+}
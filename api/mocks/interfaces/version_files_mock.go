@@ -0,0 +1,56 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: services/version_files.go
+
+// Package interfaces is a generated GoMock package.
+package interfaces
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/hbalmes/ci_cd-api/api/models"
+	apierrors "github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	reflect "reflect"
+)
+
+// MockVersionFilesService is a mock of VersionFilesService interface
+type MockVersionFilesService struct {
+	ctrl     *gomock.Controller
+	recorder *MockVersionFilesServiceMockRecorder
+}
+
+// MockVersionFilesServiceMockRecorder is the mock recorder for MockVersionFilesService
+type MockVersionFilesServiceMockRecorder struct {
+	mock *MockVersionFilesService
+}
+
+// NewMockVersionFilesService creates a new mock instance
+func NewMockVersionFilesService(ctrl *gomock.Controller) *MockVersionFilesService {
+	mock := &MockVersionFilesService{ctrl: ctrl}
+	mock.recorder = &MockVersionFilesServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockVersionFilesService) EXPECT() *MockVersionFilesServiceMockRecorder {
+	return m.recorder
+}
+
+// Bump mocks base method
+func (m *MockVersionFilesService) Bump(ctx context.Context, config *models.Configuration, pullRequest *models.PullRequest, build *models.Build, files ...models.TreeEntry) (string, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, config, pullRequest, build}
+	for _, a := range files {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Bump", varargs...)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// Bump indicates an expected call of Bump
+func (mr *MockVersionFilesServiceMockRecorder) Bump(ctx, config, pullRequest, build interface{}, files ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, config, pullRequest, build}, files...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Bump", reflect.TypeOf((*MockVersionFilesService)(nil).Bump), varargs...)
+}
@@ -5,10 +5,12 @@
 package interfaces
 
 import (
+	context "context"
 	gomock "github.com/golang/mock/gomock"
 	webhook "github.com/hbalmes/ci_cd-api/api/models/webhook"
 	apierrors "github.com/hbalmes/ci_cd-api/api/utils/apierrors"
 	reflect "reflect"
+	time "time"
 )
 
 // MockWebhookService is a mock of WebhookService interface
@@ -35,60 +37,117 @@ func (m *MockWebhookService) EXPECT() *MockWebhookServiceMockRecorder {
 }
 
 // ProcessStatusWebhook mocks base method
-func (m *MockWebhookService) ProcessStatusWebhook(payload *webhook.Status) (*webhook.Webhook, apierrors.ApiError) {
+func (m *MockWebhookService) ProcessStatusWebhook(ctx context.Context, payload *webhook.Status) (*webhook.Webhook, apierrors.ApiError) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ProcessStatusWebhook", payload)
+	ret := m.ctrl.Call(m, "ProcessStatusWebhook", ctx, payload)
 	ret0, _ := ret[0].(*webhook.Webhook)
 	ret1, _ := ret[1].(apierrors.ApiError)
 	return ret0, ret1
 }
 
 // ProcessStatusWebhook indicates an expected call of ProcessStatusWebhook
-func (mr *MockWebhookServiceMockRecorder) ProcessStatusWebhook(payload interface{}) *gomock.Call {
+func (mr *MockWebhookServiceMockRecorder) ProcessStatusWebhook(ctx, payload interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessStatusWebhook", reflect.TypeOf((*MockWebhookService)(nil).ProcessStatusWebhook), payload)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessStatusWebhook", reflect.TypeOf((*MockWebhookService)(nil).ProcessStatusWebhook), ctx, payload)
 }
 
 // ProcessPullRequestWebhook mocks base method
-func (m *MockWebhookService) ProcessPullRequestWebhook(payload *webhook.PullRequestWebhook) (*webhook.Webhook, apierrors.ApiError) {
+func (m *MockWebhookService) ProcessPullRequestWebhook(ctx context.Context, payload *webhook.PullRequestWebhook) (*webhook.Webhook, apierrors.ApiError) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ProcessPullRequestWebhook", payload)
+	ret := m.ctrl.Call(m, "ProcessPullRequestWebhook", ctx, payload)
 	ret0, _ := ret[0].(*webhook.Webhook)
 	ret1, _ := ret[1].(apierrors.ApiError)
 	return ret0, ret1
 }
 
 // ProcessPullRequestWebhook indicates an expected call of ProcessPullRequestWebhook
-func (mr *MockWebhookServiceMockRecorder) ProcessPullRequestWebhook(payload interface{}) *gomock.Call {
+func (mr *MockWebhookServiceMockRecorder) ProcessPullRequestWebhook(ctx, payload interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessPullRequestWebhook", reflect.TypeOf((*MockWebhookService)(nil).ProcessPullRequestWebhook), payload)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessPullRequestWebhook", reflect.TypeOf((*MockWebhookService)(nil).ProcessPullRequestWebhook), ctx, payload)
 }
 
 // ProcessPullRequestReviewWebhook mocks base method
-func (m *MockWebhookService) ProcessPullRequestReviewWebhook(payload *webhook.PullRequestReviewWebhook) (*webhook.Webhook, apierrors.ApiError) {
+func (m *MockWebhookService) ProcessPullRequestReviewWebhook(ctx context.Context, payload *webhook.PullRequestReviewWebhook) (*webhook.Webhook, apierrors.ApiError) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ProcessPullRequestReviewWebhook", payload)
+	ret := m.ctrl.Call(m, "ProcessPullRequestReviewWebhook", ctx, payload)
 	ret0, _ := ret[0].(*webhook.Webhook)
 	ret1, _ := ret[1].(apierrors.ApiError)
 	return ret0, ret1
 }
 
 // ProcessPullRequestReviewWebhook indicates an expected call of ProcessPullRequestReviewWebhook
-func (mr *MockWebhookServiceMockRecorder) ProcessPullRequestReviewWebhook(payload interface{}) *gomock.Call {
+func (mr *MockWebhookServiceMockRecorder) ProcessPullRequestReviewWebhook(ctx, payload interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessPullRequestReviewWebhook", reflect.TypeOf((*MockWebhookService)(nil).ProcessPullRequestReviewWebhook), payload)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessPullRequestReviewWebhook", reflect.TypeOf((*MockWebhookService)(nil).ProcessPullRequestReviewWebhook), ctx, payload)
+}
+
+// ProcessIssueCommentWebhook mocks base method
+func (m *MockWebhookService) ProcessIssueCommentWebhook(ctx context.Context, payload *webhook.IssueComment) (*webhook.Webhook, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProcessIssueCommentWebhook", ctx, payload)
+	ret0, _ := ret[0].(*webhook.Webhook)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// ProcessIssueCommentWebhook indicates an expected call of ProcessIssueCommentWebhook
+func (mr *MockWebhookServiceMockRecorder) ProcessIssueCommentWebhook(ctx, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessIssueCommentWebhook", reflect.TypeOf((*MockWebhookService)(nil).ProcessIssueCommentWebhook), ctx, payload)
+}
+
+// ProcessPushWebhook mocks base method
+func (m *MockWebhookService) ProcessPushWebhook(ctx context.Context, payload *webhook.Push) (*webhook.Webhook, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProcessPushWebhook", ctx, payload)
+	ret0, _ := ret[0].(*webhook.Webhook)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// ProcessPushWebhook indicates an expected call of ProcessPushWebhook
+func (mr *MockWebhookServiceMockRecorder) ProcessPushWebhook(ctx, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessPushWebhook", reflect.TypeOf((*MockWebhookService)(nil).ProcessPushWebhook), ctx, payload)
+}
+
+// ProcessDeploymentStatusWebhook mocks base method
+func (m *MockWebhookService) ProcessDeploymentStatusWebhook(ctx context.Context, payload *webhook.DeploymentStatus) (*webhook.Webhook, apierrors.ApiError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProcessDeploymentStatusWebhook", ctx, payload)
+	ret0, _ := ret[0].(*webhook.Webhook)
+	ret1, _ := ret[1].(apierrors.ApiError)
+	return ret0, ret1
+}
+
+// ProcessDeploymentStatusWebhook indicates an expected call of ProcessDeploymentStatusWebhook
+func (mr *MockWebhookServiceMockRecorder) ProcessDeploymentStatusWebhook(ctx, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessDeploymentStatusWebhook", reflect.TypeOf((*MockWebhookService)(nil).ProcessDeploymentStatusWebhook), ctx, payload)
 }
 
 // SavePullRequestWebhook mocks base method
-func (m *MockWebhookService) SavePullRequestWebhook(pullRequestWH webhook.PullRequestWebhook) apierrors.ApiError {
+func (m *MockWebhookService) SavePullRequestWebhook(ctx context.Context, pullRequestWH webhook.PullRequestWebhook) apierrors.ApiError {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SavePullRequestWebhook", pullRequestWH)
+	ret := m.ctrl.Call(m, "SavePullRequestWebhook", ctx, pullRequestWH)
 	ret0, _ := ret[0].(apierrors.ApiError)
 	return ret0
 }
 
 // SavePullRequestWebhook indicates an expected call of SavePullRequestWebhook
-func (mr *MockWebhookServiceMockRecorder) SavePullRequestWebhook(pullRequestWH interface{}) *gomock.Call {
+func (mr *MockWebhookServiceMockRecorder) SavePullRequestWebhook(ctx, pullRequestWH interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePullRequestWebhook", reflect.TypeOf((*MockWebhookService)(nil).SavePullRequestWebhook), ctx, pullRequestWH)
+}
+
+// ResumeJobs mocks base method
+func (m *MockWebhookService) ResumeJobs(ctx context.Context, now time.Time) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ResumeJobs", ctx, now)
+}
+
+// ResumeJobs indicates an expected call of ResumeJobs
+func (mr *MockWebhookServiceMockRecorder) ResumeJobs(ctx, now interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePullRequestWebhook", reflect.TypeOf((*MockWebhookService)(nil).SavePullRequestWebhook), pullRequestWH)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumeJobs", reflect.TypeOf((*MockWebhookService)(nil).ResumeJobs), ctx, now)
 }
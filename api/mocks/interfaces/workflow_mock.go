@@ -5,6 +5,7 @@
 package interfaces
 
 import (
+	context "context"
 	gomock "github.com/golang/mock/gomock"
 	models "github.com/hbalmes/ci_cd-api/api/models"
 	webhook "github.com/hbalmes/ci_cd-api/api/models/webhook"
@@ -36,31 +37,31 @@ func (m *MockWorkflowService) EXPECT() *MockWorkflowServiceMockRecorder {
 }
 
 // SetWorkflow mocks base method
-func (m *MockWorkflowService) SetWorkflow(config *models.Configuration) apierrors.ApiError {
+func (m *MockWorkflowService) SetWorkflow(ctx context.Context, config *models.Configuration) apierrors.ApiError {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetWorkflow", config)
+	ret := m.ctrl.Call(m, "SetWorkflow", ctx, config)
 	ret0, _ := ret[0].(apierrors.ApiError)
 	return ret0
 }
 
 // SetWorkflow indicates an expected call of SetWorkflow
-func (mr *MockWorkflowServiceMockRecorder) SetWorkflow(config interface{}) *gomock.Call {
+func (mr *MockWorkflowServiceMockRecorder) SetWorkflow(ctx, config interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWorkflow", reflect.TypeOf((*MockWorkflowService)(nil).SetWorkflow), config)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWorkflow", reflect.TypeOf((*MockWorkflowService)(nil).SetWorkflow), ctx, config)
 }
 
 // UnsetWorkflow mocks base method
-func (m *MockWorkflowService) UnsetWorkflow(config *models.Configuration) apierrors.ApiError {
+func (m *MockWorkflowService) UnsetWorkflow(ctx context.Context, config *models.Configuration) apierrors.ApiError {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UnsetWorkflow", config)
+	ret := m.ctrl.Call(m, "UnsetWorkflow", ctx, config)
 	ret0, _ := ret[0].(apierrors.ApiError)
 	return ret0
 }
 
 // UnsetWorkflow indicates an expected call of UnsetWorkflow
-func (mr *MockWorkflowServiceMockRecorder) UnsetWorkflow(config interface{}) *gomock.Call {
+func (mr *MockWorkflowServiceMockRecorder) UnsetWorkflow(ctx, config interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnsetWorkflow", reflect.TypeOf((*MockWorkflowService)(nil).UnsetWorkflow), config)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnsetWorkflow", reflect.TypeOf((*MockWorkflowService)(nil).UnsetWorkflow), ctx, config)
 }
 
 // CheckWorkflow mocks base method
@@ -0,0 +1,146 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	//APITokenScopeRead allows to get the configurations, builds, deployments and the rest of the resources
+	APITokenScopeRead = "read"
+	//APITokenScopeConfigure allows to change the configurations, release trains and freeze windows
+	APITokenScopeConfigure = "configure"
+	//APITokenScopeRelease allows to deploy, promote, roll back and approve builds and to upload coverage reports
+	APITokenScopeRelease = "release"
+	//APITokenScopeAdmin allows everything, minting and revoking api tokens included
+	APITokenScopeAdmin = "admin"
+)
+
+//APITokenScopes are the valid scopes of an api token
+var APITokenScopes = []string{APITokenScopeRead, APITokenScopeConfigure, APITokenScopeRelease, APITokenScopeAdmin}
+
+//APITokenPayload represents the payload received in the api token POST request.
+//The subject is the github user the token is issued to, the tokens of the pipelines and the bots have none.
+//The repositories are full names (owner/name) or every repository of an owner (owner/*),
+//the token can access every repository when there is none.
+type APITokenPayload struct {
+	Name         *string  `json:"name"`
+	Subject      *string  `json:"subject"`
+	Scopes       []string `json:"scopes"`
+	Repositories []string `json:"repositories"`
+}
+
+//APIToken represents a token authenticating the requests to the API.
+//Only the hash of the token is stored, the token itself is returned once when it is minted.
+//The actions done with a token bound to a subject are attributed to that github user,
+//the approvals, the promotions and the freeze overrides need one.
+type APIToken struct {
+	ID           uint32     `json:"id" gorm:"primary_key;AUTO_INCREMENT"`
+	Name         *string    `json:"name"`
+	Subject      *string    `json:"subject"`
+	Hash         *string    `json:"-" gorm:"unique_index:api_token_hash"`
+	Prefix       *string    `json:"prefix"`
+	Scopes       *string    `json:"-"`
+	Repositories *string    `json:"-" gorm:"type:text"`
+	RevokedAt    *time.Time `json:"revoked_at"`
+
+	//Token is the secret, only known when the token is minted
+	Token string `json:"-" gorm:"-"`
+
+	//GORM date attributes
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+//GetScopes returns the scopes of the token
+func (t *APIToken) GetScopes() []string {
+	return splitList(t.Scopes)
+}
+
+//GetRepositories returns the repositories the token is restricted to, none when it is not restricted
+func (t *APIToken) GetRepositories() []string {
+	return splitList(t.Repositories)
+}
+
+//HasScope checks whether the token is allowed to do what the scope allows.
+//The admin scope allows everything and any scope allows to read.
+func (t *APIToken) HasScope(scope string) bool {
+	scopes := t.GetScopes()
+
+	if len(scopes) > 0 && scope == APITokenScopeRead {
+		return true
+	}
+
+	for _, s := range scopes {
+		if s == scope || s == APITokenScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+//CanAccess checks whether the token can access the repository (owner/name).
+//An empty repository name stands for the global resources, only the tokens not restricted to some repositories can access them.
+func (t *APIToken) CanAccess(repositoryName string) bool {
+	repositories := t.GetRepositories()
+
+	if len(repositories) == 0 {
+		return true
+	}
+
+	if repositoryName == "" {
+		return false
+	}
+
+	for _, r := range repositories {
+		if strings.EqualFold(r, repositoryName) {
+			return true
+		}
+		if owner := strings.TrimSuffix(r, "/*"); owner != r && strings.HasPrefix(strings.ToLower(repositoryName), strings.ToLower(owner)+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+//Marshall converts the APIToken struct into a readable JSON interface.
+//The token itself is only present when it has just been minted.
+func (t *APIToken) Marshall() interface{} {
+	return &struct {
+		ID           uint32     `json:"id"`
+		Name         *string    `json:"name"`
+		Subject      *string    `json:"subject"`
+		Prefix       *string    `json:"prefix"`
+		Scopes       []string   `json:"scopes"`
+		Repositories []string   `json:"repositories"`
+		Token        string     `json:"token,omitempty"`
+		RevokedAt    *time.Time `json:"revoked_at"`
+		CreatedAt    time.Time  `json:"created_at"`
+	}{
+		ID:           t.ID,
+		Name:         t.Name,
+		Subject:      t.Subject,
+		Prefix:       t.Prefix,
+		Scopes:       t.GetScopes(),
+		Repositories: t.GetRepositories(),
+		Token:        t.Token,
+		RevokedAt:    t.RevokedAt,
+		CreatedAt:    t.CreatedAt,
+	}
+}
+
+//GetSubject returns the github user the token is bound to, empty when it is not bound to any
+func (t *APIToken) GetSubject() string {
+	if t.Subject == nil {
+		return ""
+	}
+	return *t.Subject
+}
+
+//splitList splits a comma separated list
+func splitList(list *string) []string {
+	if list == nil || *list == "" {
+		return []string{}
+	}
+	return strings.Split(*list, ",")
+}
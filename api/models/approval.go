@@ -0,0 +1,61 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	//ApprovalDecisionApproved is the decision of an approver who signed off the deployment
+	ApprovalDecisionApproved = "approved"
+	//ApprovalDecisionRejected is the decision of an approver who blocked the deployment
+	ApprovalDecisionRejected = "rejected"
+)
+
+//ApprovalPayload represents the payload received in the approve and reject POST requests.
+//The approver is the github user the api token is bound to, the approver of the payload is only checked against it.
+type ApprovalPayload struct {
+	Approver *string `json:"approver"`
+	Comment  *string `json:"comment"`
+}
+
+//Approval represents the decision of an approver over the deployment of a build into an environment.
+//The approvals are never deleted, so they are the audit trail of the approval gates.
+type Approval struct {
+	ID             uint32     `json:"id" gorm:"primary_key;AUTO_INCREMENT"`
+	RepositoryName *string    `json:"repository_name" gorm:"index:approval_repo_env_build"`
+	Environment    *string    `json:"environment" gorm:"index:approval_repo_env_build"`
+	BuildID        uint32     `json:"build_id" gorm:"index:approval_repo_env_build"`
+	Approver       *string    `json:"approver"`
+	Decision       *string    `json:"decision"`
+	Comment        *string    `json:"comment" gorm:"type:text"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+
+	//GORM date attributes
+	CreatedAt time.Time `json:"created_at"`
+}
+
+//IsExpired checks if the approval expired at the given time.
+func (a *Approval) IsExpired(now time.Time) bool {
+	return a.ExpiresAt != nil && !now.Before(*a.ExpiresAt)
+}
+
+//ApprovalGate represents the state of the approval gate of an environment for a build.
+type ApprovalGate struct {
+	Environment       string     `json:"environment"`
+	BuildID           uint32     `json:"build_id"`
+	RequiredApprovals int        `json:"required_approvals"`
+	ApprovedBy        []string   `json:"approved_by"`
+	RejectedBy        []string   `json:"rejected_by"`
+	Satisfied         bool       `json:"satisfied"`
+	Approvals         []Approval `json:"approvals"`
+}
+
+//Pending describes why the gate is not satisfied.
+func (g *ApprovalGate) Pending() string {
+	if len(g.RejectedBy) > 0 {
+		return fmt.Sprintf("the deployment to %s was rejected by %s", g.Environment, strings.Join(g.RejectedBy, ", "))
+	}
+	return fmt.Sprintf("the deployment to %s has %d of %d required approvals", g.Environment, len(g.ApprovedBy), g.RequiredApprovals)
+}
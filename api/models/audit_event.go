@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+const (
+	//AuditEventDirectPush is recorded when a stable branch receives commits that did not come through a merged pull request
+	AuditEventDirectPush = "direct_push"
+	//AuditEventReleaseCut is recorded when a release train cuts a new release branch
+	AuditEventReleaseCut = "release_cut"
+	//AuditEventBranchDeleted is recorded when the head branch of a merged pull request is deleted
+	AuditEventBranchDeleted = "branch_deleted"
+	//AuditEventBuildPromoted is recorded when a test build is promoted to a productive release
+	AuditEventBuildPromoted = "build_promoted"
+	//AuditEventApprovalGranted is recorded when an approver approves the deployment of a build into an environment
+	AuditEventApprovalGranted = "approval_granted"
+	//AuditEventApprovalRejected is recorded when an approver rejects the deployment of a build into an environment
+	AuditEventApprovalRejected = "approval_rejected"
+	//AuditEventFreezeOverride is recorded when a productive release or a production deployment overrides a freeze window
+	AuditEventFreezeOverride = "freeze_override"
+	//AuditEventBuildRolledBack is recorded when a productive build is rolled back
+	AuditEventBuildRolledBack = "build_rolled_back"
+)
+
+//AuditEvent represents an action over a repository that must be kept for audit purposes.
+type AuditEvent struct {
+	ID             uint32  `json:"id" gorm:"primary_key;AUTO_INCREMENT"`
+	RepositoryName *string `json:"repository_name" gorm:"index:audit_event_repo"`
+	Type           *string `json:"type"`
+	Branch         *string `json:"branch"`
+	Sha            *string `json:"sha"`
+	Actor          *string `json:"actor"`
+	Description    *string `json:"description"`
+
+	//GORM date attributes
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+//AutoMergeNotice represents the last declined auto-merge reported to a pull request.
+//It keeps the same reason from being reported again on every status webhook.
+type AutoMergeNotice struct {
+	PullRequestID     int64   `json:"pull_request_id" gorm:"primary_key;auto_increment:false"`
+	RepositoryName    *string `json:"repository_name"`
+	PullRequestNumber int     `json:"pull_request_number"`
+	Reason            *string `json:"reason"`
+
+	//GORM date attributes
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+//IsReported checks if the notice already reported the reason.
+func (n *AutoMergeNotice) IsReported(reason string) bool {
+	return n.Reason != nil && *n.Reason == reason
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+//BranchHead represents the latest sha pushed to a repository branch.
+type BranchHead struct {
+	ID             *string `json:"-" gorm:"primary_key"`
+	RepositoryName *string `json:"repository_name" gorm:"index:branch_head_repo"`
+	Branch         *string `json:"branch"`
+	Sha            *string `json:"sha"`
+	PushedBy       *string `json:"pushed_by"`
+
+	//GORM date attributes
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -1,8 +1,17 @@
 package models
 
+const (
+	//BuildStatusFinished is the status of a released build
+	BuildStatusFinished = "finished"
+	//BuildStatusRolledBack is the status of a productive build which was rolled back, its version is never reused
+	BuildStatusRolledBack = "rolled_back"
+)
+
 type Build struct {
-	ID             uint32  `json:"id" gorm:"primary_key;AUTO_INCREMENT"`
-	Sha            *string `json:"sha"`
+	ID  uint32  `json:"id" gorm:"primary_key;AUTO_INCREMENT"`
+	Sha *string `json:"sha"`
+	//SourceSha is the pull request head sha which was released, Sha is the version files commit made on top of it
+	SourceSha      *string `json:"source_sha"`
 	Major          uint8   `json:"major"`
 	Minor          uint16  `json:"minor"`
 	Patch          uint16  `json:"patch"`
@@ -17,4 +26,14 @@ type Build struct {
 	Body           *string `json:"body"`
 	GithubID       *string `json:"github_id"`
 	GithubURL      *string `json:"github_url"`
+	PromotedFromID *uint32 `json:"promoted_from_id"`
+	//ReleasedPullRequests are the pull requests listed in the release notes of the build
+	ReleasedPullRequests []ReleasedPullRequest `json:"released_pull_requests"`
+}
+
+//ReleasedPullRequest represents a pull request listed in the release notes of a build.
+type ReleasedPullRequest struct {
+	ID                uint32 `json:"-" gorm:"primary_key;AUTO_INCREMENT"`
+	BuildID           uint32 `json:"-" gorm:"index:released_pull_request_build"`
+	PullRequestNumber int    `json:"pull_request_number"`
 }
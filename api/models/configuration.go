@@ -1,14 +1,63 @@
 package models
 
 import (
+	"sort"
 	"time"
 )
 
+const defaultAutoMergeMethod = "merge"
+
+var autoMergeMethods = []string{"merge", "squash", "rebase"}
+
+var defaultBranchCleanupPrefixes = []string{"feature/", "fix/", "hotfix/"}
+
+const (
+	defaultChangelogPath = "CHANGELOG.md"
+	//ChangelogFormatKeepAChangelog follows the https://keepachangelog.com conventions
+	ChangelogFormatKeepAChangelog = "keep-a-changelog"
+	//ChangelogFormatReleaseNotes writes the generated release notes as they are
+	ChangelogFormatReleaseNotes = "release-notes"
+)
+
+var changelogFormats = []string{ChangelogFormatKeepAChangelog, ChangelogFormatReleaseNotes}
+
+const (
+	//VersionFileTypePlain is a file which only contains the version, like VERSION
+	VersionFileTypePlain = "version"
+	//VersionFileTypePackageJSON is a npm package.json
+	VersionFileTypePackageJSON = "package.json"
+	//VersionFileTypePom is a maven pom.xml
+	VersionFileTypePom = "pom.xml"
+	//VersionFileTypeChart is a helm Chart.yaml
+	VersionFileTypeChart = "chart"
+	//VersionFileTypeGo is a go file with a Version constant
+	VersionFileTypeGo = "go"
+)
+
+//defaultEnvironments are the environments of the repositories without environments configured.
+var defaultEnvironments = []Environment{
+	{Name: "dev", Position: 0},
+	{Name: "staging", Position: 1},
+	{Name: "production", Production: true, Position: 2},
+}
+
+const defaultPromotionStagingEnvironment = "staging"
+
+//defaultVersionFilePaths are the paths used when the version file has no path configured.
+var defaultVersionFilePaths = map[string]string{
+	VersionFileTypePlain:       "VERSION",
+	VersionFileTypePackageJSON: "package.json",
+	VersionFileTypePom:         "pom.xml",
+	VersionFileTypeChart:       "Chart.yaml",
+	VersionFileTypeGo:          "version.go",
+}
+
 //PostRequestPayload represents the payload received in the POST request.
 type PostRequestPayload struct {
 	Repository struct {
 		Name                *string  `json:"name"`
 		Owner               *string  `json:"owner"`
+		Team                *string  `json:"team"`
 		RequireStatusChecks []string `json:"required_status_checks"`
 	} `json:"repository"`
 
@@ -19,18 +68,128 @@ type PostRequestPayload struct {
 	CodeCoverage struct {
 		PullRequestThreshold *float64 `json:"pull_request_threshold"`
 	} `json:"code_coverage"`
+
+	ChatOps struct {
+		Maintainers []string `json:"maintainers"`
+	} `json:"chatops"`
+
+	Notifications struct {
+		DirectPushes *bool `json:"direct_pushes"`
+	} `json:"notifications"`
+
+	BackMerge struct {
+		AutoMerge *bool `json:"auto_merge"`
+	} `json:"back_merge"`
+
+	AutoMerge struct {
+		Enabled *bool   `json:"enabled"`
+		Method  *string `json:"method"`
+	} `json:"auto_merge"`
+
+	BranchCleanup struct {
+		Enabled  *bool    `json:"enabled"`
+		Prefixes []string `json:"prefixes"`
+	} `json:"branch_cleanup"`
+
+	ReleaseNotes struct {
+		Template *string `json:"template"`
+	} `json:"release_notes"`
+
+	Changelog struct {
+		Enabled *bool   `json:"enabled"`
+		Path    *string `json:"path"`
+		Format  *string `json:"format"`
+	} `json:"changelog"`
+
+	VersionFiles []VersionFileRequest `json:"version_files"`
+
+	Environments []EnvironmentRequest `json:"environments"`
+
+	Promotion struct {
+		MinSoakMinutes           *int    `json:"min_soak_minutes"`
+		RequireStagingDeployment *bool   `json:"require_staging_deployment"`
+		StagingEnvironment       *string `json:"staging_environment"`
+		RequireApproval          *bool   `json:"require_approval"`
+	} `json:"promotion"`
 }
 
 //PutRequestPayload represents the payload received in the PUT request.
 type PutRequestPayload struct {
 	Repository struct {
 		Name                *string
+		Team                *string  `json:"team"`
 		RequireStatusChecks []string `json:"required_status_checks"`
 	} `json:"repository"`
 
 	CodeCoverage struct {
 		PullRequestThreshold *float64 `json:"pull_request_threshold"`
 	} `json:"code_coverage"`
+
+	ChatOps struct {
+		Maintainers []string `json:"maintainers"`
+	} `json:"chatops"`
+
+	Notifications struct {
+		DirectPushes *bool `json:"direct_pushes"`
+	} `json:"notifications"`
+
+	BackMerge struct {
+		AutoMerge *bool `json:"auto_merge"`
+	} `json:"back_merge"`
+
+	AutoMerge struct {
+		Enabled *bool   `json:"enabled"`
+		Method  *string `json:"method"`
+	} `json:"auto_merge"`
+
+	BranchCleanup struct {
+		Enabled  *bool    `json:"enabled"`
+		Prefixes []string `json:"prefixes"`
+	} `json:"branch_cleanup"`
+
+	ReleaseNotes struct {
+		Template *string `json:"template"`
+	} `json:"release_notes"`
+
+	Changelog struct {
+		Enabled *bool   `json:"enabled"`
+		Path    *string `json:"path"`
+		Format  *string `json:"format"`
+	} `json:"changelog"`
+
+	VersionFiles []VersionFileRequest `json:"version_files"`
+
+	Environments []EnvironmentRequest `json:"environments"`
+
+	Promotion struct {
+		MinSoakMinutes           *int    `json:"min_soak_minutes"`
+		RequireStagingDeployment *bool   `json:"require_staging_deployment"`
+		StagingEnvironment       *string `json:"staging_environment"`
+		RequireApproval          *bool   `json:"require_approval"`
+	} `json:"promotion"`
+}
+
+//VersionFileRequest represents a version file received in the POST and PUT requests.
+type VersionFileRequest struct {
+	Type string `json:"type"`
+	Path string `json:"path"`
+}
+
+//EnvironmentRequest represents an environment received in the POST and PUT requests.
+//The environments are received in the order in which the builds are deployed.
+type EnvironmentRequest struct {
+	Name       string               `json:"name"`
+	Production bool                 `json:"production"`
+	Approval   *ApprovalGateRequest `json:"approval,omitempty"`
+}
+
+//ApprovalGateRequest represents the approval gate of an environment received in the POST and PUT requests.
+//The teams are team slugs of the repository owner organization.
+type ApprovalGateRequest struct {
+	Approvers         []string `json:"approvers"`
+	Teams             []string `json:"teams"`
+	RequiredApprovals int      `json:"required_approvals"`
+	ExpiryMinutes     int      `json:"expiry_minutes"`
 }
 
 //Configuration represents the only business object of this API.
@@ -39,9 +198,28 @@ type Configuration struct {
 	ID                               *string `gorm:"primary_key"`
 	RepositoryName                   *string
 	RepositoryOwner                  *string
+	Team                             *string `gorm:"index:configuration_team"`
 	RepositoryStatusChecks           []RequireStatusCheck
 	WorkflowType                     *string
 	CodeCoveragePullRequestThreshold *float64
+	Maintainers                      []Maintainer
+	NotifyDirectPushes               *bool
+	BackMergeAutoMerge               *bool
+	AutoMergeEnabled                 *bool
+	AutoMergeMethod                  *string
+	BranchCleanupEnabled             *bool
+	BranchCleanupPrefixes            []BranchCleanupPrefix
+	ReleaseNotesTemplate             *string `gorm:"type:text"`
+	ChangelogEnabled                 *bool
+	ChangelogPath                    *string
+	ChangelogFormat                  *string
+	VersionFiles                     []VersionFile
+	Environments                     []Environment
+	EnvironmentApprovers             []EnvironmentApprover
+	PromotionMinSoakMinutes          *int
+	PromotionRequireStaging          *bool
+	PromotionStagingEnvironment      *string
+	PromotionRequireApproval         *bool
 
 	//GORM date attributes
 	CreatedAt time.Time
@@ -56,12 +234,71 @@ type RequireStatusCheck struct {
 	ConfigurationID *string
 }
 
+//Maintainer is a github user allowed to execute ChatOps commands
+//even without write permissions over the repository.
+type Maintainer struct {
+	ID              *uint64 `gorm:"primary_key"`
+	Username        string
+	ConfigurationID *string
+}
+
+//BranchCleanupPrefix is a transient branch prefix whose branches are deleted once merged.
+type BranchCleanupPrefix struct {
+	ID              *uint64 `gorm:"primary_key"`
+	Prefix          string
+	ConfigurationID *string
+}
+
+//VersionFile is a file of the repository whose version is rewritten on every release.
+type VersionFile struct {
+	ID              *uint64 `gorm:"primary_key"`
+	Type            string
+	Path            string
+	ConfigurationID *string
+}
+
+//GetPath returns the path of the version file in the repository.
+//Defaults to the usual path for the file type.
+func (f *VersionFile) GetPath() string {
+	if f.Path == "" {
+		return defaultVersionFilePaths[f.Type]
+	}
+	return f.Path
+}
+
+//Environment is a place where the builds of the repository are deployed.
+//The position is the order in which the builds go through the environments.
+type Environment struct {
+	ID                    *uint64 `gorm:"primary_key"`
+	Name                  string
+	Production            bool
+	Position              int
+	RequiredApprovals     int
+	ApprovalExpiryMinutes int
+	ConfigurationID       *string
+}
+
+//HasApprovalGate checks if the deployments to the environment must be approved.
+func (e *Environment) HasApprovalGate() bool {
+	return e.RequiredApprovals > 0
+}
+
+//EnvironmentApprover is a github user or team allowed to approve the deployments to an environment.
+type EnvironmentApprover struct {
+	ID              *uint64 `gorm:"primary_key"`
+	Environment     string
+	Username        string
+	Team            string
+	ConfigurationID *string
+}
+
 //NewConfiguration converts a PostRequestPayload into a Configuration.
 func NewConfiguration(r *PostRequestPayload) *Configuration {
 	var c Configuration
 
 	c.RepositoryName = r.Repository.Name
 	c.RepositoryOwner = r.Repository.Owner
+	c.Team = r.Repository.Team
 	c.WorkflowType = r.Workflow.Type
 	c.CodeCoveragePullRequestThreshold = r.CodeCoverage.PullRequestThreshold
 
@@ -73,12 +310,34 @@ func NewConfiguration(r *PostRequestPayload) *Configuration {
 	}
 
 	c.RepositoryStatusChecks = reqChecks
+	c.Maintainers = newMaintainers(r.ChatOps.Maintainers)
+	c.NotifyDirectPushes = r.Notifications.DirectPushes
+	c.BackMergeAutoMerge = r.BackMerge.AutoMerge
+	c.AutoMergeEnabled = r.AutoMerge.Enabled
+	c.AutoMergeMethod = r.AutoMerge.Method
+	c.BranchCleanupEnabled = r.BranchCleanup.Enabled
+	c.BranchCleanupPrefixes = newBranchCleanupPrefixes(r.BranchCleanup.Prefixes)
+	c.ReleaseNotesTemplate = r.ReleaseNotes.Template
+	c.ChangelogEnabled = r.Changelog.Enabled
+	c.ChangelogPath = r.Changelog.Path
+	c.ChangelogFormat = r.Changelog.Format
+	c.VersionFiles = newVersionFiles(r.VersionFiles)
+	c.Environments = newEnvironments(r.Environments)
+	c.EnvironmentApprovers = newEnvironmentApprovers(r.Environments)
+	c.PromotionMinSoakMinutes = r.Promotion.MinSoakMinutes
+	c.PromotionRequireStaging = r.Promotion.RequireStagingDeployment
+	c.PromotionStagingEnvironment = r.Promotion.StagingEnvironment
+	c.PromotionRequireApproval = r.Promotion.RequireApproval
 
 	return &c
 }
 
 //UpdateConfiguration updates a Configuration based on a PutRequestPayload.
 func (c *Configuration) UpdateConfiguration(r *PutRequestPayload) {
+	if r.Repository.Team != nil {
+		c.Team = r.Repository.Team
+	}
+
 	if r.CodeCoverage.PullRequestThreshold != nil {
 		c.CodeCoveragePullRequestThreshold = r.CodeCoverage.PullRequestThreshold
 	}
@@ -92,6 +351,329 @@ func (c *Configuration) UpdateConfiguration(r *PutRequestPayload) {
 		}
 		c.RepositoryStatusChecks = reqChecks
 	}
+
+	if r.ChatOps.Maintainers != nil {
+		c.Maintainers = newMaintainers(r.ChatOps.Maintainers)
+	}
+
+	if r.Notifications.DirectPushes != nil {
+		c.NotifyDirectPushes = r.Notifications.DirectPushes
+	}
+
+	if r.BackMerge.AutoMerge != nil {
+		c.BackMergeAutoMerge = r.BackMerge.AutoMerge
+	}
+
+	if r.AutoMerge.Enabled != nil {
+		c.AutoMergeEnabled = r.AutoMerge.Enabled
+	}
+
+	if r.AutoMerge.Method != nil {
+		c.AutoMergeMethod = r.AutoMerge.Method
+	}
+
+	if r.BranchCleanup.Enabled != nil {
+		c.BranchCleanupEnabled = r.BranchCleanup.Enabled
+	}
+
+	if r.BranchCleanup.Prefixes != nil {
+		c.BranchCleanupPrefixes = newBranchCleanupPrefixes(r.BranchCleanup.Prefixes)
+	}
+
+	if r.ReleaseNotes.Template != nil {
+		c.ReleaseNotesTemplate = r.ReleaseNotes.Template
+	}
+
+	if r.Changelog.Enabled != nil {
+		c.ChangelogEnabled = r.Changelog.Enabled
+	}
+
+	if r.Changelog.Path != nil {
+		c.ChangelogPath = r.Changelog.Path
+	}
+
+	if r.Changelog.Format != nil {
+		c.ChangelogFormat = r.Changelog.Format
+	}
+
+	if r.VersionFiles != nil {
+		c.VersionFiles = newVersionFiles(r.VersionFiles)
+	}
+
+	if r.Environments != nil {
+		c.Environments = newEnvironments(r.Environments)
+		c.EnvironmentApprovers = newEnvironmentApprovers(r.Environments)
+	}
+
+	if r.Promotion.MinSoakMinutes != nil {
+		c.PromotionMinSoakMinutes = r.Promotion.MinSoakMinutes
+	}
+
+	if r.Promotion.RequireStagingDeployment != nil {
+		c.PromotionRequireStaging = r.Promotion.RequireStagingDeployment
+	}
+
+	if r.Promotion.StagingEnvironment != nil {
+		c.PromotionStagingEnvironment = r.Promotion.StagingEnvironment
+	}
+
+	if r.Promotion.RequireApproval != nil {
+		c.PromotionRequireApproval = r.Promotion.RequireApproval
+	}
+}
+
+//GetBranchCleanupPrefixes maps the BranchCleanupPrefixes field in the Configuration struct into a string slice.
+//Defaults to the transient branches of the workflow.
+func (c *Configuration) GetBranchCleanupPrefixes() []string {
+	if len(c.BranchCleanupPrefixes) == 0 {
+		return defaultBranchCleanupPrefixes
+	}
+
+	var prefixes []string
+	for _, p := range c.BranchCleanupPrefixes {
+		prefixes = append(prefixes, p.Prefix)
+	}
+	return prefixes
+}
+
+func newBranchCleanupPrefixes(prefixes []string) []BranchCleanupPrefix {
+	cleanupPrefixes := make([]BranchCleanupPrefix, 0)
+	for _, prefix := range prefixes {
+		cleanupPrefixes = append(cleanupPrefixes, BranchCleanupPrefix{
+			Prefix: prefix,
+		})
+	}
+	return cleanupPrefixes
+}
+
+//GetAutoMergeMethod returns the method used to merge pull requests automatically.
+//Defaults to a merge commit.
+func (c *Configuration) GetAutoMergeMethod() string {
+	if c.AutoMergeMethod == nil {
+		return defaultAutoMergeMethod
+	}
+	return *c.AutoMergeMethod
+}
+
+//IsValidAutoMergeMethod checks if the method is one of the merge methods supported by github.
+func IsValidAutoMergeMethod(method *string) bool {
+	if method == nil {
+		return true
+	}
+	for _, m := range autoMergeMethods {
+		if m == *method {
+			return true
+		}
+	}
+	return false
+}
+
+//GetChangelogPath returns the path of the changelog file in the repository.
+//Defaults to CHANGELOG.md in the repository root.
+func (c *Configuration) GetChangelogPath() string {
+	if c.ChangelogPath == nil || *c.ChangelogPath == "" {
+		return defaultChangelogPath
+	}
+	return *c.ChangelogPath
+}
+
+//GetChangelogFormat returns the format used to write the changelog sections.
+//Defaults to Keep a Changelog.
+func (c *Configuration) GetChangelogFormat() string {
+	if c.ChangelogFormat == nil {
+		return ChangelogFormatKeepAChangelog
+	}
+	return *c.ChangelogFormat
+}
+
+//IsValidChangelogFormat checks if the format is one of the supported changelog formats.
+func IsValidChangelogFormat(format *string) bool {
+	if format == nil {
+		return true
+	}
+	for _, f := range changelogFormats {
+		if f == *format {
+			return true
+		}
+	}
+	return false
+}
+
+//GetVersionFiles maps the VersionFiles field in the Configuration struct into a VersionFileRequest slice.
+func (c *Configuration) GetVersionFiles() []VersionFileRequest {
+	files := make([]VersionFileRequest, 0)
+	for _, f := range c.VersionFiles {
+		files = append(files, VersionFileRequest{
+			Type: f.Type,
+			Path: f.GetPath(),
+		})
+	}
+	return files
+}
+
+func newVersionFiles(requests []VersionFileRequest) []VersionFile {
+	files := make([]VersionFile, 0)
+	for _, r := range requests {
+		files = append(files, VersionFile{
+			Type: r.Type,
+			Path: r.Path,
+		})
+	}
+	return files
+}
+
+//IsValidVersionFiles checks if every version file has a supported type.
+func IsValidVersionFiles(files []VersionFileRequest) bool {
+	for _, f := range files {
+		if _, ok := defaultVersionFilePaths[f.Type]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+//GetEnvironments returns the environments of the repository sorted by position.
+//Defaults to dev, staging and production.
+func (c *Configuration) GetEnvironments() []Environment {
+	if len(c.Environments) == 0 {
+		return defaultEnvironments
+	}
+
+	environments := make([]Environment, len(c.Environments))
+	copy(environments, c.Environments)
+	sort.SliceStable(environments, func(i, j int) bool {
+		return environments[i].Position < environments[j].Position
+	})
+	return environments
+}
+
+//GetEnvironment returns the environment with the given name.
+//Returns nil when the repository has not the environment.
+func (c *Configuration) GetEnvironment(name string) *Environment {
+	for _, e := range c.GetEnvironments() {
+		if e.Name == name {
+			return &e
+		}
+	}
+	return nil
+}
+
+//GetProductionEnvironment returns the first production environment of the repository.
+//Returns nil when the repository has no production environment.
+func (c *Configuration) GetProductionEnvironment() *Environment {
+	for _, e := range c.GetEnvironments() {
+		if e.Production {
+			return &e
+		}
+	}
+	return nil
+}
+
+func newEnvironments(requests []EnvironmentRequest) []Environment {
+	environments := make([]Environment, 0)
+	for i, r := range requests {
+		environment := Environment{
+			Name:       r.Name,
+			Production: r.Production,
+			Position:   i,
+		}
+		if r.Approval != nil {
+			environment.RequiredApprovals = r.Approval.RequiredApprovals
+			environment.ApprovalExpiryMinutes = r.Approval.ExpiryMinutes
+		}
+		environments = append(environments, environment)
+	}
+	return environments
+}
+
+//GetEnvironmentApprovers returns the users and the teams allowed to approve the deployments to the environment.
+func (c *Configuration) GetEnvironmentApprovers(environment string) (users []string, teams []string) {
+	users = make([]string, 0)
+	teams = make([]string, 0)
+	for _, a := range c.EnvironmentApprovers {
+		if a.Environment != environment {
+			continue
+		}
+		if a.Team != "" {
+			teams = append(teams, a.Team)
+		} else {
+			users = append(users, a.Username)
+		}
+	}
+	return users, teams
+}
+
+func newEnvironmentApprovers(requests []EnvironmentRequest) []EnvironmentApprover {
+	approvers := make([]EnvironmentApprover, 0)
+	for _, r := range requests {
+		if r.Approval == nil {
+			continue
+		}
+		for _, username := range r.Approval.Approvers {
+			approvers = append(approvers, EnvironmentApprover{Environment: r.Name, Username: username})
+		}
+		for _, team := range r.Approval.Teams {
+			approvers = append(approvers, EnvironmentApprover{Environment: r.Name, Team: team})
+		}
+	}
+	return approvers
+}
+
+//IsValidEnvironments checks that every environment has a name, that the names are not repeated
+//and that the approval gates have no negative values.
+func IsValidEnvironments(environments []EnvironmentRequest) bool {
+	names := make(map[string]bool)
+	for _, e := range environments {
+		if e.Name == "" || names[e.Name] {
+			return false
+		}
+		if e.Approval != nil && (e.Approval.RequiredApprovals < 0 || e.Approval.ExpiryMinutes < 0) {
+			return false
+		}
+		names[e.Name] = true
+	}
+	return true
+}
+
+//GetPromotionMinSoakMinutes returns the minutes a test build must wait before being promoted.
+func (c *Configuration) GetPromotionMinSoakMinutes() int {
+	if c.PromotionMinSoakMinutes == nil {
+		return 0
+	}
+	return *c.PromotionMinSoakMinutes
+}
+
+//GetPromotionStagingEnvironment returns the environment where the test builds are validated before being promoted.
+//Defaults to staging.
+func (c *Configuration) GetPromotionStagingEnvironment() string {
+	if c.PromotionStagingEnvironment == nil || *c.PromotionStagingEnvironment == "" {
+		return defaultPromotionStagingEnvironment
+	}
+	return *c.PromotionStagingEnvironment
+}
+
+//IsValidPromotionSoak checks that the minimum soak time is not negative.
+func IsValidPromotionSoak(minutes *int) bool {
+	return minutes == nil || *minutes >= 0
+}
+
+//GetMaintainers maps the Maintainers field in the Configuration struct into a string slice.
+func (c *Configuration) GetMaintainers() []string {
+	var maintainers []string
+	for _, m := range c.Maintainers {
+		maintainers = append(maintainers, m.Username)
+	}
+	return maintainers
+}
+
+func newMaintainers(usernames []string) []Maintainer {
+	maintainers := make([]Maintainer, 0)
+	for _, username := range usernames {
+		maintainers = append(maintainers, Maintainer{
+			Username: username,
+		})
+	}
+	return maintainers
 }
 
 //GetRequiredStatusCheck maps the RepositoryStatusChecks field in the Configuration struct into a string slice.
@@ -111,6 +693,7 @@ func (c *Configuration) Marshall() interface{} {
 		Repository struct {
 			Name                string   `json:"name"`
 			Owner               string   `json:"owner"`
+			Team                *string  `json:"team"`
 			RequiredStatusCheck []string `json:"required_status_check"`
 		} `json:"repository"`
 		CodeCoverage struct {
@@ -119,15 +702,50 @@ func (c *Configuration) Marshall() interface{} {
 		Workflow struct {
 			Type string `json:"type"`
 		} `json:"workflow"`
+		ChatOps struct {
+			Maintainers []string `json:"maintainers"`
+		} `json:"chatops"`
+		Notifications struct {
+			DirectPushes bool `json:"direct_pushes"`
+		} `json:"notifications"`
+		BackMerge struct {
+			AutoMerge bool `json:"auto_merge"`
+		} `json:"back_merge"`
+		AutoMerge struct {
+			Enabled bool   `json:"enabled"`
+			Method  string `json:"method"`
+		} `json:"auto_merge"`
+		BranchCleanup struct {
+			Enabled  bool     `json:"enabled"`
+			Prefixes []string `json:"prefixes"`
+		} `json:"branch_cleanup"`
+		ReleaseNotes struct {
+			Template *string `json:"template"`
+		} `json:"release_notes"`
+		Changelog struct {
+			Enabled bool   `json:"enabled"`
+			Path    string `json:"path"`
+			Format  string `json:"format"`
+		} `json:"changelog"`
+		VersionFiles []VersionFileRequest `json:"version_files"`
+		Environments []EnvironmentRequest `json:"environments"`
+		Promotion    struct {
+			MinSoakMinutes           int    `json:"min_soak_minutes"`
+			RequireStagingDeployment bool   `json:"require_staging_deployment"`
+			StagingEnvironment       string `json:"staging_environment"`
+			RequireApproval          bool   `json:"require_approval"`
+		} `json:"promotion"`
 	}{
 		*c.ID,
 		struct {
 			Name                string   `json:"name"`
 			Owner               string   `json:"owner"`
+			Team                *string  `json:"team"`
 			RequiredStatusCheck []string `json:"required_status_check"`
 		}{
 			*c.RepositoryName,
 			*c.RepositoryOwner,
+			c.Team,
 			rsc,
 		},
 		struct {
@@ -140,5 +758,82 @@ func (c *Configuration) Marshall() interface{} {
 		}{
 			*c.WorkflowType,
 		},
+		struct {
+			Maintainers []string `json:"maintainers"`
+		}{
+			c.GetMaintainers(),
+		},
+		struct {
+			DirectPushes bool `json:"direct_pushes"`
+		}{
+			c.NotifyDirectPushes != nil && *c.NotifyDirectPushes,
+		},
+		struct {
+			AutoMerge bool `json:"auto_merge"`
+		}{
+			c.BackMergeAutoMerge != nil && *c.BackMergeAutoMerge,
+		},
+		struct {
+			Enabled bool   `json:"enabled"`
+			Method  string `json:"method"`
+		}{
+			c.AutoMergeEnabled != nil && *c.AutoMergeEnabled,
+			c.GetAutoMergeMethod(),
+		},
+		struct {
+			Enabled  bool     `json:"enabled"`
+			Prefixes []string `json:"prefixes"`
+		}{
+			c.BranchCleanupEnabled != nil && *c.BranchCleanupEnabled,
+			c.GetBranchCleanupPrefixes(),
+		},
+		struct {
+			Template *string `json:"template"`
+		}{
+			c.ReleaseNotesTemplate,
+		},
+		struct {
+			Enabled bool   `json:"enabled"`
+			Path    string `json:"path"`
+			Format  string `json:"format"`
+		}{
+			c.ChangelogEnabled != nil && *c.ChangelogEnabled,
+			c.GetChangelogPath(),
+			c.GetChangelogFormat(),
+		},
+		c.GetVersionFiles(),
+		c.getEnvironmentRequests(),
+		struct {
+			MinSoakMinutes           int    `json:"min_soak_minutes"`
+			RequireStagingDeployment bool   `json:"require_staging_deployment"`
+			StagingEnvironment       string `json:"staging_environment"`
+			RequireApproval          bool   `json:"require_approval"`
+		}{
+			c.GetPromotionMinSoakMinutes(),
+			c.PromotionRequireStaging != nil && *c.PromotionRequireStaging,
+			c.GetPromotionStagingEnvironment(),
+			c.PromotionRequireApproval != nil && *c.PromotionRequireApproval,
+		},
+	}
+}
+
+func (c *Configuration) getEnvironmentRequests() []EnvironmentRequest {
+	environments := make([]EnvironmentRequest, 0)
+	for _, e := range c.GetEnvironments() {
+		environment := EnvironmentRequest{
+			Name:       e.Name,
+			Production: e.Production,
+		}
+		if e.HasApprovalGate() {
+			users, teams := c.GetEnvironmentApprovers(e.Name)
+			environment.Approval = &ApprovalGateRequest{
+				Approvers:         users,
+				Teams:             teams,
+				RequiredApprovals: e.RequiredApprovals,
+				ExpiryMinutes:     e.ApprovalExpiryMinutes,
+			}
+		}
+		environments = append(environments, environment)
 	}
+	return environments
 }
@@ -0,0 +1,67 @@
+package models
+
+import "time"
+
+//CoverageRequestPayload represents the coverage report received in the POST request.
+type CoverageRequestPayload struct {
+	Sha      *string  `json:"sha"`
+	Branch   *string  `json:"branch"`
+	Total    *float64 `json:"total"`
+	Packages []struct {
+		Name     string  `json:"name"`
+		Coverage float64 `json:"coverage"`
+	} `json:"packages"`
+}
+
+//Coverage represents the coverage summary reported for a given sha.
+type Coverage struct {
+	ID             uint32            `json:"id" gorm:"primary_key;AUTO_INCREMENT"`
+	RepositoryName *string           `json:"repository_name" gorm:"index:coverage_repo"`
+	Branch         *string           `json:"branch"`
+	Sha            *string           `json:"sha"`
+	Total          float64           `json:"total"`
+	Packages       []PackageCoverage `json:"packages"`
+
+	//GORM date attributes
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+//PackageCoverage represents the coverage of a single package inside a coverage report.
+type PackageCoverage struct {
+	ID         uint32  `json:"-" gorm:"primary_key;AUTO_INCREMENT"`
+	CoverageID uint32  `json:"-"`
+	Name       string  `json:"name"`
+	Coverage   float64 `json:"coverage"`
+}
+
+//CoveragePoint represents a point in the coverage time series of a repository branch.
+//Build is the build created from the same sha, if any.
+type CoveragePoint struct {
+	Coverage
+	Build *Build `json:"build"`
+}
+
+//NewCoverage converts a CoverageRequestPayload into a Coverage.
+func NewCoverage(repositoryName string, r *CoverageRequestPayload) *Coverage {
+	var c Coverage
+
+	c.RepositoryName = &repositoryName
+	c.Sha = r.Sha
+	c.Branch = r.Branch
+	if r.Total != nil {
+		c.Total = *r.Total
+	}
+
+	packages := make([]PackageCoverage, 0)
+	for _, p := range r.Packages {
+		packages = append(packages, PackageCoverage{
+			Name:     p.Name,
+			Coverage: p.Coverage,
+		})
+	}
+
+	c.Packages = packages
+
+	return &c
+}
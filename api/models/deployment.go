@@ -0,0 +1,73 @@
+package models
+
+import "time"
+
+const (
+	//DeploymentStatusPending is the status of a deployment requested but not created in github yet
+	DeploymentStatusPending = "pending"
+	//DeploymentStatusQueued is the status of a deployment waiting for the deployer
+	DeploymentStatusQueued = "queued"
+	//DeploymentStatusInProgress is the status of a deployment being deployed
+	DeploymentStatusInProgress = "in_progress"
+	//DeploymentStatusSuccess is the status of a deployment running in the environment
+	DeploymentStatusSuccess = "success"
+	//DeploymentStatusFailure is the status of a deployment which failed
+	DeploymentStatusFailure = "failure"
+	//DeploymentStatusError is the status of a deployment which could not be deployed
+	DeploymentStatusError = "error"
+	//DeploymentStatusInactive is the status of a deployment replaced by a newer one
+	DeploymentStatusInactive = "inactive"
+)
+
+//deploymentStatusTransitions are the statuses which every deployment status can move to.
+var deploymentStatusTransitions = map[string][]string{
+	DeploymentStatusPending:    {DeploymentStatusQueued, DeploymentStatusInProgress, DeploymentStatusSuccess, DeploymentStatusFailure, DeploymentStatusError},
+	DeploymentStatusQueued:     {DeploymentStatusInProgress, DeploymentStatusSuccess, DeploymentStatusFailure, DeploymentStatusError},
+	DeploymentStatusInProgress: {DeploymentStatusSuccess, DeploymentStatusFailure, DeploymentStatusError},
+	DeploymentStatusSuccess:    {DeploymentStatusInactive},
+}
+
+//DeploymentPayload represents the payload received in the deployment POST request.
+type DeploymentPayload struct {
+	Environment *string `json:"environment"`
+	Version     *string `json:"version"`
+	Description *string `json:"description"`
+	RequestedBy *string `json:"requested_by"`
+
+	//FreezeOverrideReason allows the deployment to a production environment during a freeze window
+	FreezeOverrideReason *string `json:"freeze_override_reason"`
+}
+
+//Deployment represents the deployment of a build into an environment.
+type Deployment struct {
+	ID                 uint32  `json:"id" gorm:"primary_key;AUTO_INCREMENT"`
+	RepositoryName     *string `json:"repository_name" gorm:"index:deployment_repo_env"`
+	Environment        *string `json:"environment" gorm:"index:deployment_repo_env"`
+	BuildID            uint32  `json:"build_id"`
+	Version            *string `json:"version"`
+	Sha                *string `json:"sha"`
+	Status             *string `json:"status"`
+	Description        *string `json:"description"`
+	RequestedBy        *string `json:"requested_by"`
+	GithubDeploymentID int64   `json:"github_deployment_id" gorm:"index:deployment_github_id"`
+
+	//DeployedAt is when the deployment succeeded
+	DeployedAt *time.Time `json:"deployed_at"`
+
+	//GORM date attributes
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+//CanMoveTo checks if the deployment status can move to the given status.
+func (d *Deployment) CanMoveTo(status string) bool {
+	if d.Status == nil {
+		return true
+	}
+	for _, s := range deploymentStatusTransitions[*d.Status] {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+//DoraQuery represents the scope and the window of time of the DORA metrics.
+//The scope is either a repository or a team, which groups every repository configured with it.
+type DoraQuery struct {
+	Repository string
+	Team       string
+	From       time.Time
+	To         time.Time
+}
+
+//DoraMetrics represents the DORA metrics of a repository or a team over a window of time.
+//The medians and the rate are nil when there are no data points to compute them.
+//The data points are returned so the figures can be audited.
+type DoraMetrics struct {
+	Repository           *string          `json:"repository,omitempty"`
+	Team                 *string          `json:"team,omitempty"`
+	Repositories         []string         `json:"repositories"`
+	From                 time.Time        `json:"from"`
+	To                   time.Time        `json:"to"`
+	LeadTimeMinutes      *float64         `json:"lead_time_minutes"`
+	DeploymentCount      int              `json:"deployment_count"`
+	DeploymentsPerDay    float64          `json:"deployments_per_day"`
+	FailedChangeCount    int              `json:"failed_change_count"`
+	ChangeFailureRate    *float64         `json:"change_failure_rate"`
+	TimeToRestoreMinutes *float64         `json:"time_to_restore_minutes"`
+	LeadTimes            []DoraLeadTime   `json:"lead_times"`
+	Deployments          []DoraDeployment `json:"deployments"`
+}
+
+//DoraLeadTime is the lead time data point of a merged pull request.
+//The lead time goes from the pull request creation until its changes reach production,
+//so it is nil while they are not in production yet.
+type DoraLeadTime struct {
+	RepositoryName    string     `json:"repository_name"`
+	PullRequestNumber int        `json:"pull_request_number"`
+	Title             *string    `json:"title"`
+	CreatedAt         time.Time  `json:"created_at"`
+	MergedAt          time.Time  `json:"merged_at"`
+	DeployedAt        *time.Time `json:"deployed_at"`
+	Version           *string    `json:"version"`
+	LeadTimeMinutes   *float64   `json:"lead_time_minutes"`
+}
+
+//DoraDeployment is a change which reached production, either a production deployment
+//or a productive release for the repositories which do not deploy through this API.
+//A change fails when its deployment fails or its build is rolled back,
+//and it is restored by the rollback or the next successful change.
+type DoraDeployment struct {
+	RepositoryName       string     `json:"repository_name"`
+	DeploymentID         *uint32    `json:"deployment_id"`
+	BuildID              uint32     `json:"build_id"`
+	Version              string     `json:"version"`
+	Status               string     `json:"status"`
+	DeployedAt           time.Time  `json:"deployed_at"`
+	Failed               bool       `json:"failed"`
+	RestoredAt           *time.Time `json:"restored_at"`
+	TimeToRestoreMinutes *float64   `json:"time_to_restore_minutes"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+//FreezeNotice represents the release freeze reported to a pull request.
+//It keeps the freeze reported once per freeze window, and the sha whose status must be reset when the freeze is over.
+type FreezeNotice struct {
+	PullRequestID     int64      `json:"pull_request_id" gorm:"primary_key;auto_increment:false"`
+	RepositoryName    *string    `json:"repository_name"`
+	PullRequestNumber int        `json:"pull_request_number"`
+	Sha               *string    `json:"sha"`
+	FreezeWindowID    uint32     `json:"freeze_window_id"`
+	Until             *time.Time `json:"until" gorm:"index:freeze_notice_until"`
+
+	//GORM date attributes
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+//IsReported checks if the notice reported the given occurrence of a freeze window.
+func (n *FreezeNotice) IsReported(window *FreezeWindow, until *time.Time) bool {
+	return n.FreezeWindowID == window.ID && n.Until != nil && until != nil && n.Until.Equal(*until)
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+//FreezeWindowDateLayout is the layout of the freeze window dates received in the requests.
+const FreezeWindowDateLayout = "2006-01-02 15:04"
+
+//FreezeWindowPayload represents the payload received in the freeze window POST request.
+//A freeze window is either a date range or a cron expression with a duration.
+//The dates and the cron expression are interpreted in the timezone, UTC by default.
+type FreezeWindowPayload struct {
+	Name            *string `json:"name"`
+	StartsAt        *string `json:"starts_at"`
+	EndsAt          *string `json:"ends_at"`
+	Cron            *string `json:"cron"`
+	DurationMinutes *int    `json:"duration_minutes"`
+	Timezone        *string `json:"timezone"`
+}
+
+//FreezeWindow represents a period of time when productive releases and production deployments are blocked.
+//Global freeze windows have no repository name and apply to every repository.
+type FreezeWindow struct {
+	ID              uint32     `json:"id" gorm:"primary_key;AUTO_INCREMENT"`
+	RepositoryName  *string    `json:"repository_name" gorm:"index:freeze_window_repo"`
+	Name            *string    `json:"name"`
+	StartsAt        *time.Time `json:"starts_at"`
+	EndsAt          *time.Time `json:"ends_at"`
+	Cron            *string    `json:"cron"`
+	DurationMinutes int        `json:"duration_minutes"`
+	Timezone        *string    `json:"timezone"`
+
+	//GORM date attributes
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
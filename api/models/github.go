@@ -38,7 +38,140 @@ type BranchProtectionResponse struct {
 type GetBranchResponse struct {
 	Name   string `json:"name"`
 	Commit struct {
-		Sha string `json:"sha"`
+		Sha    string `json:"sha"`
+		Commit struct {
+			Tree struct {
+				Sha string `json:"sha"`
+			} `json:"tree"`
+		} `json:"commit"`
 	} `json:"commit"`
 	Protected bool `json:"protected"`
 }
+
+type CollaboratorPermissionResponse struct {
+	Permission string `json:"permission"`
+	User       struct {
+		Login string `json:"login"`
+	} `json:"user"`
+}
+
+type CommitPullRequestResponse struct {
+	Number         int     `json:"number"`
+	Title          string  `json:"title"`
+	State          string  `json:"state"`
+	MergedAt       *string `json:"merged_at"`
+	UpdatedAt      *string `json:"updated_at"`
+	MergeCommitSha *string `json:"merge_commit_sha"`
+	User           struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	Labels []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+	Head struct {
+		Ref string `json:"ref"`
+	} `json:"head"`
+}
+
+type PullRequestResponse struct {
+	Number    int    `json:"number"`
+	HTMLURL   string `json:"html_url"`
+	State     string `json:"state"`
+	Mergeable *bool  `json:"mergeable"`
+	Merged    bool   `json:"merged"`
+}
+
+type MergeResponse struct {
+	Sha string `json:"sha"`
+}
+
+type CompareResponse struct {
+	Commits []struct {
+		Sha    string `json:"sha"`
+		Commit struct {
+			Message string `json:"message"`
+		} `json:"commit"`
+	} `json:"commits"`
+	Files []struct {
+		Filename string `json:"filename"`
+	} `json:"files"`
+}
+
+type FileContentResponse struct {
+	Path     string `json:"path"`
+	Sha      string `json:"sha"`
+	Encoding string `json:"encoding"`
+	Content  string `json:"content"`
+}
+
+//Git tree entries of the regular files
+const (
+	TreeEntryModeFile = "100644"
+	TreeEntryTypeBlob = "blob"
+)
+
+//TreeEntry is a file written into a git tree, with its whole content
+type TreeEntry struct {
+	Path    string `json:"path"`
+	Mode    string `json:"mode"`
+	Type    string `json:"type"`
+	Content string `json:"content"`
+}
+
+//TreeRequest creates a git tree with the entries on top of the base tree
+type TreeRequest struct {
+	BaseTree string      `json:"base_tree"`
+	Tree     []TreeEntry `json:"tree"`
+}
+
+//CommitRequest creates a git commit of the tree, without moving any branch
+type CommitRequest struct {
+	Message   string   `json:"message"`
+	Tree      string   `json:"tree"`
+	Parents   []string `json:"parents"`
+	Committer struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	} `json:"committer"`
+}
+
+//GitObjectResponse is the git object created through the git data api
+type GitObjectResponse struct {
+	Sha string `json:"sha"`
+}
+
+type DeploymentRequest struct {
+	Ref                   string   `json:"ref"`
+	Environment           string   `json:"environment"`
+	Description           string   `json:"description"`
+	AutoMerge             bool     `json:"auto_merge"`
+	RequiredContexts      []string `json:"required_contexts"`
+	ProductionEnvironment bool     `json:"production_environment"`
+}
+
+type DeploymentResponse struct {
+	ID          int64  `json:"id"`
+	Sha         string `json:"sha"`
+	Environment string `json:"environment"`
+}
+
+type TeamMembershipResponse struct {
+	Role  string `json:"role"`
+	State string `json:"state"`
+}
+
+type ReleaseResponse struct {
+	ID              int64  `json:"id"`
+	TagName         string `json:"tag_name"`
+	TargetCommitish string `json:"target_commitish"`
+	Draft           bool   `json:"draft"`
+	Prerelease      bool   `json:"prerelease"`
+}
+
+type ReleaseUpdateRequest struct {
+	Draft      *bool `json:"draft,omitempty"`
+	Prerelease *bool `json:"prerelease,omitempty"`
+}
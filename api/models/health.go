@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+//Health statuses
+const (
+	HealthStatusUp   = "up"
+	HealthStatusDown = "down"
+)
+
+//Health represents the health of the API and of the dependencies checked to report it
+type Health struct {
+	Status string                 `json:"status"`
+	Checks map[string]HealthCheck `json:"checks,omitempty"`
+}
+
+//HealthCheck represents the result of checking a dependency
+type HealthCheck struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+//SchemaMigration keeps the version of the database schema.
+//There is a single row, which is updated by every migration.
+type SchemaMigration struct {
+	ID         uint8     `json:"id" gorm:"primary_key"`
+	Version    int       `json:"version"`
+	MigratedAt time.Time `json:"migrated_at"`
+}
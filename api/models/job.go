@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+const (
+	//JobStatusRunning is the status of a job being processed, or interrupted before finishing
+	JobStatusRunning = "running"
+	//JobStatusFailed is the status of a job interrupted too many times, it is not resumed anymore
+	JobStatusFailed = "failed"
+)
+
+const (
+	//JobTypeStatusWebhook processes a status webhook: builds, auto merges and merge queues
+	JobTypeStatusWebhook = "status_webhook"
+	//JobTypeReviewWebhook processes an approved or dismissed pull request review: builds and auto merges
+	JobTypeReviewWebhook = "pull_request_review_webhook"
+)
+
+//Job represents a unit of work persisted while it is processed, so it can be resumed when it is interrupted.
+//Finished jobs are deleted.
+type Job struct {
+	ID       uint32  `json:"id" gorm:"primary_key;AUTO_INCREMENT"`
+	Type     *string `json:"type"`
+	Status   *string `json:"status" gorm:"index:job_status"`
+	Payload  *string `json:"payload" gorm:"type:text"`
+	Attempts int     `json:"attempts"`
+
+	//GORM date attributes
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
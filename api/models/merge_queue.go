@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+//Merge queue entry states
+const (
+	MergeQueueQueued   = "queued"
+	MergeQueueTesting  = "testing"
+	MergeQueueMerged   = "merged"
+	MergeQueueEjected  = "ejected"
+	MergeQueueDequeued = "dequeued"
+)
+
+//MergeQueueEntry represents a pull request waiting to be merged into a protected branch.
+//The head of the queue is tested on a temporary integration branch with the base branch merged.
+//The pull request is merged only while its head is still the tested one.
+type MergeQueueEntry struct {
+	ID                uint32  `json:"id" gorm:"primary_key;auto_increment"`
+	RepositoryName    *string `json:"repository_name" gorm:"index:merge_queue_repo"`
+	BaseBranch        *string `json:"base_branch"`
+	PullRequestID     int64   `json:"pull_request_id"`
+	PullRequestNumber int     `json:"pull_request_number"`
+	Status            *string `json:"status"`
+	IntegrationBranch *string `json:"integration_branch"`
+	IntegrationSha    *string `json:"integration_sha"`
+	HeadSha           *string `json:"head_sha"`
+	QueuedBy          *string `json:"queued_by"`
+	Reason            *string `json:"reason"`
+
+	//GORM date attributes
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
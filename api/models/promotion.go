@@ -0,0 +1,11 @@
+package models
+
+//PromotionPayload represents the payload received in the build promotion POST request.
+//The requester is the github user the api token is bound to, the requester of the payload is only checked against it.
+//The promotion is approved through the approvals of the production environment.
+type PromotionPayload struct {
+	RequestedBy *string `json:"requested_by"`
+
+	//FreezeOverrideReason allows the promotion during a freeze window
+	FreezeOverrideReason *string `json:"freeze_override_reason"`
+}
@@ -3,7 +3,7 @@ package models
 import "time"
 
 type PullRequest struct {
-	ID                int64     `gorm:"primary_key"`
+	ID                int64 `gorm:"primary_key"`
 	PullRequestNumber int
 	State             *string
 	RepositoryName    *string
@@ -13,7 +13,9 @@ type PullRequest struct {
 	HeadSha           *string
 	CreatedAt         time.Time
 	UpdatedAt         time.Time
+	MergedAt          *time.Time
 	Body              *string
 	Title             *string
 	CreatedBy         *string
+	AutoMerge         bool
 }
@@ -0,0 +1,36 @@
+package models
+
+//ReleaseNotes represents the data available to the release notes template.
+type ReleaseNotes struct {
+	Version    string
+	Date       string
+	Repository string
+	Categories []ReleaseNotesCategory
+}
+
+//ReleaseNotesCategory groups the pull requests of a release by the kind of change.
+type ReleaseNotesCategory struct {
+	Title        string
+	PullRequests []ReleaseNotesPullRequest
+}
+
+//ReleaseNotesPullRequest represents a pull request included in a release.
+type ReleaseNotesPullRequest struct {
+	Number int
+	Title  string
+	Author string
+	Branch string
+	Labels []string
+	URL    string
+}
+
+//GetReleasedPullRequests returns the pull requests listed in the release notes, to be saved with the build.
+func (n *ReleaseNotes) GetReleasedPullRequests() []ReleasedPullRequest {
+	released := make([]ReleasedPullRequest, 0)
+	for _, category := range n.Categories {
+		for _, pr := range category.PullRequests {
+			released = append(released, ReleasedPullRequest{PullRequestNumber: pr.Number})
+		}
+	}
+	return released
+}
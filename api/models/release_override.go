@@ -0,0 +1,12 @@
+package models
+
+//ReleaseOverride represents the release decisions taken over a pull request through ChatOps commands.
+//Bump forces the semver field to increment and SkipRelease avoids the build creation.
+//FreezeOverrideReason allows the release during a freeze window.
+type ReleaseOverride struct {
+	PullRequestID        int64   `json:"pull_request_id" gorm:"primary_key;auto_increment:false"`
+	Bump                 *string `json:"bump"`
+	SkipRelease          bool    `json:"skip_release"`
+	FreezeOverrideReason *string `json:"freeze_override_reason"`
+	UpdatedBy            *string `json:"updated_by"`
+}
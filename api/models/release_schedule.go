@@ -0,0 +1,79 @@
+package models
+
+import "time"
+
+const (
+	defaultReleaseSourceBranch = "develop"
+	defaultReleaseTimezone     = "UTC"
+)
+
+//ReleaseSchedulePayload represents the payload received in the release schedule POST and PUT requests.
+//The cron expression is interpreted in the timezone, UTC by default.
+type ReleaseSchedulePayload struct {
+	Cron            *string `json:"cron"`
+	Timezone        *string `json:"timezone"`
+	SourceBranch    *string `json:"source_branch"`
+	OpenPullRequest *bool   `json:"open_pull_request"`
+	Announce        *bool   `json:"announce"`
+	Enabled         *bool   `json:"enabled"`
+}
+
+//ReleaseSchedule represents a release train.
+//Every time the cron expression is due, a release branch is cut from the source branch.
+type ReleaseSchedule struct {
+	ID              uint32     `json:"id" gorm:"primary_key;AUTO_INCREMENT"`
+	RepositoryName  *string    `json:"repository_name" gorm:"index:release_schedule_repo"`
+	Cron            *string    `json:"cron"`
+	Timezone        *string    `json:"timezone"`
+	SourceBranch    *string    `json:"source_branch"`
+	OpenPullRequest bool       `json:"open_pull_request"`
+	Announce        bool       `json:"announce"`
+	Enabled         bool       `json:"enabled"`
+	LastRunAt       *time.Time `json:"last_run_at"`
+	NextRunAt       *time.Time `json:"next_run_at"`
+
+	//GORM date attributes
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+//NewReleaseSchedule converts a ReleaseSchedulePayload into a ReleaseSchedule.
+func NewReleaseSchedule(repositoryName string, r *ReleaseSchedulePayload) *ReleaseSchedule {
+	sourceBranch := defaultReleaseSourceBranch
+	timezone := defaultReleaseTimezone
+	s := ReleaseSchedule{
+		RepositoryName: &repositoryName,
+		Timezone:       &timezone,
+		SourceBranch:   &sourceBranch,
+		Enabled:        true,
+	}
+	s.UpdateReleaseSchedule(r)
+	return &s
+}
+
+//UpdateReleaseSchedule updates a ReleaseSchedule based on a ReleaseSchedulePayload.
+func (s *ReleaseSchedule) UpdateReleaseSchedule(r *ReleaseSchedulePayload) {
+	if r.Cron != nil {
+		s.Cron = r.Cron
+	}
+
+	if r.Timezone != nil && *r.Timezone != "" {
+		s.Timezone = r.Timezone
+	}
+
+	if r.SourceBranch != nil {
+		s.SourceBranch = r.SourceBranch
+	}
+
+	if r.OpenPullRequest != nil {
+		s.OpenPullRequest = *r.OpenPullRequest
+	}
+
+	if r.Announce != nil {
+		s.Announce = *r.Announce
+	}
+
+	if r.Enabled != nil {
+		s.Enabled = *r.Enabled
+	}
+}
@@ -0,0 +1,27 @@
+package models
+
+const (
+	//RollbackReleaseActionPrerelease turns the github release of the rolled back build into a prerelease
+	RollbackReleaseActionPrerelease = "prerelease"
+	//RollbackReleaseActionDraft turns the github release of the rolled back build into a draft
+	RollbackReleaseActionDraft = "draft"
+)
+
+//RollbackPayload represents the payload received in the build rollback POST request.
+//The release action marks the github release as a prerelease or a draft, it is left as is when empty.
+//Redeploy deploys the previous build into the environments where the rolled back build is running.
+//The requester is optional, the rollback is attributed to the github user of the api token.
+type RollbackPayload struct {
+	RequestedBy   *string `json:"requested_by"`
+	Reason        *string `json:"reason"`
+	ReleaseAction *string `json:"release_action"`
+	Redeploy      *bool   `json:"redeploy"`
+}
+
+//Rollback represents the result of a build rollback.
+//The current build is the one the latest build points to after the rollback.
+type Rollback struct {
+	RolledBackBuild *Build       `json:"rolled_back_build"`
+	CurrentBuild    *Build       `json:"current_build"`
+	Redeployments   []Deployment `json:"redeployments"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+//ServiceCommit represents a commit this API pushed to a repository branch, like a release commit or a cut release branch.
+//It is recorded before pushing it, so its push webhook is not reported as a direct push.
+type ServiceCommit struct {
+	ID             uint32  `json:"id" gorm:"primary_key;AUTO_INCREMENT"`
+	RepositoryName *string `json:"repository_name" gorm:"index:service_commit_sha"`
+	Branch         *string `json:"branch"`
+	Sha            *string `json:"sha" gorm:"index:service_commit_sha"`
+
+	//GORM date attributes
+	CreatedAt time.Time `json:"created_at"`
+}
+
+//NewServiceCommit returns the record of a commit pushed by this API to the branch.
+func NewServiceCommit(repositoryName string, branch string, sha string) *ServiceCommit {
+	return &ServiceCommit{
+		RepositoryName: &repositoryName,
+		Branch:         &branch,
+		Sha:            &sha,
+	}
+}
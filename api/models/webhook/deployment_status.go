@@ -0,0 +1,24 @@
+package webhook
+
+//DeploymentStatus represents a deployment_status Github Webhook
+type DeploymentStatus struct {
+	Action           *string `json:"action"`
+	DeploymentStatus struct {
+		ID          int64   `json:"id"`
+		State       *string `json:"state"`
+		Description *string `json:"description"`
+		Environment *string `json:"environment"`
+	} `json:"deployment_status"`
+	Deployment struct {
+		ID          int64   `json:"id"`
+		Sha         *string `json:"sha"`
+		Environment *string `json:"environment"`
+	} `json:"deployment"`
+	Repository struct {
+		Name     *string `json:"name"`
+		FullName *string `json:"full_name"`
+	} `json:"repository"`
+	Sender struct {
+		Login *string `json:"login"`
+	} `json:"sender"`
+}
@@ -4,6 +4,26 @@ package webhook
 type IssueComment struct {
 	Action  *string `json:"action"`
 	Comment *struct {
+		ID   int64   `json:"id"`
 		Body *string `json:"body"`
+		User struct {
+			Login *string `json:"login"`
+		} `json:"user"`
+		AuthorAssociation *string `json:"author_association"`
 	} `json:"comment"`
+	Issue struct {
+		Number int `json:"number"`
+		//PullRequest is only present when the comment was made on a pull request
+		PullRequest *struct {
+			URL *string `json:"url"`
+		} `json:"pull_request"`
+	} `json:"issue"`
+	Repository struct {
+		ID       int     `json:"id"`
+		Name     *string `json:"name"`
+		FullName *string `json:"full_name"`
+	} `json:"repository"`
+	Sender struct {
+		Login *string `json:"login"`
+	} `json:"sender"`
 }
@@ -19,13 +19,16 @@ type PullRequestWebhook struct {
 		CreatedAt          time.Time     `json:"created_at"`
 		UpdatedAt          time.Time     `json:"updated_at"`
 		ClosedAt           interface{}   `json:"closed_at"`
-		MergedAt           interface{}   `json:"merged_at"`
+		MergedAt           *time.Time    `json:"merged_at"`
+		Merged             bool          `json:"merged"`
 		MergeCommitSha     interface{}   `json:"merge_commit_sha"`
 		Assignee           interface{}   `json:"assignee"`
 		Assignees          []interface{} `json:"assignees"`
 		RequestedReviewers []interface{} `json:"requested_reviewers"`
 		RequestedTeams     []interface{} `json:"requested_teams"`
-		Labels             []interface{} `json:"labels"`
+		Labels             []struct {
+			Name *string `json:"name"`
+		} `json:"labels"`
 		Milestone          interface{}   `json:"milestone"`
 		Head               struct {
 			Label *string `json:"label"`
@@ -108,4 +111,7 @@ type PullRequestWebhook struct {
 		Login *string `json:"login"`
 		ID    int     `json:"id"`
 	} `json:"sender"`
+	Label struct {
+		Name *string `json:"name"`
+	} `json:"label"`
 }
@@ -0,0 +1,31 @@
+package webhook
+
+//Push represents a push Github Webhook
+type Push struct {
+	Ref     *string `json:"ref"`
+	Before  *string `json:"before"`
+	After   *string `json:"after"`
+	Created bool    `json:"created"`
+	Deleted bool    `json:"deleted"`
+	Forced  bool    `json:"forced"`
+	Commits []struct {
+		ID      *string `json:"id"`
+		Message *string `json:"message"`
+	} `json:"commits"`
+	HeadCommit *struct {
+		ID        *string `json:"id"`
+		Message   *string `json:"message"`
+		Timestamp *string `json:"timestamp"`
+	} `json:"head_commit"`
+	Pusher struct {
+		Name *string `json:"name"`
+	} `json:"pusher"`
+	Repository struct {
+		ID       int     `json:"id"`
+		Name     *string `json:"name"`
+		FullName *string `json:"full_name"`
+	} `json:"repository"`
+	Sender struct {
+		Login *string `json:"login"`
+	} `json:"sender"`
+}
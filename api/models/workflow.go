@@ -1,5 +1,7 @@
 package models
 
+import "strings"
+
 type WorkflowConfig struct {
 	Name          *string `json:"name"`
 	Description   Description
@@ -7,6 +9,31 @@ type WorkflowConfig struct {
 	DefaultBranch *string `json:"default_branch"`
 }
 
+//GetStableBranch returns the stable branch configuration that matches the branch name.
+//Returns nil when the branch is not a stable branch of the workflow.
+func (w *WorkflowConfig) GetStableBranch(name string) *Branch {
+	for _, branch := range w.Description.Branches {
+		if !branch.Stable || branch.Name == nil {
+			continue
+		}
+		if *branch.Name == name || (branch.StartWith && strings.HasPrefix(name, *branch.Name)) {
+			stableBranch := branch
+			return &stableBranch
+		}
+	}
+	return nil
+}
+
+//GetProtectedBranch returns the stable branch configuration named exactly like the branch.
+//The branches matched by prefix, like the release branches, are created and pushed by the workflow itself
+//and are not protected, so they are not returned.
+func (w *WorkflowConfig) GetProtectedBranch(name string) *Branch {
+	if branch := w.GetStableBranch(name); branch != nil && !branch.StartWith {
+		return branch
+	}
+	return nil
+}
+
 type Description struct {
 	Branches []Branch
 }
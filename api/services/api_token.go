@@ -0,0 +1,251 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/services/storage"
+	"github.com/hbalmes/ci_cd-api/api/utils"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"github.com/hbalmes/ci_cd-api/api/utils/tracing"
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	//apiTokenPrefix makes the api tokens easy to recognize, by the secret scanners too
+	apiTokenPrefix = "cicd_"
+	//apiTokenBytes is the amount of random bytes of an api token
+	apiTokenBytes = 32
+	//apiTokenShownLength is how many characters of the token are kept to recognize it in the listings
+	apiTokenShownLength = len(apiTokenPrefix) + 8
+)
+
+//githubLoginRegexp matches the valid github logins, the subjects of the api tokens
+var githubLoginRegexp = regexp.MustCompile(`^[A-Za-z0-9](?:[A-Za-z0-9-]{0,38})$`)
+
+//apiTokenContextKey is the key of the api token of the request in its context
+type apiTokenContextKey struct{}
+
+//APITokenService is an interface which represents the APITokenService for testing purpose.
+type APITokenService interface {
+	Create(ctx context.Context, r *models.APITokenPayload) (*models.APIToken, apierrors.ApiError)
+	List(ctx context.Context) ([]models.APIToken, apierrors.ApiError)
+	Revoke(ctx context.Context, id uint32, now time.Time) apierrors.ApiError
+	Authenticate(ctx context.Context, token string) (*models.APIToken, apierrors.ApiError)
+}
+
+//APIToken represents the APITokenService layer
+//It has an instance of a DBClient layer and
+//the bootstrap token, an admin token which is not stored
+type APIToken struct {
+	SQL            storage.SQLStorage
+	BootstrapToken string
+}
+
+//NewAPITokenService initializes an APITokenService
+func NewAPITokenService(sql storage.SQLStorage, bootstrapToken string) *APIToken {
+	return &APIToken{
+		SQL:            sql,
+		BootstrapToken: bootstrapToken,
+	}
+}
+
+//Create mints a new api token.
+//Only its hash is saved, the token itself is returned this time only.
+func (s *APIToken) Create(ctx context.Context, r *models.APITokenPayload) (*models.APIToken, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "APIToken.Create")
+	defer span.End()
+
+	if err := validateAPITokenPayload(r); err != nil {
+		return nil, err
+	}
+
+	secret := make([]byte, apiTokenBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, apierrors.NewInternalServerApiError("error generating api token", err)
+	}
+	token := apiTokenPrefix + hex.EncodeToString(secret)
+
+	apiToken := models.APIToken{
+		Name:         r.Name,
+		Subject:      r.Subject,
+		Hash:         utils.Stringify(hashAPIToken(token)),
+		Prefix:       utils.Stringify(token[:apiTokenShownLength]),
+		Scopes:       utils.Stringify(strings.Join(r.Scopes, ",")),
+		Repositories: utils.Stringify(strings.Join(r.Repositories, ",")),
+	}
+
+	//Save it into database
+	if err := s.SQL.Insert(ctx, &apiToken); err != nil {
+		return nil, apierrors.NewInternalServerApiError("error saving new api token", err)
+	}
+
+	apiToken.Token = token
+
+	return &apiToken, nil
+}
+
+//List returns every api token, the revoked ones included
+func (s *APIToken) List(ctx context.Context) ([]models.APIToken, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "APIToken.List")
+	defer span.End()
+
+	tokens := make([]models.APIToken, 0)
+
+	if err := s.SQL.GetBy(ctx, &tokens); err != nil {
+		return nil, apierrors.NewInternalServerApiError("error getting api tokens", err)
+	}
+
+	return tokens, nil
+}
+
+//Revoke revokes an api token, which can not be used anymore.
+//The token is kept so the listings show when it was revoked.
+func (s *APIToken) Revoke(ctx context.Context, id uint32, now time.Time) apierrors.ApiError {
+	ctx, span := tracing.Start(ctx, "APIToken.Revoke")
+	defer span.End()
+
+	var token models.APIToken
+
+	if err := s.SQL.GetBy(ctx, &token, "id = ?", id); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return apierrors.NewNotFoundApiError(fmt.Sprintf("api token %d not found", id))
+		}
+		return apierrors.NewInternalServerApiError("error getting api token", err)
+	}
+
+	if token.RevokedAt != nil {
+		return nil
+	}
+
+	token.RevokedAt = &now
+
+	if err := s.SQL.Update(ctx, &token); err != nil {
+		return apierrors.NewInternalServerApiError("error revoking api token", err)
+	}
+
+	return nil
+}
+
+//Authenticate returns the api token matching the given one.
+//Returns an unauthorized error when the token is unknown or revoked.
+func (s *APIToken) Authenticate(ctx context.Context, token string) (*models.APIToken, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "APIToken.Authenticate")
+	defer span.End()
+
+	if token == "" {
+		return nil, apierrors.NewUnauthorizedApiError("an api token is required")
+	}
+
+	if s.BootstrapToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.BootstrapToken)) == 1 {
+		return &models.APIToken{
+			Name:   utils.Stringify("bootstrap"),
+			Scopes: utils.Stringify(models.APITokenScopeAdmin),
+		}, nil
+	}
+
+	var apiToken models.APIToken
+
+	if err := s.SQL.GetBy(ctx, &apiToken, "hash = ?", hashAPIToken(token)); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierrors.NewUnauthorizedApiError("invalid api token")
+		}
+		return nil, apierrors.NewInternalServerApiError("error getting api token", err)
+	}
+
+	if apiToken.RevokedAt != nil {
+		return nil, apierrors.NewUnauthorizedApiError("api token revoked")
+	}
+
+	return &apiToken, nil
+}
+
+//validateAPITokenPayload checks the name, the scopes and the repositories of a new api token
+func validateAPITokenPayload(r *models.APITokenPayload) apierrors.ApiError {
+	if r.Name == nil || *r.Name == "" {
+		return apierrors.NewBadRequestApiError("the api token name is required")
+	}
+
+	if r.Subject != nil && !githubLoginRegexp.MatchString(*r.Subject) {
+		return apierrors.NewBadRequestApiError(fmt.Sprintf("invalid api token subject %s, it must be a github login", *r.Subject))
+	}
+
+	if len(r.Scopes) == 0 {
+		return apierrors.NewBadRequestApiError(fmt.Sprintf("the api token needs at least one of the scopes %s", strings.Join(models.APITokenScopes, ", ")))
+	}
+
+	for _, scope := range r.Scopes {
+		if !utils.StringContains(models.APITokenScopes, scope) {
+			return apierrors.NewBadRequestApiError(fmt.Sprintf("invalid api token scope %s", scope))
+		}
+	}
+
+	for _, repository := range r.Repositories {
+		parts := strings.Split(repository, "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" || strings.Contains(parts[0], "*") {
+			return apierrors.NewBadRequestApiError(fmt.Sprintf("invalid api token repository %s, it must be owner/name or owner/*", repository))
+		}
+	}
+
+	return nil
+}
+
+//WithAPIToken returns a copy of the context carrying the api token which authenticated the request
+func WithAPIToken(ctx context.Context, token *models.APIToken) context.Context {
+	return context.WithValue(ctx, apiTokenContextKey{}, token)
+}
+
+//APITokenFromContext returns the api token which authenticated the request, nil when there is none
+func APITokenFromContext(ctx context.Context) *models.APIToken {
+	token, _ := ctx.Value(apiTokenContextKey{}).(*models.APIToken)
+	return token
+}
+
+//AuthenticatedUser returns the github user the api token of the request is bound to.
+//Returns a forbidden error when the request was not authenticated by a token bound to a user,
+//so the actions which must be attributed to someone can not be done with the tokens of the pipelines.
+func AuthenticatedUser(ctx context.Context) (string, apierrors.ApiError) {
+	token := APITokenFromContext(ctx)
+
+	if token == nil || token.GetSubject() == "" {
+		return "", apierrors.NewForbiddenApiError("the api token must be bound to a github user")
+	}
+
+	return token.GetSubject(), nil
+}
+
+//Requester returns who the request is attributed to, the github user the api token is bound to
+//or the name of the token for the tokens of the pipelines and the bots.
+//Returns a forbidden error when the request was not authenticated by an api token.
+func Requester(ctx context.Context) (string, apierrors.ApiError) {
+	token := APITokenFromContext(ctx)
+
+	if token == nil {
+		return "", apierrors.NewForbiddenApiError("the request must be authenticated by an api token")
+	}
+
+	if token.GetSubject() != "" {
+		return token.GetSubject(), nil
+	}
+
+	if token.Name == nil || *token.Name == "" {
+		return "", apierrors.NewForbiddenApiError("the api token must have a name or be bound to a github user")
+	}
+
+	return *token.Name, nil
+}
+
+//hashAPIToken returns the hash stored for an api token.
+//The tokens are random, so a fast hash is enough and it allows to look them up.
+func hashAPIToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,396 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hbalmes/ci_cd-api/api/mocks/interfaces"
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/utils"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+const testBootstrapToken = "bootstrap-token-of-at-least-32-characters"
+
+func TestAPIToken_Create(t *testing.T) {
+	tests := []struct {
+		name           string
+		payload        models.APITokenPayload
+		insertTimes    int
+		sqlInsertError error
+		err            apierrors.ApiError
+	}{
+		{
+			name:    "name missing",
+			payload: models.APITokenPayload{Scopes: []string{models.APITokenScopeRead}},
+			err:     apierrors.NewBadRequestApiError("the api token name is required"),
+		},
+		{
+			name:    "invalid subject",
+			payload: models.APITokenPayload{Name: utils.Stringify("alice"), Subject: utils.Stringify("alice smith"), Scopes: []string{models.APITokenScopeRead}},
+			err:     apierrors.NewBadRequestApiError("invalid api token subject alice smith, it must be a github login"),
+		},
+		{
+			name:    "scopes missing",
+			payload: models.APITokenPayload{Name: utils.Stringify("ci")},
+			err:     apierrors.NewBadRequestApiError("the api token needs at least one of the scopes read, configure, release, admin"),
+		},
+		{
+			name:    "invalid scope",
+			payload: models.APITokenPayload{Name: utils.Stringify("ci"), Scopes: []string{"write"}},
+			err:     apierrors.NewBadRequestApiError("invalid api token scope write"),
+		},
+		{
+			name:    "invalid repository",
+			payload: models.APITokenPayload{Name: utils.Stringify("ci"), Scopes: []string{models.APITokenScopeRead}, Repositories: []string{"*/ci_cd-api"}},
+			err:     apierrors.NewBadRequestApiError("invalid api token repository */ci_cd-api, it must be owner/name or owner/*"),
+		},
+		{
+			name:           "error saving api token",
+			payload:        models.APITokenPayload{Name: utils.Stringify("ci"), Scopes: []string{models.APITokenScopeRead}},
+			insertTimes:    1,
+			sqlInsertError: gorm.ErrInvalidSQL,
+			err:            apierrors.NewInternalServerApiError("error saving new api token", gorm.ErrInvalidSQL),
+		},
+		{
+			name:        "api token minted",
+			payload:     models.APITokenPayload{Name: utils.Stringify("ci"), Scopes: []string{models.APITokenScopeRelease, models.APITokenScopeRead}, Repositories: []string{"hbalmes/ci_cd-api", "hbalmes/*"}},
+			insertTimes: 1,
+		},
+		{
+			name:        "api token minted for a user",
+			payload:     models.APITokenPayload{Name: utils.Stringify("ci"), Subject: utils.Stringify("hbalmes"), Scopes: []string{models.APITokenScopeRelease}},
+			insertTimes: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+
+			var saved *models.APIToken
+			sqlStorage.EXPECT().
+				Insert(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, token *models.APIToken) error {
+					saved = token
+					return tt.sqlInsertError
+				}).
+				Times(tt.insertTimes)
+
+			s := &APIToken{
+				SQL: sqlStorage,
+			}
+
+			token, err := s.Create(context.Background(), &tt.payload)
+			if tt.err != nil {
+				assert.Nil(t, token)
+				assert.Equal(t, tt.err, err)
+				return
+			}
+			assert.Nil(t, err)
+
+			//Only the hash of the token is saved
+			assert.True(t, strings.HasPrefix(token.Token, apiTokenPrefix))
+			assert.Len(t, token.Token, len(apiTokenPrefix)+2*apiTokenBytes)
+			assert.Equal(t, hashAPIToken(token.Token), *saved.Hash)
+			assert.Equal(t, token.Token[:apiTokenShownLength], *saved.Prefix)
+			assert.Equal(t, "ci", *saved.Name)
+			assert.Equal(t, tt.payload.Subject, saved.Subject)
+			assert.Equal(t, tt.payload.Scopes, saved.GetScopes())
+			assert.ElementsMatch(t, tt.payload.Repositories, saved.GetRepositories())
+		})
+	}
+}
+
+func TestAPIToken_List(t *testing.T) {
+	tests := []struct {
+		name        string
+		sqlGetError error
+		want        []models.APIToken
+		err         apierrors.ApiError
+	}{
+		{
+			name:        "error getting api tokens",
+			sqlGetError: gorm.ErrInvalidSQL,
+			err:         apierrors.NewInternalServerApiError("error getting api tokens", gorm.ErrInvalidSQL),
+		},
+		{
+			name: "api tokens listed",
+			want: []models.APIToken{{ID: 1, Name: utils.Stringify("ci")}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+
+			sqlStorage.EXPECT().
+				GetBy(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, tokens *[]models.APIToken, qry ...interface{}) error {
+					*tokens = append(*tokens, tt.want...)
+					return tt.sqlGetError
+				}).
+				Times(1)
+
+			s := &APIToken{
+				SQL: sqlStorage,
+			}
+
+			tokens, err := s.List(context.Background())
+			if tt.err != nil {
+				assert.Equal(t, tt.err, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, tokens)
+		})
+	}
+}
+
+func TestAPIToken_Revoke(t *testing.T) {
+	now := time.Now()
+	revokedAt := now.Add(-time.Hour)
+
+	tests := []struct {
+		name           string
+		sqlGetError    error
+		revokedAt      *time.Time
+		updateTimes    int
+		sqlUpdateError error
+		err            apierrors.ApiError
+	}{
+		{
+			name:        "api token not found",
+			sqlGetError: gorm.ErrRecordNotFound,
+			err:         apierrors.NewNotFoundApiError("api token 1 not found"),
+		},
+		{
+			name:        "error getting api token",
+			sqlGetError: gorm.ErrInvalidSQL,
+			err:         apierrors.NewInternalServerApiError("error getting api token", gorm.ErrInvalidSQL),
+		},
+		{
+			name:      "api token already revoked",
+			revokedAt: &revokedAt,
+		},
+		{
+			name:           "error revoking api token",
+			updateTimes:    1,
+			sqlUpdateError: gorm.ErrInvalidSQL,
+			err:            apierrors.NewInternalServerApiError("error revoking api token", gorm.ErrInvalidSQL),
+		},
+		{
+			name:        "api token revoked",
+			updateTimes: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+
+			sqlStorage.EXPECT().
+				GetBy(gomock.Any(), gomock.Any(), "id = ?", uint32(1)).
+				DoAndReturn(func(ctx context.Context, token *models.APIToken, qry ...interface{}) error {
+					token.ID = 1
+					token.RevokedAt = tt.revokedAt
+					return tt.sqlGetError
+				}).
+				Times(1)
+
+			sqlStorage.EXPECT().
+				Update(gomock.Any(), &models.APIToken{ID: 1, RevokedAt: &now}).
+				Return(tt.sqlUpdateError).
+				Times(tt.updateTimes)
+
+			s := &APIToken{
+				SQL: sqlStorage,
+			}
+
+			err := s.Revoke(context.Background(), 1, now)
+			if tt.err == nil {
+				assert.Nil(t, err)
+				return
+			}
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestAPIToken_Authenticate(t *testing.T) {
+	revokedAt := time.Now()
+
+	tests := []struct {
+		name        string
+		token       string
+		getTimes    int
+		sqlGetError error
+		revokedAt   *time.Time
+		want        *models.APIToken
+		err         apierrors.ApiError
+	}{
+		{
+			name: "api token missing",
+			err:  apierrors.NewUnauthorizedApiError("an api token is required"),
+		},
+		{
+			name:  "bootstrap token",
+			token: testBootstrapToken,
+			want:  &models.APIToken{Name: utils.Stringify("bootstrap"), Scopes: utils.Stringify(models.APITokenScopeAdmin)},
+		},
+		{
+			name:        "unknown api token",
+			token:       "cicd_unknown",
+			getTimes:    1,
+			sqlGetError: gorm.ErrRecordNotFound,
+			err:         apierrors.NewUnauthorizedApiError("invalid api token"),
+		},
+		{
+			name:        "error getting api token",
+			token:       "cicd_token",
+			getTimes:    1,
+			sqlGetError: gorm.ErrInvalidSQL,
+			err:         apierrors.NewInternalServerApiError("error getting api token", gorm.ErrInvalidSQL),
+		},
+		{
+			name:      "revoked api token",
+			token:     "cicd_token",
+			getTimes:  1,
+			revokedAt: &revokedAt,
+			err:       apierrors.NewUnauthorizedApiError("api token revoked"),
+		},
+		{
+			name:     "api token authenticated",
+			token:    "cicd_token",
+			getTimes: 1,
+			want:     &models.APIToken{ID: 1, Scopes: utils.Stringify(models.APITokenScopeRead)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+
+			sqlStorage.EXPECT().
+				GetBy(gomock.Any(), gomock.Any(), "hash = ?", hashAPIToken(tt.token)).
+				DoAndReturn(func(ctx context.Context, token *models.APIToken, qry ...interface{}) error {
+					token.ID = 1
+					token.Scopes = utils.Stringify(models.APITokenScopeRead)
+					token.RevokedAt = tt.revokedAt
+					return tt.sqlGetError
+				}).
+				Times(tt.getTimes)
+
+			s := &APIToken{
+				SQL:            sqlStorage,
+				BootstrapToken: testBootstrapToken,
+			}
+
+			token, err := s.Authenticate(context.Background(), tt.token)
+			assert.Equal(t, tt.want, token)
+			if tt.err == nil {
+				assert.Nil(t, err)
+				return
+			}
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestAuthenticatedUser(t *testing.T) {
+	tests := []struct {
+		name  string
+		token *models.APIToken
+		want  string
+		err   apierrors.ApiError
+	}{
+		{
+			name: "request not authenticated",
+			err:  apierrors.NewForbiddenApiError("the api token must be bound to a github user"),
+		},
+		{
+			name:  "api token not bound to a user",
+			token: &models.APIToken{Name: utils.Stringify("ci")},
+			err:   apierrors.NewForbiddenApiError("the api token must be bound to a github user"),
+		},
+		{
+			name:  "api token bound to a user",
+			token: &models.APIToken{Name: utils.Stringify("ci"), Subject: utils.Stringify("hbalmes")},
+			want:  "hbalmes",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.token != nil {
+				ctx = WithAPIToken(ctx, tt.token)
+			}
+
+			user, err := AuthenticatedUser(ctx)
+			assert.Equal(t, tt.want, user)
+			if tt.err == nil {
+				assert.Nil(t, err)
+				return
+			}
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestRequester(t *testing.T) {
+	tests := []struct {
+		name  string
+		token *models.APIToken
+		want  string
+		err   apierrors.ApiError
+	}{
+		{
+			name: "request not authenticated",
+			err:  apierrors.NewForbiddenApiError("the request must be authenticated by an api token"),
+		},
+		{
+			name:  "api token without name nor user",
+			token: &models.APIToken{},
+			err:   apierrors.NewForbiddenApiError("the api token must have a name or be bound to a github user"),
+		},
+		{
+			name:  "api token of a pipeline",
+			token: &models.APIToken{Name: utils.Stringify("ci")},
+			want:  "ci",
+		},
+		{
+			name:  "api token bound to a user",
+			token: &models.APIToken{Name: utils.Stringify("ci"), Subject: utils.Stringify("hbalmes")},
+			want:  "hbalmes",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.token != nil {
+				ctx = WithAPIToken(ctx, tt.token)
+			}
+
+			requester, err := Requester(ctx)
+			assert.Equal(t, tt.want, requester)
+			if tt.err == nil {
+				assert.Nil(t, err)
+				return
+			}
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
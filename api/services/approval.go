@@ -0,0 +1,305 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hbalmes/ci_cd-api/api/clients"
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/services/storage"
+	"github.com/hbalmes/ci_cd-api/api/utils"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"github.com/hbalmes/ci_cd-api/api/utils/logger"
+	"github.com/hbalmes/ci_cd-api/api/utils/tracing"
+	"github.com/jinzhu/gorm"
+)
+
+//ApprovalService is an interface which represents the ApprovalService for testing purpose.
+type ApprovalService interface {
+	Approve(ctx context.Context, repositoryName string, buildID uint32, environment string, r *models.ApprovalPayload) (*models.Approval, apierrors.ApiError)
+	Reject(ctx context.Context, repositoryName string, buildID uint32, environment string, r *models.ApprovalPayload) (*models.Approval, apierrors.ApiError)
+	GetGate(ctx context.Context, repositoryName string, buildID uint32, environment string) (*models.ApprovalGate, apierrors.ApiError)
+	CheckGate(ctx context.Context, config *models.Configuration, environment *models.Environment, buildID uint32) (*models.ApprovalGate, apierrors.ApiError)
+}
+
+//Approval represents the ApprovalService layer
+//It has an instance of a DBClient layer,
+//A github client instance,
+//A ConfigService instance and
+//An AuditService instance
+type Approval struct {
+	SQL           storage.SQLStorage
+	GithubClient  clients.GithubClient
+	ConfigService ConfigurationService
+	AuditService  AuditService
+}
+
+//NewApprovalService initializes an ApprovalService
+func NewApprovalService(sql storage.SQLStorage, githubClient clients.GithubClient, configService ConfigurationService, auditService AuditService) *Approval {
+	return &Approval{
+		SQL:           sql,
+		GithubClient:  githubClient,
+		ConfigService: configService,
+		AuditService:  auditService,
+	}
+}
+
+//Approve records the approval of the deployment of a build into an environment.
+func (s *Approval) Approve(ctx context.Context, repositoryName string, buildID uint32, environment string, r *models.ApprovalPayload) (*models.Approval, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "Approval.Approve")
+	defer span.End()
+
+	return s.decide(ctx, repositoryName, buildID, environment, r, models.ApprovalDecisionApproved)
+}
+
+//Reject records the rejection of the deployment of a build into an environment.
+//A comment explaining the rejection is required.
+func (s *Approval) Reject(ctx context.Context, repositoryName string, buildID uint32, environment string, r *models.ApprovalPayload) (*models.Approval, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "Approval.Reject")
+	defer span.End()
+
+	if r.Comment == nil || *r.Comment == "" {
+		return nil, apierrors.NewBadRequestApiError("a comment is required to reject")
+	}
+	return s.decide(ctx, repositoryName, buildID, environment, r, models.ApprovalDecisionRejected)
+}
+
+//decide records the decision of the user the api token of the request is bound to.
+//The approver of the payload is optional, but it must be that user when it is present.
+func (s *Approval) decide(ctx context.Context, repositoryName string, buildID uint32, environmentName string, r *models.ApprovalPayload, decision string) (*models.Approval, apierrors.ApiError) {
+
+	approver, apiErr := AuthenticatedUser(ctx)
+
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	if r.Approver != nil && *r.Approver != "" && !strings.EqualFold(*r.Approver, approver) {
+		return nil, apierrors.NewForbiddenApiError(fmt.Sprintf("the approver %s is not the authenticated user %s", *r.Approver, approver))
+	}
+
+	config, environment, apiErr := s.getEnvironment(ctx, repositoryName, environmentName)
+
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	var build models.Build
+
+	if err := s.SQL.GetBy(ctx, &build, "id = ? AND repository_name = ?", buildID, repositoryName); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierrors.NewNotFoundApiError(fmt.Sprintf("build %d not found", buildID))
+		}
+		return nil, apierrors.NewInternalServerApiError("error getting build", err)
+	}
+
+	allowed, apiErr := s.IsApprover(ctx, config, environment, approver)
+
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	if !allowed {
+		return nil, apierrors.NewApiError(fmt.Sprintf("user %s is not an approver of the environment %s", approver, environment.Name), "forbidden", http.StatusForbidden, apierrors.CauseList{})
+	}
+
+	approval := models.Approval{
+		RepositoryName: utils.Stringify(repositoryName),
+		Environment:    utils.Stringify(environment.Name),
+		BuildID:        build.ID,
+		Approver:       utils.Stringify(approver),
+		Decision:       utils.Stringify(decision),
+		Comment:        r.Comment,
+	}
+
+	if environment.ApprovalExpiryMinutes > 0 {
+		expiresAt := time.Now().Add(time.Duration(environment.ApprovalExpiryMinutes) * time.Minute)
+		approval.ExpiresAt = &expiresAt
+	}
+
+	//Save it into database
+	if err := s.SQL.Insert(ctx, &approval); err != nil {
+		return nil, apierrors.NewInternalServerApiError("error saving new approval", err)
+	}
+
+	eventType := models.AuditEventApprovalGranted
+	if decision == models.ApprovalDecisionRejected {
+		eventType = models.AuditEventApprovalRejected
+	}
+
+	description := fmt.Sprintf("deployment of build #%d to %s %s", build.ID, environment.Name, decision)
+	if r.Comment != nil && *r.Comment != "" {
+		description = fmt.Sprintf("%s: %s", description, *r.Comment)
+	}
+
+	event := models.AuditEvent{
+		RepositoryName: utils.Stringify(repositoryName),
+		Type:           utils.Stringify(eventType),
+		Branch:         build.Branch,
+		Sha:            build.Sha,
+		Actor:          utils.Stringify(approver),
+		Description:    utils.Stringify(description),
+	}
+
+	if err := s.AuditService.Record(ctx, &event); err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("error recording approval")
+	}
+
+	return &approval, nil
+}
+
+//GetGate returns the state of the approval gate of an environment for a build.
+func (s *Approval) GetGate(ctx context.Context, repositoryName string, buildID uint32, environmentName string) (*models.ApprovalGate, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "Approval.GetGate")
+	defer span.End()
+
+	config, environment, apiErr := s.getEnvironment(ctx, repositoryName, environmentName)
+
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	return s.CheckGate(ctx, config, environment, buildID)
+}
+
+//CheckGate evaluates the approval gate of an environment for a build.
+//Only the latest decision of every approver counts, and the expired decisions are ignored.
+//The gate is satisfied with the required approvals and no rejections.
+func (s *Approval) CheckGate(ctx context.Context, config *models.Configuration, environment *models.Environment, buildID uint32) (*models.ApprovalGate, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "Approval.CheckGate")
+	defer span.End()
+
+	gate := models.ApprovalGate{
+		Environment:       environment.Name,
+		BuildID:           buildID,
+		RequiredApprovals: environment.RequiredApprovals,
+		ApprovedBy:        make([]string, 0),
+		RejectedBy:        make([]string, 0),
+		Approvals:         make([]models.Approval, 0),
+	}
+
+	if !environment.HasApprovalGate() {
+		gate.Satisfied = true
+		return &gate, nil
+	}
+
+	if err := s.SQL.GetBy(ctx, &gate.Approvals, "repository_name = ? AND environment = ? AND build_id = ?", *config.ID, environment.Name, buildID); err != nil {
+		return nil, apierrors.NewInternalServerApiError("error getting approvals", err)
+	}
+
+	sort.SliceStable(gate.Approvals, func(i, j int) bool {
+		return gate.Approvals[i].ID < gate.Approvals[j].ID
+	})
+
+	gate.ApprovedBy, gate.RejectedBy = latestDecisions(gate.Approvals, time.Now())
+
+	gate.Satisfied = len(gate.RejectedBy) == 0 && len(gate.ApprovedBy) >= gate.RequiredApprovals
+
+	return &gate, nil
+}
+
+//IsApprover checks if the user can approve the deployments to the environment.
+//When the gate has no approvers configured, the maintainers and the users with write permissions can approve.
+func (s *Approval) IsApprover(ctx context.Context, config *models.Configuration, environment *models.Environment, username string) (bool, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "Approval.IsApprover")
+	defer span.End()
+
+	users, teams := config.GetEnvironmentApprovers(environment.Name)
+
+	if len(users) == 0 && len(teams) == 0 {
+		for _, maintainer := range config.GetMaintainers() {
+			if strings.EqualFold(maintainer, username) {
+				return true, nil
+			}
+		}
+
+		permission, err := s.GithubClient.GetCollaboratorPermission(ctx, config, username)
+
+		if err != nil {
+			return false, err
+		}
+
+		return permission == "admin" || permission == "write", nil
+	}
+
+	for _, user := range users {
+		if strings.EqualFold(user, username) {
+			return true, nil
+		}
+	}
+
+	for _, team := range teams {
+		member, err := s.GithubClient.IsTeamMember(ctx, config, team, username)
+
+		if err != nil {
+			return false, err
+		}
+
+		if member {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (s *Approval) getEnvironment(ctx context.Context, repositoryName string, environmentName string) (*models.Configuration, *models.Environment, apierrors.ApiError) {
+
+	config, err := s.ConfigService.Get(ctx, repositoryName)
+
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil, apierrors.NewNotFoundApiError(fmt.Sprintf("configuration for repository %s not found", repositoryName))
+		}
+		return nil, nil, apierrors.NewInternalServerApiError("error checking configuration existance", err)
+	}
+
+	environment := config.GetEnvironment(environmentName)
+
+	if environment == nil {
+		return nil, nil, apierrors.NewBadRequestApiError(fmt.Sprintf("environment %s not configured for the repository", environmentName))
+	}
+
+	//The promotions to production are approved in its environment, even without an approval gate
+	promotion := environment.Production && config.PromotionRequireApproval != nil && *config.PromotionRequireApproval
+
+	if !environment.HasApprovalGate() && !promotion {
+		return nil, nil, apierrors.NewBadRequestApiError(fmt.Sprintf("environment %s has no approval gate", environmentName))
+	}
+
+	return config, environment, nil
+}
+
+//latestDecisions returns the approvers and the rejecters of the given approvals, which must be sorted by id.
+//Only the latest decision of every approver counts, and the expired decisions are ignored.
+func latestDecisions(approvals []models.Approval, now time.Time) ([]string, []string) {
+	decisions := make(map[string]string)
+	approvers := make([]string, 0)
+
+	for _, approval := range approvals {
+		if approval.IsExpired(now) {
+			continue
+		}
+		if _, ok := decisions[*approval.Approver]; !ok {
+			approvers = append(approvers, *approval.Approver)
+		}
+		decisions[*approval.Approver] = *approval.Decision
+	}
+
+	approvedBy := make([]string, 0)
+	rejectedBy := make([]string, 0)
+
+	for _, approver := range approvers {
+		if decisions[approver] == models.ApprovalDecisionRejected {
+			rejectedBy = append(rejectedBy, approver)
+		} else {
+			approvedBy = append(approvedBy, approver)
+		}
+	}
+
+	return approvedBy, rejectedBy
+}
@@ -0,0 +1,371 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hbalmes/ci_cd-api/api/mocks/interfaces"
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/utils"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func newApprovalTestConfig() models.Configuration {
+	return models.Configuration{
+		ID:              utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:  utils.Stringify("ci-cd_api"),
+		RepositoryOwner: utils.Stringify("hbalmes"),
+		Environments: []models.Environment{
+			{Name: "staging", Position: 0},
+			{Name: "production", Production: true, Position: 1, RequiredApprovals: 2, ApprovalExpiryMinutes: 60},
+		},
+		EnvironmentApprovers: []models.EnvironmentApprover{
+			{Environment: "production", Username: "approver1"},
+			{Environment: "production", Username: "approver2"},
+			{Environment: "production", Team: "release-managers"},
+		},
+	}
+}
+
+func TestApproval_Decide(t *testing.T) {
+	type expects struct {
+		buildErr  error
+		member    bool
+		teamTimes int
+		err       apierrors.ApiError
+	}
+
+	config := newApprovalTestConfig()
+
+	tests := []struct {
+		name        string
+		environment string
+		decision    string
+		user        string
+		payload     *models.ApprovalPayload
+		expects     expects
+	}{
+		{
+			name:        "api token not bound to a user",
+			environment: "production",
+			decision:    models.ApprovalDecisionApproved,
+			payload:     &models.ApprovalPayload{Approver: utils.Stringify("approver1")},
+			expects: expects{
+				err: apierrors.NewForbiddenApiError("the api token must be bound to a github user"),
+			},
+		},
+		{
+			name:        "approver of the payload is not the authenticated user",
+			environment: "production",
+			decision:    models.ApprovalDecisionApproved,
+			user:        "approver2",
+			payload:     &models.ApprovalPayload{Approver: utils.Stringify("approver1")},
+			expects: expects{
+				err: apierrors.NewForbiddenApiError("the approver approver1 is not the authenticated user approver2"),
+			},
+		},
+		{
+			name:        "approver of the payload omitted",
+			environment: "production",
+			decision:    models.ApprovalDecisionApproved,
+			user:        "approver1",
+			payload:     &models.ApprovalPayload{},
+		},
+		{
+			name:        "rejection without comment",
+			environment: "production",
+			decision:    models.ApprovalDecisionRejected,
+			user:        "approver1",
+			payload:     &models.ApprovalPayload{Approver: utils.Stringify("approver1")},
+			expects: expects{
+				err: apierrors.NewBadRequestApiError("a comment is required to reject"),
+			},
+		},
+		{
+			name:        "environment without gate",
+			environment: "staging",
+			decision:    models.ApprovalDecisionApproved,
+			user:        "approver1",
+			payload:     &models.ApprovalPayload{Approver: utils.Stringify("approver1")},
+			expects: expects{
+				err: apierrors.NewBadRequestApiError("environment staging has no approval gate"),
+			},
+		},
+		{
+			name:        "build not found",
+			environment: "production",
+			decision:    models.ApprovalDecisionApproved,
+			user:        "approver1",
+			payload:     &models.ApprovalPayload{Approver: utils.Stringify("approver1")},
+			expects: expects{
+				buildErr: gorm.ErrRecordNotFound,
+				err:      apierrors.NewNotFoundApiError("build 12 not found"),
+			},
+		},
+		{
+			name:        "user not allowed",
+			environment: "production",
+			decision:    models.ApprovalDecisionApproved,
+			user:        "hbalmes",
+			payload:     &models.ApprovalPayload{Approver: utils.Stringify("hbalmes")},
+			expects: expects{
+				teamTimes: 1,
+				err:       apierrors.NewApiError("user hbalmes is not an approver of the environment production", "forbidden", http.StatusForbidden, apierrors.CauseList{}),
+			},
+		},
+		{
+			name:        "approved by a team member",
+			environment: "production",
+			decision:    models.ApprovalDecisionApproved,
+			user:        "hbalmes",
+			payload:     &models.ApprovalPayload{Approver: utils.Stringify("hbalmes")},
+			expects: expects{
+				member:    true,
+				teamTimes: 1,
+			},
+		},
+		{
+			name:        "approved by an approver with another case",
+			environment: "production",
+			decision:    models.ApprovalDecisionApproved,
+			user:        "Approver1",
+			payload:     &models.ApprovalPayload{},
+		},
+		{
+			name:        "rejected by an approver",
+			environment: "production",
+			decision:    models.ApprovalDecisionRejected,
+			user:        "approver2",
+			payload:     &models.ApprovalPayload{Approver: utils.Stringify("approver2"), Comment: utils.Stringify("error rate too high")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+			githubClient := interfaces.NewMockGithubClient(ctrl)
+			configService := interfaces.NewMockConfigurationService(ctrl)
+			auditService := interfaces.NewMockAuditService(ctrl)
+
+			configService.EXPECT().
+				Get(gomock.Any(), "hbalmes/ci-cd_api").
+				Return(&config, nil).
+				AnyTimes()
+
+			sqlStorage.EXPECT().
+				GetBy(gomock.Any(), gomock.Any(), "id = ? AND repository_name = ?", uint32(12), "hbalmes/ci-cd_api").
+				DoAndReturn(func(ctx context.Context, e interface{}, qry ...interface{}) error {
+					*e.(*models.Build) = models.Build{ID: 12, Sha: utils.Stringify("buildsha")}
+					return tt.expects.buildErr
+				}).
+				AnyTimes()
+
+			githubClient.EXPECT().
+				IsTeamMember(gomock.Any(), &config, "release-managers", gomock.Any()).
+				Return(tt.expects.member, nil).
+				Times(tt.expects.teamTimes)
+
+			sqlStorage.EXPECT().
+				Insert(gomock.Any(), gomock.Any()).
+				Return(nil).
+				AnyTimes()
+
+			auditService.EXPECT().
+				Record(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, event *models.AuditEvent) apierrors.ApiError {
+					assert.Equal(t, tt.user, *event.Actor)
+					assert.Contains(t, *event.Description, "deployment of build #12 to production "+tt.decision)
+					return nil
+				}).
+				AnyTimes()
+
+			s := &Approval{
+				SQL:           sqlStorage,
+				GithubClient:  githubClient,
+				ConfigService: configService,
+				AuditService:  auditService,
+			}
+
+			ctx := context.Background()
+			if tt.user != "" {
+				ctx = WithAPIToken(ctx, &models.APIToken{Subject: utils.Stringify(tt.user)})
+			}
+
+			var approval *models.Approval
+			var err apierrors.ApiError
+			if tt.decision == models.ApprovalDecisionApproved {
+				approval, err = s.Approve(ctx, "hbalmes/ci-cd_api", 12, tt.environment, tt.payload)
+			} else {
+				approval, err = s.Reject(ctx, "hbalmes/ci-cd_api", 12, tt.environment, tt.payload)
+			}
+
+			assert.Equal(t, tt.expects.err, err)
+			if err == nil {
+				assert.Equal(t, tt.decision, *approval.Decision)
+				assert.Equal(t, tt.user, *approval.Approver)
+				assert.WithinDuration(t, time.Now().Add(time.Hour), *approval.ExpiresAt, time.Minute)
+			}
+		})
+	}
+}
+
+func TestApproval_ApprovePromotion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	enabled := true
+
+	//Production has no approval gate, but the promotions must be approved
+	config := models.Configuration{
+		ID:                       utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:           utils.Stringify("ci-cd_api"),
+		RepositoryOwner:          utils.Stringify("hbalmes"),
+		Maintainers:              []models.Maintainer{{Username: "maintainer"}},
+		PromotionRequireApproval: &enabled,
+	}
+
+	sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+	configService := interfaces.NewMockConfigurationService(ctrl)
+	auditService := interfaces.NewMockAuditService(ctrl)
+
+	configService.EXPECT().
+		Get(gomock.Any(), "hbalmes/ci-cd_api").
+		Return(&config, nil).
+		Times(2)
+
+	sqlStorage.EXPECT().
+		GetBy(gomock.Any(), gomock.Any(), "id = ? AND repository_name = ?", uint32(12), "hbalmes/ci-cd_api").
+		DoAndReturn(func(ctx context.Context, e interface{}, qry ...interface{}) error {
+			*e.(*models.Build) = models.Build{ID: 12, Sha: utils.Stringify("buildsha")}
+			return nil
+		}).
+		Times(1)
+
+	sqlStorage.EXPECT().
+		Insert(gomock.Any(), gomock.Any()).
+		Return(nil).
+		Times(1)
+
+	auditService.EXPECT().
+		Record(gomock.Any(), gomock.Any()).
+		Return(nil).
+		Times(1)
+
+	s := &Approval{
+		SQL:           sqlStorage,
+		ConfigService: configService,
+		AuditService:  auditService,
+	}
+
+	ctx := WithAPIToken(context.Background(), &models.APIToken{Subject: utils.Stringify("maintainer")})
+
+	approval, err := s.Approve(ctx, "hbalmes/ci-cd_api", 12, "production", &models.ApprovalPayload{})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "maintainer", *approval.Approver)
+	assert.Equal(t, "production", *approval.Environment)
+
+	//The other environments still need an approval gate
+	_, err = s.Approve(ctx, "hbalmes/ci-cd_api", 12, "staging", &models.ApprovalPayload{})
+
+	assert.Equal(t, apierrors.NewBadRequestApiError("environment staging has no approval gate"), err)
+}
+
+func TestApproval_CheckGate(t *testing.T) {
+	config := newApprovalTestConfig()
+
+	expired := time.Now().Add(-time.Minute)
+
+	newApproval := func(id uint32, approver string, decision string, expiresAt *time.Time) models.Approval {
+		return models.Approval{ID: id, Approver: utils.Stringify(approver), Decision: utils.Stringify(decision), ExpiresAt: expiresAt}
+	}
+
+	tests := []struct {
+		name       string
+		approvals  []models.Approval
+		approvedBy []string
+		rejectedBy []string
+		satisfied  bool
+	}{
+		{
+			name:       "without approvals",
+			approvedBy: []string{},
+			rejectedBy: []string{},
+		},
+		{
+			name: "expired approvals are ignored",
+			approvals: []models.Approval{
+				newApproval(1, "approver1", models.ApprovalDecisionApproved, &expired),
+				newApproval(2, "approver2", models.ApprovalDecisionApproved, nil),
+			},
+			approvedBy: []string{"approver2"},
+			rejectedBy: []string{},
+		},
+		{
+			name: "the latest decision of every approver counts",
+			approvals: []models.Approval{
+				newApproval(3, "approver2", models.ApprovalDecisionApproved, nil),
+				newApproval(1, "approver1", models.ApprovalDecisionRejected, nil),
+				newApproval(2, "approver1", models.ApprovalDecisionApproved, nil),
+			},
+			approvedBy: []string{"approver1", "approver2"},
+			rejectedBy: []string{},
+			satisfied:  true,
+		},
+		{
+			name: "a rejection blocks the gate",
+			approvals: []models.Approval{
+				newApproval(1, "approver1", models.ApprovalDecisionApproved, nil),
+				newApproval(2, "approver2", models.ApprovalDecisionApproved, nil),
+				newApproval(3, "hbalmes", models.ApprovalDecisionRejected, nil),
+			},
+			approvedBy: []string{"approver1", "approver2"},
+			rejectedBy: []string{"hbalmes"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+
+			sqlStorage.EXPECT().
+				GetBy(gomock.Any(), gomock.Any(), "repository_name = ? AND environment = ? AND build_id = ?", "hbalmes/ci-cd_api", "production", uint32(12)).
+				DoAndReturn(func(ctx context.Context, e interface{}, qry ...interface{}) error {
+					*e.(*[]models.Approval) = append(*e.(*[]models.Approval), tt.approvals...)
+					return nil
+				}).
+				Times(1)
+
+			s := &Approval{
+				SQL: sqlStorage,
+			}
+
+			gate, err := s.CheckGate(context.Background(), &config, config.GetEnvironment("production"), 12)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.approvedBy, gate.ApprovedBy)
+			assert.Equal(t, tt.rejectedBy, gate.RejectedBy)
+			assert.Equal(t, tt.satisfied, gate.Satisfied)
+		})
+	}
+}
+
+func TestApproval_CheckGateWithoutGate(t *testing.T) {
+	config := newApprovalTestConfig()
+
+	s := &Approval{}
+
+	gate, err := s.CheckGate(context.Background(), &config, config.GetEnvironment("staging"), 12)
+
+	assert.Nil(t, err)
+	assert.True(t, gate.Satisfied)
+}
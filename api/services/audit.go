@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"sort"
+
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/services/storage"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"github.com/hbalmes/ci_cd-api/api/utils/tracing"
+)
+
+//AuditService is an interface which represents the AuditService for testing purpose.
+type AuditService interface {
+	Record(ctx context.Context, event *models.AuditEvent) apierrors.ApiError
+	List(ctx context.Context, repositoryName string) ([]models.AuditEvent, apierrors.ApiError)
+}
+
+//Audit represents the AuditService layer
+//It has an instance of a DBClient layer
+type Audit struct {
+	SQL storage.SQLStorage
+}
+
+//NewAuditService initializes an AuditService
+func NewAuditService(sql storage.SQLStorage) *Audit {
+	return &Audit{
+		SQL: sql,
+	}
+}
+
+//Record saves an audit event.
+func (s *Audit) Record(ctx context.Context, event *models.AuditEvent) apierrors.ApiError {
+	ctx, span := tracing.Start(ctx, "Audit.Record")
+	defer span.End()
+
+	if event.RepositoryName == nil || event.Type == nil {
+		return apierrors.NewBadRequestApiError("repository name and type are required")
+	}
+
+	//Save it into database
+	if err := s.SQL.Insert(ctx, event); err != nil {
+		return apierrors.NewInternalServerApiError("error saving new audit event", err)
+	}
+
+	return nil
+}
+
+//List returns the audit events recorded for a repository, newest first.
+func (s *Audit) List(ctx context.Context, repositoryName string) ([]models.AuditEvent, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "Audit.List")
+	defer span.End()
+
+	events := make([]models.AuditEvent, 0)
+
+	if err := s.SQL.GetBy(ctx, &events, "repository_name = ?", repositoryName); err != nil {
+		return nil, apierrors.NewInternalServerApiError("error getting audit events", err)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].CreatedAt.After(events[j].CreatedAt)
+	})
+
+	return events, nil
+}
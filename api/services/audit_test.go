@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hbalmes/ci_cd-api/api/mocks/interfaces"
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/utils"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAudit_Record(t *testing.T) {
+	tests := []struct {
+		name           string
+		event          *models.AuditEvent
+		sqlInsertError error
+		insertTimes    int
+		err            apierrors.ApiError
+	}{
+		{
+			name:  "invalid event",
+			event: &models.AuditEvent{RepositoryName: utils.Stringify("hbalmes/ci-cd_api")},
+			err:   apierrors.NewBadRequestApiError("repository name and type are required"),
+		},
+		{
+			name: "error saving event",
+			event: &models.AuditEvent{
+				RepositoryName: utils.Stringify("hbalmes/ci-cd_api"),
+				Type:           utils.Stringify(models.AuditEventDirectPush),
+			},
+			sqlInsertError: gorm.ErrInvalidSQL,
+			insertTimes:    1,
+			err:            apierrors.NewInternalServerApiError("error saving new audit event", gorm.ErrInvalidSQL),
+		},
+		{
+			name: "event saved",
+			event: &models.AuditEvent{
+				RepositoryName: utils.Stringify("hbalmes/ci-cd_api"),
+				Type:           utils.Stringify(models.AuditEventDirectPush),
+			},
+			insertTimes: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+
+			sqlStorage.EXPECT().
+				Insert(gomock.Any(), tt.event).
+				Return(tt.sqlInsertError).
+				Times(tt.insertTimes)
+
+			s := &Audit{
+				SQL: sqlStorage,
+			}
+
+			err := s.Record(context.Background(), tt.event)
+			if tt.err == nil {
+				assert.Nil(t, err)
+				return
+			}
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestAudit_List(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		sqlGetError error
+		err         apierrors.ApiError
+	}{
+		{
+			name:        "error getting events",
+			sqlGetError: gorm.ErrInvalidSQL,
+			err:         apierrors.NewInternalServerApiError("error getting audit events", gorm.ErrInvalidSQL),
+		},
+		{
+			name: "events sorted newest first",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+
+			sqlStorage.EXPECT().
+				GetBy(gomock.Any(), gomock.Any(), "repository_name = ?", "hbalmes/ci-cd_api").
+				Do(func(ctx context.Context, e interface{}, qry ...interface{}) {
+					*e.(*[]models.AuditEvent) = []models.AuditEvent{
+						{ID: 1, CreatedAt: now.Add(-time.Hour)},
+						{ID: 2, CreatedAt: now},
+					}
+				}).
+				Return(tt.sqlGetError).
+				Times(1)
+
+			s := &Audit{
+				SQL: sqlStorage,
+			}
+
+			events, err := s.List(context.Background(), "hbalmes/ci-cd_api")
+			if tt.err != nil {
+				assert.Nil(t, events)
+				assert.Equal(t, tt.err, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, uint32(2), events[0].ID)
+			assert.Equal(t, uint32(1), events[1].ID)
+		})
+	}
+}
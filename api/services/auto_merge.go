@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/hbalmes/ci_cd-api/api/clients"
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/models/webhook"
+	"github.com/hbalmes/ci_cd-api/api/services/storage"
+	"github.com/hbalmes/ci_cd-api/api/utils"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"github.com/hbalmes/ci_cd-api/api/utils/logger"
+	"github.com/hbalmes/ci_cd-api/api/utils/tracing"
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	autoMergeLabel         = "auto-merge"
+	closedPullRequestState = "closed"
+)
+
+//AutoMergeService is an interface which represents the AutoMergeService for testing purpose.
+type AutoMergeService interface {
+	ProcessAutoMerge(ctx context.Context, config *models.Configuration, payload *webhook.Status) (bool, apierrors.ApiError)
+}
+
+//AutoMerge represents the AutoMergeService layer
+//It has an instance of a DBClient layer,
+//A github client instance,
+//A BuildService instance and
+//A WorkflowService instance
+type AutoMerge struct {
+	SQL             storage.SQLStorage
+	GithubClient    clients.GithubClient
+	BuildService    BuildService
+	WorkflowService WorkflowService
+}
+
+//NewAutoMergeService initializes an AutoMergeService
+func NewAutoMergeService(sql storage.SQLStorage, githubClient clients.GithubClient, buildService BuildService, workflowService WorkflowService) *AutoMerge {
+	return &AutoMerge{
+		SQL:             sql,
+		GithubClient:    githubClient,
+		BuildService:    buildService,
+		WorkflowService: workflowService,
+	}
+}
+
+//IsAutoMergeEnabled checks if the pull request must be merged automatically.
+//It is enabled for every pull request by the configuration or for a single one through the 'auto-merge' label.
+func IsAutoMergeEnabled(config *models.Configuration, pullRequest *models.PullRequest) bool {
+	return (config.AutoMergeEnabled != nil && *config.AutoMergeEnabled) || pullRequest.AutoMerge
+}
+
+//HasAutoMergeLabel checks if the 'auto-merge' label is present in the pull request webhook.
+func HasAutoMergeLabel(payload webhook.PullRequestWebhook) bool {
+	for _, label := range payload.PullRequest.Labels {
+		if label.Name != nil && *label.Name == autoMergeLabel {
+			return true
+		}
+	}
+	return false
+}
+
+//ProcessAutoMerge merges the pull request of the payload sha once every quality gate passed.
+//The workflow is validated again before merging and the reason is posted in the pull request
+//when the merge is declined, once per reason.
+//The merge is guarded by the payload sha, so the commits pushed after the quality gates passed wait for their own.
+//Returns if the pull request was merged.
+func (s *AutoMerge) ProcessAutoMerge(ctx context.Context, config *models.Configuration, payload *webhook.Status) (bool, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "AutoMerge.ProcessAutoMerge")
+	defer span.End()
+
+	var pullRequest models.PullRequest
+
+	if err := s.SQL.GetBy(ctx, &pullRequest, "head_sha = ?", *payload.Sha); err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return false, apierrors.NewInternalServerApiError("error getting pull request", err)
+		}
+		//Shas without pull request have nothing to merge
+		return false, nil
+	}
+
+	if !IsAutoMergeEnabled(config, &pullRequest) {
+		return false, nil
+	}
+
+	if pullRequest.State != nil && *pullRequest.State == closedPullRequestState {
+		return false, nil
+	}
+
+	//Waits until every required context and approval is satisfied
+	states := s.BuildService.GetStatusChecksState(ctx, autoMergeStatusChecks(config), payload)
+
+	for _, passed := range states {
+		if !passed {
+			return false, nil
+		}
+	}
+
+	//The workflow could have changed since it was checked
+	var prWebhook webhook.PullRequestWebhook
+	prWebhook.PullRequest.Base.Ref = pullRequest.BaseRef
+	prWebhook.PullRequest.Head.Ref = pullRequest.HeadRef
+	prWebhook.PullRequest.Head.Sha = pullRequest.HeadSha
+	prWebhook.Repository.FullName = pullRequest.RepositoryName
+
+	workflowStatus := s.WorkflowService.CheckWorkflow(config, &prWebhook)
+
+	if *workflowStatus.State != statusWebhookSuccessState {
+		s.reportDeclinedMerge(ctx, config, &pullRequest, fmt.Sprintf("The workflow is not valid anymore: %s", *workflowStatus.Description))
+		return false, apierrors.NewApiError("auto-merge declined by the workflow", "conflict_error", http.StatusConflict, apierrors.CauseList{})
+	}
+
+	method := config.GetAutoMergeMethod()
+
+	//Only the sha which passed the quality gates is merged
+	if err := s.GithubClient.MergePullRequest(ctx, config, pullRequest.PullRequestNumber, method, *payload.Sha); err != nil {
+		//New commits were pushed, the merge waits for their quality gates
+		if err.Code() == clients.HeadModifiedErrorCode {
+			logger.FromContext(ctx).Info().Int("pull_request", pullRequest.PullRequestNumber).Str("sha", *payload.Sha).Msg("pull request head modified, auto-merge waits for the new head")
+			return false, nil
+		}
+
+		s.reportDeclinedMerge(ctx, config, &pullRequest, fmt.Sprintf("Github refused the **%s**: %s", method, err.Message()))
+		return false, err
+	}
+
+	logger.FromContext(ctx).Info().Int("pull_request", pullRequest.PullRequestNumber).Str("method", method).Msg("pull request merged automatically")
+
+	return true, nil
+}
+
+func (s *AutoMerge) reportDeclinedMerge(ctx context.Context, config *models.Configuration, pullRequest *models.PullRequest, reason string) {
+	var notice models.AutoMergeNotice
+
+	if err := s.SQL.GetBy(ctx, &notice, "pull_request_id = ?", pullRequest.ID); err != nil && err != gorm.ErrRecordNotFound {
+		logger.FromContext(ctx).Error().Err(err).Int("pull_request", pullRequest.PullRequestNumber).Msg("error getting auto-merge notice")
+	}
+
+	//The reason was already posted by a previous status
+	if notice.IsReported(reason) {
+		return
+	}
+
+	body := "# Auto-merge declined :no_entry: \n" + "\n" + fmt.Sprintf("> %s", reason)
+
+	if err := s.GithubClient.CreateIssueComment(ctx, config, pullRequest, body); err != nil {
+		logger.FromContext(ctx).Error().Err(err).Int("pull_request", pullRequest.PullRequestNumber).Msg("error reporting declined auto-merge")
+		return
+	}
+
+	notice.PullRequestID = pullRequest.ID
+	notice.RepositoryName = pullRequest.RepositoryName
+	notice.PullRequestNumber = pullRequest.PullRequestNumber
+	notice.Reason = utils.Stringify(reason)
+
+	if err := s.SQL.Update(ctx, &notice); err != nil {
+		logger.FromContext(ctx).Error().Err(err).Int("pull_request", pullRequest.PullRequestNumber).Msg("error saving auto-merge notice")
+	}
+}
+
+//autoMergeStatusChecks returns the contexts and the approval required to merge a pull request.
+func autoMergeStatusChecks(config *models.Configuration) []string {
+	return append(config.GetRequiredStatusCheck(), "pull_request_review")
+}
@@ -0,0 +1,331 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hbalmes/ci_cd-api/api/mocks/interfaces"
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/models/webhook"
+	"github.com/hbalmes/ci_cd-api/api/utils"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHasAutoMergeLabel(t *testing.T) {
+	var labeled webhook.PullRequestWebhook
+	labeled.PullRequest.Labels = append(labeled.PullRequest.Labels, struct {
+		Name *string `json:"name"`
+	}{Name: utils.Stringify("auto-merge")})
+
+	var unlabeled webhook.PullRequestWebhook
+	unlabeled.PullRequest.Labels = append(unlabeled.PullRequest.Labels, struct {
+		Name *string `json:"name"`
+	}{Name: utils.Stringify("bug")})
+
+	assert.True(t, HasAutoMergeLabel(labeled))
+	assert.False(t, HasAutoMergeLabel(unlabeled))
+}
+
+func TestAutoMerge_ProcessAutoMerge(t *testing.T) {
+	type args struct {
+		config      *models.Configuration
+		pullRequest models.PullRequest
+	}
+
+	type expects struct {
+		getPRErr      error
+		states        map[string]bool
+		statesTimes   int
+		workflowState string
+		workflowTimes int
+		mergeErr      apierrors.ApiError
+		mergeMethod   string
+		mergeTimes    int
+		notice        *models.AutoMergeNotice
+		commentTimes  int
+		merged        bool
+		err           apierrors.ApiError
+	}
+
+	enabled := true
+
+	configEnabled := models.Configuration{
+		ID:                     utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:         utils.Stringify("ci-cd_api"),
+		RepositoryOwner:        utils.Stringify("hbalmes"),
+		AutoMergeEnabled:       &enabled,
+		AutoMergeMethod:        utils.Stringify("squash"),
+		RepositoryStatusChecks: []models.RequireStatusCheck{{Check: "ci"}, {Check: "workflow"}},
+	}
+
+	configDisabled := models.Configuration{
+		ID:                     utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:         utils.Stringify("ci-cd_api"),
+		RepositoryOwner:        utils.Stringify("hbalmes"),
+		RepositoryStatusChecks: []models.RequireStatusCheck{{Check: "ci"}, {Check: "workflow"}},
+	}
+
+	pr := models.PullRequest{
+		ID:                1234,
+		PullRequestNumber: 12,
+		State:             utils.Stringify("open"),
+		RepositoryName:    utils.Stringify("hbalmes/ci-cd_api"),
+		BaseRef:           utils.Stringify("develop"),
+		HeadRef:           utils.Stringify("feature/auto-merge"),
+		HeadSha:           utils.Stringify("23456789qwertyuiasdfghjzxcvbn"),
+	}
+
+	labeledPR := pr
+	labeledPR.AutoMerge = true
+
+	closedPR := pr
+	closedPR.State = utils.Stringify("closed")
+
+	allPassed := map[string]bool{"ci": true, "workflow": true, "pull_request_review": true}
+
+	refusedReason := models.AutoMergeNotice{PullRequestID: 1234, Reason: utils.Stringify("Github refused the **squash**: pull request not mergeable")}
+	workflowReason := models.AutoMergeNotice{PullRequestID: 1234, Reason: utils.Stringify("The workflow is not valid anymore: the workflow description")}
+
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+		expects expects
+	}{
+		{
+			name:    "sha without pull request",
+			args:    args{config: &configEnabled, pullRequest: pr},
+			wantErr: false,
+			expects: expects{
+				getPRErr: gorm.ErrRecordNotFound,
+			},
+		},
+		{
+			name:    "error getting pull request",
+			args:    args{config: &configEnabled, pullRequest: pr},
+			wantErr: true,
+			expects: expects{
+				getPRErr: gorm.ErrInvalidSQL,
+				err:      apierrors.NewInternalServerApiError("error getting pull request", gorm.ErrInvalidSQL),
+			},
+		},
+		{
+			name:    "auto-merge disabled and pull request without label",
+			args:    args{config: &configDisabled, pullRequest: pr},
+			wantErr: false,
+		},
+		{
+			name:    "closed pull request",
+			args:    args{config: &configEnabled, pullRequest: closedPR},
+			wantErr: false,
+		},
+		{
+			name:    "quality gates pending",
+			args:    args{config: &configEnabled, pullRequest: pr},
+			wantErr: false,
+			expects: expects{
+				states:      map[string]bool{"ci": true, "workflow": true, "pull_request_review": false},
+				statesTimes: 1,
+			},
+		},
+		{
+			name:    "ci pending",
+			args:    args{config: &configEnabled, pullRequest: pr},
+			wantErr: false,
+			expects: expects{
+				states:      map[string]bool{"ci": false, "workflow": true, "pull_request_review": true},
+				statesTimes: 1,
+			},
+		},
+		{
+			name:    "workflow not valid anymore",
+			args:    args{config: &configEnabled, pullRequest: pr},
+			wantErr: true,
+			expects: expects{
+				states:        allPassed,
+				statesTimes:   1,
+				workflowState: "error",
+				workflowTimes: 1,
+				commentTimes:  1,
+				err:           apierrors.NewApiError("auto-merge declined by the workflow", "conflict_error", 409, apierrors.CauseList{}),
+			},
+		},
+		{
+			name:    "github refuses the merge",
+			args:    args{config: &configEnabled, pullRequest: pr},
+			wantErr: true,
+			expects: expects{
+				states:        allPassed,
+				statesTimes:   1,
+				workflowState: "success",
+				workflowTimes: 1,
+				mergeErr:      apierrors.NewApiError("pull request not mergeable", "conflict_error", 409, apierrors.CauseList{}),
+				mergeMethod:   "squash",
+				mergeTimes:    1,
+				commentTimes:  1,
+				err:           apierrors.NewApiError("pull request not mergeable", "conflict_error", 409, apierrors.CauseList{}),
+			},
+		},
+		{
+			name:    "head modified since the quality gates passed",
+			args:    args{config: &configEnabled, pullRequest: pr},
+			wantErr: false,
+			expects: expects{
+				states:        allPassed,
+				statesTimes:   1,
+				workflowState: "success",
+				workflowTimes: 1,
+				mergeErr:      apierrors.NewApiError("pull request head was modified", "head_modified", 409, apierrors.CauseList{}),
+				mergeMethod:   "squash",
+				mergeTimes:    1,
+			},
+		},
+		{
+			name:    "declined reason already reported",
+			args:    args{config: &configEnabled, pullRequest: pr},
+			wantErr: true,
+			expects: expects{
+				states:        allPassed,
+				statesTimes:   1,
+				workflowState: "success",
+				workflowTimes: 1,
+				mergeErr:      apierrors.NewApiError("pull request not mergeable", "conflict_error", 409, apierrors.CauseList{}),
+				mergeMethod:   "squash",
+				mergeTimes:    1,
+				notice:        &refusedReason,
+				err:           apierrors.NewApiError("pull request not mergeable", "conflict_error", 409, apierrors.CauseList{}),
+			},
+		},
+		{
+			name:    "declined for a new reason",
+			args:    args{config: &configEnabled, pullRequest: pr},
+			wantErr: true,
+			expects: expects{
+				states:        allPassed,
+				statesTimes:   1,
+				workflowState: "success",
+				workflowTimes: 1,
+				mergeErr:      apierrors.NewApiError("pull request not mergeable", "conflict_error", 409, apierrors.CauseList{}),
+				mergeMethod:   "squash",
+				mergeTimes:    1,
+				notice:        &workflowReason,
+				commentTimes:  1,
+				err:           apierrors.NewApiError("pull request not mergeable", "conflict_error", 409, apierrors.CauseList{}),
+			},
+		},
+		{
+			name:    "merged with the configured method",
+			args:    args{config: &configEnabled, pullRequest: pr},
+			wantErr: false,
+			expects: expects{
+				states:        allPassed,
+				statesTimes:   1,
+				workflowState: "success",
+				workflowTimes: 1,
+				mergeMethod:   "squash",
+				mergeTimes:    1,
+				merged:        true,
+			},
+		},
+		{
+			name:    "labeled pull request merged with the default method",
+			args:    args{config: &configDisabled, pullRequest: labeledPR},
+			wantErr: false,
+			expects: expects{
+				states:        allPassed,
+				statesTimes:   1,
+				workflowState: "success",
+				workflowTimes: 1,
+				mergeMethod:   "merge",
+				mergeTimes:    1,
+				merged:        true,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+			githubClient := interfaces.NewMockGithubClient(ctrl)
+			buildService := interfaces.NewMockBuildService(ctrl)
+			workflowService := interfaces.NewMockWorkflowService(ctrl)
+
+			pullRequest := tt.args.pullRequest
+
+			sqlStorage.EXPECT().
+				GetBy(gomock.Any(), gomock.Any(), "head_sha = ?", gomock.Any()).
+				Do(func(ctx context.Context, e interface{}, qry ...interface{}) {
+					*e.(*models.PullRequest) = pullRequest
+				}).
+				Return(tt.expects.getPRErr).
+				Times(1)
+
+			sqlStorage.EXPECT().
+				GetBy(gomock.Any(), gomock.Any(), "pull_request_id = ?", int64(1234)).
+				DoAndReturn(func(ctx context.Context, e interface{}, qry ...interface{}) error {
+					if tt.expects.notice == nil {
+						return gorm.ErrRecordNotFound
+					}
+					*e.(*models.AutoMergeNotice) = *tt.expects.notice
+					return nil
+				}).
+				AnyTimes()
+
+			sqlStorage.EXPECT().
+				Update(gomock.Any(), gomock.Any()).
+				Do(func(ctx context.Context, e interface{}) {
+					assert.Equal(t, int64(1234), e.(*models.AutoMergeNotice).PullRequestID)
+				}).
+				Return(nil).
+				Times(tt.expects.commentTimes)
+
+			buildService.EXPECT().
+				GetStatusChecksState(gomock.Any(), []string{"ci", "workflow", "pull_request_review"}, gomock.Any()).
+				Return(tt.expects.states).
+				Times(tt.expects.statesTimes)
+
+			workflowService.EXPECT().
+				CheckWorkflow(tt.args.config, gomock.Any()).
+				Return(&webhook.Status{
+					State:       utils.Stringify(tt.expects.workflowState),
+					Description: utils.Stringify("the workflow description"),
+				}).
+				Times(tt.expects.workflowTimes)
+
+			githubClient.EXPECT().
+				MergePullRequest(gomock.Any(), tt.args.config, 12, tt.expects.mergeMethod, "23456789qwertyuiasdfghjzxcvbn").
+				Return(tt.expects.mergeErr).
+				Times(tt.expects.mergeTimes)
+
+			githubClient.EXPECT().
+				CreateIssueComment(gomock.Any(), tt.args.config, gomock.Any(), gomock.Any()).
+				Return(nil).
+				Times(tt.expects.commentTimes)
+
+			s := &AutoMerge{
+				SQL:             sqlStorage,
+				GithubClient:    githubClient,
+				BuildService:    buildService,
+				WorkflowService: workflowService,
+			}
+
+			var payload webhook.Status
+			payload.Sha = pr.HeadSha
+			payload.Repository.FullName = pr.RepositoryName
+
+			merged, err := s.ProcessAutoMerge(context.Background(), tt.args.config, &payload)
+
+			assert.Equal(t, tt.expects.merged, merged)
+			if tt.wantErr {
+				assert.Equal(t, tt.expects.err, err)
+				return
+			}
+			assert.Nil(t, err)
+		})
+	}
+}
@@ -1,85 +1,162 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"github.com/coreos/go-semver/semver"
 	"github.com/hbalmes/ci_cd-api/api/clients"
+	"github.com/hbalmes/ci_cd-api/api/metrics"
 	"github.com/hbalmes/ci_cd-api/api/models"
 	"github.com/hbalmes/ci_cd-api/api/models/webhook"
 	"github.com/hbalmes/ci_cd-api/api/services/storage"
 	"github.com/hbalmes/ci_cd-api/api/utils"
 	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"github.com/hbalmes/ci_cd-api/api/utils/logger"
+	"github.com/hbalmes/ci_cd-api/api/utils/tracing"
 	"github.com/jinzhu/gorm"
-	"github.com/rs/zerolog/log"
+	"net/http"
 	"strconv"
 	"strings"
 	"time"
 )
 
 const (
-	initialMajor       = 0
-	initialMinor       = 0
-	initialPatch       = 0
-	initialBuildStatus = "finished"
-	initialBuildType   = "productive"
-	automaticBuildBody = "release created automatically by hbalmes/ci_cd-api"
+	initialMajor        = 0
+	initialMinor        = 0
+	initialPatch        = 0
+	initialBuildStatus  = "finished"
+	initialBuildType    = "productive"
+	automaticBuildBody  = "release created automatically by hbalmes/ci_cd-api"
+	backMergeHead       = "master"
+	backMergeBase       = "develop"
+	backMergeMethod     = "merge"
+	freezeStatusContext = "release-freeze"
+	freezeDateLayout    = "2006-01-02 15:04 MST"
 )
 
 type BuildService interface {
-	ProcessBuild(config *models.Configuration, payload *webhook.Status) (*models.Build, apierrors.ApiError)
+	ProcessBuild(ctx context.Context, config *models.Configuration, payload *webhook.Status) (*models.Build, apierrors.ApiError)
+	GetBuildeableStatusChecks(config *models.Configuration) []string
+	GetStatusChecksState(ctx context.Context, reqSCConfigured []string, payload *webhook.Status) map[string]bool
+	GetReleaseOverride(ctx context.Context, pr *models.PullRequest) *models.ReleaseOverride
+	ResetFreezeStatus(ctx context.Context, config *models.Configuration, pr *models.PullRequest, description string)
+	ResetEndedFreezes(ctx context.Context, now time.Time)
+	BackMerge(ctx context.Context, config *models.Configuration, pr *models.PullRequest)
+	GetLatestBuild(ctx context.Context, config *models.Configuration) *semver.Version
+	IncrementSemVer(version semver.Version, incrementer string) semver.Version
+	SaveBuild(ctx context.Context, build *models.Build) apierrors.ApiError
+	CreateAndSaveLatestBuild(ctx context.Context, build *models.Build, lastBuild *semver.Version) apierrors.ApiError
 }
 
 //Build represents the BuildService layer
 //It has an instance of a DBClient layer and
 //A Webhook service instance and
-//A ConfigService instance
+//A ConfigService instance and
+//A ReleaseNotesService instance and
+//A ChangelogService instance,
+//A VersionFilesService instance and
+//A FreezeWindowService instance
 type Build struct {
-	SQL          storage.SQLStorage
-	GithubClient clients.GithubClient
+	SQL                 storage.SQLStorage
+	GithubClient        clients.GithubClient
+	ReleaseNotesService ReleaseNotesService
+	ChangelogService    ChangelogService
+	VersionFilesService VersionFilesService
+	FreezeWindowService FreezeWindowService
 }
 
 //NewConfigurationSeNewWebhookServicervice initializes a WebhookService
-func NewBuildService(sql storage.SQLStorage) *Build {
+func NewBuildService(sql storage.SQLStorage, githubClient clients.GithubClient, releaseNotesService ReleaseNotesService, changelogService ChangelogService, versionFilesService VersionFilesService, freezeWindowService FreezeWindowService) *Build {
 	return &Build{
-		SQL: sql,
-		GithubClient: clients.NewGithubClient(),
+		SQL:                 sql,
+		GithubClient:        githubClient,
+		ReleaseNotesService: releaseNotesService,
+		ChangelogService:    changelogService,
+		VersionFilesService: versionFilesService,
+		FreezeWindowService: freezeWindowService,
 	}
 }
 
 //ProcessBuild checks if all quality checks passed, and creates a build
 //For this checks quality checks like (coverage, ci, workflow and pull request review approved)
 //Find the latest created build and increment the semver number following the configuration
-func (s *Build) ProcessBuild(config *models.Configuration, payload *webhook.Status) (*models.Build, apierrors.ApiError) {
+func (s *Build) ProcessBuild(ctx context.Context, config *models.Configuration, payload *webhook.Status) (*models.Build, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "Build.ProcessBuild")
+	defer span.End()
 
 	//First check if all status checks configured pass
 	buildeableSChecks := s.GetBuildeableStatusChecks(config)
 
-	isBuildeable := s.CheckBuildability(buildeableSChecks, payload)
+	isBuildeable := s.CheckBuildability(ctx, buildeableSChecks, payload)
 
 	if isBuildeable {
 		//Gets the last craeted build
 		//if the repo don't have builds created, we generate the default
-		lastBuild := s.GetLatestBuild(config)
+		lastBuild := s.GetLatestBuild(ctx, config)
 
 		//Busca a que PR pertenece el sha para luego saber que campo debo aumentar
-		pRequest, err := s.GetPullRequestBySha(*payload.Sha)
+		pRequest, err := s.GetPullRequestBySha(ctx, *payload.Sha)
 
 		if err != nil {
 			return nil, err
 		}
 
+		//The version files commit of a release is not released again
+		if s.IsReleased(ctx, *payload.Sha) {
+			return nil, apierrors.NewApiError("The sha was already released.", "skipped", 206, apierrors.CauseList{})
+		}
+
+		//Back-merges only bring released changes into develop
+		if IsBackMerge(pRequest) {
+			return nil, apierrors.NewApiError("Back-merge pull requests are not released.", "skipped", 206, apierrors.CauseList{})
+		}
+
+		//Release decisions taken through ChatOps commands
+		override := s.GetReleaseOverride(ctx, pRequest)
+
+		if override != nil && override.SkipRelease {
+			return nil, apierrors.NewApiError("The release was skipped by a ChatOps command.", "skipped", 206, apierrors.CauseList{})
+		}
+
 		//traemos el incrementador y el tipo de build
 		incrementer, buildType := s.GetIncrementerAndType(pRequest)
+
+		if override != nil && override.Bump != nil {
+			incrementer = *override.Bump
+		}
+
+		//Productive releases are blocked during the freeze windows
+		if buildType == "productive" {
+			if freezeErr := s.CheckFreeze(ctx, config, pRequest, override); freezeErr != nil {
+				return nil, freezeErr
+			}
+		}
 		newSemVer := s.IncrementSemVer(*lastBuild, incrementer)
 
 		//Creates the build entity
 		build:= s.CreateBuild(pRequest, newSemVer, buildType)
 
-		//Creates the github release
-		createGHReleaseErr := s.GithubClient.CreateRelease(config, build)
+		//Release notes with the pull requests merged since the previous build
+		releaseNotes, notesErr := s.ReleaseNotesService.Generate(ctx, config, build, pRequest)
 
-		if createGHReleaseErr != nil {
-			return nil, createGHReleaseErr
+		if notesErr == nil {
+			build.ReleasedPullRequests = releaseNotes.GetReleasedPullRequests()
+
+			var body string
+			if body, notesErr = s.ReleaseNotesService.Render(config, releaseNotes); notesErr == nil {
+				build.Body = utils.Stringify(body)
+			}
+		}
+
+		if notesErr != nil {
+			logger.FromContext(ctx).Error().Err(notesErr).Str("sha", *payload.Sha).Msg("error generating release notes")
+		}
+
+		//Productive releases rewrite the version files and the changelog before tagging, so the tag contains them
+		if *build.Type == "productive" {
+			if commitErr := s.CommitReleaseFiles(ctx, config, pRequest, build, releaseNotes, notesErr); commitErr != nil {
+				return nil, commitErr
+			}
 		}
 
 		//Release Tag Name
@@ -88,17 +165,35 @@ func (s *Build) ProcessBuild(config *models.Configuration, payload *webhook.Stat
 			tagName = tagName + "-" + *build.Tag
 		}
 
+		//Creates the github release
+		createGHReleaseErr := s.GithubClient.CreateRelease(ctx, config, build)
+
+		if createGHReleaseErr != nil {
+			//The release could have been created by a processing interrupted before saving the build
+			release, getReleaseErr := s.GithubClient.GetReleaseByTag(ctx, config, tagName)
+
+			if getReleaseErr != nil {
+				return nil, createGHReleaseErr
+			}
+
+			logger.FromContext(ctx).Warn().Str("tag", tagName).Msg("github release already created, resuming the build")
+
+			if release.TargetCommitish != "" {
+				build.Sha = utils.Stringify(release.TargetCommitish)
+			}
+		}
+
 		build.GithubURL = utils.Stringify(tagName)
 
 		//Save Build into db
-		saveBuildErr := s.SaveBuild(build)
+		saveBuildErr := s.SaveBuild(ctx, build)
 
 		if saveBuildErr != nil {
 			return nil, saveBuildErr
 		}
 
 		//Save latest Build into db
-		saveLatestErr := s.CreateAndSaveLatestBuild(build, lastBuild)
+		saveLatestErr := s.CreateAndSaveLatestBuild(ctx, build, lastBuild)
 
 		if saveLatestErr != nil {
 			return nil, err
@@ -106,11 +201,10 @@ func (s *Build) ProcessBuild(config *models.Configuration, payload *webhook.Stat
 
 		//Send the issue comment to Pull request
 		issueCommentBody := s.GetIssueCommentBody(build)
-		sendIssuecommentErr := s.GithubClient.CreateIssueComment(config, pRequest, issueCommentBody)
+		sendIssuecommentErr := s.GithubClient.CreateIssueComment(ctx, config, pRequest, issueCommentBody)
 
 		if sendIssuecommentErr != nil {
-			log.Error().Err(sendIssuecommentErr).Str("sha", *payload.Sha).
-				Str("repository", *payload.Repository.FullName).Msg("error creating new issue comment")
+			logger.FromContext(ctx).Error().Err(sendIssuecommentErr).Str("sha", *payload.Sha).Msg("error creating new issue comment")
 		}
 
 		return build, nil
@@ -119,7 +213,49 @@ func (s *Build) ProcessBuild(config *models.Configuration, payload *webhook.Stat
 	return nil, apierrors.NewApiError("They have not yet passed all the quality controls necessary to create a new version.", "error", 206, apierrors.CauseList{})
 }
 
-func (s *Build) CheckBuildability(reqSCConfigured []string, payload *webhook.Status) bool {
+//CommitReleaseFiles commits the version files and the changelog section of the release into the pull request head branch,
+//all of them in a single commit whose sha is the one tagged.
+//A release missing any of them is not created, only the changelog skipped on purpose is left out.
+func (s *Build) CommitReleaseFiles(ctx context.Context, config *models.Configuration, pRequest *models.PullRequest, build *models.Build, releaseNotes *models.ReleaseNotes, notesErr apierrors.ApiError) apierrors.ApiError {
+	ctx, span := tracing.Start(ctx, "Build.CommitReleaseFiles")
+	defer span.End()
+
+	files := make([]models.TreeEntry, 0)
+
+	if IsChangelogEnabled(config) {
+		//The changelog section is made of the release notes
+		if notesErr != nil {
+			return notesErr
+		}
+
+		changelog, changelogErr := s.ChangelogService.Prepend(ctx, config, pRequest, *build.Sha, releaseNotes, *build.Body)
+
+		switch {
+		case changelogErr != nil && changelogErr.Status() == http.StatusPartialContent:
+			logger.FromContext(ctx).Info().Str("sha", *build.Sha).Str("reason", changelogErr.Message()).Msg("changelog not updated")
+		case changelogErr != nil:
+			logger.FromContext(ctx).Error().Err(changelogErr).Str("sha", *build.Sha).Msg("error updating changelog")
+			return changelogErr
+		case changelog != nil:
+			files = append(files, *changelog)
+		}
+	}
+
+	sha, bumpErr := s.VersionFilesService.Bump(ctx, config, pRequest, build, files...)
+
+	if bumpErr != nil {
+		logger.FromContext(ctx).Error().Err(bumpErr).Str("sha", *build.Sha).Msg("error bumping version files")
+		return bumpErr
+	}
+
+	build.Sha = utils.Stringify(sha)
+
+	return nil
+}
+
+func (s *Build) CheckBuildability(ctx context.Context, reqSCConfigured []string, payload *webhook.Status) bool {
+	ctx, span := tracing.Start(ctx, "Build.CheckBuildability")
+	defer span.End()
 
 	//TODO: podriamos mejorar esto y buscar 1 todos los status
 
@@ -134,7 +270,7 @@ func (s *Build) CheckBuildability(reqSCConfigured []string, payload *webhook.Sta
 		statusWebhookID := utils.Stringify(utils.GetMD5Hash(shBaseID))
 
 		//Get from db all status from repository and sha
-		if err := s.SQL.GetBy(&webhook, "id = ?", statusWebhookID); err != nil {
+		if err := s.SQL.GetBy(ctx, &webhook, "id = ?", statusWebhookID); err != nil {
 			if err != gorm.ErrRecordNotFound {
 				return false
 			}
@@ -145,6 +281,31 @@ func (s *Build) CheckBuildability(reqSCConfigured []string, payload *webhook.Sta
 	return true
 }
 
+//GetStatusChecksState returns, for every required status check, if it was already satisfied for the payload sha.
+func (s *Build) GetStatusChecksState(ctx context.Context, reqSCConfigured []string, payload *webhook.Status) map[string]bool {
+	ctx, span := tracing.Start(ctx, "Build.GetStatusChecksState")
+	defer span.End()
+
+	states := make(map[string]bool)
+
+	for _, reqSCheck := range reqSCConfigured {
+		var webhook webhook.Webhook
+
+		state := statusWebhookSuccessState
+		if reqSCheck == "pull_request_review" {
+			state = approvedPullRequestReviewState
+		}
+
+		//Build a ID to identify a unique webhook
+		shBaseID := *payload.Repository.FullName + *payload.Sha + reqSCheck + state
+		statusWebhookID := utils.Stringify(utils.GetMD5Hash(shBaseID))
+
+		states[reqSCheck] = s.SQL.GetBy(ctx, &webhook, "id = ?", statusWebhookID) == nil
+	}
+
+	return states
+}
+
 func (s *Build) GetBuildeableStatusChecks(config *models.Configuration) []string {
 
 	configuredReqStatusChecks := config.GetRequiredStatusCheck()
@@ -158,7 +319,9 @@ func (s *Build) GetBuildeableStatusChecks(config *models.Configuration) []string
 //Gets the latest build created
 //When the repository has no versions created, we create a default
 //TODO: Add retries
-func (s *Build) GetLatestBuild(config *models.Configuration) *semver.Version {
+func (s *Build) GetLatestBuild(ctx context.Context, config *models.Configuration) *semver.Version {
+	ctx, span := tracing.Start(ctx, "Build.GetLatestBuild")
+	defer span.End()
 
 	var build models.Build
 	var latestBuild models.LatestBuild
@@ -166,9 +329,9 @@ func (s *Build) GetLatestBuild(config *models.Configuration) *semver.Version {
 	var semverBuild semver.Version
 
 	//get the last build generated for the repository
-	err := s.SQL.GetBy(&latestBuild, "repository_name = ?", config.ID)
+	err := s.SQL.GetBy(ctx, &latestBuild, "repository_name = ?", config.ID)
 	if err == nil {
-		if err := s.SQL.GetBy(&build, "id = ?", latestBuild.BuildID); err != nil {
+		if err := s.SQL.GetBy(ctx, &build, "id = ?", latestBuild.BuildID); err != nil {
 			createInitialDefaultBuild = true
 		}
 	} else {
@@ -177,6 +340,8 @@ func (s *Build) GetLatestBuild(config *models.Configuration) *semver.Version {
 
 	if createInitialDefaultBuild {
 		build = *s.CreateInitialBuild(config)
+	} else {
+		build = s.skipRolledBackVersions(ctx, config, build)
 	}
 
 	//Build Semver Version
@@ -188,6 +353,35 @@ func (s *Build) GetLatestBuild(config *models.Configuration) *semver.Version {
 	return &semverBuild
 }
 
+//skipRolledBackVersions returns the build with the highest version between the given one and the newer rolled back builds.
+//After a rollback the latest build points to an older build, so the rolled back versions must not be reused.
+func (s *Build) skipRolledBackVersions(ctx context.Context, config *models.Configuration, build models.Build) models.Build {
+
+	rolledBack := make([]models.Build, 0)
+
+	if err := s.SQL.GetBy(ctx, &rolledBack, "repository_name = ? AND status = ? AND id > ?", config.ID, models.BuildStatusRolledBack, build.ID); err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("error getting rolled back builds")
+		return build
+	}
+
+	highest := build
+	for _, rb := range rolledBack {
+		if newBuildSemVer(&highest).LessThan(*newBuildSemVer(&rb)) {
+			highest = rb
+		}
+	}
+
+	return highest
+}
+
+func newBuildSemVer(build *models.Build) *semver.Version {
+	return &semver.Version{
+		Major: int64(build.Major),
+		Minor: int64(build.Minor),
+		Patch: int64(build.Patch),
+	}
+}
+
 func (s *Build) CreateInitialBuild(config *models.Configuration) *models.Build {
 
 	now := time.Now()
@@ -206,11 +400,13 @@ func (s *Build) CreateInitialBuild(config *models.Configuration) *models.Build {
 	return &build
 }
 
-func (s *Build) GetPullRequestBySha(sha string) (*models.PullRequest, apierrors.ApiError) {
+func (s *Build) GetPullRequestBySha(ctx context.Context, sha string) (*models.PullRequest, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "Build.GetPullRequestBySha")
+	defer span.End()
 
 	var pr models.PullRequest
 	//Get from db all status from repository and sha
-	if err := s.SQL.GetBy(&pr, "head_sha = ?", sha); err != nil {
+	if err := s.SQL.GetBy(ctx, &pr, "head_sha = ?", sha); err != nil {
 		if err != gorm.ErrRecordNotFound {
 			return nil, apierrors.NewInternalServerApiError("error getting pull request", err)
 		}
@@ -220,6 +416,178 @@ func (s *Build) GetPullRequestBySha(sha string) (*models.PullRequest, apierrors.
 	return &pr, nil
 }
 
+//IsReleased checks if a build was already created for the sha,
+//either the pull request head sha or the version files commit made on top of it.
+func (s *Build) IsReleased(ctx context.Context, sha string) bool {
+	ctx, span := tracing.Start(ctx, "Build.IsReleased")
+	defer span.End()
+
+	var build models.Build
+
+	if err := s.SQL.GetBy(ctx, &build, "sha = ? OR source_sha = ?", sha, sha); err != nil {
+		if err != gorm.ErrRecordNotFound {
+			logger.FromContext(ctx).Error().Err(err).Str("sha", sha).Msg("error getting sha build")
+		}
+		return false
+	}
+
+	return true
+}
+
+//GetReleaseOverride gets the release decisions taken over the pull request through ChatOps commands.
+//Returns nil when there are no decisions taken.
+func (s *Build) GetReleaseOverride(ctx context.Context, pr *models.PullRequest) *models.ReleaseOverride {
+	ctx, span := tracing.Start(ctx, "Build.GetReleaseOverride")
+	defer span.End()
+
+	var override models.ReleaseOverride
+
+	if err := s.SQL.GetBy(ctx, &override, "pull_request_id = ?", pr.ID); err != nil {
+		if err != gorm.ErrRecordNotFound {
+			logger.FromContext(ctx).Error().Err(err).Int64("pull_request_id", pr.ID).Msg("error getting release override")
+		}
+		return nil
+	}
+
+	return &override
+}
+
+//CheckFreeze refuses the productive release of the pull request when the repository is inside a freeze window.
+//The refusal is reported to the pull request with a commit status, and with a comment once per freeze window.
+//An emergency override taken through ChatOps allows the release, and it is audited.
+//The status is reset once the release is allowed again.
+func (s *Build) CheckFreeze(ctx context.Context, config *models.Configuration, pr *models.PullRequest, override *models.ReleaseOverride) apierrors.ApiError {
+	ctx, span := tracing.Start(ctx, "Build.CheckFreeze")
+	defer span.End()
+
+	window, until, err := s.FreezeWindowService.GetActive(ctx, *config.ID, time.Now())
+
+	if err != nil {
+		return err
+	}
+
+	if window == nil {
+		s.ResetFreezeStatus(ctx, config, pr, "The release freeze is over")
+		return nil
+	}
+
+	if override != nil && override.FreezeOverrideReason != nil {
+		actor := ""
+		if override.UpdatedBy != nil {
+			actor = *override.UpdatedBy
+		}
+		s.FreezeWindowService.RecordOverride(ctx, *config.ID, window, actor, *override.FreezeOverrideReason, pr.HeadRef, pr.HeadSha)
+		s.ResetFreezeStatus(ctx, config, pr, "The release freeze was overridden")
+		return nil
+	}
+
+	description := fmt.Sprintf("Productive releases are frozen by %s until %s", *window.Name, until.Format(freezeDateLayout))
+
+	if statusErr := s.createFreezeStatus(ctx, config, pr.HeadSha, "failure", description); statusErr != nil {
+		logger.FromContext(ctx).Error().Err(statusErr).Str("sha", *pr.HeadSha).Msg("error creating freeze status")
+	}
+
+	notice := s.getFreezeNotice(ctx, pr.ID)
+	reported := notice != nil && notice.IsReported(window, until)
+
+	if notice == nil {
+		notice = &models.FreezeNotice{PullRequestID: pr.ID}
+	}
+	notice.RepositoryName = config.ID
+	notice.PullRequestNumber = pr.PullRequestNumber
+	notice.Sha = pr.HeadSha
+	notice.FreezeWindowID = window.ID
+	notice.Until = until
+
+	if !reported {
+		body := "# Release freeze :snowflake: \n" + "\n" +
+			fmt.Sprintf("> **Status:** **blocked** :red_circle:\n\n%s.\n\n", description) +
+			"Use `/ci-cd retry-release` once the freeze is over, or `/ci-cd freeze-override <reason>` for an emergency release."
+
+		if commentErr := s.GithubClient.CreateIssueComment(ctx, config, pr, body); commentErr != nil {
+			logger.FromContext(ctx).Error().Err(commentErr).Int("pull_request", pr.PullRequestNumber).Msg("error reporting release freeze")
+		}
+	}
+
+	if sqlErr := s.SQL.Update(ctx, notice); sqlErr != nil {
+		logger.FromContext(ctx).Error().Err(sqlErr).Int64("pull_request_id", pr.ID).Msg("error saving freeze notice")
+	}
+
+	return apierrors.NewApiError(fmt.Sprintf("The release is blocked by the freeze window %s.", *window.Name), "skipped", 206, apierrors.CauseList{})
+}
+
+//ResetFreezeStatus sets the freeze status reported to the pull request back to success.
+//Nothing is done when the release of the pull request was not blocked by a freeze window.
+func (s *Build) ResetFreezeStatus(ctx context.Context, config *models.Configuration, pr *models.PullRequest, description string) {
+	ctx, span := tracing.Start(ctx, "Build.ResetFreezeStatus")
+	defer span.End()
+
+	if notice := s.getFreezeNotice(ctx, pr.ID); notice != nil {
+		s.resetFreezeNotice(ctx, config, notice, description)
+	}
+}
+
+//ResetEndedFreezes sets the freeze statuses reported to the pull requests back to success once their freeze windows ended.
+func (s *Build) ResetEndedFreezes(ctx context.Context, now time.Time) {
+	ctx, span := tracing.Start(ctx, "Build.ResetEndedFreezes")
+	defer span.End()
+
+	notices := make([]models.FreezeNotice, 0)
+
+	if err := s.SQL.GetBy(ctx, &notices, "until <= ?", now); err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("error getting ended freeze notices")
+		return
+	}
+
+	for i := range notices {
+		var config models.Configuration
+
+		if err := s.SQL.GetBy(ctx, &config, "id = ?", *notices[i].RepositoryName); err != nil {
+			logger.FromContext(ctx).Error().Err(err).Str("repository", *notices[i].RepositoryName).Msg("error getting configuration")
+			continue
+		}
+
+		s.resetFreezeNotice(ctx, &config, &notices[i], "The release freeze is over")
+	}
+}
+
+//resetFreezeNotice sets the freeze status of the notice sha back to success and deletes the notice.
+//The notice is kept when the status can not be created, so it is retried later.
+func (s *Build) resetFreezeNotice(ctx context.Context, config *models.Configuration, notice *models.FreezeNotice, description string) {
+
+	if err := s.createFreezeStatus(ctx, config, notice.Sha, "success", description); err != nil {
+		logger.FromContext(ctx).Error().Err(err).Str("sha", *notice.Sha).Msg("error resetting freeze status")
+		return
+	}
+
+	if err := s.SQL.Delete(ctx, notice); err != nil {
+		logger.FromContext(ctx).Error().Err(err).Int64("pull_request_id", notice.PullRequestID).Msg("error deleting freeze notice")
+	}
+}
+
+func (s *Build) createFreezeStatus(ctx context.Context, config *models.Configuration, sha *string, state string, description string) apierrors.ApiError {
+	var statusWH webhook.Status
+	statusWH.Sha = sha
+	statusWH.State = utils.Stringify(state)
+	statusWH.Context = utils.Stringify(freezeStatusContext)
+	statusWH.Description = utils.Stringify(description)
+
+	return s.GithubClient.CreateStatus(ctx, config, &statusWH)
+}
+
+func (s *Build) getFreezeNotice(ctx context.Context, pullRequestID int64) *models.FreezeNotice {
+	var notice models.FreezeNotice
+
+	if err := s.SQL.GetBy(ctx, &notice, "pull_request_id = ?", pullRequestID); err != nil {
+		if err != gorm.ErrRecordNotFound {
+			logger.FromContext(ctx).Error().Err(err).Int64("pull_request_id", pullRequestID).Msg("error getting freeze notice")
+		}
+		return nil
+	}
+
+	return &notice
+}
+
 func (s *Build) GetIncrementerAndType(pr *models.PullRequest) (incrementer string, buildType string) {
 
 	switch *pr.BaseRef {
@@ -282,6 +650,7 @@ func (s *Build) CreateBuild(pullRequest *models.PullRequest, newSemVer semver.Ve
 	build.Patch = uint16(newSemVer.Patch)
 	build.Status = utils.Stringify(initialBuildStatus)
 	build.Sha = pullRequest.HeadSha
+	build.SourceSha = pullRequest.HeadSha
 	build.Type = utils.Stringify(buildType)
 	build.RepositoryName = pullRequest.RepositoryName
 	build.UpdatedAt = utils.Stringify(time.Now().Format("2006-01-02 15:04:05"))
@@ -293,18 +662,27 @@ func (s *Build) CreateBuild(pullRequest *models.PullRequest, newSemVer semver.Ve
 	return &build
 }
 
-func (s *Build) SaveBuild(build *models.Build) apierrors.ApiError {
+func (s *Build) SaveBuild(ctx context.Context, build *models.Build) apierrors.ApiError {
+	ctx, span := tracing.Start(ctx, "Build.SaveBuild")
+	defer span.End()
+
 	//Save it into build table
-	if err := s.SQL.Insert(&build); err != nil {
+	if err := s.SQL.Insert(ctx, &build); err != nil {
 		return apierrors.NewInternalServerApiError("something was wrong inserting new build", err)
 	}
+
+	if build.Type != nil {
+		metrics.BuildsCreated.WithLabelValues(*build.Type).Inc()
+	}
 	return nil
 }
 
 
 
 
-func (s *Build) CreateAndSaveLatestBuild(build *models.Build, lastBuild *semver.Version) apierrors.ApiError {
+func (s *Build) CreateAndSaveLatestBuild(ctx context.Context, build *models.Build, lastBuild *semver.Version) apierrors.ApiError {
+	ctx, span := tracing.Start(ctx, "Build.CreateAndSaveLatestBuild")
+	defer span.End()
 
 	var latestBuild models.LatestBuild
 	latestBuildID, _ := strconv.Atoi(lastBuild.Metadata)
@@ -314,18 +692,85 @@ func (s *Build) CreateAndSaveLatestBuild(build *models.Build, lastBuild *semver.
 
 	//TODO: FIX this please
 	//Delete from configurations DB
-	if sqlErr := s.SQL.Delete(&latestBuild); sqlErr != nil {
+	if sqlErr := s.SQL.Delete(ctx, &latestBuild); sqlErr != nil {
 		return apierrors.NewInternalServerApiError("something was wrong deleting repo latest build", sqlErr)
 	}
 
 	//Save it into latestBuild Table
-	if err := s.SQL.Update(&latestBuild); err != nil {
+	if err := s.SQL.Update(ctx, &latestBuild); err != nil {
 		return apierrors.NewInternalServerApiError("something was wrong updating repo latest build", err)
 	}
 
 	return nil
 }
 
+//IsBackMerge checks if the pull request brings master changes back into develop.
+func IsBackMerge(pr *models.PullRequest) bool {
+	return pr.BaseRef != nil && pr.HeadRef != nil && *pr.BaseRef == backMergeBase && *pr.HeadRef == backMergeHead
+}
+
+//NeedsBackMerge checks if the pull request is a gitflow release or hotfix merged into master.
+func NeedsBackMerge(config *models.Configuration, pr *models.PullRequest) bool {
+	if config.WorkflowType == nil || *config.WorkflowType != "gitflow" {
+		return false
+	}
+
+	if pr.BaseRef == nil || pr.HeadRef == nil || *pr.BaseRef != backMergeHead {
+		return false
+	}
+
+	return strings.HasPrefix(*pr.HeadRef, "release/") || strings.HasPrefix(*pr.HeadRef, "hotfix/")
+}
+
+//BackMerge opens the pull request that brings master changes back into develop
+//and merges it when the configuration asks for it.
+//It must run once the pull request is merged, so master has the released changes.
+//The outcome is reported on the original pull request.
+func (s *Build) BackMerge(ctx context.Context, config *models.Configuration, pr *models.PullRequest) {
+	ctx, span := tracing.Start(ctx, "Build.BackMerge")
+	defer span.End()
+
+	title := fmt.Sprintf("Back-merge %s into %s", *pr.HeadRef, backMergeBase)
+	body := fmt.Sprintf("Brings the changes released in #%d back into %s.\n\n%s", pr.PullRequestNumber, backMergeBase, automaticBuildBody)
+
+	backMergePR, err := s.GithubClient.CreatePullRequest(ctx, config, backMergeHead, backMergeBase, title, body)
+
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Int("pull_request", pr.PullRequestNumber).Msg("error creating back-merge pull request")
+		s.reportBackMerge(ctx, config, pr, fmt.Sprintf("> **Status:** **error** :red_circle:\n\nThe back-merge pull request from %s into %s could not be created: %s", backMergeHead, backMergeBase, err.Message()))
+		return
+	}
+
+	conflicted := backMergePR.Mergeable != nil && !*backMergePR.Mergeable
+
+	if !conflicted && config.BackMergeAutoMerge != nil && *config.BackMergeAutoMerge {
+		mergeErr := s.GithubClient.MergePullRequest(ctx, config, backMergePR.Number, backMergeMethod, "")
+
+		if mergeErr == nil {
+			s.reportBackMerge(ctx, config, pr, fmt.Sprintf("> **Status:** **merged** :white_check_mark:\n\n%s was merged back into %s in #%d", backMergeHead, backMergeBase, backMergePR.Number))
+			return
+		}
+
+		logger.FromContext(ctx).Error().Err(mergeErr).Int("pull_request", backMergePR.Number).Msg("error merging back-merge pull request")
+		conflicted = mergeErr.Status() == http.StatusConflict
+	}
+
+	if conflicted {
+		s.reportBackMerge(ctx, config, pr, fmt.Sprintf("> **Status:** **conflicts** :red_circle:\n\nThe back-merge pull request #%d has conflicts with %s and must be resolved manually.", backMergePR.Number, backMergeBase))
+		return
+	}
+
+	s.reportBackMerge(ctx, config, pr, fmt.Sprintf("> **Status:** **pending** :clock8:\n\nThe back-merge pull request #%d was opened and is waiting to be merged.", backMergePR.Number))
+}
+
+func (s *Build) reportBackMerge(ctx context.Context, config *models.Configuration, pr *models.PullRequest, report string) {
+	body := "# Back-merge report \n" + "\n" + report
+
+	if err := s.GithubClient.CreateIssueComment(ctx, config, pr, body); err != nil {
+		logger.FromContext(ctx).Error().Err(err).Int("pull_request", pr.PullRequestNumber).Msg("error reporting back-merge")
+	}
+}
+
 func (s *Build) GetIssueCommentBody(build *models.Build) string {
 	var body string
 	var emoji string
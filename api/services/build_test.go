@@ -1,7 +1,9 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"github.com/coreos/go-semver/semver"
 	"github.com/golang/mock/gomock"
 	"github.com/hbalmes/ci_cd-api/api/mocks/interfaces"
@@ -176,15 +178,15 @@ func TestBuild_ProcessBuild(t *testing.T) {
 			sqlStorage := interfaces.NewMockSQLStorage(ctrl)
 
 			checkBuildability := sqlStorage.EXPECT().
-				GetBy(gomock.Any(), gomock.Any(), gomock.Any()).
-				DoAndReturn(func(e interface{}, qry ...interface{}) *webhook.Webhook {
+				GetBy(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, e interface{}, qry ...interface{}) *webhook.Webhook {
 					return &webhookOK
 				}).
 				Return(tt.expects.sqlGetByError).MaxTimes(tt.args.getSCTimes)
 
 			getLatestBuilds := sqlStorage.EXPECT().
-				GetBy(gomock.Any(), gomock.Any(), gomock.Any()).
-				DoAndReturn(func(e interface{}, qry ...interface{}) *models.LatestBuild {
+				GetBy(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, e interface{}, qry ...interface{}) *models.LatestBuild {
 					return &latestBuild
 				}).
 				Return(tt.expects.sqlGetLatestErr).
@@ -192,8 +194,8 @@ func TestBuild_ProcessBuild(t *testing.T) {
 				MaxTimes(tt.args.getLastBuildTimes)
 
 			getBuilds := sqlStorage.EXPECT().
-				GetBy(gomock.Any(), gomock.Any(), gomock.Any()).
-				DoAndReturn(func(e interface{}, qry ...interface{}) *models.Build {
+				GetBy(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, e interface{}, qry ...interface{}) *models.Build {
 					return &buildOK
 				}).
 				Return(tt.expects.sqlGetBuildErr).
@@ -201,8 +203,13 @@ func TestBuild_ProcessBuild(t *testing.T) {
 				MaxTimes(tt.args.getBuildsTimes)
 
 			sqlStorage.EXPECT().
-				GetBy(gomock.Any(), gomock.Any(), gomock.Any()).
-				DoAndReturn(func(e interface{}, qry ...interface{}) *models.PullRequest {
+				GetBy(gomock.Any(), gomock.Any(), "repository_name = ? AND status = ? AND id > ?", gomock.Any(), models.BuildStatusRolledBack, gomock.Any()).
+				Return(nil).
+				MaxTimes(tt.args.getBuildsTimes)
+
+			sqlStorage.EXPECT().
+				GetBy(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, e interface{}, qry ...interface{}) *models.PullRequest {
 					return &pullr
 				}).
 				Return(tt.expects.sqlGetPRErr).
@@ -212,7 +219,7 @@ func TestBuild_ProcessBuild(t *testing.T) {
 			s := &Build{
 				SQL: sqlStorage,
 			}
-			build, buildErr := s.ProcessBuild(tt.args.config, tt.args.payload)
+			build, buildErr := s.ProcessBuild(context.Background(), tt.args.config, tt.args.payload)
 			if !reflect.DeepEqual(build, tt.expects.build) {
 				t.Errorf("ProcessBuild() got = %v, want %v", build, tt.expects.build)
 			}
@@ -516,16 +523,16 @@ func TestBuild_GetLatestBuild(t *testing.T) {
 
 			gomock.InOrder(
 				sqlStorage.EXPECT().
-					GetBy(gomock.Any(), gomock.Any(), gomock.Any()).
-					DoAndReturn(func(e interface{}, qry ...interface{}) *models.LatestBuild {
+					GetBy(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, e interface{}, qry ...interface{}) *models.LatestBuild {
 						return &latestBuild
 					}).
 					Return(tt.expects.sqlGetLatestErr).
 					Times(1),
 
 				sqlStorage.EXPECT().
-					GetBy(gomock.Any(), gomock.Any(), gomock.Any()).
-					DoAndReturn(func(e interface{}, qry ...interface{}) *models.Build {
+					GetBy(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, e interface{}, qry ...interface{}) *models.Build {
 						return &buildOK
 					}).
 					Return(tt.expects.sqlGetBuildErr).
@@ -535,13 +542,77 @@ func TestBuild_GetLatestBuild(t *testing.T) {
 			s := &Build{
 				SQL: sqlStorage,
 			}
-			if got := s.GetLatestBuild(tt.args.config); !reflect.DeepEqual(got, tt.expects.versionRes) {
+			if got := s.GetLatestBuild(context.Background(), tt.args.config); !reflect.DeepEqual(got, tt.expects.versionRes) {
 				t.Errorf("GetLatestBuild() = %v, want %v", got, tt.expects.versionRes)
 			}
 		})
 	}
 }
 
+func TestBuild_GetLatestBuildSkipsRolledBackVersions(t *testing.T) {
+	tests := []struct {
+		name       string
+		rolledBack []models.Build
+		getErr     error
+		want       *semver.Version
+	}{
+		{
+			name: "without rolled back builds",
+			want: &semver.Version{Major: 1, Minor: 2, Metadata: "3"},
+		},
+		{
+			name:   "error getting rolled back builds",
+			getErr: gorm.ErrInvalidSQL,
+			want:   &semver.Version{Major: 1, Minor: 2, Metadata: "3"},
+		},
+		{
+			name:       "rolled back builds newer than the latest one",
+			rolledBack: []models.Build{{ID: 11, Major: 1, Minor: 4}, {ID: 12, Major: 1, Minor: 3}},
+			want:       &semver.Version{Major: 1, Minor: 4, Metadata: "3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+
+			config := models.Configuration{ID: utils.Stringify("hbalmes/ci-cd_api")}
+
+			sqlStorage.EXPECT().
+				GetBy(gomock.Any(), gomock.Any(), "repository_name = ?", config.ID).
+				DoAndReturn(func(ctx context.Context, latest *models.LatestBuild, qry ...interface{}) error {
+					*latest = models.LatestBuild{ID: 3, BuildID: 10}
+					return nil
+				}).
+				Times(1)
+
+			sqlStorage.EXPECT().
+				GetBy(gomock.Any(), gomock.Any(), "id = ?", uint32(10)).
+				DoAndReturn(func(ctx context.Context, build *models.Build, qry ...interface{}) error {
+					*build = models.Build{ID: 10, Major: 1, Minor: 2, Status: utils.Stringify("finished")}
+					return nil
+				}).
+				Times(1)
+
+			sqlStorage.EXPECT().
+				GetBy(gomock.Any(), gomock.Any(), "repository_name = ? AND status = ? AND id > ?", config.ID, models.BuildStatusRolledBack, uint32(10)).
+				DoAndReturn(func(ctx context.Context, builds *[]models.Build, qry ...interface{}) error {
+					*builds = tt.rolledBack
+					return tt.getErr
+				}).
+				Times(1)
+
+			s := &Build{
+				SQL: sqlStorage,
+			}
+
+			assert.Equal(t, tt.want, s.GetLatestBuild(context.Background(), &config))
+		})
+	}
+}
+
 func TestBuild_GetIncrementerAndType(t *testing.T) {
 
 	type args struct {
@@ -750,14 +821,14 @@ func TestBuild_GetPullRequestBySha(t *testing.T) {
 			}
 
 			sqlStorage.EXPECT().
-				GetBy(gomock.Any(), gomock.Any(), gomock.Any()).
-				DoAndReturn(func(e interface{}, qry ...interface{}) models.PullRequest {
+				GetBy(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, e interface{}, qry ...interface{}) models.PullRequest {
 					return *tt.expects.wantPullRequestWebhook
 				}).
 				Return(tt.expects.sqlGetErr).
 				AnyTimes()
 
-			_, gotApiError := s.GetPullRequestBySha(tt.args.sha)
+			_, gotApiError := s.GetPullRequestBySha(context.Background(), tt.args.sha)
 			if !reflect.DeepEqual(gotApiError, tt.expects.wantApiError) {
 				t.Errorf("GetPullRequestBySha() gotApiError = %v, want %v", gotApiError, tt.expects.wantApiError)
 			}
@@ -765,6 +836,52 @@ func TestBuild_GetPullRequestBySha(t *testing.T) {
 	}
 }
 
+func TestBuild_IsReleased(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		sqlGetErr error
+		want      bool
+	}{
+		{
+			name:      "error getting the sha build",
+			sqlGetErr: gorm.ErrCantStartTransaction,
+			want:      false,
+		},
+		{
+			name:      "sha not released",
+			sqlGetErr: gorm.ErrRecordNotFound,
+			want:      false,
+		},
+		{
+			name:      "sha already released",
+			sqlGetErr: nil,
+			want:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+
+			s := &Build{
+				SQL: sqlStorage,
+			}
+
+			sqlStorage.EXPECT().
+				GetBy(gomock.Any(), gomock.Any(), "sha = ? OR source_sha = ?", "1234567wertyasdfghzxcvb", "1234567wertyasdfghzxcvb").
+				Return(tt.sqlGetErr).
+				Times(1)
+
+			if got := s.IsReleased(context.Background(), "1234567wertyasdfghzxcvb"); got != tt.want {
+				t.Errorf("IsReleased() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestBuild_CreateBuild(t *testing.T) {
 
 	type args struct {
@@ -837,6 +954,7 @@ func TestBuild_CreateBuild(t *testing.T) {
 			got := s.CreateBuild(tt.args.pullRequest, tt.args.newSemVer, tt.args.buildType)
 
 			if got != nil {
+				assert.Equal(t, tt.args.pullRequest.HeadSha, got.SourceSha)
 				assert.Equal(t, utils.Stringify("123456789asdfghjkqwertyu"), tt.expects.wantBuild.Sha)
 				assert.Equal(t, utils.Stringify("pending"), tt.expects.wantBuild.Status)
 				assert.Equal(t, utils.Stringify("hbalmes"), tt.expects.wantBuild.Username)
@@ -911,7 +1029,7 @@ func TestBuild_SaveBuild(t *testing.T) {
 			sqlStorage := interfaces.NewMockSQLStorage(ctrl)
 
 			sqlStorage.EXPECT().
-				Insert(gomock.Any()).
+				Insert(gomock.Any(), gomock.Any()).
 				Return(tt.expects.sqlInsertError).
 				AnyTimes()
 
@@ -919,7 +1037,7 @@ func TestBuild_SaveBuild(t *testing.T) {
 				SQL: sqlStorage,
 			}
 
-			got := s.SaveBuild(tt.args.build)
+			got := s.SaveBuild(context.Background(), tt.args.build)
 
 			if !reflect.DeepEqual(got, tt.expects.wantApiErr) {
 				t.Errorf("CreateAndSaveBuild() got1 = %v, want %v", got, tt.expects.wantApiErr)
@@ -1009,12 +1127,12 @@ func TestBuild_CreateAndSaveLatestBuild(t *testing.T) {
 			sqlStorage := interfaces.NewMockSQLStorage(ctrl)
 
 			sqlStorage.EXPECT().
-				Delete(gomock.Any()).
+				Delete(gomock.Any(), gomock.Any()).
 				Return(tt.expects.sqlDeleteError).
 				AnyTimes()
 
 			sqlStorage.EXPECT().
-				Update(gomock.Any()).
+				Update(gomock.Any(), gomock.Any()).
 				Return(tt.expects.sqlUpdateError).
 				AnyTimes()
 
@@ -1022,7 +1140,7 @@ func TestBuild_CreateAndSaveLatestBuild(t *testing.T) {
 				SQL: sqlStorage,
 			}
 
-			if got := s.CreateAndSaveLatestBuild(tt.args.build, &tt.args.lastBuild); !reflect.DeepEqual(got, tt.expects.wantApiErr) {
+			if got := s.CreateAndSaveLatestBuild(context.Background(), tt.args.build, &tt.args.lastBuild); !reflect.DeepEqual(got, tt.expects.wantApiErr) {
 				t.Errorf("CreateAndSaveLatestBuild() = %v, want %v", got, tt.expects.wantApiErr)
 			}
 		})
@@ -1126,3 +1244,536 @@ func TestBuild_getIssueCommentBody(t *testing.T) {
 		})
 	}
 }
+
+func TestNeedsBackMerge(t *testing.T) {
+	gitflow := models.Configuration{WorkflowType: utils.Stringify("gitflow")}
+	other := models.Configuration{WorkflowType: utils.Stringify("trunk")}
+
+	tests := []struct {
+		name   string
+		config *models.Configuration
+		base   string
+		head   string
+		want   bool
+	}{
+		{name: "release into master", config: &gitflow, base: "master", head: "release/1.2", want: true},
+		{name: "hotfix into master", config: &gitflow, base: "master", head: "hotfix/crash", want: true},
+		{name: "feature into develop", config: &gitflow, base: "develop", head: "feature/login", want: false},
+		{name: "back-merge", config: &gitflow, base: "develop", head: "master", want: false},
+		{name: "not gitflow", config: &other, base: "master", head: "release/1.2", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pr := models.PullRequest{BaseRef: utils.Stringify(tt.base), HeadRef: utils.Stringify(tt.head)}
+			assert.Equal(t, tt.want, NeedsBackMerge(tt.config, &pr))
+			assert.Equal(t, tt.base == "develop" && tt.head == "master", IsBackMerge(&pr))
+		})
+	}
+}
+
+func TestBuild_BackMerge(t *testing.T) {
+	type expects struct {
+		createResp   *models.PullRequestResponse
+		createErr    apierrors.ApiError
+		mergeErr     apierrors.ApiError
+		mergeTimes   int
+		reportStatus string
+	}
+
+	autoMerge := true
+	notMergeable := false
+
+	config := models.Configuration{
+		ID:                 utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:     utils.Stringify("ci-cd_api"),
+		RepositoryOwner:    utils.Stringify("hbalmes"),
+		WorkflowType:       utils.Stringify("gitflow"),
+		BackMergeAutoMerge: &autoMerge,
+	}
+
+	manualConfig := config
+	manualConfig.BackMergeAutoMerge = nil
+
+	pr := models.PullRequest{
+		PullRequestNumber: 12,
+		BaseRef:           utils.Stringify("master"),
+		HeadRef:           utils.Stringify("release/1.2"),
+	}
+
+	tests := []struct {
+		name    string
+		config  *models.Configuration
+		expects expects
+	}{
+		{
+			name:   "error creating back-merge pull request",
+			config: &config,
+			expects: expects{
+				createErr:    apierrors.NewBadRequestApiError("pull request not created"),
+				reportStatus: "**error**",
+			},
+		},
+		{
+			name:   "back-merge merged",
+			config: &config,
+			expects: expects{
+				createResp:   &models.PullRequestResponse{Number: 13},
+				mergeTimes:   1,
+				reportStatus: "**merged**",
+			},
+		},
+		{
+			name:   "back-merge with conflicts",
+			config: &config,
+			expects: expects{
+				createResp:   &models.PullRequestResponse{Number: 13},
+				mergeErr:     apierrors.NewApiError("pull request not mergeable", "conflict_error", 409, apierrors.CauseList{}),
+				mergeTimes:   1,
+				reportStatus: "**conflicts**",
+			},
+		},
+		{
+			name:   "back-merge not mergeable",
+			config: &config,
+			expects: expects{
+				createResp:   &models.PullRequestResponse{Number: 13, Mergeable: &notMergeable},
+				reportStatus: "**conflicts**",
+			},
+		},
+		{
+			name:   "back-merge waiting to be merged",
+			config: &manualConfig,
+			expects: expects{
+				createResp:   &models.PullRequestResponse{Number: 13},
+				reportStatus: "**pending**",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ghClient := interfaces.NewMockGithubClient(ctrl)
+
+			ghClient.EXPECT().
+				CreatePullRequest(gomock.Any(), tt.config, "master", "develop", "Back-merge release/1.2 into develop", gomock.Any()).
+				Return(tt.expects.createResp, tt.expects.createErr).
+				Times(1)
+
+			ghClient.EXPECT().
+				MergePullRequest(gomock.Any(), tt.config, 13, "merge", "").
+				Return(tt.expects.mergeErr).
+				Times(tt.expects.mergeTimes)
+
+			ghClient.EXPECT().
+				CreateIssueComment(gomock.Any(), tt.config, &pr, gomock.Any()).
+				Do(func(ctx context.Context, config *models.Configuration, pullRequest *models.PullRequest, body string) {
+					assert.Contains(t, body, tt.expects.reportStatus)
+				}).
+				Return(nil).
+				Times(1)
+
+			s := &Build{
+				GithubClient: ghClient,
+			}
+
+			s.BackMerge(context.Background(), tt.config, &pr)
+		})
+	}
+}
+
+func TestBuild_CommitReleaseFiles(t *testing.T) {
+	type expects struct {
+		notesErr       apierrors.ApiError
+		prependTimes   int
+		changelog      *models.TreeEntry
+		changelogErr   apierrors.ApiError
+		bumpTimes      int
+		committedFiles []models.TreeEntry
+		bumpErr        apierrors.ApiError
+		sha            string
+		err            apierrors.ApiError
+	}
+
+	enabled := true
+
+	config := models.Configuration{
+		ID:               utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:   utils.Stringify("ci-cd_api"),
+		RepositoryOwner:  utils.Stringify("hbalmes"),
+		ChangelogEnabled: &enabled,
+	}
+
+	configWithoutChangelog := config
+	configWithoutChangelog.ChangelogEnabled = nil
+
+	pullRequest := models.PullRequest{
+		PullRequestNumber: 30,
+		BaseRef:           utils.Stringify("master"),
+		HeadRef:           utils.Stringify("release/1.3"),
+	}
+
+	notes := models.ReleaseNotes{Version: "v1.3.0"}
+
+	changelog := models.TreeEntry{Path: "CHANGELOG.md", Mode: models.TreeEntryModeFile, Type: models.TreeEntryTypeBlob, Content: "# Changelog\n"}
+
+	tests := []struct {
+		name    string
+		config  *models.Configuration
+		expects expects
+	}{
+		{
+			name:   "without changelog",
+			config: &configWithoutChangelog,
+			expects: expects{
+				bumpTimes:      1,
+				committedFiles: []models.TreeEntry{},
+				sha:            "commitsha",
+			},
+		},
+		{
+			name:   "release notes not generated",
+			config: &config,
+			expects: expects{
+				notesErr: apierrors.NewInternalServerApiError("error comparing commits - status: 500", nil),
+				sha:      "buildsha",
+				err:      apierrors.NewInternalServerApiError("error comparing commits - status: 500", nil),
+			},
+		},
+		{
+			name:   "error preparing the changelog",
+			config: &config,
+			expects: expects{
+				prependTimes: 1,
+				changelogErr: apierrors.NewInternalServerApiError("error getting file content - status: 500", nil),
+				sha:          "buildsha",
+				err:          apierrors.NewInternalServerApiError("error getting file content - status: 500", nil),
+			},
+		},
+		{
+			name:   "changelog skipped",
+			config: &config,
+			expects: expects{
+				prependTimes:   1,
+				changelogErr:   apierrors.NewApiError("CHANGELOG.md was changed by the pull request", "skipped", 206, apierrors.CauseList{}),
+				bumpTimes:      1,
+				committedFiles: []models.TreeEntry{},
+				sha:            "commitsha",
+			},
+		},
+		{
+			name:   "error bumping the version files",
+			config: &config,
+			expects: expects{
+				prependTimes:   1,
+				changelog:      &changelog,
+				bumpTimes:      1,
+				committedFiles: []models.TreeEntry{changelog},
+				bumpErr:        apierrors.NewApiError("branch release/1.3 moved since buildsha", "conflict_error", 409, apierrors.CauseList{}),
+				sha:            "buildsha",
+				err:            apierrors.NewApiError("branch release/1.3 moved since buildsha", "conflict_error", 409, apierrors.CauseList{}),
+			},
+		},
+		{
+			name:   "changelog committed with the version files",
+			config: &config,
+			expects: expects{
+				prependTimes:   1,
+				changelog:      &changelog,
+				bumpTimes:      1,
+				committedFiles: []models.TreeEntry{changelog},
+				sha:            "commitsha",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			changelogService := interfaces.NewMockChangelogService(ctrl)
+			versionFilesService := interfaces.NewMockVersionFilesService(ctrl)
+
+			build := models.Build{
+				Sha:   utils.Stringify("buildsha"),
+				Major: 1,
+				Minor: 3,
+				Body:  utils.Stringify("release notes"),
+			}
+
+			changelogService.EXPECT().
+				Prepend(gomock.Any(), tt.config, &pullRequest, "buildsha", &notes, "release notes").
+				Return(tt.expects.changelog, tt.expects.changelogErr).
+				Times(tt.expects.prependTimes)
+
+			versionFilesService.EXPECT().
+				Bump(gomock.Any(), tt.config, &pullRequest, &build, gomock.Any()).
+				DoAndReturn(func(ctx context.Context, config *models.Configuration, pullRequest *models.PullRequest, build *models.Build, files ...models.TreeEntry) (string, apierrors.ApiError) {
+					assert.Equal(t, tt.expects.committedFiles, files)
+					if tt.expects.bumpErr != nil {
+						return "", tt.expects.bumpErr
+					}
+					return "commitsha", nil
+				}).
+				Times(tt.expects.bumpTimes)
+
+			s := &Build{
+				ChangelogService:    changelogService,
+				VersionFilesService: versionFilesService,
+			}
+
+			err := s.CommitReleaseFiles(context.Background(), tt.config, &pullRequest, &build, &notes, tt.expects.notesErr)
+			assert.Equal(t, tt.expects.err, err)
+			assert.Equal(t, tt.expects.sha, *build.Sha)
+		})
+	}
+}
+
+func TestBuild_CheckFreeze(t *testing.T) {
+	type expects struct {
+		frozen        bool
+		activeErr     apierrors.ApiError
+		notice        *models.FreezeNotice
+		overrideTimes int
+		statuses      []string
+		commentTimes  int
+		saveTimes     int
+		deleteTimes   int
+		err           apierrors.ApiError
+	}
+
+	config := models.Configuration{
+		ID:              utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:  utils.Stringify("ci-cd_api"),
+		RepositoryOwner: utils.Stringify("hbalmes"),
+	}
+
+	pr := models.PullRequest{
+		ID:                1234,
+		PullRequestNumber: 12,
+		BaseRef:           utils.Stringify("master"),
+		HeadRef:           utils.Stringify("release/1.3"),
+		HeadSha:           utils.Stringify("headsha"),
+	}
+
+	freezeEnd := time.Date(2020, 11, 30, 0, 0, 0, 0, time.UTC)
+	previousFreezeEnd := time.Date(2020, 11, 23, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		override *models.ReleaseOverride
+		expects  expects
+	}{
+		{
+			name: "error getting freeze windows",
+			expects: expects{
+				activeErr: apierrors.NewInternalServerApiError("error getting freeze windows", gorm.ErrInvalidSQL),
+				err:       apierrors.NewInternalServerApiError("error getting freeze windows", gorm.ErrInvalidSQL),
+			},
+		},
+		{
+			name: "not frozen",
+		},
+		{
+			name: "freeze over",
+			expects: expects{
+				notice:      &models.FreezeNotice{PullRequestID: 1234, Sha: utils.Stringify("oldsha"), FreezeWindowID: 7, Until: &freezeEnd},
+				statuses:    []string{"success oldsha The release freeze is over"},
+				deleteTimes: 1,
+			},
+		},
+		{
+			name: "frozen",
+			expects: expects{
+				frozen:       true,
+				statuses:     []string{"failure headsha Productive releases are frozen by black-friday until 2020-11-30 00:00 UTC"},
+				commentTimes: 1,
+				saveTimes:    1,
+				err:          apierrors.NewApiError("The release is blocked by the freeze window black-friday.", "skipped", 206, apierrors.CauseList{}),
+			},
+		},
+		{
+			name: "frozen and already reported",
+			expects: expects{
+				frozen:    true,
+				notice:    &models.FreezeNotice{PullRequestID: 1234, Sha: utils.Stringify("oldsha"), FreezeWindowID: 7, Until: &freezeEnd},
+				statuses:  []string{"failure headsha Productive releases are frozen by black-friday until 2020-11-30 00:00 UTC"},
+				saveTimes: 1,
+				err:       apierrors.NewApiError("The release is blocked by the freeze window black-friday.", "skipped", 206, apierrors.CauseList{}),
+			},
+		},
+		{
+			name: "frozen again by a new occurrence of the window",
+			expects: expects{
+				frozen:       true,
+				notice:       &models.FreezeNotice{PullRequestID: 1234, Sha: utils.Stringify("headsha"), FreezeWindowID: 7, Until: &previousFreezeEnd},
+				statuses:     []string{"failure headsha Productive releases are frozen by black-friday until 2020-11-30 00:00 UTC"},
+				commentTimes: 1,
+				saveTimes:    1,
+				err:          apierrors.NewApiError("The release is blocked by the freeze window black-friday.", "skipped", 206, apierrors.CauseList{}),
+			},
+		},
+		{
+			name:     "frozen with an override without reason",
+			override: &models.ReleaseOverride{PullRequestID: 1234, Bump: utils.Stringify("major")},
+			expects: expects{
+				frozen:       true,
+				statuses:     []string{"failure headsha Productive releases are frozen by black-friday until 2020-11-30 00:00 UTC"},
+				commentTimes: 1,
+				saveTimes:    1,
+				err:          apierrors.NewApiError("The release is blocked by the freeze window black-friday.", "skipped", 206, apierrors.CauseList{}),
+			},
+		},
+		{
+			name:     "freeze overridden",
+			override: &models.ReleaseOverride{PullRequestID: 1234, FreezeOverrideReason: utils.Stringify("security fix"), UpdatedBy: utils.Stringify("maintainer")},
+			expects: expects{
+				frozen:        true,
+				overrideTimes: 1,
+				notice:        &models.FreezeNotice{PullRequestID: 1234, Sha: utils.Stringify("headsha"), FreezeWindowID: 7, Until: &freezeEnd},
+				statuses:      []string{"success headsha The release freeze was overridden"},
+				deleteTimes:   1,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+			githubClient := interfaces.NewMockGithubClient(ctrl)
+			freezeWindowService := interfaces.NewMockFreezeWindowService(ctrl)
+
+			var window *models.FreezeWindow
+			var until *time.Time
+			if tt.expects.frozen {
+				window = &models.FreezeWindow{ID: 7, Name: utils.Stringify("black-friday")}
+				until = &freezeEnd
+			}
+
+			freezeWindowService.EXPECT().
+				GetActive(gomock.Any(), "hbalmes/ci-cd_api", gomock.Any()).
+				Return(window, until, tt.expects.activeErr).
+				Times(1)
+
+			freezeWindowService.EXPECT().
+				RecordOverride(gomock.Any(), "hbalmes/ci-cd_api", window, "maintainer", "security fix", pr.HeadRef, pr.HeadSha).
+				Times(tt.expects.overrideTimes)
+
+			sqlStorage.EXPECT().
+				GetBy(gomock.Any(), gomock.Any(), "pull_request_id = ?", int64(1234)).
+				DoAndReturn(func(ctx context.Context, e interface{}, qry ...interface{}) error {
+					if tt.expects.notice == nil {
+						return gorm.ErrRecordNotFound
+					}
+					*e.(*models.FreezeNotice) = *tt.expects.notice
+					return nil
+				}).
+				AnyTimes()
+
+			sqlStorage.EXPECT().
+				Update(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, e interface{}) error {
+					notice := e.(*models.FreezeNotice)
+					assert.Equal(t, int64(1234), notice.PullRequestID)
+					assert.Equal(t, "headsha", *notice.Sha)
+					assert.Equal(t, uint32(7), notice.FreezeWindowID)
+					assert.Equal(t, freezeEnd, *notice.Until)
+					return nil
+				}).
+				Times(tt.expects.saveTimes)
+
+			sqlStorage.EXPECT().
+				Delete(gomock.Any(), gomock.Any()).
+				Return(nil).
+				Times(tt.expects.deleteTimes)
+
+			statuses := make([]string, 0)
+			githubClient.EXPECT().
+				CreateStatus(gomock.Any(), &config, gomock.Any()).
+				DoAndReturn(func(ctx context.Context, config *models.Configuration, status *webhook.Status) apierrors.ApiError {
+					assert.Equal(t, "release-freeze", *status.Context)
+					statuses = append(statuses, fmt.Sprintf("%s %s %s", *status.State, *status.Sha, *status.Description))
+					return nil
+				}).
+				AnyTimes()
+
+			githubClient.EXPECT().
+				CreateIssueComment(gomock.Any(), &config, &pr, gomock.Any()).
+				Return(nil).
+				Times(tt.expects.commentTimes)
+
+			s := &Build{
+				SQL:                 sqlStorage,
+				GithubClient:        githubClient,
+				FreezeWindowService: freezeWindowService,
+			}
+
+			assert.Equal(t, tt.expects.err, s.CheckFreeze(context.Background(), &config, &pr, tt.override))
+			if tt.expects.statuses == nil {
+				tt.expects.statuses = []string{}
+			}
+			assert.Equal(t, tt.expects.statuses, statuses)
+		})
+	}
+}
+
+func TestBuild_ResetEndedFreezes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+	githubClient := interfaces.NewMockGithubClient(ctrl)
+
+	now := time.Date(2020, 11, 30, 0, 1, 0, 0, time.UTC)
+
+	sqlStorage.EXPECT().
+		GetBy(gomock.Any(), gomock.Any(), "until <= ?", now).
+		DoAndReturn(func(ctx context.Context, e interface{}, qry ...interface{}) error {
+			*e.(*[]models.FreezeNotice) = []models.FreezeNotice{
+				{PullRequestID: 1234, RepositoryName: utils.Stringify("hbalmes/ci-cd_api"), Sha: utils.Stringify("firstsha")},
+				{PullRequestID: 1235, RepositoryName: utils.Stringify("hbalmes/ci-cd_api"), Sha: utils.Stringify("secondsha")},
+			}
+			return nil
+		}).
+		Times(1)
+
+	sqlStorage.EXPECT().
+		GetBy(gomock.Any(), gomock.Any(), "id = ?", "hbalmes/ci-cd_api").
+		DoAndReturn(func(ctx context.Context, e interface{}, qry ...interface{}) error {
+			*e.(*models.Configuration) = models.Configuration{ID: utils.Stringify("hbalmes/ci-cd_api")}
+			return nil
+		}).
+		Times(2)
+
+	//The status of the first pull request fails, so its notice is kept to retry it
+	githubClient.EXPECT().
+		CreateStatus(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, config *models.Configuration, status *webhook.Status) apierrors.ApiError {
+			assert.Equal(t, "success", *status.State)
+			assert.Equal(t, "The release freeze is over", *status.Description)
+			if *status.Sha == "firstsha" {
+				return apierrors.NewInternalServerApiError("error creating status", nil)
+			}
+			return nil
+		}).
+		Times(2)
+
+	sqlStorage.EXPECT().
+		Delete(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, e interface{}) error {
+			assert.Equal(t, int64(1235), e.(*models.FreezeNotice).PullRequestID)
+			return nil
+		}).
+		Times(1)
+
+	s := &Build{
+		SQL:          sqlStorage,
+		GithubClient: githubClient,
+	}
+
+	s.ResetEndedFreezes(context.Background(), now)
+}
+
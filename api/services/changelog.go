@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/hbalmes/ci_cd-api/api/clients"
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/services/storage"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"github.com/hbalmes/ci_cd-api/api/utils/tracing"
+)
+
+const (
+	//ServiceCommitterName and ServiceCommitterEmail are the committer of the commits made by this API
+	ServiceCommitterName  = "ci_cd-api"
+	ServiceCommitterEmail = "ci_cd-api@users.noreply.github.com"
+
+	changelogTitle            = "# Changelog\n"
+	keepAChangelogDescription = `
+All notable changes to this project will be documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.0.0/),
+and this project adheres to [Semantic Versioning](https://semver.org/spec/v2.0.0.html).
+`
+	changelogSectionPrefix = "## "
+	unreleasedSectionTitle = "unreleased"
+	keepAChangelogAdded    = "Added"
+	keepAChangelogChanged  = "Changed"
+	keepAChangelogFixed    = "Fixed"
+	changelogSkippedCode   = "skipped"
+)
+
+//keepAChangelogSections is the order in which the Keep a Changelog sections are shown.
+var keepAChangelogSections = []string{keepAChangelogAdded, keepAChangelogChanged, keepAChangelogFixed}
+
+var keepAChangelogCategories = map[string]string{
+	breakingChangesCategory: keepAChangelogChanged,
+	featuresCategory:        keepAChangelogAdded,
+	bugFixesCategory:        keepAChangelogFixed,
+	documentationCategory:   keepAChangelogChanged,
+	maintenanceCategory:     keepAChangelogChanged,
+	otherChangesCategory:    keepAChangelogChanged,
+}
+
+//ChangelogService is an interface which represents the ChangelogService for testing purpose.
+type ChangelogService interface {
+	Prepend(ctx context.Context, config *models.Configuration, pullRequest *models.PullRequest, sha string, notes *models.ReleaseNotes, body string) (*models.TreeEntry, apierrors.ApiError)
+}
+
+//Changelog represents the ChangelogService layer
+//It has an instance of a DBClient layer and
+//A github client instance
+type Changelog struct {
+	SQL          storage.SQLStorage
+	GithubClient clients.GithubClient
+}
+
+//NewChangelogService initializes a ChangelogService
+func NewChangelogService(sql storage.SQLStorage, githubClient clients.GithubClient) *Changelog {
+	return &Changelog{
+		SQL:          sql,
+		GithubClient: githubClient,
+	}
+}
+
+//IsChangelogEnabled checks if the repository keeps its changelog file updated through this API.
+func IsChangelogEnabled(config *models.Configuration) bool {
+	return config.ChangelogEnabled != nil && *config.ChangelogEnabled
+}
+
+//Prepend returns the changelog file of the pull request head branch at the given sha, the one being released,
+//with the release section prepended. It is committed along the version files, so the changelog is merged with the release
+//instead of being committed to the base branch.
+//It is skipped when the pull request changed the changelog too, because the commit would conflict with it,
+//or when the changelog already has the release section.
+//Returns nil when the changelog is not kept by this API.
+func (s *Changelog) Prepend(ctx context.Context, config *models.Configuration, pullRequest *models.PullRequest, sha string, notes *models.ReleaseNotes, body string) (*models.TreeEntry, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "Changelog.Prepend")
+	defer span.End()
+
+	if !IsChangelogEnabled(config) {
+		return nil, nil
+	}
+
+	if pullRequest.BaseRef == nil || pullRequest.HeadRef == nil {
+		return nil, apierrors.NewBadRequestApiError("invalid pull request branches")
+	}
+
+	path := config.GetChangelogPath()
+
+	compare, err := s.GithubClient.CompareCommits(ctx, config, *pullRequest.BaseRef, *pullRequest.HeadRef)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range compare.Files {
+		if file.Filename == path {
+			return nil, apierrors.NewApiError(fmt.Sprintf("%s was changed by the pull request", path), changelogSkippedCode, http.StatusPartialContent, apierrors.CauseList{})
+		}
+	}
+
+	content := NewChangelogContent(config)
+
+	file, err := s.GithubClient.GetFileContent(ctx, config, path, sha)
+
+	if err != nil {
+		if err.Status() != http.StatusNotFound {
+			return nil, err
+		}
+	} else {
+		content = file.Content
+	}
+
+	if HasChangelogSection(content, notes.Version) {
+		return nil, apierrors.NewApiError(fmt.Sprintf("%s already has a section for %s", path, notes.Version), changelogSkippedCode, http.StatusPartialContent, apierrors.CauseList{})
+	}
+
+	return &models.TreeEntry{
+		Path:    path,
+		Mode:    models.TreeEntryModeFile,
+		Type:    models.TreeEntryTypeBlob,
+		Content: PrependChangelogSection(content, FormatChangelogSection(config, notes, body)),
+	}, nil
+}
+
+//NewChangelogContent returns the content of a changelog file without releases.
+func NewChangelogContent(config *models.Configuration) string {
+	if config.GetChangelogFormat() == models.ChangelogFormatKeepAChangelog {
+		return changelogTitle + keepAChangelogDescription
+	}
+	return changelogTitle
+}
+
+//FormatChangelogSection returns the changelog section of the release in the configured format.
+func FormatChangelogSection(config *models.Configuration, notes *models.ReleaseNotes, body string) string {
+
+	if config.GetChangelogFormat() == models.ChangelogFormatReleaseNotes {
+		return strings.TrimSpace(body) + "\n"
+	}
+
+	entries := make(map[string][]string)
+	for _, category := range notes.Categories {
+		section := keepAChangelogCategories[category.Title]
+		for _, pr := range category.PullRequests {
+			entry := fmt.Sprintf("- %s ([#%d](%s))", pr.Title, pr.Number, pr.URL)
+			if category.Title == breakingChangesCategory {
+				entry = fmt.Sprintf("- **BREAKING** %s ([#%d](%s))", pr.Title, pr.Number, pr.URL)
+			}
+			entries[section] = append(entries[section], entry)
+		}
+	}
+
+	var section strings.Builder
+	section.WriteString(fmt.Sprintf("%s[%s] - %s\n", changelogSectionPrefix, strings.TrimPrefix(notes.Version, "v"), notes.Date))
+	for _, title := range keepAChangelogSections {
+		if len(entries[title]) == 0 {
+			continue
+		}
+		section.WriteString(fmt.Sprintf("\n### %s\n\n%s\n", title, strings.Join(entries[title], "\n")))
+	}
+
+	return section.String()
+}
+
+//HasChangelogSection checks if the changelog already has a section for the version.
+func HasChangelogSection(content string, version string) bool {
+
+	versionRegexp := regexp.MustCompile(`(^|[^\w.])v?` + regexp.QuoteMeta(strings.TrimPrefix(version, "v")) + `([^\w.-]|$)`)
+
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(line, changelogSectionPrefix) && versionRegexp.MatchString(strings.TrimPrefix(line, changelogSectionPrefix)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+//PrependChangelogSection adds the section before the latest release of the changelog.
+//The unreleased section, if any, is kept at the top.
+func PrependChangelogSection(content string, section string) string {
+
+	lines := strings.Split(content, "\n")
+
+	for i, line := range lines {
+		if strings.HasPrefix(line, changelogSectionPrefix) && !strings.Contains(strings.ToLower(line), unreleasedSectionTitle) {
+			after := strings.Join(lines[i:], "\n")
+			if i == 0 {
+				return section + "\n" + after
+			}
+			return strings.Join(lines[:i], "\n") + "\n" + section + "\n" + after
+		}
+	}
+
+	return strings.TrimRight(content, "\n") + "\n\n" + section
+}
@@ -0,0 +1,285 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hbalmes/ci_cd-api/api/mocks/interfaces"
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/utils"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrependChangelogSection(t *testing.T) {
+	section := "## [1.1.0] - 2020-06-02\n\n### Added\n\n- New endpoint ([#2](url))\n"
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "before the latest release",
+			content: "# Changelog\n\n## [1.0.0] - 2020-06-01\n\n### Added\n\n- First release\n",
+			want:    "# Changelog\n\n" + section + "\n## [1.0.0] - 2020-06-01\n\n### Added\n\n- First release\n",
+		},
+		{
+			name:    "unreleased section is kept at the top",
+			content: "# Changelog\n\n## [Unreleased]\n\n## [1.0.0] - 2020-06-01\n",
+			want:    "# Changelog\n\n## [Unreleased]\n\n" + section + "\n## [1.0.0] - 2020-06-01\n",
+		},
+		{
+			name:    "without releases",
+			content: "# Changelog\n",
+			want:    "# Changelog\n\n" + section,
+		},
+		{
+			name:    "without title",
+			content: "## v1.0.0\n",
+			want:    section + "\n## v1.0.0\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, PrependChangelogSection(tt.content, section))
+		})
+	}
+}
+
+func TestHasChangelogSection(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		version string
+		want    bool
+	}{
+		{
+			name:    "keep a changelog section",
+			content: "# Changelog\n\n## [1.3.0] - 2020-06-01\n",
+			version: "v1.3.0",
+			want:    true,
+		},
+		{
+			name:    "release notes section",
+			content: "# Changelog\n\n## v1.3.0\n",
+			version: "v1.3.0",
+			want:    true,
+		},
+		{
+			name:    "another version",
+			content: "# Changelog\n\n## [11.3.0] - 2020-06-01\n\n## [1.3.0-rc] - 2020-06-01\n",
+			version: "v1.3.0",
+			want:    false,
+		},
+		{
+			name:    "version outside a section title",
+			content: "# Changelog\n\n- Bump to 1.3.0\n",
+			version: "v1.3.0",
+			want:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, HasChangelogSection(tt.content, tt.version))
+		})
+	}
+}
+
+func TestFormatChangelogSection(t *testing.T) {
+	notes := &models.ReleaseNotes{
+		Version: "v1.3.0",
+		Date:    "2020-06-01",
+		Categories: []models.ReleaseNotesCategory{
+			{Title: breakingChangesCategory, PullRequests: []models.ReleaseNotesPullRequest{{Number: 20, Title: "Remove v1", URL: "url20"}}},
+			{Title: featuresCategory, PullRequests: []models.ReleaseNotesPullRequest{{Number: 21, Title: "Merge queues", URL: "url21"}}},
+			{Title: bugFixesCategory, PullRequests: []models.ReleaseNotesPullRequest{{Number: 22, Title: "Fix auto-merge", URL: "url22"}}},
+			{Title: documentationCategory, PullRequests: []models.ReleaseNotesPullRequest{{Number: 23, Title: "Docs", URL: "url23"}}},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		config *models.Configuration
+		want   string
+	}{
+		{
+			name:   "keep a changelog",
+			config: &models.Configuration{},
+			want: "## [1.3.0] - 2020-06-01\n" +
+				"\n### Added\n\n- Merge queues ([#21](url21))\n" +
+				"\n### Changed\n\n- **BREAKING** Remove v1 ([#20](url20))\n- Docs ([#23](url23))\n" +
+				"\n### Fixed\n\n- Fix auto-merge ([#22](url22))\n",
+		},
+		{
+			name:   "release notes",
+			config: &models.Configuration{ChangelogFormat: utils.Stringify(models.ChangelogFormatReleaseNotes)},
+			want:   "## v1.3.0\n\n### Features\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, FormatChangelogSection(tt.config, notes, "## v1.3.0\n\n### Features\n\n"))
+		})
+	}
+}
+
+func TestChangelog_Prepend(t *testing.T) {
+	type expects struct {
+		compareFiles []string
+		compareErr   apierrors.ApiError
+		getFileTimes int
+		file         *models.FileContentResponse
+		getFileErr   apierrors.ApiError
+		content      string
+		err          apierrors.ApiError
+	}
+
+	enabled := true
+
+	config := models.Configuration{
+		ID:               utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:   utils.Stringify("ci-cd_api"),
+		RepositoryOwner:  utils.Stringify("hbalmes"),
+		ChangelogEnabled: &enabled,
+	}
+
+	configDisabled := config
+	configDisabled.ChangelogEnabled = nil
+
+	pullRequest := models.PullRequest{
+		PullRequestNumber: 30,
+		BaseRef:           utils.Stringify("master"),
+		HeadRef:           utils.Stringify("release/1.3"),
+	}
+
+	notes := models.ReleaseNotes{
+		Version: "v1.3.0",
+		Date:    "2020-06-01",
+		Categories: []models.ReleaseNotesCategory{
+			{Title: featuresCategory, PullRequests: []models.ReleaseNotesPullRequest{{Number: 21, Title: "Merge queues", URL: "url21"}}},
+		},
+	}
+
+	existingFile := models.FileContentResponse{
+		Path:    "CHANGELOG.md",
+		Sha:     "filesha",
+		Content: "# Changelog\n\n## [1.2.0] - 2020-05-01\n",
+	}
+
+	releasedFile := existingFile
+	releasedFile.Content = "# Changelog\n\n## [1.3.0] - 2020-05-31\n"
+
+	tests := []struct {
+		name    string
+		config  *models.Configuration
+		expects expects
+	}{
+		{
+			name:    "changelog disabled",
+			config:  &configDisabled,
+			expects: expects{},
+		},
+		{
+			name:   "error comparing the pull request",
+			config: &config,
+			expects: expects{
+				compareErr: apierrors.NewInternalServerApiError("error comparing commits - status: 404", nil),
+				err:        apierrors.NewInternalServerApiError("error comparing commits - status: 404", nil),
+			},
+		},
+		{
+			name:   "changelog changed by the pull request",
+			config: &config,
+			expects: expects{
+				compareFiles: []string{"main.go", "CHANGELOG.md"},
+				err:          apierrors.NewApiError("CHANGELOG.md was changed by the pull request", "skipped", http.StatusPartialContent, apierrors.CauseList{}),
+			},
+		},
+		{
+			name:   "error getting the changelog",
+			config: &config,
+			expects: expects{
+				getFileTimes: 1,
+				getFileErr:   apierrors.NewInternalServerApiError("error getting file content - status: 500", nil),
+				err:          apierrors.NewInternalServerApiError("error getting file content - status: 500", nil),
+			},
+		},
+		{
+			name:   "changelog already has the release",
+			config: &config,
+			expects: expects{
+				getFileTimes: 1,
+				file:         &releasedFile,
+				err:          apierrors.NewApiError("CHANGELOG.md already has a section for v1.3.0", "skipped", http.StatusPartialContent, apierrors.CauseList{}),
+			},
+		},
+		{
+			name:   "changelog created",
+			config: &config,
+			expects: expects{
+				getFileTimes: 1,
+				getFileErr:   apierrors.NewNotFoundApiError("file CHANGELOG.md not found"),
+				content:      "adheres to [Semantic Versioning](https://semver.org/spec/v2.0.0.html).\n\n## [1.3.0] - 2020-06-01\n",
+			},
+		},
+		{
+			name:   "changelog updated",
+			config: &config,
+			expects: expects{
+				getFileTimes: 1,
+				file:         &existingFile,
+				content:      "# Changelog\n\n## [1.3.0] - 2020-06-01\n\n### Added\n\n- Merge queues ([#21](url21))\n\n## [1.2.0] - 2020-05-01\n",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			githubClient := interfaces.NewMockGithubClient(ctrl)
+
+			compare := models.CompareResponse{}
+			for _, filename := range tt.expects.compareFiles {
+				compare.Files = append(compare.Files, struct {
+					Filename string `json:"filename"`
+				}{Filename: filename})
+			}
+
+			compareTimes := 1
+			if tt.config.ChangelogEnabled == nil {
+				compareTimes = 0
+			}
+
+			githubClient.EXPECT().
+				CompareCommits(gomock.Any(), tt.config, "master", "release/1.3").
+				Return(&compare, tt.expects.compareErr).
+				Times(compareTimes)
+
+			githubClient.EXPECT().
+				GetFileContent(gomock.Any(), tt.config, "CHANGELOG.md", "headsha").
+				Return(tt.expects.file, tt.expects.getFileErr).
+				Times(tt.expects.getFileTimes)
+
+			s := &Changelog{
+				GithubClient: githubClient,
+			}
+
+			file, err := s.Prepend(context.Background(), tt.config, &pullRequest, "headsha", &notes, "")
+			assert.Equal(t, tt.expects.err, err)
+			if tt.expects.content == "" {
+				assert.Nil(t, file)
+				return
+			}
+			if assert.NotNil(t, file) {
+				assert.Equal(t, "CHANGELOG.md", file.Path)
+				assert.Equal(t, models.TreeEntryModeFile, file.Mode)
+				assert.Equal(t, models.TreeEntryTypeBlob, file.Type)
+				assert.Contains(t, file.Content, tt.expects.content)
+			}
+		})
+	}
+}
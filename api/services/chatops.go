@@ -0,0 +1,339 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hbalmes/ci_cd-api/api/clients"
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/models/webhook"
+	"github.com/hbalmes/ci_cd-api/api/services/storage"
+	"github.com/hbalmes/ci_cd-api/api/utils"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"github.com/hbalmes/ci_cd-api/api/utils/logger"
+	"github.com/hbalmes/ci_cd-api/api/utils/tracing"
+)
+
+const (
+	chatOpsCommandPrefix          = "/ci-cd"
+	chatOpsStatusCommand          = "status"
+	chatOpsRetryReleaseCommand    = "retry-release"
+	chatOpsBumpCommand            = "bump"
+	chatOpsSkipReleaseCommand     = "skip-release"
+	chatOpsRecheckWorkflowCommand = "recheck-workflow"
+	chatOpsQueueCommand           = "queue"
+	chatOpsDequeueCommand         = "dequeue"
+	chatOpsFreezeOverrideCommand  = "freeze-override"
+)
+
+//ChatOpsService is an interface which represents the ChatOpsService for testing purpose.
+type ChatOpsService interface {
+	ProcessCommand(ctx context.Context, config *models.Configuration, pullRequest *models.PullRequest, sender string, comment string) (string, apierrors.ApiError)
+}
+
+//ChatOps represents the ChatOpsService layer
+//It has an instance of a DBClient layer,
+//A github client instance,
+//A BuildService instance,
+//A WorkflowService instance and
+//A MergeQueueService instance
+type ChatOps struct {
+	SQL               storage.SQLStorage
+	GithubClient      clients.GithubClient
+	BuildService      BuildService
+	WorkflowService   WorkflowService
+	MergeQueueService MergeQueueService
+}
+
+//NewChatOpsService initializes a ChatOpsService
+func NewChatOpsService(sql storage.SQLStorage, githubClient clients.GithubClient, buildService BuildService, workflowService WorkflowService, mergeQueueService MergeQueueService) *ChatOps {
+	return &ChatOps{
+		SQL:               sql,
+		GithubClient:      githubClient,
+		BuildService:      buildService,
+		WorkflowService:   workflowService,
+		MergeQueueService: mergeQueueService,
+	}
+}
+
+//ParseChatOpsCommand looks for a '/ci-cd <command> [args]' line into the comment body.
+//Returns the command, its arguments and if the comment has a command at all.
+func ParseChatOpsCommand(comment string) (string, []string, bool) {
+	for _, line := range strings.Split(comment, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 1 && fields[0] == chatOpsCommandPrefix {
+			return fields[1], fields[2:], true
+		}
+	}
+	return "", nil, false
+}
+
+//ProcessCommand executes the ChatOps command present in the comment over the pull request
+//and replies to the pull request with the outcome.
+//Only maintainers configured for the repository or users with write permissions can execute commands.
+func (s *ChatOps) ProcessCommand(ctx context.Context, config *models.Configuration, pullRequest *models.PullRequest, sender string, comment string) (string, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "ChatOps.ProcessCommand")
+	defer span.End()
+
+	command, args, ok := ParseChatOpsCommand(comment)
+
+	if !ok {
+		return "", apierrors.NewBadRequestApiError("the comment has no ChatOps command")
+	}
+
+	authorized, authErr := s.IsAuthorized(ctx, config, sender)
+
+	if authErr != nil {
+		return "", authErr
+	}
+
+	var reply string
+	var cmdErr apierrors.ApiError
+
+	if !authorized {
+		reply = fmt.Sprintf("@%s you are not allowed to execute `%s %s` on this repository.", sender, chatOpsCommandPrefix, command)
+		cmdErr = apierrors.NewApiError("user not allowed to execute ChatOps commands", "forbidden", 403, apierrors.CauseList{})
+	} else {
+		switch command {
+		case chatOpsStatusCommand:
+			reply = s.GetReadinessReport(ctx, config, pullRequest)
+		case chatOpsRetryReleaseCommand:
+			reply, cmdErr = s.RetryRelease(ctx, config, pullRequest, sender)
+		case chatOpsBumpCommand:
+			reply, cmdErr = s.Bump(ctx, pullRequest, args, sender)
+		case chatOpsSkipReleaseCommand:
+			reply, cmdErr = s.SkipRelease(ctx, pullRequest, sender)
+		case chatOpsRecheckWorkflowCommand:
+			reply, cmdErr = s.RecheckWorkflow(ctx, config, pullRequest)
+		case chatOpsQueueCommand:
+			reply, cmdErr = s.Queue(ctx, config, pullRequest, sender)
+		case chatOpsDequeueCommand:
+			reply, cmdErr = s.Dequeue(ctx, config, pullRequest)
+		case chatOpsFreezeOverrideCommand:
+			reply, cmdErr = s.FreezeOverride(ctx, config, pullRequest, args, sender)
+		default:
+			reply = fmt.Sprintf("Unknown command `%s`. Available commands: `status`, `retry-release`, `bump <major|minor|patch>`, `skip-release`, `recheck-workflow`, `queue`, `dequeue` and `freeze-override <reason>`.", command)
+			cmdErr = apierrors.NewBadRequestApiError("ChatOps command not supported")
+		}
+	}
+
+	//Reply the outcome to the pull request
+	if replyErr := s.GithubClient.CreateIssueComment(ctx, config, pullRequest, reply); replyErr != nil {
+		logger.FromContext(ctx).Error().Err(replyErr).Str("command", command).Msg("error replying ChatOps command")
+	}
+
+	return reply, cmdErr
+}
+
+//IsAuthorized checks if the user is a configured maintainer or has write permissions over the repository.
+func (s *ChatOps) IsAuthorized(ctx context.Context, config *models.Configuration, username string) (bool, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "ChatOps.IsAuthorized")
+	defer span.End()
+
+	for _, maintainer := range config.GetMaintainers() {
+		if maintainer == username {
+			return true, nil
+		}
+	}
+
+	permission, err := s.GithubClient.GetCollaboratorPermission(ctx, config, username)
+
+	if err != nil {
+		return false, err
+	}
+
+	return permission == "admin" || permission == "write", nil
+}
+
+//GetReadinessReport builds the report of the quality checks required to create a new version for the pull request.
+func (s *ChatOps) GetReadinessReport(ctx context.Context, config *models.Configuration, pullRequest *models.PullRequest) string {
+	ctx, span := tracing.Start(ctx, "ChatOps.GetReadinessReport")
+	defer span.End()
+
+	states := s.BuildService.GetStatusChecksState(ctx, s.BuildService.GetBuildeableStatusChecks(config), newChatOpsStatusPayload(pullRequest, ""))
+
+	checks := make([]string, 0)
+	for check := range states {
+		checks = append(checks, check)
+	}
+	sort.Strings(checks)
+
+	ready := true
+	body := "# Readiness report \n" + "\n" +
+		fmt.Sprintf("> **Sha:** `%s`", *pullRequest.HeadSha) + "\n\n" +
+		"| Check | State |\n" +
+		"| --- | --- |\n"
+
+	for _, check := range checks {
+		emoji := ":white_check_mark:"
+		if !states[check] {
+			emoji = ":clock8:"
+			ready = false
+		}
+		body = body + fmt.Sprintf("| %s | %s |\n", check, emoji)
+	}
+
+	if override := s.BuildService.GetReleaseOverride(ctx, pullRequest); override != nil {
+		if override.SkipRelease {
+			body = body + "\n**Release:** skipped"
+		} else if override.Bump != nil {
+			body = body + fmt.Sprintf("\n**Release:** %s bump", *override.Bump)
+		}
+	}
+
+	if ready {
+		body = body + "\n**Ready to release:** yes :rocket:"
+	} else {
+		body = body + "\n**Ready to release:** not yet"
+	}
+
+	return body
+}
+
+//RetryRelease re-executes the build process for the pull request head sha.
+func (s *ChatOps) RetryRelease(ctx context.Context, config *models.Configuration, pullRequest *models.PullRequest, sender string) (string, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "ChatOps.RetryRelease")
+	defer span.End()
+
+	build, err := s.BuildService.ProcessBuild(ctx, config, newChatOpsStatusPayload(pullRequest, sender))
+
+	if err != nil {
+		return fmt.Sprintf("The release could not be created: %s", err.Message()), err
+	}
+
+	tagName := fmt.Sprintf("v%d.%d.%d", build.Major, build.Minor, build.Patch)
+	if build.Tag != nil {
+		tagName = tagName + "-" + *build.Tag
+	}
+
+	releaseURL := fmt.Sprintf("https://github.com/%s/%s/releases/tag/%s", *config.RepositoryOwner, *config.RepositoryName, tagName)
+
+	return fmt.Sprintf("Release [%s](%s) created :rocket:", tagName, releaseURL), nil
+}
+
+//Bump forces the semver field to increment when the pull request is released.
+func (s *ChatOps) Bump(ctx context.Context, pullRequest *models.PullRequest, args []string, sender string) (string, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "ChatOps.Bump")
+	defer span.End()
+
+	if len(args) == 0 || (args[0] != "major" && args[0] != "minor" && args[0] != "patch") {
+		return "Usage: `/ci-cd bump <major|minor|patch>`", apierrors.NewBadRequestApiError("invalid bump argument")
+	}
+
+	override := s.BuildService.GetReleaseOverride(ctx, pullRequest)
+	if override == nil {
+		override = &models.ReleaseOverride{PullRequestID: pullRequest.ID}
+	}
+	override.Bump = utils.Stringify(args[0])
+	override.SkipRelease = false
+	override.UpdatedBy = utils.Stringify(sender)
+
+	if err := s.SQL.Update(ctx, override); err != nil {
+		return "Something went wrong saving the bump.", apierrors.NewInternalServerApiError("error saving release override", err)
+	}
+
+	return fmt.Sprintf("The next release of this pull request will be a **%s** bump.", args[0]), nil
+}
+
+//SkipRelease avoids the build creation when the pull request passes all the quality checks.
+func (s *ChatOps) SkipRelease(ctx context.Context, pullRequest *models.PullRequest, sender string) (string, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "ChatOps.SkipRelease")
+	defer span.End()
+
+	override := s.BuildService.GetReleaseOverride(ctx, pullRequest)
+	if override == nil {
+		override = &models.ReleaseOverride{PullRequestID: pullRequest.ID}
+	}
+	override.SkipRelease = true
+	override.UpdatedBy = utils.Stringify(sender)
+
+	if err := s.SQL.Update(ctx, override); err != nil {
+		return "Something went wrong skipping the release.", apierrors.NewInternalServerApiError("error saving release override", err)
+	}
+
+	return "The release of this pull request will be skipped.", nil
+}
+
+//FreezeOverride allows the release of the pull request during a freeze window.
+//The reason is required and the override is audited when the release is created.
+func (s *ChatOps) FreezeOverride(ctx context.Context, config *models.Configuration, pullRequest *models.PullRequest, args []string, sender string) (string, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "ChatOps.FreezeOverride")
+	defer span.End()
+
+	if len(args) == 0 {
+		return "Usage: `/ci-cd freeze-override <reason>`", apierrors.NewBadRequestApiError("the freeze override reason is required")
+	}
+
+	override := s.BuildService.GetReleaseOverride(ctx, pullRequest)
+	if override == nil {
+		override = &models.ReleaseOverride{PullRequestID: pullRequest.ID}
+	}
+	override.FreezeOverrideReason = utils.Stringify(strings.Join(args, " "))
+	override.UpdatedBy = utils.Stringify(sender)
+
+	if err := s.SQL.Update(ctx, override); err != nil {
+		return "Something went wrong saving the freeze override.", apierrors.NewInternalServerApiError("error saving release override", err)
+	}
+
+	//The freeze does not block the pull request anymore
+	s.BuildService.ResetFreezeStatus(ctx, config, pullRequest, "The release freeze was overridden")
+
+	return "The freeze windows will be overridden for this pull request. Use `/ci-cd retry-release` to release it now.", nil
+}
+
+//RecheckWorkflow checks the workflow again and notifies the workflow status to the pull request head sha.
+func (s *ChatOps) RecheckWorkflow(ctx context.Context, config *models.Configuration, pullRequest *models.PullRequest) (string, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "ChatOps.RecheckWorkflow")
+	defer span.End()
+
+	var prWebhook webhook.PullRequestWebhook
+	prWebhook.PullRequest.Base.Ref = pullRequest.BaseRef
+	prWebhook.PullRequest.Head.Ref = pullRequest.HeadRef
+	prWebhook.PullRequest.Head.Sha = pullRequest.HeadSha
+	prWebhook.Repository.FullName = pullRequest.RepositoryName
+
+	statusWH := s.WorkflowService.CheckWorkflow(config, &prWebhook)
+
+	if err := s.GithubClient.CreateStatus(ctx, config, statusWH); err != nil {
+		return "Something went wrong notifying the workflow status.", err
+	}
+
+	return fmt.Sprintf("Workflow checked: **%s** - %s", *statusWH.State, *statusWH.Description), nil
+}
+
+//Queue adds the pull request to the merge queue of its base branch.
+func (s *ChatOps) Queue(ctx context.Context, config *models.Configuration, pullRequest *models.PullRequest, sender string) (string, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "ChatOps.Queue")
+	defer span.End()
+
+	if _, err := s.MergeQueueService.Enqueue(ctx, config, pullRequest, sender); err != nil {
+		return fmt.Sprintf("The pull request could not be queued: %s", err.Message()), err
+	}
+
+	return fmt.Sprintf("The pull request was added to the **%s** merge queue.", *pullRequest.BaseRef), nil
+}
+
+//Dequeue removes the pull request from the merge queue of its base branch.
+func (s *ChatOps) Dequeue(ctx context.Context, config *models.Configuration, pullRequest *models.PullRequest) (string, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "ChatOps.Dequeue")
+	defer span.End()
+
+	if err := s.MergeQueueService.Dequeue(ctx, config, pullRequest); err != nil {
+		return fmt.Sprintf("The pull request could not be dequeued: %s", err.Message()), err
+	}
+
+	return fmt.Sprintf("The pull request was removed from the **%s** merge queue.", *pullRequest.BaseRef), nil
+}
+
+func newChatOpsStatusPayload(pullRequest *models.PullRequest, sender string) *webhook.Status {
+	var statusWebhook webhook.Status
+
+	statusWebhook.Sha = pullRequest.HeadSha
+	statusWebhook.State = utils.Stringify(statusWebhookSuccessState)
+	statusWebhook.Repository.FullName = pullRequest.RepositoryName
+	statusWebhook.Sender.Login = utils.Stringify(sender)
+
+	return &statusWebhook
+}
@@ -0,0 +1,350 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hbalmes/ci_cd-api/api/mocks/interfaces"
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/models/webhook"
+	"github.com/hbalmes/ci_cd-api/api/utils"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseChatOpsCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		comment string
+		command string
+		args    []string
+		ok      bool
+	}{
+		{
+			name:    "comment without command",
+			comment: "LGTM!",
+			ok:      false,
+		},
+		{
+			name:    "prefix without command",
+			comment: "/ci-cd",
+			ok:      false,
+		},
+		{
+			name:    "command without args",
+			comment: "/ci-cd status",
+			command: "status",
+			args:    []string{},
+			ok:      true,
+		},
+		{
+			name:    "command with args in the middle of the comment",
+			comment: "this breaks the api\n/ci-cd bump major\nthanks",
+			command: "bump",
+			args:    []string{"major"},
+			ok:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command, args, ok := ParseChatOpsCommand(tt.comment)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.command, command)
+			assert.Equal(t, tt.args, args)
+		})
+	}
+}
+
+func TestChatOps_ProcessCommand(t *testing.T) {
+	type args struct {
+		sender  string
+		comment string
+	}
+
+	type expects struct {
+		permission      string
+		permissionErr   apierrors.ApiError
+		permissionTimes int
+		replyTimes      int
+		sqlUpdateErr    error
+		updateTimes     int
+		resetTimes      int
+		build           *models.Build
+		buildErr        apierrors.ApiError
+		processTimes    int
+		statusTimes     int
+		reply           string
+		err             apierrors.ApiError
+	}
+
+	config := models.Configuration{
+		ID:              utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:  utils.Stringify("ci-cd_api"),
+		RepositoryOwner: utils.Stringify("hbalmes"),
+		WorkflowType:    utils.Stringify("gitflow"),
+		Maintainers:     []models.Maintainer{{Username: "maintainer"}},
+	}
+
+	pr := models.PullRequest{
+		ID:                1234,
+		PullRequestNumber: 12,
+		RepositoryName:    utils.Stringify("hbalmes/ci-cd_api"),
+		BaseRef:           utils.Stringify("develop"),
+		HeadRef:           utils.Stringify("feature/chatops"),
+		HeadSha:           utils.Stringify("23456789qwertyuiasdfghjzxcvbn"),
+	}
+
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+		expects expects
+	}{
+		{
+			name:    "comment without command",
+			args:    args{sender: "maintainer", comment: "LGTM"},
+			wantErr: true,
+			expects: expects{
+				err: apierrors.NewBadRequestApiError("the comment has no ChatOps command"),
+			},
+		},
+		{
+			name:    "error getting collaborator permission",
+			args:    args{sender: "someone", comment: "/ci-cd skip-release"},
+			wantErr: true,
+			expects: expects{
+				permissionErr:   apierrors.NewInternalServerApiError("error getting collaborator permission", nil),
+				permissionTimes: 1,
+				err:             apierrors.NewInternalServerApiError("error getting collaborator permission", nil),
+			},
+		},
+		{
+			name:    "user without write permission",
+			args:    args{sender: "someone", comment: "/ci-cd skip-release"},
+			wantErr: true,
+			expects: expects{
+				permission:      "read",
+				permissionTimes: 1,
+				replyTimes:      1,
+				reply:           "@someone you are not allowed to execute `/ci-cd skip-release` on this repository.",
+				err:             apierrors.NewApiError("user not allowed to execute ChatOps commands", "forbidden", 403, apierrors.CauseList{}),
+			},
+		},
+		{
+			name:    "unknown command",
+			args:    args{sender: "maintainer", comment: "/ci-cd deploy"},
+			wantErr: true,
+			expects: expects{
+				replyTimes: 1,
+				reply:      "Unknown command `deploy`. Available commands: `status`, `retry-release`, `bump <major|minor|patch>`, `skip-release`, `recheck-workflow`, `queue`, `dequeue` and `freeze-override <reason>`.",
+				err:        apierrors.NewBadRequestApiError("ChatOps command not supported"),
+			},
+		},
+		{
+			name:    "invalid bump",
+			args:    args{sender: "maintainer", comment: "/ci-cd bump huge"},
+			wantErr: true,
+			expects: expects{
+				replyTimes: 1,
+				reply:      "Usage: `/ci-cd bump <major|minor|patch>`",
+				err:        apierrors.NewBadRequestApiError("invalid bump argument"),
+			},
+		},
+		{
+			name:    "bump major by a collaborator with write permission",
+			args:    args{sender: "writer", comment: "/ci-cd bump major"},
+			wantErr: false,
+			expects: expects{
+				permission:      "write",
+				permissionTimes: 1,
+				replyTimes:      1,
+				updateTimes:     1,
+				reply:           "The next release of this pull request will be a **major** bump.",
+			},
+		},
+		{
+			name:    "error saving skip release",
+			args:    args{sender: "maintainer", comment: "/ci-cd skip-release"},
+			wantErr: true,
+			expects: expects{
+				replyTimes:   1,
+				updateTimes:  1,
+				sqlUpdateErr: gorm.ErrInvalidSQL,
+				reply:        "Something went wrong skipping the release.",
+				err:          apierrors.NewInternalServerApiError("error saving release override", gorm.ErrInvalidSQL),
+			},
+		},
+		{
+			name:    "freeze override without reason",
+			args:    args{sender: "maintainer", comment: "/ci-cd freeze-override"},
+			wantErr: true,
+			expects: expects{
+				replyTimes: 1,
+				reply:      "Usage: `/ci-cd freeze-override <reason>`",
+				err:        apierrors.NewBadRequestApiError("the freeze override reason is required"),
+			},
+		},
+		{
+			name:    "freeze override",
+			args:    args{sender: "maintainer", comment: "/ci-cd freeze-override security fix for CVE-2020-1234"},
+			wantErr: false,
+			expects: expects{
+				replyTimes:  1,
+				updateTimes: 1,
+				resetTimes:  1,
+				reply:       "The freeze windows will be overridden for this pull request. Use `/ci-cd retry-release` to release it now.",
+			},
+		},
+		{
+			name:    "retry release fails",
+			args:    args{sender: "maintainer", comment: "/ci-cd retry-release"},
+			wantErr: true,
+			expects: expects{
+				replyTimes:   1,
+				processTimes: 1,
+				buildErr:     apierrors.NewNotFoundApiError("pull request not found for the sha"),
+				reply:        "The release could not be created: pull request not found for the sha",
+				err:          apierrors.NewNotFoundApiError("pull request not found for the sha"),
+			},
+		},
+		{
+			name:    "retry release",
+			args:    args{sender: "maintainer", comment: "/ci-cd retry-release"},
+			wantErr: false,
+			expects: expects{
+				replyTimes:   1,
+				processTimes: 1,
+				build:        &models.Build{Major: 1, Minor: 3, Patch: 0, GithubURL: utils.Stringify("v1.3.0")},
+				reply:        "Release [v1.3.0](https://github.com/hbalmes/ci-cd_api/releases/tag/v1.3.0) created :rocket:",
+			},
+		},
+		{
+			name:    "retry pre-release",
+			args:    args{sender: "maintainer", comment: "/ci-cd retry-release"},
+			wantErr: false,
+			expects: expects{
+				replyTimes:   1,
+				processTimes: 1,
+				build:        &models.Build{Major: 1, Minor: 3, Patch: 0, Tag: utils.Stringify("rc.1")},
+				reply:        "Release [v1.3.0-rc.1](https://github.com/hbalmes/ci-cd_api/releases/tag/v1.3.0-rc.1) created :rocket:",
+			},
+		},
+		{
+			name:    "recheck workflow",
+			args:    args{sender: "maintainer", comment: "/ci-cd recheck-workflow"},
+			wantErr: false,
+			expects: expects{
+				replyTimes:  1,
+				statusTimes: 1,
+				reply:       "Workflow checked: **success** - Great! You comply with the workflow",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+			githubClient := interfaces.NewMockGithubClient(ctrl)
+			buildService := interfaces.NewMockBuildService(ctrl)
+
+			githubClient.EXPECT().
+				GetCollaboratorPermission(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(tt.expects.permission, tt.expects.permissionErr).
+				Times(tt.expects.permissionTimes)
+
+			githubClient.EXPECT().
+				CreateIssueComment(gomock.Any(), gomock.Any(), gomock.Any(), tt.expects.reply).
+				Return(nil).
+				Times(tt.expects.replyTimes)
+
+			githubClient.EXPECT().
+				CreateStatus(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(nil).
+				Times(tt.expects.statusTimes)
+
+			buildService.EXPECT().
+				GetReleaseOverride(gomock.Any(), gomock.Any()).
+				Return(nil).
+				Times(tt.expects.updateTimes)
+
+			buildService.EXPECT().
+				ResetFreezeStatus(gomock.Any(), &config, &pr, "The release freeze was overridden").
+				Times(tt.expects.resetTimes)
+
+			buildService.EXPECT().
+				ProcessBuild(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(tt.expects.build, tt.expects.buildErr).
+				Times(tt.expects.processTimes)
+
+			sqlStorage.EXPECT().
+				Update(gomock.Any(), gomock.Any()).
+				Return(tt.expects.sqlUpdateErr).
+				Times(tt.expects.updateTimes)
+
+			s := &ChatOps{
+				SQL:             sqlStorage,
+				GithubClient:    githubClient,
+				BuildService:    buildService,
+				WorkflowService: &Configuration{},
+			}
+
+			reply, err := s.ProcessCommand(context.Background(), &config, &pr, tt.args.sender, tt.args.comment)
+
+			assert.Equal(t, tt.expects.reply, reply)
+			if tt.wantErr {
+				assert.Equal(t, tt.expects.err, err)
+				return
+			}
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func TestChatOps_GetReadinessReport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	buildService := interfaces.NewMockBuildService(ctrl)
+
+	config := models.Configuration{
+		ID: utils.Stringify("hbalmes/ci-cd_api"),
+	}
+
+	pr := models.PullRequest{
+		RepositoryName: utils.Stringify("hbalmes/ci-cd_api"),
+		HeadSha:        utils.Stringify("23456789qwertyuiasdfghjzxcvbn"),
+	}
+
+	buildService.EXPECT().
+		GetBuildeableStatusChecks(&config).
+		Return([]string{"workflow", "pull_request_review"}).
+		Times(1)
+
+	buildService.EXPECT().
+		GetStatusChecksState(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, reqSC []string, payload *webhook.Status) map[string]bool {
+			assert.Equal(t, pr.HeadSha, payload.Sha)
+			return map[string]bool{"workflow": true, "pull_request_review": false}
+		}).
+		Times(1)
+
+	buildService.EXPECT().
+		GetReleaseOverride(gomock.Any(), &pr).
+		Return(&models.ReleaseOverride{Bump: utils.Stringify("major")}).
+		Times(1)
+
+	s := &ChatOps{
+		BuildService: buildService,
+	}
+
+	report := s.GetReadinessReport(context.Background(), &config, &pr)
+
+	assert.Contains(t, report, "| pull_request_review | :clock8: |")
+	assert.Contains(t, report, "| workflow | :white_check_mark: |")
+	assert.Contains(t, report, "**Release:** major bump")
+	assert.Contains(t, report, "**Ready to release:** not yet")
+}
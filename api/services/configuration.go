@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/hbalmes/ci_cd-api/api/clients"
@@ -8,16 +9,16 @@ import (
 	"github.com/hbalmes/ci_cd-api/api/services/storage"
 	"github.com/hbalmes/ci_cd-api/api/utils"
 	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"github.com/hbalmes/ci_cd-api/api/utils/tracing"
 	"github.com/jinzhu/gorm"
-	"os"
 )
 
 //ConfigurationService is an interface which represents the ConfigurationService for testing purpose.
 type ConfigurationService interface {
-	Create(r *models.PostRequestPayload) (*models.Configuration, apierrors.ApiError)
-	Get(string) (*models.Configuration, error)
-	Update(r *models.PutRequestPayload) (*models.Configuration, error)
-	Delete(id string) error
+	Create(ctx context.Context, r *models.PostRequestPayload) (*models.Configuration, apierrors.ApiError)
+	Get(ctx context.Context, id string) (*models.Configuration, error)
+	Update(ctx context.Context, r *models.PutRequestPayload) (*models.Configuration, error)
+	Delete(ctx context.Context, id string) error
 }
 
 //Configuration represents the ConfigurationService layer
@@ -29,16 +30,42 @@ type Configuration struct {
 }
 
 //NewConfigurationService initializes a ConfigurationService
-func NewConfigurationService(sql storage.SQLStorage) *Configuration {
+func NewConfigurationService(sql storage.SQLStorage, githubClient clients.GithubClient) *Configuration {
 	return &Configuration{
 		SQL:          sql,
-		GithubClient: clients.NewGithubClient(),
+		GithubClient: githubClient,
 	}
 }
 
 //Create creates a Release Process valid configuration.
 //It performs all the actions needed to enabled successfuly Release Process.
-func (s *Configuration) Create(r *models.PostRequestPayload) (*models.Configuration, apierrors.ApiError) {
+func (s *Configuration) Create(ctx context.Context, r *models.PostRequestPayload) (*models.Configuration, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "Configuration.Create")
+	defer span.End()
+
+	if !models.IsValidAutoMergeMethod(r.AutoMerge.Method) {
+		return nil, apierrors.NewBadRequestApiError("invalid auto merge method")
+	}
+
+	if _, err := ParseReleaseNotesTemplate(r.ReleaseNotes.Template); err != nil {
+		return nil, apierrors.NewBadRequestApiError("invalid release notes template")
+	}
+
+	if !models.IsValidChangelogFormat(r.Changelog.Format) {
+		return nil, apierrors.NewBadRequestApiError("invalid changelog format")
+	}
+
+	if !models.IsValidVersionFiles(r.VersionFiles) {
+		return nil, apierrors.NewBadRequestApiError("invalid version file type")
+	}
+
+	if !models.IsValidEnvironments(r.Environments) {
+		return nil, apierrors.NewBadRequestApiError("invalid environments")
+	}
+
+	if !models.IsValidPromotionSoak(r.Promotion.MinSoakMinutes) {
+		return nil, apierrors.NewBadRequestApiError("invalid promotion soak time")
+	}
 
 	config := *models.NewConfiguration(r)
 	config.ID = utils.Stringify(fmt.Sprintf("%s/%s", *r.Repository.Owner, *r.Repository.Name))
@@ -46,21 +73,21 @@ func (s *Configuration) Create(r *models.PostRequestPayload) (*models.Configurat
 	var cf models.Configuration
 
 	//Search the configuration into database
-	if err := s.SQL.GetBy(&cf, "id = ?", fmt.Sprintf("%s/%s", *config.RepositoryOwner, *config.RepositoryName)); err != nil {
+	if err := s.SQL.GetBy(ctx, &cf, "id = ?", fmt.Sprintf("%s/%s", *config.RepositoryOwner, *config.RepositoryName)); err != nil {
 
 		//If the error is not a not found error, then there is a problem
 		if err != gorm.ErrRecordNotFound {
 			return nil, apierrors.NewInternalServerApiError("error checking configuration existence", err)
 		}
 
-		setWorkflowError := s.SetWorkflow(&config)
+		setWorkflowError := s.SetWorkflow(ctx, &config)
 
 		if setWorkflowError != nil {
 			return nil, setWorkflowError
 		}
 
 		//Save it into database
-		if err := s.SQL.Insert(&config); err != nil {
+		if err := s.SQL.Insert(ctx, &config); err != nil {
 			return nil, apierrors.NewInternalServerApiError("error saving new configuration", err)
 		}
 		return &config, nil
@@ -72,22 +99,12 @@ func (s *Configuration) Create(r *models.PostRequestPayload) (*models.Configurat
 
 //Get searches a configuration into database.
 //Returns an error if the config is not found.
-func (s *Configuration) Get(id string) (*models.Configuration, error) {
-	var cf models.Configuration
-	//To wakeup the db.
-	scope := os.Getenv("SCOPE")
-	if scope == "production" {
-		for i := 1; i < 5; i++ {
-			if err := s.SQL.GetBy(&cf, "id = ?", "hbalmes/ci_cd-api"); err != nil {
-				if err != gorm.ErrRecordNotFound {
-					continue
-				}
-			}
-			break
-		}
-	}
+func (s *Configuration) Get(ctx context.Context, id string) (*models.Configuration, error) {
+	ctx, span := tracing.Start(ctx, "Configuration.Get")
+	defer span.End()
 
-	if err := s.SQL.GetBy(&cf, "id = ?", id); err != nil {
+	var cf models.Configuration
+	if err := s.SQL.GetBy(ctx, &cf, "id = ?", id); err != nil {
 		if err != gorm.ErrRecordNotFound {
 			return nil, errors.New("error checking configuration existance")
 		}
@@ -101,9 +118,35 @@ func (s *Configuration) Get(id string) (*models.Configuration, error) {
 //Update modifies a configuration.
 //It receives a PutRequestPayload.
 //Returns an error if the config is not found or if it some problem updating the config.
-func (s *Configuration) Update(r *models.PutRequestPayload) (*models.Configuration, error) {
+func (s *Configuration) Update(ctx context.Context, r *models.PutRequestPayload) (*models.Configuration, error) {
+	ctx, span := tracing.Start(ctx, "Configuration.Update")
+	defer span.End()
+
+	if !models.IsValidAutoMergeMethod(r.AutoMerge.Method) {
+		return nil, errors.New("invalid auto merge method")
+	}
 
-	oldConfig, err := s.Get(*r.Repository.Name)
+	if _, err := ParseReleaseNotesTemplate(r.ReleaseNotes.Template); err != nil {
+		return nil, errors.New("invalid release notes template")
+	}
+
+	if !models.IsValidChangelogFormat(r.Changelog.Format) {
+		return nil, errors.New("invalid changelog format")
+	}
+
+	if !models.IsValidVersionFiles(r.VersionFiles) {
+		return nil, errors.New("invalid version file type")
+	}
+
+	if !models.IsValidEnvironments(r.Environments) {
+		return nil, errors.New("invalid environments")
+	}
+
+	if !models.IsValidPromotionSoak(r.Promotion.MinSoakMinutes) {
+		return nil, errors.New("invalid promotion soak time")
+	}
+
+	oldConfig, err := s.Get(ctx, *r.Repository.Name)
 
 	if err != nil {
 		return nil, err
@@ -121,14 +164,46 @@ func (s *Configuration) Update(r *models.PutRequestPayload) (*models.Configurati
 		// child table, so we removed them and then saved the new ones.
 
 		//Delete from configurations DB
-		if sqlErr := s.SQL.DeleteFromRequireStatusChecksByConfigurationID(oldConfig.ID); sqlErr != nil {
+		if sqlErr := s.SQL.DeleteFromRequireStatusChecksByConfigurationID(ctx, oldConfig.ID); sqlErr != nil {
+			return nil, sqlErr
+		}
+
+	}
+
+	//Update the ChatOps maintainers
+	if r.ChatOps.Maintainers != nil {
+		if sqlErr := s.SQL.DeleteFromMaintainersByConfigurationID(ctx, oldConfig.ID); sqlErr != nil {
 			return nil, sqlErr
 		}
+	}
 
+	//Update the branch cleanup prefixes
+	if r.BranchCleanup.Prefixes != nil {
+		if sqlErr := s.SQL.DeleteFromBranchCleanupPrefixesByConfigurationID(ctx, oldConfig.ID); sqlErr != nil {
+			return nil, sqlErr
+		}
+	}
+
+	//Update the version files
+	if r.VersionFiles != nil {
+		if sqlErr := s.SQL.DeleteFromVersionFilesByConfigurationID(ctx, oldConfig.ID); sqlErr != nil {
+			return nil, sqlErr
+		}
+	}
+
+	//Update the environments
+	if r.Environments != nil {
+		if sqlErr := s.SQL.DeleteFromEnvironmentsByConfigurationID(ctx, oldConfig.ID); sqlErr != nil {
+			return nil, sqlErr
+		}
+
+		if sqlErr := s.SQL.DeleteFromEnvironmentApproversByConfigurationID(ctx, oldConfig.ID); sqlErr != nil {
+			return nil, sqlErr
+		}
 	}
 
 	//Save the new config into database
-	if err := s.SQL.Update(&newConfig); err != nil {
+	if err := s.SQL.Update(ctx, &newConfig); err != nil {
 		return nil, errors.New("error updating repository configuration")
 	}
 	return &newConfig, nil
@@ -137,28 +212,30 @@ func (s *Configuration) Update(r *models.PutRequestPayload) (*models.Configurati
 //Delete erase the configuration.
 //It makes a sof delete.
 //Receives the configuration id (repoName) and returns an error it it occurs.
-func (s *Configuration) Delete(id string) error {
+func (s *Configuration) Delete(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "Configuration.Delete")
+	defer span.End()
 
-	cf, err := s.Get(id)
+	cf, err := s.Get(ctx, id)
 
 	if err != nil {
 		return err
 	}
 
-	unsetWorkflowError := s.UnsetWorkflow(cf)
+	unsetWorkflowError := s.UnsetWorkflow(ctx, cf)
 
 	if unsetWorkflowError != nil {
 		return unsetWorkflowError
 	}
 
 	//Delete from configurations DB
-	if sqlErr := s.SQL.Delete(cf); sqlErr != nil {
+	if sqlErr := s.SQL.Delete(ctx, cf); sqlErr != nil {
 		return sqlErr
 	}
 
 	//TODO:Descomentar esto y probar.
 	//Delete from configurations DB
-	/*if sqlErr := s.SQL.DeleteFromRequireStatusChecksByConfigurationID(cf.ID); sqlErr != nil {
+	/*if sqlErr := s.SQL.DeleteFromRequireStatusChecksByConfigurationID(ctx, cf.ID); sqlErr != nil {
 		return sqlErr
 	}*/
 
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"github.com/golang/mock/gomock"
 	"github.com/hbalmes/ci_cd-api/api/mocks/interfaces"
@@ -127,29 +128,29 @@ func TestConfiguration_Create(t *testing.T) {
 			}
 
 			sqlStorage.EXPECT().
-				GetBy(gomock.Any(), gomock.Any(), gomock.Any()).
-				DoAndReturn(func(e interface{}, qry ...interface{}) *models.Configuration {
+				GetBy(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, e interface{}, qry ...interface{}) *models.Configuration {
 					return &cicdConfigOK
 				}).
 				Return(tt.expects.sqlGetByError).
 				AnyTimes()
 
 			sqlStorage.EXPECT().
-				Insert(gomock.Any()).
+				Insert(gomock.Any(), gomock.Any()).
 				Return(tt.expects.sqlInsertError).
 				AnyTimes()
 
 			githubClient.EXPECT().
-				ProtectBranch(gomock.Any(), gomock.Any()).
+				ProtectBranch(gomock.Any(), gomock.Any(), gomock.Any()).
 				Return(tt.expects.setWorkflowError).
 				AnyTimes()
 
 			githubClient.EXPECT().
-				SetDefaultBranch(gomock.Any(), gomock.Any()).
+				SetDefaultBranch(gomock.Any(), gomock.Any(), gomock.Any()).
 				Return(tt.expects.setWorkflowError).
 				AnyTimes()
 
-			conf, err := s.Create(tt.args.payload)
+			conf, err := s.Create(context.Background(), tt.args.payload)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Configuration.Create() error = %v, wantErr %v", tt.expects.error, tt.wantErr)
@@ -217,7 +218,7 @@ func TestConfiguration_Get(t *testing.T) {
 
 			sqlStorage := interfaces.NewMockSQLStorage(ctl)
 			sqlStorage.EXPECT().
-				GetBy(gomock.Any(), gomock.Any(), gomock.Any()).
+				GetBy(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 				Return(tt.expects.error).
 				AnyTimes()
 
@@ -225,7 +226,7 @@ func TestConfiguration_Get(t *testing.T) {
 				SQL: sqlStorage,
 			}
 
-			_, err := s.Get(tt.args.id)
+			_, err := s.Get(context.Background(), tt.args.id)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Configuration.Get() error = %v, wantErr %v", err, tt.wantErr)
@@ -273,7 +274,7 @@ func TestConfiguration_GetProductionScopeOK(t *testing.T) {
 			sqlStorage := interfaces.NewMockSQLStorage(ctl)
 
 			sqlStorage.EXPECT().
-				GetBy(gomock.Any(), gomock.Any(), gomock.Any()).
+				GetBy(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 				Return(tt.expects.error).
 				AnyTimes()
 
@@ -282,7 +283,7 @@ func TestConfiguration_GetProductionScopeOK(t *testing.T) {
 			}
 
 			os.Setenv("SCOPE", "production")
-			_, err := s.Get(tt.args.id)
+			_, err := s.Get(context.Background(), tt.args.id)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Configuration.Get() error = %v, wantErr %v", err, tt.wantErr)
@@ -311,10 +312,10 @@ func TestConfiguration_GetProductionScopeFails(t *testing.T) {
 		expects expects
 	}{
 		{
-			name: "wake up db fail - fails 15 times",
+			name: "db fail - the configuration is queried once",
 			args: args{
 				id:    "fury_repo-name",
-				times: 5,
+				times: 1,
 			},
 			expects: expects{
 				error: gorm.ErrInvalidSQL,
@@ -331,7 +332,7 @@ func TestConfiguration_GetProductionScopeFails(t *testing.T) {
 			sqlStorage := interfaces.NewMockSQLStorage(ctl)
 
 			sqlStorage.EXPECT().
-				GetBy(gomock.Any(), gomock.Any(), gomock.Any()).
+				GetBy(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 				Return(tt.expects.error).
 				Times(tt.args.times)
 
@@ -340,7 +341,7 @@ func TestConfiguration_GetProductionScopeFails(t *testing.T) {
 			}
 
 			os.Setenv("SCOPE", "production")
-			_, err := s.Get(tt.args.id)
+			_, err := s.Get(context.Background(), tt.args.id)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Configuration.Get() error = %v, wantErr %v", err, tt.wantErr)
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"sort"
+
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/services/storage"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"github.com/hbalmes/ci_cd-api/api/utils/tracing"
+)
+
+//CoverageService is an interface which represents the CoverageService for testing purpose.
+type CoverageService interface {
+	Create(ctx context.Context, repositoryName string, r *models.CoverageRequestPayload) (*models.Coverage, apierrors.ApiError)
+	GetHistory(ctx context.Context, repositoryName string, branch string) ([]models.CoveragePoint, apierrors.ApiError)
+}
+
+//Coverage represents the CoverageService layer
+//It has an instance of a DBClient layer
+type Coverage struct {
+	SQL storage.SQLStorage
+}
+
+//NewCoverageService initializes a CoverageService
+func NewCoverageService(sql storage.SQLStorage) *Coverage {
+	return &Coverage{
+		SQL: sql,
+	}
+}
+
+//Create saves the coverage summary and the per package breakdown reported for a sha.
+func (s *Coverage) Create(ctx context.Context, repositoryName string, r *models.CoverageRequestPayload) (*models.Coverage, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "Coverage.Create")
+	defer span.End()
+
+	if r.Sha == nil || r.Branch == nil || r.Total == nil {
+		return nil, apierrors.NewBadRequestApiError("sha, branch and total are required")
+	}
+
+	coverage := models.NewCoverage(repositoryName, r)
+
+	//Save it into database
+	if err := s.SQL.Insert(ctx, coverage); err != nil {
+		return nil, apierrors.NewInternalServerApiError("error saving new coverage report", err)
+	}
+
+	return coverage, nil
+}
+
+//GetHistory returns the coverage time series of a repository, oldest first.
+//When a branch is given, only the reports of that branch are returned.
+//Every point is linked to the build created from the same sha, before committing its release files.
+func (s *Coverage) GetHistory(ctx context.Context, repositoryName string, branch string) ([]models.CoveragePoint, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "Coverage.GetHistory")
+	defer span.End()
+
+	var coverages []models.Coverage
+	var builds []models.Build
+
+	qry := []interface{}{"repository_name = ?", repositoryName}
+	if branch != "" {
+		qry = []interface{}{"repository_name = ? AND branch = ?", repositoryName, branch}
+	}
+
+	if err := s.SQL.GetBy(ctx, &coverages, qry...); err != nil {
+		return nil, apierrors.NewInternalServerApiError("error getting coverage history", err)
+	}
+
+	if err := s.SQL.GetBy(ctx, &builds, "repository_name = ?", repositoryName); err != nil {
+		return nil, apierrors.NewInternalServerApiError("error getting repository builds", err)
+	}
+
+	//The coverage is reported for the sha of the pull request, which is the source sha of a build
+	//whose sha is the release commit. The builds without source sha are linked by their own sha.
+	buildsBySha := make(map[string]models.Build)
+	for _, build := range builds {
+		if build.SourceSha != nil {
+			buildsBySha[*build.SourceSha] = build
+		} else if build.Sha != nil {
+			buildsBySha[*build.Sha] = build
+		}
+	}
+
+	sort.SliceStable(coverages, func(i, j int) bool {
+		return coverages[i].CreatedAt.Before(coverages[j].CreatedAt)
+	})
+
+	points := make([]models.CoveragePoint, 0)
+	for _, coverage := range coverages {
+		point := models.CoveragePoint{Coverage: coverage}
+		if coverage.Sha != nil {
+			if build, ok := buildsBySha[*coverage.Sha]; ok {
+				point.Build = &build
+			}
+		}
+		points = append(points, point)
+	}
+
+	return points, nil
+}
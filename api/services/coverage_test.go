@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hbalmes/ci_cd-api/api/mocks/interfaces"
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/utils"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoverage_Create(t *testing.T) {
+	type args struct {
+		payload *models.CoverageRequestPayload
+	}
+
+	type expects struct {
+		sqlInsertError error
+		insertTimes    int
+		err            apierrors.ApiError
+	}
+
+	total := 81.5
+
+	var payloadOK models.CoverageRequestPayload
+	payloadOK.Sha = utils.Stringify("23456789qwertyuiasdfghjzxcvbn")
+	payloadOK.Branch = utils.Stringify("develop")
+	payloadOK.Total = &total
+	payloadOK.Packages = append(payloadOK.Packages, struct {
+		Name     string  `json:"name"`
+		Coverage float64 `json:"coverage"`
+	}{Name: "api/services", Coverage: 75.2})
+
+	var payloadWithoutSha models.CoverageRequestPayload
+	payloadWithoutSha.Branch = utils.Stringify("develop")
+	payloadWithoutSha.Total = &total
+
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+		expects expects
+	}{
+		{
+			name:    "invalid payload",
+			args:    args{payload: &payloadWithoutSha},
+			wantErr: true,
+			expects: expects{
+				err: apierrors.NewBadRequestApiError("sha, branch and total are required"),
+			},
+		},
+		{
+			name:    "error saving coverage",
+			args:    args{payload: &payloadOK},
+			wantErr: true,
+			expects: expects{
+				sqlInsertError: gorm.ErrInvalidSQL,
+				insertTimes:    1,
+				err:            apierrors.NewInternalServerApiError("error saving new coverage report", gorm.ErrInvalidSQL),
+			},
+		},
+		{
+			name:    "coverage saved successfully",
+			args:    args{payload: &payloadOK},
+			wantErr: false,
+			expects: expects{
+				insertTimes: 1,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+
+			sqlStorage.EXPECT().
+				Insert(gomock.Any(), gomock.Any()).
+				Return(tt.expects.sqlInsertError).
+				Times(tt.expects.insertTimes)
+
+			s := &Coverage{
+				SQL: sqlStorage,
+			}
+
+			got, err := s.Create(context.Background(), "hbalmes/ci-cd_api", tt.args.payload)
+
+			if tt.wantErr {
+				assert.Nil(t, got)
+				assert.Equal(t, tt.expects.err, err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, "hbalmes/ci-cd_api", *got.RepositoryName)
+			assert.Equal(t, total, got.Total)
+			assert.Equal(t, 1, len(got.Packages))
+			assert.Equal(t, "api/services", got.Packages[0].Name)
+		})
+	}
+}
+
+func TestCoverage_GetHistory(t *testing.T) {
+	type expects struct {
+		getCoveragesError error
+		getBuildsError    error
+		getBuildsTimes    int
+		points            int
+		err               apierrors.ApiError
+	}
+
+	now := time.Now()
+
+	coverages := []models.Coverage{
+		{
+			Sha:       utils.Stringify("sha2"),
+			Branch:    utils.Stringify("develop"),
+			Total:     70,
+			CreatedAt: now,
+		},
+		{
+			Sha:       utils.Stringify("sha1"),
+			Branch:    utils.Stringify("develop"),
+			Total:     80,
+			CreatedAt: now.Add(-time.Hour),
+		},
+		{
+			Sha:       utils.Stringify("sha3"),
+			Branch:    utils.Stringify("develop"),
+			Total:     75,
+			CreatedAt: now.Add(time.Hour),
+		},
+	}
+
+	builds := []models.Build{
+		{
+			ID:  12,
+			Sha: utils.Stringify("sha1"),
+		},
+		{
+			ID:        13,
+			Sha:       utils.Stringify("release-sha"),
+			SourceSha: utils.Stringify("sha3"),
+		},
+	}
+
+	tests := []struct {
+		name    string
+		wantErr bool
+		expects expects
+	}{
+		{
+			name:    "error getting coverages",
+			wantErr: true,
+			expects: expects{
+				getCoveragesError: gorm.ErrInvalidSQL,
+				err:               apierrors.NewInternalServerApiError("error getting coverage history", gorm.ErrInvalidSQL),
+			},
+		},
+		{
+			name:    "error getting builds",
+			wantErr: true,
+			expects: expects{
+				getBuildsError: gorm.ErrInvalidSQL,
+				getBuildsTimes: 1,
+				err:            apierrors.NewInternalServerApiError("error getting repository builds", gorm.ErrInvalidSQL),
+			},
+		},
+		{
+			name:    "history sorted and linked to builds",
+			wantErr: false,
+			expects: expects{
+				getBuildsTimes: 1,
+				points:         3,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+
+			getCoverages := sqlStorage.EXPECT().
+				GetBy(gomock.Any(), gomock.Any(), gomock.Any()).
+				Do(func(ctx context.Context, e interface{}, qry ...interface{}) {
+					*e.(*[]models.Coverage) = append([]models.Coverage{}, coverages...)
+				}).
+				Return(tt.expects.getCoveragesError).
+				Times(1)
+
+			sqlStorage.EXPECT().
+				GetBy(gomock.Any(), gomock.Any(), gomock.Any()).
+				Do(func(ctx context.Context, e interface{}, qry ...interface{}) {
+					*e.(*[]models.Build) = builds
+				}).
+				Return(tt.expects.getBuildsError).
+				After(getCoverages).
+				Times(tt.expects.getBuildsTimes)
+
+			s := &Coverage{
+				SQL: sqlStorage,
+			}
+
+			got, err := s.GetHistory(context.Background(), "hbalmes/ci-cd_api", "develop")
+
+			if tt.wantErr {
+				assert.Nil(t, got)
+				assert.Equal(t, tt.expects.err, err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expects.points, len(got))
+			assert.Equal(t, "sha1", *got[0].Sha)
+			assert.Equal(t, uint32(12), got[0].Build.ID)
+			assert.Equal(t, "sha2", *got[1].Sha)
+			assert.Nil(t, got[1].Build)
+			//The release commit of the build is not the reported sha
+			assert.Equal(t, "sha3", *got[2].Sha)
+			assert.Equal(t, uint32(13), got[2].Build.ID)
+		})
+	}
+}
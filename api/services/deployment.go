@@ -0,0 +1,346 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/coreos/go-semver/semver"
+	"github.com/hbalmes/ci_cd-api/api/clients"
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/models/webhook"
+	"github.com/hbalmes/ci_cd-api/api/services/storage"
+	"github.com/hbalmes/ci_cd-api/api/utils"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"github.com/hbalmes/ci_cd-api/api/utils/logger"
+	"github.com/hbalmes/ci_cd-api/api/utils/tracing"
+	"github.com/jinzhu/gorm"
+)
+
+//DeploymentService is an interface which represents the DeploymentService for testing purpose.
+type DeploymentService interface {
+	Create(ctx context.Context, repositoryName string, r *models.DeploymentPayload) (*models.Deployment, apierrors.ApiError)
+	List(ctx context.Context, repositoryName string, environment string) ([]models.Deployment, apierrors.ApiError)
+	ProcessStatus(ctx context.Context, payload *webhook.DeploymentStatus) (*models.Deployment, apierrors.ApiError)
+}
+
+//Deployment represents the DeploymentService layer
+//It has an instance of a DBClient layer,
+//A github client instance,
+//A ConfigService instance,
+//An ApprovalService instance and
+//A FreezeWindowService instance
+type Deployment struct {
+	SQL                 storage.SQLStorage
+	GithubClient        clients.GithubClient
+	ConfigService       ConfigurationService
+	ApprovalService     ApprovalService
+	FreezeWindowService FreezeWindowService
+}
+
+//NewDeploymentService initializes a DeploymentService
+func NewDeploymentService(sql storage.SQLStorage, githubClient clients.GithubClient, configService ConfigurationService, approvalService ApprovalService, freezeWindowService FreezeWindowService) *Deployment {
+	return &Deployment{
+		SQL:                 sql,
+		GithubClient:        githubClient,
+		ConfigService:       configService,
+		ApprovalService:     approvalService,
+		FreezeWindowService: freezeWindowService,
+	}
+}
+
+//Create requests the deployment of a build into an environment of the repository.
+//The latest build is deployed when the payload has no version.
+//The deployment is created in github too, where it waits for the deployer.
+//Rolled back builds are refused.
+//Deployments to environments with an approval gate are refused until the build is approved.
+//Deployments to production environments are refused during the freeze windows, unless overridden with a reason.
+//The deployment is requested by the github user of the api token, or by the token itself for the pipelines,
+//the requester of the payload must be that one when sent.
+func (s *Deployment) Create(ctx context.Context, repositoryName string, r *models.DeploymentPayload) (*models.Deployment, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "Deployment.Create")
+	defer span.End()
+
+	requester, apiErr := Requester(ctx)
+
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	if r.RequestedBy != nil && *r.RequestedBy != "" && !strings.EqualFold(*r.RequestedBy, requester) {
+		return nil, apierrors.NewForbiddenApiError(fmt.Sprintf("the requester %s is not the authenticated user %s", *r.RequestedBy, requester))
+	}
+
+	if r.Environment == nil || *r.Environment == "" {
+		return nil, apierrors.NewBadRequestApiError("the deployment environment is required")
+	}
+
+	config, err := s.ConfigService.Get(ctx, repositoryName)
+
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierrors.NewNotFoundApiError(fmt.Sprintf("configuration for repository %s not found", repositoryName))
+		}
+		return nil, apierrors.NewInternalServerApiError("error checking configuration existance", err)
+	}
+
+	environment := config.GetEnvironment(*r.Environment)
+
+	if environment == nil {
+		return nil, apierrors.NewBadRequestApiError(fmt.Sprintf("environment %s not configured for the repository", *r.Environment))
+	}
+
+	build, apiErr := s.GetBuild(ctx, repositoryName, r.Version)
+
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	if build.Status != nil && *build.Status == models.BuildStatusRolledBack {
+		return nil, apierrors.NewApiError(fmt.Sprintf("build %d was rolled back and can not be deployed", build.ID), "conflict_error", http.StatusConflict, apierrors.CauseList{})
+	}
+
+	if environment.HasApprovalGate() {
+		gate, apiErr := s.ApprovalService.CheckGate(ctx, config, environment, build.ID)
+
+		if apiErr != nil {
+			return nil, apiErr
+		}
+
+		if !gate.Satisfied {
+			return nil, apierrors.NewApiError(gate.Pending(), "conflict_error", http.StatusConflict, apierrors.CauseList{})
+		}
+	}
+
+	if environment.Production {
+		if apiErr := s.checkFreeze(ctx, repositoryName, environment, build, r); apiErr != nil {
+			return nil, apiErr
+		}
+	}
+
+	version := fmt.Sprintf("v%d.%d.%d", build.Major, build.Minor, build.Patch)
+	if build.Tag != nil {
+		version = version + "-" + *build.Tag
+	}
+
+	description := fmt.Sprintf("Deploy %s to %s", version, environment.Name)
+	if r.Description != nil {
+		description = *r.Description
+	}
+
+	deployment := models.Deployment{
+		RepositoryName: utils.Stringify(repositoryName),
+		Environment:    utils.Stringify(environment.Name),
+		BuildID:        build.ID,
+		Version:        utils.Stringify(version),
+		Sha:            build.Sha,
+		Status:         utils.Stringify(models.DeploymentStatusPending),
+		Description:    utils.Stringify(description),
+		RequestedBy:    utils.Stringify(requester),
+	}
+
+	//Save it into database
+	if err := s.SQL.Insert(ctx, &deployment); err != nil {
+		return nil, apierrors.NewInternalServerApiError("error saving new deployment", err)
+	}
+
+	ghDeployment, apiErr := s.GithubClient.CreateDeployment(ctx, config, &models.DeploymentRequest{
+		Ref:                   *build.Sha,
+		Environment:           environment.Name,
+		Description:           description,
+		AutoMerge:             false,
+		RequiredContexts:      []string{},
+		ProductionEnvironment: environment.Production,
+	})
+
+	if apiErr != nil {
+		deployment.Status = utils.Stringify(models.DeploymentStatusError)
+		if err := s.SQL.Update(ctx, &deployment); err != nil {
+			logger.FromContext(ctx).Error().Err(err).Uint32("deployment", deployment.ID).Msg("error updating deployment status")
+		}
+		return nil, apiErr
+	}
+
+	deployment.GithubDeploymentID = ghDeployment.ID
+	deployment.Status = utils.Stringify(models.DeploymentStatusQueued)
+
+	if err := s.SQL.Update(ctx, &deployment); err != nil {
+		return nil, apierrors.NewInternalServerApiError("error updating deployment", err)
+	}
+
+	if err := s.GithubClient.CreateDeploymentStatus(ctx, config, ghDeployment.ID, models.DeploymentStatusQueued, description); err != nil {
+		logger.FromContext(ctx).Error().Err(err).Uint32("deployment", deployment.ID).Msg("error creating github deployment status")
+	}
+
+	return &deployment, nil
+}
+
+//checkFreeze refuses the deployment when the repository is inside a freeze window.
+//An emergency override needs a reason and an api token bound to a github user, who is audited as its actor.
+func (s *Deployment) checkFreeze(ctx context.Context, repositoryName string, environment *models.Environment, build *models.Build, r *models.DeploymentPayload) apierrors.ApiError {
+
+	window, until, apiErr := s.FreezeWindowService.GetActive(ctx, repositoryName, time.Now())
+
+	if apiErr != nil {
+		return apiErr
+	}
+
+	if window == nil {
+		return nil
+	}
+
+	if r.FreezeOverrideReason == nil || *r.FreezeOverrideReason == "" {
+		return apierrors.NewApiError(fmt.Sprintf("deployments to %s are frozen by %s until %s", environment.Name, *window.Name, until.Format(freezeDateLayout)), "conflict_error", http.StatusConflict, apierrors.CauseList{})
+	}
+
+	actor, apiErr := AuthenticatedUser(ctx)
+
+	if apiErr != nil {
+		return apiErr
+	}
+
+	s.FreezeWindowService.RecordOverride(ctx, repositoryName, window, actor, *r.FreezeOverrideReason, nil, build.Sha)
+
+	return nil
+}
+
+//GetBuild returns the build of the repository with the given version, like v1.2.0 or v1.3.0-beta.
+//Returns the latest build when the version is nil.
+func (s *Deployment) GetBuild(ctx context.Context, repositoryName string, version *string) (*models.Build, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "Deployment.GetBuild")
+	defer span.End()
+
+	var build models.Build
+
+	if version == nil {
+		var latestBuild models.LatestBuild
+
+		if err := s.SQL.GetBy(ctx, &latestBuild, "repository_name = ?", repositoryName); err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, apierrors.NewNotFoundApiError("the repository has no builds to deploy")
+			}
+			return nil, apierrors.NewInternalServerApiError("error getting latest build", err)
+		}
+
+		if err := s.SQL.GetBy(ctx, &build, "id = ?", latestBuild.BuildID); err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, apierrors.NewNotFoundApiError("the repository has no builds to deploy")
+			}
+			return nil, apierrors.NewInternalServerApiError("error getting latest build", err)
+		}
+
+		return &build, nil
+	}
+
+	semVer, err := semver.NewVersion(strings.TrimPrefix(*version, "v"))
+
+	if err != nil {
+		return nil, apierrors.NewBadRequestApiError(fmt.Sprintf("invalid version %s", *version))
+	}
+
+	qry := []interface{}{"repository_name = ? AND major = ? AND minor = ? AND patch = ? AND tag IS NULL", repositoryName, semVer.Major, semVer.Minor, semVer.Patch}
+	if semVer.PreRelease != "" {
+		qry = []interface{}{"repository_name = ? AND major = ? AND minor = ? AND patch = ? AND tag = ?", repositoryName, semVer.Major, semVer.Minor, semVer.Patch, string(semVer.PreRelease)}
+	}
+
+	if err := s.SQL.GetBy(ctx, &build, qry...); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierrors.NewNotFoundApiError(fmt.Sprintf("build %s not found", *version))
+		}
+		return nil, apierrors.NewInternalServerApiError("error getting build", err)
+	}
+
+	return &build, nil
+}
+
+//List returns the deployments of a repository, the newest first.
+//When the environment is not empty, only its deployments are returned.
+func (s *Deployment) List(ctx context.Context, repositoryName string, environment string) ([]models.Deployment, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "Deployment.List")
+	defer span.End()
+
+	deployments := make([]models.Deployment, 0)
+
+	qry := []interface{}{"repository_name = ?", repositoryName}
+	if environment != "" {
+		qry = []interface{}{"repository_name = ? AND environment = ?", repositoryName, environment}
+	}
+
+	if err := s.SQL.GetBy(ctx, &deployments, qry...); err != nil {
+		return nil, apierrors.NewInternalServerApiError("error getting deployments", err)
+	}
+
+	sort.SliceStable(deployments, func(i, j int) bool {
+		return deployments[i].ID > deployments[j].ID
+	})
+
+	return deployments, nil
+}
+
+//ProcessStatus moves the deployment to the status reported by github.
+//When a deployment succeeds, the previous successful deployments of the environment become inactive.
+func (s *Deployment) ProcessStatus(ctx context.Context, payload *webhook.DeploymentStatus) (*models.Deployment, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "Deployment.ProcessStatus")
+	defer span.End()
+
+	var deployment models.Deployment
+
+	if err := s.SQL.GetBy(ctx, &deployment, "github_deployment_id = ?", payload.Deployment.ID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierrors.NewNotFoundApiError(fmt.Sprintf("deployment %d not found", payload.Deployment.ID))
+		}
+		return nil, apierrors.NewInternalServerApiError("error getting deployment", err)
+	}
+
+	state := *payload.DeploymentStatus.State
+
+	//The statuses created by this API come back as webhooks
+	if deployment.Status != nil && *deployment.Status == state {
+		return &deployment, nil
+	}
+
+	if !deployment.CanMoveTo(state) {
+		return nil, apierrors.NewApiError(fmt.Sprintf("invalid deployment status transition from %s to %s", *deployment.Status, state), "conflict_error", http.StatusConflict, apierrors.CauseList{})
+	}
+
+	deployment.Status = utils.Stringify(state)
+	if payload.DeploymentStatus.Description != nil && *payload.DeploymentStatus.Description != "" {
+		deployment.Description = payload.DeploymentStatus.Description
+	}
+
+	if state == models.DeploymentStatusSuccess {
+		now := time.Now()
+		deployment.DeployedAt = &now
+	}
+
+	if err := s.SQL.Update(ctx, &deployment); err != nil {
+		return nil, apierrors.NewInternalServerApiError("error updating deployment", err)
+	}
+
+	if state == models.DeploymentStatusSuccess {
+		s.deactivatePrevious(ctx, &deployment)
+	}
+
+	return &deployment, nil
+}
+
+func (s *Deployment) deactivatePrevious(ctx context.Context, deployment *models.Deployment) {
+
+	var previous []models.Deployment
+
+	if err := s.SQL.GetBy(ctx, &previous, "repository_name = ? AND environment = ? AND status = ? AND id <> ?",
+		*deployment.RepositoryName, *deployment.Environment, models.DeploymentStatusSuccess, deployment.ID); err != nil {
+		logger.FromContext(ctx).Error().Err(err).Uint32("deployment", deployment.ID).Msg("error getting previous deployments")
+		return
+	}
+
+	for i := range previous {
+		previous[i].Status = utils.Stringify(models.DeploymentStatusInactive)
+		if err := s.SQL.Update(ctx, &previous[i]); err != nil {
+			logger.FromContext(ctx).Error().Err(err).Uint32("deployment", previous[i].ID).Msg("error deactivating deployment")
+		}
+	}
+}
@@ -0,0 +1,575 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hbalmes/ci_cd-api/api/mocks/interfaces"
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/models/webhook"
+	"github.com/hbalmes/ci_cd-api/api/utils"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeployment_Create(t *testing.T) {
+	type expects struct {
+		configErr       error
+		latestErr       error
+		buildErr        error
+		buildStatus     string
+		insertErr       error
+		ghDeployment    *models.DeploymentResponse
+		ghDeploymentErr apierrors.ApiError
+		ghTimes         int
+		statusTimes     int
+		frozen          bool
+		overrideTimes   int
+		updates         []string
+		requestedBy     string
+		err             apierrors.ApiError
+	}
+
+	freezeEnd := time.Date(2020, 11, 30, 0, 0, 0, 0, time.UTC)
+
+	config := models.Configuration{
+		ID:              utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:  utils.Stringify("ci-cd_api"),
+		RepositoryOwner: utils.Stringify("hbalmes"),
+	}
+
+	build := models.Build{
+		ID:    12,
+		Sha:   utils.Stringify("buildsha"),
+		Major: 1,
+		Minor: 3,
+	}
+
+	newPayload := func(environment string, version *string) *models.DeploymentPayload {
+		return &models.DeploymentPayload{
+			Environment: utils.Stringify(environment),
+			Version:     version,
+		}
+	}
+
+	tests := []struct {
+		name      string
+		user      string
+		anonymous bool
+		payload   *models.DeploymentPayload
+		expects   expects
+	}{
+		{
+			name:      "request without api token",
+			anonymous: true,
+			payload:   newPayload("staging", nil),
+			expects: expects{
+				err: apierrors.NewForbiddenApiError("the request must be authenticated by an api token"),
+			},
+		},
+		{
+			name: "requested by another user",
+			user: "someone",
+			payload: &models.DeploymentPayload{
+				Environment: utils.Stringify("staging"),
+				RequestedBy: utils.Stringify("hbalmes"),
+			},
+			expects: expects{
+				err: apierrors.NewForbiddenApiError("the requester hbalmes is not the authenticated user someone"),
+			},
+		},
+		{
+			name: "requested on behalf of another user by a pipeline",
+			payload: &models.DeploymentPayload{
+				Environment: utils.Stringify("staging"),
+				RequestedBy: utils.Stringify("hbalmes"),
+			},
+			expects: expects{
+				err: apierrors.NewForbiddenApiError("the requester hbalmes is not the authenticated user deploy-pipeline"),
+			},
+		},
+		{
+			name:    "environment required",
+			payload: &models.DeploymentPayload{},
+			expects: expects{
+				err: apierrors.NewBadRequestApiError("the deployment environment is required"),
+			},
+		},
+		{
+			name:    "configuration not found",
+			payload: newPayload("staging", nil),
+			expects: expects{
+				configErr: gorm.ErrRecordNotFound,
+				err:       apierrors.NewNotFoundApiError("configuration for repository hbalmes/ci-cd_api not found"),
+			},
+		},
+		{
+			name:    "environment not configured",
+			payload: newPayload("qa", nil),
+			expects: expects{
+				err: apierrors.NewBadRequestApiError("environment qa not configured for the repository"),
+			},
+		},
+		{
+			name:    "repository without builds",
+			payload: newPayload("staging", nil),
+			expects: expects{
+				latestErr: gorm.ErrRecordNotFound,
+				err:       apierrors.NewNotFoundApiError("the repository has no builds to deploy"),
+			},
+		},
+		{
+			name:    "invalid version",
+			payload: newPayload("staging", utils.Stringify("latest")),
+			expects: expects{
+				err: apierrors.NewBadRequestApiError("invalid version latest"),
+			},
+		},
+		{
+			name:    "version not found",
+			payload: newPayload("staging", utils.Stringify("v9.9.9")),
+			expects: expects{
+				buildErr: gorm.ErrRecordNotFound,
+				err:      apierrors.NewNotFoundApiError("build v9.9.9 not found"),
+			},
+		},
+		{
+			name:    "rolled back build",
+			payload: newPayload("staging", utils.Stringify("v1.3.0")),
+			expects: expects{
+				buildStatus: models.BuildStatusRolledBack,
+				err:         apierrors.NewApiError("build 12 was rolled back and can not be deployed", "conflict_error", http.StatusConflict, apierrors.CauseList{}),
+			},
+		},
+		{
+			name:    "error saving the deployment",
+			payload: newPayload("staging", nil),
+			expects: expects{
+				insertErr: errors.New("insert error"),
+				err:       apierrors.NewInternalServerApiError("error saving new deployment", errors.New("insert error")),
+			},
+		},
+		{
+			name:    "github deployment not created",
+			payload: newPayload("staging", nil),
+			expects: expects{
+				ghDeploymentErr: apierrors.NewBadRequestApiError("deployment not created"),
+				ghTimes:         1,
+				updates:         []string{models.DeploymentStatusError},
+				err:             apierrors.NewBadRequestApiError("deployment not created"),
+			},
+		},
+		{
+			name:    "production frozen",
+			payload: newPayload("production", utils.Stringify("v1.3.0")),
+			expects: expects{
+				frozen: true,
+				err:    apierrors.NewApiError("deployments to production are frozen by black-friday until 2020-11-30 00:00 UTC", "conflict_error", http.StatusConflict, apierrors.CauseList{}),
+			},
+		},
+		{
+			name: "freeze override with an api token not bound to a user",
+			payload: &models.DeploymentPayload{
+				Environment:          utils.Stringify("production"),
+				Version:              utils.Stringify("v1.3.0"),
+				FreezeOverrideReason: utils.Stringify("payments outage"),
+			},
+			expects: expects{
+				frozen: true,
+				err:    apierrors.NewForbiddenApiError("the api token must be bound to a github user"),
+			},
+		},
+		{
+			name: "freeze override requested by another user",
+			user: "someone",
+			payload: &models.DeploymentPayload{
+				Environment:          utils.Stringify("production"),
+				Version:              utils.Stringify("v1.3.0"),
+				RequestedBy:          utils.Stringify("hbalmes"),
+				FreezeOverrideReason: utils.Stringify("payments outage"),
+			},
+			expects: expects{
+				frozen: true,
+				err:    apierrors.NewForbiddenApiError("the requester hbalmes is not the authenticated user someone"),
+			},
+		},
+		{
+			name: "production freeze overridden",
+			user: "hbalmes",
+			payload: &models.DeploymentPayload{
+				Environment:          utils.Stringify("production"),
+				Version:              utils.Stringify("v1.3.0"),
+				RequestedBy:          utils.Stringify("HBalmes"),
+				FreezeOverrideReason: utils.Stringify("payments outage"),
+			},
+			expects: expects{
+				frozen:        true,
+				overrideTimes: 1,
+				ghDeployment:  &models.DeploymentResponse{ID: 99},
+				ghTimes:       1,
+				statusTimes:   1,
+				updates:       []string{models.DeploymentStatusQueued},
+				requestedBy:   "hbalmes",
+			},
+		},
+		{
+			name:    "deployment queued by a pipeline",
+			payload: newPayload("production", utils.Stringify("v1.3.0")),
+			expects: expects{
+				ghDeployment: &models.DeploymentResponse{ID: 99},
+				ghTimes:      1,
+				statusTimes:  1,
+				updates:      []string{models.DeploymentStatusQueued},
+				requestedBy:  "deploy-pipeline",
+			},
+		},
+		{
+			name:    "deployment queued by a user",
+			user:    "hbalmes",
+			payload: newPayload("production", utils.Stringify("v1.3.0")),
+			expects: expects{
+				ghDeployment: &models.DeploymentResponse{ID: 99},
+				ghTimes:      1,
+				statusTimes:  1,
+				updates:      []string{models.DeploymentStatusQueued},
+				requestedBy:  "hbalmes",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+			githubClient := interfaces.NewMockGithubClient(ctrl)
+			configService := interfaces.NewMockConfigurationService(ctrl)
+			freezeWindowService := interfaces.NewMockFreezeWindowService(ctrl)
+
+			configService.EXPECT().
+				Get(gomock.Any(), "hbalmes/ci-cd_api").
+				Return(&config, tt.expects.configErr).
+				AnyTimes()
+
+			var window *models.FreezeWindow
+			var until *time.Time
+			if tt.expects.frozen {
+				window = &models.FreezeWindow{Name: utils.Stringify("black-friday")}
+				until = &freezeEnd
+			}
+
+			freezeWindowService.EXPECT().
+				GetActive(gomock.Any(), "hbalmes/ci-cd_api", gomock.Any()).
+				Return(window, until, nil).
+				AnyTimes()
+
+			freezeWindowService.EXPECT().
+				RecordOverride(gomock.Any(), "hbalmes/ci-cd_api", window, "hbalmes", "payments outage", nil, build.Sha).
+				Times(tt.expects.overrideTimes)
+
+			sqlStorage.EXPECT().
+				GetBy(gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, e interface{}, qry ...interface{}) error {
+					switch e := e.(type) {
+					case *models.LatestBuild:
+						e.BuildID = build.ID
+						return tt.expects.latestErr
+					case *models.Build:
+						*e = build
+						if tt.expects.buildStatus != "" {
+							e.Status = utils.Stringify(tt.expects.buildStatus)
+						}
+						return tt.expects.buildErr
+					}
+					return nil
+				}).
+				AnyTimes()
+
+			sqlStorage.EXPECT().
+				Insert(gomock.Any(), gomock.Any()).
+				Return(tt.expects.insertErr).
+				AnyTimes()
+
+			updates := make([]string, 0)
+			sqlStorage.EXPECT().
+				Update(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, e interface{}) error {
+					updates = append(updates, *e.(*models.Deployment).Status)
+					return nil
+				}).
+				AnyTimes()
+
+			githubClient.EXPECT().
+				CreateDeployment(gomock.Any(), &config, gomock.Any()).
+				DoAndReturn(func(ctx context.Context, config *models.Configuration, request *models.DeploymentRequest) (*models.DeploymentResponse, apierrors.ApiError) {
+					assert.Equal(t, "buildsha", request.Ref)
+					assert.Equal(t, *tt.payload.Environment, request.Environment)
+					assert.Equal(t, *tt.payload.Environment == "production", request.ProductionEnvironment)
+					return tt.expects.ghDeployment, tt.expects.ghDeploymentErr
+				}).
+				Times(tt.expects.ghTimes)
+
+			githubClient.EXPECT().
+				CreateDeploymentStatus(gomock.Any(), &config, int64(99), models.DeploymentStatusQueued, "Deploy v1.3.0 to production").
+				Return(nil).
+				Times(tt.expects.statusTimes)
+
+			s := &Deployment{
+				SQL:                 sqlStorage,
+				GithubClient:        githubClient,
+				ConfigService:       configService,
+				FreezeWindowService: freezeWindowService,
+			}
+
+			//The tokens of the pipelines are not bound to a user
+			ctx := context.Background()
+			if !tt.anonymous {
+				token := &models.APIToken{Name: utils.Stringify("deploy-pipeline")}
+				if tt.user != "" {
+					token.Subject = utils.Stringify(tt.user)
+				}
+				ctx = WithAPIToken(ctx, token)
+			}
+
+			deployment, err := s.Create(ctx, "hbalmes/ci-cd_api", tt.payload)
+
+			assert.Equal(t, tt.expects.err, err)
+			if tt.expects.updates != nil {
+				assert.Equal(t, tt.expects.updates, updates)
+			}
+			if err == nil {
+				assert.Equal(t, int64(99), deployment.GithubDeploymentID)
+				assert.Equal(t, "v1.3.0", *deployment.Version)
+				assert.Equal(t, build.ID, deployment.BuildID)
+				assert.Equal(t, tt.expects.requestedBy, *deployment.RequestedBy)
+			}
+		})
+	}
+}
+
+func TestDeployment_ProcessStatus(t *testing.T) {
+	type expects struct {
+		getErr    error
+		updateErr error
+		updates   []string
+		err       apierrors.ApiError
+	}
+
+	newPayload := func(state string) *webhook.DeploymentStatus {
+		var payload webhook.DeploymentStatus
+		payload.DeploymentStatus.State = utils.Stringify(state)
+		payload.DeploymentStatus.Description = utils.Stringify("deployed by the pipeline")
+		payload.Deployment.ID = 99
+		return &payload
+	}
+
+	tests := []struct {
+		name    string
+		status  string
+		payload *webhook.DeploymentStatus
+		expects expects
+	}{
+		{
+			name:    "deployment not found",
+			payload: newPayload(models.DeploymentStatusSuccess),
+			expects: expects{
+				getErr: gorm.ErrRecordNotFound,
+				err:    apierrors.NewNotFoundApiError("deployment 99 not found"),
+			},
+		},
+		{
+			name:    "status created by the api",
+			status:  models.DeploymentStatusQueued,
+			payload: newPayload(models.DeploymentStatusQueued),
+			expects: expects{
+				updates: []string{},
+			},
+		},
+		{
+			name:    "invalid transition",
+			status:  models.DeploymentStatusSuccess,
+			payload: newPayload(models.DeploymentStatusInProgress),
+			expects: expects{
+				updates: []string{},
+				err:     apierrors.NewApiError("invalid deployment status transition from success to in_progress", "conflict_error", http.StatusConflict, apierrors.CauseList{}),
+			},
+		},
+		{
+			name:    "error updating the deployment",
+			status:  models.DeploymentStatusQueued,
+			payload: newPayload(models.DeploymentStatusInProgress),
+			expects: expects{
+				updateErr: errors.New("update error"),
+				err:       apierrors.NewInternalServerApiError("error updating deployment", errors.New("update error")),
+			},
+		},
+		{
+			name:    "deployment in progress",
+			status:  models.DeploymentStatusQueued,
+			payload: newPayload(models.DeploymentStatusInProgress),
+			expects: expects{
+				updates: []string{models.DeploymentStatusInProgress},
+			},
+		},
+		{
+			name:    "deployment succeeded deactivates the previous",
+			status:  models.DeploymentStatusInProgress,
+			payload: newPayload(models.DeploymentStatusSuccess),
+			expects: expects{
+				updates: []string{models.DeploymentStatusSuccess, models.DeploymentStatusInactive},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+
+			sqlStorage.EXPECT().
+				GetBy(gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, e interface{}, qry ...interface{}) error {
+					switch e := e.(type) {
+					case *models.Deployment:
+						*e = models.Deployment{
+							ID:                 5,
+							RepositoryName:     utils.Stringify("hbalmes/ci-cd_api"),
+							Environment:        utils.Stringify("staging"),
+							Status:             utils.Stringify(tt.status),
+							GithubDeploymentID: 99,
+						}
+						return tt.expects.getErr
+					case *[]models.Deployment:
+						assert.Equal(t, []interface{}{"hbalmes/ci-cd_api", "staging", models.DeploymentStatusSuccess, uint32(5)}, qry[1:])
+						*e = []models.Deployment{{ID: 3, Status: utils.Stringify(models.DeploymentStatusSuccess)}}
+					}
+					return nil
+				}).
+				AnyTimes()
+
+			updates := make([]string, 0)
+			sqlStorage.EXPECT().
+				Update(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, e interface{}) error {
+					updates = append(updates, *e.(*models.Deployment).Status)
+					return tt.expects.updateErr
+				}).
+				AnyTimes()
+
+			s := &Deployment{
+				SQL: sqlStorage,
+			}
+
+			deployment, err := s.ProcessStatus(context.Background(), tt.payload)
+
+			assert.Equal(t, tt.expects.err, err)
+			if tt.expects.updates != nil {
+				assert.Equal(t, tt.expects.updates, updates)
+			}
+			if err == nil {
+				assert.Equal(t, *tt.payload.DeploymentStatus.State, *deployment.Status)
+			}
+		})
+	}
+}
+
+func TestDeployment_List(t *testing.T) {
+	tests := []struct {
+		name        string
+		environment string
+		qry         []interface{}
+		getErr      error
+		err         apierrors.ApiError
+	}{
+		{
+			name:   "error getting deployments",
+			qry:    []interface{}{"repository_name = ?", "hbalmes/ci-cd_api"},
+			getErr: errors.New("get error"),
+			err:    apierrors.NewInternalServerApiError("error getting deployments", errors.New("get error")),
+		},
+		{
+			name: "repository deployments",
+			qry:  []interface{}{"repository_name = ?", "hbalmes/ci-cd_api"},
+		},
+		{
+			name:        "environment deployments",
+			environment: "staging",
+			qry:         []interface{}{"repository_name = ? AND environment = ?", "hbalmes/ci-cd_api", "staging"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+
+			sqlStorage.EXPECT().
+				GetBy(gomock.Any(), gomock.Any(), tt.qry...).
+				DoAndReturn(func(ctx context.Context, e interface{}, qry ...interface{}) error {
+					*e.(*[]models.Deployment) = []models.Deployment{{ID: 1}, {ID: 3}, {ID: 2}}
+					return tt.getErr
+				}).
+				Times(1)
+
+			s := &Deployment{
+				SQL: sqlStorage,
+			}
+
+			deployments, err := s.List(context.Background(), "hbalmes/ci-cd_api", tt.environment)
+
+			assert.Equal(t, tt.err, err)
+			if err == nil {
+				assert.Equal(t, []uint32{3, 2, 1}, []uint32{deployments[0].ID, deployments[1].ID, deployments[2].ID})
+			}
+		})
+	}
+}
+
+func TestDeployment_CreateWaitingForApproval(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	config := newApprovalTestConfig()
+
+	sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+	configService := interfaces.NewMockConfigurationService(ctrl)
+	approvalService := interfaces.NewMockApprovalService(ctrl)
+
+	configService.EXPECT().
+		Get(gomock.Any(), "hbalmes/ci-cd_api").
+		Return(&config, nil).
+		Times(1)
+
+	sqlStorage.EXPECT().
+		GetBy(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, e interface{}, qry ...interface{}) error {
+			*e.(*models.Build) = models.Build{ID: 12, Sha: utils.Stringify("buildsha"), Major: 1, Minor: 3}
+			return nil
+		}).
+		Times(1)
+
+	approvalService.EXPECT().
+		CheckGate(gomock.Any(), &config, config.GetEnvironment("production"), uint32(12)).
+		Return(&models.ApprovalGate{Environment: "production", RequiredApprovals: 2, ApprovedBy: []string{"approver1"}}, nil).
+		Times(1)
+
+	s := &Deployment{
+		SQL:             sqlStorage,
+		ConfigService:   configService,
+		ApprovalService: approvalService,
+	}
+
+	_, err := s.Create(WithAPIToken(context.Background(), &models.APIToken{Name: utils.Stringify("deploy-pipeline")}), "hbalmes/ci-cd_api", &models.DeploymentPayload{
+		Environment: utils.Stringify("production"),
+		Version:     utils.Stringify("v1.3.0"),
+	})
+
+	assert.Equal(t, apierrors.NewApiError("the deployment to production has 1 of 2 required approvals", "conflict_error", http.StatusConflict, apierrors.CauseList{}), err)
+}
@@ -0,0 +1,500 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/hbalmes/ci_cd-api/api/configs"
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/services/storage"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"github.com/hbalmes/ci_cd-api/api/utils/logger"
+	"github.com/hbalmes/ci_cd-api/api/utils/tracing"
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	defaultDoraWindowDays = 30
+	doraDateLayout        = "2006-01-02"
+)
+
+//DoraService is an interface which represents the DoraService for testing purpose.
+type DoraService interface {
+	Get(ctx context.Context, query *models.DoraQuery) (*models.DoraMetrics, apierrors.ApiError)
+}
+
+//Dora represents the DoraService layer
+//It has an instance of a DBClient layer and
+//A ConfigService instance
+type Dora struct {
+	SQL           storage.SQLStorage
+	ConfigService ConfigurationService
+}
+
+//NewDoraService initializes a DoraService
+func NewDoraService(sql storage.SQLStorage, configService ConfigurationService) *Dora {
+	return &Dora{
+		SQL:           sql,
+		ConfigService: configService,
+	}
+}
+
+//NewDoraQuery builds the query of the DORA metrics from the request parameters.
+//The window goes from the from date until the end of the to date, today by default.
+//Without a from date, the window is the given days until the to date, 30 by default.
+func NewDoraQuery(repository string, team string, from string, to string, days string, now time.Time) (*models.DoraQuery, apierrors.ApiError) {
+
+	if (repository == "") == (team == "") {
+		return nil, apierrors.NewBadRequestApiError("either a repository or a team is required")
+	}
+
+	query := models.DoraQuery{
+		Repository: repository,
+		Team:       team,
+		To:         now,
+	}
+
+	if to != "" {
+		toDate, err := time.Parse(doraDateLayout, to)
+		if err != nil {
+			return nil, apierrors.NewBadRequestApiError(fmt.Sprintf("invalid to date %s, the layout is %s", to, doraDateLayout))
+		}
+		query.To = toDate.AddDate(0, 0, 1)
+	}
+
+	if from != "" {
+		fromDate, err := time.Parse(doraDateLayout, from)
+		if err != nil {
+			return nil, apierrors.NewBadRequestApiError(fmt.Sprintf("invalid from date %s, the layout is %s", from, doraDateLayout))
+		}
+		query.From = fromDate
+	} else {
+		windowDays := defaultDoraWindowDays
+		if days != "" {
+			n, err := strconv.Atoi(days)
+			if err != nil || n <= 0 {
+				return nil, apierrors.NewBadRequestApiError(fmt.Sprintf("invalid days %s", days))
+			}
+			windowDays = n
+		}
+		query.From = query.To.AddDate(0, 0, -windowDays)
+	}
+
+	if !query.To.After(query.From) {
+		return nil, apierrors.NewBadRequestApiError("the metrics window must end after it starts")
+	}
+
+	return &query, nil
+}
+
+//Get computes the DORA metrics of a repository, or of every repository of a team, over the window of the query.
+//Lead time for changes is the median time from the creation of the pull requests merged in the window until they reach production.
+//Deployment frequency counts the changes which reached production in the window.
+//Change failure rate is the proportion of those changes which failed,
+//and time to restore is the median time until the failed changes were restored.
+func (s *Dora) Get(ctx context.Context, query *models.DoraQuery) (*models.DoraMetrics, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "Dora.Get")
+	defer span.End()
+
+	configs, apiErr := s.getConfigurations(ctx, query)
+
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	metrics := models.DoraMetrics{
+		Repositories: make([]string, 0),
+		From:         query.From,
+		To:           query.To,
+		LeadTimes:    make([]models.DoraLeadTime, 0),
+		Deployments:  make([]models.DoraDeployment, 0),
+	}
+
+	if query.Repository != "" {
+		metrics.Repository = &query.Repository
+	} else {
+		metrics.Team = &query.Team
+	}
+
+	for i := range configs {
+		config := &configs[i]
+		metrics.Repositories = append(metrics.Repositories, *config.ID)
+
+		//The changes merged in the window could reach production after it
+		changes, apiErr := s.GetProductionChanges(ctx, config, query.From)
+
+		if apiErr != nil {
+			return nil, apiErr
+		}
+
+		leadTimes, apiErr := s.GetLeadTimes(ctx, config, changes, query)
+
+		if apiErr != nil {
+			return nil, apiErr
+		}
+
+		metrics.LeadTimes = append(metrics.LeadTimes, leadTimes...)
+
+		for _, change := range changes {
+			if change.DeployedAt.Before(query.To) {
+				metrics.Deployments = append(metrics.Deployments, change)
+			}
+		}
+	}
+
+	sort.SliceStable(metrics.LeadTimes, func(i, j int) bool {
+		return metrics.LeadTimes[i].MergedAt.Before(metrics.LeadTimes[j].MergedAt)
+	})
+
+	sort.SliceStable(metrics.Deployments, func(i, j int) bool {
+		return metrics.Deployments[i].DeployedAt.Before(metrics.Deployments[j].DeployedAt)
+	})
+
+	ComputeDoraMetrics(&metrics)
+
+	return &metrics, nil
+}
+
+func (s *Dora) getConfigurations(ctx context.Context, query *models.DoraQuery) ([]models.Configuration, apierrors.ApiError) {
+
+	if query.Repository != "" {
+		config, err := s.ConfigService.Get(ctx, query.Repository)
+
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, apierrors.NewNotFoundApiError(fmt.Sprintf("configuration for repository %s not found", query.Repository))
+			}
+			return nil, apierrors.NewInternalServerApiError("error checking configuration existance", err)
+		}
+
+		return []models.Configuration{*config}, nil
+	}
+
+	configs := make([]models.Configuration, 0)
+
+	if err := s.SQL.GetBy(ctx, &configs, "team = ?", query.Team); err != nil {
+		return nil, apierrors.NewInternalServerApiError("error getting team configurations", err)
+	}
+
+	if len(configs) == 0 {
+		return nil, apierrors.NewNotFoundApiError(fmt.Sprintf("team %s has no repositories configured", query.Team))
+	}
+
+	return configs, nil
+}
+
+//GetProductionChanges returns the changes which reached production since the given time, the oldest first.
+//The changes are the deployments to the production environment or,
+//for the repositories which do not deploy through this API, the productive releases.
+func (s *Dora) GetProductionChanges(ctx context.Context, config *models.Configuration, since time.Time) ([]models.DoraDeployment, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "Dora.GetProductionChanges")
+	defer span.End()
+
+	changes := make([]models.DoraDeployment, 0)
+	rolledBackAt := make(map[uint32]time.Time)
+
+	deployments := make([]models.Deployment, 0)
+
+	if environment := config.GetProductionEnvironment(); environment != nil {
+		if err := s.SQL.GetBy(ctx, &deployments, "repository_name = ? AND environment = ? AND status IN (?) AND updated_at >= ?", *config.ID, environment.Name,
+			[]string{models.DeploymentStatusSuccess, models.DeploymentStatusInactive, models.DeploymentStatusFailure}, since); err != nil {
+			return nil, apierrors.NewInternalServerApiError("error getting production deployments", err)
+		}
+	}
+
+	if len(deployments) > 0 {
+		buildIDs := make([]uint32, 0)
+		for _, d := range deployments {
+			buildIDs = append(buildIDs, d.BuildID)
+		}
+
+		builds := make([]models.Build, 0)
+
+		if err := s.SQL.GetBy(ctx, &builds, "id IN (?)", buildIDs); err != nil {
+			return nil, apierrors.NewInternalServerApiError("error getting deployed builds", err)
+		}
+
+		rolledBack := make(map[uint32]bool)
+		for _, b := range builds {
+			if at, ok := getRolledBackAt(ctx, &b); ok {
+				rolledBack[b.ID] = true
+				rolledBackAt[b.ID] = at
+			}
+		}
+
+		for _, d := range deployments {
+			deployedAt := d.UpdatedAt
+			if d.DeployedAt != nil {
+				deployedAt = *d.DeployedAt
+			}
+
+			if deployedAt.Before(since) {
+				continue
+			}
+
+			id := d.ID
+			change := models.DoraDeployment{
+				RepositoryName: *config.ID,
+				DeploymentID:   &id,
+				BuildID:        d.BuildID,
+				Status:         *d.Status,
+				DeployedAt:     deployedAt,
+				Failed:         *d.Status == models.DeploymentStatusFailure || rolledBack[d.BuildID],
+			}
+			if d.Version != nil {
+				change.Version = *d.Version
+			}
+
+			changes = append(changes, change)
+		}
+	} else {
+		builds := make([]models.Build, 0)
+
+		if err := s.SQL.GetBy(ctx, &builds, "repository_name = ? AND type = ? AND status IN (?) AND created_at >= ?", *config.ID, "productive",
+			[]string{models.BuildStatusFinished, models.BuildStatusRolledBack}, since.In(time.Local).Format(buildDateLayout)); err != nil {
+			return nil, apierrors.NewInternalServerApiError("error getting productive builds", err)
+		}
+
+		for _, b := range builds {
+			createdAt, err := time.ParseInLocation(buildDateLayout, *b.CreatedAt, time.Local)
+
+			if err != nil {
+				logger.FromContext(ctx).Error().Err(err).Uint32("build", b.ID).Msg("error parsing build creation date")
+				continue
+			}
+
+			at, rolledBack := getRolledBackAt(ctx, &b)
+			if rolledBack {
+				rolledBackAt[b.ID] = at
+			}
+
+			changes = append(changes, models.DoraDeployment{
+				RepositoryName: *config.ID,
+				BuildID:        b.ID,
+				Version:        getBuildVersion(&b),
+				Status:         *b.Status,
+				DeployedAt:     createdAt,
+				Failed:         rolledBack,
+			})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].DeployedAt.Before(changes[j].DeployedAt)
+	})
+
+	setRestoreTimes(changes, rolledBackAt)
+
+	return changes, nil
+}
+
+//GetLeadTimes returns the lead time data points of the pull requests merged in the window into the stable branches of the workflow.
+//The pull requests between stable branches, like the releases and the back-merges, only carry changes already counted.
+//The changes of a pull request reach production with the first successful change whose build lists it in its release notes.
+func (s *Dora) GetLeadTimes(ctx context.Context, config *models.Configuration, changes []models.DoraDeployment, query *models.DoraQuery) ([]models.DoraLeadTime, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "Dora.GetLeadTimes")
+	defer span.End()
+
+	leadTimes := make([]models.DoraLeadTime, 0)
+	pullRequests := make([]models.PullRequest, 0)
+
+	if err := s.SQL.GetBy(ctx, &pullRequests, "repository_name = ? AND merged_at >= ? AND merged_at < ?", *config.ID, query.From, query.To); err != nil {
+		return nil, apierrors.NewInternalServerApiError("error getting merged pull requests", err)
+	}
+
+	if len(pullRequests) == 0 {
+		return leadTimes, nil
+	}
+
+	releasedBy, apiErr := s.getReleasingChanges(ctx, changes)
+
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	wfc := configs.GetWorkflowConfiguration(config)
+
+	for _, pr := range pullRequests {
+		if pr.MergedAt == nil || pr.BaseRef == nil || wfc.GetStableBranch(*pr.BaseRef) == nil {
+			continue
+		}
+
+		if pr.HeadRef != nil && wfc.GetStableBranch(*pr.HeadRef) != nil {
+			continue
+		}
+
+		leadTime := models.DoraLeadTime{
+			RepositoryName:    *config.ID,
+			PullRequestNumber: pr.PullRequestNumber,
+			Title:             pr.Title,
+			CreatedAt:         pr.CreatedAt,
+			MergedAt:          *pr.MergedAt,
+		}
+
+		if change, ok := releasedBy[pr.PullRequestNumber]; ok {
+			deployedAt := change.DeployedAt
+			version := change.Version
+			minutes := roundDoraMetric(deployedAt.Sub(pr.CreatedAt).Minutes())
+
+			leadTime.DeployedAt = &deployedAt
+			leadTime.Version = &version
+			leadTime.LeadTimeMinutes = &minutes
+		}
+
+		leadTimes = append(leadTimes, leadTime)
+	}
+
+	return leadTimes, nil
+}
+
+//getReleasingChanges returns the first successful change releasing every pull request, by pull request number.
+//The changes must be sorted from the oldest.
+func (s *Dora) getReleasingChanges(ctx context.Context, changes []models.DoraDeployment) (map[int]models.DoraDeployment, apierrors.ApiError) {
+	releasedBy := make(map[int]models.DoraDeployment)
+
+	buildIDs := make([]uint32, 0)
+	seen := make(map[uint32]bool)
+	for _, change := range changes {
+		if change.Status != models.DeploymentStatusFailure && !seen[change.BuildID] {
+			seen[change.BuildID] = true
+			buildIDs = append(buildIDs, change.BuildID)
+		}
+	}
+
+	if len(buildIDs) == 0 {
+		return releasedBy, nil
+	}
+
+	released := make([]models.ReleasedPullRequest, 0)
+
+	if err := s.SQL.GetBy(ctx, &released, "build_id IN (?)", buildIDs); err != nil {
+		return nil, apierrors.NewInternalServerApiError("error getting released pull requests", err)
+	}
+
+	byBuild := make(map[uint32][]int)
+	for _, r := range released {
+		byBuild[r.BuildID] = append(byBuild[r.BuildID], r.PullRequestNumber)
+	}
+
+	for _, change := range changes {
+		if change.Status == models.DeploymentStatusFailure {
+			continue
+		}
+		for _, number := range byBuild[change.BuildID] {
+			if _, ok := releasedBy[number]; !ok {
+				releasedBy[number] = change
+			}
+		}
+	}
+
+	return releasedBy, nil
+}
+
+//ComputeDoraMetrics computes the DORA metrics from the data points of the metrics.
+func ComputeDoraMetrics(metrics *models.DoraMetrics) {
+
+	leadTimes := make([]float64, 0)
+	for _, lt := range metrics.LeadTimes {
+		if lt.LeadTimeMinutes != nil {
+			leadTimes = append(leadTimes, *lt.LeadTimeMinutes)
+		}
+	}
+
+	restoreTimes := make([]float64, 0)
+	failed := 0
+	for _, d := range metrics.Deployments {
+		if !d.Failed {
+			continue
+		}
+		failed++
+		if d.TimeToRestoreMinutes != nil {
+			restoreTimes = append(restoreTimes, *d.TimeToRestoreMinutes)
+		}
+	}
+
+	metrics.LeadTimeMinutes = getMedian(leadTimes)
+	metrics.DeploymentCount = len(metrics.Deployments)
+	metrics.DeploymentsPerDay = roundDoraMetric(float64(metrics.DeploymentCount) / (metrics.To.Sub(metrics.From).Hours() / 24))
+	metrics.FailedChangeCount = failed
+	metrics.TimeToRestoreMinutes = getMedian(restoreTimes)
+	metrics.ChangeFailureRate = nil
+
+	if metrics.DeploymentCount > 0 {
+		rate := roundDoraMetric(float64(failed) / float64(metrics.DeploymentCount))
+		metrics.ChangeFailureRate = &rate
+	}
+}
+
+//setRestoreTimes sets when every failed change was restored,
+//either by the rollback of its build or by the next successful change.
+func setRestoreTimes(changes []models.DoraDeployment, rolledBackAt map[uint32]time.Time) {
+	for i := range changes {
+		if !changes[i].Failed {
+			continue
+		}
+
+		var restoredAt *time.Time
+
+		if at, ok := rolledBackAt[changes[i].BuildID]; ok && at.After(changes[i].DeployedAt) {
+			restoredAt = &at
+		}
+
+		for j := i + 1; j < len(changes); j++ {
+			if changes[j].Failed || !changes[j].DeployedAt.After(changes[i].DeployedAt) {
+				continue
+			}
+			if restoredAt == nil || changes[j].DeployedAt.Before(*restoredAt) {
+				at := changes[j].DeployedAt
+				restoredAt = &at
+			}
+			break
+		}
+
+		if restoredAt != nil {
+			minutes := roundDoraMetric(restoredAt.Sub(changes[i].DeployedAt).Minutes())
+			changes[i].RestoredAt = restoredAt
+			changes[i].TimeToRestoreMinutes = &minutes
+		}
+	}
+}
+
+//getRolledBackAt returns when the build was rolled back, if it was.
+func getRolledBackAt(ctx context.Context, build *models.Build) (time.Time, bool) {
+	if build.Status == nil || *build.Status != models.BuildStatusRolledBack || build.UpdatedAt == nil {
+		return time.Time{}, false
+	}
+
+	at, err := time.ParseInLocation(buildDateLayout, *build.UpdatedAt, time.Local)
+
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Uint32("build", build.ID).Msg("error parsing build rollback date")
+		return time.Time{}, false
+	}
+
+	return at, true
+}
+
+func getMedian(values []float64) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+
+	median := sorted[len(sorted)/2]
+	if len(sorted)%2 == 0 {
+		median = (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+	}
+
+	median = roundDoraMetric(median)
+	return &median
+}
+
+func roundDoraMetric(value float64) float64 {
+	return math.Round(value*100) / 100
+}
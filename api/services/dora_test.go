@@ -0,0 +1,298 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hbalmes/ci_cd-api/api/mocks/interfaces"
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/utils"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	doraDeploymentsQuery  = "repository_name = ? AND environment = ? AND status IN (?) AND updated_at >= ?"
+	doraBuildsQuery       = "repository_name = ? AND type = ? AND status IN (?) AND created_at >= ?"
+	doraPullRequestsQuery = "repository_name = ? AND merged_at >= ? AND merged_at < ?"
+)
+
+func TestNewDoraQuery(t *testing.T) {
+	now := time.Date(2020, 11, 30, 12, 0, 0, 0, time.UTC)
+
+	type args struct {
+		repository string
+		team       string
+		from       string
+		to         string
+		days       string
+	}
+
+	tests := []struct {
+		name  string
+		args  args
+		query *models.DoraQuery
+		err   apierrors.ApiError
+	}{
+		{
+			name: "without repository nor team",
+			err:  apierrors.NewBadRequestApiError("either a repository or a team is required"),
+		},
+		{
+			name: "with repository and team",
+			args: args{repository: "hbalmes/ci-cd_api", team: "payments"},
+			err:  apierrors.NewBadRequestApiError("either a repository or a team is required"),
+		},
+		{
+			name: "invalid days",
+			args: args{repository: "hbalmes/ci-cd_api", days: "-7"},
+			err:  apierrors.NewBadRequestApiError("invalid days -7"),
+		},
+		{
+			name: "invalid from date",
+			args: args{team: "payments", from: "01/11/2020"},
+			err:  apierrors.NewBadRequestApiError("invalid from date 01/11/2020, the layout is 2006-01-02"),
+		},
+		{
+			name: "window ending before it starts",
+			args: args{team: "payments", from: "2020-11-10", to: "2020-11-01"},
+			err:  apierrors.NewBadRequestApiError("the metrics window must end after it starts"),
+		},
+		{
+			name:  "default window",
+			args:  args{repository: "hbalmes/ci-cd_api"},
+			query: &models.DoraQuery{Repository: "hbalmes/ci-cd_api", From: now.AddDate(0, 0, -30), To: now},
+		},
+		{
+			name:  "last days",
+			args:  args{team: "payments", days: "7"},
+			query: &models.DoraQuery{Team: "payments", From: now.AddDate(0, 0, -7), To: now},
+		},
+		{
+			name: "dates",
+			args: args{team: "payments", from: "2020-11-01", to: "2020-11-15"},
+			query: &models.DoraQuery{
+				Team: "payments",
+				From: time.Date(2020, 11, 1, 0, 0, 0, 0, time.UTC),
+				To:   time.Date(2020, 11, 16, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := NewDoraQuery(tt.args.repository, tt.args.team, tt.args.from, tt.args.to, tt.args.days, now)
+
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.query, query)
+		})
+	}
+}
+
+func TestDora_GetRepository(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+	configService := interfaces.NewMockConfigurationService(ctrl)
+
+	t0 := time.Date(2020, 11, 1, 0, 0, 0, 0, time.Local)
+	at := func(d time.Duration) time.Time { return t0.Add(d) }
+	day := 24 * time.Hour
+
+	config := models.Configuration{ID: utils.Stringify("hbalmes/ci-cd_api"), WorkflowType: utils.Stringify("gitflow")}
+
+	query := &models.DoraQuery{
+		Repository: "hbalmes/ci-cd_api",
+		From:       t0,
+		To:         at(10 * day),
+	}
+
+	configService.EXPECT().
+		Get(gomock.Any(), "hbalmes/ci-cd_api").
+		Return(&config, nil).
+		Times(1)
+
+	deployedAt := []time.Time{at(day), at(3 * day), at(3*day + 30*time.Minute)}
+
+	sqlStorage.EXPECT().
+		GetBy(gomock.Any(), gomock.Any(), doraDeploymentsQuery, "hbalmes/ci-cd_api", "production", gomock.Any(), t0).
+		DoAndReturn(func(ctx context.Context, deployments *[]models.Deployment, qry ...interface{}) error {
+			*deployments = []models.Deployment{
+				{ID: 1, BuildID: 10, Version: utils.Stringify("v1.2.0"), Status: utils.Stringify(models.DeploymentStatusInactive), DeployedAt: &deployedAt[0], UpdatedAt: at(3 * day)},
+				{ID: 2, BuildID: 11, Version: utils.Stringify("v1.3.0"), Status: utils.Stringify(models.DeploymentStatusFailure), UpdatedAt: at(2 * day)},
+				{ID: 3, BuildID: 12, Version: utils.Stringify("v1.4.0"), Status: utils.Stringify(models.DeploymentStatusInactive), DeployedAt: &deployedAt[1], UpdatedAt: at(3*day + 30*time.Minute)},
+				{ID: 4, BuildID: 10, Version: utils.Stringify("v1.2.0"), Status: utils.Stringify(models.DeploymentStatusSuccess), DeployedAt: &deployedAt[2], UpdatedAt: at(3*day + 30*time.Minute)},
+			}
+			return nil
+		}).
+		Times(1)
+
+	sqlStorage.EXPECT().
+		GetBy(gomock.Any(), gomock.Any(), "id IN (?)", []uint32{10, 11, 12, 10}).
+		DoAndReturn(func(ctx context.Context, builds *[]models.Build, qry ...interface{}) error {
+			*builds = []models.Build{
+				{ID: 10, Status: utils.Stringify(models.BuildStatusFinished)},
+				{ID: 11, Status: utils.Stringify(models.BuildStatusFinished)},
+				{ID: 12, Status: utils.Stringify(models.BuildStatusRolledBack), UpdatedAt: utils.Stringify(at(3*day + 2*time.Hour).Format(buildDateLayout))},
+			}
+			return nil
+		}).
+		Times(1)
+
+	mergedAt := []time.Time{at(12 * time.Hour), at(2*day - time.Hour), at(4 * day)}
+
+	sqlStorage.EXPECT().
+		GetBy(gomock.Any(), gomock.Any(), doraPullRequestsQuery, "hbalmes/ci-cd_api", query.From, query.To).
+		DoAndReturn(func(ctx context.Context, pullRequests *[]models.PullRequest, qry ...interface{}) error {
+			*pullRequests = []models.PullRequest{
+				{PullRequestNumber: 1, CreatedAt: t0, MergedAt: &mergedAt[0], BaseRef: utils.Stringify("develop"), HeadRef: utils.Stringify("feature/one")},
+				{PullRequestNumber: 2, CreatedAt: at(day), MergedAt: &mergedAt[1], BaseRef: utils.Stringify("develop"), HeadRef: utils.Stringify("feature/two")},
+				{PullRequestNumber: 3, CreatedAt: at(3 * day), MergedAt: &mergedAt[2], BaseRef: utils.Stringify("develop"), HeadRef: utils.Stringify("fix/three")},
+				{PullRequestNumber: 4, CreatedAt: at(day), MergedAt: &mergedAt[1], BaseRef: utils.Stringify("feature/two"), HeadRef: utils.Stringify("feature/four")},
+				{PullRequestNumber: 5, CreatedAt: at(2 * day), MergedAt: &mergedAt[2], BaseRef: utils.Stringify("master"), HeadRef: utils.Stringify("release/1.4.0")},
+			}
+			return nil
+		}).
+		Times(1)
+
+	//The second pull request is listed by the failed build and then by the next release
+	sqlStorage.EXPECT().
+		GetBy(gomock.Any(), gomock.Any(), "build_id IN (?)", []uint32{10, 12}).
+		DoAndReturn(func(ctx context.Context, released *[]models.ReleasedPullRequest, qry ...interface{}) error {
+			*released = []models.ReleasedPullRequest{
+				{BuildID: 10, PullRequestNumber: 1},
+				{BuildID: 12, PullRequestNumber: 2},
+				{BuildID: 12, PullRequestNumber: 5},
+			}
+			return nil
+		}).
+		Times(1)
+
+	s := &Dora{
+		SQL:           sqlStorage,
+		ConfigService: configService,
+	}
+
+	metrics, err := s.Get(context.Background(), query)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "hbalmes/ci-cd_api", *metrics.Repository)
+	assert.Equal(t, []string{"hbalmes/ci-cd_api"}, metrics.Repositories)
+
+	//Lead times of 1 and 2 days, the third pull request is not in production yet
+	//The pull request into a feature branch and the release are not counted
+	assert.Len(t, metrics.LeadTimes, 3)
+	assert.Equal(t, 1440.0, *metrics.LeadTimes[0].LeadTimeMinutes)
+	assert.Equal(t, "v1.2.0", *metrics.LeadTimes[0].Version)
+	assert.Equal(t, 2880.0, *metrics.LeadTimes[1].LeadTimeMinutes)
+	assert.Equal(t, "v1.4.0", *metrics.LeadTimes[1].Version)
+	assert.Nil(t, metrics.LeadTimes[2].LeadTimeMinutes)
+	assert.Equal(t, 2160.0, *metrics.LeadTimeMinutes)
+
+	assert.Equal(t, 4, metrics.DeploymentCount)
+	assert.Equal(t, 0.4, metrics.DeploymentsPerDay)
+
+	//The failed deployment is restored by the redeploy, and so is the rolled back build before its rollback
+	assert.Equal(t, 2, metrics.FailedChangeCount)
+	assert.Equal(t, 0.5, *metrics.ChangeFailureRate)
+	assert.Equal(t, 1470.0, *metrics.Deployments[1].TimeToRestoreMinutes)
+	assert.Equal(t, 30.0, *metrics.Deployments[2].TimeToRestoreMinutes)
+	assert.Equal(t, 750.0, *metrics.TimeToRestoreMinutes)
+}
+
+func TestDora_GetTeam(t *testing.T) {
+	t0 := time.Date(2020, 11, 1, 0, 0, 0, 0, time.Local)
+	day := 24 * time.Hour
+
+	query := &models.DoraQuery{
+		Team: "payments",
+		From: t0,
+		To:   t0.Add(10 * day),
+	}
+
+	tests := []struct {
+		name       string
+		configs    []models.Configuration
+		configsErr error
+		err        apierrors.ApiError
+	}{
+		{
+			name:       "error getting team configurations",
+			configsErr: gorm.ErrInvalidSQL,
+			err:        apierrors.NewInternalServerApiError("error getting team configurations", gorm.ErrInvalidSQL),
+		},
+		{
+			name: "team without repositories",
+			err:  apierrors.NewNotFoundApiError("team payments has no repositories configured"),
+		},
+		{
+			name:    "repositories without deployments",
+			configs: []models.Configuration{{ID: utils.Stringify("hbalmes/payments-api")}, {ID: utils.Stringify("hbalmes/payments-web")}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+
+			sqlStorage.EXPECT().
+				GetBy(gomock.Any(), gomock.Any(), "team = ?", "payments").
+				DoAndReturn(func(ctx context.Context, configs *[]models.Configuration, qry ...interface{}) error {
+					*configs = tt.configs
+					return tt.configsErr
+				}).
+				Times(1)
+
+			sqlStorage.EXPECT().
+				GetBy(gomock.Any(), gomock.Any(), doraDeploymentsQuery, gomock.Any(), "production", gomock.Any(), t0).
+				Return(nil).
+				Times(len(tt.configs))
+
+			sqlStorage.EXPECT().
+				GetBy(gomock.Any(), gomock.Any(), doraBuildsQuery, gomock.Any(), "productive", gomock.Any(), t0.Format(buildDateLayout)).
+				DoAndReturn(func(ctx context.Context, builds *[]models.Build, qry ...interface{}) error {
+					repository := qry[1].(string)
+					*builds = []models.Build{
+						{ID: 1, Major: 1, Status: utils.Stringify(models.BuildStatusFinished), CreatedAt: utils.Stringify(t0.Add(day).Format(buildDateLayout))},
+					}
+					if repository == "hbalmes/payments-web" {
+						*builds = []models.Build{
+							{ID: 2, Major: 2, Status: utils.Stringify(models.BuildStatusRolledBack), CreatedAt: utils.Stringify(t0.Add(2 * day).Format(buildDateLayout)), UpdatedAt: utils.Stringify(t0.Add(2*day + time.Hour).Format(buildDateLayout))},
+						}
+					}
+					return nil
+				}).
+				Times(len(tt.configs))
+
+			sqlStorage.EXPECT().
+				GetBy(gomock.Any(), gomock.Any(), doraPullRequestsQuery, gomock.Any(), query.From, query.To).
+				Return(nil).
+				Times(len(tt.configs))
+
+			s := &Dora{
+				SQL: sqlStorage,
+			}
+
+			metrics, err := s.Get(context.Background(), query)
+
+			assert.Equal(t, tt.err, err)
+			if err != nil {
+				return
+			}
+
+			assert.Equal(t, "payments", *metrics.Team)
+			assert.Equal(t, []string{"hbalmes/payments-api", "hbalmes/payments-web"}, metrics.Repositories)
+			assert.Equal(t, 2, metrics.DeploymentCount)
+			assert.Equal(t, "v1.0.0", metrics.Deployments[0].Version)
+			assert.Equal(t, "v2.0.0", metrics.Deployments[1].Version)
+			assert.Equal(t, 0.5, *metrics.ChangeFailureRate)
+			assert.Equal(t, 60.0, *metrics.TimeToRestoreMinutes)
+			assert.Nil(t, metrics.LeadTimeMinutes)
+		})
+	}
+}
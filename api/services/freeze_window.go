@@ -0,0 +1,276 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/services/storage"
+	"github.com/hbalmes/ci_cd-api/api/utils"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"github.com/hbalmes/ci_cd-api/api/utils/logger"
+	"github.com/hbalmes/ci_cd-api/api/utils/tracing"
+	"github.com/jinzhu/gorm"
+	"github.com/robfig/cron/v3"
+)
+
+const defaultFreezeWindowTimezone = "UTC"
+
+//FreezeWindowService is an interface which represents the FreezeWindowService for testing purpose.
+type FreezeWindowService interface {
+	Create(ctx context.Context, repositoryName string, r *models.FreezeWindowPayload) (*models.FreezeWindow, apierrors.ApiError)
+	List(ctx context.Context, repositoryName string) ([]models.FreezeWindow, apierrors.ApiError)
+	Delete(ctx context.Context, repositoryName string, id uint32) apierrors.ApiError
+	GetActive(ctx context.Context, repositoryName string, now time.Time) (*models.FreezeWindow, *time.Time, apierrors.ApiError)
+	RecordOverride(ctx context.Context, repositoryName string, window *models.FreezeWindow, actor string, reason string, branch *string, sha *string)
+}
+
+//FreezeWindow represents the FreezeWindowService layer
+//It has an instance of a DBClient layer,
+//A ConfigService instance and
+//An AuditService instance
+type FreezeWindow struct {
+	SQL           storage.SQLStorage
+	ConfigService ConfigurationService
+	AuditService  AuditService
+}
+
+//NewFreezeWindowService initializes a FreezeWindowService
+func NewFreezeWindowService(sql storage.SQLStorage, configService ConfigurationService, auditService AuditService) *FreezeWindow {
+	return &FreezeWindow{
+		SQL:           sql,
+		ConfigService: configService,
+		AuditService:  auditService,
+	}
+}
+
+//Create creates a freeze window for a configured repository.
+//The freeze window is global when the repository name is empty.
+func (s *FreezeWindow) Create(ctx context.Context, repositoryName string, r *models.FreezeWindowPayload) (*models.FreezeWindow, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "FreezeWindow.Create")
+	defer span.End()
+
+	window, apiErr := NewFreezeWindow(r)
+
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	if repositoryName != "" {
+		if _, err := s.ConfigService.Get(ctx, repositoryName); err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, apierrors.NewNotFoundApiError(fmt.Sprintf("configuration for repository %s not found", repositoryName))
+			}
+			return nil, apierrors.NewInternalServerApiError("error checking configuration existance", err)
+		}
+		window.RepositoryName = utils.Stringify(repositoryName)
+	}
+
+	//Save it into database
+	if err := s.SQL.Insert(ctx, window); err != nil {
+		return nil, apierrors.NewInternalServerApiError("error saving new freeze window", err)
+	}
+
+	return window, nil
+}
+
+//List returns the freeze windows which apply to a repository, the global ones included.
+//Only the global freeze windows are returned when the repository name is empty.
+func (s *FreezeWindow) List(ctx context.Context, repositoryName string) ([]models.FreezeWindow, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "FreezeWindow.List")
+	defer span.End()
+
+	windows := make([]models.FreezeWindow, 0)
+
+	qry := []interface{}{"repository_name IS NULL"}
+	if repositoryName != "" {
+		qry = []interface{}{"repository_name = ? OR repository_name IS NULL", repositoryName}
+	}
+
+	if err := s.SQL.GetBy(ctx, &windows, qry...); err != nil {
+		return nil, apierrors.NewInternalServerApiError("error getting freeze windows", err)
+	}
+
+	return windows, nil
+}
+
+//Delete erases a freeze window.
+//Global freeze windows can only be deleted when the repository name is empty.
+func (s *FreezeWindow) Delete(ctx context.Context, repositoryName string, id uint32) apierrors.ApiError {
+	ctx, span := tracing.Start(ctx, "FreezeWindow.Delete")
+	defer span.End()
+
+	var window models.FreezeWindow
+
+	qry := []interface{}{"id = ? AND repository_name IS NULL", id}
+	if repositoryName != "" {
+		qry = []interface{}{"id = ? AND repository_name = ?", id, repositoryName}
+	}
+
+	if err := s.SQL.GetBy(ctx, &window, qry...); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return apierrors.NewNotFoundApiError(fmt.Sprintf("freeze window %d not found", id))
+		}
+		return apierrors.NewInternalServerApiError("error getting freeze window", err)
+	}
+
+	if err := s.SQL.Delete(ctx, &window); err != nil {
+		return apierrors.NewInternalServerApiError("error deleting freeze window", err)
+	}
+
+	return nil
+}
+
+//GetActive returns the freeze window of the repository active at the given time and when it ends.
+//Returns nil when the repository is not frozen.
+func (s *FreezeWindow) GetActive(ctx context.Context, repositoryName string, now time.Time) (*models.FreezeWindow, *time.Time, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "FreezeWindow.GetActive")
+	defer span.End()
+
+	windows, err := s.List(ctx, repositoryName)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i := range windows {
+		if until := GetFreezeWindowEnd(ctx, &windows[i], now); until != nil {
+			return &windows[i], until, nil
+		}
+	}
+
+	return nil, nil, nil
+}
+
+//RecordOverride audits an emergency override of a freeze window.
+func (s *FreezeWindow) RecordOverride(ctx context.Context, repositoryName string, window *models.FreezeWindow, actor string, reason string, branch *string, sha *string) {
+	ctx, span := tracing.Start(ctx, "FreezeWindow.RecordOverride")
+	defer span.End()
+
+	event := models.AuditEvent{
+		RepositoryName: utils.Stringify(repositoryName),
+		Type:           utils.Stringify(models.AuditEventFreezeOverride),
+		Branch:         branch,
+		Sha:            sha,
+		Actor:          utils.Stringify(actor),
+		Description:    utils.Stringify(fmt.Sprintf("freeze window %s overridden: %s", *window.Name, reason)),
+	}
+
+	if err := s.AuditService.Record(ctx, &event); err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("error recording freeze window override")
+	}
+}
+
+//NewFreezeWindow converts a FreezeWindowPayload into a FreezeWindow.
+//The dates of the payload are parsed in the timezone of the payload.
+func NewFreezeWindow(r *models.FreezeWindowPayload) (*models.FreezeWindow, apierrors.ApiError) {
+
+	if r.Name == nil || *r.Name == "" {
+		return nil, apierrors.NewBadRequestApiError("the freeze window name is required")
+	}
+
+	timezone := defaultFreezeWindowTimezone
+	if r.Timezone != nil && *r.Timezone != "" {
+		timezone = *r.Timezone
+	}
+
+	location, err := time.LoadLocation(timezone)
+
+	if err != nil {
+		return nil, apierrors.NewBadRequestApiError(fmt.Sprintf("invalid timezone %s", timezone))
+	}
+
+	window := models.FreezeWindow{
+		Name:     r.Name,
+		Timezone: utils.Stringify(timezone),
+	}
+
+	isRange := r.StartsAt != nil || r.EndsAt != nil
+
+	if isRange == (r.Cron != nil) {
+		return nil, apierrors.NewBadRequestApiError("a freeze window needs either a date range or a cron expression")
+	}
+
+	if isRange {
+		if r.StartsAt == nil || r.EndsAt == nil {
+			return nil, apierrors.NewBadRequestApiError("the freeze window needs both starts_at and ends_at")
+		}
+
+		startsAt, startErr := time.ParseInLocation(models.FreezeWindowDateLayout, *r.StartsAt, location)
+		endsAt, endErr := time.ParseInLocation(models.FreezeWindowDateLayout, *r.EndsAt, location)
+
+		if startErr != nil || endErr != nil {
+			return nil, apierrors.NewBadRequestApiError(fmt.Sprintf("invalid freeze window dates, the layout is %s", models.FreezeWindowDateLayout))
+		}
+
+		if !endsAt.After(startsAt) {
+			return nil, apierrors.NewBadRequestApiError("the freeze window must end after it starts")
+		}
+
+		window.StartsAt = &startsAt
+		window.EndsAt = &endsAt
+
+		return &window, nil
+	}
+
+	if _, err := cron.ParseStandard(*r.Cron); err != nil {
+		return nil, apierrors.NewBadRequestApiError(fmt.Sprintf("invalid cron expression: %s", err.Error()))
+	}
+
+	if r.DurationMinutes == nil || *r.DurationMinutes <= 0 {
+		return nil, apierrors.NewBadRequestApiError("the freeze window duration is required")
+	}
+
+	window.Cron = r.Cron
+	window.DurationMinutes = *r.DurationMinutes
+
+	return &window, nil
+}
+
+//GetFreezeWindowEnd returns when the freeze window ends if it is active at the given time.
+//Recurrent freeze windows start every time the cron expression is due and last their duration.
+//Returns nil when the freeze window is not active.
+func GetFreezeWindowEnd(ctx context.Context, window *models.FreezeWindow, now time.Time) *time.Time {
+
+	if window.StartsAt != nil && window.EndsAt != nil {
+		if !now.Before(*window.StartsAt) && now.Before(*window.EndsAt) {
+			return window.EndsAt
+		}
+		return nil
+	}
+
+	if window.Cron == nil {
+		return nil
+	}
+
+	location, err := time.LoadLocation(*window.Timezone)
+
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Uint32("freeze_window", window.ID).Msg("invalid freeze window timezone")
+		return nil
+	}
+
+	schedule, err := cron.ParseStandard(*window.Cron)
+
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Uint32("freeze_window", window.ID).Msg("invalid freeze window cron expression")
+		return nil
+	}
+
+	duration := time.Duration(window.DurationMinutes) * time.Minute
+
+	//The latest start inside the duration, if any, is the first start after now minus the duration
+	start := schedule.Next(now.Add(-duration - time.Second).In(location))
+
+	if start.After(now) {
+		return nil
+	}
+
+	end := start.Add(duration)
+	if !now.Before(end) {
+		return nil
+	}
+
+	return &end
+}
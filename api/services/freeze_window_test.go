@@ -0,0 +1,324 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hbalmes/ci_cd-api/api/mocks/interfaces"
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/utils"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewFreezeWindow(t *testing.T) {
+	duration := 3600
+
+	tests := []struct {
+		name    string
+		payload models.FreezeWindowPayload
+		err     apierrors.ApiError
+	}{
+		{
+			name:    "without name",
+			payload: models.FreezeWindowPayload{Cron: utils.Stringify("0 18 * * 5"), DurationMinutes: &duration},
+			err:     apierrors.NewBadRequestApiError("the freeze window name is required"),
+		},
+		{
+			name:    "invalid timezone",
+			payload: models.FreezeWindowPayload{Name: utils.Stringify("weekend"), Timezone: utils.Stringify("Mars/Olympus")},
+			err:     apierrors.NewBadRequestApiError("invalid timezone Mars/Olympus"),
+		},
+		{
+			name:    "neither range nor cron",
+			payload: models.FreezeWindowPayload{Name: utils.Stringify("weekend")},
+			err:     apierrors.NewBadRequestApiError("a freeze window needs either a date range or a cron expression"),
+		},
+		{
+			name:    "both range and cron",
+			payload: models.FreezeWindowPayload{Name: utils.Stringify("weekend"), StartsAt: utils.Stringify("2020-11-27 00:00"), Cron: utils.Stringify("0 18 * * 5")},
+			err:     apierrors.NewBadRequestApiError("a freeze window needs either a date range or a cron expression"),
+		},
+		{
+			name:    "range without end",
+			payload: models.FreezeWindowPayload{Name: utils.Stringify("black-friday"), StartsAt: utils.Stringify("2020-11-27 00:00")},
+			err:     apierrors.NewBadRequestApiError("the freeze window needs both starts_at and ends_at"),
+		},
+		{
+			name:    "invalid dates",
+			payload: models.FreezeWindowPayload{Name: utils.Stringify("black-friday"), StartsAt: utils.Stringify("27/11/2020"), EndsAt: utils.Stringify("2020-11-30 00:00")},
+			err:     apierrors.NewBadRequestApiError("invalid freeze window dates, the layout is 2006-01-02 15:04"),
+		},
+		{
+			name:    "range ending before it starts",
+			payload: models.FreezeWindowPayload{Name: utils.Stringify("black-friday"), StartsAt: utils.Stringify("2020-11-30 00:00"), EndsAt: utils.Stringify("2020-11-27 00:00")},
+			err:     apierrors.NewBadRequestApiError("the freeze window must end after it starts"),
+		},
+		{
+			name:    "cron without duration",
+			payload: models.FreezeWindowPayload{Name: utils.Stringify("weekend"), Cron: utils.Stringify("0 18 * * 5")},
+			err:     apierrors.NewBadRequestApiError("the freeze window duration is required"),
+		},
+		{
+			name:    "valid range",
+			payload: models.FreezeWindowPayload{Name: utils.Stringify("black-friday"), StartsAt: utils.Stringify("2020-11-27 00:00"), EndsAt: utils.Stringify("2020-11-30 00:00"), Timezone: utils.Stringify("America/Argentina/Buenos_Aires")},
+		},
+		{
+			name:    "valid cron",
+			payload: models.FreezeWindowPayload{Name: utils.Stringify("weekend"), Cron: utils.Stringify("0 18 * * 5"), DurationMinutes: &duration},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			window, err := NewFreezeWindow(&tt.payload)
+
+			if tt.err != nil {
+				assert.Nil(t, window)
+				assert.Equal(t, tt.err, err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.payload.Name, window.Name)
+		})
+	}
+
+	t.Run("range dates in the timezone", func(t *testing.T) {
+		window, err := NewFreezeWindow(&models.FreezeWindowPayload{
+			Name:     utils.Stringify("black-friday"),
+			StartsAt: utils.Stringify("2020-11-27 00:00"),
+			EndsAt:   utils.Stringify("2020-11-30 00:00"),
+			Timezone: utils.Stringify("America/Argentina/Buenos_Aires"),
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, time.Date(2020, 11, 27, 3, 0, 0, 0, time.UTC), window.StartsAt.UTC())
+		assert.Equal(t, "America/Argentina/Buenos_Aires", *window.Timezone)
+	})
+}
+
+func TestGetFreezeWindowEnd(t *testing.T) {
+	startsAt := time.Date(2020, 11, 27, 0, 0, 0, 0, time.UTC)
+	endsAt := time.Date(2020, 11, 30, 0, 0, 0, 0, time.UTC)
+
+	rangeWindow := models.FreezeWindow{Name: utils.Stringify("black-friday"), StartsAt: &startsAt, EndsAt: &endsAt, Timezone: utils.Stringify("UTC")}
+
+	//Every friday at 18:00 in Buenos Aires (21:00 UTC) during the weekend
+	cronWindow := models.FreezeWindow{Name: utils.Stringify("weekend"), Cron: utils.Stringify("0 18 * * 5"), DurationMinutes: 60 * 60, Timezone: utils.Stringify("America/Argentina/Buenos_Aires")}
+	cronEnd := time.Date(2020, 11, 9, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		window models.FreezeWindow
+		now    time.Time
+		want   *time.Time
+	}{
+		{
+			name:   "before the range",
+			window: rangeWindow,
+			now:    time.Date(2020, 11, 26, 23, 59, 0, 0, time.UTC),
+		},
+		{
+			name:   "at the start of the range",
+			window: rangeWindow,
+			now:    startsAt,
+			want:   &endsAt,
+		},
+		{
+			name:   "at the end of the range",
+			window: rangeWindow,
+			now:    endsAt,
+		},
+		{
+			name:   "before the cron start",
+			window: cronWindow,
+			now:    time.Date(2020, 11, 6, 20, 59, 0, 0, time.UTC),
+		},
+		{
+			name:   "at the cron start",
+			window: cronWindow,
+			now:    time.Date(2020, 11, 6, 21, 0, 0, 0, time.UTC),
+			want:   &cronEnd,
+		},
+		{
+			name:   "inside the cron duration",
+			window: cronWindow,
+			now:    time.Date(2020, 11, 8, 12, 0, 0, 0, time.UTC),
+			want:   &cronEnd,
+		},
+		{
+			name:   "after the cron duration",
+			window: cronWindow,
+			now:    cronEnd,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GetFreezeWindowEnd(context.Background(), &tt.window, tt.now)
+
+			if tt.want == nil {
+				assert.Nil(t, got)
+				return
+			}
+
+			assert.NotNil(t, got)
+			assert.True(t, tt.want.Equal(*got))
+		})
+	}
+}
+
+func TestFreezeWindow_GetActive(t *testing.T) {
+	startsAt := time.Date(2020, 11, 27, 0, 0, 0, 0, time.UTC)
+	endsAt := time.Date(2020, 11, 30, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		repository string
+		qry        []interface{}
+		getErr     error
+		frozen     bool
+		now        time.Time
+		err        apierrors.ApiError
+	}{
+		{
+			name:       "error getting freeze windows",
+			repository: "hbalmes/ci-cd_api",
+			qry:        []interface{}{"repository_name = ? OR repository_name IS NULL", "hbalmes/ci-cd_api"},
+			getErr:     gorm.ErrInvalidSQL,
+			err:        apierrors.NewInternalServerApiError("error getting freeze windows", gorm.ErrInvalidSQL),
+		},
+		{
+			name:       "repository not frozen",
+			repository: "hbalmes/ci-cd_api",
+			qry:        []interface{}{"repository_name = ? OR repository_name IS NULL", "hbalmes/ci-cd_api"},
+			now:        time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "repository frozen",
+			repository: "hbalmes/ci-cd_api",
+			qry:        []interface{}{"repository_name = ? OR repository_name IS NULL", "hbalmes/ci-cd_api"},
+			now:        time.Date(2020, 11, 28, 0, 0, 0, 0, time.UTC),
+			frozen:     true,
+		},
+		{
+			name:   "global freeze windows",
+			qry:    []interface{}{"repository_name IS NULL"},
+			now:    time.Date(2020, 11, 28, 0, 0, 0, 0, time.UTC),
+			frozen: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+
+			sqlStorage.EXPECT().
+				GetBy(gomock.Any(), gomock.Any(), tt.qry...).
+				DoAndReturn(func(ctx context.Context, windows *[]models.FreezeWindow, qry ...interface{}) error {
+					*windows = []models.FreezeWindow{
+						{ID: 1, Name: utils.Stringify("black-friday"), StartsAt: &startsAt, EndsAt: &endsAt},
+					}
+					return tt.getErr
+				}).
+				Times(1)
+
+			s := &FreezeWindow{
+				SQL: sqlStorage,
+			}
+
+			window, until, err := s.GetActive(context.Background(), tt.repository, tt.now)
+
+			assert.Equal(t, tt.err, err)
+			if !tt.frozen {
+				assert.Nil(t, window)
+				assert.Nil(t, until)
+				return
+			}
+
+			assert.Equal(t, "black-friday", *window.Name)
+			assert.Equal(t, endsAt, *until)
+		})
+	}
+}
+
+func TestFreezeWindow_Delete(t *testing.T) {
+	tests := []struct {
+		name       string
+		repository string
+		qry        []interface{}
+		getErr     error
+		delTimes   int
+		err        apierrors.ApiError
+	}{
+		{
+			name:       "freeze window of another repository",
+			repository: "hbalmes/ci-cd_api",
+			qry:        []interface{}{"id = ? AND repository_name = ?", uint32(1), "hbalmes/ci-cd_api"},
+			getErr:     gorm.ErrRecordNotFound,
+			err:        apierrors.NewNotFoundApiError("freeze window 1 not found"),
+		},
+		{
+			name:       "repository freeze window",
+			repository: "hbalmes/ci-cd_api",
+			qry:        []interface{}{"id = ? AND repository_name = ?", uint32(1), "hbalmes/ci-cd_api"},
+			delTimes:   1,
+		},
+		{
+			name:     "global freeze window",
+			qry:      []interface{}{"id = ? AND repository_name IS NULL", uint32(1)},
+			delTimes: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+
+			sqlStorage.EXPECT().
+				GetBy(gomock.Any(), gomock.Any(), tt.qry...).
+				Return(tt.getErr).
+				Times(1)
+
+			sqlStorage.EXPECT().
+				Delete(gomock.Any(), gomock.Any()).
+				Return(nil).
+				Times(tt.delTimes)
+
+			s := &FreezeWindow{
+				SQL: sqlStorage,
+			}
+
+			assert.Equal(t, tt.err, s.Delete(context.Background(), tt.repository, 1))
+		})
+	}
+}
+
+func TestFreezeWindow_RecordOverride(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	auditService := interfaces.NewMockAuditService(ctrl)
+
+	auditService.EXPECT().
+		Record(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, event *models.AuditEvent) apierrors.ApiError {
+			assert.Equal(t, models.AuditEventFreezeOverride, *event.Type)
+			assert.Equal(t, "maintainer", *event.Actor)
+			assert.Equal(t, "freeze window black-friday overridden: security fix", *event.Description)
+			return nil
+		}).
+		Times(1)
+
+	s := &FreezeWindow{
+		AuditService: auditService,
+	}
+
+	s.RecordOverride(context.Background(), "hbalmes/ci-cd_api", &models.FreezeWindow{Name: utils.Stringify("black-friday")}, "maintainer", "security fix", nil, utils.Stringify("headsha"))
+}
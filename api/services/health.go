@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hbalmes/ci_cd-api/api/clients"
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/services/storage"
+	"github.com/hbalmes/ci_cd-api/api/utils/tracing"
+	"github.com/jinzhu/gorm"
+)
+
+//Readiness checks
+const (
+	HealthCheckDatabase   = "database"
+	HealthCheckMigrations = "migrations"
+	HealthCheckGithub     = "github"
+)
+
+//githubCheckTTL is how long the result of checking the github credentials is kept,
+//so the readiness probes do not consume the github rate limit
+const githubCheckTTL = time.Minute
+
+//HealthService is an interface which represents the HealthService for testing purpose.
+type HealthService interface {
+	Live() *models.Health
+	Ready(ctx context.Context) *models.Health
+}
+
+//Health represents the HealthService layer
+//It has an instance of a DBClient layer and a GithubClient
+type Health struct {
+	SQL          storage.SQLStorage
+	GithubClient clients.GithubClient
+
+	mu              sync.Mutex
+	githubCheck     models.HealthCheck
+	githubCheckedAt time.Time
+}
+
+//NewHealthService initializes a HealthService
+func NewHealthService(sql storage.SQLStorage, githubClient clients.GithubClient) *Health {
+	return &Health{
+		SQL:          sql,
+		GithubClient: githubClient,
+	}
+}
+
+//Live reports that the API is up.
+//It does not check any dependency, so a failing dependency never restarts the API.
+func (s *Health) Live() *models.Health {
+	return &models.Health{
+		Status: models.HealthStatusUp,
+	}
+}
+
+//Ready reports whether the API is able to serve requests.
+//It checks the database connection, the version of the database schema and the github credentials,
+//and it is up only when all of them are up.
+func (s *Health) Ready(ctx context.Context) *models.Health {
+	ctx, span := tracing.Start(ctx, "Health.Ready")
+	defer span.End()
+
+	health := models.Health{
+		Status: models.HealthStatusUp,
+		Checks: map[string]models.HealthCheck{
+			HealthCheckDatabase:   s.checkDatabase(ctx),
+			HealthCheckMigrations: s.checkMigrations(ctx),
+			HealthCheckGithub:     s.checkGithub(ctx),
+		},
+	}
+
+	for _, check := range health.Checks {
+		if check.Status != models.HealthStatusUp {
+			health.Status = models.HealthStatusDown
+		}
+	}
+
+	return &health
+}
+
+//checkDatabase checks the database connection
+func (s *Health) checkDatabase(ctx context.Context) models.HealthCheck {
+	if err := s.SQL.Ping(ctx); err != nil {
+		return downHealthCheck(err.Error())
+	}
+	return models.HealthCheck{Status: models.HealthStatusUp}
+}
+
+//checkMigrations checks that the database schema is at least the version expected by this API
+func (s *Health) checkMigrations(ctx context.Context) models.HealthCheck {
+	var migration models.SchemaMigration
+
+	if err := s.SQL.Get(ctx, &migration, storage.SchemaMigrationID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return downHealthCheck("database schema not migrated")
+		}
+		return downHealthCheck(err.Error())
+	}
+
+	if migration.Version < storage.SchemaVersion {
+		return downHealthCheck(fmt.Sprintf("database schema version %d, expected %d", migration.Version, storage.SchemaVersion))
+	}
+
+	return models.HealthCheck{Status: models.HealthStatusUp}
+}
+
+//checkGithub checks the github credentials
+//The result is kept for a while, see githubCheckTTL
+func (s *Health) checkGithub(ctx context.Context) models.HealthCheck {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.githubCheckedAt.IsZero() && time.Since(s.githubCheckedAt) < githubCheckTTL {
+		return s.githubCheck
+	}
+
+	s.githubCheck = models.HealthCheck{Status: models.HealthStatusUp}
+	if err := s.GithubClient.CheckCredentials(ctx); err != nil {
+		s.githubCheck = downHealthCheck(err.Message())
+	}
+	s.githubCheckedAt = time.Now()
+
+	return s.githubCheck
+}
+
+//downHealthCheck returns a failed check with the given error
+func downHealthCheck(err string) models.HealthCheck {
+	return models.HealthCheck{
+		Status: models.HealthStatusDown,
+		Error:  err,
+	}
+}
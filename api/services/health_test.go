@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hbalmes/ci_cd-api/api/mocks/interfaces"
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/services/storage"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealth_Live(t *testing.T) {
+	s := &Health{}
+
+	assert.Equal(t, &models.Health{Status: models.HealthStatusUp}, s.Live())
+}
+
+func TestHealth_Ready(t *testing.T) {
+	up := models.HealthCheck{Status: models.HealthStatusUp}
+
+	tests := []struct {
+		name          string
+		sqlPingError  error
+		sqlGetError   error
+		schemaVersion int
+		githubError   apierrors.ApiError
+		want          *models.Health
+	}{
+		{
+			name:          "every dependency up",
+			schemaVersion: storage.SchemaVersion,
+			want: &models.Health{
+				Status: models.HealthStatusUp,
+				Checks: map[string]models.HealthCheck{
+					HealthCheckDatabase:   up,
+					HealthCheckMigrations: up,
+					HealthCheckGithub:     up,
+				},
+			},
+		},
+		{
+			name:          "database schema migrated by a newer version",
+			schemaVersion: storage.SchemaVersion + 1,
+			want: &models.Health{
+				Status: models.HealthStatusUp,
+				Checks: map[string]models.HealthCheck{
+					HealthCheckDatabase:   up,
+					HealthCheckMigrations: up,
+					HealthCheckGithub:     up,
+				},
+			},
+		},
+		{
+			name:         "database down",
+			sqlPingError: gorm.ErrInvalidSQL,
+			sqlGetError:  gorm.ErrInvalidSQL,
+			want: &models.Health{
+				Status: models.HealthStatusDown,
+				Checks: map[string]models.HealthCheck{
+					HealthCheckDatabase:   {Status: models.HealthStatusDown, Error: gorm.ErrInvalidSQL.Error()},
+					HealthCheckMigrations: {Status: models.HealthStatusDown, Error: gorm.ErrInvalidSQL.Error()},
+					HealthCheckGithub:     up,
+				},
+			},
+		},
+		{
+			name:        "database schema not migrated",
+			sqlGetError: gorm.ErrRecordNotFound,
+			want: &models.Health{
+				Status: models.HealthStatusDown,
+				Checks: map[string]models.HealthCheck{
+					HealthCheckDatabase:   up,
+					HealthCheckMigrations: {Status: models.HealthStatusDown, Error: "database schema not migrated"},
+					HealthCheckGithub:     up,
+				},
+			},
+		},
+		{
+			name:          "database schema outdated",
+			schemaVersion: storage.SchemaVersion - 1,
+			want: &models.Health{
+				Status: models.HealthStatusDown,
+				Checks: map[string]models.HealthCheck{
+					HealthCheckDatabase:   up,
+					HealthCheckMigrations: {Status: models.HealthStatusDown, Error: fmt.Sprintf("database schema version %d, expected %d", storage.SchemaVersion-1, storage.SchemaVersion)},
+					HealthCheckGithub:     up,
+				},
+			},
+		},
+		{
+			name:          "invalid github credentials",
+			schemaVersion: storage.SchemaVersion,
+			githubError:   apierrors.NewApiError("invalid github credentials", "unauthorized", 401, apierrors.CauseList{}),
+			want: &models.Health{
+				Status: models.HealthStatusDown,
+				Checks: map[string]models.HealthCheck{
+					HealthCheckDatabase:   up,
+					HealthCheckMigrations: up,
+					HealthCheckGithub:     {Status: models.HealthStatusDown, Error: "invalid github credentials"},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+			githubClient := interfaces.NewMockGithubClient(ctrl)
+
+			sqlStorage.EXPECT().
+				Ping(gomock.Any()).
+				Return(tt.sqlPingError).
+				Times(1)
+
+			sqlStorage.EXPECT().
+				Get(gomock.Any(), gomock.Any(), storage.SchemaMigrationID).
+				DoAndReturn(func(ctx context.Context, migration *models.SchemaMigration, id interface{}) error {
+					migration.Version = tt.schemaVersion
+					return tt.sqlGetError
+				}).
+				Times(1)
+
+			//The github credentials are checked once, the second readiness check uses the kept result
+			githubClient.EXPECT().
+				CheckCredentials(gomock.Any()).
+				Return(tt.githubError).
+				Times(1)
+
+			s := &Health{
+				SQL:          sqlStorage,
+				GithubClient: githubClient,
+			}
+
+			assert.Equal(t, tt.want, s.Ready(context.Background()))
+
+			sqlStorage.EXPECT().Ping(gomock.Any()).Return(tt.sqlPingError)
+			sqlStorage.EXPECT().Get(gomock.Any(), gomock.Any(), storage.SchemaMigrationID).
+				DoAndReturn(func(ctx context.Context, migration *models.SchemaMigration, id interface{}) error {
+					migration.Version = tt.schemaVersion
+					return tt.sqlGetError
+				})
+
+			assert.Equal(t, tt.want, s.Ready(context.Background()))
+		})
+	}
+}
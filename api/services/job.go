@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/services/storage"
+	"github.com/hbalmes/ci_cd-api/api/utils"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"github.com/hbalmes/ci_cd-api/api/utils/logger"
+	"github.com/hbalmes/ci_cd-api/api/utils/tracing"
+	"github.com/jinzhu/gorm"
+)
+
+//jobStaleAfter is how long a running job is left alone before considering it interrupted.
+//It is longer than the shutdown timeout, so the jobs being drained by a stopping instance are not resumed twice.
+const jobStaleAfter = 5 * time.Minute
+
+//maxJobAttempts is how many times a job is processed before giving up on it
+const maxJobAttempts = 3
+
+//JobService is an interface which represents the JobService for testing purpose.
+type JobService interface {
+	Start(ctx context.Context, jobType string, payload interface{}) (*models.Job, apierrors.ApiError)
+	Finish(ctx context.Context, job *models.Job) apierrors.ApiError
+	ClaimInterrupted(ctx context.Context, now time.Time) ([]models.Job, apierrors.ApiError)
+}
+
+//Job represents the JobService layer
+//It has an instance of a DBClient layer
+type Job struct {
+	SQL storage.SQLStorage
+}
+
+//NewJobService initializes a JobService
+func NewJobService(sql storage.SQLStorage) *Job {
+	return &Job{
+		SQL: sql,
+	}
+}
+
+//Start persists a running job with its payload before processing it.
+func (s *Job) Start(ctx context.Context, jobType string, payload interface{}) (*models.Job, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "Job.Start")
+	defer span.End()
+
+	bytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, apierrors.NewInternalServerApiError("error marshalling job payload", err)
+	}
+
+	job := models.Job{
+		Type:     utils.Stringify(jobType),
+		Status:   utils.Stringify(models.JobStatusRunning),
+		Payload:  utils.Stringify(string(bytes)),
+		Attempts: 1,
+	}
+
+	if err := s.SQL.Insert(ctx, &job); err != nil {
+		return nil, apierrors.NewInternalServerApiError("error saving new job", err)
+	}
+
+	return &job, nil
+}
+
+//Finish deletes a processed job, so it is not resumed.
+func (s *Job) Finish(ctx context.Context, job *models.Job) apierrors.ApiError {
+	ctx, span := tracing.Start(ctx, "Job.Finish")
+	defer span.End()
+
+	if err := s.SQL.Delete(ctx, job); err != nil {
+		return apierrors.NewInternalServerApiError("error deleting finished job", err)
+	}
+
+	return nil
+}
+
+//ClaimInterrupted returns the running jobs not updated for a while, which were interrupted before finishing.
+//Every returned job counts a new attempt, and the jobs without attempts left are marked as failed instead.
+//A job is only claimed when it is still stale while updating it, so the instances resuming jobs at the same time
+//do not process it twice.
+func (s *Job) ClaimInterrupted(ctx context.Context, now time.Time) ([]models.Job, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "Job.ClaimInterrupted")
+	defer span.End()
+
+	var jobs []models.Job
+	staleBefore := now.Add(-jobStaleAfter)
+
+	if err := s.SQL.GetBy(ctx, &jobs, "status = ? AND updated_at < ?", models.JobStatusRunning, staleBefore); err != nil {
+		return nil, apierrors.NewInternalServerApiError("error getting interrupted jobs", err)
+	}
+
+	claimed := make([]models.Job, 0)
+
+	for _, job := range jobs {
+		job := job
+
+		//Updating the job refreshes its updated_at, so it is not claimed again while it is resumed
+		values := map[string]interface{}{"attempts": gorm.Expr("attempts + 1"), "updated_at": now}
+		if job.Attempts >= maxJobAttempts {
+			values = map[string]interface{}{"status": models.JobStatusFailed, "updated_at": now}
+		}
+
+		updated, err := s.SQL.UpdateBy(ctx, &models.Job{}, values, "id = ? AND status = ? AND updated_at < ?", job.ID, models.JobStatusRunning, staleBefore)
+
+		if err != nil {
+			return nil, apierrors.NewInternalServerApiError("error updating interrupted job", err)
+		}
+
+		//Another instance claimed it first
+		if updated != 1 {
+			logger.FromContext(ctx).Info().Uint32("job_id", job.ID).Str("type", *job.Type).Msg("job already claimed")
+			continue
+		}
+
+		if job.Attempts >= maxJobAttempts {
+			logger.FromContext(ctx).Error().Uint32("job_id", job.ID).Str("type", *job.Type).Int("attempts", job.Attempts).Msg("job failed, it is not resumed anymore")
+			continue
+		}
+
+		job.Attempts++
+		job.UpdatedAt = now
+		claimed = append(claimed, job)
+	}
+
+	return claimed, nil
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hbalmes/ci_cd-api/api/mocks/interfaces"
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/utils"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJob_Start(t *testing.T) {
+	tests := []struct {
+		name           string
+		sqlInsertError error
+		want           *models.Job
+		err            apierrors.ApiError
+	}{
+		{
+			name:           "error saving job",
+			sqlInsertError: gorm.ErrInvalidSQL,
+			err:            apierrors.NewInternalServerApiError("error saving new job", gorm.ErrInvalidSQL),
+		},
+		{
+			name: "job saved",
+			want: &models.Job{
+				Type:     utils.Stringify(models.JobTypeStatusWebhook),
+				Status:   utils.Stringify(models.JobStatusRunning),
+				Payload:  utils.Stringify(`{"sha":"23456789qwertyuiasdfghjzxcvbn"}`),
+				Attempts: 1,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+
+			sqlStorage.EXPECT().
+				Insert(gomock.Any(), gomock.Any()).
+				Return(tt.sqlInsertError).
+				Times(1)
+
+			s := &Job{
+				SQL: sqlStorage,
+			}
+
+			payload := map[string]string{"sha": "23456789qwertyuiasdfghjzxcvbn"}
+			job, err := s.Start(context.Background(), models.JobTypeStatusWebhook, payload)
+			assert.Equal(t, tt.want, job)
+			if tt.err == nil {
+				assert.Nil(t, err)
+				return
+			}
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestJob_Finish(t *testing.T) {
+	tests := []struct {
+		name           string
+		sqlDeleteError error
+		err            apierrors.ApiError
+	}{
+		{
+			name:           "error deleting job",
+			sqlDeleteError: gorm.ErrInvalidSQL,
+			err:            apierrors.NewInternalServerApiError("error deleting finished job", gorm.ErrInvalidSQL),
+		},
+		{
+			name: "job deleted",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+
+			job := &models.Job{ID: 1}
+
+			sqlStorage.EXPECT().
+				Delete(gomock.Any(), job).
+				Return(tt.sqlDeleteError).
+				Times(1)
+
+			s := &Job{
+				SQL: sqlStorage,
+			}
+
+			err := s.Finish(context.Background(), job)
+			if tt.err == nil {
+				assert.Nil(t, err)
+				return
+			}
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestJob_ClaimInterrupted(t *testing.T) {
+	now := time.Now()
+	staleBefore := now.Add(-jobStaleAfter)
+
+	interrupted := []models.Job{
+		{ID: 1, Type: utils.Stringify(models.JobTypeStatusWebhook), Status: utils.Stringify(models.JobStatusRunning), Attempts: 1},
+		{ID: 2, Type: utils.Stringify(models.JobTypeStatusWebhook), Status: utils.Stringify(models.JobStatusRunning), Attempts: maxJobAttempts},
+	}
+
+	type update struct {
+		id     uint32
+		values map[string]interface{}
+	}
+
+	claim := update{id: 1, values: map[string]interface{}{"attempts": gorm.Expr("attempts + 1"), "updated_at": now}}
+	fail := update{id: 2, values: map[string]interface{}{"status": models.JobStatusFailed, "updated_at": now}}
+
+	tests := []struct {
+		name           string
+		sqlGetError    error
+		sqlUpdateError error
+		updatedRows    int64
+		updateTimes    int
+		want           []models.Job
+		updated        []update
+		err            apierrors.ApiError
+	}{
+		{
+			name:        "error getting jobs",
+			sqlGetError: gorm.ErrInvalidSQL,
+			err:         apierrors.NewInternalServerApiError("error getting interrupted jobs", gorm.ErrInvalidSQL),
+		},
+		{
+			name:           "error updating job",
+			sqlUpdateError: gorm.ErrInvalidSQL,
+			updateTimes:    1,
+			err:            apierrors.NewInternalServerApiError("error updating interrupted job", gorm.ErrInvalidSQL),
+		},
+		{
+			name:        "jobs with attempts left claimed, the others failed",
+			updatedRows: 1,
+			updateTimes: 2,
+			want: []models.Job{
+				{ID: 1, Type: utils.Stringify(models.JobTypeStatusWebhook), Status: utils.Stringify(models.JobStatusRunning), Attempts: 2, UpdatedAt: now},
+			},
+			updated: []update{claim, fail},
+		},
+		{
+			name:        "jobs already claimed by another instance",
+			updateTimes: 2,
+			want:        []models.Job{},
+			updated:     []update{claim, fail},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+
+			sqlStorage.EXPECT().
+				GetBy(gomock.Any(), gomock.Any(), "status = ? AND updated_at < ?", models.JobStatusRunning, staleBefore).
+				DoAndReturn(func(ctx context.Context, jobs *[]models.Job, qry ...interface{}) error {
+					*jobs = append([]models.Job{}, interrupted...)
+					return tt.sqlGetError
+				}).
+				Times(1)
+
+			//The jobs are only updated while they are still running and stale
+			updated := make([]update, 0)
+			sqlStorage.EXPECT().
+				UpdateBy(gomock.Any(), &models.Job{}, gomock.Any(), "id = ? AND status = ? AND updated_at < ?", gomock.Any(), models.JobStatusRunning, staleBefore).
+				DoAndReturn(func(ctx context.Context, e interface{}, values map[string]interface{}, qry string, args ...interface{}) (int64, error) {
+					updated = append(updated, update{id: args[0].(uint32), values: values})
+					return tt.updatedRows, tt.sqlUpdateError
+				}).
+				Times(tt.updateTimes)
+
+			s := &Job{
+				SQL: sqlStorage,
+			}
+
+			jobs, err := s.ClaimInterrupted(context.Background(), now)
+			if tt.err != nil {
+				assert.Equal(t, tt.err, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, jobs)
+			assert.Equal(t, tt.updated, updated)
+		})
+	}
+}
@@ -0,0 +1,353 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/hbalmes/ci_cd-api/api/clients"
+	"github.com/hbalmes/ci_cd-api/api/configs"
+	"github.com/hbalmes/ci_cd-api/api/metrics"
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/models/webhook"
+	"github.com/hbalmes/ci_cd-api/api/services/storage"
+	"github.com/hbalmes/ci_cd-api/api/utils"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"github.com/hbalmes/ci_cd-api/api/utils/logger"
+	"github.com/hbalmes/ci_cd-api/api/utils/tracing"
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	mergeQueueLabel           = "merge-queue"
+	mergeQueueBranchPrefix    = "merge-queue/"
+	failureStatusWebhookState = "failure"
+	errorStatusWebhookState   = "error"
+)
+
+var activeMergeQueueStates = []string{models.MergeQueueQueued, models.MergeQueueTesting}
+
+//MergeQueueService is an interface which represents the MergeQueueService for testing purpose.
+type MergeQueueService interface {
+	Enqueue(ctx context.Context, config *models.Configuration, pullRequest *models.PullRequest, sender string) (*models.MergeQueueEntry, apierrors.ApiError)
+	Dequeue(ctx context.Context, config *models.Configuration, pullRequest *models.PullRequest) apierrors.ApiError
+	List(ctx context.Context, repositoryName string, branch string) ([]models.MergeQueueEntry, apierrors.ApiError)
+	Advance(ctx context.Context, config *models.Configuration, branch string) apierrors.ApiError
+	ProcessStatus(ctx context.Context, config *models.Configuration, payload *webhook.Status) apierrors.ApiError
+}
+
+//MergeQueue represents the MergeQueueService layer
+//It has an instance of a DBClient layer,
+//A github client instance and
+//A BuildService instance
+type MergeQueue struct {
+	SQL          storage.SQLStorage
+	GithubClient clients.GithubClient
+	BuildService BuildService
+}
+
+//NewMergeQueueService initializes a MergeQueueService
+func NewMergeQueueService(sql storage.SQLStorage, githubClient clients.GithubClient, buildService BuildService) *MergeQueue {
+	return &MergeQueue{
+		SQL:          sql,
+		GithubClient: githubClient,
+		BuildService: buildService,
+	}
+}
+
+//Enqueue adds the pull request at the end of the merge queue of its base branch.
+//Only pull requests against protected branches of the workflow can be queued.
+func (s *MergeQueue) Enqueue(ctx context.Context, config *models.Configuration, pullRequest *models.PullRequest, sender string) (*models.MergeQueueEntry, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "MergeQueue.Enqueue")
+	defer span.End()
+
+	wfc := configs.GetWorkflowConfiguration(config)
+
+	if pullRequest.BaseRef == nil || wfc.GetStableBranch(*pullRequest.BaseRef) == nil {
+		return nil, apierrors.NewBadRequestApiError("merge queues are only available for protected branches")
+	}
+
+	if pullRequest.State != nil && *pullRequest.State == closedPullRequestState {
+		return nil, apierrors.NewBadRequestApiError("closed pull requests can not be queued")
+	}
+
+	if _, err := s.getActiveEntry(ctx, pullRequest); err == nil {
+		return nil, apierrors.NewApiError("pull request already queued", "conflict_error", http.StatusConflict, apierrors.CauseList{})
+	} else if err.Status() != http.StatusNotFound {
+		return nil, err
+	}
+
+	entry := models.MergeQueueEntry{
+		RepositoryName:    pullRequest.RepositoryName,
+		BaseBranch:        pullRequest.BaseRef,
+		PullRequestID:     pullRequest.ID,
+		PullRequestNumber: pullRequest.PullRequestNumber,
+		Status:            utils.Stringify(models.MergeQueueQueued),
+		QueuedBy:          utils.Stringify(sender),
+	}
+
+	//Save it into database
+	if err := s.SQL.Insert(ctx, &entry); err != nil {
+		return nil, apierrors.NewInternalServerApiError("error saving merge queue entry", err)
+	}
+
+	if err := s.Advance(ctx, config, *pullRequest.BaseRef); err != nil {
+		logger.FromContext(ctx).Error().Err(err).Str("branch", *pullRequest.BaseRef).Msg("error advancing merge queue")
+	}
+
+	return &entry, nil
+}
+
+//Dequeue removes the pull request from the merge queue of its base branch.
+//When the pull request was being tested, the next one in the queue starts testing.
+func (s *MergeQueue) Dequeue(ctx context.Context, config *models.Configuration, pullRequest *models.PullRequest) apierrors.ApiError {
+	ctx, span := tracing.Start(ctx, "MergeQueue.Dequeue")
+	defer span.End()
+
+	entry, err := s.getActiveEntry(ctx, pullRequest)
+
+	if err != nil {
+		return err
+	}
+
+	if closeErr := s.close(ctx, config, entry, models.MergeQueueDequeued, "removed from the queue"); closeErr != nil {
+		return closeErr
+	}
+
+	//Advancing also records the new size of the queue, it does nothing while another pull request is being tested
+	if err := s.Advance(ctx, config, *entry.BaseBranch); err != nil {
+		logger.FromContext(ctx).Error().Err(err).Str("branch", *entry.BaseBranch).Msg("error advancing merge queue")
+	}
+
+	return nil
+}
+
+//List returns the queued pull requests of a repository, in merge order.
+//When a branch is given, only the queue of that branch is returned and its size is recorded.
+func (s *MergeQueue) List(ctx context.Context, repositoryName string, branch string) ([]models.MergeQueueEntry, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "MergeQueue.List")
+	defer span.End()
+
+	entries := make([]models.MergeQueueEntry, 0)
+
+	qry := []interface{}{"repository_name = ? AND status IN (?)", repositoryName, activeMergeQueueStates}
+	if branch != "" {
+		qry = []interface{}{"repository_name = ? AND base_branch = ? AND status IN (?)", repositoryName, branch, activeMergeQueueStates}
+	}
+
+	if err := s.SQL.GetBy(ctx, &entries, qry...); err != nil {
+		return nil, apierrors.NewInternalServerApiError("error getting merge queue", err)
+	}
+
+	if branch != "" {
+		metrics.MergeQueueEntries.WithLabelValues(repositoryName, branch).Set(float64(len(entries)))
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].ID < entries[j].ID
+	})
+
+	return entries, nil
+}
+
+//Advance starts testing the head of the merge queue when nothing is being tested.
+//The pull request head is merged with the base branch into a temporary integration branch,
+//so the CI runs over the result of the merge. Pull requests in conflict are ejected.
+func (s *MergeQueue) Advance(ctx context.Context, config *models.Configuration, branch string) apierrors.ApiError {
+	ctx, span := tracing.Start(ctx, "MergeQueue.Advance")
+	defer span.End()
+
+	for {
+		entries, err := s.List(ctx, *config.ID, branch)
+
+		if err != nil {
+			return err
+		}
+
+		if len(entries) == 0 || *entries[0].Status == models.MergeQueueTesting {
+			return nil
+		}
+
+		head := entries[0]
+
+		var pullRequest models.PullRequest
+		if err := s.SQL.GetBy(ctx, &pullRequest, "id = ?", head.PullRequestID); err != nil {
+			return apierrors.NewInternalServerApiError("error getting queued pull request", err)
+		}
+
+		baseBranch, baseErr := s.GithubClient.GetBranchInformation(ctx, config, branch)
+
+		if baseErr != nil {
+			return baseErr
+		}
+
+		integrationBranch := fmt.Sprintf("%s%s/pr-%d", mergeQueueBranchPrefix, branch, head.PullRequestNumber)
+
+		if createErr := s.GithubClient.CreateBranch(ctx, config, &models.Branch{Name: utils.Stringify(integrationBranch)}, baseBranch.Commit.Sha); createErr != nil {
+			return createErr
+		}
+
+		head.IntegrationBranch = utils.Stringify(integrationBranch)
+
+		mergeSha, mergeErr := s.GithubClient.MergeBranch(ctx, config, integrationBranch, *pullRequest.HeadSha,
+			fmt.Sprintf("Merge queue: #%d into %s", head.PullRequestNumber, branch))
+
+		if mergeErr != nil {
+			if mergeErr.Status() != http.StatusConflict {
+				return mergeErr
+			}
+
+			if closeErr := s.close(ctx, config, &head, models.MergeQueueEjected, fmt.Sprintf("conflicts with **%s**", branch)); closeErr != nil {
+				return closeErr
+			}
+			continue
+		}
+
+		//The base branch already contains the pull request
+		if mergeSha == "" {
+			mergeSha = baseBranch.Commit.Sha
+		}
+
+		head.Status = utils.Stringify(models.MergeQueueTesting)
+		head.IntegrationSha = utils.Stringify(mergeSha)
+		head.HeadSha = pullRequest.HeadSha
+
+		if err := s.SQL.Update(ctx, &head); err != nil {
+			return apierrors.NewInternalServerApiError("error updating merge queue entry", err)
+		}
+
+		return nil
+	}
+}
+
+//ProcessStatus checks the status reported for an integration branch.
+//The pull request is merged once every required context is green and ejected as soon as one is red.
+//The merge is guarded by the tested head, when the head moved since the test started the pull request is tested again.
+func (s *MergeQueue) ProcessStatus(ctx context.Context, config *models.Configuration, payload *webhook.Status) apierrors.ApiError {
+	ctx, span := tracing.Start(ctx, "MergeQueue.ProcessStatus")
+	defer span.End()
+
+	var entry models.MergeQueueEntry
+
+	if err := s.SQL.GetBy(ctx, &entry, "integration_sha = ? AND status = ?", *payload.Sha, models.MergeQueueTesting); err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return apierrors.NewInternalServerApiError("error getting merge queue entry", err)
+		}
+		//The sha is not being tested by a merge queue
+		return nil
+	}
+
+	switch *payload.State {
+	case failureStatusWebhookState, errorStatusWebhookState:
+		if err := s.close(ctx, config, &entry, models.MergeQueueEjected, fmt.Sprintf("**%s** finished with **%s**", *payload.Context, *payload.State)); err != nil {
+			return err
+		}
+		return s.Advance(ctx, config, *entry.BaseBranch)
+
+	case statusWebhookSuccessState:
+		states := s.BuildService.GetStatusChecksState(ctx, mergeQueueStatusChecks(config), payload)
+
+		for _, passed := range states {
+			if !passed {
+				return nil
+			}
+		}
+
+		if err := s.GithubClient.MergePullRequest(ctx, config, entry.PullRequestNumber, config.GetAutoMergeMethod(), *entry.HeadSha); err != nil {
+			if err.Code() == clients.HeadModifiedErrorCode {
+				if retestErr := s.retest(ctx, config, &entry); retestErr != nil {
+					return retestErr
+				}
+				return s.Advance(ctx, config, *entry.BaseBranch)
+			}
+
+			if closeErr := s.close(ctx, config, &entry, models.MergeQueueEjected, fmt.Sprintf("github refused the merge: %s", err.Message())); closeErr != nil {
+				return closeErr
+			}
+			return s.Advance(ctx, config, *entry.BaseBranch)
+		}
+
+		if err := s.close(ctx, config, &entry, models.MergeQueueMerged, fmt.Sprintf("merged into **%s**", *entry.BaseBranch)); err != nil {
+			return err
+		}
+		return s.Advance(ctx, config, *entry.BaseBranch)
+	}
+
+	return nil
+}
+
+//close takes the entry out of the queue, removes its integration branch and notifies the pull request.
+func (s *MergeQueue) close(ctx context.Context, config *models.Configuration, entry *models.MergeQueueEntry, status string, reason string) apierrors.ApiError {
+
+	entry.Status = utils.Stringify(status)
+	entry.Reason = utils.Stringify(reason)
+
+	if err := s.SQL.Update(ctx, entry); err != nil {
+		return apierrors.NewInternalServerApiError("error updating merge queue entry", err)
+	}
+
+	if entry.IntegrationBranch != nil {
+		if err := s.GithubClient.DeleteBranch(ctx, config, *entry.IntegrationBranch); err != nil {
+			logger.FromContext(ctx).Error().Err(err).Str("branch", *entry.IntegrationBranch).Msg("error deleting integration branch")
+		}
+	}
+
+	emoji := ":x:"
+	if status == models.MergeQueueMerged {
+		emoji = ":white_check_mark:"
+	}
+
+	body := fmt.Sprintf("# Merge queue %s \n", emoji) + "\n" + fmt.Sprintf("> **%s**: %s", status, reason)
+	pullRequest := models.PullRequest{
+		ID:                entry.PullRequestID,
+		PullRequestNumber: entry.PullRequestNumber,
+		RepositoryName:    entry.RepositoryName,
+	}
+
+	if err := s.GithubClient.CreateIssueComment(ctx, config, &pullRequest, body); err != nil {
+		logger.FromContext(ctx).Error().Err(err).Int("pull_request", entry.PullRequestNumber).Msg("error notifying merge queue outcome")
+	}
+
+	return nil
+}
+
+//retest puts the entry back at its place in the queue, so the next advance tests its current head.
+func (s *MergeQueue) retest(ctx context.Context, config *models.Configuration, entry *models.MergeQueueEntry) apierrors.ApiError {
+
+	if entry.IntegrationBranch != nil {
+		if err := s.GithubClient.DeleteBranch(ctx, config, *entry.IntegrationBranch); err != nil {
+			logger.FromContext(ctx).Error().Err(err).Str("branch", *entry.IntegrationBranch).Msg("error deleting integration branch")
+		}
+	}
+
+	entry.Status = utils.Stringify(models.MergeQueueQueued)
+	entry.IntegrationBranch = nil
+	entry.IntegrationSha = nil
+	entry.HeadSha = nil
+
+	if err := s.SQL.Update(ctx, entry); err != nil {
+		return apierrors.NewInternalServerApiError("error updating merge queue entry", err)
+	}
+
+	return nil
+}
+
+func (s *MergeQueue) getActiveEntry(ctx context.Context, pullRequest *models.PullRequest) (*models.MergeQueueEntry, apierrors.ApiError) {
+	var entry models.MergeQueueEntry
+
+	if err := s.SQL.GetBy(ctx, &entry, "pull_request_id = ? AND status IN (?)", pullRequest.ID, activeMergeQueueStates); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierrors.NewNotFoundApiError("pull request is not queued")
+		}
+		return nil, apierrors.NewInternalServerApiError("error getting merge queue entry", err)
+	}
+
+	return &entry, nil
+}
+
+//mergeQueueStatusChecks returns the contexts that must be green on the integration branch.
+//The workflow is only checked over pull requests.
+func mergeQueueStatusChecks(config *models.Configuration) []string {
+	return utils.Remove(config.GetRequiredStatusCheck(), "workflow")
+}
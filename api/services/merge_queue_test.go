@@ -0,0 +1,437 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hbalmes/ci_cd-api/api/metrics"
+	"github.com/hbalmes/ci_cd-api/api/mocks/interfaces"
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/models/webhook"
+	"github.com/hbalmes/ci_cd-api/api/utils"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"github.com/jinzhu/gorm"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+//mergeQueueStorage keeps the merge queue entries in memory, so the queue advances as the service updates it.
+type mergeQueueStorage struct {
+	entries      []models.MergeQueueEntry
+	pullRequests map[int64]models.PullRequest
+}
+
+func (m *mergeQueueStorage) mock(sqlStorage *interfaces.MockSQLStorage) {
+	sqlStorage.EXPECT().
+		GetBy(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, e interface{}, qry ...interface{}) error {
+			switch dest := e.(type) {
+			case *[]models.MergeQueueEntry:
+				for _, entry := range m.entries {
+					if *entry.Status == models.MergeQueueQueued || *entry.Status == models.MergeQueueTesting {
+						*dest = append(*dest, entry)
+					}
+				}
+			case *models.MergeQueueEntry:
+				for _, entry := range m.entries {
+					if strings.HasPrefix(qry[0].(string), "pull_request_id") && entry.PullRequestID == qry[1].(int64) &&
+						(*entry.Status == models.MergeQueueQueued || *entry.Status == models.MergeQueueTesting) {
+						*dest = entry
+						return nil
+					}
+					if strings.HasPrefix(qry[0].(string), "integration_sha") && entry.IntegrationSha != nil &&
+						*entry.IntegrationSha == qry[1].(string) && *entry.Status == models.MergeQueueTesting {
+						*dest = entry
+						return nil
+					}
+				}
+				return gorm.ErrRecordNotFound
+			case *models.PullRequest:
+				*dest = m.pullRequests[qry[1].(int64)]
+			}
+			return nil
+		}).
+		AnyTimes()
+
+	sqlStorage.EXPECT().
+		Insert(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, e interface{}) error {
+			entry := e.(*models.MergeQueueEntry)
+			entry.ID = uint32(len(m.entries) + 1)
+			m.entries = append(m.entries, *entry)
+			return nil
+		}).
+		AnyTimes()
+
+	sqlStorage.EXPECT().
+		Update(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, e interface{}) error {
+			entry := e.(*models.MergeQueueEntry)
+			m.entries[entry.ID-1] = *entry
+			return nil
+		}).
+		AnyTimes()
+}
+
+func newMergeQueueEntry(id uint32, number int, status string, integrationSha *string) models.MergeQueueEntry {
+	entry := models.MergeQueueEntry{
+		ID:                id,
+		RepositoryName:    utils.Stringify("hbalmes/ci-cd_api"),
+		BaseBranch:        utils.Stringify("develop"),
+		PullRequestID:     int64(number * 100),
+		PullRequestNumber: number,
+		Status:            utils.Stringify(status),
+		IntegrationSha:    integrationSha,
+	}
+	if status == models.MergeQueueTesting {
+		entry.IntegrationBranch = utils.Stringify(fmt.Sprintf("merge-queue/develop/pr-%d", number))
+	}
+	return entry
+}
+
+func newMergeQueuePullRequest(number int, baseRef string) models.PullRequest {
+	return models.PullRequest{
+		ID:                int64(number * 100),
+		PullRequestNumber: number,
+		State:             utils.Stringify("open"),
+		RepositoryName:    utils.Stringify("hbalmes/ci-cd_api"),
+		BaseRef:           utils.Stringify(baseRef),
+		HeadRef:           utils.Stringify("feature/queue"),
+		HeadSha:           utils.Stringify("headsha"),
+	}
+}
+
+func TestMergeQueue_Enqueue(t *testing.T) {
+	type expects struct {
+		createBranchTimes int
+		mergeErr          apierrors.ApiError
+		mergeTimes        int
+		deleteTimes       int
+		commentTimes      int
+		statuses          []string
+		queued            float64
+		err               apierrors.ApiError
+	}
+
+	config := models.Configuration{
+		ID:              utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:  utils.Stringify("ci-cd_api"),
+		RepositoryOwner: utils.Stringify("hbalmes"),
+		WorkflowType:    utils.Stringify("gitflow"),
+	}
+
+	tests := []struct {
+		name        string
+		pullRequest models.PullRequest
+		queue       []models.MergeQueueEntry
+		wantErr     bool
+		expects     expects
+	}{
+		{
+			name:        "not protected base branch",
+			pullRequest: newMergeQueuePullRequest(1, "feature/base"),
+			wantErr:     true,
+			expects: expects{
+				err: apierrors.NewBadRequestApiError("merge queues are only available for protected branches"),
+			},
+		},
+		{
+			name:        "pull request already queued",
+			pullRequest: newMergeQueuePullRequest(1, "develop"),
+			queue:       []models.MergeQueueEntry{newMergeQueueEntry(1, 1, models.MergeQueueQueued, nil)},
+			wantErr:     true,
+			expects: expects{
+				statuses: []string{models.MergeQueueQueued},
+				err:      apierrors.NewApiError("pull request already queued", "conflict_error", 409, apierrors.CauseList{}),
+			},
+		},
+		{
+			name:        "empty queue starts testing the pull request",
+			pullRequest: newMergeQueuePullRequest(1, "develop"),
+			wantErr:     false,
+			expects: expects{
+				createBranchTimes: 1,
+				mergeTimes:        1,
+				statuses:          []string{models.MergeQueueTesting},
+				queued:            1,
+			},
+		},
+		{
+			name:        "pull request waits behind the one being tested",
+			pullRequest: newMergeQueuePullRequest(2, "develop"),
+			queue:       []models.MergeQueueEntry{newMergeQueueEntry(1, 1, models.MergeQueueTesting, utils.Stringify("integrationsha"))},
+			wantErr:     false,
+			expects: expects{
+				statuses: []string{models.MergeQueueTesting, models.MergeQueueQueued},
+				queued:   2,
+			},
+		},
+		{
+			name:        "pull request in conflict is ejected",
+			pullRequest: newMergeQueuePullRequest(1, "develop"),
+			wantErr:     false,
+			expects: expects{
+				createBranchTimes: 1,
+				mergeErr:          apierrors.NewApiError("merge conflict", "conflict_error", 409, apierrors.CauseList{}),
+				mergeTimes:        1,
+				deleteTimes:       1,
+				commentTimes:      1,
+				statuses:          []string{models.MergeQueueEjected},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+			githubClient := interfaces.NewMockGithubClient(ctrl)
+
+			queueStorage := &mergeQueueStorage{
+				entries:      append([]models.MergeQueueEntry{}, tt.queue...),
+				pullRequests: map[int64]models.PullRequest{tt.pullRequest.ID: tt.pullRequest},
+			}
+			queueStorage.mock(sqlStorage)
+
+			baseBranch := models.GetBranchResponse{Name: "develop"}
+			baseBranch.Commit.Sha = "basesha"
+
+			githubClient.EXPECT().
+				GetBranchInformation(gomock.Any(), &config, "develop").
+				Return(&baseBranch, nil).
+				Times(tt.expects.createBranchTimes)
+
+			githubClient.EXPECT().
+				CreateBranch(gomock.Any(), &config, &models.Branch{Name: utils.Stringify("merge-queue/develop/pr-1")}, "basesha").
+				Return(nil).
+				Times(tt.expects.createBranchTimes)
+
+			githubClient.EXPECT().
+				MergeBranch(gomock.Any(), &config, "merge-queue/develop/pr-1", "headsha", "Merge queue: #1 into develop").
+				Return("integrationsha", tt.expects.mergeErr).
+				Times(tt.expects.mergeTimes)
+
+			githubClient.EXPECT().
+				DeleteBranch(gomock.Any(), &config, "merge-queue/develop/pr-1").
+				Return(nil).
+				Times(tt.expects.deleteTimes)
+
+			githubClient.EXPECT().
+				CreateIssueComment(gomock.Any(), &config, gomock.Any(), gomock.Any()).
+				Return(nil).
+				Times(tt.expects.commentTimes)
+
+			s := &MergeQueue{
+				SQL:          sqlStorage,
+				GithubClient: githubClient,
+			}
+
+			pullRequest := tt.pullRequest
+			entry, err := s.Enqueue(context.Background(), &config, &pullRequest, "maintainer")
+
+			statuses := make([]string, 0)
+			for _, e := range queueStorage.entries {
+				statuses = append(statuses, *e.Status)
+			}
+
+			if tt.wantErr {
+				assert.Nil(t, entry)
+				assert.Equal(t, tt.expects.err, err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expects.statuses, statuses)
+			assert.Equal(t, tt.expects.queued, testutil.ToFloat64(metrics.MergeQueueEntries.WithLabelValues("hbalmes/ci-cd_api", "develop")))
+		})
+	}
+}
+
+func TestMergeQueue_ProcessStatus(t *testing.T) {
+	type expects struct {
+		states       map[string]bool
+		statesTimes  int
+		mergeErr     apierrors.ApiError
+		mergeTimes   int
+		retestTimes  int
+		deleteTimes  int
+		commentTimes int
+		statuses     []string
+	}
+
+	config := models.Configuration{
+		ID:                     utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:         utils.Stringify("ci-cd_api"),
+		RepositoryOwner:        utils.Stringify("hbalmes"),
+		WorkflowType:           utils.Stringify("gitflow"),
+		RepositoryStatusChecks: []models.RequireStatusCheck{{Check: "ci"}, {Check: "workflow"}},
+	}
+
+	queue := []models.MergeQueueEntry{
+		newMergeQueueEntry(1, 1, models.MergeQueueTesting, utils.Stringify("integrationsha")),
+	}
+	queue[0].HeadSha = utils.Stringify("testedsha")
+
+	pullRequest := newMergeQueuePullRequest(1, "develop")
+
+	tests := []struct {
+		name    string
+		sha     string
+		state   string
+		expects expects
+	}{
+		{
+			name:  "sha not tested by a merge queue",
+			sha:   "othersha",
+			state: "failure",
+			expects: expects{
+				statuses: []string{models.MergeQueueTesting},
+			},
+		},
+		{
+			name:  "red context ejects the pull request",
+			sha:   "integrationsha",
+			state: "failure",
+			expects: expects{
+				deleteTimes:  1,
+				commentTimes: 1,
+				statuses:     []string{models.MergeQueueEjected},
+			},
+		},
+		{
+			name:  "contexts still pending",
+			sha:   "integrationsha",
+			state: "success",
+			expects: expects{
+				states:      map[string]bool{"ci": false},
+				statesTimes: 1,
+				statuses:    []string{models.MergeQueueTesting},
+			},
+		},
+		{
+			name:  "github refuses the merge",
+			sha:   "integrationsha",
+			state: "success",
+			expects: expects{
+				states:       map[string]bool{"ci": true},
+				statesTimes:  1,
+				mergeErr:     apierrors.NewApiError("pull request not mergeable", "conflict_error", 409, apierrors.CauseList{}),
+				mergeTimes:   1,
+				deleteTimes:  1,
+				commentTimes: 1,
+				statuses:     []string{models.MergeQueueEjected},
+			},
+		},
+		{
+			name:  "head moved since the test, the pull request is tested again",
+			sha:   "integrationsha",
+			state: "success",
+			expects: expects{
+				states:      map[string]bool{"ci": true},
+				statesTimes: 1,
+				mergeErr:    apierrors.NewApiError("pull request head was modified", "head_modified", 409, apierrors.CauseList{}),
+				mergeTimes:  1,
+				retestTimes: 1,
+				deleteTimes: 1,
+				statuses:    []string{models.MergeQueueTesting},
+			},
+		},
+		{
+			name:  "green integration branch merges the tested head",
+			sha:   "integrationsha",
+			state: "success",
+			expects: expects{
+				states:       map[string]bool{"ci": true},
+				statesTimes:  1,
+				mergeTimes:   1,
+				deleteTimes:  1,
+				commentTimes: 1,
+				statuses:     []string{models.MergeQueueMerged},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+			githubClient := interfaces.NewMockGithubClient(ctrl)
+			buildService := interfaces.NewMockBuildService(ctrl)
+
+			queueStorage := &mergeQueueStorage{
+				entries:      append([]models.MergeQueueEntry{}, queue...),
+				pullRequests: map[int64]models.PullRequest{pullRequest.ID: pullRequest},
+			}
+			queueStorage.mock(sqlStorage)
+
+			buildService.EXPECT().
+				GetStatusChecksState(gomock.Any(), []string{"ci"}, gomock.Any()).
+				Return(tt.expects.states).
+				Times(tt.expects.statesTimes)
+
+			githubClient.EXPECT().
+				MergePullRequest(gomock.Any(), &config, 1, "merge", "testedsha").
+				Return(tt.expects.mergeErr).
+				Times(tt.expects.mergeTimes)
+
+			baseBranch := models.GetBranchResponse{Name: "develop"}
+			baseBranch.Commit.Sha = "basesha"
+
+			githubClient.EXPECT().
+				GetBranchInformation(gomock.Any(), &config, "develop").
+				Return(&baseBranch, nil).
+				Times(tt.expects.retestTimes)
+
+			githubClient.EXPECT().
+				CreateBranch(gomock.Any(), &config, &models.Branch{Name: utils.Stringify("merge-queue/develop/pr-1")}, "basesha").
+				Return(nil).
+				Times(tt.expects.retestTimes)
+
+			githubClient.EXPECT().
+				MergeBranch(gomock.Any(), &config, "merge-queue/develop/pr-1", "headsha", "Merge queue: #1 into develop").
+				Return("newintegrationsha", nil).
+				Times(tt.expects.retestTimes)
+
+			githubClient.EXPECT().
+				DeleteBranch(gomock.Any(), &config, "merge-queue/develop/pr-1").
+				Return(nil).
+				Times(tt.expects.deleteTimes)
+
+			githubClient.EXPECT().
+				CreateIssueComment(gomock.Any(), &config, gomock.Any(), gomock.Any()).
+				Return(nil).
+				Times(tt.expects.commentTimes)
+
+			s := &MergeQueue{
+				SQL:          sqlStorage,
+				GithubClient: githubClient,
+				BuildService: buildService,
+			}
+
+			var payload webhook.Status
+			payload.Sha = utils.Stringify(tt.sha)
+			payload.State = utils.Stringify(tt.state)
+			payload.Context = utils.Stringify("ci")
+			payload.Repository.FullName = utils.Stringify("hbalmes/ci-cd_api")
+
+			err := s.ProcessStatus(context.Background(), &config, &payload)
+
+			statuses := make([]string, 0)
+			for _, e := range queueStorage.entries {
+				statuses = append(statuses, *e.Status)
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expects.statuses, statuses)
+			if tt.expects.retestTimes > 0 {
+				assert.Equal(t, "newintegrationsha", *queueStorage.entries[0].IntegrationSha)
+				assert.Equal(t, "headsha", *queueStorage.entries[0].HeadSha)
+			}
+		})
+	}
+}
@@ -0,0 +1,302 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hbalmes/ci_cd-api/api/clients"
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/services/storage"
+	"github.com/hbalmes/ci_cd-api/api/utils"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"github.com/hbalmes/ci_cd-api/api/utils/logger"
+	"github.com/hbalmes/ci_cd-api/api/utils/tracing"
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	promotionIncrementer = "minor"
+	buildDateLayout      = "2006-01-02 15:04:05"
+)
+
+//PromotionService is an interface which represents the PromotionService for testing purpose.
+type PromotionService interface {
+	Promote(ctx context.Context, repositoryName string, buildID uint32, r *models.PromotionPayload) (*models.Build, apierrors.ApiError)
+	CheckGates(ctx context.Context, config *models.Configuration, source *models.Build, requester string) ([]string, apierrors.ApiError)
+}
+
+//Promotion represents the PromotionService layer
+//It has an instance of a DBClient layer,
+//A github client instance,
+//A ConfigService instance,
+//A BuildService instance,
+//An AuditService instance,
+//An ApprovalService instance and
+//A FreezeWindowService instance
+type Promotion struct {
+	SQL                 storage.SQLStorage
+	GithubClient        clients.GithubClient
+	ConfigService       ConfigurationService
+	BuildService        BuildService
+	AuditService        AuditService
+	ApprovalService     ApprovalService
+	FreezeWindowService FreezeWindowService
+}
+
+//NewPromotionService initializes a PromotionService
+func NewPromotionService(sql storage.SQLStorage, githubClient clients.GithubClient, configService ConfigurationService, buildService BuildService, auditService AuditService, approvalService ApprovalService, freezeWindowService FreezeWindowService) *Promotion {
+	return &Promotion{
+		SQL:                 sql,
+		GithubClient:        githubClient,
+		ConfigService:       configService,
+		BuildService:        buildService,
+		AuditService:        auditService,
+		ApprovalService:     approvalService,
+		FreezeWindowService: freezeWindowService,
+	}
+}
+
+//Promote creates a productive release from a test build, pointing to the very same sha.
+//Nothing is rebuilt, so the artifact validated in staging is the one released.
+//The productive build keeps the id of the test build it was promoted from.
+//The requester is the github user the api token of the request is bound to.
+func (s *Promotion) Promote(ctx context.Context, repositoryName string, buildID uint32, r *models.PromotionPayload) (*models.Build, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "Promotion.Promote")
+	defer span.End()
+
+	requester, apiErr := AuthenticatedUser(ctx)
+
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	if r.RequestedBy != nil && *r.RequestedBy != "" && !strings.EqualFold(*r.RequestedBy, requester) {
+		return nil, apierrors.NewForbiddenApiError(fmt.Sprintf("the requester %s is not the authenticated user %s", *r.RequestedBy, requester))
+	}
+
+	config, err := s.ConfigService.Get(ctx, repositoryName)
+
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierrors.NewNotFoundApiError(fmt.Sprintf("configuration for repository %s not found", repositoryName))
+		}
+		return nil, apierrors.NewInternalServerApiError("error checking configuration existance", err)
+	}
+
+	var source models.Build
+
+	if err := s.SQL.GetBy(ctx, &source, "id = ? AND repository_name = ?", buildID, repositoryName); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierrors.NewNotFoundApiError(fmt.Sprintf("build %d not found", buildID))
+		}
+		return nil, apierrors.NewInternalServerApiError("error getting build", err)
+	}
+
+	if source.Type == nil || *source.Type != "test" {
+		return nil, apierrors.NewBadRequestApiError("only test builds can be promoted")
+	}
+
+	var promoted models.Build
+
+	if err := s.SQL.GetBy(ctx, &promoted, "promoted_from_id = ?", source.ID); err == nil {
+		return nil, apierrors.NewApiError(fmt.Sprintf("build %d was already promoted to %s", source.ID, *promoted.GithubURL), "conflict_error", http.StatusConflict, apierrors.CauseList{})
+	} else if err != gorm.ErrRecordNotFound {
+		return nil, apierrors.NewInternalServerApiError("error getting promoted build", err)
+	}
+
+	pending, apiErr := s.CheckGates(ctx, config, &source, requester)
+
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	if len(pending) > 0 {
+		return nil, apierrors.NewApiError(fmt.Sprintf("the promotion gates are not satisfied: %s", strings.Join(pending, "; ")), "conflict_error", http.StatusConflict, apierrors.CauseList{})
+	}
+
+	if apiErr := s.checkFreeze(ctx, repositoryName, &source, requester, r); apiErr != nil {
+		return nil, apiErr
+	}
+
+	lastBuild := s.BuildService.GetLatestBuild(ctx, config)
+	newSemVer := s.BuildService.IncrementSemVer(*lastBuild, promotionIncrementer)
+
+	now := time.Now().Format(buildDateLayout)
+	sourceVersion := fmt.Sprintf("v%d.%d.%d", source.Major, source.Minor, source.Patch)
+
+	body := fmt.Sprintf("Promotion of %s (build #%d), requested by @%s.", sourceVersion, source.ID, requester)
+	if source.Body != nil {
+		body = body + "\n\n" + *source.Body
+	}
+
+	build := models.Build{
+		Major:          uint8(newSemVer.Major),
+		Minor:          uint16(newSemVer.Minor),
+		Patch:          uint16(newSemVer.Patch),
+		Status:         utils.Stringify(initialBuildStatus),
+		Sha:            source.Sha,
+		Type:           utils.Stringify("productive"),
+		RepositoryName: source.RepositoryName,
+		UpdatedAt:      utils.Stringify(now),
+		CreatedAt:      utils.Stringify(now),
+		Branch:         source.Branch,
+		Username:       utils.Stringify(requester),
+		Body:           utils.Stringify(body),
+		PromotedFromID: &source.ID,
+	}
+
+	//Creates the github release
+	if err := s.GithubClient.CreateRelease(ctx, config, &build); err != nil {
+		return nil, err
+	}
+
+	build.GithubURL = utils.Stringify(fmt.Sprintf("v%d.%d.%d", build.Major, build.Minor, build.Patch))
+
+	if err := s.BuildService.SaveBuild(ctx, &build); err != nil {
+		return nil, err
+	}
+
+	if err := s.BuildService.CreateAndSaveLatestBuild(ctx, &build, lastBuild); err != nil {
+		return nil, err
+	}
+
+	event := models.AuditEvent{
+		RepositoryName: utils.Stringify(repositoryName),
+		Type:           utils.Stringify(models.AuditEventBuildPromoted),
+		Branch:         source.Branch,
+		Sha:            source.Sha,
+		Actor:          utils.Stringify(requester),
+		Description:    utils.Stringify(fmt.Sprintf("%s promoted from %s (build #%d)", *build.GithubURL, sourceVersion, source.ID)),
+	}
+
+	if err := s.AuditService.Record(ctx, &event); err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("error recording build promotion")
+	}
+
+	return &build, nil
+}
+
+//checkFreeze refuses the promotion when the repository is inside a freeze window, like the productive releases.
+//An emergency override needs a reason, and it is audited with the requester as its actor.
+func (s *Promotion) checkFreeze(ctx context.Context, repositoryName string, source *models.Build, requester string, r *models.PromotionPayload) apierrors.ApiError {
+
+	window, until, apiErr := s.FreezeWindowService.GetActive(ctx, repositoryName, time.Now())
+
+	if apiErr != nil {
+		return apiErr
+	}
+
+	if window == nil {
+		return nil
+	}
+
+	if r.FreezeOverrideReason == nil || *r.FreezeOverrideReason == "" {
+		return apierrors.NewApiError(fmt.Sprintf("productive releases are frozen by %s until %s", *window.Name, until.Format(freezeDateLayout)), "conflict_error", http.StatusConflict, apierrors.CauseList{})
+	}
+
+	s.FreezeWindowService.RecordOverride(ctx, repositoryName, window, requester, *r.FreezeOverrideReason, source.Branch, source.Sha)
+
+	return nil
+}
+
+//CheckGates checks the promotion conditions configured for the repository.
+//Returns the conditions not satisfied yet, so an empty slice means the build can be promoted.
+func (s *Promotion) CheckGates(ctx context.Context, config *models.Configuration, source *models.Build, requester string) ([]string, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "Promotion.CheckGates")
+	defer span.End()
+
+	pending := make([]string, 0)
+
+	//The soak time counts since the test build was released
+	if minSoak := config.GetPromotionMinSoakMinutes(); minSoak > 0 {
+		createdAt, err := time.ParseInLocation(buildDateLayout, *source.CreatedAt, time.Local)
+
+		if err != nil {
+			return nil, apierrors.NewInternalServerApiError("error parsing build creation date", err)
+		}
+
+		if soak := time.Since(createdAt); soak < time.Duration(minSoak)*time.Minute {
+			pending = append(pending, fmt.Sprintf("the build must soak %d minutes, %d elapsed", minSoak, int(soak.Minutes())))
+		}
+	}
+
+	if config.PromotionRequireStaging != nil && *config.PromotionRequireStaging {
+		environment := config.GetPromotionStagingEnvironment()
+		deployments := make([]models.Deployment, 0)
+
+		//Deployments replaced by newer ones were successful too
+		if err := s.SQL.GetBy(ctx, &deployments, "build_id = ? AND environment = ? AND status IN (?)",
+			source.ID, environment, []string{models.DeploymentStatusSuccess, models.DeploymentStatusInactive}); err != nil {
+			return nil, apierrors.NewInternalServerApiError("error getting build deployments", err)
+		}
+
+		if len(deployments) == 0 {
+			pending = append(pending, fmt.Sprintf("the build was not deployed successfully to %s", environment))
+		}
+	}
+
+	if config.PromotionRequireApproval != nil && *config.PromotionRequireApproval {
+		if environment := config.GetProductionEnvironment(); environment == nil {
+			pending = append(pending, "the promotion must be approved in the production environment, but the repository has none")
+		} else {
+			approved, err := s.isApproved(ctx, config, environment, source, requester)
+
+			if err != nil {
+				return nil, err
+			}
+
+			if !approved {
+				pending = append(pending, fmt.Sprintf("the promotion must be approved in %s by a maintainer other than the requester", environment.Name))
+			}
+		}
+	}
+
+	//The approval gate of production must be satisfied by the test build
+	if environment := config.GetProductionEnvironment(); environment != nil && environment.HasApprovalGate() {
+		gate, err := s.ApprovalService.CheckGate(ctx, config, environment, source.ID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !gate.Satisfied {
+			pending = append(pending, gate.Pending())
+		}
+	}
+
+	return pending, nil
+}
+
+//isApproved checks if the build was approved in the production environment by someone other than the requester.
+//The approvals are the ones recorded through the approval service, so the approvers were authenticated
+//and checked to be maintainers or collaborators with write permissions when they approved.
+func (s *Promotion) isApproved(ctx context.Context, config *models.Configuration, environment *models.Environment, source *models.Build, requester string) (bool, apierrors.ApiError) {
+
+	approvals := make([]models.Approval, 0)
+
+	if err := s.SQL.GetBy(ctx, &approvals, "repository_name = ? AND environment = ? AND build_id = ?", *config.ID, environment.Name, source.ID); err != nil {
+		return false, apierrors.NewInternalServerApiError("error getting approvals", err)
+	}
+
+	sort.SliceStable(approvals, func(i, j int) bool {
+		return approvals[i].ID < approvals[j].ID
+	})
+
+	approvedBy, rejectedBy := latestDecisions(approvals, time.Now())
+
+	if len(rejectedBy) > 0 {
+		return false, nil
+	}
+
+	for _, approver := range approvedBy {
+		if !strings.EqualFold(approver, requester) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,411 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/coreos/go-semver/semver"
+	"github.com/golang/mock/gomock"
+	"github.com/hbalmes/ci_cd-api/api/mocks/interfaces"
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/utils"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromotion_Promote(t *testing.T) {
+	type expects struct {
+		sourceErr     error
+		sourceType    string
+		promotedErr   error
+		releaseErr    apierrors.ApiError
+		releaseTimes  int
+		frozen        bool
+		overrideTimes int
+		err           apierrors.ApiError
+	}
+
+	freezeEnd := time.Date(2020, 11, 30, 0, 0, 0, 0, time.UTC)
+
+	config := models.Configuration{
+		ID:              utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:  utils.Stringify("ci-cd_api"),
+		RepositoryOwner: utils.Stringify("hbalmes"),
+	}
+
+	payload := &models.PromotionPayload{
+		RequestedBy: utils.Stringify("hbalmes"),
+	}
+
+	tests := []struct {
+		name    string
+		user    string
+		payload *models.PromotionPayload
+		expects expects
+	}{
+		{
+			name:    "api token not bound to a user",
+			payload: payload,
+			expects: expects{
+				err: apierrors.NewForbiddenApiError("the api token must be bound to a github user"),
+			},
+		},
+		{
+			name:    "requester of the payload is not the authenticated user",
+			user:    "someone",
+			payload: payload,
+			expects: expects{
+				err: apierrors.NewForbiddenApiError("the requester hbalmes is not the authenticated user someone"),
+			},
+		},
+		{
+			name:    "requester of the payload omitted",
+			user:    "hbalmes",
+			payload: &models.PromotionPayload{},
+			expects: expects{
+				sourceErr: gorm.ErrRecordNotFound,
+				err:       apierrors.NewNotFoundApiError("build 12 not found"),
+			},
+		},
+		{
+			name:    "build not found",
+			user:    "hbalmes",
+			payload: payload,
+			expects: expects{
+				sourceErr: gorm.ErrRecordNotFound,
+				err:       apierrors.NewNotFoundApiError("build 12 not found"),
+			},
+		},
+		{
+			name:    "productive build",
+			user:    "hbalmes",
+			payload: payload,
+			expects: expects{
+				sourceType: "productive",
+				err:        apierrors.NewBadRequestApiError("only test builds can be promoted"),
+			},
+		},
+		{
+			name:    "build already promoted",
+			user:    "hbalmes",
+			payload: payload,
+			expects: expects{
+				sourceType: "test",
+				err:        apierrors.NewApiError("build 12 was already promoted to v1.4.0", "conflict_error", http.StatusConflict, apierrors.CauseList{}),
+			},
+		},
+		{
+			name:    "error creating the release",
+			user:    "hbalmes",
+			payload: payload,
+			expects: expects{
+				sourceType:   "test",
+				promotedErr:  gorm.ErrRecordNotFound,
+				releaseErr:   apierrors.NewInternalServerApiError("error creating new release", nil),
+				releaseTimes: 1,
+				err:          apierrors.NewInternalServerApiError("error creating new release", nil),
+			},
+		},
+		{
+			name:    "productive releases frozen",
+			user:    "hbalmes",
+			payload: payload,
+			expects: expects{
+				sourceType:  "test",
+				promotedErr: gorm.ErrRecordNotFound,
+				frozen:      true,
+				err:         apierrors.NewApiError("productive releases are frozen by black-friday until 2020-11-30 00:00 UTC", "conflict_error", http.StatusConflict, apierrors.CauseList{}),
+			},
+		},
+		{
+			name: "freeze overridden",
+			user: "hbalmes",
+			payload: &models.PromotionPayload{
+				FreezeOverrideReason: utils.Stringify("payments outage"),
+			},
+			expects: expects{
+				sourceType:    "test",
+				promotedErr:   gorm.ErrRecordNotFound,
+				frozen:        true,
+				overrideTimes: 1,
+				releaseTimes:  1,
+			},
+		},
+		{
+			name:    "build promoted",
+			user:    "hbalmes",
+			payload: payload,
+			expects: expects{
+				sourceType:   "test",
+				promotedErr:  gorm.ErrRecordNotFound,
+				releaseTimes: 1,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+			githubClient := interfaces.NewMockGithubClient(ctrl)
+			configService := interfaces.NewMockConfigurationService(ctrl)
+			buildService := interfaces.NewMockBuildService(ctrl)
+			auditService := interfaces.NewMockAuditService(ctrl)
+			freezeWindowService := interfaces.NewMockFreezeWindowService(ctrl)
+
+			var window *models.FreezeWindow
+			var until *time.Time
+			if tt.expects.frozen {
+				window = &models.FreezeWindow{Name: utils.Stringify("black-friday")}
+				until = &freezeEnd
+			}
+
+			freezeWindowService.EXPECT().
+				GetActive(gomock.Any(), "hbalmes/ci-cd_api", gomock.Any()).
+				Return(window, until, nil).
+				AnyTimes()
+
+			freezeWindowService.EXPECT().
+				RecordOverride(gomock.Any(), "hbalmes/ci-cd_api", window, "hbalmes", "payments outage", utils.Stringify("feature/promotions"), utils.Stringify("testsha")).
+				Times(tt.expects.overrideTimes)
+
+			configService.EXPECT().
+				Get(gomock.Any(), "hbalmes/ci-cd_api").
+				Return(&config, nil).
+				AnyTimes()
+
+			sqlStorage.EXPECT().
+				GetBy(gomock.Any(), gomock.Any(), "id = ? AND repository_name = ?", uint32(12), "hbalmes/ci-cd_api").
+				DoAndReturn(func(ctx context.Context, e interface{}, qry ...interface{}) error {
+					*e.(*models.Build) = models.Build{
+						ID:             12,
+						Sha:            utils.Stringify("testsha"),
+						Major:          1,
+						Minor:          3,
+						Type:           utils.Stringify(tt.expects.sourceType),
+						RepositoryName: utils.Stringify("hbalmes/ci-cd_api"),
+						Branch:         utils.Stringify("feature/promotions"),
+						Body:           utils.Stringify("release notes"),
+					}
+					return tt.expects.sourceErr
+				}).
+				AnyTimes()
+
+			sqlStorage.EXPECT().
+				GetBy(gomock.Any(), gomock.Any(), "promoted_from_id = ?", uint32(12)).
+				DoAndReturn(func(ctx context.Context, e interface{}, qry ...interface{}) error {
+					*e.(*models.Build) = models.Build{ID: 13, GithubURL: utils.Stringify("v1.4.0")}
+					return tt.expects.promotedErr
+				}).
+				AnyTimes()
+
+			buildService.EXPECT().
+				GetLatestBuild(gomock.Any(), &config).
+				Return(&semver.Version{Major: 1, Minor: 3}).
+				MaxTimes(1)
+
+			buildService.EXPECT().
+				IncrementSemVer(semver.Version{Major: 1, Minor: 3}, "minor").
+				Return(semver.Version{Major: 1, Minor: 4}).
+				MaxTimes(1)
+
+			githubClient.EXPECT().
+				CreateRelease(gomock.Any(), &config, gomock.Any()).
+				DoAndReturn(func(ctx context.Context, config *models.Configuration, build *models.Build) apierrors.ApiError {
+					assert.Equal(t, "testsha", *build.Sha)
+					assert.Equal(t, "productive", *build.Type)
+					assert.Equal(t, uint32(12), *build.PromotedFromID)
+					assert.Contains(t, *build.Body, "Promotion of v1.3.0 (build #12), requested by @hbalmes.\n\nrelease notes")
+					return tt.expects.releaseErr
+				}).
+				Times(tt.expects.releaseTimes)
+
+			buildService.EXPECT().
+				SaveBuild(gomock.Any(), gomock.Any()).
+				Return(nil).
+				MaxTimes(1)
+
+			buildService.EXPECT().
+				CreateAndSaveLatestBuild(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(nil).
+				MaxTimes(1)
+
+			auditService.EXPECT().
+				Record(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, event *models.AuditEvent) apierrors.ApiError {
+					assert.Equal(t, models.AuditEventBuildPromoted, *event.Type)
+					assert.Equal(t, "hbalmes", *event.Actor)
+					assert.Equal(t, "v1.4.0 promoted from v1.3.0 (build #12)", *event.Description)
+					return nil
+				}).
+				MaxTimes(1)
+
+			s := &Promotion{
+				SQL:                 sqlStorage,
+				GithubClient:        githubClient,
+				ConfigService:       configService,
+				BuildService:        buildService,
+				AuditService:        auditService,
+				FreezeWindowService: freezeWindowService,
+			}
+
+			ctx := WithAPIToken(context.Background(), &models.APIToken{Subject: utils.Stringify(tt.user)})
+
+			build, err := s.Promote(ctx, "hbalmes/ci-cd_api", 12, tt.payload)
+
+			assert.Equal(t, tt.expects.err, err)
+			if err == nil {
+				assert.Equal(t, "v1.4.0", *build.GithubURL)
+			}
+		})
+	}
+}
+
+func TestPromotion_CheckGates(t *testing.T) {
+	type expects struct {
+		deployments int
+		approvals   []models.Approval
+		pending     []string
+	}
+
+	soak := 60
+	enabled := true
+
+	config := models.Configuration{
+		ID:                       utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:           utils.Stringify("ci-cd_api"),
+		RepositoryOwner:          utils.Stringify("hbalmes"),
+		Maintainers:              []models.Maintainer{{Username: "maintainer"}},
+		PromotionMinSoakMinutes:  &soak,
+		PromotionRequireStaging:  &enabled,
+		PromotionRequireApproval: &enabled,
+	}
+
+	expired := time.Now().Add(-time.Minute)
+
+	approval := func(id uint32, approver string, decision string) models.Approval {
+		return models.Approval{ID: id, Approver: utils.Stringify(approver), Decision: utils.Stringify(decision)}
+	}
+
+	tests := []struct {
+		name      string
+		createdAt time.Time
+		requester string
+		expects   expects
+	}{
+		{
+			name:      "no gate satisfied",
+			createdAt: time.Now().Add(-10 * time.Minute),
+			requester: "hbalmes",
+			expects: expects{
+				pending: []string{
+					"the build must soak 60 minutes, 10 elapsed",
+					"the build was not deployed successfully to staging",
+					"the promotion must be approved in production by a maintainer other than the requester",
+				},
+			},
+		},
+		{
+			name:      "self approval",
+			createdAt: time.Now().Add(-2 * time.Hour),
+			requester: "maintainer",
+			expects: expects{
+				deployments: 1,
+				approvals:   []models.Approval{approval(1, "maintainer", models.ApprovalDecisionApproved)},
+				pending:     []string{"the promotion must be approved in production by a maintainer other than the requester"},
+			},
+		},
+		{
+			name:      "approval expired",
+			createdAt: time.Now().Add(-2 * time.Hour),
+			requester: "hbalmes",
+			expects: expects{
+				deployments: 1,
+				approvals: []models.Approval{
+					{ID: 1, Approver: utils.Stringify("maintainer"), Decision: utils.Stringify(models.ApprovalDecisionApproved), ExpiresAt: &expired},
+				},
+				pending: []string{"the promotion must be approved in production by a maintainer other than the requester"},
+			},
+		},
+		{
+			name:      "approved and rejected",
+			createdAt: time.Now().Add(-2 * time.Hour),
+			requester: "hbalmes",
+			expects: expects{
+				deployments: 1,
+				approvals: []models.Approval{
+					approval(1, "maintainer", models.ApprovalDecisionApproved),
+					approval(2, "writer", models.ApprovalDecisionRejected),
+				},
+				pending: []string{"the promotion must be approved in production by a maintainer other than the requester"},
+			},
+		},
+		{
+			name:      "approved after a rejection",
+			createdAt: time.Now().Add(-2 * time.Hour),
+			requester: "hbalmes",
+			expects: expects{
+				deployments: 1,
+				approvals: []models.Approval{
+					approval(2, "maintainer", models.ApprovalDecisionApproved),
+					approval(1, "maintainer", models.ApprovalDecisionRejected),
+				},
+				pending: []string{},
+			},
+		},
+		{
+			name:      "approved by another user",
+			createdAt: time.Now().Add(-2 * time.Hour),
+			requester: "hbalmes",
+			expects: expects{
+				deployments: 1,
+				approvals:   []models.Approval{approval(1, "writer", models.ApprovalDecisionApproved)},
+				pending:     []string{},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+
+			sqlStorage.EXPECT().
+				GetBy(gomock.Any(), gomock.Any(), "build_id = ? AND environment = ? AND status IN (?)", uint32(12), "staging", gomock.Any()).
+				DoAndReturn(func(ctx context.Context, e interface{}, qry ...interface{}) error {
+					for i := 0; i < tt.expects.deployments; i++ {
+						*e.(*[]models.Deployment) = append(*e.(*[]models.Deployment), models.Deployment{BuildID: 12})
+					}
+					return nil
+				}).
+				Times(1)
+
+			sqlStorage.EXPECT().
+				GetBy(gomock.Any(), gomock.Any(), "repository_name = ? AND environment = ? AND build_id = ?", "hbalmes/ci-cd_api", "production", uint32(12)).
+				DoAndReturn(func(ctx context.Context, e interface{}, qry ...interface{}) error {
+					*e.(*[]models.Approval) = append(*e.(*[]models.Approval), tt.expects.approvals...)
+					return nil
+				}).
+				Times(1)
+
+			s := &Promotion{
+				SQL: sqlStorage,
+			}
+
+			source := models.Build{
+				ID:        12,
+				CreatedAt: utils.Stringify(tt.createdAt.Format(buildDateLayout)),
+			}
+
+			pending, err := s.CheckGates(context.Background(), &config, &source, tt.requester)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expects.pending, pending)
+		})
+	}
+}
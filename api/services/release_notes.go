@@ -0,0 +1,374 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/hbalmes/ci_cd-api/api/clients"
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/services/storage"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"github.com/hbalmes/ci_cd-api/api/utils/logger"
+	"github.com/hbalmes/ci_cd-api/api/utils/tracing"
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	breakingChangesCategory = "Breaking changes"
+	featuresCategory        = "Features"
+	bugFixesCategory        = "Bug fixes"
+	documentationCategory   = "Documentation"
+	maintenanceCategory     = "Maintenance"
+	otherChangesCategory    = "Other changes"
+
+	releaseNotesDateLayout = "2006-01-02"
+
+	defaultReleaseNotesTemplate = `## {{.Version}}
+{{range .Categories}}
+### {{.Title}}
+{{range .PullRequests}}* {{.Title}} ([#{{.Number}}]({{.URL}})) by [@{{.Author}}](https://github.com/{{.Author}})
+{{end}}{{end}}
+_` + automaticBuildBody + `_
+`
+)
+
+//releaseNotesCategories is the order in which the categories are shown.
+var releaseNotesCategories = []string{breakingChangesCategory, featuresCategory, bugFixesCategory,
+	documentationCategory, maintenanceCategory, otherChangesCategory}
+
+var labelCategories = map[string]string{
+	"breaking":        breakingChangesCategory,
+	"breaking-change": breakingChangesCategory,
+	"feature":         featuresCategory,
+	"enhancement":     featuresCategory,
+	"bug":             bugFixesCategory,
+	"fix":             bugFixesCategory,
+	"documentation":   documentationCategory,
+	"docs":            documentationCategory,
+}
+
+var conventionalCommitCategories = map[string]string{
+	"feat":     featuresCategory,
+	"fix":      bugFixesCategory,
+	"docs":     documentationCategory,
+	"perf":     maintenanceCategory,
+	"refactor": maintenanceCategory,
+	"chore":    maintenanceCategory,
+	"test":     maintenanceCategory,
+	"ci":       maintenanceCategory,
+	"build":    maintenanceCategory,
+	"style":    maintenanceCategory,
+}
+
+var branchPrefixCategories = map[string]string{
+	"feature/":     featuresCategory,
+	"enhancement/": featuresCategory,
+	"fix/":         bugFixesCategory,
+	"bugfix/":      bugFixesCategory,
+	"hotfix/":      bugFixesCategory,
+}
+
+var conventionalCommitRegexp = regexp.MustCompile(`^(\w+)(\([^)]*\))?(!)?:`)
+
+//mergeRefRegexps match the pull request number in the first line of merge commits and squashed commits.
+var mergeRefRegexps = []*regexp.Regexp{
+	regexp.MustCompile(`^Merge pull request #(\d+)`),
+	regexp.MustCompile(`\(#(\d+)\)$`),
+}
+
+//ReleaseNotesService is an interface which represents the ReleaseNotesService for testing purpose.
+type ReleaseNotesService interface {
+	Generate(ctx context.Context, config *models.Configuration, build *models.Build, pullRequest *models.PullRequest) (*models.ReleaseNotes, apierrors.ApiError)
+	Render(config *models.Configuration, notes *models.ReleaseNotes) (string, apierrors.ApiError)
+}
+
+//ReleaseNotes represents the ReleaseNotesService layer
+//It has an instance of a DBClient layer and
+//A github client instance
+type ReleaseNotes struct {
+	SQL          storage.SQLStorage
+	GithubClient clients.GithubClient
+}
+
+//NewReleaseNotesService initializes a ReleaseNotesService
+func NewReleaseNotesService(sql storage.SQLStorage, githubClient clients.GithubClient) *ReleaseNotes {
+	return &ReleaseNotes{
+		SQL:          sql,
+		GithubClient: githubClient,
+	}
+}
+
+//ParseReleaseNotesTemplate parses the release notes template of the configuration.
+//Uses the default template when the configuration has not one.
+func ParseReleaseNotesTemplate(text *string) (*template.Template, error) {
+	if text == nil {
+		return template.New("release_notes").Parse(defaultReleaseNotesTemplate)
+	}
+	return template.New("release_notes").Parse(*text)
+}
+
+//Generate builds the release notes with the pull requests merged since the previous build.
+//The pull request that creates the build is always included.
+func (s *ReleaseNotes) Generate(ctx context.Context, config *models.Configuration, build *models.Build, pullRequest *models.PullRequest) (*models.ReleaseNotes, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "ReleaseNotes.Generate")
+	defer span.End()
+
+	pullRequests, err := s.GetPullRequests(ctx, config, build, pullRequest)
+
+	if err != nil {
+		return nil, err
+	}
+
+	version := fmt.Sprintf("v%d.%d.%d", build.Major, build.Minor, build.Patch)
+	if build.Tag != nil {
+		version = version + "-" + *build.Tag
+	}
+
+	return &models.ReleaseNotes{
+		Version:    version,
+		Date:       time.Now().Format(releaseNotesDateLayout),
+		Repository: *config.ID,
+		Categories: CategorizePullRequests(pullRequests),
+	}, nil
+}
+
+//Render executes the release notes template of the configuration.
+func (s *ReleaseNotes) Render(config *models.Configuration, notes *models.ReleaseNotes) (string, apierrors.ApiError) {
+
+	tmpl, parseErr := ParseReleaseNotesTemplate(config.ReleaseNotesTemplate)
+
+	if parseErr != nil {
+		return "", apierrors.NewBadRequestApiError(fmt.Sprintf("invalid release notes template: %s", parseErr.Error()))
+	}
+
+	var body bytes.Buffer
+	if execErr := tmpl.Execute(&body, notes); execErr != nil {
+		return "", apierrors.NewInternalServerApiError("error executing release notes template", execErr)
+	}
+
+	return body.String(), nil
+}
+
+//GetPullRequests returns the pull requests merged between the previous build sha and the build sha.
+//The pull requests are found by the merge refs in the commit messages and the merge commit shas,
+//looked up in a single list of the closed pull requests. A commit is only looked up alone when its
+//pull request is not in that list, and it is skipped when the lookup fails.
+func (s *ReleaseNotes) GetPullRequests(ctx context.Context, config *models.Configuration, build *models.Build, pullRequest *models.PullRequest) ([]models.ReleaseNotesPullRequest, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "ReleaseNotes.GetPullRequests")
+	defer span.End()
+
+	pullRequests := make([]models.ReleaseNotesPullRequest, 0)
+	included := make(map[int]bool)
+
+	previousBuild, err := s.getPreviousBuild(ctx, config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if previousBuild != nil && previousBuild.Sha != nil && build.Sha != nil && *previousBuild.Sha != *build.Sha {
+
+		compare, compareErr := s.GithubClient.CompareCommits(ctx, config, *previousBuild.Sha, *build.Sha)
+
+		if compareErr != nil {
+			return nil, compareErr
+		}
+
+		merged := make(map[int]models.CommitPullRequestResponse)
+		byMergeSha := make(map[string]int)
+
+		//The pull requests merged since the previous build were updated after it was created
+		var since time.Time
+		if previousBuild.CreatedAt != nil {
+			since, _ = time.ParseInLocation(buildDateLayout, *previousBuild.CreatedAt, time.Local)
+		}
+
+		closed, listErr := s.GithubClient.ListClosedPullRequests(ctx, config, since)
+
+		if listErr != nil {
+			logger.FromContext(ctx).Error().Err(listErr).Msg("error listing closed pull requests, looking them up by commit")
+			closed = nil
+		}
+
+		for _, pr := range closed {
+			if pr.MergedAt == nil {
+				continue
+			}
+			merged[pr.Number] = pr
+			if pr.MergeCommitSha != nil {
+				byMergeSha[*pr.MergeCommitSha] = pr.Number
+			}
+		}
+
+		add := func(pr models.CommitPullRequestResponse) {
+			included[pr.Number] = true
+
+			labels := make([]string, 0)
+			for _, label := range pr.Labels {
+				labels = append(labels, label.Name)
+			}
+
+			pullRequests = append(pullRequests, models.ReleaseNotesPullRequest{
+				Number: pr.Number,
+				Title:  pr.Title,
+				Author: pr.User.Login,
+				Branch: pr.Head.Ref,
+				Labels: labels,
+				URL:    fmt.Sprintf("https://github.com/%s/pull/%d", *config.ID, pr.Number),
+			})
+		}
+
+		for _, commit := range compare.Commits {
+			number, referenced := GetMergedPullRequestNumber(commit.Commit.Message)
+
+			if !referenced {
+				//Rebased pull requests have no merge ref, but their last commit is the merge commit
+				if number, referenced = byMergeSha[commit.Sha]; !referenced {
+					continue
+				}
+			}
+
+			if included[number] || number == pullRequest.PullRequestNumber {
+				continue
+			}
+
+			if pr, ok := merged[number]; ok {
+				add(pr)
+				continue
+			}
+
+			commitPRs, prErr := s.GithubClient.GetPullRequestsByCommit(ctx, config, commit.Sha)
+
+			if prErr != nil {
+				logger.FromContext(ctx).Error().Err(prErr).Str("sha", commit.Sha).Int("pull_request", number).Msg("error getting commit pull requests, skipping it from the release notes")
+				continue
+			}
+
+			for _, pr := range commitPRs {
+				if pr.Number == number && pr.MergedAt != nil {
+					add(pr)
+				}
+			}
+		}
+	}
+
+	if !included[pullRequest.PullRequestNumber] {
+		pullRequests = append(pullRequests, newReleaseNotesPullRequest(config, pullRequest))
+	}
+
+	return pullRequests, nil
+}
+
+//GetMergedPullRequestNumber returns the number of the pull request merged by the commit, read from its message.
+//Merge commits start with "Merge pull request #N" and squashed commits end their first line with "(#N)".
+func GetMergedPullRequestNumber(message string) (int, bool) {
+
+	firstLine := strings.TrimSpace(strings.SplitN(message, "\n", 2)[0])
+
+	for _, ref := range mergeRefRegexps {
+		if match := ref.FindStringSubmatch(firstLine); match != nil {
+			if number, err := strconv.Atoi(match[1]); err == nil {
+				return number, true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+//CategorizePullRequests groups the pull requests by category.
+//The category comes from the labels, the conventional commit type of the title or the head branch prefix, in that order.
+func CategorizePullRequests(pullRequests []models.ReleaseNotesPullRequest) []models.ReleaseNotesCategory {
+
+	byCategory := make(map[string][]models.ReleaseNotesPullRequest)
+	for _, pr := range pullRequests {
+		category := GetReleaseNotesCategory(pr)
+		byCategory[category] = append(byCategory[category], pr)
+	}
+
+	categories := make([]models.ReleaseNotesCategory, 0)
+	for _, title := range releaseNotesCategories {
+		if prs, ok := byCategory[title]; ok {
+			categories = append(categories, models.ReleaseNotesCategory{
+				Title:        title,
+				PullRequests: prs,
+			})
+		}
+	}
+
+	return categories
+}
+
+//GetReleaseNotesCategory returns the category of the pull request.
+func GetReleaseNotesCategory(pr models.ReleaseNotesPullRequest) string {
+
+	for _, label := range pr.Labels {
+		if category, ok := labelCategories[strings.ToLower(label)]; ok {
+			return category
+		}
+	}
+
+	if match := conventionalCommitRegexp.FindStringSubmatch(pr.Title); match != nil {
+		if match[3] == "!" {
+			return breakingChangesCategory
+		}
+		if category, ok := conventionalCommitCategories[strings.ToLower(match[1])]; ok {
+			return category
+		}
+	}
+
+	for prefix, category := range branchPrefixCategories {
+		if strings.HasPrefix(pr.Branch, prefix) {
+			return category
+		}
+	}
+
+	return otherChangesCategory
+}
+
+func (s *ReleaseNotes) getPreviousBuild(ctx context.Context, config *models.Configuration) (*models.Build, apierrors.ApiError) {
+	var latestBuild models.LatestBuild
+	var build models.Build
+
+	if err := s.SQL.GetBy(ctx, &latestBuild, "repository_name = ?", config.ID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, apierrors.NewInternalServerApiError("error getting latest build", err)
+	}
+
+	if err := s.SQL.GetBy(ctx, &build, "id = ?", latestBuild.BuildID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, apierrors.NewInternalServerApiError("error getting previous build", err)
+	}
+
+	return &build, nil
+}
+
+func newReleaseNotesPullRequest(config *models.Configuration, pullRequest *models.PullRequest) models.ReleaseNotesPullRequest {
+	pr := models.ReleaseNotesPullRequest{
+		Number: pullRequest.PullRequestNumber,
+		Labels: make([]string, 0),
+		URL:    fmt.Sprintf("https://github.com/%s/pull/%d", *config.ID, pullRequest.PullRequestNumber),
+	}
+
+	if pullRequest.Title != nil {
+		pr.Title = *pullRequest.Title
+	}
+	if pullRequest.CreatedBy != nil {
+		pr.Author = *pullRequest.CreatedBy
+	}
+	if pullRequest.HeadRef != nil {
+		pr.Branch = *pullRequest.HeadRef
+	}
+
+	return pr
+}
@@ -0,0 +1,334 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hbalmes/ci_cd-api/api/mocks/interfaces"
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/utils"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetReleaseNotesCategory(t *testing.T) {
+	tests := []struct {
+		name     string
+		pr       models.ReleaseNotesPullRequest
+		category string
+	}{
+		{
+			name:     "label has priority",
+			pr:       models.ReleaseNotesPullRequest{Title: "feat: new endpoint", Branch: "feature/endpoint", Labels: []string{"Bug"}},
+			category: "Bug fixes",
+		},
+		{
+			name:     "conventional commit type",
+			pr:       models.ReleaseNotesPullRequest{Title: "docs(readme): explain configuration", Branch: "feature/readme"},
+			category: "Documentation",
+		},
+		{
+			name:     "conventional commit breaking change",
+			pr:       models.ReleaseNotesPullRequest{Title: "feat!: remove v1 endpoints", Branch: "feature/v2"},
+			category: "Breaking changes",
+		},
+		{
+			name:     "branch prefix",
+			pr:       models.ReleaseNotesPullRequest{Title: "Fix nil pointer", Branch: "hotfix/nil-pointer"},
+			category: "Bug fixes",
+		},
+		{
+			name:     "without category",
+			pr:       models.ReleaseNotesPullRequest{Title: "Release 1.2", Branch: "release/1.2"},
+			category: "Other changes",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.category, GetReleaseNotesCategory(tt.pr))
+		})
+	}
+}
+
+func TestReleaseNotes_GenerateAndRender(t *testing.T) {
+	type expects struct {
+		getLatestErr error
+		compareErr   apierrors.ApiError
+		compareTimes int
+		listErr      apierrors.ApiError
+		listTimes    int
+		prErr        apierrors.ApiError
+		prTimes      int
+		contains     []string
+		notContains  []string
+		err          apierrors.ApiError
+	}
+
+	config := models.Configuration{
+		ID:              utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:  utils.Stringify("ci-cd_api"),
+		RepositoryOwner: utils.Stringify("hbalmes"),
+	}
+
+	configCustomTemplate := config
+	configCustomTemplate.ReleaseNotesTemplate = utils.Stringify("{{.Version}}{{range .Categories}}|{{.Title}}:{{range .PullRequests}}{{.Number}}{{end}}{{end}}")
+
+	configInvalidTemplate := config
+	configInvalidTemplate.ReleaseNotesTemplate = utils.Stringify("{{range .Categories}")
+
+	build := models.Build{
+		Sha:   utils.Stringify("headsha"),
+		Major: 1,
+		Minor: 3,
+		Patch: 0,
+	}
+
+	pullRequest := models.PullRequest{
+		PullRequestNumber: 30,
+		Title:             utils.Stringify("Release 1.3"),
+		HeadRef:           utils.Stringify("release/1.3"),
+		CreatedBy:         utils.Stringify("hbalmes"),
+	}
+
+	mergedAt := "2020-06-01T10:00:00Z"
+
+	featurePR := models.CommitPullRequestResponse{Number: 21, Title: "feat: merge queues", MergedAt: &mergedAt}
+	featurePR.User.Login = "dev1"
+
+	fixPR := models.CommitPullRequestResponse{Number: 22, Title: "Fix auto-merge", MergedAt: &mergedAt}
+	fixPR.User.Login = "dev2"
+	fixPR.Head.Ref = "fix/auto-merge"
+
+	rebasedPR := models.CommitPullRequestResponse{Number: 23, Title: "docs: merge queues", MergedAt: &mergedAt, MergeCommitSha: utils.Stringify("sha4")}
+	rebasedPR.User.Login = "dev3"
+
+	openPR := models.CommitPullRequestResponse{Number: 25, Title: "WIP"}
+
+	//The fix was merged before the listed pull requests, so it is looked up by its commit
+	closedPRs := []models.CommitPullRequestResponse{featurePR, rebasedPR, openPR}
+
+	compare := models.CompareResponse{}
+	for _, commit := range []struct{ sha, message string }{
+		{"sha1", "Merge pull request #21 from hbalmes/feature/merge-queues\n\nfeat: merge queues"},
+		{"sha2", "Fix auto-merge (#22)"},
+		{"sha3", "wip (#25)"},
+		{"sha4", "docs: merge queues"},
+		{"sha5", "Merge pull request #30 from hbalmes/release/1.3"},
+	} {
+		var c struct {
+			Sha    string `json:"sha"`
+			Commit struct {
+				Message string `json:"message"`
+			} `json:"commit"`
+		}
+		c.Sha = commit.sha
+		c.Commit.Message = commit.message
+		compare.Commits = append(compare.Commits, c)
+	}
+
+	tests := []struct {
+		name    string
+		config  *models.Configuration
+		wantErr bool
+		expects expects
+	}{
+		{
+			name:    "first build only includes its pull request",
+			config:  &config,
+			wantErr: false,
+			expects: expects{
+				getLatestErr: gorm.ErrRecordNotFound,
+				contains: []string{
+					"## v1.3.0",
+					"### Other changes",
+					"* Release 1.3 ([#30](https://github.com/hbalmes/ci-cd_api/pull/30)) by [@hbalmes](https://github.com/hbalmes)",
+				},
+			},
+		},
+		{
+			name:    "error comparing commits",
+			config:  &config,
+			wantErr: true,
+			expects: expects{
+				compareErr:   apierrors.NewInternalServerApiError("error comparing commits - status: 404", nil),
+				compareTimes: 1,
+				err:          apierrors.NewInternalServerApiError("error comparing commits - status: 404", nil),
+			},
+		},
+		{
+			name:    "merged pull requests grouped by category",
+			config:  &config,
+			wantErr: false,
+			expects: expects{
+				compareTimes: 1,
+				listTimes:    1,
+				prTimes:      1,
+				contains: []string{
+					"### Features\n* feat: merge queues ([#21](https://github.com/hbalmes/ci-cd_api/pull/21)) by [@dev1](https://github.com/dev1)",
+					"### Bug fixes\n* Fix auto-merge ([#22](https://github.com/hbalmes/ci-cd_api/pull/22)) by [@dev2](https://github.com/dev2)",
+					"### Documentation\n* docs: merge queues ([#23](https://github.com/hbalmes/ci-cd_api/pull/23)) by [@dev3](https://github.com/dev3)",
+					"### Other changes\n* Release 1.3",
+				},
+			},
+		},
+		{
+			name:    "error listing pull requests, merge refs looked up by commit",
+			config:  &config,
+			wantErr: false,
+			expects: expects{
+				compareTimes: 1,
+				listErr:      apierrors.NewInternalServerApiError("error listing closed pull requests - status: 502", nil),
+				listTimes:    1,
+				prTimes:      1,
+				contains: []string{
+					"### Features\n* feat: merge queues ([#21](https://github.com/hbalmes/ci-cd_api/pull/21))",
+					"### Bug fixes\n* Fix auto-merge ([#22](https://github.com/hbalmes/ci-cd_api/pull/22))",
+				},
+				notContains: []string{"#23"},
+			},
+		},
+		{
+			name:    "commit lookup failure skips its pull request",
+			config:  &config,
+			wantErr: false,
+			expects: expects{
+				compareTimes: 1,
+				listTimes:    1,
+				prErr:        apierrors.NewInternalServerApiError("error getting commit pull requests", nil),
+				prTimes:      1,
+				contains: []string{
+					"### Features\n* feat: merge queues ([#21](https://github.com/hbalmes/ci-cd_api/pull/21))",
+					"### Other changes\n* Release 1.3",
+				},
+				notContains: []string{"#22"},
+			},
+		},
+		{
+			name:    "configured template",
+			config:  &configCustomTemplate,
+			wantErr: false,
+			expects: expects{
+				compareTimes: 1,
+				listTimes:    1,
+				prTimes:      1,
+				contains:     []string{"v1.3.0|Features:21|Bug fixes:22|Documentation:23|Other changes:30"},
+			},
+		},
+		{
+			name:    "invalid template",
+			config:  &configInvalidTemplate,
+			wantErr: true,
+			expects: expects{
+				compareTimes: 1,
+				listTimes:    1,
+				prTimes:      1,
+				err:          apierrors.NewBadRequestApiError("invalid release notes template: template: release_notes:1: bad character U+007D '}'"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+			githubClient := interfaces.NewMockGithubClient(ctrl)
+
+			getLatest := sqlStorage.EXPECT().
+				GetBy(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				Do(func(ctx context.Context, e interface{}, qry ...interface{}) {
+					*e.(*models.LatestBuild) = models.LatestBuild{BuildID: 7}
+				}).
+				Return(tt.expects.getLatestErr).
+				Times(1)
+
+			getBuildTimes := 1
+			if tt.expects.getLatestErr != nil {
+				getBuildTimes = 0
+			}
+
+			sqlStorage.EXPECT().
+				GetBy(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				Do(func(ctx context.Context, e interface{}, qry ...interface{}) {
+					*e.(*models.Build) = models.Build{ID: 7, Sha: utils.Stringify("basesha"), CreatedAt: utils.Stringify("2020-05-01 09:00:00")}
+				}).
+				Return(nil).
+				After(getLatest).
+				Times(getBuildTimes)
+
+			githubClient.EXPECT().
+				CompareCommits(gomock.Any(), tt.config, "basesha", "headsha").
+				Return(&compare, tt.expects.compareErr).
+				Times(tt.expects.compareTimes)
+
+			githubClient.EXPECT().
+				ListClosedPullRequests(gomock.Any(), tt.config, time.Date(2020, 5, 1, 9, 0, 0, 0, time.Local)).
+				Return(closedPRs, tt.expects.listErr).
+				Times(tt.expects.listTimes)
+
+			//Without the list, the merged feature is also looked up by its commit
+			githubClient.EXPECT().
+				GetPullRequestsByCommit(gomock.Any(), tt.config, "sha1").
+				Return([]models.CommitPullRequestResponse{featurePR}, nil).
+				MaxTimes(tt.expects.prTimes)
+
+			githubClient.EXPECT().
+				GetPullRequestsByCommit(gomock.Any(), tt.config, "sha2").
+				Return([]models.CommitPullRequestResponse{fixPR}, tt.expects.prErr).
+				Times(tt.expects.prTimes)
+
+			githubClient.EXPECT().
+				GetPullRequestsByCommit(gomock.Any(), tt.config, "sha3").
+				Return([]models.CommitPullRequestResponse{openPR}, nil).
+				MaxTimes(tt.expects.prTimes)
+
+			s := &ReleaseNotes{
+				SQL:          sqlStorage,
+				GithubClient: githubClient,
+			}
+
+			var body string
+			notes, err := s.Generate(context.Background(), tt.config, &build, &pullRequest)
+			if err == nil {
+				body, err = s.Render(tt.config, notes)
+			}
+
+			if tt.wantErr {
+				assert.Equal(t, tt.expects.err, err)
+				return
+			}
+
+			assert.Nil(t, err)
+			for _, expected := range tt.expects.contains {
+				assert.Contains(t, body, expected)
+			}
+			assert.NotContains(t, body, "#25")
+			for _, unexpected := range tt.expects.notContains {
+				assert.NotContains(t, body, unexpected)
+			}
+		})
+	}
+}
+
+func TestGetMergedPullRequestNumber(t *testing.T) {
+	tests := []struct {
+		message string
+		number  int
+		ok      bool
+	}{
+		{message: "Merge pull request #21 from hbalmes/feature/merge-queues\n\nfeat: merge queues", number: 21, ok: true},
+		{message: "Fix auto-merge (#22)\n\n* first commit\n* second commit", number: 22, ok: true},
+		{message: "Fix auto-merge\n\nSee (#22)"},
+		{message: "Merge branch 'develop' into feature/merge-queues"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			number, ok := GetMergedPullRequestNumber(tt.message)
+			assert.Equal(t, tt.number, number)
+			assert.Equal(t, tt.ok, ok)
+		})
+	}
+}
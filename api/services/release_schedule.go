@@ -0,0 +1,288 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hbalmes/ci_cd-api/api/clients"
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/services/storage"
+	"github.com/hbalmes/ci_cd-api/api/utils"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"github.com/hbalmes/ci_cd-api/api/utils/logger"
+	"github.com/hbalmes/ci_cd-api/api/utils/tracing"
+	"github.com/jinzhu/gorm"
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	releaseBranchPrefix = "release/"
+	releaseTargetBranch = "master"
+)
+
+//ReleaseScheduleService is an interface which represents the ReleaseScheduleService for testing purpose.
+type ReleaseScheduleService interface {
+	Create(ctx context.Context, repositoryName string, r *models.ReleaseSchedulePayload) (*models.ReleaseSchedule, apierrors.ApiError)
+	List(ctx context.Context, repositoryName string) ([]models.ReleaseSchedule, apierrors.ApiError)
+	Update(ctx context.Context, repositoryName string, id uint32, r *models.ReleaseSchedulePayload) (*models.ReleaseSchedule, apierrors.ApiError)
+	Delete(ctx context.Context, repositoryName string, id uint32) apierrors.ApiError
+	CutReleaseBranch(ctx context.Context, schedule *models.ReleaseSchedule) (string, apierrors.ApiError)
+	RunDueSchedules(ctx context.Context, now time.Time)
+}
+
+//ReleaseSchedule represents the ReleaseScheduleService layer
+//It has an instance of a DBClient layer,
+//A github client instance,
+//A ConfigService instance,
+//A BuildService instance and
+//An AuditService instance
+type ReleaseSchedule struct {
+	SQL           storage.SQLStorage
+	GithubClient  clients.GithubClient
+	ConfigService ConfigurationService
+	BuildService  BuildService
+	AuditService  AuditService
+}
+
+//NewReleaseScheduleService initializes a ReleaseScheduleService
+func NewReleaseScheduleService(sql storage.SQLStorage, githubClient clients.GithubClient, configService ConfigurationService, buildService BuildService, auditService AuditService) *ReleaseSchedule {
+	return &ReleaseSchedule{
+		SQL:           sql,
+		GithubClient:  githubClient,
+		ConfigService: configService,
+		BuildService:  buildService,
+		AuditService:  auditService,
+	}
+}
+
+//Create creates a release train for a configured repository.
+func (s *ReleaseSchedule) Create(ctx context.Context, repositoryName string, r *models.ReleaseSchedulePayload) (*models.ReleaseSchedule, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "ReleaseSchedule.Create")
+	defer span.End()
+
+	if _, err := s.ConfigService.Get(ctx, repositoryName); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierrors.NewNotFoundApiError(fmt.Sprintf("configuration for repository %s not found", repositoryName))
+		}
+		return nil, apierrors.NewInternalServerApiError("error checking configuration existance", err)
+	}
+
+	schedule := models.NewReleaseSchedule(repositoryName, r)
+
+	if err := scheduleNextRun(schedule, time.Now()); err != nil {
+		return nil, err
+	}
+
+	//Save it into database
+	if err := s.SQL.Insert(ctx, schedule); err != nil {
+		return nil, apierrors.NewInternalServerApiError("error saving new release schedule", err)
+	}
+
+	return schedule, nil
+}
+
+//List returns the release trains of a repository.
+func (s *ReleaseSchedule) List(ctx context.Context, repositoryName string) ([]models.ReleaseSchedule, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "ReleaseSchedule.List")
+	defer span.End()
+
+	schedules := make([]models.ReleaseSchedule, 0)
+
+	if err := s.SQL.GetBy(ctx, &schedules, "repository_name = ?", repositoryName); err != nil {
+		return nil, apierrors.NewInternalServerApiError("error getting release schedules", err)
+	}
+
+	return schedules, nil
+}
+
+//Update modifies a release train and recalculates its next run.
+func (s *ReleaseSchedule) Update(ctx context.Context, repositoryName string, id uint32, r *models.ReleaseSchedulePayload) (*models.ReleaseSchedule, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "ReleaseSchedule.Update")
+	defer span.End()
+
+	schedule, err := s.get(ctx, repositoryName, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	schedule.UpdateReleaseSchedule(r)
+
+	if err := scheduleNextRun(schedule, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := s.SQL.Update(ctx, schedule); err != nil {
+		return nil, apierrors.NewInternalServerApiError("error updating release schedule", err)
+	}
+
+	return schedule, nil
+}
+
+//Delete erases a release train.
+func (s *ReleaseSchedule) Delete(ctx context.Context, repositoryName string, id uint32) apierrors.ApiError {
+	ctx, span := tracing.Start(ctx, "ReleaseSchedule.Delete")
+	defer span.End()
+
+	schedule, err := s.get(ctx, repositoryName, id)
+
+	if err != nil {
+		return err
+	}
+
+	if err := s.SQL.Delete(ctx, schedule); err != nil {
+		return apierrors.NewInternalServerApiError("error deleting release schedule", err)
+	}
+
+	return nil
+}
+
+//RunDueSchedules cuts the release branches of every enabled release train due at the given time.
+func (s *ReleaseSchedule) RunDueSchedules(ctx context.Context, now time.Time) {
+	ctx, span := tracing.Start(ctx, "ReleaseSchedule.RunDueSchedules")
+	defer span.End()
+
+	var schedules []models.ReleaseSchedule
+
+	if err := s.SQL.GetBy(ctx, &schedules, "enabled = ? AND next_run_at <= ?", true, now); err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("error getting due release schedules")
+		return
+	}
+
+	for i := range schedules {
+		schedule := &schedules[i]
+		scheduleCtx := logger.WithRepository(ctx, *schedule.RepositoryName)
+
+		branchName, err := s.CutReleaseBranch(scheduleCtx, schedule)
+
+		if err != nil {
+			logger.FromContext(scheduleCtx).Error().Err(err).Uint32("schedule", schedule.ID).Msg("error cutting release branch")
+		} else {
+			logger.FromContext(scheduleCtx).Info().Uint32("schedule", schedule.ID).Str("branch", branchName).Msg("release branch cut successfully")
+		}
+
+		schedule.LastRunAt = &now
+		if err := scheduleNextRun(schedule, now); err != nil {
+			schedule.Enabled = false
+		}
+
+		if err := s.SQL.Update(scheduleCtx, schedule); err != nil {
+			logger.FromContext(scheduleCtx).Error().Err(err).Uint32("schedule", schedule.ID).Msg("error updating release schedule")
+		}
+	}
+}
+
+//CutReleaseBranch creates the release branch for the next minor version from the source branch.
+//It opens the pull request to master and announces the cut when the release train asks for it.
+func (s *ReleaseSchedule) CutReleaseBranch(ctx context.Context, schedule *models.ReleaseSchedule) (string, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "ReleaseSchedule.CutReleaseBranch")
+	defer span.End()
+
+	config, err := s.ConfigService.Get(ctx, *schedule.RepositoryName)
+
+	if err != nil {
+		return "", apierrors.NewInternalServerApiError("error getting repository configuration", err)
+	}
+
+	//The branch name is computed from the next minor version
+	nextVersion := s.BuildService.IncrementSemVer(*s.BuildService.GetLatestBuild(ctx, config), "minor")
+	version := fmt.Sprintf("%d.%d", nextVersion.Major, nextVersion.Minor)
+	branchName := releaseBranchPrefix + version
+
+	if _, branchErr := s.GithubClient.GetBranchInformation(ctx, config, branchName); branchErr == nil {
+		return branchName, apierrors.NewApiError(fmt.Sprintf("release branch %s already exists", branchName), "conflict_error", http.StatusConflict, apierrors.CauseList{})
+	}
+
+	sourceBranch, sourceErr := s.GithubClient.GetBranchInformation(ctx, config, *schedule.SourceBranch)
+
+	if sourceErr != nil {
+		return branchName, sourceErr
+	}
+
+	//Recorded before creating the branch, so its push is never reported as a direct push
+	if err := s.SQL.Insert(ctx, models.NewServiceCommit(*schedule.RepositoryName, branchName, sourceBranch.Commit.Sha)); err != nil {
+		return branchName, apierrors.NewInternalServerApiError("error saving the release branch commit", err)
+	}
+
+	if createErr := s.GithubClient.CreateBranch(ctx, config, &models.Branch{Name: utils.Stringify(branchName)}, sourceBranch.Commit.Sha); createErr != nil {
+		return branchName, createErr
+	}
+
+	event := models.AuditEvent{
+		RepositoryName: schedule.RepositoryName,
+		Type:           utils.Stringify(models.AuditEventReleaseCut),
+		Branch:         utils.Stringify(branchName),
+		Sha:            utils.Stringify(sourceBranch.Commit.Sha),
+		Actor:          utils.Stringify("release-train"),
+		Description:    utils.Stringify(fmt.Sprintf("%s cut from %s", branchName, *schedule.SourceBranch)),
+	}
+
+	if auditErr := s.AuditService.Record(ctx, &event); auditErr != nil {
+		logger.FromContext(ctx).Error().Err(auditErr).Str("branch", branchName).Msg("error recording release cut")
+	}
+
+	announcement := fmt.Sprintf("# Release train :steam_locomotive: \n\n> **%s** was cut from **%s**.", branchName, *schedule.SourceBranch)
+
+	if schedule.OpenPullRequest {
+		pr, prErr := s.GithubClient.CreatePullRequest(ctx, config, branchName, releaseTargetBranch, fmt.Sprintf("Release %s", version), automaticBuildBody)
+
+		if prErr != nil {
+			logger.FromContext(ctx).Error().Err(prErr).Str("branch", branchName).Msg("error opening release pull request")
+		} else {
+			announcement = announcement + fmt.Sprintf("\n\nRelease pull request: #%d", pr.Number)
+		}
+	}
+
+	if schedule.Announce {
+		if commentErr := s.GithubClient.CreateCommitComment(ctx, config, sourceBranch.Commit.Sha, announcement); commentErr != nil {
+			logger.FromContext(ctx).Error().Err(commentErr).Str("branch", branchName).Msg("error announcing release cut")
+		}
+	}
+
+	return branchName, nil
+}
+
+func (s *ReleaseSchedule) get(ctx context.Context, repositoryName string, id uint32) (*models.ReleaseSchedule, apierrors.ApiError) {
+	var schedule models.ReleaseSchedule
+
+	if err := s.SQL.GetBy(ctx, &schedule, "id = ? AND repository_name = ?", id, repositoryName); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierrors.NewNotFoundApiError(fmt.Sprintf("release schedule %d not found", id))
+		}
+		return nil, apierrors.NewInternalServerApiError("error getting release schedule", err)
+	}
+
+	return &schedule, nil
+}
+
+//scheduleNextRun validates the cron expression and sets the next run after the given time.
+//The cron expression is evaluated in the timezone of the release train, UTC when it has none.
+func scheduleNextRun(schedule *models.ReleaseSchedule, from time.Time) apierrors.ApiError {
+	if schedule.Cron == nil {
+		return apierrors.NewBadRequestApiError("cron expression is required")
+	}
+
+	timezone := "UTC"
+	if schedule.Timezone != nil && *schedule.Timezone != "" {
+		timezone = *schedule.Timezone
+	}
+
+	location, err := time.LoadLocation(timezone)
+
+	if err != nil {
+		return apierrors.NewBadRequestApiError(fmt.Sprintf("invalid timezone %s", timezone))
+	}
+
+	cronSchedule, err := cron.ParseStandard(*schedule.Cron)
+
+	if err != nil {
+		return apierrors.NewBadRequestApiError(fmt.Sprintf("invalid cron expression: %s", err.Error()))
+	}
+
+	nextRun := cronSchedule.Next(from.In(location))
+	schedule.NextRunAt = &nextRun
+
+	return nil
+}
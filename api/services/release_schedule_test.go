@@ -0,0 +1,336 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/coreos/go-semver/semver"
+	"github.com/golang/mock/gomock"
+	"github.com/hbalmes/ci_cd-api/api/mocks/interfaces"
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/utils"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReleaseSchedule_Create(t *testing.T) {
+	type args struct {
+		payload *models.ReleaseSchedulePayload
+	}
+
+	type expects struct {
+		getConfigErr   error
+		sqlInsertError error
+		insertTimes    int
+		err            apierrors.ApiError
+	}
+
+	config := models.Configuration{
+		ID: utils.Stringify("hbalmes/ci-cd_api"),
+	}
+
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+		expects expects
+	}{
+		{
+			name:    "configuration not found",
+			args:    args{payload: &models.ReleaseSchedulePayload{Cron: utils.Stringify("0 10 * * 1")}},
+			wantErr: true,
+			expects: expects{
+				getConfigErr: gorm.ErrRecordNotFound,
+				err:          apierrors.NewNotFoundApiError("configuration for repository hbalmes/ci-cd_api not found"),
+			},
+		},
+		{
+			name:    "invalid cron expression",
+			args:    args{payload: &models.ReleaseSchedulePayload{Cron: utils.Stringify("every monday")}},
+			wantErr: true,
+			expects: expects{
+				err: apierrors.NewBadRequestApiError("invalid cron expression: expected exactly 5 fields, found 2: [every monday]"),
+			},
+		},
+		{
+			name:    "invalid timezone",
+			args:    args{payload: &models.ReleaseSchedulePayload{Cron: utils.Stringify("0 10 * * 1"), Timezone: utils.Stringify("Mars/Olympus")}},
+			wantErr: true,
+			expects: expects{
+				err: apierrors.NewBadRequestApiError("invalid timezone Mars/Olympus"),
+			},
+		},
+		{
+			name:    "error saving release schedule",
+			args:    args{payload: &models.ReleaseSchedulePayload{Cron: utils.Stringify("0 10 * * 1")}},
+			wantErr: true,
+			expects: expects{
+				sqlInsertError: gorm.ErrInvalidSQL,
+				insertTimes:    1,
+				err:            apierrors.NewInternalServerApiError("error saving new release schedule", gorm.ErrInvalidSQL),
+			},
+		},
+		{
+			name:    "release schedule saved successfully",
+			args:    args{payload: &models.ReleaseSchedulePayload{Cron: utils.Stringify("0 10 * * 1")}},
+			wantErr: false,
+			expects: expects{
+				insertTimes: 1,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+			configService := interfaces.NewMockConfigurationService(ctrl)
+
+			configService.EXPECT().
+				Get(gomock.Any(), gomock.Any()).
+				Return(&config, tt.expects.getConfigErr).
+				Times(1)
+
+			sqlStorage.EXPECT().
+				Insert(gomock.Any(), gomock.Any()).
+				Return(tt.expects.sqlInsertError).
+				Times(tt.expects.insertTimes)
+
+			s := &ReleaseSchedule{
+				SQL:           sqlStorage,
+				ConfigService: configService,
+			}
+
+			got, err := s.Create(context.Background(), "hbalmes/ci-cd_api", tt.args.payload)
+
+			if tt.wantErr {
+				assert.Nil(t, got)
+				assert.Equal(t, tt.expects.err, err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, "develop", *got.SourceBranch)
+			assert.Equal(t, "UTC", *got.Timezone)
+			assert.True(t, got.Enabled)
+			assert.NotNil(t, got.NextRunAt)
+		})
+	}
+}
+
+func TestScheduleNextRun(t *testing.T) {
+	//Friday 2020-11-27 12:00 UTC, 09:00 in Buenos Aires
+	from := time.Date(2020, 11, 27, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		timezone *string
+		want     time.Time
+	}{
+		{
+			name: "release train without timezone",
+			want: time.Date(2020, 11, 30, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "release train in the timezone of the team",
+			timezone: utils.Stringify("America/Argentina/Buenos_Aires"),
+			want:     time.Date(2020, 11, 30, 13, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := models.ReleaseSchedule{Cron: utils.Stringify("0 10 * * 1"), Timezone: tt.timezone}
+
+			err := scheduleNextRun(&schedule, from)
+
+			assert.Nil(t, err)
+			assert.True(t, tt.want.Equal(*schedule.NextRunAt))
+		})
+	}
+}
+
+func TestReleaseSchedule_CutReleaseBranch(t *testing.T) {
+	type expects struct {
+		releaseBranchErr apierrors.ApiError
+		sourceBranchErr  apierrors.ApiError
+		sourceTimes      int
+		recordErr        error
+		recordTimes      int
+		createBranchErr  apierrors.ApiError
+		createTimes      int
+		auditTimes       int
+		prTimes          int
+		commentTimes     int
+		err              apierrors.ApiError
+	}
+
+	config := models.Configuration{
+		ID: utils.Stringify("hbalmes/ci-cd_api"),
+	}
+
+	schedule := models.ReleaseSchedule{
+		RepositoryName:  utils.Stringify("hbalmes/ci-cd_api"),
+		Cron:            utils.Stringify("0 10 * * 1"),
+		SourceBranch:    utils.Stringify("develop"),
+		OpenPullRequest: true,
+		Announce:        true,
+		Enabled:         true,
+	}
+
+	tests := []struct {
+		name    string
+		wantErr bool
+		expects expects
+	}{
+		{
+			name:    "release branch already exists",
+			wantErr: true,
+			expects: expects{
+				err: apierrors.NewApiError("release branch release/1.3 already exists", "conflict_error", 409, apierrors.CauseList{}),
+			},
+		},
+		{
+			name:    "error getting source branch",
+			wantErr: true,
+			expects: expects{
+				releaseBranchErr: apierrors.NewNotFoundApiError("branch not found"),
+				sourceBranchErr:  apierrors.NewInternalServerApiError("error getting branch", nil),
+				sourceTimes:      1,
+				err:              apierrors.NewInternalServerApiError("error getting branch", nil),
+			},
+		},
+		{
+			name:    "error saving the commit of the release branch",
+			wantErr: true,
+			expects: expects{
+				releaseBranchErr: apierrors.NewNotFoundApiError("branch not found"),
+				sourceTimes:      1,
+				recordErr:        gorm.ErrInvalidSQL,
+				recordTimes:      1,
+				err:              apierrors.NewInternalServerApiError("error saving the release branch commit", gorm.ErrInvalidSQL),
+			},
+		},
+		{
+			name:    "error creating release branch",
+			wantErr: true,
+			expects: expects{
+				releaseBranchErr: apierrors.NewNotFoundApiError("branch not found"),
+				sourceTimes:      1,
+				recordTimes:      1,
+				createBranchErr:  apierrors.NewInternalServerApiError("error creating branch", nil),
+				createTimes:      1,
+				err:              apierrors.NewInternalServerApiError("error creating branch", nil),
+			},
+		},
+		{
+			name:    "release branch cut, pull request opened and announced",
+			wantErr: false,
+			expects: expects{
+				releaseBranchErr: apierrors.NewNotFoundApiError("branch not found"),
+				sourceTimes:      1,
+				recordTimes:      1,
+				createTimes:      1,
+				auditTimes:       1,
+				prTimes:          1,
+				commentTimes:     1,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+			githubClient := interfaces.NewMockGithubClient(ctrl)
+			configService := interfaces.NewMockConfigurationService(ctrl)
+			buildService := interfaces.NewMockBuildService(ctrl)
+			auditService := interfaces.NewMockAuditService(ctrl)
+
+			latest := semver.New("1.2.5")
+
+			configService.EXPECT().
+				Get(gomock.Any(), "hbalmes/ci-cd_api").
+				Return(&config, nil).
+				Times(1)
+
+			buildService.EXPECT().
+				GetLatestBuild(gomock.Any(), &config).
+				Return(latest).
+				Times(1)
+
+			buildService.EXPECT().
+				IncrementSemVer(*latest, "minor").
+				Return(*semver.New("1.3.0")).
+				Times(1)
+
+			githubClient.EXPECT().
+				GetBranchInformation(gomock.Any(), &config, "release/1.3").
+				Return(&models.GetBranchResponse{}, tt.expects.releaseBranchErr).
+				Times(1)
+
+			sourceBranch := models.GetBranchResponse{Name: "develop"}
+			sourceBranch.Commit.Sha = "23456789qwertyuiasdfghjzxcvbn"
+
+			githubClient.EXPECT().
+				GetBranchInformation(gomock.Any(), &config, "develop").
+				Return(&sourceBranch, tt.expects.sourceBranchErr).
+				Times(tt.expects.sourceTimes)
+
+			//The commit is recorded before creating the branch, so its push is not a direct push
+			record := sqlStorage.EXPECT().
+				Insert(gomock.Any(), models.NewServiceCommit("hbalmes/ci-cd_api", "release/1.3", "23456789qwertyuiasdfghjzxcvbn")).
+				Return(tt.expects.recordErr).
+				Times(tt.expects.recordTimes)
+
+			githubClient.EXPECT().
+				CreateBranch(gomock.Any(), &config, &models.Branch{Name: utils.Stringify("release/1.3")}, "23456789qwertyuiasdfghjzxcvbn").
+				After(record).
+				Return(tt.expects.createBranchErr).
+				Times(tt.expects.createTimes)
+
+			auditService.EXPECT().
+				Record(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, event *models.AuditEvent) apierrors.ApiError {
+					assert.Equal(t, models.AuditEventReleaseCut, *event.Type)
+					assert.Equal(t, "release/1.3", *event.Branch)
+					return nil
+				}).
+				Times(tt.expects.auditTimes)
+
+			githubClient.EXPECT().
+				CreatePullRequest(gomock.Any(), &config, "release/1.3", "master", "Release 1.3", gomock.Any()).
+				Return(&models.PullRequestResponse{Number: 42}, nil).
+				Times(tt.expects.prTimes)
+
+			githubClient.EXPECT().
+				CreateCommitComment(gomock.Any(), &config, "23456789qwertyuiasdfghjzxcvbn", gomock.Any()).
+				DoAndReturn(func(ctx context.Context, config *models.Configuration, sha string, body string) apierrors.ApiError {
+					assert.Contains(t, body, "**release/1.3** was cut from **develop**")
+					assert.Contains(t, body, "#42")
+					return nil
+				}).
+				Times(tt.expects.commentTimes)
+
+			s := &ReleaseSchedule{
+				SQL:           sqlStorage,
+				GithubClient:  githubClient,
+				ConfigService: configService,
+				BuildService:  buildService,
+				AuditService:  auditService,
+			}
+
+			branchName, err := s.CutReleaseBranch(context.Background(), &schedule)
+
+			assert.Equal(t, "release/1.3", branchName)
+			if tt.wantErr {
+				assert.Equal(t, tt.expects.err, err)
+				return
+			}
+			assert.Nil(t, err)
+		})
+	}
+}
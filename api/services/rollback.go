@@ -0,0 +1,274 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hbalmes/ci_cd-api/api/clients"
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/services/storage"
+	"github.com/hbalmes/ci_cd-api/api/utils"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"github.com/hbalmes/ci_cd-api/api/utils/logger"
+	"github.com/hbalmes/ci_cd-api/api/utils/tracing"
+	"github.com/jinzhu/gorm"
+)
+
+//RollbackService is an interface which represents the RollbackService for testing purpose.
+type RollbackService interface {
+	Rollback(ctx context.Context, repositoryName string, buildID uint32, r *models.RollbackPayload) (*models.Rollback, apierrors.ApiError)
+}
+
+//Rollback represents the RollbackService layer
+//It has an instance of a DBClient layer,
+//A github client instance,
+//A ConfigService instance,
+//A DeploymentService instance and
+//An AuditService instance
+type Rollback struct {
+	SQL               storage.SQLStorage
+	GithubClient      clients.GithubClient
+	ConfigService     ConfigurationService
+	DeploymentService DeploymentService
+	AuditService      AuditService
+}
+
+//NewRollbackService initializes a RollbackService
+func NewRollbackService(sql storage.SQLStorage, githubClient clients.GithubClient, configService ConfigurationService, deploymentService DeploymentService, auditService AuditService) *Rollback {
+	return &Rollback{
+		SQL:               sql,
+		GithubClient:      githubClient,
+		ConfigService:     configService,
+		DeploymentService: deploymentService,
+		AuditService:      auditService,
+	}
+}
+
+//Rollback marks a productive build as rolled back and goes back to the previous good productive build.
+//The rollback is attributed to the github user of the api token, the requester of the payload must be that user when sent.
+//The latest build points to the previous build unless a newer productive build was already released,
+//and the version of the rolled back build is never reused.
+//Optionally, the github release is turned into a prerelease or a draft
+//and the previous build is deployed into the environments running the rolled back build.
+func (s *Rollback) Rollback(ctx context.Context, repositoryName string, buildID uint32, r *models.RollbackPayload) (*models.Rollback, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "Rollback.Rollback")
+	defer span.End()
+
+	requester, apiErr := AuthenticatedUser(ctx)
+
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	if r.RequestedBy != nil && *r.RequestedBy != "" && !strings.EqualFold(*r.RequestedBy, requester) {
+		return nil, apierrors.NewForbiddenApiError(fmt.Sprintf("the requester %s is not the authenticated user %s", *r.RequestedBy, requester))
+	}
+
+	if r.ReleaseAction != nil && *r.ReleaseAction != models.RollbackReleaseActionPrerelease && *r.ReleaseAction != models.RollbackReleaseActionDraft {
+		return nil, apierrors.NewBadRequestApiError(fmt.Sprintf("invalid release action %s, use %s or %s", *r.ReleaseAction, models.RollbackReleaseActionPrerelease, models.RollbackReleaseActionDraft))
+	}
+
+	config, err := s.ConfigService.Get(ctx, repositoryName)
+
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierrors.NewNotFoundApiError(fmt.Sprintf("configuration for repository %s not found", repositoryName))
+		}
+		return nil, apierrors.NewInternalServerApiError("error checking configuration existance", err)
+	}
+
+	var build models.Build
+
+	if err := s.SQL.GetBy(ctx, &build, "id = ? AND repository_name = ?", buildID, repositoryName); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apierrors.NewNotFoundApiError(fmt.Sprintf("build %d not found", buildID))
+		}
+		return nil, apierrors.NewInternalServerApiError("error getting build", err)
+	}
+
+	if build.Type == nil || *build.Type != "productive" {
+		return nil, apierrors.NewBadRequestApiError("only productive builds can be rolled back")
+	}
+
+	if build.Status != nil && *build.Status == models.BuildStatusRolledBack {
+		return nil, apierrors.NewApiError(fmt.Sprintf("build %d is already rolled back", build.ID), "conflict_error", http.StatusConflict, apierrors.CauseList{})
+	}
+
+	builds := make([]models.Build, 0)
+
+	if err := s.SQL.GetBy(ctx, &builds, "repository_name = ? AND type = ? AND status = ?", repositoryName, "productive", models.BuildStatusFinished); err != nil {
+		return nil, apierrors.NewInternalServerApiError("error getting productive builds", err)
+	}
+
+	var previous *models.Build
+	superseded := false
+
+	for i := range builds {
+		if builds[i].ID > build.ID {
+			superseded = true
+		} else if builds[i].ID < build.ID && (previous == nil || builds[i].ID > previous.ID) {
+			previous = &builds[i]
+		}
+	}
+
+	if previous == nil {
+		return nil, apierrors.NewApiError(fmt.Sprintf("there is no productive build previous to build %d to roll back to", build.ID), "conflict_error", http.StatusConflict, apierrors.CauseList{})
+	}
+
+	build.Status = utils.Stringify(models.BuildStatusRolledBack)
+	build.UpdatedAt = utils.Stringify(time.Now().Format(buildDateLayout))
+
+	if err := s.SQL.Update(ctx, &build); err != nil {
+		return nil, apierrors.NewInternalServerApiError("error updating build", err)
+	}
+
+	//A newer productive build already replaced the rolled back one
+	if !superseded {
+		if apiErr := s.repointLatestBuild(ctx, repositoryName, previous); apiErr != nil {
+			return nil, apiErr
+		}
+	}
+
+	version := getBuildVersion(&build)
+	previousVersion := getBuildVersion(previous)
+
+	if r.ReleaseAction != nil {
+		s.updateRelease(ctx, config, version, *r.ReleaseAction)
+	}
+
+	rollback := models.Rollback{
+		RolledBackBuild: &build,
+		CurrentBuild:    previous,
+		Redeployments:   make([]models.Deployment, 0),
+	}
+
+	if r.Redeploy != nil && *r.Redeploy {
+		rollback.Redeployments = s.redeploy(ctx, repositoryName, &build, previousVersion, requester, r)
+	}
+
+	description := fmt.Sprintf("%s rolled back to %s", version, previousVersion)
+	if r.Reason != nil && *r.Reason != "" {
+		description = fmt.Sprintf("%s: %s", description, *r.Reason)
+	}
+
+	event := models.AuditEvent{
+		RepositoryName: utils.Stringify(repositoryName),
+		Type:           utils.Stringify(models.AuditEventBuildRolledBack),
+		Branch:         build.Branch,
+		Sha:            build.Sha,
+		Actor:          utils.Stringify(requester),
+		Description:    utils.Stringify(description),
+	}
+
+	if err := s.AuditService.Record(ctx, &event); err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("error recording build rollback")
+	}
+
+	return &rollback, nil
+}
+
+//repointLatestBuild points the latest build of the repository, the one deployed by default, to the given build.
+func (s *Rollback) repointLatestBuild(ctx context.Context, repositoryName string, build *models.Build) apierrors.ApiError {
+
+	var latestBuild models.LatestBuild
+
+	if err := s.SQL.GetBy(ctx, &latestBuild, "repository_name = ?", repositoryName); err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return apierrors.NewInternalServerApiError("error getting latest build", err)
+		}
+		latestBuild.RepositoryName = utils.Stringify(repositoryName)
+	}
+
+	latestBuild.BuildID = build.ID
+
+	if err := s.SQL.Update(ctx, &latestBuild); err != nil {
+		return apierrors.NewInternalServerApiError("error updating latest build", err)
+	}
+
+	return nil
+}
+
+//updateRelease turns the github release of the version into a prerelease or a draft.
+//The rollback is already done, so the errors are only logged.
+func (s *Rollback) updateRelease(ctx context.Context, config *models.Configuration, version string, action string) {
+
+	release, err := s.GithubClient.GetReleaseByTag(ctx, config, version)
+
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Str("version", version).Msg("error getting rolled back release")
+		return
+	}
+
+	enabled := true
+
+	var request models.ReleaseUpdateRequest
+	switch action {
+	case models.RollbackReleaseActionDraft:
+		request.Draft = &enabled
+	default:
+		request.Prerelease = &enabled
+	}
+
+	if err := s.GithubClient.UpdateRelease(ctx, config, release.ID, &request); err != nil {
+		logger.FromContext(ctx).Error().Err(err).Str("version", version).Msg("error updating rolled back release")
+	}
+}
+
+//redeploy deploys the previous version into every environment where the rolled back build is the active deployment,
+//on behalf of the requester of the rollback.
+//Returns the deployments created, the ones refused are only logged.
+func (s *Rollback) redeploy(ctx context.Context, repositoryName string, build *models.Build, previousVersion string, requester string, r *models.RollbackPayload) []models.Deployment {
+
+	redeployments := make([]models.Deployment, 0)
+	deployments := make([]models.Deployment, 0)
+
+	if err := s.SQL.GetBy(ctx, &deployments, "build_id = ? AND status = ?", build.ID, models.DeploymentStatusSuccess); err != nil {
+		logger.FromContext(ctx).Error().Err(err).Uint32("build", build.ID).Msg("error getting rolled back build deployments")
+		return redeployments
+	}
+
+	//Rollbacks are emergencies, so they go through the freeze windows
+	freezeOverrideReason := fmt.Sprintf("rollback of %s", getBuildVersion(build))
+	if r.Reason != nil && *r.Reason != "" {
+		freezeOverrideReason = fmt.Sprintf("%s: %s", freezeOverrideReason, *r.Reason)
+	}
+
+	deployed := make(map[string]bool)
+
+	for _, deployment := range deployments {
+		if deployment.Environment == nil || deployed[*deployment.Environment] {
+			continue
+		}
+		deployed[*deployment.Environment] = true
+
+		redeployment, err := s.DeploymentService.Create(ctx, repositoryName, &models.DeploymentPayload{
+			Environment:          deployment.Environment,
+			Version:              utils.Stringify(previousVersion),
+			Description:          utils.Stringify(fmt.Sprintf("Rollback to %s", previousVersion)),
+			RequestedBy:          utils.Stringify(requester),
+			FreezeOverrideReason: utils.Stringify(freezeOverrideReason),
+		})
+
+		if err != nil {
+			logger.FromContext(ctx).Error().Err(err).
+				Str("environment", *deployment.Environment).Msg("error redeploying previous build")
+			continue
+		}
+
+		redeployments = append(redeployments, *redeployment)
+	}
+
+	return redeployments
+}
+
+//getBuildVersion returns the version of the build, like v1.2.0 or v1.3.0-beta.
+func getBuildVersion(build *models.Build) string {
+	version := fmt.Sprintf("v%d.%d.%d", build.Major, build.Minor, build.Patch)
+	if build.Tag != nil {
+		version = version + "-" + *build.Tag
+	}
+	return version
+}
@@ -0,0 +1,312 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hbalmes/ci_cd-api/api/mocks/interfaces"
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/utils"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRollback_Rollback(t *testing.T) {
+	type expects struct {
+		buildErr       error
+		buildType      string
+		buildStatus    string
+		productive     []models.Build
+		repointTimes   int
+		releaseTimes   int
+		redeployTimes  int
+		redeployErr    apierrors.ApiError
+		redeployments  int
+		currentBuildID uint32
+		err            apierrors.ApiError
+	}
+
+	config := models.Configuration{
+		ID:              utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:  utils.Stringify("ci-cd_api"),
+		RepositoryOwner: utils.Stringify("hbalmes"),
+	}
+
+	redeploy := true
+
+	payload := &models.RollbackPayload{
+		RequestedBy: utils.Stringify("HBalmes"),
+		Reason:      utils.Stringify("checkout is broken"),
+	}
+
+	goodBuilds := []models.Build{
+		{ID: 8, Major: 1, Minor: 1},
+		{ID: 10, Major: 1, Minor: 2},
+		{ID: 12, Major: 1, Minor: 3},
+	}
+
+	tests := []struct {
+		name    string
+		user    string
+		payload *models.RollbackPayload
+		expects expects
+	}{
+		{
+			name:    "api token not bound to a user",
+			payload: &models.RollbackPayload{},
+			expects: expects{
+				err: apierrors.NewForbiddenApiError("the api token must be bound to a github user"),
+			},
+		},
+		{
+			name:    "requester is not the authenticated user",
+			user:    "hbalmes",
+			payload: &models.RollbackPayload{RequestedBy: utils.Stringify("mallory")},
+			expects: expects{
+				err: apierrors.NewForbiddenApiError("the requester mallory is not the authenticated user hbalmes"),
+			},
+		},
+		{
+			name:    "invalid release action",
+			user:    "hbalmes",
+			payload: &models.RollbackPayload{RequestedBy: utils.Stringify("hbalmes"), ReleaseAction: utils.Stringify("delete")},
+			expects: expects{
+				err: apierrors.NewBadRequestApiError("invalid release action delete, use prerelease or draft"),
+			},
+		},
+		{
+			name:    "build not found",
+			user:    "hbalmes",
+			payload: payload,
+			expects: expects{
+				buildErr: gorm.ErrRecordNotFound,
+				err:      apierrors.NewNotFoundApiError("build 12 not found"),
+			},
+		},
+		{
+			name:    "test build",
+			user:    "hbalmes",
+			payload: payload,
+			expects: expects{
+				buildType: "test",
+				err:       apierrors.NewBadRequestApiError("only productive builds can be rolled back"),
+			},
+		},
+		{
+			name:    "build already rolled back",
+			user:    "hbalmes",
+			payload: payload,
+			expects: expects{
+				buildType:   "productive",
+				buildStatus: models.BuildStatusRolledBack,
+				err:         apierrors.NewApiError("build 12 is already rolled back", "conflict_error", http.StatusConflict, apierrors.CauseList{}),
+			},
+		},
+		{
+			name:    "first productive build",
+			user:    "hbalmes",
+			payload: payload,
+			expects: expects{
+				buildType:   "productive",
+				buildStatus: "finished",
+				productive:  []models.Build{{ID: 12, Major: 1, Minor: 3}},
+				err:         apierrors.NewApiError("there is no productive build previous to build 12 to roll back to", "conflict_error", http.StatusConflict, apierrors.CauseList{}),
+			},
+		},
+		{
+			name:    "build rolled back",
+			user:    "hbalmes",
+			payload: payload,
+			expects: expects{
+				buildType:      "productive",
+				buildStatus:    "finished",
+				productive:     goodBuilds,
+				repointTimes:   1,
+				currentBuildID: 10,
+			},
+		},
+		{
+			name:    "build superseded by a newer productive build",
+			user:    "hbalmes",
+			payload: payload,
+			expects: expects{
+				buildType:      "productive",
+				buildStatus:    "finished",
+				productive:     append(goodBuilds, models.Build{ID: 14, Major: 1, Minor: 4}),
+				currentBuildID: 10,
+			},
+		},
+		{
+			name: "build rolled back with release action and redeploy",
+			user: "hbalmes",
+			payload: &models.RollbackPayload{
+				Reason:        utils.Stringify("checkout is broken"),
+				ReleaseAction: utils.Stringify(models.RollbackReleaseActionPrerelease),
+				Redeploy:      &redeploy,
+			},
+			expects: expects{
+				buildType:      "productive",
+				buildStatus:    "finished",
+				productive:     goodBuilds,
+				repointTimes:   1,
+				releaseTimes:   1,
+				redeployTimes:  2,
+				redeployments:  2,
+				currentBuildID: 10,
+			},
+		},
+		{
+			name: "redeploy refused",
+			user: "hbalmes",
+			payload: &models.RollbackPayload{
+				Redeploy: &redeploy,
+			},
+			expects: expects{
+				buildType:      "productive",
+				buildStatus:    "finished",
+				productive:     goodBuilds,
+				repointTimes:   1,
+				redeployTimes:  2,
+				redeployErr:    apierrors.NewApiError("the deployment to production has 0 of 1 required approvals", "conflict_error", http.StatusConflict, apierrors.CauseList{}),
+				currentBuildID: 10,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+			githubClient := interfaces.NewMockGithubClient(ctrl)
+			configService := interfaces.NewMockConfigurationService(ctrl)
+			deploymentService := interfaces.NewMockDeploymentService(ctrl)
+			auditService := interfaces.NewMockAuditService(ctrl)
+
+			configService.EXPECT().
+				Get(gomock.Any(), "hbalmes/ci-cd_api").
+				Return(&config, nil).
+				AnyTimes()
+
+			sqlStorage.EXPECT().
+				GetBy(gomock.Any(), gomock.Any(), "id = ? AND repository_name = ?", uint32(12), "hbalmes/ci-cd_api").
+				DoAndReturn(func(ctx context.Context, build *models.Build, qry ...interface{}) error {
+					*build = models.Build{
+						ID:             12,
+						Sha:            utils.Stringify("badsha"),
+						Major:          1,
+						Minor:          3,
+						Type:           utils.Stringify(tt.expects.buildType),
+						Status:         utils.Stringify(tt.expects.buildStatus),
+						RepositoryName: utils.Stringify("hbalmes/ci-cd_api"),
+					}
+					return tt.expects.buildErr
+				}).
+				AnyTimes()
+
+			sqlStorage.EXPECT().
+				GetBy(gomock.Any(), gomock.Any(), "repository_name = ? AND type = ? AND status = ?", "hbalmes/ci-cd_api", "productive", models.BuildStatusFinished).
+				DoAndReturn(func(ctx context.Context, builds *[]models.Build, qry ...interface{}) error {
+					*builds = tt.expects.productive
+					return nil
+				}).
+				AnyTimes()
+
+			sqlStorage.EXPECT().
+				Update(gomock.Any(), gomock.AssignableToTypeOf(&models.Build{})).
+				DoAndReturn(func(ctx context.Context, build *models.Build) error {
+					assert.Equal(t, models.BuildStatusRolledBack, *build.Status)
+					return nil
+				}).
+				MaxTimes(1)
+
+			sqlStorage.EXPECT().
+				GetBy(gomock.Any(), gomock.Any(), "repository_name = ?", "hbalmes/ci-cd_api").
+				DoAndReturn(func(ctx context.Context, latest *models.LatestBuild, qry ...interface{}) error {
+					*latest = models.LatestBuild{ID: 3, BuildID: 12}
+					return nil
+				}).
+				Times(tt.expects.repointTimes)
+
+			sqlStorage.EXPECT().
+				Update(gomock.Any(), gomock.AssignableToTypeOf(&models.LatestBuild{})).
+				DoAndReturn(func(ctx context.Context, latest *models.LatestBuild) error {
+					assert.Equal(t, uint16(3), latest.ID)
+					assert.Equal(t, uint32(10), latest.BuildID)
+					return nil
+				}).
+				Times(tt.expects.repointTimes)
+
+			githubClient.EXPECT().
+				GetReleaseByTag(gomock.Any(), &config, "v1.3.0").
+				Return(&models.ReleaseResponse{ID: 99, TagName: "v1.3.0"}, nil).
+				Times(tt.expects.releaseTimes)
+
+			githubClient.EXPECT().
+				UpdateRelease(gomock.Any(), &config, int64(99), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, config *models.Configuration, releaseID int64, request *models.ReleaseUpdateRequest) apierrors.ApiError {
+					assert.True(t, *request.Prerelease)
+					assert.Nil(t, request.Draft)
+					return nil
+				}).
+				Times(tt.expects.releaseTimes)
+
+			sqlStorage.EXPECT().
+				GetBy(gomock.Any(), gomock.Any(), "build_id = ? AND status = ?", uint32(12), models.DeploymentStatusSuccess).
+				DoAndReturn(func(ctx context.Context, deployments *[]models.Deployment, qry ...interface{}) error {
+					*deployments = []models.Deployment{
+						{ID: 1, Environment: utils.Stringify("staging")},
+						{ID: 2, Environment: utils.Stringify("production")},
+						{ID: 3, Environment: utils.Stringify("production")},
+					}
+					return nil
+				}).
+				MaxTimes(1)
+
+			deploymentService.EXPECT().
+				Create(gomock.Any(), "hbalmes/ci-cd_api", gomock.Any()).
+				DoAndReturn(func(ctx context.Context, repositoryName string, r *models.DeploymentPayload) (*models.Deployment, apierrors.ApiError) {
+					assert.Equal(t, "v1.2.0", *r.Version)
+					assert.Equal(t, "hbalmes", *r.RequestedBy)
+					assert.Contains(t, *r.FreezeOverrideReason, "rollback of v1.3.0")
+					if tt.expects.redeployErr != nil {
+						return nil, tt.expects.redeployErr
+					}
+					return &models.Deployment{Environment: r.Environment, Version: r.Version}, nil
+				}).
+				Times(tt.expects.redeployTimes)
+
+			auditService.EXPECT().
+				Record(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, event *models.AuditEvent) apierrors.ApiError {
+					assert.Equal(t, models.AuditEventBuildRolledBack, *event.Type)
+					assert.Equal(t, "hbalmes", *event.Actor)
+					assert.Contains(t, *event.Description, "v1.3.0 rolled back to v1.2.0")
+					return nil
+				}).
+				MaxTimes(1)
+
+			s := &Rollback{
+				SQL:               sqlStorage,
+				GithubClient:      githubClient,
+				ConfigService:     configService,
+				DeploymentService: deploymentService,
+				AuditService:      auditService,
+			}
+
+			ctx := WithAPIToken(context.Background(), &models.APIToken{Subject: utils.Stringify(tt.user)})
+
+			rollback, err := s.Rollback(ctx, "hbalmes/ci-cd_api", 12, tt.payload)
+
+			assert.Equal(t, tt.expects.err, err)
+			if err == nil {
+				assert.Equal(t, models.BuildStatusRolledBack, *rollback.RolledBackBuild.Status)
+				assert.Equal(t, tt.expects.currentBuildID, rollback.CurrentBuild.ID)
+				assert.Len(t, rollback.Redeployments, tt.expects.redeployments)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/hbalmes/ci_cd-api/api/utils/logger"
+)
+
+const defaultSchedulerInterval = time.Minute
+
+//Scheduler periodically runs the release trains that are due, resumes the interrupted jobs
+//and resets the freeze statuses of the freeze windows ended.
+type Scheduler struct {
+	ReleaseScheduleService ReleaseScheduleService
+	WebhookService         WebhookService
+	BuildService           BuildService
+	Interval               time.Duration
+	stop                   chan struct{}
+	done                   chan struct{}
+}
+
+//NewScheduler initializes a Scheduler
+func NewScheduler(releaseScheduleService ReleaseScheduleService, webhookService WebhookService, buildService BuildService) *Scheduler {
+	return &Scheduler{
+		ReleaseScheduleService: releaseScheduleService,
+		WebhookService:         webhookService,
+		BuildService:           buildService,
+		Interval:               defaultSchedulerInterval,
+	}
+}
+
+//Start runs the due release trains every interval in background until Stop is called.
+//The jobs interrupted by a previous run of the API are resumed on start and then every interval.
+func (s *Scheduler) Start() {
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	ticker := time.NewTicker(s.Interval)
+
+	go func() {
+		defer close(s.done)
+		defer ticker.Stop()
+
+		s.WebhookService.ResumeJobs(logger.NewJobContext("resume_jobs"), time.Now())
+
+		for {
+			select {
+			case now := <-ticker.C:
+				s.ReleaseScheduleService.RunDueSchedules(logger.NewJobContext("release_trains"), now)
+				s.WebhookService.ResumeJobs(logger.NewJobContext("resume_jobs"), now)
+				s.BuildService.ResetEndedFreezes(logger.NewJobContext("ended_freezes"), now)
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+//Stop stops the scheduler, waiting for the running jobs to finish until the context is done.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	if s.stop == nil {
+		return nil
+	}
+
+	close(s.stop)
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"github.com/hbalmes/ci_cd-api/api/clients"
+	"github.com/hbalmes/ci_cd-api/api/configs"
+	"github.com/hbalmes/ci_cd-api/api/services/storage"
+)
+
+//Services holds a single instance of every service of the API.
+//The services share the storage and the github client, so they share its rate limit too.
+//Replacing any of them by a fake is enough to serve the routes in tests.
+type Services struct {
+	APIToken        APITokenService
+	Approval        ApprovalService
+	Audit           AuditService
+	AutoMerge       AutoMergeService
+	Build           BuildService
+	Changelog       ChangelogService
+	ChatOps         ChatOpsService
+	Configuration   ConfigurationService
+	Coverage        CoverageService
+	Deployment      DeploymentService
+	Dora            DoraService
+	FreezeWindow    FreezeWindowService
+	Health          HealthService
+	Job             JobService
+	MergeQueue      MergeQueueService
+	Promotion       PromotionService
+	ReleaseNotes    ReleaseNotesService
+	ReleaseSchedule ReleaseScheduleService
+	Rollback        RollbackService
+	VersionFiles    VersionFilesService
+	Webhook         WebhookService
+}
+
+//NewServices initializes every service once, each one before the services depending on it
+func NewServices(sql storage.SQLStorage, githubClient clients.GithubClient, auth configs.AuthConfig) *Services {
+	apiToken := NewAPITokenService(sql, auth.BootstrapToken)
+	audit := NewAuditService(sql)
+	coverage := NewCoverageService(sql)
+	job := NewJobService(sql)
+	health := NewHealthService(sql, githubClient)
+	configuration := NewConfigurationService(sql, githubClient)
+	dora := NewDoraService(sql, configuration)
+	changelog := NewChangelogService(sql, githubClient)
+	releaseNotes := NewReleaseNotesService(sql, githubClient)
+	versionFiles := NewVersionFilesService(sql, githubClient)
+	freezeWindow := NewFreezeWindowService(sql, configuration, audit)
+	build := NewBuildService(sql, githubClient, releaseNotes, changelog, versionFiles, freezeWindow)
+	approval := NewApprovalService(sql, githubClient, configuration, audit)
+	deployment := NewDeploymentService(sql, githubClient, configuration, approval, freezeWindow)
+	mergeQueue := NewMergeQueueService(sql, githubClient, build)
+	autoMerge := NewAutoMergeService(sql, githubClient, build, configuration)
+	chatOps := NewChatOpsService(sql, githubClient, build, configuration, mergeQueue)
+	promotion := NewPromotionService(sql, githubClient, configuration, build, audit, approval, freezeWindow)
+	releaseSchedule := NewReleaseScheduleService(sql, githubClient, configuration, build, audit)
+	rollback := NewRollbackService(sql, githubClient, configuration, deployment, audit)
+	webhook := NewWebhookService(sql, githubClient, configuration, build, chatOps, audit, autoMerge, mergeQueue, deployment, job)
+
+	return &Services{
+		APIToken:        apiToken,
+		Approval:        approval,
+		Audit:           audit,
+		AutoMerge:       autoMerge,
+		Build:           build,
+		Changelog:       changelog,
+		ChatOps:         chatOps,
+		Configuration:   configuration,
+		Coverage:        coverage,
+		Deployment:      deployment,
+		Dora:            dora,
+		FreezeWindow:    freezeWindow,
+		Health:          health,
+		Job:             job,
+		MergeQueue:      mergeQueue,
+		Promotion:       promotion,
+		ReleaseNotes:    releaseNotes,
+		ReleaseSchedule: releaseSchedule,
+		Rollback:        rollback,
+		VersionFiles:    versionFiles,
+		Webhook:         webhook,
+	}
+}
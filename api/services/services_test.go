@@ -0,0 +1,42 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hbalmes/ci_cd-api/api/configs"
+	"github.com/hbalmes/ci_cd-api/api/mocks/interfaces"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewServices(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+	githubClient := interfaces.NewMockGithubClient(ctrl)
+
+	s := NewServices(sqlStorage, githubClient, configs.AuthConfig{})
+
+	//Every service uses the given github client
+	assert.Same(t, githubClient, s.Webhook.(*Webhook).GithubClient)
+	assert.Same(t, githubClient, s.Build.(*Build).GithubClient)
+	assert.Same(t, githubClient, s.Configuration.(*Configuration).GithubClient)
+	assert.Same(t, githubClient, s.Health.(*Health).GithubClient)
+
+	//The services depending on another one share its single instance
+	webhook := s.Webhook.(*Webhook)
+	assert.Same(t, s.Configuration, webhook.ConfigService)
+	assert.Same(t, s.Build, webhook.BuildService)
+	assert.Same(t, s.ChatOps, webhook.ChatOpsService)
+	assert.Same(t, s.Deployment, webhook.DeploymentService)
+	assert.Same(t, s.Job, webhook.JobService)
+	assert.Same(t, s.Build, s.MergeQueue.(*MergeQueue).BuildService)
+	assert.Same(t, s.Build, s.ChatOps.(*ChatOps).BuildService)
+	assert.Same(t, s.MergeQueue, s.ChatOps.(*ChatOps).MergeQueueService)
+	assert.Same(t, s.FreezeWindow, s.Build.(*Build).FreezeWindowService)
+	assert.Same(t, s.FreezeWindow, s.Promotion.(*Promotion).FreezeWindowService)
+	assert.Same(t, s.Approval, s.Deployment.(*Deployment).ApprovalService)
+	assert.Same(t, s.Deployment, s.Rollback.(*Rollback).DeploymentService)
+	assert.Same(t, s.Audit, s.Promotion.(*Promotion).AuditService)
+}
@@ -1,21 +1,40 @@
 package storage
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"github.com/hbalmes/ci_cd-api/api/configs"
+	"github.com/hbalmes/ci_cd-api/api/metrics"
 	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/models/webhook"
+	"github.com/hbalmes/ci_cd-api/api/utils/logger"
+	"github.com/hbalmes/ci_cd-api/api/utils/tracing"
+	"strings"
+	"time"
 
 	"github.com/jinzhu/gorm"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 //SQLStorage is an interface built to represent a relational database accesor
 type SQLStorage interface {
-	Insert(interface{}) error
-	Update(interface{}) error
-	Get(interface{}, interface{}) error
-	GetBy(interface{}, ...interface{}) error
-	Delete(interface{}) error
-	DeleteFromRequireStatusChecksByConfigurationID(*string) error
+	Insert(context.Context, interface{}) error
+	Update(context.Context, interface{}) error
+	UpdateBy(context.Context, interface{}, map[string]interface{}, string, ...interface{}) (int64, error)
+	Get(context.Context, interface{}, interface{}) error
+	GetBy(context.Context, interface{}, ...interface{}) error
+	Delete(context.Context, interface{}) error
+	DeleteFromRequireStatusChecksByConfigurationID(context.Context, *string) error
+	DeleteFromMaintainersByConfigurationID(context.Context, *string) error
+	DeleteFromBranchCleanupPrefixesByConfigurationID(context.Context, *string) error
+	DeleteFromVersionFilesByConfigurationID(context.Context, *string) error
+	DeleteFromEnvironmentsByConfigurationID(context.Context, *string) error
+	DeleteFromEnvironmentApproversByConfigurationID(context.Context, *string) error
+	Ping(context.Context) error
 }
 
 //SQLClient is an interface built to represent a *gorm.DB instance generated by GORM
@@ -23,12 +42,26 @@ type SQLClient interface {
 	Create(value interface{}) *gorm.DB
 	Find(out interface{}, where ...interface{}) *gorm.DB
 	Save(value interface{}) *gorm.DB
+	Model(value interface{}) *gorm.DB
 	Delete(value interface{}, where ...interface{}) *gorm.DB
 	Set(name string, value interface{}) *gorm.DB
 	Close() error
 	AutoMigrate(values ...interface{}) *gorm.DB
+	DB() *sql.DB
 }
 
+//SchemaVersion is the version of the database schema expected by this API
+//It must be bumped every time a model is added or changed
+const SchemaVersion = 11
+
+//SchemaMigrationID is the id of the row keeping the version of the database schema
+const SchemaMigrationID = 1
+
+const (
+	connectionInitialBackoff = time.Second
+	connectionMaxBackoff     = 30 * time.Second
+)
+
 //SQL implements the SQLStorage interface
 type SQL struct {
 	Client SQLClient
@@ -36,12 +69,9 @@ type SQL struct {
 
 //NewMySQL stablish a connection with a mysql database
 //Returns an error in case of being imposible to stablish the connection
-func NewMySQL() (*SQL, error) {
+func NewMySQL(cfg configs.DatabaseConfig) (*SQL, error) {
 	//Stablish a database connection
-	db, err := gorm.Open("mysql", fmt.Sprintf(
-		"%s:%s@tcp(%s)/%s?charset=utf8&parseTime=True&loc=Local",
-		configs.GetDBConnectionParams()...,
-	))
+	db, err := gorm.Open("mysql", cfg.DSN())
 
 	//Something was wrong stablishing the database connection
 	if err != nil {
@@ -50,61 +80,199 @@ func NewMySQL() (*SQL, error) {
 		}, err
 	}
 
-	//Setting the database logger
-	/*
-		db.SetLogger(gorm.Logger{
-			LogWriter: logger.Log,
-		})
-	*/
-	db.LogMode(true)
+	//Setting the database logger, the queries are only logged on debug level
+	db.SetLogger(gormLogger{})
+	db.LogMode(zerolog.GlobalLevel() <= zerolog.DebugLevel)
 
 	return &SQL{
 		Client: db,
 	}, nil
 }
 
-//Insert create and save an element into the database
-func (s *SQL) Insert(e interface{}) error {
-	if err := s.Client.Create(e).Error; err != nil {
-		return err
+//ConnectMySQL stablish a connection with a mysql database, retrying with an exponential backoff
+//Returns the last error in case of being imposible to stablish the connection after the configured attempts
+func ConnectMySQL(ctx context.Context, cfg configs.DatabaseConfig) (*SQL, error) {
+	backoff := connectionInitialBackoff
+
+	for attempt := 1; ; attempt++ {
+		s, err := NewMySQL(cfg)
+		if err == nil {
+			return s, nil
+		}
+
+		if attempt >= cfg.ConnectionAttempts {
+			return nil, err
+		}
+
+		logger.FromContext(ctx).Warn().Err(err).Int("attempt", attempt).Dur("backoff", backoff).Msg("error stablishing the database connection, retrying")
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		if backoff *= 2; backoff > connectionMaxBackoff {
+			backoff = connectionMaxBackoff
+		}
 	}
-	return nil
 }
 
-//Get searches an element into the database and returns the found value
-func (s *SQL) Get(e interface{}, id interface{}) error {
-	if err := s.Client.Set("gorm:auto_preload", true).Find(e, id).Error; err != nil {
+//Migrate creates or updates the tables of every model and records the version of the schema
+//The version is never lowered, so an older instance does not overwrite the one of a newer instance
+func (s *SQL) Migrate(ctx context.Context) error {
+	done := observe(ctx, "Migrate", "migrate")
+	err := s.Client.AutoMigrate(&models.Configuration{}, &models.RequireStatusCheck{}, &webhook.Webhook{}, &models.PullRequest{}, &models.Build{}, &models.LatestBuild{},
+		&models.Coverage{}, &models.PackageCoverage{}, &models.Maintainer{}, &models.ReleaseOverride{},
+		&models.BranchHead{}, &models.AuditEvent{}, &models.ReleaseSchedule{}, &models.MergeQueueEntry{},
+		&models.BranchCleanupPrefix{}, &models.VersionFile{}, &models.Environment{}, &models.Deployment{},
+		&models.EnvironmentApprover{}, &models.Approval{}, &models.FreezeWindow{}, &models.FreezeNotice{}, &models.AutoMergeNotice{}, &models.Job{}, &models.APIToken{}, &models.ServiceCommit{}, &models.ReleasedPullRequest{}, &models.SchemaMigration{}).Error
+	done(err)
+	if err != nil {
 		return err
 	}
-	return nil
-}
 
-//GetBy searches an element into the database based on the given query and returns the found values
-func (s *SQL) GetBy(e interface{}, qry ...interface{}) error {
-	if err := s.Client.Set("gorm:auto_preload", true).Find(e, qry...).Error; err != nil {
+	var migration models.SchemaMigration
+	if err := s.Get(ctx, &migration, SchemaMigrationID); err != nil && err != gorm.ErrRecordNotFound {
 		return err
 	}
-	return nil
+
+	if migration.Version >= SchemaVersion {
+		return nil
+	}
+
+	return s.Update(ctx, &models.SchemaMigration{
+		ID:         SchemaMigrationID,
+		Version:    SchemaVersion,
+		MigratedAt: time.Now(),
+	})
+}
+
+//Insert create and save an element into the database
+func (s *SQL) Insert(ctx context.Context, e interface{}) error {
+	done := observe(ctx, "Insert", "insert")
+	err := s.Client.Create(e).Error
+	done(err)
+	return err
+}
+
+//Get searches an element into the database and returns the found value
+func (s *SQL) Get(ctx context.Context, e interface{}, id interface{}) error {
+	done := observe(ctx, "Get", "get")
+	err := s.Client.Set("gorm:auto_preload", true).Find(e, id).Error
+	done(err)
+	return err
+}
+
+//GetBy searches an element into the database based on the given query and returns the found values
+func (s *SQL) GetBy(ctx context.Context, e interface{}, qry ...interface{}) error {
+	done := observe(ctx, "GetBy", "get_by")
+	err := s.Client.Set("gorm:auto_preload", true).Find(e, qry...).Error
+	done(err)
+	return err
 }
 
 //Update saves an interface into the database
-func (s *SQL) Update(e interface{}) error {
-	if err := s.Client.Save(e).Error; err != nil {
-		return err
-	}
-	return nil
+func (s *SQL) Update(ctx context.Context, e interface{}) error {
+	done := observe(ctx, "Update", "update")
+	err := s.Client.Save(e).Error
+	done(err)
+	return err
 }
 
-func (s *SQL) Delete(e interface{}) error {
-	if err := s.Client.Delete(e).Error; err != nil {
-		return err
-	}
-	return nil
+//UpdateBy updates the given columns of the elements matching the query, without reading them first
+//Returns how many elements were updated, so a concurrent change of the same elements can be detected.
+func (s *SQL) UpdateBy(ctx context.Context, e interface{}, values map[string]interface{}, qry string, args ...interface{}) (int64, error) {
+	done := observe(ctx, "UpdateBy", "update_by")
+	result := s.Client.Model(e).Where(qry, args...).Updates(values)
+	done(result.Error)
+	return result.RowsAffected, result.Error
 }
 
-func (s *SQL) DeleteFromRequireStatusChecksByConfigurationID(id *string) error {
-	if err := s.Client.Delete(models.RequireStatusCheck{}).Where("configuration_id = ?", id).Error; err != nil {
-		return err
+func (s *SQL) Delete(ctx context.Context, e interface{}) error {
+	done := observe(ctx, "Delete", "delete")
+	err := s.Client.Delete(e).Error
+	done(err)
+	return err
+}
+
+func (s *SQL) DeleteFromRequireStatusChecksByConfigurationID(ctx context.Context, id *string) error {
+	done := observe(ctx, "DeleteFromRequireStatusChecksByConfigurationID", "delete")
+	err := s.Client.Delete(models.RequireStatusCheck{}).Where("configuration_id = ?", id).Error
+	done(err)
+	return err
+}
+
+func (s *SQL) DeleteFromMaintainersByConfigurationID(ctx context.Context, id *string) error {
+	done := observe(ctx, "DeleteFromMaintainersByConfigurationID", "delete")
+	err := s.Client.Delete(models.Maintainer{}, "configuration_id = ?", id).Error
+	done(err)
+	return err
+}
+
+func (s *SQL) DeleteFromBranchCleanupPrefixesByConfigurationID(ctx context.Context, id *string) error {
+	done := observe(ctx, "DeleteFromBranchCleanupPrefixesByConfigurationID", "delete")
+	err := s.Client.Delete(models.BranchCleanupPrefix{}, "configuration_id = ?", id).Error
+	done(err)
+	return err
+}
+
+func (s *SQL) DeleteFromVersionFilesByConfigurationID(ctx context.Context, id *string) error {
+	done := observe(ctx, "DeleteFromVersionFilesByConfigurationID", "delete")
+	err := s.Client.Delete(models.VersionFile{}, "configuration_id = ?", id).Error
+	done(err)
+	return err
+}
+
+func (s *SQL) DeleteFromEnvironmentsByConfigurationID(ctx context.Context, id *string) error {
+	done := observe(ctx, "DeleteFromEnvironmentsByConfigurationID", "delete")
+	err := s.Client.Delete(models.Environment{}, "configuration_id = ?", id).Error
+	done(err)
+	return err
+}
+
+func (s *SQL) DeleteFromEnvironmentApproversByConfigurationID(ctx context.Context, id *string) error {
+	done := observe(ctx, "DeleteFromEnvironmentApproversByConfigurationID", "delete")
+	err := s.Client.Delete(models.EnvironmentApprover{}, "configuration_id = ?", id).Error
+	done(err)
+	return err
+}
+
+//Ping checks that the database connection is alive
+func (s *SQL) Ping(ctx context.Context) error {
+	done := observe(ctx, "Ping", "ping")
+	err := s.Client.DB().PingContext(ctx)
+	done(err)
+	return err
+}
+
+//observe starts the span of the database operation and returns the function ending it
+//Once the operation is done, its latency is recorded and it is logged.
+//Not finding the searched elements is expected, so only the other errors are logged as errors and recorded in the span.
+func observe(ctx context.Context, method string, operation string) func(error) {
+	_, span := tracing.Start(ctx, "SQL."+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemMySQL, semconv.DBOperation(operation)),
+	)
+	start := time.Now()
+
+	return func(err error) {
+		metrics.ObserveDBQuery(operation, start)
+
+		l := logger.FromContext(ctx)
+		if err != nil && err != gorm.ErrRecordNotFound {
+			tracing.End(span, err)
+			l.Error().Err(err).Str("operation", operation).Dur("latency", time.Since(start)).Msg("database operation failed")
+			return
+		}
+		span.End()
+		l.Debug().Str("operation", operation).Dur("latency", time.Since(start)).Msg("database operation")
 	}
-	return nil
+}
+
+//gormLogger writes the gorm logs through the global logger
+type gormLogger struct{}
+
+func (gormLogger) Print(values ...interface{}) {
+	log.Debug().Msg(strings.TrimSpace(fmt.Sprint(gorm.LogFormatter(values...)...)))
 }
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/hbalmes/ci_cd-api/api/clients"
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/services/storage"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"github.com/hbalmes/ci_cd-api/api/utils/tracing"
+)
+
+const releaseCommitTemplate = "Release %s"
+
+var (
+	packageJSONVersionRegexp = regexp.MustCompile(`("version"\s*:\s*")([^"]*)`)
+	chartVersionRegexp       = regexp.MustCompile(`(?m)^(version:[ \t]*["']?)([^"'\s#]+)`)
+	chartAppVersionRegexp    = regexp.MustCompile(`(?m)^(appVersion:[ \t]*["']?)([^"'\s#]+)`)
+	goVersionRegexp          = regexp.MustCompile(`(?m)^(\s*(?:const\s+)?Version\s*(?:string\s*)?=\s*")([^"]*)`)
+)
+
+//VersionFilesService is an interface which represents the VersionFilesService for testing purpose.
+type VersionFilesService interface {
+	Bump(ctx context.Context, config *models.Configuration, pullRequest *models.PullRequest, build *models.Build, files ...models.TreeEntry) (string, apierrors.ApiError)
+}
+
+//VersionFiles represents the VersionFilesService layer
+//It has an instance of a DBClient layer and
+//A github client instance
+type VersionFiles struct {
+	SQL          storage.SQLStorage
+	GithubClient clients.GithubClient
+}
+
+//NewVersionFilesService initializes a VersionFilesService
+func NewVersionFilesService(sql storage.SQLStorage, githubClient clients.GithubClient) *VersionFiles {
+	return &VersionFiles{
+		SQL:          sql,
+		GithubClient: githubClient,
+	}
+}
+
+//Bump rewrites the version of every version file configured and commits them, along with the given files like the changelog,
+//into the pull request head branch.
+//Every file goes into a single commit made through the git data api, so the release has a single sha to be checked
+//and the branch only moves when the whole commit is ready.
+//Returns the sha which must be tagged, that is the build sha when there is nothing to commit.
+//The head branch must still point to the build sha, otherwise the release would include changes not checked.
+func (s *VersionFiles) Bump(ctx context.Context, config *models.Configuration, pullRequest *models.PullRequest, build *models.Build, files ...models.TreeEntry) (string, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "VersionFiles.Bump")
+	defer span.End()
+
+	if len(config.VersionFiles) == 0 && len(files) == 0 {
+		return *build.Sha, nil
+	}
+
+	if pullRequest.HeadRef == nil {
+		return "", apierrors.NewBadRequestApiError("invalid pull request branches")
+	}
+
+	branch, err := s.GithubClient.GetBranchInformation(ctx, config, *pullRequest.HeadRef)
+
+	if err != nil {
+		return "", err
+	}
+
+	if branch.Commit.Sha != *build.Sha {
+		return "", apierrors.NewApiError(fmt.Sprintf("branch %s moved since %s", *pullRequest.HeadRef, *build.Sha), "conflict_error", http.StatusConflict, apierrors.CauseList{})
+	}
+
+	version := fmt.Sprintf("%d.%d.%d", build.Major, build.Minor, build.Patch)
+	if build.Tag != nil {
+		version = version + "-" + *build.Tag
+	}
+
+	entries := make([]models.TreeEntry, 0)
+
+	for _, versionFile := range config.VersionFiles {
+		path := versionFile.GetPath()
+
+		file, err := s.GithubClient.GetFileContent(ctx, config, path, *build.Sha)
+
+		if err != nil {
+			return "", err
+		}
+
+		content, found := BumpVersionFile(versionFile.Type, file.Content, version)
+
+		if !found {
+			return "", apierrors.NewBadRequestApiError(fmt.Sprintf("version not found in %s", path))
+		}
+
+		//The file already has the version
+		if content == file.Content {
+			continue
+		}
+
+		entries = append(entries, models.TreeEntry{
+			Path:    path,
+			Mode:    models.TreeEntryModeFile,
+			Type:    models.TreeEntryTypeBlob,
+			Content: content,
+		})
+	}
+
+	entries = append(entries, files...)
+
+	if len(entries) == 0 {
+		return *build.Sha, nil
+	}
+
+	tree, err := s.GithubClient.CreateTree(ctx, config, &models.TreeRequest{
+		BaseTree: branch.Commit.Commit.Tree.Sha,
+		Tree:     entries,
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	commit := models.CommitRequest{
+		Message: fmt.Sprintf(releaseCommitTemplate, version),
+		Tree:    tree,
+		Parents: []string{*build.Sha},
+	}
+	commit.Committer.Name = ServiceCommitterName
+	commit.Committer.Email = ServiceCommitterEmail
+
+	sha, err := s.GithubClient.CreateCommit(ctx, config, &commit)
+
+	if err != nil {
+		return "", err
+	}
+
+	//Recorded before moving the branch, so its push is never reported as a direct push
+	if err := s.SQL.Insert(ctx, models.NewServiceCommit(*config.ID, *pullRequest.HeadRef, sha)); err != nil {
+		return "", apierrors.NewInternalServerApiError("error saving the release commit", err)
+	}
+
+	//Not forced, so it fails when the branch moved since it was checked
+	if err := s.GithubClient.FastForwardBranch(ctx, config, *pullRequest.HeadRef, sha); err != nil {
+		return "", err
+	}
+
+	return sha, nil
+}
+
+//BumpVersionFile rewrites the version inside the content of a version file.
+//Returns false when the version was not found in the content.
+func BumpVersionFile(fileType string, content string, version string) (string, bool) {
+
+	switch fileType {
+	case models.VersionFileTypePlain:
+		return formatVersion(strings.TrimSpace(content), version) + "\n", true
+	case models.VersionFileTypePackageJSON:
+		return replaceVersion(packageJSONVersionRegexp, content, version)
+	case models.VersionFileTypePom:
+		return replacePomVersion(content, version)
+	case models.VersionFileTypeChart:
+		content, found := replaceVersion(chartVersionRegexp, content, version)
+		if !found {
+			return content, false
+		}
+		//The appVersion is optional
+		content, _ = replaceVersion(chartAppVersionRegexp, content, version)
+		return content, true
+	case models.VersionFileTypeGo:
+		return replaceVersion(goVersionRegexp, content, version)
+	}
+
+	return content, false
+}
+
+//replaceVersion replaces the second group of the first match of the expression with the version.
+func replaceVersion(expression *regexp.Regexp, content string, version string) (string, bool) {
+
+	match := expression.FindStringSubmatchIndex(content)
+
+	if match == nil {
+		return content, false
+	}
+
+	return content[:match[4]] + formatVersion(content[match[4]:match[5]], version) + content[match[5]:], true
+}
+
+//replacePomVersion replaces the version of the project, the parent and the dependencies versions are kept.
+func replacePomVersion(content string, version string) (string, bool) {
+
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	path := make([]string, 0)
+
+	for {
+		token, err := decoder.Token()
+
+		//io.EOF included, the project has no version
+		if err != nil {
+			return content, false
+		}
+
+		switch element := token.(type) {
+		case xml.StartElement:
+			path = append(path, element.Name.Local)
+
+			if strings.Join(path, "/") == "project/version" {
+				start := decoder.InputOffset()
+
+				token, err := decoder.Token()
+
+				if err != nil {
+					return content, false
+				}
+
+				end := start
+				if _, ok := token.(xml.CharData); ok {
+					end = decoder.InputOffset()
+				}
+
+				return content[:start] + formatVersion(content[start:end], version) + content[end:], true
+			}
+		case xml.EndElement:
+			path = path[:len(path)-1]
+		}
+	}
+}
+
+//formatVersion keeps the v prefix of the old version.
+func formatVersion(old string, version string) string {
+	if strings.HasPrefix(old, "v") {
+		return "v" + version
+	}
+	return version
+}
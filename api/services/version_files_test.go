@@ -0,0 +1,419 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hbalmes/ci_cd-api/api/mocks/interfaces"
+	"github.com/hbalmes/ci_cd-api/api/models"
+	"github.com/hbalmes/ci_cd-api/api/utils"
+	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBumpVersionFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileType string
+		content  string
+		want     string
+		found    bool
+	}{
+		{
+			name:     "plain version file",
+			fileType: models.VersionFileTypePlain,
+			content:  "1.2.0\n",
+			want:     "1.3.0\n",
+			found:    true,
+		},
+		{
+			name:     "plain version file with v prefix",
+			fileType: models.VersionFileTypePlain,
+			content:  "v1.2.0",
+			want:     "v1.3.0\n",
+			found:    true,
+		},
+		{
+			name:     "package.json",
+			fileType: models.VersionFileTypePackageJSON,
+			content:  "{\n  \"name\": \"api\",\n  \"version\": \"1.2.0\",\n  \"dependencies\": {\n    \"left-pad\": \"1.0.0\"\n  }\n}\n",
+			want:     "{\n  \"name\": \"api\",\n  \"version\": \"1.3.0\",\n  \"dependencies\": {\n    \"left-pad\": \"1.0.0\"\n  }\n}\n",
+			found:    true,
+		},
+		{
+			name:     "package.json without version",
+			fileType: models.VersionFileTypePackageJSON,
+			content:  "{\n  \"name\": \"api\"\n}\n",
+			want:     "{\n  \"name\": \"api\"\n}\n",
+			found:    false,
+		},
+		{
+			name:     "pom.xml keeps the parent and dependencies versions",
+			fileType: models.VersionFileTypePom,
+			content: `<?xml version="1.0" encoding="UTF-8"?>
+<project>
+  <parent>
+    <artifactId>parent</artifactId>
+    <version>2.0.0</version>
+  </parent>
+  <artifactId>api</artifactId>
+  <version>1.2.0</version>
+  <dependencies>
+    <dependency>
+      <version>3.0.0</version>
+    </dependency>
+  </dependencies>
+</project>
+`,
+			want: `<?xml version="1.0" encoding="UTF-8"?>
+<project>
+  <parent>
+    <artifactId>parent</artifactId>
+    <version>2.0.0</version>
+  </parent>
+  <artifactId>api</artifactId>
+  <version>1.3.0</version>
+  <dependencies>
+    <dependency>
+      <version>3.0.0</version>
+    </dependency>
+  </dependencies>
+</project>
+`,
+			found: true,
+		},
+		{
+			name:     "pom.xml with an empty version",
+			fileType: models.VersionFileTypePom,
+			content:  "<project><version></version></project>",
+			want:     "<project><version>1.3.0</version></project>",
+			found:    true,
+		},
+		{
+			name:     "pom.xml inheriting the parent version",
+			fileType: models.VersionFileTypePom,
+			content:  "<project><parent><version>2.0.0</version></parent></project>",
+			want:     "<project><parent><version>2.0.0</version></parent></project>",
+			found:    false,
+		},
+		{
+			name:     "helm chart",
+			fileType: models.VersionFileTypeChart,
+			content:  "apiVersion: v2\nname: api\nversion: 1.2.0\nappVersion: \"1.2.0\"\n",
+			want:     "apiVersion: v2\nname: api\nversion: 1.3.0\nappVersion: \"1.3.0\"\n",
+			found:    true,
+		},
+		{
+			name:     "helm chart without app version",
+			fileType: models.VersionFileTypeChart,
+			content:  "apiVersion: v2\nname: api\nversion: 1.2.0\n",
+			want:     "apiVersion: v2\nname: api\nversion: 1.3.0\n",
+			found:    true,
+		},
+		{
+			name:     "go constant",
+			fileType: models.VersionFileTypeGo,
+			content:  "package version\n\nconst Version = \"v1.2.0\"\n",
+			want:     "package version\n\nconst Version = \"v1.3.0\"\n",
+			found:    true,
+		},
+		{
+			name:     "go constant in a block",
+			fileType: models.VersionFileTypeGo,
+			content:  "package version\n\nconst (\n\tName           = \"api\"\n\tVersion string = \"1.2.0\"\n)\n",
+			want:     "package version\n\nconst (\n\tName           = \"api\"\n\tVersion string = \"1.3.0\"\n)\n",
+			found:    true,
+		},
+		{
+			name:     "unknown type",
+			fileType: "gradle",
+			content:  "version = '1.2.0'",
+			want:     "version = '1.2.0'",
+			found:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := BumpVersionFile(tt.fileType, tt.content, "1.3.0")
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.found, found)
+		})
+	}
+}
+
+func TestVersionFiles_Bump(t *testing.T) {
+	type expects struct {
+		branchSha   string
+		branchErr   apierrors.ApiError
+		files       map[string]*models.FileContentResponse
+		fileErr     apierrors.ApiError
+		extra       []models.TreeEntry
+		commitTimes int
+		tree        []models.TreeEntry
+		treeErr     apierrors.ApiError
+		commitErr   apierrors.ApiError
+		recordErr   error
+		refErr      apierrors.ApiError
+		sha         string
+		err         apierrors.ApiError
+	}
+
+	config := models.Configuration{
+		ID:              utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:  utils.Stringify("ci-cd_api"),
+		RepositoryOwner: utils.Stringify("hbalmes"),
+		VersionFiles: []models.VersionFile{
+			{Type: models.VersionFileTypePlain},
+			{Type: models.VersionFileTypePackageJSON, Path: "web/package.json"},
+		},
+	}
+
+	configWithoutFiles := config
+	configWithoutFiles.VersionFiles = nil
+
+	pullRequest := models.PullRequest{
+		PullRequestNumber: 30,
+		BaseRef:           utils.Stringify("master"),
+		HeadRef:           utils.Stringify("release/1.3"),
+	}
+
+	build := models.Build{
+		Sha:   utils.Stringify("buildsha"),
+		Major: 1,
+		Minor: 3,
+	}
+
+	staleFiles := map[string]*models.FileContentResponse{
+		"VERSION":          {Sha: "versionsha", Content: "1.2.0\n"},
+		"web/package.json": {Sha: "packagesha", Content: `{"version": "1.2.0"}`},
+	}
+
+	bumpedTree := []models.TreeEntry{
+		{Path: "VERSION", Mode: "100644", Type: "blob", Content: "1.3.0\n"},
+		{Path: "web/package.json", Mode: "100644", Type: "blob", Content: `{"version": "1.3.0"}`},
+	}
+
+	changelog := models.TreeEntry{Path: "CHANGELOG.md", Mode: "100644", Type: "blob", Content: "# Changelog\n\n## [1.3.0] - 2020-06-01\n"}
+
+	tests := []struct {
+		name    string
+		config  *models.Configuration
+		expects expects
+	}{
+		{
+			name:    "without version files",
+			config:  &configWithoutFiles,
+			expects: expects{sha: "buildsha"},
+		},
+		{
+			name:   "error getting the branch",
+			config: &config,
+			expects: expects{
+				branchErr: apierrors.NewNotFoundApiError("branch not found"),
+				err:       apierrors.NewNotFoundApiError("branch not found"),
+			},
+		},
+		{
+			name:   "branch moved since the build",
+			config: &config,
+			expects: expects{
+				branchSha: "newersha",
+				err:       apierrors.NewApiError("branch release/1.3 moved since buildsha", "conflict_error", 409, apierrors.CauseList{}),
+			},
+		},
+		{
+			name:   "version file not found",
+			config: &config,
+			expects: expects{
+				branchSha: "buildsha",
+				fileErr:   apierrors.NewNotFoundApiError("file VERSION not found"),
+				err:       apierrors.NewNotFoundApiError("file VERSION not found"),
+			},
+		},
+		{
+			name:   "version not found in the file",
+			config: &config,
+			expects: expects{
+				branchSha: "buildsha",
+				files: map[string]*models.FileContentResponse{
+					"VERSION":          {Sha: "versionsha", Content: "1.2.0\n"},
+					"web/package.json": {Sha: "packagesha", Content: `{"name": "web"}`},
+				},
+				err: apierrors.NewBadRequestApiError("version not found in web/package.json"),
+			},
+		},
+		{
+			name:   "error creating the tree",
+			config: &config,
+			expects: expects{
+				branchSha:   "buildsha",
+				files:       staleFiles,
+				commitTimes: 1,
+				tree:        bumpedTree,
+				treeErr:     apierrors.NewInternalServerApiError("error creating tree - status: 422", nil),
+				err:         apierrors.NewInternalServerApiError("error creating tree - status: 422", nil),
+			},
+		},
+		{
+			name:   "error creating the commit",
+			config: &config,
+			expects: expects{
+				branchSha:   "buildsha",
+				files:       staleFiles,
+				commitTimes: 1,
+				tree:        bumpedTree,
+				commitErr:   apierrors.NewInternalServerApiError("error creating commit - status: 422", nil),
+				err:         apierrors.NewInternalServerApiError("error creating commit - status: 422", nil),
+			},
+		},
+		{
+			name:   "error saving the release commit",
+			config: &config,
+			expects: expects{
+				branchSha:   "buildsha",
+				files:       staleFiles,
+				commitTimes: 1,
+				tree:        bumpedTree,
+				recordErr:   gorm.ErrInvalidSQL,
+				err:         apierrors.NewInternalServerApiError("error saving the release commit", gorm.ErrInvalidSQL),
+			},
+		},
+		{
+			name:   "branch moved before the commit",
+			config: &config,
+			expects: expects{
+				branchSha:   "buildsha",
+				files:       staleFiles,
+				commitTimes: 1,
+				tree:        bumpedTree,
+				refErr:      apierrors.NewApiError("branch release/1.3 can not be fast-forwarded to commitsha", "conflict_error", 409, apierrors.CauseList{}),
+				err:         apierrors.NewApiError("branch release/1.3 can not be fast-forwarded to commitsha", "conflict_error", 409, apierrors.CauseList{}),
+			},
+		},
+		{
+			name:   "version files already bumped",
+			config: &config,
+			expects: expects{
+				branchSha: "buildsha",
+				files: map[string]*models.FileContentResponse{
+					"VERSION":          {Sha: "versionsha", Content: "1.3.0\n"},
+					"web/package.json": {Sha: "packagesha", Content: `{"version": "1.3.0"}`},
+				},
+				sha: "buildsha",
+			},
+		},
+		{
+			name:   "changelog committed without version files",
+			config: &configWithoutFiles,
+			expects: expects{
+				branchSha:   "buildsha",
+				extra:       []models.TreeEntry{changelog},
+				commitTimes: 1,
+				tree:        []models.TreeEntry{changelog},
+				sha:         "commitsha",
+			},
+		},
+		{
+			name:   "version files and changelog bumped in a single commit",
+			config: &config,
+			expects: expects{
+				branchSha:   "buildsha",
+				files:       staleFiles,
+				extra:       []models.TreeEntry{changelog},
+				commitTimes: 1,
+				tree:        append(append([]models.TreeEntry{}, bumpedTree...), changelog),
+				sha:         "commitsha",
+			},
+		},
+		{
+			name:   "version files bumped in a single commit",
+			config: &config,
+			expects: expects{
+				branchSha:   "buildsha",
+				files:       staleFiles,
+				commitTimes: 1,
+				tree:        bumpedTree,
+				sha:         "commitsha",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+			githubClient := interfaces.NewMockGithubClient(ctrl)
+
+			branch := models.GetBranchResponse{}
+			branch.Commit.Sha = tt.expects.branchSha
+			branch.Commit.Commit.Tree.Sha = "buildtreesha"
+
+			githubClient.EXPECT().
+				GetBranchInformation(gomock.Any(), tt.config, "release/1.3").
+				Return(&branch, tt.expects.branchErr).
+				MaxTimes(1)
+
+			githubClient.EXPECT().
+				GetFileContent(gomock.Any(), tt.config, gomock.Any(), "buildsha").
+				DoAndReturn(func(ctx context.Context, config *models.Configuration, path string, ref string) (*models.FileContentResponse, apierrors.ApiError) {
+					if tt.expects.fileErr != nil {
+						return nil, tt.expects.fileErr
+					}
+					return tt.expects.files[path], nil
+				}).
+				AnyTimes()
+
+			createTree := githubClient.EXPECT().
+				CreateTree(gomock.Any(), tt.config, gomock.Any()).
+				DoAndReturn(func(ctx context.Context, config *models.Configuration, request *models.TreeRequest) (string, apierrors.ApiError) {
+					assert.Equal(t, "buildtreesha", request.BaseTree)
+					assert.Equal(t, tt.expects.tree, request.Tree)
+					if tt.expects.treeErr != nil {
+						return "", tt.expects.treeErr
+					}
+					return "treesha", nil
+				}).
+				Times(tt.expects.commitTimes)
+
+			createCommit := githubClient.EXPECT().
+				CreateCommit(gomock.Any(), tt.config, gomock.Any()).
+				DoAndReturn(func(ctx context.Context, config *models.Configuration, request *models.CommitRequest) (string, apierrors.ApiError) {
+					assert.Equal(t, "Release 1.3.0", request.Message)
+					assert.Equal(t, "treesha", request.Tree)
+					assert.Equal(t, []string{"buildsha"}, request.Parents)
+					assert.Equal(t, ServiceCommitterEmail, request.Committer.Email)
+					if tt.expects.commitErr != nil {
+						return "", tt.expects.commitErr
+					}
+					return "commitsha", nil
+				}).
+				After(createTree).
+				MaxTimes(1)
+
+			//The commit is recorded before moving the branch, so its push is not a direct push
+			record := sqlStorage.EXPECT().
+				Insert(gomock.Any(), models.NewServiceCommit("hbalmes/ci-cd_api", "release/1.3", "commitsha")).
+				Return(tt.expects.recordErr).
+				After(createCommit).
+				MaxTimes(1)
+
+			githubClient.EXPECT().
+				FastForwardBranch(gomock.Any(), tt.config, "release/1.3", "commitsha").
+				Return(tt.expects.refErr).
+				After(record).
+				MaxTimes(1)
+
+			s := &VersionFiles{
+				SQL:          sqlStorage,
+				GithubClient: githubClient,
+			}
+
+			sha, err := s.Bump(context.Background(), tt.config, &pullRequest, &build, tt.expects.extra...)
+			assert.Equal(t, tt.expects.err, err)
+			assert.Equal(t, tt.expects.sha, sha)
+		})
+	}
+}
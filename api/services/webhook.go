@@ -1,14 +1,24 @@
 package services
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"github.com/hbalmes/ci_cd-api/api/clients"
+	"github.com/hbalmes/ci_cd-api/api/configs"
+	"github.com/hbalmes/ci_cd-api/api/metrics"
 	"github.com/hbalmes/ci_cd-api/api/models"
 	"github.com/hbalmes/ci_cd-api/api/models/webhook"
 	"github.com/hbalmes/ci_cd-api/api/services/storage"
 	"github.com/hbalmes/ci_cd-api/api/utils"
 	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"github.com/hbalmes/ci_cd-api/api/utils/logger"
+	"github.com/hbalmes/ci_cd-api/api/utils/tracing"
 	"github.com/jinzhu/gorm"
-	"github.com/rs/zerolog/log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
 const (
@@ -16,44 +26,70 @@ const (
 	pullRequestReviewEditedAction    = "edited"
 	pullRequestReviewDismissedAction = "dismissed"
 	approvedPullRequestReviewState   = "approved"
+	issueCommentCreatedAction        = "created"
+	branchRefPrefix                  = "refs/heads/"
 )
 
 type WebhookService interface {
-	ProcessStatusWebhook(payload *webhook.Status) (*webhook.Webhook, apierrors.ApiError)
-	ProcessPullRequestWebhook(payload *webhook.PullRequestWebhook) (*webhook.Webhook, apierrors.ApiError)
-	ProcessPullRequestReviewWebhook(payload *webhook.PullRequestReviewWebhook) (*webhook.Webhook, apierrors.ApiError)
-	SavePullRequestWebhook(pullRequestWH webhook.PullRequestWebhook) apierrors.ApiError
+	ProcessStatusWebhook(ctx context.Context, payload *webhook.Status) (*webhook.Webhook, apierrors.ApiError)
+	ProcessPullRequestWebhook(ctx context.Context, payload *webhook.PullRequestWebhook) (*webhook.Webhook, apierrors.ApiError)
+	ProcessPullRequestReviewWebhook(ctx context.Context, payload *webhook.PullRequestReviewWebhook) (*webhook.Webhook, apierrors.ApiError)
+	ProcessIssueCommentWebhook(ctx context.Context, payload *webhook.IssueComment) (*webhook.Webhook, apierrors.ApiError)
+	ProcessPushWebhook(ctx context.Context, payload *webhook.Push) (*webhook.Webhook, apierrors.ApiError)
+	ProcessDeploymentStatusWebhook(ctx context.Context, payload *webhook.DeploymentStatus) (*webhook.Webhook, apierrors.ApiError)
+	SavePullRequestWebhook(ctx context.Context, pullRequestWH webhook.PullRequestWebhook) apierrors.ApiError
+	ResumeJobs(ctx context.Context, now time.Time)
 }
 
 //Webhook represents the WebhookService layer
 //It has an instance of a DBClient layer and
 //A github client instance
 type Webhook struct {
-	SQL           storage.SQLStorage
-	GithubClient  clients.GithubClient
-	ConfigService ConfigurationService
-	BuildService  BuildService
+	SQL               storage.SQLStorage
+	GithubClient      clients.GithubClient
+	ConfigService     ConfigurationService
+	BuildService      BuildService
+	ChatOpsService    ChatOpsService
+	AuditService      AuditService
+	AutoMergeService  AutoMergeService
+	MergeQueueService MergeQueueService
+	DeploymentService DeploymentService
+	JobService        JobService
 }
 
 //NewConfigurationSeNewWebhookServicervice initializes a WebhookService
-func NewWebhookService(sql storage.SQLStorage) *Webhook {
+func NewWebhookService(sql storage.SQLStorage, githubClient clients.GithubClient, configService ConfigurationService, buildService BuildService, chatOpsService ChatOpsService, auditService AuditService, autoMergeService AutoMergeService, mergeQueueService MergeQueueService, deploymentService DeploymentService, jobService JobService) *Webhook {
 	return &Webhook{
-		SQL:           sql,
-		GithubClient:  clients.NewGithubClient(),
-		ConfigService: NewConfigurationService(sql),
-		BuildService:  NewBuildService(sql),
+		SQL:               sql,
+		GithubClient:      githubClient,
+		ConfigService:     configService,
+		BuildService:      buildService,
+		ChatOpsService:    chatOpsService,
+		AuditService:      auditService,
+		AutoMergeService:  autoMergeService,
+		MergeQueueService: mergeQueueService,
+		DeploymentService: deploymentService,
+		JobService:        jobService,
 	}
 }
 
 //ProcessStatusWebhook process
-func (s *Webhook) ProcessStatusWebhook(payload *webhook.Status) (*webhook.Webhook, apierrors.ApiError) {
+func (s *Webhook) ProcessStatusWebhook(ctx context.Context, payload *webhook.Status) (*webhook.Webhook, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "Webhook.ProcessStatusWebhook")
+	defer span.End()
+
+	defer metrics.ObserveWebhookProcessing("ProcessStatusWebhook", time.Now())
+
+	if payload.Repository.FullName != nil {
+		ctx = logger.WithRepository(ctx, *payload.Repository.FullName)
+	}
 
 	var wh webhook.Webhook
 
 	webhookType := "status"
 
 	//Validates that the repository has a ci cd configuration
-	conf, err := s.ConfigService.Get(*payload.Repository.FullName)
+	conf, err := s.ConfigService.Get(ctx, *payload.Repository.FullName)
 
 	if err != nil {
 		return nil, apierrors.NewInternalServerApiError("error checking configuration existance", err)
@@ -74,7 +110,7 @@ func (s *Webhook) ProcessStatusWebhook(payload *webhook.Status) (*webhook.Webhoo
 	statusWebhookID := utils.Stringify(utils.GetMD5Hash(shBaseID))
 
 	//Search the status webhook into database
-	if err := s.SQL.GetBy(&wh, "id = ?", *statusWebhookID); err != nil {
+	if err := s.SQL.GetBy(ctx, &wh, "id = ?", *statusWebhookID); err != nil {
 
 		//If the error is not a not found error, then there is a problem
 		if err != gorm.ErrRecordNotFound {
@@ -94,17 +130,21 @@ func (s *Webhook) ProcessStatusWebhook(payload *webhook.Status) (*webhook.Webhoo
 		wh.Sha = payload.Sha
 		wh.Description = payload.Description
 
+		//The webhook is not processed again once saved, so the processing is persisted before saving it to be resumed if interrupted
+		job, jobErr := s.JobService.Start(ctx, models.JobTypeStatusWebhook, payload)
+
+		if jobErr != nil {
+			return nil, jobErr
+		}
+
 		//Save it into database
-		if err := s.SQL.Insert(&wh); err != nil {
+		if err := s.SQL.Insert(ctx, &wh); err != nil {
+			s.finishJob(ctx, job)
 			return nil, apierrors.NewInternalServerApiError("error saving new status webhook", err)
 		}
 
-		//TODO: Logear el build generado o insertarlo en el apartado build
-		build, _ := s.BuildService.ProcessBuild(conf, payload)
-
-		if build != nil {
-			//TODO: Logear
-		}
+		s.processStatus(ctx, conf, payload)
+		s.finishJob(ctx, job)
 
 	} else { //If webhook already exists then return it
 		return nil, apierrors.NewConflictApiError("Resource Already exists")
@@ -113,15 +153,118 @@ func (s *Webhook) ProcessStatusWebhook(payload *webhook.Status) (*webhook.Webhoo
 	return &wh, nil
 }
 
+//processStatus creates the build, auto merges the pull request and moves the merge queue of a saved status webhook
+func (s *Webhook) processStatus(ctx context.Context, conf *models.Configuration, payload *webhook.Status) {
+	defer metrics.TrackJob(models.JobTypeStatusWebhook)()
+
+	//The build could replace the reported state, the merge queue must see the state of the integration branch
+	reported := *payload
+
+	//TODO: Logear el build generado o insertarlo en el apartado build
+	build, _ := s.BuildService.ProcessBuild(ctx, conf, payload)
+
+	if build != nil {
+		//TODO: Logear
+	}
+
+	s.AutoMerge(ctx, conf, payload)
+
+	//The sha could be the head of an integration branch
+	if mqErr := s.MergeQueueService.ProcessStatus(ctx, conf, &reported); mqErr != nil {
+		logger.FromContext(ctx).Error().Err(mqErr).Str("sha", *payload.Sha).Msg("error processing merge queue status")
+	}
+}
+
+//processReview creates the build and auto merges the pull request of an approved or dismissed review
+func (s *Webhook) processReview(ctx context.Context, conf *models.Configuration, payload *webhook.Status) {
+	defer metrics.TrackJob(models.JobTypeReviewWebhook)()
+
+	build, buildErr := s.BuildService.ProcessBuild(ctx, conf, payload)
+
+	if buildErr != nil {
+		logger.FromContext(ctx).Error().Err(buildErr).Str("sha", *payload.Sha).Msg("error processing pull request review build")
+	}
+
+	if build != nil {
+		logger.FromContext(ctx).Info().Str("sha", *build.Sha).Str("type", *build.Type).Str("build", *build.GithubURL).Msg("build created successfully")
+	}
+
+	s.AutoMerge(ctx, conf, payload)
+}
+
+//finishJob deletes a processed job, logging the error as the processing was already done
+func (s *Webhook) finishJob(ctx context.Context, job *models.Job) {
+	if err := s.JobService.Finish(ctx, job); err != nil {
+		logger.FromContext(ctx).Error().Err(err).Uint32("job_id", job.ID).Str("type", *job.Type).Msg("error finishing job")
+	}
+}
+
+//ResumeJobs processes again the jobs interrupted before finishing, like the ones of an instance killed during a deploy
+func (s *Webhook) ResumeJobs(ctx context.Context, now time.Time) {
+	ctx, span := tracing.Start(ctx, "Webhook.ResumeJobs")
+	defer span.End()
+
+	jobs, err := s.JobService.ClaimInterrupted(ctx, now)
+
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("error claiming interrupted jobs")
+		return
+	}
+
+	for i := range jobs {
+		job := &jobs[i]
+		jobCtx := logger.With(ctx, "job_id", strconv.FormatUint(uint64(job.ID), 10))
+
+		switch *job.Type {
+		case models.JobTypeStatusWebhook, models.JobTypeReviewWebhook:
+			//Both jobs are persisted with the status payload to build
+			var payload webhook.Status
+			if err := json.Unmarshal([]byte(*job.Payload), &payload); err != nil || payload.Repository.FullName == nil {
+				logger.FromContext(jobCtx).Error().Err(err).Str("type", *job.Type).Msg("invalid webhook job payload")
+				continue
+			}
+
+			jobCtx = logger.WithRepository(jobCtx, *payload.Repository.FullName)
+
+			conf, err := s.ConfigService.Get(jobCtx, *payload.Repository.FullName)
+			if err != nil || conf == nil {
+				logger.FromContext(jobCtx).Error().Err(err).Str("type", *job.Type).Msg("error getting configuration to resume webhook job")
+				continue
+			}
+
+			logger.FromContext(jobCtx).Info().Str("type", *job.Type).Str("sha", *payload.Sha).Int("attempt", job.Attempts).Msg("resuming webhook job")
+
+			if *job.Type == models.JobTypeStatusWebhook {
+				s.processStatus(jobCtx, conf, &payload)
+			} else {
+				s.processReview(jobCtx, conf, &payload)
+			}
+		default:
+			logger.FromContext(jobCtx).Error().Str("type", *job.Type).Msg("unknown job type")
+			continue
+		}
+
+		s.finishJob(jobCtx, job)
+	}
+}
+
 //ProcessPullRequestWebhook process
-func (s *Webhook) ProcessPullRequestWebhook(payload *webhook.PullRequestWebhook) (*webhook.Webhook, apierrors.ApiError) {
+func (s *Webhook) ProcessPullRequestWebhook(ctx context.Context, payload *webhook.PullRequestWebhook) (*webhook.Webhook, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "Webhook.ProcessPullRequestWebhook")
+	defer span.End()
+
+	defer metrics.ObserveWebhookProcessing("ProcessPullRequestWebhook", time.Now())
+
+	if payload.Repository.FullName != nil {
+		ctx = logger.WithRepository(ctx, *payload.Repository.FullName)
+	}
 
 	var prWH models.PullRequest
 	var wh webhook.Webhook
 	var cf Configuration
 
 	//Validates that the repository has a ci cd configuration
-	config, err := s.ConfigService.Get(*payload.Repository.FullName)
+	config, err := s.ConfigService.Get(ctx, *payload.Repository.FullName)
 
 	if err != nil {
 		return nil, apierrors.NewInternalServerApiError("error checking configuration existance", err)
@@ -138,7 +281,7 @@ func (s *Webhook) ProcessPullRequestWebhook(payload *webhook.PullRequestWebhook)
 	webhookType := "pull_request"
 
 	//Search the pull request webhook in database
-	if err := s.SQL.GetBy(&prWH, "id = ?", &payload.PullRequest.ID); err != nil {
+	if err := s.SQL.GetBy(ctx, &prWH, "id = ?", &payload.PullRequest.ID); err != nil {
 
 		//If the error is not a not found error, then there is a problem
 		if err != gorm.ErrRecordNotFound {
@@ -146,7 +289,7 @@ func (s *Webhook) ProcessPullRequestWebhook(payload *webhook.PullRequestWebhook)
 		}
 
 		//Save the Pull request
-		saveErr := s.SavePullRequestWebhook(*payload)
+		saveErr := s.SavePullRequestWebhook(ctx, *payload)
 
 		if saveErr != nil {
 			return nil, saveErr
@@ -170,7 +313,7 @@ func (s *Webhook) ProcessPullRequestWebhook(payload *webhook.PullRequestWebhook)
 		wh.GithubPullRequestNumber = &payload.PullRequest.Number
 
 		//Save it into database
-		if err := s.SQL.Insert(&wh); err != nil {
+		if err := s.SQL.Insert(ctx, &wh); err != nil {
 			return nil, apierrors.NewInternalServerApiError("error saving new pull request webhook", err)
 		}
 
@@ -179,7 +322,7 @@ func (s *Webhook) ProcessPullRequestWebhook(payload *webhook.PullRequestWebhook)
 
 			statusWH := cf.CheckWorkflow(config, payload)
 
-			notifyStatusErr := s.GithubClient.CreateStatus(config, statusWH)
+			notifyStatusErr := s.GithubClient.CreateStatus(ctx, config, statusWH)
 
 			if notifyStatusErr != nil {
 				return nil, apierrors.NewInternalServerApiError(notifyStatusErr.Message(), notifyStatusErr)
@@ -196,7 +339,7 @@ func (s *Webhook) ProcessPullRequestWebhook(payload *webhook.PullRequestWebhook)
 		case "synchronize":
 
 			//Update the Pull request
-			updateErr := s.UpdatePullRequestWebhook(*payload)
+			updateErr := s.UpdatePullRequestWebhook(ctx, *payload)
 
 			if updateErr != nil {
 				return nil, apierrors.NewInternalServerApiError(updateErr.Error(), updateErr)
@@ -204,7 +347,7 @@ func (s *Webhook) ProcessPullRequestWebhook(payload *webhook.PullRequestWebhook)
 
 			statusWH := cf.CheckWorkflow(config, payload)
 
-			notifyStatusErr := s.GithubClient.CreateStatus(config, statusWH)
+			notifyStatusErr := s.GithubClient.CreateStatus(ctx, config, statusWH)
 
 			if notifyStatusErr != nil {
 				return nil, apierrors.NewInternalServerApiError(notifyStatusErr.Message(), notifyStatusErr)
@@ -212,12 +355,63 @@ func (s *Webhook) ProcessPullRequestWebhook(payload *webhook.PullRequestWebhook)
 		case "closed", "reopened":
 
 			//Update the Pull request
-			updateErr := s.UpdatePullRequestWebhook(*payload)
+			updateErr := s.UpdatePullRequestWebhook(ctx, *payload)
 
 			if updateErr != nil {
 				return nil, apierrors.NewInternalServerApiError(updateErr.Error(), updateErr)
 			}
 
+			if *payload.Action == "closed" {
+				pr := models.PullRequest{
+					ID:                payload.PullRequest.ID,
+					PullRequestNumber: payload.PullRequest.Number,
+					RepositoryName:    payload.Repository.FullName,
+					BaseRef:           payload.PullRequest.Base.Ref,
+					HeadRef:           payload.PullRequest.Head.Ref,
+					HeadSha:           payload.PullRequest.Head.Sha,
+				}
+
+				//Closed pull requests leave the merge queue, so the next one starts testing
+				if dequeueErr := s.MergeQueueService.Dequeue(ctx, config, &pr); dequeueErr != nil && dequeueErr.Status() != http.StatusNotFound {
+					logger.FromContext(ctx).Error().Err(dequeueErr).Int("pull_request", pr.PullRequestNumber).Msg("error dequeuing closed pull request")
+				}
+
+				if payload.PullRequest.Merged {
+					//Gitflow releases and hotfixes merged into master must be merged back into develop
+					if NeedsBackMerge(config, &pr) {
+						s.BuildService.BackMerge(ctx, config, &pr)
+					}
+
+					//Merged head branches are not needed anymore
+					s.DeleteMergedBranch(ctx, config, payload)
+				}
+			}
+
+		case "labeled", "unlabeled":
+
+			//Update the Pull request auto-merge flag
+			updateErr := s.UpdatePullRequestWebhook(ctx, *payload)
+
+			if updateErr != nil {
+				return nil, apierrors.NewInternalServerApiError(updateErr.Error(), updateErr)
+			}
+
+			//The quality gates could have passed before the label was added
+			if HasAutoMergeLabel(*payload) {
+				var statusWH webhook.Status
+				statusWH.Sha = payload.PullRequest.Head.Sha
+				statusWH.State = utils.Stringify(statusWebhookSuccessState)
+				statusWH.Repository.FullName = payload.Repository.FullName
+				statusWH.Sender.Login = payload.Sender.Login
+
+				s.AutoMerge(ctx, config, &statusWH)
+			}
+
+			//The 'merge-queue' label manages the merge queue
+			if payload.Label.Name != nil && *payload.Label.Name == mergeQueueLabel {
+				s.ManageMergeQueue(ctx, config, payload, &prWH)
+			}
+
 		default:
 			return nil, apierrors.NewConflictApiError("Resource Already exists")
 		}
@@ -227,28 +421,33 @@ func (s *Webhook) ProcessPullRequestWebhook(payload *webhook.PullRequestWebhook)
 }
 
 //ProcessPullRequestWebhook process
-func (s *Webhook) ProcessPullRequestReviewWebhook(payload *webhook.PullRequestReviewWebhook) (*webhook.Webhook, apierrors.ApiError) {
-	log.Info().Str("action", *payload.Action).Str("state", *payload.Review.State).
-		Str("repository", *payload.Repository.FullName).Msg("processing pull request review webhook")
+func (s *Webhook) ProcessPullRequestReviewWebhook(ctx context.Context, payload *webhook.PullRequestReviewWebhook) (*webhook.Webhook, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "Webhook.ProcessPullRequestReviewWebhook")
+	defer span.End()
+
+	defer metrics.ObserveWebhookProcessing("ProcessPullRequestReviewWebhook", time.Now())
+
+	if payload.Repository.FullName != nil {
+		ctx = logger.WithRepository(ctx, *payload.Repository.FullName)
+	}
+	logger.FromContext(ctx).Info().Str("action", *payload.Action).Str("state", *payload.Review.State).Msg("processing pull request review webhook")
 
 	var wh webhook.Webhook
 
 	//Validates that the repository has a ci cd configuration
-	config, err := s.ConfigService.Get(*payload.Repository.FullName)
+	config, err := s.ConfigService.Get(ctx, *payload.Repository.FullName)
 
 	if err != nil {
-		log.Error().Err(err).Str("action", *payload.Action).Str("state", *payload.Review.State).
-			Str("repository", *payload.Repository.FullName).Msg("error checking status webhook existence")
+		logger.FromContext(ctx).Error().Err(err).Str("action", *payload.Action).Str("state", *payload.Review.State).Msg("error checking status webhook existence")
 		return nil, apierrors.NewInternalServerApiError("error checking configuration existance", err)
 	}
 
 	if config == nil {
-		log.Error().Err(err).Str("action", *payload.Action).Str("state", *payload.Review.State).
-			Str("repository", *payload.Repository.FullName).Msg("configuration not found for the repository")
+		logger.FromContext(ctx).Error().Err(err).Str("action", *payload.Action).Str("state", *payload.Review.State).Msg("configuration not found for the repository")
 		return nil, apierrors.NewNotFoundApiError("configuration not found for the repository")
 	}
 
-	log.Info().Msgf("config getted successfully for %s", *payload.Repository.FullName)
+	logger.FromContext(ctx).Info().Msgf("config getted successfully for %s", *payload.Repository.FullName)
 
 	webhookType := utils.Stringify("pull_request_review")
 
@@ -256,18 +455,22 @@ func (s *Webhook) ProcessPullRequestReviewWebhook(payload *webhook.PullRequestRe
 	prWHBaseID := *payload.Repository.FullName + *payload.PullRequest.Head.Sha + *webhookType + *payload.Review.State
 	prWebhookID := utils.Stringify(utils.GetMD5Hash(prWHBaseID))
 
+	//We create the payload necessary to process the build
+	buildPayload := s.BuildStatusWebhookPayload(*payload)
+
+	var job *models.Job
+
 	switch *payload.Action {
 	case pullRequestReviewSubmittedAction:
 		//If the revision was approved. We must keep in the database
 		if *payload.Review.State == approvedPullRequestReviewState {
 
 			//Search the status webhook into database
-			if err := s.SQL.GetBy(&wh, "id = ?", &prWebhookID); err != nil {
+			if err := s.SQL.GetBy(ctx, &wh, "id = ?", &prWebhookID); err != nil {
 
 				//If the error is not a not found error, then there is a problem
 				if err != gorm.ErrRecordNotFound {
-					log.Error().Err(err).Str("action", *payload.Action).Str("state", *payload.Review.State).
-						Str("repository", *payload.Repository.FullName).Msg("error checking status webhook existence")
+					logger.FromContext(ctx).Error().Err(err).Str("action", *payload.Action).Str("state", *payload.Review.State).Msg("error checking status webhook existence")
 					return nil, apierrors.NewNotFoundApiError("error checking status webhook existence")
 				}
 
@@ -283,10 +486,16 @@ func (s *Webhook) ProcessPullRequestReviewWebhook(payload *webhook.PullRequestRe
 				wh.WebhookCreateAt = payload.PullRequest.CreatedAt
 				wh.WebhookUpdated = payload.PullRequest.UpdatedAt
 
+				//The webhook is not processed again once saved, so the processing is persisted before saving it to be resumed if interrupted
+				var jobErr apierrors.ApiError
+				if job, jobErr = s.JobService.Start(ctx, models.JobTypeReviewWebhook, buildPayload); jobErr != nil {
+					return nil, jobErr
+				}
+
 				//Save it into database
-				if err := s.SQL.Insert(&wh); err != nil {
-					log.Error().Err(err).Str("action", *payload.Action).Str("state", *payload.Review.State).
-						Str("repository", *payload.Repository.FullName).Msg("error saving new pull request review webhook")
+				if err := s.SQL.Insert(ctx, &wh); err != nil {
+					s.finishJob(ctx, job)
+					logger.FromContext(ctx).Error().Err(err).Str("action", *payload.Action).Str("state", *payload.Review.State).Msg("error saving new pull request review webhook")
 					return nil, apierrors.NewInternalServerApiError("error saving new pull request review webhook", err)
 				}
 			} else { //If webhook already exists then return it
@@ -294,57 +503,392 @@ func (s *Webhook) ProcessPullRequestReviewWebhook(payload *webhook.PullRequestRe
 				return &wh, nil
 			}
 		} else {
-			log.Info().Str("action", *payload.Action).Str("state", *payload.Review.State).
-				Str("repository", *payload.Repository.FullName).Msg("pull request review state not supported yet")
+			logger.FromContext(ctx).Info().Str("action", *payload.Action).Str("state", *payload.Review.State).Msg("pull request review state not supported yet")
 			return nil, apierrors.NewBadRequestApiError("pull request review state not supported yet")
 		}
 
 	case pullRequestReviewDismissedAction:
 		//Search the status webhook into database
-		if err := s.SQL.GetBy(&wh, "id = ?", &prWebhookID); err != nil {
+		if err := s.SQL.GetBy(ctx, &wh, "id = ?", &prWebhookID); err != nil {
 			//If the error is not a not found error, then there is a problem
 			if err == gorm.ErrRecordNotFound {
-				log.Error().Err(err).Str("action", *payload.Action).Str("state", *payload.Review.State).
-					Str("repository", *payload.Repository.FullName).Msg("webhook not found")
+				logger.FromContext(ctx).Error().Err(err).Str("action", *payload.Action).Str("state", *payload.Review.State).Msg("webhook not found")
 			} else {
-				log.Error().Err(err).Str("action", *payload.Action).Str("state", *payload.Review.State).
-					Str("repository", *payload.Repository.FullName).
+				logger.FromContext(ctx).Error().Err(err).Str("action", *payload.Action).Str("state", *payload.Review.State).
 					Msg("error checking status webhook existence")
 				return nil, apierrors.NewNotFoundApiError("error checking status webhook existence")
 			}
 
 		} else {
 			//Delete the value from DB
-			if err := s.SQL.Delete(&wh); err != nil {
-				log.Error().Err(err).Str("action", *payload.Action).Str("state", *payload.Review.State).
-					Str("repository", *payload.Repository.FullName).
+			if err := s.SQL.Delete(ctx, &wh); err != nil {
+				logger.FromContext(ctx).Error().Err(err).Str("action", *payload.Action).Str("state", *payload.Review.State).
 					Msg("error deleting pull request review webhook")
 				return nil, apierrors.NewInternalServerApiError("error deleting pull request review webhook", err)
 			}
 		}
 	default:
-		log.Info().Msgf("pull request review webhook - action: %s", *payload.Action)
+		logger.FromContext(ctx).Info().Msgf("pull request review webhook - action: %s", *payload.Action)
 		return nil, apierrors.NewBadRequestApiError("action not supported yet")
 	}
 
-	//We create the payload necessary to process the build
-	buildPayload := s.BuildStatusWebhookPayload(*payload)
-	build, buildErr := s.BuildService.ProcessBuild(config, buildPayload)
+	//The dismissed reviews are not saved, so their processing is persisted here to be resumed if interrupted
+	if job == nil {
+		var jobErr apierrors.ApiError
+		if job, jobErr = s.JobService.Start(ctx, models.JobTypeReviewWebhook, buildPayload); jobErr != nil {
+			return nil, jobErr
+		}
+	}
 
-	if buildErr != nil {
-		log.Error().Err(err).Str("action", *payload.Action).Str("state", *payload.Review.State).
-			Str("repository", *payload.Repository.FullName).Msg("error checking status webhook existence")
+	s.processReview(ctx, config, buildPayload)
+	s.finishJob(ctx, job)
+
+	return &wh, nil
+}
+
+//ProcessIssueCommentWebhook process the ChatOps commands written in pull request comments
+func (s *Webhook) ProcessIssueCommentWebhook(ctx context.Context, payload *webhook.IssueComment) (*webhook.Webhook, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "Webhook.ProcessIssueCommentWebhook")
+	defer span.End()
+
+	defer metrics.ObserveWebhookProcessing("ProcessIssueCommentWebhook", time.Now())
+
+	if payload.Repository.FullName != nil {
+		ctx = logger.WithRepository(ctx, *payload.Repository.FullName)
 	}
 
-	if build != nil {
-		log.Info().Str("sha", *build.Sha).Str("type", *build.Type).Str("build", *build.GithubURL).
-			Str("repository", *payload.Repository.FullName).Msg("build created successfully")
+	var wh webhook.Webhook
+	var pr models.PullRequest
+
+	if payload.Action == nil || payload.Comment == nil || payload.Comment.Body == nil {
+		return nil, apierrors.NewBadRequestApiError("invalid issue comment webhook payload")
+	}
+
+	//Only new comments made on pull requests can carry commands, github sends every other comment too
+	if *payload.Action != issueCommentCreatedAction || payload.Issue.PullRequest == nil {
+		return nil, apierrors.NewApiError("Only new pull request comments carry ChatOps commands.", "skipped", http.StatusPartialContent, apierrors.CauseList{})
+	}
+
+	if _, _, ok := ParseChatOpsCommand(*payload.Comment.Body); !ok {
+		return nil, apierrors.NewApiError("The comment has no ChatOps command.", "skipped", http.StatusPartialContent, apierrors.CauseList{})
+	}
+
+	logger.FromContext(ctx).Info().Str("action", *payload.Action).Int("pull_request", payload.Issue.Number).Msg("processing issue comment webhook")
+
+	//Validates that the repository has a ci cd configuration
+	config, err := s.ConfigService.Get(ctx, *payload.Repository.FullName)
+
+	if err != nil {
+		return nil, apierrors.NewInternalServerApiError("error checking configuration existance", err)
+	}
+
+	if config == nil {
+		return nil, apierrors.NewNotFoundApiError("configuration not found for the repository")
+	}
+
+	webhookType := "issue_comment"
+
+	//Build a ID to identify a unique webhook
+	whBaseID := *payload.Repository.FullName + webhookType + strconv.FormatInt(payload.Comment.ID, 10) + *payload.Action
+	icWebhookID := utils.Stringify(utils.GetMD5Hash(whBaseID))
+
+	//Search the issue comment webhook into database
+	if err := s.SQL.GetBy(ctx, &wh, "id = ?", *icWebhookID); err == nil {
+		return nil, apierrors.NewConflictApiError("Resource Already exists")
+	} else if err != gorm.ErrRecordNotFound {
+		return nil, apierrors.NewInternalServerApiError("error checking issue comment webhook existence", err)
+	}
+
+	//Search the pull request where the comment was made
+	if err := s.SQL.GetBy(ctx, &pr, "repository_name = ? AND pull_request_number = ?", *payload.Repository.FullName, payload.Issue.Number); err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return nil, apierrors.NewInternalServerApiError("error getting pull request", err)
+		}
+		return nil, apierrors.NewNotFoundApiError("pull request not found")
+	}
+
+	//Fill every field in the webhook
+	wh.ID = icWebhookID
+	wh.Type = utils.Stringify(webhookType)
+	wh.GithubRepositoryName = payload.Repository.FullName
+	wh.GithubPullRequestNumber = &payload.Issue.Number
+	wh.SenderName = payload.Sender.Login
+	wh.State = payload.Action
+	wh.Sha = pr.HeadSha
+	wh.Description = payload.Comment.Body
+
+	//Save it into database
+	if err := s.SQL.Insert(ctx, &wh); err != nil {
+		return nil, apierrors.NewInternalServerApiError("error saving new issue comment webhook", err)
+	}
+
+	reply, cmdErr := s.ChatOpsService.ProcessCommand(ctx, config, &pr, *payload.Sender.Login, *payload.Comment.Body)
+
+	if cmdErr != nil {
+		logger.FromContext(ctx).Error().Err(cmdErr).Int("pull_request", payload.Issue.Number).Msg("error executing ChatOps command")
+		return nil, cmdErr
+	}
+
+	logger.FromContext(ctx).Info().Str("reply", reply).Int("pull_request", payload.Issue.Number).Msg("ChatOps command executed")
+
+	return &wh, nil
+}
+
+//ProcessPushWebhook process
+//It keeps the head of every branch and flags the pushes to stable branches that did not come through a merged pull request
+func (s *Webhook) ProcessPushWebhook(ctx context.Context, payload *webhook.Push) (*webhook.Webhook, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "Webhook.ProcessPushWebhook")
+	defer span.End()
+
+	defer metrics.ObserveWebhookProcessing("ProcessPushWebhook", time.Now())
+
+	if payload.Repository.FullName != nil {
+		ctx = logger.WithRepository(ctx, *payload.Repository.FullName)
+	}
+
+	var wh webhook.Webhook
+
+	if payload.Ref == nil || payload.After == nil || payload.Repository.FullName == nil {
+		return nil, apierrors.NewBadRequestApiError("invalid push webhook payload")
+	}
+
+	//Tags pushes are not tracked
+	if !strings.HasPrefix(*payload.Ref, branchRefPrefix) {
+		return nil, apierrors.NewBadRequestApiError("only branch pushes are supported")
+	}
+
+	branch := strings.TrimPrefix(*payload.Ref, branchRefPrefix)
+
+	//Validates that the repository has a ci cd configuration
+	config, err := s.ConfigService.Get(ctx, *payload.Repository.FullName)
+
+	if err != nil {
+		return nil, apierrors.NewInternalServerApiError("error checking configuration existance", err)
+	}
+
+	if config == nil {
+		return nil, apierrors.NewNotFoundApiError("configuration not found for the repository")
+	}
+
+	webhookType := "push"
+
+	//Build a ID to identify a unique webhook
+	whBaseID := *payload.Repository.FullName + webhookType + *payload.Ref + *payload.After
+	pushWebhookID := utils.Stringify(utils.GetMD5Hash(whBaseID))
+
+	//Search the push webhook into database
+	if err := s.SQL.GetBy(ctx, &wh, "id = ?", *pushWebhookID); err == nil {
+		return nil, apierrors.NewConflictApiError("Resource Already exists")
+	} else if err != gorm.ErrRecordNotFound {
+		return nil, apierrors.NewInternalServerApiError("error checking push webhook existence", err)
+	}
+
+	//Fill every field in the webhook
+	wh.ID = pushWebhookID
+	wh.Type = utils.Stringify(webhookType)
+	wh.GithubRepositoryName = payload.Repository.FullName
+	wh.SenderName = payload.Pusher.Name
+	wh.Sha = payload.After
+	wh.Context = utils.Stringify(branch)
+	if payload.HeadCommit != nil {
+		wh.Description = payload.HeadCommit.Message
+	}
+
+	//Save it into database
+	if err := s.SQL.Insert(ctx, &wh); err != nil {
+		return nil, apierrors.NewInternalServerApiError("error saving new push webhook", err)
+	}
+
+	if err := s.UpdateBranchHead(ctx, payload, branch); err != nil {
+		return nil, err
+	}
+
+	//Branch creations and deletions are not direct pushes
+	if payload.Created || payload.Deleted {
+		return &wh, nil
+	}
+
+	wfc := configs.GetWorkflowConfiguration(config)
+
+	//Only the stable branches named exactly are audited, the prefix ones like release/ are pushed as part of the workflow
+	//Commits pushed by this API, like the release commits, are not direct pushes
+	if wfc.GetProtectedBranch(branch) != nil && !s.isServicePush(ctx, payload, branch) && s.IsDirectPush(ctx, config, branch, *payload.After) {
+		s.ReportDirectPush(ctx, config, payload, branch)
+	}
+
+	return &wh, nil
+}
+
+//isServicePush checks if the pushed sha is a commit this API pushed to the branch, like a release commit.
+//The push is checked as any other when the commits of the API could not be read.
+func (s *Webhook) isServicePush(ctx context.Context, payload *webhook.Push, branch string) bool {
+	var commits []models.ServiceCommit
+
+	if err := s.SQL.GetBy(ctx, &commits, "repository_name = ? AND branch = ? AND sha = ?", *payload.Repository.FullName, branch, *payload.After); err != nil {
+		logger.FromContext(ctx).Error().Err(err).Str("sha", *payload.After).Str("branch", branch).Msg("error getting the commits pushed by the api")
+		return false
+	}
+
+	return len(commits) > 0
+}
+
+//ProcessDeploymentStatusWebhook process
+//It keeps the deployments created by this API in sync with the statuses reported by the deployers
+func (s *Webhook) ProcessDeploymentStatusWebhook(ctx context.Context, payload *webhook.DeploymentStatus) (*webhook.Webhook, apierrors.ApiError) {
+	ctx, span := tracing.Start(ctx, "Webhook.ProcessDeploymentStatusWebhook")
+	defer span.End()
+
+	defer metrics.ObserveWebhookProcessing("ProcessDeploymentStatusWebhook", time.Now())
+
+	if payload.Repository.FullName != nil {
+		ctx = logger.WithRepository(ctx, *payload.Repository.FullName)
+	}
+
+	var wh webhook.Webhook
+
+	if payload.Repository.FullName == nil || payload.DeploymentStatus.State == nil || payload.Deployment.ID == 0 {
+		return nil, apierrors.NewBadRequestApiError("invalid deployment_status webhook payload")
+	}
+
+	//Validates that the repository has a ci cd configuration
+	config, err := s.ConfigService.Get(ctx, *payload.Repository.FullName)
+
+	if err != nil {
+		return nil, apierrors.NewInternalServerApiError("error checking configuration existance", err)
+	}
+
+	if config == nil {
+		return nil, apierrors.NewNotFoundApiError("configuration not found for the repository")
+	}
+
+	webhookType := "deployment_status"
+
+	//Build a ID to identify a unique webhook
+	whBaseID := *payload.Repository.FullName + webhookType + strconv.FormatInt(payload.DeploymentStatus.ID, 10)
+	deploymentStatusWebhookID := utils.Stringify(utils.GetMD5Hash(whBaseID))
+
+	//Search the deployment status webhook into database
+	if err := s.SQL.GetBy(ctx, &wh, "id = ?", *deploymentStatusWebhookID); err == nil {
+		return nil, apierrors.NewConflictApiError("Resource Already exists")
+	} else if err != gorm.ErrRecordNotFound {
+		return nil, apierrors.NewInternalServerApiError("error checking deployment status webhook existence", err)
+	}
+
+	//Fill every field in the webhook
+	wh.ID = deploymentStatusWebhookID
+	wh.Type = utils.Stringify(webhookType)
+	wh.GithubRepositoryName = payload.Repository.FullName
+	wh.SenderName = payload.Sender.Login
+	wh.Sha = payload.Deployment.Sha
+	wh.Context = payload.Deployment.Environment
+	wh.State = payload.DeploymentStatus.State
+	wh.Description = payload.DeploymentStatus.Description
+
+	//Save it into database
+	if err := s.SQL.Insert(ctx, &wh); err != nil {
+		return nil, apierrors.NewInternalServerApiError("error saving new deployment status webhook", err)
+	}
+
+	//Deployments not created by this API are only recorded
+	if _, err := s.DeploymentService.ProcessStatus(ctx, payload); err != nil {
+		logger.FromContext(ctx).Info().Err(err).Int64("deployment", payload.Deployment.ID).Msg("deployment status not processed")
 	}
 
 	return &wh, nil
 }
 
-func (s *Webhook) SavePullRequestWebhook(pullRequestWH webhook.PullRequestWebhook) apierrors.ApiError {
+//UpdateBranchHead keeps the latest sha pushed to the branch.
+//When the branch is deleted its head is deleted too.
+func (s *Webhook) UpdateBranchHead(ctx context.Context, payload *webhook.Push, branch string) apierrors.ApiError {
+	ctx, span := tracing.Start(ctx, "Webhook.UpdateBranchHead")
+	defer span.End()
+
+	var head models.BranchHead
+
+	head.ID = utils.Stringify(utils.GetMD5Hash(*payload.Repository.FullName + branch))
+	head.RepositoryName = payload.Repository.FullName
+	head.Branch = utils.Stringify(branch)
+	head.Sha = payload.After
+	head.PushedBy = payload.Pusher.Name
+
+	if payload.Deleted {
+		if err := s.SQL.Delete(ctx, &head); err != nil {
+			return apierrors.NewInternalServerApiError("error deleting branch head", err)
+		}
+		return nil
+	}
+
+	if err := s.SQL.Update(ctx, &head); err != nil {
+		return apierrors.NewInternalServerApiError("error updating branch head", err)
+	}
+
+	return nil
+}
+
+//IsDirectPush checks if the sha pushed to the branch did not come through a pull request merged into it.
+func (s *Webhook) IsDirectPush(ctx context.Context, config *models.Configuration, branch string, sha string) bool {
+	ctx, span := tracing.Start(ctx, "Webhook.IsDirectPush")
+	defer span.End()
+
+	pullRequests, err := s.GithubClient.GetPullRequestsByCommit(ctx, config, sha)
+
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Str("sha", sha).Str("branch", branch).Msg("error getting commit pull requests")
+		return false
+	}
+
+	for _, pr := range pullRequests {
+		if pr.MergedAt != nil && pr.Base.Ref == branch {
+			return false
+		}
+	}
+
+	return true
+}
+
+//ReportDirectPush records the direct push as an audit event
+//and notifies it with a commit comment when the configuration asks for it.
+func (s *Webhook) ReportDirectPush(ctx context.Context, config *models.Configuration, payload *webhook.Push, branch string) {
+	ctx, span := tracing.Start(ctx, "Webhook.ReportDirectPush")
+	defer span.End()
+
+	pusher := "unknown"
+	if payload.Pusher.Name != nil {
+		pusher = *payload.Pusher.Name
+	}
+
+	description := fmt.Sprintf("%s pushed %s directly to the stable branch %s", pusher, *payload.After, branch)
+
+	logger.FromContext(ctx).Warn().Str("sha", *payload.After).Str("branch", branch).Msg("direct push to a stable branch")
+
+	event := models.AuditEvent{
+		RepositoryName: payload.Repository.FullName,
+		Type:           utils.Stringify(models.AuditEventDirectPush),
+		Branch:         utils.Stringify(branch),
+		Sha:            payload.After,
+		Actor:          utils.Stringify(pusher),
+		Description:    utils.Stringify(description),
+	}
+
+	if err := s.AuditService.Record(ctx, &event); err != nil {
+		logger.FromContext(ctx).Error().Err(err).Str("sha", *payload.After).Msg("error recording direct push")
+	}
+
+	if config.NotifyDirectPushes == nil || !*config.NotifyDirectPushes {
+		return
+	}
+
+	body := "# Direct push detected :warning: \n" + "\n" +
+		fmt.Sprintf("> @%s pushed to the stable branch **%s** without a merged pull request.", pusher, branch)
+
+	if err := s.GithubClient.CreateCommitComment(ctx, config, *payload.After, body); err != nil {
+		logger.FromContext(ctx).Error().Err(err).Str("sha", *payload.After).Msg("error notifying direct push")
+	}
+}
+
+func (s *Webhook) SavePullRequestWebhook(ctx context.Context, pullRequestWH webhook.PullRequestWebhook) apierrors.ApiError {
+	ctx, span := tracing.Start(ctx, "Webhook.SavePullRequestWebhook")
+	defer span.End()
 
 	var prWH models.PullRequest
 
@@ -361,17 +905,21 @@ func (s *Webhook) SavePullRequestWebhook(pullRequestWH webhook.PullRequestWebhoo
 	prWH.HeadSha = pullRequestWH.PullRequest.Head.Sha
 	prWH.CreatedAt = pullRequestWH.PullRequest.CreatedAt
 	prWH.UpdatedAt = pullRequestWH.PullRequest.UpdatedAt
+	prWH.MergedAt = pullRequestWH.PullRequest.MergedAt
 	prWH.CreatedBy = pullRequestWH.PullRequest.User.Login
+	prWH.AutoMerge = HasAutoMergeLabel(pullRequestWH)
 
 	//Save it into database
-	if err := s.SQL.Insert(&prWH); err != nil {
+	if err := s.SQL.Insert(ctx, &prWH); err != nil {
 		return apierrors.NewInternalServerApiError("error saving new status webhook", err)
 	}
 
 	return nil
 }
 
-func (s *Webhook) UpdatePullRequestWebhook(pullRequestWH webhook.PullRequestWebhook) apierrors.ApiError {
+func (s *Webhook) UpdatePullRequestWebhook(ctx context.Context, pullRequestWH webhook.PullRequestWebhook) apierrors.ApiError {
+	ctx, span := tracing.Start(ctx, "Webhook.UpdatePullRequestWebhook")
+	defer span.End()
 
 	var prWH models.PullRequest
 
@@ -388,16 +936,97 @@ func (s *Webhook) UpdatePullRequestWebhook(pullRequestWH webhook.PullRequestWebh
 	prWH.HeadSha = pullRequestWH.PullRequest.Head.Sha
 	prWH.CreatedAt = pullRequestWH.PullRequest.CreatedAt
 	prWH.UpdatedAt = pullRequestWH.PullRequest.UpdatedAt
+	prWH.MergedAt = pullRequestWH.PullRequest.MergedAt
 	prWH.CreatedBy = pullRequestWH.PullRequest.User.Login
+	prWH.AutoMerge = HasAutoMergeLabel(pullRequestWH)
 
 	//Save it into database
-	if err := s.SQL.Update(&prWH); err != nil {
+	if err := s.SQL.Update(ctx, &prWH); err != nil {
 		return apierrors.NewInternalServerApiError("error updating new status webhook", err)
 	}
 
 	return nil
 }
 
+//AutoMerge tries to merge the pull request of the payload sha when auto-merge is enabled for it.
+func (s *Webhook) AutoMerge(ctx context.Context, config *models.Configuration, payload *webhook.Status) {
+	ctx, span := tracing.Start(ctx, "Webhook.AutoMerge")
+	defer span.End()
+
+	merged, err := s.AutoMergeService.ProcessAutoMerge(ctx, config, payload)
+
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Str("sha", *payload.Sha).Msg("error merging pull request automatically")
+	}
+
+	if merged {
+		logger.FromContext(ctx).Info().Str("sha", *payload.Sha).Msg("pull request merged automatically")
+	}
+}
+
+//DeleteMergedBranch deletes the head branch of a merged pull request when the configuration asks for it.
+//Only branches that match the configured transient prefixes are deleted, stable branches never.
+//Returns if the branch was deleted.
+func (s *Webhook) DeleteMergedBranch(ctx context.Context, config *models.Configuration, payload *webhook.PullRequestWebhook) bool {
+	ctx, span := tracing.Start(ctx, "Webhook.DeleteMergedBranch")
+	defer span.End()
+
+	if config.BranchCleanupEnabled == nil || !*config.BranchCleanupEnabled {
+		return false
+	}
+
+	branch := *payload.PullRequest.Head.Ref
+
+	//Branches from forks do not belong to the repository
+	if payload.PullRequest.Head.Repo.FullName == nil || *payload.PullRequest.Head.Repo.FullName != *payload.Repository.FullName {
+		return false
+	}
+
+	wfc := configs.GetWorkflowConfiguration(config)
+
+	if wfc.GetStableBranch(branch) != nil || !utils.HasAnyPrefix(branch, config.GetBranchCleanupPrefixes()) {
+		return false
+	}
+
+	if err := s.GithubClient.DeleteBranch(ctx, config, branch); err != nil {
+		logger.FromContext(ctx).Error().Err(err).Str("branch", branch).Msg("error deleting merged branch")
+		return false
+	}
+
+	event := models.AuditEvent{
+		RepositoryName: payload.Repository.FullName,
+		Type:           utils.Stringify(models.AuditEventBranchDeleted),
+		Branch:         utils.Stringify(branch),
+		Sha:            payload.PullRequest.Head.Sha,
+		Actor:          payload.Sender.Login,
+		Description:    utils.Stringify(fmt.Sprintf("%s deleted after merging pull request #%d", branch, payload.PullRequest.Number)),
+	}
+
+	if err := s.AuditService.Record(ctx, &event); err != nil {
+		logger.FromContext(ctx).Error().Err(err).Str("branch", branch).Msg("error recording merged branch deletion")
+	}
+
+	return true
+}
+
+//ManageMergeQueue queues the pull request when the 'merge-queue' label is added and dequeues it when it is removed.
+func (s *Webhook) ManageMergeQueue(ctx context.Context, config *models.Configuration, payload *webhook.PullRequestWebhook, pullRequest *models.PullRequest) {
+	ctx, span := tracing.Start(ctx, "Webhook.ManageMergeQueue")
+	defer span.End()
+
+	var err apierrors.ApiError
+
+	if *payload.Action == "labeled" {
+		_, err = s.MergeQueueService.Enqueue(ctx, config, pullRequest, *payload.Sender.Login)
+	} else {
+		err = s.MergeQueueService.Dequeue(ctx, config, pullRequest)
+	}
+
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Str("action", *payload.Action).Int("pull_request", pullRequest.PullRequestNumber).Msg("error managing merge queue")
+	}
+}
+
 func (s *Webhook) BuildStatusWebhookPayload(pullRequestReviewWH webhook.PullRequestReviewWebhook) *webhook.Status {
 	var statusWebhook webhook.Status
 
@@ -1,13 +1,19 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"github.com/golang/mock/gomock"
+	"github.com/hbalmes/ci_cd-api/api/metrics"
 	"github.com/hbalmes/ci_cd-api/api/mocks/interfaces"
 	"github.com/hbalmes/ci_cd-api/api/models"
 	"github.com/hbalmes/ci_cd-api/api/models/webhook"
 	"github.com/hbalmes/ci_cd-api/api/utils"
 	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
 	"github.com/jinzhu/gorm"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"net/http"
 	"testing"
 	"time"
 )
@@ -31,6 +37,9 @@ func TestWebhook_ProcessPullRequestReviewWebhook(t *testing.T) {
 		getConfig     apierrors.ApiError
 		build         *models.Build
 		buildErr      apierrors.ApiError
+		jobTimes      int
+		jobErr        apierrors.ApiError
+		insertTimes   int
 	}
 
 	statusList := []string{"workflow", "continuous-integration", "minimum-coverage", "pull-request-coverage"}
@@ -123,6 +132,8 @@ func TestWebhook_ProcessPullRequestReviewWebhook(t *testing.T) {
 			},
 
 			expects: expects{
+				jobTimes:    1,
+				insertTimes: 1,
 				clientsResult: clientsResult{
 					sqlClient: nil,
 				},
@@ -153,6 +164,8 @@ func TestWebhook_ProcessPullRequestReviewWebhook(t *testing.T) {
 				payload: &pullRequestReviewPayloadOK,
 			},
 			expects: expects{
+				jobTimes:    1,
+				insertTimes: 1,
 				clientsResult: clientsResult{
 					sqlClient: apierrors.NewBadRequestApiError("error al guardar papu"),
 				},
@@ -161,6 +174,19 @@ func TestWebhook_ProcessPullRequestReviewWebhook(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "test - action: submitted, review approved - error saving the job",
+			args: args{
+				payload: &pullRequestReviewPayloadOK,
+			},
+			expects: expects{
+				error:    gorm.ErrRecordNotFound,
+				config:   &cicdConfigOK,
+				jobTimes: 1,
+				jobErr:   apierrors.NewInternalServerApiError("error saving new job", gorm.ErrInvalidSQL),
+			},
+			wantErr: true,
+		},
 		{
 			name: "test - action: submitted, review approved - Webhook already exists",
 			args: args{
@@ -200,6 +226,7 @@ func TestWebhook_ProcessPullRequestReviewWebhook(t *testing.T) {
 				payload: &pullRequestReviewPayloadReviewDismissed,
 			},
 			expects: expects{
+				jobTimes:    1,
 				error:       gorm.ErrRecordNotFound,
 				errorDelete: nil,
 				config:      &cicdConfigOK,
@@ -224,6 +251,7 @@ func TestWebhook_ProcessPullRequestReviewWebhook(t *testing.T) {
 				payload: &pullRequestReviewPayloadReviewDismissed,
 			},
 			expects: expects{
+				jobTimes:    1,
 				error:       nil,
 				errorDelete: nil,
 				config:      &cicdConfigOK,
@@ -254,39 +282,68 @@ func TestWebhook_ProcessPullRequestReviewWebhook(t *testing.T) {
 			buildService := interfaces.NewMockBuildService(ctrl)
 
 			configService.EXPECT().
-				Get(gomock.Any()).
+				Get(gomock.Any(), gomock.Any()).
 				Return(tt.expects.config, tt.expects.getConfig).
 				AnyTimes()
 
 			buildService.EXPECT().
-				ProcessBuild(gomock.Any(), gomock.Any()).Return(tt.expects.build, tt.expects.buildErr).
+				ProcessBuild(gomock.Any(), gomock.Any(), gomock.Any()).Return(tt.expects.build, tt.expects.buildErr).
 				AnyTimes()
 
 			sqlStorage.EXPECT().
-				GetBy(gomock.Any(), gomock.Any(), gomock.Any()).
-				DoAndReturn(func(e interface{}, qry ...interface{}) *webhook.Webhook {
+				GetBy(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, e interface{}, qry ...interface{}) *webhook.Webhook {
 					return &webhookOK
 				}).
 				Return(tt.expects.error).
 				AnyTimes()
 
 			sqlStorage.EXPECT().
-				Insert(gomock.Any()).
+				Insert(gomock.Any(), gomock.Any()).
 				Return(tt.expects.clientsResult.sqlClient).
-				AnyTimes()
+				Times(tt.expects.insertTimes)
 
 			sqlStorage.EXPECT().
-				Delete(gomock.Any()).
+				Delete(gomock.Any(), gomock.Any()).
 				Return(tt.expects.errorDelete).
 				AnyTimes()
 
+			autoMergeService := interfaces.NewMockAutoMergeService(ctrl)
+
+			autoMergeService.EXPECT().
+				ProcessAutoMerge(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(false, nil).
+				AnyTimes()
+
+			//The job is saved before the webhook, and deleted once processed or when the webhook could not be saved
+			jobService := interfaces.NewMockJobService(ctrl)
+			job := &models.Job{ID: 1, Type: utils.Stringify(models.JobTypeReviewWebhook)}
+			finishTimes := tt.expects.jobTimes
+
+			if tt.expects.jobErr != nil {
+				job = nil
+				finishTimes = 0
+			}
+
+			jobService.EXPECT().
+				Start(gomock.Any(), models.JobTypeReviewWebhook, gomock.Any()).
+				Return(job, tt.expects.jobErr).
+				Times(tt.expects.jobTimes)
+
+			jobService.EXPECT().
+				Finish(gomock.Any(), job).
+				Return(nil).
+				Times(finishTimes)
+
 			s := &Webhook{
-				SQL:           sqlStorage,
-				GithubClient:  githubClient,
-				ConfigService: configService,
-				BuildService:  buildService,
+				SQL:              sqlStorage,
+				GithubClient:     githubClient,
+				ConfigService:    configService,
+				BuildService:     buildService,
+				AutoMergeService: autoMergeService,
+				JobService:       jobService,
 			}
-			_, err := s.ProcessPullRequestReviewWebhook(tt.args.payload)
+			_, err := s.ProcessPullRequestReviewWebhook(context.Background(), tt.args.payload)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Webhook.ProcessPullRequestReviewWebhook() error = %v, wantErr %v", err, tt.wantErr)
@@ -360,7 +417,7 @@ func TestWebhook_SavePullRequestWebhook(t *testing.T) {
 			githubClient := interfaces.NewMockGithubClient(ctrl)
 
 			sqlStorage.EXPECT().
-				Insert(gomock.Any()).
+				Insert(gomock.Any(), gomock.Any()).
 				Return(tt.expects.clientsResult.sqlClient).
 				AnyTimes()
 
@@ -369,7 +426,7 @@ func TestWebhook_SavePullRequestWebhook(t *testing.T) {
 				GithubClient: githubClient,
 			}
 
-			err := s.SavePullRequestWebhook(tt.args.prWebhook)
+			err := s.SavePullRequestWebhook(context.Background(), tt.args.prWebhook)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Webhook.ProcessPullRequestReviewWebhook() error = %v, wantErr %v", err, tt.wantErr)
@@ -561,7 +618,7 @@ func TestWebhook_ProcessPullRequestWebhook(t *testing.T) {
 				clientsResult: clientsResult{
 					sqlClient: nil,
 				},
-				sqlGetByError: nil,
+				sqlGetByError:  nil,
 				sqlUpdateError: gorm.ErrCantStartTransaction,
 			},
 			wantErr: true,
@@ -593,7 +650,7 @@ func TestWebhook_ProcessPullRequestWebhook(t *testing.T) {
 				clientsResult: clientsResult{
 					sqlClient: nil,
 				},
-				sqlGetByError: nil,
+				sqlGetByError:  nil,
 				sqlUpdateError: gorm.ErrCantStartTransaction,
 			},
 			wantErr: true,
@@ -718,46 +775,53 @@ func TestWebhook_ProcessPullRequestWebhook(t *testing.T) {
 			sqlStorage := interfaces.NewMockSQLStorage(ctrl)
 			githubClient := interfaces.NewMockGithubClient(ctrl)
 			configService := interfaces.NewMockConfigurationService(ctrl)
+			mergeQueueService := interfaces.NewMockMergeQueueService(ctrl)
 
 			configService.EXPECT().
-				Get(gomock.Any()).
+				Get(gomock.Any(), gomock.Any()).
 				Return(tt.expects.config, tt.expects.getConfig).
 				AnyTimes()
 
 			sqlStorage.EXPECT().
-				GetBy(gomock.Any(), gomock.Any(), gomock.Any()).
-				DoAndReturn(func(e interface{}, qry ...interface{}) *webhook.Webhook {
+				GetBy(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, e interface{}, qry ...interface{}) *webhook.Webhook {
 					return &webhookOK
 				}).
 				Return(tt.expects.sqlGetByError).
 				AnyTimes()
 
 			sqlStorage.EXPECT().
-				Insert(gomock.Any()).
+				Insert(gomock.Any(), gomock.Any()).
 				Return(tt.expects.sqlInsertError).
 				AnyTimes()
 
 			sqlStorage.EXPECT().
-				Delete(gomock.Any()).
+				Delete(gomock.Any(), gomock.Any()).
 				Return(tt.expects.sqlDeleteError).
 				AnyTimes()
 
 			sqlStorage.EXPECT().
-				Update(gomock.Any()).
+				Update(gomock.Any(), gomock.Any()).
 				Return(tt.expects.sqlUpdateError).
 				AnyTimes()
 
 			githubClient.EXPECT().
-				CreateStatus(gomock.Any(), gomock.Any()).
+				CreateStatus(gomock.Any(), gomock.Any(), gomock.Any()).
 				Return(tt.expects.clientsResult.githubClient).
 				AnyTimes()
 
+			mergeQueueService.EXPECT().
+				Dequeue(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(apierrors.NewNotFoundApiError("pull request is not queued")).
+				AnyTimes()
+
 			s := &Webhook{
-				SQL:           sqlStorage,
-				GithubClient:  githubClient,
-				ConfigService: configService,
+				SQL:               sqlStorage,
+				GithubClient:      githubClient,
+				ConfigService:     configService,
+				MergeQueueService: mergeQueueService,
 			}
-			_, err := s.ProcessPullRequestWebhook(tt.args.payload)
+			_, err := s.ProcessPullRequestWebhook(context.Background(), tt.args.payload)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Webhook.ProcessPullRequestWebhook() error = %v, wantErr %v", err, tt.wantErr)
@@ -870,25 +934,25 @@ func TestWebhook_ProcessPullRequestWebhookErrorSavingOnDB(t *testing.T) {
 			gomock.InOrder(
 
 				configService.EXPECT().
-					Get(gomock.Any()).
+					Get(gomock.Any(), gomock.Any()).
 					Return(tt.expects.config, tt.expects.getConfig).
 					AnyTimes(),
 
 				sqlStorage.EXPECT().
-					GetBy(gomock.Any(), gomock.Any(), gomock.Any()).
-					DoAndReturn(func(e interface{}, qry ...interface{}) *webhook.Webhook {
+					GetBy(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, e interface{}, qry ...interface{}) *webhook.Webhook {
 						return &webhookOK
 					}).
 					Return(tt.expects.sqlGetByError).
 					AnyTimes(),
 
 				sqlStorage.EXPECT().
-					Insert(gomock.Any()).
+					Insert(gomock.Any(), gomock.Any()).
 					Return(tt.expects.sqlInsertPRError).
 					Times(1),
 
 				sqlStorage.EXPECT().
-					Insert(gomock.Any()).
+					Insert(gomock.Any(), gomock.Any()).
 					Return(tt.expects.sqlInsertWHError).
 					Times(1),
 			)
@@ -898,7 +962,7 @@ func TestWebhook_ProcessPullRequestWebhookErrorSavingOnDB(t *testing.T) {
 				GithubClient:  githubClient,
 				ConfigService: configService,
 			}
-			_, err := s.ProcessPullRequestWebhook(tt.args.payload)
+			_, err := s.ProcessPullRequestWebhook(context.Background(), tt.args.payload)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Webhook.ProcessPullRequestReviewWebhook() error = %v, wantErr %v", err, tt.wantErr)
@@ -922,6 +986,8 @@ func TestWebhook_ProcessStatusWebhook(t *testing.T) {
 		config         *models.Configuration
 		build          *models.Build
 		buildErr       apierrors.ApiError
+		jobTimes       int
+		jobErr         apierrors.ApiError
 	}
 
 	var allowedStatusWebhookSuccess webhook.Status
@@ -1061,6 +1127,20 @@ func TestWebhook_ProcessStatusWebhook(t *testing.T) {
 				config:         &cicdConfigOK,
 				sqlGetByError:  gorm.ErrRecordNotFound,
 				sqlInsertError: gorm.ErrInvalidTransaction,
+				jobTimes:       1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "test - Error saving the job before the webhook",
+			args: args{
+				payload: &allowedStatusWebhookSuccess,
+			},
+			expects: expects{
+				config:        &cicdConfigOK,
+				sqlGetByError: gorm.ErrRecordNotFound,
+				jobTimes:      1,
+				jobErr:        apierrors.NewInternalServerApiError("error saving new job", gorm.ErrInvalidSQL),
 			},
 			wantErr: true,
 		},
@@ -1072,6 +1152,7 @@ func TestWebhook_ProcessStatusWebhook(t *testing.T) {
 			expects: expects{
 				config:        &cicdConfigOK,
 				sqlGetByError: gorm.ErrRecordNotFound,
+				jobTimes:      1,
 			},
 			wantErr: false,
 		},
@@ -1087,40 +1168,1070 @@ func TestWebhook_ProcessStatusWebhook(t *testing.T) {
 			buildService := interfaces.NewMockBuildService(ctrl)
 
 			buildService.EXPECT().
-				ProcessBuild(gomock.Any(), gomock.Any()).Return(tt.expects.build, tt.expects.buildErr).
+				ProcessBuild(gomock.Any(), gomock.Any(), gomock.Any()).Return(tt.expects.build, tt.expects.buildErr).
 				AnyTimes()
 
 			configService.EXPECT().
-				Get(gomock.Any()).
+				Get(gomock.Any(), gomock.Any()).
 				Return(tt.expects.config, tt.expects.getConfig).
 				AnyTimes()
 
 			sqlStorage.EXPECT().
-				GetBy(gomock.Any(), gomock.Any(), gomock.Any()).
-				DoAndReturn(func(e interface{}, qry ...interface{}) *webhook.Webhook {
+				GetBy(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, e interface{}, qry ...interface{}) *webhook.Webhook {
 					return &webhookOK
 				}).
 				Return(tt.expects.sqlGetByError).
 				AnyTimes()
 
+			//The webhook is not saved when its job could not be saved
+			insertTimes := tt.expects.jobTimes
+			if tt.expects.jobErr != nil {
+				insertTimes = 0
+			}
+
 			sqlStorage.EXPECT().
-				Insert(gomock.Any()).
+				Insert(gomock.Any(), gomock.Any()).
 				Return(tt.expects.sqlInsertError).
+				Times(insertTimes)
+
+			autoMergeService := interfaces.NewMockAutoMergeService(ctrl)
+
+			autoMergeService.EXPECT().
+				ProcessAutoMerge(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(false, nil).
+				AnyTimes()
+
+			mergeQueueService := interfaces.NewMockMergeQueueService(ctrl)
+
+			mergeQueueService.EXPECT().
+				ProcessStatus(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(nil).
 				AnyTimes()
 
+			jobService := interfaces.NewMockJobService(ctrl)
+
+			//The processing of a webhook is persisted before saving it, until it finishes
+			jobService.EXPECT().
+				Start(gomock.Any(), models.JobTypeStatusWebhook, tt.args.payload).
+				Return(&models.Job{ID: 1}, tt.expects.jobErr).
+				Times(tt.expects.jobTimes)
+
+			jobService.EXPECT().
+				Finish(gomock.Any(), &models.Job{ID: 1}).
+				Return(nil).
+				Times(insertTimes)
+
+			s := &Webhook{
+				SQL:               sqlStorage,
+				GithubClient:      githubClient,
+				ConfigService:     configService,
+				BuildService:      buildService,
+				AutoMergeService:  autoMergeService,
+				MergeQueueService: mergeQueueService,
+				JobService:        jobService,
+			}
+			_, err := s.ProcessStatusWebhook(context.Background(), tt.args.payload)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Webhook.ProcessStatusWebhook() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+		})
+	}
+}
+
+func TestWebhook_ProcessIssueCommentWebhook(t *testing.T) {
+
+	type expects struct {
+		config          *models.Configuration
+		getConfigErr    error
+		sqlGetWHError   error
+		sqlGetPRError   error
+		sqlInsertError  error
+		commandErr      apierrors.ApiError
+		commandTimes    int
+		wantErrorStatus int
+	}
+
+	newPayload := func(action string, body string, onPullRequest bool) *webhook.IssueComment {
+		var payload webhook.IssueComment
+		payload.Action = utils.Stringify(action)
+		payload.Comment = &struct {
+			ID   int64   `json:"id"`
+			Body *string `json:"body"`
+			User struct {
+				Login *string `json:"login"`
+			} `json:"user"`
+			AuthorAssociation *string `json:"author_association"`
+		}{ID: 987, Body: utils.Stringify(body)}
+		payload.Issue.Number = 12
+		if onPullRequest {
+			payload.Issue.PullRequest = &struct {
+				URL *string `json:"url"`
+			}{URL: utils.Stringify("https://api.github.com/repos/hbalmes/ci-cd_api/pulls/12")}
+		}
+		payload.Repository.FullName = utils.Stringify("hbalmes/ci-cd_api")
+		payload.Sender.Login = utils.Stringify("hbalmes")
+		return &payload
+	}
+
+	cicdConfigOK := models.Configuration{
+		ID:              utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:  utils.Stringify("ci-cd_api"),
+		RepositoryOwner: utils.Stringify("hbalmes"),
+		WorkflowType:    utils.Stringify("gitflow"),
+	}
+
+	tests := []struct {
+		name    string
+		payload *webhook.IssueComment
+		wantErr bool
+		expects expects
+	}{
+		{
+			name:    "comment on an issue",
+			payload: newPayload("created", "/ci-cd status", false),
+			wantErr: true,
+			expects: expects{wantErrorStatus: http.StatusPartialContent},
+		},
+		{
+			name:    "edited comment",
+			payload: newPayload("edited", "/ci-cd status", true),
+			wantErr: true,
+			expects: expects{wantErrorStatus: http.StatusPartialContent},
+		},
+		{
+			name:    "comment without command",
+			payload: newPayload("created", "LGTM", true),
+			wantErr: true,
+			expects: expects{wantErrorStatus: http.StatusPartialContent},
+		},
+		{
+			name:    "configuration not found",
+			payload: newPayload("created", "/ci-cd status", true),
+			wantErr: true,
+			expects: expects{wantErrorStatus: http.StatusNotFound},
+		},
+		{
+			name:    "webhook already processed",
+			payload: newPayload("created", "/ci-cd status", true),
+			wantErr: true,
+			expects: expects{
+				config:          &cicdConfigOK,
+				wantErrorStatus: http.StatusConflict,
+			},
+		},
+		{
+			name:    "pull request not found",
+			payload: newPayload("created", "/ci-cd status", true),
+			wantErr: true,
+			expects: expects{
+				config:          &cicdConfigOK,
+				sqlGetWHError:   gorm.ErrRecordNotFound,
+				sqlGetPRError:   gorm.ErrRecordNotFound,
+				wantErrorStatus: http.StatusNotFound,
+			},
+		},
+		{
+			name:    "error saving webhook",
+			payload: newPayload("created", "/ci-cd status", true),
+			wantErr: true,
+			expects: expects{
+				config:          &cicdConfigOK,
+				sqlGetWHError:   gorm.ErrRecordNotFound,
+				sqlInsertError:  gorm.ErrInvalidSQL,
+				wantErrorStatus: http.StatusInternalServerError,
+			},
+		},
+		{
+			name:    "command fails",
+			payload: newPayload("created", "/ci-cd bump huge", true),
+			wantErr: true,
+			expects: expects{
+				config:          &cicdConfigOK,
+				sqlGetWHError:   gorm.ErrRecordNotFound,
+				commandErr:      apierrors.NewBadRequestApiError("invalid bump argument"),
+				commandTimes:    1,
+				wantErrorStatus: http.StatusBadRequest,
+			},
+		},
+		{
+			name:    "command executed",
+			payload: newPayload("created", "/ci-cd status", true),
+			wantErr: false,
+			expects: expects{
+				config:        &cicdConfigOK,
+				sqlGetWHError: gorm.ErrRecordNotFound,
+				commandTimes:  1,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+			configService := interfaces.NewMockConfigurationService(ctrl)
+			chatOpsService := interfaces.NewMockChatOpsService(ctrl)
+
+			configService.EXPECT().
+				Get(gomock.Any(), gomock.Any()).
+				Return(tt.expects.config, tt.expects.getConfigErr).
+				AnyTimes()
+
+			sqlStorage.EXPECT().
+				GetBy(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(tt.expects.sqlGetWHError).
+				AnyTimes()
+
+			sqlStorage.EXPECT().
+				GetBy(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(tt.expects.sqlGetPRError).
+				AnyTimes()
+
+			sqlStorage.EXPECT().
+				Insert(gomock.Any(), gomock.Any()).
+				Return(tt.expects.sqlInsertError).
+				AnyTimes()
+
+			chatOpsService.EXPECT().
+				ProcessCommand(gomock.Any(), &cicdConfigOK, gomock.Any(), "hbalmes", *tt.payload.Comment.Body).
+				Return("reply", tt.expects.commandErr).
+				Times(tt.expects.commandTimes)
+
+			s := &Webhook{
+				SQL:            sqlStorage,
+				ConfigService:  configService,
+				ChatOpsService: chatOpsService,
+			}
+			wh, err := s.ProcessIssueCommentWebhook(context.Background(), tt.payload)
+
+			if tt.wantErr {
+				assert.Nil(t, wh)
+				assert.Equal(t, tt.expects.wantErrorStatus, err.Status())
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, "issue_comment", *wh.Type)
+			assert.Equal(t, 12, *wh.GithubPullRequestNumber)
+		})
+	}
+}
+
+func TestWebhook_ProcessPushWebhook(t *testing.T) {
+
+	type expects struct {
+		config          *models.Configuration
+		sqlGetWHError   error
+		sqlInsertError  error
+		sqlUpdateError  error
+		updateTimes     int
+		deleteTimes     int
+		commitPRs       []models.CommitPullRequestResponse
+		commitPRsErr    apierrors.ApiError
+		commitPRsTimes  int
+		serviceCommit   bool
+		serviceErr      error
+		auditTimes      int
+		commentTimes    int
+		wantErrorStatus int
+	}
+
+	newPayload := func(ref string, created bool, deleted bool, pusher string) *webhook.Push {
+		var payload webhook.Push
+		payload.Ref = utils.Stringify(ref)
+		payload.After = utils.Stringify("23456789qwertyuiasdfghjzxcvbn")
+		payload.Created = created
+		payload.Deleted = deleted
+		payload.Pusher.Name = utils.Stringify(pusher)
+		payload.Repository.FullName = utils.Stringify("hbalmes/ci-cd_api")
+		return &payload
+	}
+
+	notify := true
+	cicdConfigOK := models.Configuration{
+		ID:                 utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:     utils.Stringify("ci-cd_api"),
+		RepositoryOwner:    utils.Stringify("hbalmes"),
+		WorkflowType:       utils.Stringify("gitflow"),
+		NotifyDirectPushes: &notify,
+	}
+
+	mergedAt := "2020-05-01T10:00:00Z"
+	mergedIntoMaster := []models.CommitPullRequestResponse{{Number: 1, MergedAt: &mergedAt}}
+	mergedIntoMaster[0].Base.Ref = "master"
+
+	tests := []struct {
+		name    string
+		payload *webhook.Push
+		wantErr bool
+		expects expects
+	}{
+		{
+			name:    "tag push",
+			payload: newPayload("refs/tags/v1.0.0", false, false, "hbalmes"),
+			wantErr: true,
+			expects: expects{wantErrorStatus: http.StatusBadRequest},
+		},
+		{
+			name:    "configuration not found",
+			payload: newPayload("refs/heads/master", false, false, "hbalmes"),
+			wantErr: true,
+			expects: expects{wantErrorStatus: http.StatusNotFound},
+		},
+		{
+			name:    "webhook already processed",
+			payload: newPayload("refs/heads/master", false, false, "hbalmes"),
+			wantErr: true,
+			expects: expects{
+				config:          &cicdConfigOK,
+				wantErrorStatus: http.StatusConflict,
+			},
+		},
+		{
+			name:    "error saving webhook",
+			payload: newPayload("refs/heads/master", false, false, "hbalmes"),
+			wantErr: true,
+			expects: expects{
+				config:          &cicdConfigOK,
+				sqlGetWHError:   gorm.ErrRecordNotFound,
+				sqlInsertError:  gorm.ErrInvalidSQL,
+				wantErrorStatus: http.StatusInternalServerError,
+			},
+		},
+		{
+			name:    "error updating branch head",
+			payload: newPayload("refs/heads/master", false, false, "hbalmes"),
+			wantErr: true,
+			expects: expects{
+				config:          &cicdConfigOK,
+				sqlGetWHError:   gorm.ErrRecordNotFound,
+				sqlUpdateError:  gorm.ErrInvalidSQL,
+				updateTimes:     1,
+				wantErrorStatus: http.StatusInternalServerError,
+			},
+		},
+		{
+			name:    "push to a feature branch",
+			payload: newPayload("refs/heads/feature/push", false, false, "hbalmes"),
+			expects: expects{
+				config:        &cicdConfigOK,
+				sqlGetWHError: gorm.ErrRecordNotFound,
+				updateTimes:   1,
+			},
+		},
+		{
+			name:    "push to a release branch",
+			payload: newPayload("refs/heads/release/1.2", false, false, "hbalmes"),
+			expects: expects{
+				config:        &cicdConfigOK,
+				sqlGetWHError: gorm.ErrRecordNotFound,
+				updateTimes:   1,
+			},
+		},
+		{
+			name:    "stable branch deleted",
+			payload: newPayload("refs/heads/release/1.2", false, true, "hbalmes"),
+			expects: expects{
+				config:        &cicdConfigOK,
+				sqlGetWHError: gorm.ErrRecordNotFound,
+				deleteTimes:   1,
+			},
+		},
+		{
+			name:    "push to master through a merged pull request",
+			payload: newPayload("refs/heads/master", false, false, "hbalmes"),
+			expects: expects{
+				config:         &cicdConfigOK,
+				sqlGetWHError:  gorm.ErrRecordNotFound,
+				updateTimes:    1,
+				commitPRs:      mergedIntoMaster,
+				commitPRsTimes: 1,
+			},
+		},
+		{
+			name:    "error getting commit pull requests",
+			payload: newPayload("refs/heads/master", false, false, "hbalmes"),
+			expects: expects{
+				config:         &cicdConfigOK,
+				sqlGetWHError:  gorm.ErrRecordNotFound,
+				updateTimes:    1,
+				commitPRsErr:   apierrors.NewInternalServerApiError("error getting commit pull requests", nil),
+				commitPRsTimes: 1,
+			},
+		},
+		{
+			name:    "direct push to develop",
+			payload: newPayload("refs/heads/develop", false, false, "hbalmes"),
+			expects: expects{
+				config:         &cicdConfigOK,
+				sqlGetWHError:  gorm.ErrRecordNotFound,
+				updateTimes:    1,
+				commitPRs:      mergedIntoMaster,
+				commitPRsTimes: 1,
+				auditTimes:     1,
+				commentTimes:   1,
+			},
+		},
+		{
+			name:    "push to develop of a commit of this api",
+			payload: newPayload("refs/heads/develop", false, false, "ci-cd-bot"),
+			expects: expects{
+				config:        &cicdConfigOK,
+				sqlGetWHError: gorm.ErrRecordNotFound,
+				updateTimes:   1,
+				serviceCommit: true,
+			},
+		},
+		{
+			name:    "direct push to develop by the github user of this api",
+			payload: newPayload("refs/heads/develop", false, false, "ci-cd-bot"),
+			expects: expects{
+				config:         &cicdConfigOK,
+				sqlGetWHError:  gorm.ErrRecordNotFound,
+				updateTimes:    1,
+				commitPRs:      mergedIntoMaster,
+				commitPRsTimes: 1,
+				auditTimes:     1,
+				commentTimes:   1,
+			},
+		},
+		{
+			name:    "error getting the commits of this api",
+			payload: newPayload("refs/heads/develop", false, false, "ci-cd-bot"),
+			expects: expects{
+				config:         &cicdConfigOK,
+				sqlGetWHError:  gorm.ErrRecordNotFound,
+				updateTimes:    1,
+				commitPRs:      mergedIntoMaster,
+				commitPRsTimes: 1,
+				serviceErr:     gorm.ErrInvalidSQL,
+				auditTimes:     1,
+				commentTimes:   1,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+			githubClient := interfaces.NewMockGithubClient(ctrl)
+			configService := interfaces.NewMockConfigurationService(ctrl)
+			auditService := interfaces.NewMockAuditService(ctrl)
+
+			configService.EXPECT().
+				Get(gomock.Any(), gomock.Any()).
+				Return(tt.expects.config, nil).
+				AnyTimes()
+
+			sqlStorage.EXPECT().
+				GetBy(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(tt.expects.sqlGetWHError).
+				AnyTimes()
+
+			sqlStorage.EXPECT().
+				Insert(gomock.Any(), gomock.Any()).
+				Return(tt.expects.sqlInsertError).
+				AnyTimes()
+
+			sqlStorage.EXPECT().
+				Update(gomock.Any(), gomock.Any()).
+				Return(tt.expects.sqlUpdateError).
+				Times(tt.expects.updateTimes)
+
+			sqlStorage.EXPECT().
+				Delete(gomock.Any(), gomock.Any()).
+				Return(nil).
+				Times(tt.expects.deleteTimes)
+
+			githubClient.EXPECT().
+				GetPullRequestsByCommit(gomock.Any(), gomock.Any(), "23456789qwertyuiasdfghjzxcvbn").
+				Return(tt.expects.commitPRs, tt.expects.commitPRsErr).
+				Times(tt.expects.commitPRsTimes)
+
+			sqlStorage.EXPECT().
+				GetBy(gomock.Any(), gomock.Any(), "repository_name = ? AND branch = ? AND sha = ?", "hbalmes/ci-cd_api", gomock.Any(), "23456789qwertyuiasdfghjzxcvbn").
+				DoAndReturn(func(ctx context.Context, e interface{}, qry ...interface{}) error {
+					if tt.expects.serviceCommit {
+						*e.(*[]models.ServiceCommit) = []models.ServiceCommit{*models.NewServiceCommit("hbalmes/ci-cd_api", "develop", "23456789qwertyuiasdfghjzxcvbn")}
+					}
+					return tt.expects.serviceErr
+				}).
+				AnyTimes()
+
+			auditService.EXPECT().
+				Record(gomock.Any(), gomock.Any()).
+				Return(nil).
+				Times(tt.expects.auditTimes)
+
+			githubClient.EXPECT().
+				CreateCommitComment(gomock.Any(), gomock.Any(), "23456789qwertyuiasdfghjzxcvbn", gomock.Any()).
+				Return(nil).
+				Times(tt.expects.commentTimes)
+
 			s := &Webhook{
 				SQL:           sqlStorage,
 				GithubClient:  githubClient,
 				ConfigService: configService,
-				BuildService:  buildService,
+				AuditService:  auditService,
 			}
-			_, err := s.ProcessStatusWebhook(tt.args.payload)
+			wh, err := s.ProcessPushWebhook(context.Background(), tt.payload)
 
-			if (err != nil) != tt.wantErr {
-				t.Errorf("Webhook.ProcessStatusWebhook() error = %v, wantErr %v", err, tt.wantErr)
+			if tt.wantErr {
+				assert.Nil(t, wh)
+				assert.Equal(t, tt.expects.wantErrorStatus, err.Status())
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, "push", *wh.Type)
+		})
+	}
+}
+
+func TestWebhook_DeleteMergedBranch(t *testing.T) {
+	type expects struct {
+		deleteErr   apierrors.ApiError
+		deleteTimes int
+		auditTimes  int
+		deleted     bool
+	}
+
+	enabled := true
+
+	configEnabled := models.Configuration{
+		ID:                   utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:       utils.Stringify("ci-cd_api"),
+		RepositoryOwner:      utils.Stringify("hbalmes"),
+		WorkflowType:         utils.Stringify("gitflow"),
+		BranchCleanupEnabled: &enabled,
+	}
+
+	configDisabled := models.Configuration{
+		ID:              utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:  utils.Stringify("ci-cd_api"),
+		RepositoryOwner: utils.Stringify("hbalmes"),
+		WorkflowType:    utils.Stringify("gitflow"),
+	}
+
+	configCustomPrefixes := configEnabled
+	configCustomPrefixes.BranchCleanupPrefixes = []models.BranchCleanupPrefix{{Prefix: "chore/"}, {Prefix: "release/"}}
+
+	newPayload := func(head string, headRepo string) *webhook.PullRequestWebhook {
+		var payload webhook.PullRequestWebhook
+		payload.Action = utils.Stringify("closed")
+		payload.PullRequest.Number = 12
+		payload.PullRequest.Merged = true
+		payload.PullRequest.Head.Ref = utils.Stringify(head)
+		payload.PullRequest.Head.Sha = utils.Stringify("23456789qwertyuiasdfghjzxcvbn")
+		payload.PullRequest.Head.Repo.FullName = utils.Stringify(headRepo)
+		payload.Repository.FullName = utils.Stringify("hbalmes/ci-cd_api")
+		payload.Sender.Login = utils.Stringify("hbalmes")
+		return &payload
+	}
+
+	tests := []struct {
+		name    string
+		config  *models.Configuration
+		payload *webhook.PullRequestWebhook
+		expects expects
+	}{
+		{
+			name:    "branch cleanup disabled",
+			config:  &configDisabled,
+			payload: newPayload("feature/cleanup", "hbalmes/ci-cd_api"),
+		},
+		{
+			name:    "branch from a fork",
+			config:  &configEnabled,
+			payload: newPayload("feature/cleanup", "someone/ci-cd_api"),
+		},
+		{
+			name:    "stable branch is never deleted",
+			config:  &configCustomPrefixes,
+			payload: newPayload("release/1.2", "hbalmes/ci-cd_api"),
+		},
+		{
+			name:    "branch without a transient prefix",
+			config:  &configCustomPrefixes,
+			payload: newPayload("feature/cleanup", "hbalmes/ci-cd_api"),
+		},
+		{
+			name:    "error deleting branch",
+			config:  &configEnabled,
+			payload: newPayload("feature/cleanup", "hbalmes/ci-cd_api"),
+			expects: expects{
+				deleteErr:   apierrors.NewNotFoundApiError("branch feature/cleanup not found"),
+				deleteTimes: 1,
+			},
+		},
+		{
+			name:    "merged branch deleted and audited",
+			config:  &configEnabled,
+			payload: newPayload("hotfix/cleanup", "hbalmes/ci-cd_api"),
+			expects: expects{
+				deleteTimes: 1,
+				auditTimes:  1,
+				deleted:     true,
+			},
+		},
+		{
+			name:    "merged branch with a custom prefix deleted",
+			config:  &configCustomPrefixes,
+			payload: newPayload("chore/cleanup", "hbalmes/ci-cd_api"),
+			expects: expects{
+				deleteTimes: 1,
+				auditTimes:  1,
+				deleted:     true,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			githubClient := interfaces.NewMockGithubClient(ctrl)
+			auditService := interfaces.NewMockAuditService(ctrl)
+
+			githubClient.EXPECT().
+				DeleteBranch(gomock.Any(), tt.config, *tt.payload.PullRequest.Head.Ref).
+				Return(tt.expects.deleteErr).
+				Times(tt.expects.deleteTimes)
+
+			auditService.EXPECT().
+				Record(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, event *models.AuditEvent) apierrors.ApiError {
+					assert.Equal(t, models.AuditEventBranchDeleted, *event.Type)
+					assert.Equal(t, *tt.payload.PullRequest.Head.Ref, *event.Branch)
+					return nil
+				}).
+				Times(tt.expects.auditTimes)
+
+			s := &Webhook{
+				GithubClient: githubClient,
+				AuditService: auditService,
+			}
+
+			assert.Equal(t, tt.expects.deleted, s.DeleteMergedBranch(context.Background(), tt.config, tt.payload))
+		})
+	}
+}
+
+func TestWebhook_ProcessPullRequestWebhookClosed(t *testing.T) {
+	type expects struct {
+		dequeueErr     apierrors.ApiError
+		backMergeTimes int
+	}
+
+	config := models.Configuration{
+		ID:              utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:  utils.Stringify("ci-cd_api"),
+		RepositoryOwner: utils.Stringify("hbalmes"),
+		WorkflowType:    utils.Stringify("gitflow"),
+	}
+
+	newPayload := func(base string, head string, merged bool) *webhook.PullRequestWebhook {
+		var payload webhook.PullRequestWebhook
+		payload.Action = utils.Stringify("closed")
+		payload.PullRequest.ID = 1234
+		payload.PullRequest.Number = 12
+		payload.PullRequest.Merged = merged
+		payload.PullRequest.Base.Ref = utils.Stringify(base)
+		payload.PullRequest.Head.Ref = utils.Stringify(head)
+		payload.PullRequest.Head.Sha = utils.Stringify("23456789qwertyuiasdfghjzxcvbn")
+		payload.PullRequest.Head.Repo.FullName = utils.Stringify("hbalmes/ci-cd_api")
+		payload.Repository.FullName = utils.Stringify("hbalmes/ci-cd_api")
+		payload.Sender.Login = utils.Stringify("hbalmes")
+		return &payload
+	}
+
+	tests := []struct {
+		name    string
+		payload *webhook.PullRequestWebhook
+		expects expects
+	}{
+		{
+			name:    "release closed without merging",
+			payload: newPayload("master", "release/1.3", false),
+			expects: expects{
+				dequeueErr: apierrors.NewNotFoundApiError("pull request is not queued"),
+			},
+		},
+		{
+			name:    "queued pull request closed without merging",
+			payload: newPayload("develop", "feature/queue", false),
+		},
+		{
+			name:    "error dequeuing the closed pull request",
+			payload: newPayload("develop", "feature/queue", false),
+			expects: expects{
+				dequeueErr: apierrors.NewInternalServerApiError("error getting merge queue entry", nil),
+			},
+		},
+		{
+			name:    "feature merged into develop",
+			payload: newPayload("develop", "feature/back-merge", true),
+			expects: expects{
+				dequeueErr: apierrors.NewNotFoundApiError("pull request is not queued"),
+			},
+		},
+		{
+			name:    "release merged into master",
+			payload: newPayload("master", "release/1.3", true),
+			expects: expects{
+				backMergeTimes: 1,
+			},
+		},
+		{
+			name:    "hotfix merged into master",
+			payload: newPayload("master", "hotfix/1.3.1", true),
+			expects: expects{
+				backMergeTimes: 1,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+			configService := interfaces.NewMockConfigurationService(ctrl)
+			buildService := interfaces.NewMockBuildService(ctrl)
+			mergeQueueService := interfaces.NewMockMergeQueueService(ctrl)
+
+			configService.EXPECT().
+				Get(gomock.Any(), "hbalmes/ci-cd_api").
+				Return(&config, nil).
+				Times(1)
+
+			sqlStorage.EXPECT().
+				GetBy(gomock.Any(), gomock.Any(), "id = ?", gomock.Any()).
+				Return(nil).
+				Times(1)
+
+			sqlStorage.EXPECT().
+				Update(gomock.Any(), gomock.Any()).
+				Return(nil).
+				Times(1)
+
+			mergeQueueService.EXPECT().
+				Dequeue(gomock.Any(), &config, gomock.Any()).
+				Do(func(ctx context.Context, config *models.Configuration, pr *models.PullRequest) {
+					assert.Equal(t, int64(1234), pr.ID)
+				}).
+				Return(tt.expects.dequeueErr).
+				Times(1)
+
+			buildService.EXPECT().
+				BackMerge(gomock.Any(), &config, gomock.Any()).
+				Do(func(ctx context.Context, config *models.Configuration, pr *models.PullRequest) {
+					assert.Equal(t, int64(1234), pr.ID)
+					assert.Equal(t, 12, pr.PullRequestNumber)
+					assert.Equal(t, *tt.payload.PullRequest.Head.Ref, *pr.HeadRef)
+				}).
+				Times(tt.expects.backMergeTimes)
+
+			s := &Webhook{
+				SQL:               sqlStorage,
+				ConfigService:     configService,
+				BuildService:      buildService,
+				MergeQueueService: mergeQueueService,
+			}
+
+			_, err := s.ProcessPullRequestWebhook(context.Background(), tt.payload)
+
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func TestWebhook_ProcessDeploymentStatusWebhook(t *testing.T) {
+
+	type expects struct {
+		config          *models.Configuration
+		sqlGetWHError   error
+		sqlInsertError  error
+		processTimes    int
+		processErr      apierrors.ApiError
+		wantErrorStatus int
+	}
+
+	newPayload := func(state string) *webhook.DeploymentStatus {
+		var payload webhook.DeploymentStatus
+		payload.DeploymentStatus.ID = 42
+		payload.DeploymentStatus.State = utils.Stringify(state)
+		payload.Deployment.ID = 7
+		payload.Deployment.Sha = utils.Stringify("23456789qwertyuiasdfghjzxcvbn")
+		payload.Deployment.Environment = utils.Stringify("staging")
+		payload.Repository.FullName = utils.Stringify("hbalmes/ci-cd_api")
+		payload.Sender.Login = utils.Stringify("deployer")
+		return &payload
+	}
+
+	invalidPayload := newPayload("success")
+	invalidPayload.Deployment.ID = 0
+
+	cicdConfigOK := models.Configuration{
+		ID:              utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:  utils.Stringify("ci-cd_api"),
+		RepositoryOwner: utils.Stringify("hbalmes"),
+		WorkflowType:    utils.Stringify("gitflow"),
+	}
+
+	tests := []struct {
+		name    string
+		payload *webhook.DeploymentStatus
+		wantErr bool
+		expects expects
+	}{
+		{
+			name:    "invalid payload",
+			payload: invalidPayload,
+			wantErr: true,
+			expects: expects{
+				wantErrorStatus: http.StatusBadRequest,
+			},
+		},
+		{
+			name:    "configuration not found",
+			payload: newPayload("success"),
+			wantErr: true,
+			expects: expects{
+				wantErrorStatus: http.StatusNotFound,
+			},
+		},
+		{
+			name:    "webhook already exists",
+			payload: newPayload("success"),
+			wantErr: true,
+			expects: expects{
+				config:          &cicdConfigOK,
+				wantErrorStatus: http.StatusConflict,
+			},
+		},
+		{
+			name:    "error saving the webhook",
+			payload: newPayload("success"),
+			wantErr: true,
+			expects: expects{
+				config:          &cicdConfigOK,
+				sqlGetWHError:   gorm.ErrRecordNotFound,
+				sqlInsertError:  errors.New("insert error"),
+				wantErrorStatus: http.StatusInternalServerError,
+			},
+		},
+		{
+			name:    "deployment not created by the api",
+			payload: newPayload("success"),
+			wantErr: false,
+			expects: expects{
+				config:        &cicdConfigOK,
+				sqlGetWHError: gorm.ErrRecordNotFound,
+				processTimes:  1,
+				processErr:    apierrors.NewNotFoundApiError("deployment 7 not found"),
+			},
+		},
+		{
+			name:    "deployment status processed",
+			payload: newPayload("success"),
+			wantErr: false,
+			expects: expects{
+				config:        &cicdConfigOK,
+				sqlGetWHError: gorm.ErrRecordNotFound,
+				processTimes:  1,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sqlStorage := interfaces.NewMockSQLStorage(ctrl)
+			configService := interfaces.NewMockConfigurationService(ctrl)
+			deploymentService := interfaces.NewMockDeploymentService(ctrl)
+
+			configService.EXPECT().
+				Get(gomock.Any(), gomock.Any()).
+				Return(tt.expects.config, nil).
+				AnyTimes()
+
+			sqlStorage.EXPECT().
+				GetBy(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(tt.expects.sqlGetWHError).
+				AnyTimes()
+
+			sqlStorage.EXPECT().
+				Insert(gomock.Any(), gomock.Any()).
+				Return(tt.expects.sqlInsertError).
+				AnyTimes()
+
+			deploymentService.EXPECT().
+				ProcessStatus(gomock.Any(), tt.payload).
+				Return(nil, tt.expects.processErr).
+				Times(tt.expects.processTimes)
+
+			s := &Webhook{
+				SQL:               sqlStorage,
+				ConfigService:     configService,
+				DeploymentService: deploymentService,
+			}
+			wh, err := s.ProcessDeploymentStatusWebhook(context.Background(), tt.payload)
+
+			if tt.wantErr {
+				assert.Nil(t, wh)
+				assert.Equal(t, tt.expects.wantErrorStatus, err.Status())
 				return
 			}
 
+			assert.Nil(t, err)
+			assert.Equal(t, "deployment_status", *wh.Type)
+			assert.Equal(t, "staging", *wh.Context)
+			assert.Equal(t, "success", *wh.State)
+		})
+	}
+}
+
+func TestWebhook_ResumeJobs(t *testing.T) {
+	now := time.Now()
+
+	config := &models.Configuration{
+		ID:              utils.Stringify("hbalmes/ci-cd_api"),
+		RepositoryName:  utils.Stringify("ci-cd_api"),
+		RepositoryOwner: utils.Stringify("hbalmes"),
+	}
+
+	statusJob := models.Job{
+		ID:       1,
+		Type:     utils.Stringify(models.JobTypeStatusWebhook),
+		Status:   utils.Stringify(models.JobStatusRunning),
+		Payload:  utils.Stringify(`{"sha": "23456789qwertyuiasdfghjzxcvbn", "context": "workflow", "state": "success", "repository": {"full_name": "hbalmes/ci-cd_api"}}`),
+		Attempts: 2,
+	}
+
+	invalidJob := statusJob
+	invalidJob.Payload = utils.Stringify(`{"sha": 1}`)
+
+	reviewJob := statusJob
+	reviewJob.Type = utils.Stringify(models.JobTypeReviewWebhook)
+
+	unknownJob := statusJob
+	unknownJob.Type = utils.Stringify("unknown")
+
+	runningJobs := func() float64 {
+		return testutil.ToFloat64(metrics.JobsRunning.WithLabelValues(models.JobTypeStatusWebhook)) +
+			testutil.ToFloat64(metrics.JobsRunning.WithLabelValues(models.JobTypeReviewWebhook))
+	}
+
+	tests := []struct {
+		name            string
+		jobs            []models.Job
+		claimErr        apierrors.ApiError
+		configErr       error
+		processTimes    int
+		mergeQueueTimes int
+		finishTimes     int
+	}{
+		{
+			name:     "error claiming jobs",
+			claimErr: apierrors.NewInternalServerApiError("error getting interrupted jobs", gorm.ErrInvalidSQL),
+		},
+		{
+			name:            "status webhook job resumed and finished",
+			jobs:            []models.Job{statusJob},
+			processTimes:    1,
+			mergeQueueTimes: 1,
+			finishTimes:     1,
+		},
+		{
+			name:         "review webhook job resumed and finished, without moving the merge queues",
+			jobs:         []models.Job{reviewJob},
+			processTimes: 1,
+			finishTimes:  1,
+		},
+		{
+			name:      "error getting configuration, job left to be resumed again",
+			jobs:      []models.Job{statusJob},
+			configErr: gorm.ErrInvalidSQL,
+		},
+		{
+			name: "invalid payload, job left until it fails",
+			jobs: []models.Job{invalidJob},
+		},
+		{
+			name: "unknown job type",
+			jobs: []models.Job{unknownJob},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			jobService := interfaces.NewMockJobService(ctrl)
+			configService := interfaces.NewMockConfigurationService(ctrl)
+			buildService := interfaces.NewMockBuildService(ctrl)
+			autoMergeService := interfaces.NewMockAutoMergeService(ctrl)
+			mergeQueueService := interfaces.NewMockMergeQueueService(ctrl)
+
+			jobService.EXPECT().
+				ClaimInterrupted(gomock.Any(), now).
+				Return(tt.jobs, tt.claimErr).
+				Times(1)
+
+			configService.EXPECT().
+				Get(gomock.Any(), "hbalmes/ci-cd_api").
+				Return(config, tt.configErr).
+				AnyTimes()
+
+			buildService.EXPECT().
+				ProcessBuild(gomock.Any(), config, gomock.Any()).
+				DoAndReturn(func(ctx context.Context, config *models.Configuration, payload *webhook.Status) (*models.Build, apierrors.ApiError) {
+					assert.Equal(t, "23456789qwertyuiasdfghjzxcvbn", *payload.Sha)
+					//The resumed job is counted as running while it is processed
+					assert.Equal(t, float64(1), runningJobs())
+					//The buildability check replaces the state of the payload
+					payload.State = utils.Stringify("approved")
+					return nil, nil
+				}).
+				Times(tt.processTimes)
+
+			autoMergeService.EXPECT().
+				ProcessAutoMerge(gomock.Any(), config, gomock.Any()).
+				Return(false, nil).
+				Times(tt.processTimes)
+
+			mergeQueueService.EXPECT().
+				ProcessStatus(gomock.Any(), config, gomock.Any()).
+				DoAndReturn(func(ctx context.Context, config *models.Configuration, payload *webhook.Status) apierrors.ApiError {
+					assert.Equal(t, "success", *payload.State)
+					return nil
+				}).
+				Times(tt.mergeQueueTimes)
+
+			jobService.EXPECT().
+				Finish(gomock.Any(), gomock.Any()).
+				Return(nil).
+				Times(tt.finishTimes)
+
+			s := &Webhook{
+				ConfigService:     configService,
+				BuildService:      buildService,
+				AutoMergeService:  autoMergeService,
+				MergeQueueService: mergeQueueService,
+				JobService:        jobService,
+			}
+
+			s.ResumeJobs(context.Background(), now)
+			assert.Equal(t, float64(0), runningJobs())
 		})
 	}
 }
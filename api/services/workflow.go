@@ -1,18 +1,20 @@
 package services
 
 import (
+	"context"
 	"github.com/hbalmes/ci_cd-api/api/models"
 	"github.com/hbalmes/ci_cd-api/api/models/webhook"
 	"github.com/hbalmes/ci_cd-api/api/utils"
 	"github.com/hbalmes/ci_cd-api/api/utils/apierrors"
+	"github.com/hbalmes/ci_cd-api/api/utils/tracing"
 	"strings"
 )
 import "github.com/hbalmes/ci_cd-api/api/configs"
 
 //ConfigurationService is an interface which represents the ConfigurationService for testing purpose.
 type WorkflowService interface {
-	SetWorkflow(config *models.Configuration) apierrors.ApiError
-	UnsetWorkflow(config *models.Configuration) apierrors.ApiError
+	SetWorkflow(ctx context.Context, config *models.Configuration) apierrors.ApiError
+	UnsetWorkflow(ctx context.Context, config *models.Configuration) apierrors.ApiError
 	CheckWorkflow(config *models.Configuration, prWebhook *webhook.PullRequestWebhook) *webhook.Status
 }
 
@@ -26,7 +28,9 @@ const (
 
 //SetWorkflow protects the necessary branches for the workflow selected by the user
 //It performs all the actions needed to enabled successfuly Release Process.
-func (c *Configuration) SetWorkflow(config *models.Configuration) apierrors.ApiError {
+func (c *Configuration) SetWorkflow(ctx context.Context, config *models.Configuration) apierrors.ApiError {
+	ctx, span := tracing.Start(ctx, "Configuration.SetWorkflow")
+	defer span.End()
 
 	//Get the selected workflow configuration
 	wfc := configs.GetWorkflowConfiguration(config)
@@ -37,13 +41,13 @@ func (c *Configuration) SetWorkflow(config *models.Configuration) apierrors.ApiE
 	for _, branch := range workflowBranchesList {
 		if branch.Stable && branch.Requirements.ProtectAtStartup {
 			//Protect the branch
-			bpError := c.GithubClient.ProtectBranch(config, &branch)
+			bpError := c.GithubClient.ProtectBranch(ctx, config, &branch)
 
 			if bpError != nil {
 				//the branch does not exist. We will create it.
 				if bpError.Message() == "branch not found" {
 
-					createBranchErr := c.GithubClient.CreateGithubRef(config, &branch, wfc)
+					createBranchErr := c.GithubClient.CreateGithubRef(ctx, config, &branch, wfc)
 
 					if createBranchErr != nil {
 						return createBranchErr
@@ -60,7 +64,7 @@ func (c *Configuration) SetWorkflow(config *models.Configuration) apierrors.ApiE
 	}
 
 	//Update the default branch
-	setDefaultBranchErr := c.GithubClient.SetDefaultBranch(config, wfc)
+	setDefaultBranchErr := c.GithubClient.SetDefaultBranch(ctx, config, wfc)
 
 	if setDefaultBranchErr != nil {
 		return setDefaultBranchErr
@@ -125,7 +129,9 @@ func (c *Configuration) CheckWorkflow(config *models.Configuration, prWebhook *w
 }
 
 //UnsetWorkflow unprotect the necessary branches for the workflow configured
-func (c *Configuration) UnsetWorkflow(config *models.Configuration) apierrors.ApiError {
+func (c *Configuration) UnsetWorkflow(ctx context.Context, config *models.Configuration) apierrors.ApiError {
+	ctx, span := tracing.Start(ctx, "Configuration.UnsetWorkflow")
+	defer span.End()
 
 	//Get the selected workflow configuration
 	wfc := configs.GetWorkflowConfiguration(config)
@@ -136,7 +142,7 @@ func (c *Configuration) UnsetWorkflow(config *models.Configuration) apierrors.Ap
 	for _, branch := range workflowBranchesList {
 		if branch.Stable && branch.Requirements.ProtectAtStartup {
 			//delete branch protection
-			bpError := c.GithubClient.UnprotectBranch(config, &branch)
+			bpError := c.GithubClient.UnprotectBranch(ctx, config, &branch)
 
 			if bpError != nil {
 				return bpError
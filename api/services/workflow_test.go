@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"github.com/golang/mock/gomock"
 	"github.com/hbalmes/ci_cd-api/api/mocks/interfaces"
@@ -104,17 +105,17 @@ func TestConfiguration_SetWorkflow(t *testing.T) {
 			githubClient := interfaces.NewMockGithubClient(ctrl)
 
 			githubClient.EXPECT().
-				ProtectBranch(gomock.Any(), gomock.Any()).
+				ProtectBranch(gomock.Any(), gomock.Any(), gomock.Any()).
 				Return(tt.expects.protectBranchErr).
 				AnyTimes()
 
 			githubClient.EXPECT().
-				CreateGithubRef(gomock.Any(), gomock.Any(), gomock.Any()).
+				CreateGithubRef(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 				Return(tt.expects.createGitRefErr).
 				AnyTimes()
 
 			githubClient.EXPECT().
-				SetDefaultBranch(gomock.Any(), gomock.Any()).
+				SetDefaultBranch(gomock.Any(), gomock.Any(), gomock.Any()).
 				Return(tt.expects.setDefaultBranchErr).
 				AnyTimes()
 
@@ -122,7 +123,7 @@ func TestConfiguration_SetWorkflow(t *testing.T) {
 				SQL:          sqlStorage,
 				GithubClient: githubClient,
 			}
-			if err := c.SetWorkflow(tt.args.config); (err != nil) != tt.wantErr {
+			if err := c.SetWorkflow(context.Background(), tt.args.config); (err != nil) != tt.wantErr {
 				t.Errorf("SetWorkflow() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -243,14 +244,14 @@ func TestConfiguration_CheckWorkflow(t *testing.T) {
 			want: &statusWebhookFail,
 		},
 		{
-			name: "base: develop - head: master - Workflow FAIL",
+			name: "base: develop - head: master - back-merge - Workflow OK",
 			args: args{
 				config:    &cicdConfigOK,
 				prWebhook: &pullRequestWebhook,
 				baseRef:   "develop",
 				headRef:   "master",
 			},
-			want: &statusWebhookFail,
+			want: &statusWebhookOK,
 		},
 		{
 			name: "base: develop - head: release - Workflow FAIL",
@@ -573,7 +574,7 @@ func TestConfiguration_UnsetWorkflow(t *testing.T) {
 			githubClient := interfaces.NewMockGithubClient(ctrl)
 
 			githubClient.EXPECT().
-				UnprotectBranch(gomock.Any(), gomock.Any()).
+				UnprotectBranch(gomock.Any(), gomock.Any(), gomock.Any()).
 				Return(tt.expects.unprotectBranchErr).
 				AnyTimes()
 
@@ -581,7 +582,7 @@ func TestConfiguration_UnsetWorkflow(t *testing.T) {
 				SQL:          sqlStorage,
 				GithubClient: githubClient,
 			}
-			if err := c.UnsetWorkflow(tt.args.config); (err != nil) != tt.wantErr {
+			if err := c.UnsetWorkflow(context.Background(), tt.args.config); (err != nil) != tt.wantErr {
 				t.Errorf("SetWorkflow() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
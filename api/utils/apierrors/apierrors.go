@@ -58,6 +58,14 @@ func NewBadRequestApiError(message string) ApiError {
 	return apiErr{message, "bad_request", http.StatusBadRequest, CauseList{}}
 }
 
+func NewUnauthorizedApiError(message string) ApiError {
+	return apiErr{message, "unauthorized", http.StatusUnauthorized, CauseList{}}
+}
+
+func NewForbiddenApiError(message string) ApiError {
+	return apiErr{message, "forbidden", http.StatusForbidden, CauseList{}}
+}
+
 func NewInternalServerApiError(message string, err error) ApiError {
 	cause := CauseList{}
 	if err != nil {
@@ -38,5 +38,7 @@ type HTTPContext interface {
 	GetHeader(string) string
 	JSON(int, interface{})
 	Param(key string) string
+	Query(key string) string
 	ShouldBindJSON(interface{}) error
+	Value(key interface{}) interface{}
 }
\ No newline at end of file
@@ -0,0 +1,140 @@
+package logger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hbalmes/ci_cd-api/api/configs"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	requestIDHeader      = "X-Request-ID"
+	githubDeliveryHeader = "X-GitHub-Delivery"
+	githubEventHeader    = "X-Github-Event"
+	requestIDField       = "request_id"
+	traceIDField         = "trace_id"
+	repositoryField      = "repository"
+	defaultLevel         = zerolog.InfoLevel
+	serviceName          = "ci_cd-api"
+)
+
+type ctxKey struct{}
+
+//Setup configures the global logger to write JSON lines into the stdout
+//The minimum level is taken from the configs, an invalid one falls back to info
+func Setup(cfg configs.LogConfig) {
+	level, err := zerolog.ParseLevel(cfg.Level)
+	if err != nil || level == zerolog.NoLevel {
+		level = defaultLevel
+	}
+
+	zerolog.SetGlobalLevel(level)
+	zerolog.TimeFieldFormat = time.RFC3339Nano
+	log.Logger = zerolog.New(os.Stdout).With().Timestamp().Str("service", serviceName).Logger()
+
+	if err != nil {
+		log.Warn().Err(err).Msg("invalid log level, using info")
+	}
+}
+
+//NewContext returns a copy of the context carrying the given logger
+func NewContext(ctx context.Context, l zerolog.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, &l)
+}
+
+//FromContext returns the logger carried by the context
+//When there is none, the global logger is returned so nothing is lost
+func FromContext(ctx context.Context) *zerolog.Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(ctxKey{}).(*zerolog.Logger); ok {
+			return l
+		}
+	}
+	return &log.Logger
+}
+
+//With returns a copy of the context whose logger adds the given field to every log
+func With(ctx context.Context, key string, value string) context.Context {
+	return NewContext(ctx, FromContext(ctx).With().Str(key, value).Logger())
+}
+
+//WithRepository returns a copy of the context whose logger adds the repository to every log
+func WithRepository(ctx context.Context, repositoryName string) context.Context {
+	return With(ctx, repositoryField, repositoryName)
+}
+
+//NewJobContext returns a context for the work done outside of a request, like the scheduled jobs
+func NewJobContext(job string) context.Context {
+	return NewContext(context.Background(), log.Logger.With().Str(requestIDField, newRequestID()).Str("job", job).Logger())
+}
+
+//Middleware seeds the request context with a logger carrying the correlation id of the request
+//The id is the github delivery id for the webhooks, the X-Request-ID header or a new one,
+//and it is returned in the X-Request-ID header. The trace id is added when the request is traced.
+//Once the request is served, it writes the access log.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		requestID := c.GetHeader(githubDeliveryHeader)
+		if requestID == "" {
+			requestID = c.GetHeader(requestIDHeader)
+		}
+		if requestID == "" {
+			requestID = newRequestID()
+		}
+
+		lc := log.Logger.With().Str(requestIDField, requestID)
+		if event := c.GetHeader(githubEventHeader); event != "" {
+			lc = lc.Str("github_event", event)
+		}
+		if sc := trace.SpanContextFromContext(c.Request.Context()); sc.HasTraceID() {
+			lc = lc.Str(traceIDField, sc.TraceID().String())
+		}
+		if owner, name := c.Param("repoOwner"), c.Param("repoName"); owner != "" && name != "" {
+			lc = lc.Str(repositoryField, owner+"/"+name)
+		}
+		l := lc.Logger()
+
+		c.Request = c.Request.WithContext(NewContext(c.Request.Context(), l))
+		c.Header(requestIDHeader, requestID)
+
+		c.Next()
+
+		status := c.Writer.Status()
+
+		var event *zerolog.Event
+		switch {
+		case status >= http.StatusInternalServerError:
+			event = l.Error()
+		case status >= http.StatusBadRequest:
+			event = l.Warn()
+		default:
+			event = l.Info()
+		}
+
+		event.Str("method", c.Request.Method).
+			Str("path", c.Request.URL.Path).
+			Int("status", status).
+			Dur("latency", time.Since(start)).
+			Str("client_ip", c.ClientIP()).
+			Msg("request served")
+	}
+}
+
+//newRequestID generates a random correlation id
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return time.Now().Format("20060102150405.000000000")
+	}
+	return hex.EncodeToString(b)
+}
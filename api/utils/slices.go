@@ -1,6 +1,10 @@
 package utils
 
-import "github.com/hbalmes/ci_cd-api/api/models"
+import (
+	"strings"
+
+	"github.com/hbalmes/ci_cd-api/api/models"
+)
 
 //Returns if a slice contains a requireStatusCheck.
 func ContainsStatusChecks(s []models.RequireStatusCheck, e string) bool {
@@ -23,12 +27,22 @@ func Remove(s []string, r string) []string {
 	return rsc
 }
 
+//Returns if the string starts with any of the prefixes
+func HasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
 //Returns if a slice of string contains an string
-/*func StringContains(s []string, e string) bool {
+func StringContains(s []string, e string) bool {
 	for _, a := range s {
 		if a == e {
 			return true
 		}
 	}
 	return false
-}*/
+}
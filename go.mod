@@ -6,12 +6,20 @@ require (
 	github.com/coreos/go-semver v0.3.0
 	github.com/gin-gonic/gin v1.5.0
 	github.com/go-git/go-git/v5 v5.1.0
-	github.com/golang/mock v1.4.3
+	github.com/golang/mock v1.6.0
 	github.com/jinzhu/gorm v1.9.12
-	github.com/json-iterator/go v1.1.7
+	github.com/json-iterator/go v1.1.10
 	github.com/mercadolibre/golang-restclient v0.0.0-20170701022150-51958130a0a0
 	github.com/modern-go/concurrent v0.0.0-20180306012644-bacd9c7ef1dd // indirect
 	github.com/modern-go/reflect2 v1.0.1 // indirect
+	github.com/prometheus/client_golang v1.7.0
+	github.com/robfig/cron/v3 v3.0.1
 	github.com/rs/zerolog v1.19.0
-	github.com/stretchr/testify v1.5.1
+	github.com/stretchr/testify v1.8.4
+	go.opentelemetry.io/otel v1.24.0
+	go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp v1.24.0
+	go.opentelemetry.io/otel/exporters/stdout/stdouttrace v1.24.0
+	go.opentelemetry.io/otel/sdk v1.24.0
+	go.opentelemetry.io/otel/trace v1.24.0
+	gopkg.in/yaml.v3 v3.0.1
 )